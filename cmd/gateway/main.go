@@ -4,30 +4,53 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"seckill_system/config"
 	"seckill_system/global"
+	"seckill_system/service"
+	"seckill_system/web/middleware"
 	"seckill_system/web/router"
 )
 
 // 初始化全局变量
 func init() {
 	global.DBClient = nil
-	global.RedisClusterClient = nil
+	global.RedisClient = nil
 	global.KafkaWriter = nil
 	global.KafkaReader = nil
 	global.EtcdClient = nil
 }
 
+// 本地配置文件路径
+const configPath = "conf/conf.yaml"
+
 // 程序主入口
 func main() {
-	// 加载配置文件
-	config.InitConfig("conf/conf.yaml")
+	// 加载配置：设置了SECKILL_CONFIG_ETCD_KEY时从Etcd读取配置文档，否则读取本地配置文件
+	if key := os.Getenv("SECKILL_CONFIG_ETCD_KEY"); key != "" {
+		endpoints := strings.Split(os.Getenv("SECKILL_CONFIG_ETCD_ENDPOINTS"), ",")
+		if err := config.InitConfigFromEtcd(endpoints, key); err != nil {
+			slog.Error("Failed to load config from etcd", "key", key, "error", err)
+			os.Exit(1)
+		}
+		// SECKILL_CONFIG_ETCD_WATCH=true时监听配置文档变更
+		if os.Getenv("SECKILL_CONFIG_ETCD_WATCH") == "true" {
+			if err := config.WatchConfigFromEtcd(context.Background(), endpoints, key, nil); err != nil {
+				slog.Error("Failed to watch config in etcd", "key", key, "error", err)
+			}
+		}
+	} else {
+		config.InitConfig(configPath)
+		// 收到SIGHUP时重新加载配置文件中可热加载的配置项
+		go watchReload(configPath)
+	}
 	cfg := config.AppConfig
 
 	// 初始化数据库和中间件连接
@@ -35,9 +58,15 @@ func main() {
 	global.InitRedis()
 	global.InitKafka()
 	global.InitEtcd()
+	// 仓库在构造时读取全局客户端，必须在全部客户端初始化之后再构造服务和路由
+	if err := global.CheckInitialized(); err != nil {
+		slog.Error("Initialization order violated", "error", err)
+		os.Exit(1)
+	}
 
-	// 设置路由
-	gateway := router.InitRouter()
+	// 设置路由，配置了独立管理端口时公共路由不包含管理接口
+	separateAdmin := cfg.Server.AdminPort > 0
+	gateway := router.InitRouter(!separateAdmin)
 
 	// 配置HTTP服务器
 	gatewayServer := &http.Server{
@@ -45,23 +74,55 @@ func main() {
 		Handler: gateway,
 	}
 
+	// 监听公共端口，配置了单IP连接数上限时在接受连接时拒绝超出上限的连接
+	listener, err := net.Listen("tcp", gatewayServer.Addr)
+	if err != nil {
+		slog.Error("Failed to listen on gateway port", "addr", gatewayServer.Addr, "error", err)
+		os.Exit(1)
+	}
+	listener, err = middleware.ConnLimitListener(listener)
+	if err != nil {
+		slog.Error("Failed to apply per-IP connection limit", "error", err)
+		os.Exit(1)
+	}
+
 	// 启动HTTP服务
 	go func() {
 		slog.Info("🚀 Seckill system gateway service started",
 			"port", cfg.Server.Port,
+			"max_conns_per_ip", cfg.Server.MaxConnsPerIP,
 		)
-		if err := gatewayServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := gatewayServer.Serve(listener); err != nil && err != http.ErrServerClosed {
 			slog.Error("Seckill system gateway service failed", "error", err)
 			os.Exit(1)
 		}
 	}()
 
+	// 启动独立的管理接口服务
+	var adminServer *http.Server
+	if separateAdmin {
+		adminServer = &http.Server{
+			Addr:    fmt.Sprintf("%s:%d", cfg.Server.AdminHost, cfg.Server.AdminPort),
+			Handler: router.InitAdminRouter(),
+		}
+		go func() {
+			slog.Info("Seckill system admin service started",
+				"addr", adminServer.Addr,
+			)
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("Seckill system admin service failed", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	// 监听终止信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	slog.Info("Shutting down server...")
+	shutdownStart := time.Now()
 
 	// 设置优雅关闭超时时间
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -73,14 +134,42 @@ func main() {
 	} else {
 		slog.Info("Gateway gracefully stopped")
 	}
+	if adminServer != nil {
+		if err := adminServer.Shutdown(ctx); err != nil {
+			slog.Error("Admin server forced to shutdown", "error", err)
+		} else {
+			slog.Info("Admin server gracefully stopped")
+		}
+	}
 
-	// 释放所有资源
-	cleanupResources()
+	// 停止消费者、释放所有资源并输出关闭摘要
+	cleanupResources(ctx, shutdownStart)
 	slog.Info("Server exited")
 }
 
-// 关闭所有服务连接
-func cleanupResources() {
+// watchReload 监听SIGHUP信号并热加载日志级别和数据库连接池大小
+// 配置文件无效时记录错误并继续使用当前配置
+func watchReload(path string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		cfg, err := config.ReloadConfig(path)
+		if err != nil {
+			slog.Error("Failed to reload config, keeping current config",
+				"path", path,
+				"error", err,
+			)
+			continue
+		}
+		if err := global.ApplyMySQLPool(cfg.Database); err != nil {
+			slog.Error("Failed to apply reloaded MySQL pool config", "error", err)
+		}
+	}
+}
+
+// 关闭所有服务连接，先停止商品服务的Kafka消费者再关闭Kafka客户端
+func cleanupResources(ctx context.Context, shutdownStart time.Time) {
+	service.GetGoodService().Shutdown(ctx, shutdownStart)
 	global.CloseMysql()
 	global.CloseRedis()
 	global.CloseKafka()
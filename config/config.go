@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"path/filepath"
+	"seckill_system/model"
 	"strings"
 	"time"
 
@@ -14,37 +16,186 @@ import (
 
 // ServerConfig 定义服务器相关配置
 type ServerConfig struct {
-	Port int `yaml:"port"` // 服务监听端口
+	Port      int    `yaml:"port"`       // 服务监听端口
+	AdminPort int    `yaml:"admin_port"` // 管理接口独立监听端口，0表示管理接口与公共接口共用端口
+	AdminHost string `yaml:"admin_host"` // 管理接口监听地址，默认仅本机可访问
+	GoodsETag bool   `yaml:"goods_etag"` // 商品信息接口是否支持ETag条件请求（命中时返回无响应体的304）
+
+	GoodsInfoFields []string `yaml:"goods_info_fields"` // 商品信息接口返回的字段，未配置时使用model.DefaultGoodsInfoFields
+
+	GoodsIdRange GoodsIdRange `yaml:"goods_id_range"` // 有效商品ID范围，超出范围的请求在访问Redis和数据库前被拒绝
+
+	MaxInflightRequests int      `yaml:"max_inflight_requests"` // 公共接口最大并发处理请求数，超出时立即返回503，0表示不限制
+	InflightExemptPaths []string `yaml:"inflight_exempt_paths"` // 不受并发上限约束的路径前缀，未配置时使用默认值
+
+	MaxConnsPerIP        int      `yaml:"max_conns_per_ip"`        // 公共接口单个客户端IP最大并发连接数，超出时在接受连接时直接关闭，0表示不限制
+	ConnLimitExemptCIDRs []string `yaml:"conn_limit_exempt_cidrs"` // 不受连接数上限约束的网段（内部网络），未配置时使用默认值
+}
+
+// DefaultConnLimitExemptCIDRs 默认不受连接数上限约束的网段（回环地址和私有网段）
+var DefaultConnLimitExemptCIDRs = []string{"127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "::1/128", "fc00::/7"}
+
+// DefaultInflightExemptPaths 默认不受并发上限约束的路径前缀（健康检查、监控指标和性能分析）
+var DefaultInflightExemptPaths = []string{"/health", "/ready", "/metrics", "/debug/pprof"}
+
+// DefaultMaxGoodsId 默认允许的最大商品ID
+const DefaultMaxGoodsId = 1000000000
+
+// GoodsIdRange 定义有效商品ID范围（闭区间）
+type GoodsIdRange struct {
+	Min int64 `yaml:"min"` // 最小商品ID
+	Max int64 `yaml:"max"` // 最大商品ID，0表示不限制上限
 }
 
+// Check 校验商品ID是否在有效范围内，Min未配置时至少为1
+func (r GoodsIdRange) Check(goodsId int64) error {
+	min := max(r.Min, 1)
+	if goodsId < min || (r.Max > 0 && goodsId > r.Max) {
+		return fmt.Errorf("goods id %d out of range [%d, %d]", goodsId, min, r.Max)
+	}
+	return nil
+}
+
+// DefaultDBReadTimeoutMs 只读查询单次执行的默认超时时间（毫秒）
+const DefaultDBReadTimeoutMs = 1000
+
+// 数据库连接池默认参数
+const (
+	DefaultDBMaxOpenConns = 100 // 默认最大打开连接数
+	DefaultDBMaxIdleConns = 20  // 默认最大空闲连接数
+)
+
 // MysqlConfig 定义MySQL数据库连接配置
 type MysqlConfig struct {
-	Host     string `yaml:"host"`     // 数据库主机地址
-	Port     int    `yaml:"port"`     // 数据库端口
-	User     string `yaml:"user"`     // 数据库用户名
-	Password string `yaml:"password"` // 数据库密码
-	Name     string `yaml:"name"`     // 数据库名称
+	Host          string `yaml:"host"`            // 数据库主机地址
+	Port          int    `yaml:"port"`            // 数据库端口
+	User          string `yaml:"user"`            // 数据库用户名
+	Password      string `yaml:"password"`        // 数据库密码
+	Name          string `yaml:"name"`            // 数据库名称
+	ReadTimeoutMs int    `yaml:"read_timeout_ms"` // 只读查询单次执行超时（毫秒），超时后按瞬时错误重试，0表示使用默认值
+	MaxOpenConns  int    `yaml:"max_open_conns"`  // 连接池最大打开连接数，0表示使用默认值，支持SIGHUP热加载
+	MaxIdleConns  int    `yaml:"max_idle_conns"`  // 连接池最大空闲连接数，0表示使用默认值，支持SIGHUP热加载
+}
+
+// OpenConns 返回连接池最大打开连接数
+func (mc MysqlConfig) OpenConns() int {
+	if mc.MaxOpenConns == 0 {
+		return DefaultDBMaxOpenConns
+	}
+	return mc.MaxOpenConns
+}
+
+// IdleConns 返回连接池最大空闲连接数
+func (mc MysqlConfig) IdleConns() int {
+	if mc.MaxIdleConns == 0 {
+		return DefaultDBMaxIdleConns
+	}
+	return mc.MaxIdleConns
+}
+
+// ReadTimeout 返回只读查询单次执行的超时时间
+func (mc MysqlConfig) ReadTimeout() time.Duration {
+	if mc.ReadTimeoutMs == 0 {
+		return DefaultDBReadTimeoutMs * time.Millisecond
+	}
+	return time.Duration(mc.ReadTimeoutMs) * time.Millisecond
 }
 
 // RedisConfig 定义Redis集群配置
 type RedisConfig struct {
-	ClusterNodes string `yaml:"cluster_nodes"` // Redis集群节点地址，多个节点用逗号分隔
-	Password     string `yaml:"password"`      // Redis访问密码
+	Mode                 string `yaml:"mode"`                    // 部署模式，cluster或standalone，默认cluster
+	ClusterNodes         string `yaml:"cluster_nodes"`           // Redis集群节点地址，多个节点用逗号分隔；standalone模式下只能配置一个地址
+	Password             string `yaml:"password"`                // Redis访问密码
+	MaxScriptKeys        int    `yaml:"max_script_keys"`         // 单个Lua脚本允许的最大键数量
+	TokenLength          int    `yaml:"token_length"`            // 用户令牌和秒杀令牌的长度
+	TokenVerifyAttempts  int    `yaml:"token_verify_attempts"`   // 用户令牌校验遇到瞬时错误时最多执行次数（含首次），0表示使用默认值
+	TokenVerifyBackoffMs int    `yaml:"token_verify_backoff_ms"` // 用户令牌校验首次重试前的等待时间（毫秒），之后每次翻倍，0表示使用默认值
+}
+
+// Redis部署模式
+const (
+	RedisModeCluster    = "cluster"    // Redis集群（默认）
+	RedisModeStandalone = "standalone" // 单节点Redis，用于开发和测试环境
+)
+
+// ClientMode 返回Redis部署模式，未配置时为cluster
+func (rc RedisConfig) ClientMode() string {
+	if rc.Mode == "" {
+		return RedisModeCluster
+	}
+	return rc.Mode
+}
+
+// 用户令牌校验重试默认值
+const (
+	DefaultTokenVerifyAttempts  = 3  // 默认最多执行3次
+	DefaultTokenVerifyBackoffMs = 10 // 默认首次重试前等待10毫秒
+)
+
+// VerifyAttempts 返回用户令牌校验最多执行次数
+func (rc RedisConfig) VerifyAttempts() int {
+	if rc.TokenVerifyAttempts == 0 {
+		return DefaultTokenVerifyAttempts
+	}
+	return rc.TokenVerifyAttempts
+}
+
+// VerifyBackoff 返回用户令牌校验首次重试前的等待时间
+func (rc RedisConfig) VerifyBackoff() time.Duration {
+	if rc.TokenVerifyBackoffMs == 0 {
+		return DefaultTokenVerifyBackoffMs * time.Millisecond
+	}
+	return time.Duration(rc.TokenVerifyBackoffMs) * time.Millisecond
 }
 
 // KafkaConfig 定义Kafka消息队列配置
 type KafkaConfig struct {
-	Brokers string `yaml:"brokers"`  // Kafka broker地址，多个用逗号分隔
-	Topic   string `yaml:"topic"`    // Kafka主题名称
-	GroupID string `yaml:"group_id"` // 消费者组ID
+	Brokers               string `yaml:"brokers"`                 // Kafka broker地址，多个用逗号分隔
+	Topic                 string `yaml:"topic"`                   // Kafka主题名称
+	GroupID               string `yaml:"group_id"`                // 消费者组ID
+	AuditEnabled          bool   `yaml:"audit_enabled"`           // 是否发送秒杀审计事件
+	AuditTopic            string `yaml:"audit_topic"`             // 审计事件主题名称
+	InitRetries           int    `yaml:"init_retries"`            // 启动时检查broker连通性的最大重试次数
+	FailFast              bool   `yaml:"fail_fast"`               // broker全部不可达时是否终止启动
+	HandlerTimeoutSeconds int    `yaml:"handler_timeout_seconds"` // 单条订单消息的最长处理时间（秒），0表示使用默认值
+	HandlerAttempts       int    `yaml:"handler_attempts"`        // 处理失败的订单消息最多尝试次数，耗尽后转发死信主题，0表示使用默认值
+	DeadLetterTopic       string `yaml:"dead_letter_topic"`       // 无法解析、重试耗尽或处理超时的订单消息转发的死信主题，为空时只记录日志
+	LifecycleTopic        string `yaml:"lifecycle_topic"`         // 活动生命周期事件主题，为空时只记录日志
+	SeckillRequestTopic   string `yaml:"seckill_request_topic"`   // 异步秒杀请求主题，为空时不开启异步秒杀
+}
+
+// DefaultKafkaHandlerTimeoutSeconds 单条订单消息的默认最长处理时间（秒）
+const DefaultKafkaHandlerTimeoutSeconds = 10
+
+// DefaultKafkaHandlerAttempts 处理失败的订单消息默认最多尝试次数
+const DefaultKafkaHandlerAttempts = 3
+
+// MaxHandlerAttempts 返回处理失败的订单消息最多尝试次数
+func (kc KafkaConfig) MaxHandlerAttempts() int {
+	if kc.HandlerAttempts == 0 {
+		return DefaultKafkaHandlerAttempts
+	}
+	return kc.HandlerAttempts
+}
+
+// HandlerTimeout 返回单条订单消息的最长处理时间
+func (kc KafkaConfig) HandlerTimeout() time.Duration {
+	if kc.HandlerTimeoutSeconds == 0 {
+		return DefaultKafkaHandlerTimeoutSeconds * time.Second
+	}
+	return time.Duration(kc.HandlerTimeoutSeconds) * time.Second
 }
 
 // EtcdConfig 定义Etcd配置
 type EtcdConfig struct {
-	Host        string `yaml:"host"`         // Etcd服务地址
+	Host        string `yaml:"host"`         // Etcd服务地址，多个端点用逗号分隔
 	DialTimeout int    `yaml:"dial_timeout"` // 连接超时时间（秒）
 	Username    string `yaml:"username"`     // 认证用户名
 	Password    string `yaml:"password"`     // 认证密码
+	InitRetries int    `yaml:"init_retries"` // 写入默认配置的最大重试次数
+	FailFast    bool   `yaml:"fail_fast"`    // 默认配置写入最终失败时是否终止启动
+	// CacheFallback 是否在Etcd读取失败时使用最近一次成功读取的秒杀开关、限流配置和黑名单结果
+	CacheFallback bool `yaml:"cache_fallback"`
 }
 
 // LogConfig 定义日志配置
@@ -52,35 +203,444 @@ type LogConfig struct {
 	Level    string `yaml:"level"`     // 日志级别
 	FilePath string `yaml:"file_path"` // 日志文件路径
 	MaxSize  int64  `yaml:"max_size"`  // 单个日志文件最大大小（MB）
+
+	Network         LogNetworkConfig `yaml:"network"`           // 额外的网络日志输出
+	TraceBufferSize int              `yaml:"trace_buffer_size"` // 按请求ID缓存日志的环形缓冲区容量（条），0表示不启用
+}
+
+// 网络日志输出目标类型
+const (
+	LogTargetSyslog = "syslog" // 写入syslog，地址为空时写入本机syslog
+	LogTargetTCP    = "tcp"    // 以JSON行写入TCP收集器
+	LogTargetUDP    = "udp"    // 以JSON行写入UDP收集器
+)
+
+// LogNetworkConfig 定义网络日志输出配置，Target为空时不启用
+type LogNetworkConfig struct {
+	Target  string `yaml:"target"`  // 输出目标：syslog、tcp或udp
+	Address string `yaml:"address"` // 收集器地址，如127.0.0.1:514
+	Tag     string `yaml:"tag"`     // syslog标签，默认seckill_system
+}
+
+// 分布式锁后端类型
+const (
+	LockBackendEtcd  = "etcd"  // Etcd租约锁，强一致
+	LockBackendRedis = "redis" // Redis SET NX锁，低延迟
+)
+
+// 分布式锁分类
+const (
+	LockCategorySeckill     = "seckill"     // 秒杀下单和令牌签发的用户级锁
+	LockCategoryPreload     = "preload"     // 库存预加载锁
+	LockCategoryMaintenance = "maintenance" // 清理、补偿等维护任务锁
+)
+
+// DefaultLockNamespace 分布式锁键的默认命名空间
+const DefaultLockNamespace = "/seckill/locks/"
+
+// LockConfig 定义各类分布式锁使用的后端（etcd或redis），未配置时使用etcd
+type LockConfig struct {
+	Seckill     string `yaml:"seckill"`     // 秒杀锁后端
+	Preload     string `yaml:"preload"`     // 预加载锁后端
+	Maintenance string `yaml:"maintenance"` // 维护任务锁后端
+	Namespace   string `yaml:"namespace"`   // 锁键命名空间，避免与其他工具的键冲突，默认/seckill/locks/
+}
+
+// Key 返回带命名空间前缀的锁键，所有分布式锁键都应通过该方法构造
+func (lc LockConfig) Key(name string) string {
+	namespace := lc.Namespace
+	if namespace == "" {
+		namespace = DefaultLockNamespace
+	}
+	if !strings.HasSuffix(namespace, "/") {
+		namespace += "/"
+	}
+	return namespace + name
+}
+
+// Backend 返回指定分类的锁后端，未知分类或未配置时返回etcd
+func (lc LockConfig) Backend(category string) string {
+	var backend string
+	switch category {
+	case LockCategorySeckill:
+		backend = lc.Seckill
+	case LockCategoryPreload:
+		backend = lc.Preload
+	case LockCategoryMaintenance:
+		backend = lc.Maintenance
+	}
+	if backend == "" {
+		return LockBackendEtcd
+	}
+	return backend
+}
+
+// Validate 校验各分类的锁后端配置
+func (lc LockConfig) Validate() error {
+	for _, category := range []string{LockCategorySeckill, LockCategoryPreload, LockCategoryMaintenance} {
+		if backend := lc.Backend(category); backend != LockBackendEtcd && backend != LockBackendRedis {
+			return fmt.Errorf("invalid lock backend %q for %s locks, expected etcd or redis", backend, category)
+		}
+	}
+	if lc.Namespace != "" && !strings.HasPrefix(lc.Namespace, "/") {
+		return fmt.Errorf("invalid lock namespace %q, must start with /", lc.Namespace)
+	}
+	return nil
+}
+
+// 令牌默认有效期
+const (
+	DefaultUserTokenTTL    = 24 * time.Hour   // 用户令牌默认有效期
+	DefaultSeckillTokenTTL = 30 * time.Minute // 秒杀令牌默认有效期
+)
+
+// minTokenTTL 令牌有效期下限，Redis过期时间按秒计算
+const minTokenTTL = time.Second
+
+// TokenConfig 定义令牌有效期配置，取值为Go时长字符串，如"24h"、"30m"
+type TokenConfig struct {
+	UserTokenTTL     time.Duration `yaml:"user_token_ttl"`     // 用户令牌有效期，未配置时为24h
+	SeckillTokenTTL  time.Duration `yaml:"seckill_token_ttl"`  // 秒杀令牌有效期，未配置时为30m
+	TTLJitterPercent int           `yaml:"ttl_jitter_percent"` // 有效期随机抖动百分比，签发时在±该比例内浮动以分散同批令牌的过期时间，0表示不抖动
+}
+
+// maxTokenTTLJitterPercent 令牌有效期抖动百分比上限
+const maxTokenTTLJitterPercent = 50
+
+// UserTTL 返回用户令牌有效期
+func (tc TokenConfig) UserTTL() time.Duration {
+	if tc.UserTokenTTL == 0 {
+		return DefaultUserTokenTTL
+	}
+	return tc.UserTokenTTL
 }
 
+// SeckillTTL 返回秒杀令牌有效期
+func (tc TokenConfig) SeckillTTL() time.Duration {
+	if tc.SeckillTokenTTL == 0 {
+		return DefaultSeckillTokenTTL
+	}
+	return tc.SeckillTokenTTL
+}
+
+// Validate 校验令牌有效期，未配置的有效期使用默认值，其余不得短于1秒；抖动百分比须在0到50之间
+func (tc TokenConfig) Validate() error {
+	if tc.UserTTL() < minTokenTTL {
+		return fmt.Errorf("token user_token_ttl must be at least %s, got %s", minTokenTTL, tc.UserTokenTTL)
+	}
+	if tc.SeckillTTL() < minTokenTTL {
+		return fmt.Errorf("token seckill_token_ttl must be at least %s, got %s", minTokenTTL, tc.SeckillTokenTTL)
+	}
+	if tc.TTLJitterPercent < 0 || tc.TTLJitterPercent > maxTokenTTLJitterPercent {
+		return fmt.Errorf("token ttl_jitter_percent must be between 0 and %d, got %d", maxTokenTTLJitterPercent, tc.TTLJitterPercent)
+	}
+	return nil
+}
+
+// 只读模式响应默认值
+const (
+	DefaultReadOnlyMessage           = "System is in read-only mode for maintenance, please retry later"
+	DefaultReadOnlyRetryAfterSeconds = 60
+)
+
+// ReadOnlyConfig 定义只读模式下写接口的响应，只读模式本身由Etcd键/seckill/config/read_only开关
+type ReadOnlyConfig struct {
+	Message           string `yaml:"message"`             // 写接口被拒绝时返回的提示信息，为空时使用默认值
+	RetryAfterSeconds int    `yaml:"retry_after_seconds"` // Retry-After响应头（秒），0表示使用默认值
+}
+
+// ResponseMessage 返回写接口被拒绝时的提示信息
+func (rc ReadOnlyConfig) ResponseMessage() string {
+	if rc.Message == "" {
+		return DefaultReadOnlyMessage
+	}
+	return rc.Message
+}
+
+// RetryAfter 返回Retry-After响应头的秒数
+func (rc ReadOnlyConfig) RetryAfter() int {
+	if rc.RetryAfterSeconds == 0 {
+		return DefaultReadOnlyRetryAfterSeconds
+	}
+	return rc.RetryAfterSeconds
+}
+
+// PaymentConfig 定义支付处理配置
+type PaymentConfig struct {
+	FailureGraceSeconds int `yaml:"failure_grace_seconds"` // 支付失败后等待支付渠道重试的宽限期（秒），0表示立即取消订单
+}
+
+// FailureGrace 返回支付失败宽限期
+func (pc PaymentConfig) FailureGrace() time.Duration {
+	return time.Duration(pc.FailureGraceSeconds) * time.Second
+}
+
+// 秒杀下单模式
+const (
+	SeckillModeDB    = "db"    // Redis预扣库存后在数据库事务中乐观锁扣减并创建订单（默认）
+	SeckillModeRedis = "redis" // 仅扣减Redis库存，订单暂存Redis后异步批量写入数据库
+)
+
+// DefaultSeckillFlushIntervalSeconds Redis-only模式订单写入数据库的默认间隔（秒）
+const DefaultSeckillFlushIntervalSeconds = 1
+
+// SeckillConfig 定义秒杀下单策略配置
+type SeckillConfig struct {
+	Mode                 string               `yaml:"mode"`                   // 全局下单模式，db或redis，默认db
+	GoodsModes           map[int64]string     `yaml:"goods_modes"`            // 按商品ID覆盖的下单模式
+	FlushIntervalSeconds int                  `yaml:"flush_interval_seconds"` // Redis-only模式订单写入数据库的间隔（秒）
+	ResultCacheSeconds   int                  `yaml:"result_cache_seconds"`   // 秒杀成功结果的缓存时间（秒），窗口内的重复提交直接返回缓存的订单，0表示不缓存
+	GoodsAllowlist       model.GoodsAllowlist `yaml:"goods_allowlist"`        // 秒杀商品准入名单，为空时不限制，Etcd中存在名单时以Etcd为准
+	StockRefreshSeconds  int                  `yaml:"stock_refresh_seconds"`  // 以数据库库存校准进行中活动Redis库存的间隔（秒），0表示不校准
+	MaxTokensPerUser     int                  `yaml:"max_tokens_per_user"`    // 每个用户在一次秒杀活动中最多获取的令牌数，0表示不限制
+	RecheckBlacklist     bool                 `yaml:"recheck_blacklist"`      // 下单时是否重新检查黑名单，拒绝获取令牌后被加入黑名单的用户
+	IdempotencySeconds   int                  `yaml:"idempotency_seconds"`    // 携带Idempotency-Key的秒杀请求结果保留时间（秒），窗口内相同幂等键的重试返回原结果
+	SoldOutCacheSeconds  int                  `yaml:"sold_out_cache_seconds"` // 确认售罄后本实例直接拒绝请求的时间（秒），本实例归还库存时立即失效，0表示不缓存
+	SoldOutLogSample     int                  `yaml:"sold_out_log_sample"`    // 每个商品每N次售罄拒绝记录一条日志，0表示使用默认值，1表示全部记录
+	AsyncResultSeconds   int                  `yaml:"async_result_seconds"`   // 异步秒杀请求处理结果的保留时间（秒），0表示使用默认值
+	ReserveStock         bool                 `yaml:"reserve_stock"`          // 签发秒杀令牌时预占一件Redis库存，兑换时不再扣减，令牌过期或删除后归还
+}
+
+// DefaultSeckillAsyncResultSeconds 异步秒杀请求处理结果的默认保留时间（秒）
+const DefaultSeckillAsyncResultSeconds = 600
+
+// AsyncResultTTL 返回异步秒杀请求处理结果的保留时间，未配置时使用默认值
+func (sc SeckillConfig) AsyncResultTTL() time.Duration {
+	if sc.AsyncResultSeconds <= 0 {
+		return DefaultSeckillAsyncResultSeconds * time.Second
+	}
+	return time.Duration(sc.AsyncResultSeconds) * time.Second
+}
+
+// DefaultSoldOutLogSample 售罄拒绝日志的默认采样间隔
+const DefaultSoldOutLogSample = 100
+
+// SoldOutCacheTTL 返回售罄标记的缓存时间，0表示不缓存
+func (sc SeckillConfig) SoldOutCacheTTL() time.Duration {
+	return time.Duration(sc.SoldOutCacheSeconds) * time.Second
+}
+
+// SoldOutLogEvery 返回售罄拒绝日志的采样间隔，未配置时使用默认值
+func (sc SeckillConfig) SoldOutLogEvery() int64 {
+	if sc.SoldOutLogSample <= 0 {
+		return DefaultSoldOutLogSample
+	}
+	return int64(sc.SoldOutLogSample)
+}
+
+// DefaultSeckillIdempotencySeconds 秒杀请求幂等记录的默认保留时间（秒）
+const DefaultSeckillIdempotencySeconds = 300
+
+// IdempotencyTTL 返回秒杀请求幂等记录的保留时间，未配置时使用默认值
+func (sc SeckillConfig) IdempotencyTTL() time.Duration {
+	if sc.IdempotencySeconds <= 0 {
+		return DefaultSeckillIdempotencySeconds * time.Second
+	}
+	return time.Duration(sc.IdempotencySeconds) * time.Second
+}
+
+// StockRefreshInterval 返回Redis库存校准间隔，0表示不校准
+func (sc SeckillConfig) StockRefreshInterval() time.Duration {
+	return time.Duration(sc.StockRefreshSeconds) * time.Second
+}
+
+// ResultCacheTTL 返回秒杀结果缓存时间，0表示不缓存
+func (sc SeckillConfig) ResultCacheTTL() time.Duration {
+	return time.Duration(sc.ResultCacheSeconds) * time.Second
+}
+
+// ModeFor 返回指定商品使用的下单模式
+func (sc SeckillConfig) ModeFor(goodsId int64) string {
+	if mode, ok := sc.GoodsModes[goodsId]; ok {
+		return mode
+	}
+	if sc.Mode == "" {
+		return SeckillModeDB
+	}
+	return sc.Mode
+}
+
+// UsesRedisMode 是否有商品使用Redis-only模式
+func (sc SeckillConfig) UsesRedisMode() bool {
+	if sc.Mode == SeckillModeRedis {
+		return true
+	}
+	for _, mode := range sc.GoodsModes {
+		if mode == SeckillModeRedis {
+			return true
+		}
+	}
+	return false
+}
+
+// FlushInterval 返回Redis-only模式订单写入数据库的间隔
+func (sc SeckillConfig) FlushInterval() time.Duration {
+	if sc.FlushIntervalSeconds <= 0 {
+		return DefaultSeckillFlushIntervalSeconds * time.Second
+	}
+	return time.Duration(sc.FlushIntervalSeconds) * time.Second
+}
+
+// Validate 校验下单模式配置
+func (sc SeckillConfig) Validate() error {
+	if sc.Mode != "" && sc.Mode != SeckillModeDB && sc.Mode != SeckillModeRedis {
+		return fmt.Errorf("invalid seckill mode %q, must be %q or %q", sc.Mode, SeckillModeDB, SeckillModeRedis)
+	}
+	for goodsId, mode := range sc.GoodsModes {
+		if mode != SeckillModeDB && mode != SeckillModeRedis {
+			return fmt.Errorf("invalid seckill mode %q for goods %d, must be %q or %q", mode, goodsId, SeckillModeDB, SeckillModeRedis)
+		}
+	}
+	if sc.FlushIntervalSeconds < 0 {
+		return fmt.Errorf("seckill flush_interval_seconds must not be negative, got %d", sc.FlushIntervalSeconds)
+	}
+	if sc.ResultCacheSeconds < 0 {
+		return fmt.Errorf("seckill result_cache_seconds must not be negative, got %d", sc.ResultCacheSeconds)
+	}
+	if sc.IdempotencySeconds < 0 {
+		return fmt.Errorf("seckill idempotency_seconds must not be negative, got %d", sc.IdempotencySeconds)
+	}
+	if sc.StockRefreshSeconds < 0 {
+		return fmt.Errorf("seckill stock_refresh_seconds must not be negative, got %d", sc.StockRefreshSeconds)
+	}
+	if sc.MaxTokensPerUser < 0 {
+		return fmt.Errorf("seckill max_tokens_per_user must not be negative, got %d", sc.MaxTokensPerUser)
+	}
+	if sc.SoldOutCacheSeconds < 0 {
+		return fmt.Errorf("seckill sold_out_cache_seconds must not be negative, got %d", sc.SoldOutCacheSeconds)
+	}
+	if sc.SoldOutLogSample < 0 {
+		return fmt.Errorf("seckill sold_out_log_sample must not be negative, got %d", sc.SoldOutLogSample)
+	}
+	if sc.AsyncResultSeconds < 0 {
+		return fmt.Errorf("seckill async_result_seconds must not be negative, got %d", sc.AsyncResultSeconds)
+	}
+	return nil
+}
+
+// 限流后端故障时的处理方式
+const (
+	RateLimitFailOpen   = "open"   // 放行请求
+	RateLimitFailClosed = "closed" // 拒绝请求（默认）
+)
+
+// RateLimitConfig 定义限流配置
+type RateLimitConfig struct {
+	Allowlist   model.RateLimitAllowlist `yaml:"allowlist"`    // 限流豁免名单，Etcd中存在豁免名单时以Etcd为准
+	FailureMode string                   `yaml:"failure_mode"` // Redis限流失败时的处理方式，open或closed，默认closed
+}
+
+// FailOpen 限流后端故障时是否放行请求
+func (rc RateLimitConfig) FailOpen() bool {
+	return rc.FailureMode == RateLimitFailOpen
+}
+
+// Validate 校验限流配置
+func (rc RateLimitConfig) Validate() error {
+	switch rc.FailureMode {
+	case "", RateLimitFailOpen, RateLimitFailClosed:
+		return nil
+	}
+	return fmt.Errorf("invalid rate limit failure_mode %q, must be %q or %q", rc.FailureMode, RateLimitFailOpen, RateLimitFailClosed)
+}
+
+// SeedConfig 定义测试数据生成配置
+type SeedConfig struct {
+	Categories []int64  `yaml:"categories"` // 商品分类ID列表
+	ItemTypes  []string `yaml:"item_types"` // 商品类型标签列表
+	ItemName   string   `yaml:"item_name"`  // 商品名称，用于生成标题和副标题
+	Count      *int     `yaml:"count"`      // 启动时插入的商品数量，未配置时使用默认值，0表示不插入
+
+	PurchaseLimit int64 `yaml:"purchase_limit"` // 生成的秒杀活动中每个用户最多购买的件数，0表示每人限购一件
+}
+
+// DefaultSeedCount 默认插入的测试商品数量
+const DefaultSeedCount = 1000
+
 // Config 聚合所有配置项
 type Config struct {
-	Server      ServerConfig `yaml:"server"`      // 服务器配置
-	Database    MysqlConfig  `yaml:"database"`    // MySQL数据库配置
-	Redis       RedisConfig  `yaml:"redis"`       // Redis配置
-	Kafka       KafkaConfig  `yaml:"kafka"`       // Kafka配置
-	Etcd        EtcdConfig   `yaml:"etcd"`        // Etcd配置
-	Log         LogConfig    `yaml:"log"`         // 日志配置
-	Environment string       `yaml:"environment"` // 运行环境
+	Server      ServerConfig    `yaml:"server"`      // 服务器配置
+	Database    MysqlConfig     `yaml:"database"`    // MySQL数据库配置
+	Redis       RedisConfig     `yaml:"redis"`       // Redis配置
+	Token       TokenConfig     `yaml:"token"`       // 令牌有效期配置
+	ReadOnly    ReadOnlyConfig  `yaml:"read_only"`   // 只读模式响应配置
+	Kafka       KafkaConfig     `yaml:"kafka"`       // Kafka配置
+	Etcd        EtcdConfig      `yaml:"etcd"`        // Etcd配置
+	Log         LogConfig       `yaml:"log"`         // 日志配置
+	RateLimit   RateLimitConfig `yaml:"rate_limit"`  // 限流配置
+	Lock        LockConfig      `yaml:"lock"`        // 分布式锁配置
+	Payment     PaymentConfig   `yaml:"payment"`     // 支付处理配置
+	Seckill     SeckillConfig   `yaml:"seckill"`     // 秒杀下单策略配置
+	Seed        SeedConfig      `yaml:"seed"`        // 测试数据生成配置
+	Time        TimeConfig      `yaml:"time"`        // 时间戳时区和格式配置
+	Environment string          `yaml:"environment"` // 运行环境
 }
 
 // AppConfig 全局配置实例
 var AppConfig *Config
 
-// GetRedisClusterNodes 将Redis集群节点字符串转换为切片
+// activeFileHandler 当前使用的文件日志处理器，未初始化日志时为nil
+var activeFileHandler *rotatingFileHandler
+
+// DefaultSeedConfig 返回默认的测试数据生成配置（图书类目）
+func DefaultSeedConfig() SeedConfig {
+	return SeedConfig{
+		Categories: []int64{1, 2, 3, 4, 5},
+		ItemTypes:  []string{"Computer", "Literature", "Science", "History", "Art"},
+		ItemName:   "Book",
+	}
+}
+
+// ApplyDefaults 为未配置的测试数据生成项填充默认值
+func (sc *SeedConfig) ApplyDefaults() {
+	defaults := DefaultSeedConfig()
+	if len(sc.Categories) == 0 {
+		sc.Categories = defaults.Categories
+	}
+	if len(sc.ItemTypes) == 0 {
+		sc.ItemTypes = defaults.ItemTypes
+	}
+	if sc.ItemName == "" {
+		sc.ItemName = defaults.ItemName
+	}
+	if sc.Count == nil {
+		count := DefaultSeedCount
+		sc.Count = &count
+	}
+}
+
+// GoodsCount 返回启动时插入的商品数量，未配置时返回默认值
+func (sc *SeedConfig) GoodsCount() int {
+	if sc.Count == nil {
+		return DefaultSeedCount
+	}
+	return *sc.Count
+}
+
+// splitAddresses 将逗号分隔的地址列表转换为切片，去除空白并过滤空条目
+func splitAddresses(addresses string) []string {
+	var list []string
+	for _, address := range strings.Split(addresses, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			list = append(list, address)
+		}
+	}
+	return list
+}
+
+// GetRedisClusterNodes 将Redis集群节点字符串转换为切片，去除空白并过滤空节点
 func (rc *RedisConfig) GetRedisClusterNodes() []string {
-	return strings.Split(rc.ClusterNodes, ",")
+	return splitAddresses(rc.ClusterNodes)
 }
 
-// GetKafkaBrokers 将Kafka broker地址字符串转换为切片
+// GetKafkaBrokers 将Kafka broker地址字符串转换为切片，去除空白并过滤空地址
 func (kc *KafkaConfig) GetKafkaBrokers() []string {
-	return strings.Split(kc.Brokers, ",")
+	return splitAddresses(kc.Brokers)
 }
 
-// GetEtcdEndpoints 获取Etcd服务端点（返回切片形式）
+// GetEtcdEndpoints 将Etcd服务地址字符串转换为端点切片，去除空白并过滤空端点
 func (ec *EtcdConfig) GetEtcdEndpoints() []string {
-	return []string{ec.Host}
+	return splitAddresses(ec.Host)
 }
 
 // Validate 验证配置完整性
@@ -89,6 +649,45 @@ func (cfg *Config) Validate() error {
 	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
 		return fmt.Errorf("server port must be between 1 and 65535, got %d", cfg.Server.Port)
 	}
+	if cfg.Server.AdminPort < 0 || cfg.Server.AdminPort > 65535 {
+		return fmt.Errorf("server admin_port must be between 0 and 65535, got %d", cfg.Server.AdminPort)
+	}
+	if cfg.Server.AdminPort == cfg.Server.Port {
+		return fmt.Errorf("server admin_port must differ from port %d", cfg.Server.Port)
+	}
+	if cfg.Server.AdminHost == "" {
+		cfg.Server.AdminHost = "127.0.0.1" // 默认仅监听本机
+	}
+	if cfg.Server.GoodsIdRange.Min <= 0 {
+		cfg.Server.GoodsIdRange.Min = 1 // 商品ID从1开始
+	}
+	if err := model.ValidateGoodsInfoFields(cfg.Server.GoodsInfoFields); err != nil {
+		return fmt.Errorf("server goods_info_fields: %w", err)
+	}
+	if cfg.Server.MaxInflightRequests < 0 {
+		return fmt.Errorf("server max_inflight_requests must not be negative, got %d", cfg.Server.MaxInflightRequests)
+	}
+	if cfg.Server.InflightExemptPaths == nil {
+		cfg.Server.InflightExemptPaths = DefaultInflightExemptPaths
+	}
+	if cfg.Server.MaxConnsPerIP < 0 {
+		return fmt.Errorf("server max_conns_per_ip must not be negative, got %d", cfg.Server.MaxConnsPerIP)
+	}
+	if cfg.Server.ConnLimitExemptCIDRs == nil {
+		cfg.Server.ConnLimitExemptCIDRs = DefaultConnLimitExemptCIDRs
+	}
+	for _, cidr := range cfg.Server.ConnLimitExemptCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("server conn_limit_exempt_cidrs contains invalid CIDR %q: %w", cidr, err)
+		}
+	}
+	if cfg.Server.GoodsIdRange.Max == 0 {
+		cfg.Server.GoodsIdRange.Max = DefaultMaxGoodsId
+	}
+	if cfg.Server.GoodsIdRange.Max < cfg.Server.GoodsIdRange.Min {
+		return fmt.Errorf("server goods_id_range max %d must not be less than min %d",
+			cfg.Server.GoodsIdRange.Max, cfg.Server.GoodsIdRange.Min)
+	}
 
 	// 数据库配置验证：检查必需的主机、端口、用户名和数据库名
 	if cfg.Database.Host == "" {
@@ -103,35 +702,84 @@ func (cfg *Config) Validate() error {
 	if cfg.Database.Name == "" {
 		return fmt.Errorf("database name is required")
 	}
+	if cfg.Database.ReadTimeoutMs < 0 {
+		return fmt.Errorf("database read_timeout_ms must not be negative, got %d", cfg.Database.ReadTimeoutMs)
+	}
+	if cfg.Database.MaxOpenConns < 0 || cfg.Database.MaxIdleConns < 0 {
+		return fmt.Errorf("database max_open_conns and max_idle_conns must not be negative")
+	}
 
 	// Redis配置验证：确保集群节点配置不为空且有效
-	if cfg.Redis.ClusterNodes == "" {
+	if strings.TrimSpace(cfg.Redis.ClusterNodes) == "" {
 		return fmt.Errorf("redis cluster nodes are required")
 	}
-	nodes := cfg.Redis.GetRedisClusterNodes()
-	if len(nodes) == 0 {
-		return fmt.Errorf("no valid redis cluster nodes found")
+	if len(cfg.Redis.GetRedisClusterNodes()) == 0 {
+		return fmt.Errorf("no valid redis cluster nodes found in %q", cfg.Redis.ClusterNodes)
+	}
+	switch cfg.Redis.ClientMode() {
+	case RedisModeCluster:
+	case RedisModeStandalone:
+		if nodes := cfg.Redis.GetRedisClusterNodes(); len(nodes) != 1 {
+			return fmt.Errorf("redis standalone mode requires exactly one address in cluster_nodes, got %d", len(nodes))
+		}
+	default:
+		return fmt.Errorf("invalid redis mode %q, must be %q or %q", cfg.Redis.Mode, RedisModeCluster, RedisModeStandalone)
+	}
+	if cfg.Redis.MaxScriptKeys < 0 {
+		return fmt.Errorf("redis max_script_keys must not be negative, got %d", cfg.Redis.MaxScriptKeys)
+	}
+	if cfg.Redis.MaxScriptKeys == 0 {
+		cfg.Redis.MaxScriptKeys = 4 // 默认单个脚本最多4个键
+	}
+	if cfg.Redis.TokenLength < 0 {
+		return fmt.Errorf("redis token_length must not be negative, got %d", cfg.Redis.TokenLength)
+	}
+	if cfg.Redis.TokenLength == 0 {
+		cfg.Redis.TokenLength = 32 // 默认令牌长度32位
+	}
+	if cfg.Redis.TokenVerifyAttempts < 0 {
+		return fmt.Errorf("redis token_verify_attempts must not be negative, got %d", cfg.Redis.TokenVerifyAttempts)
+	}
+	if cfg.Redis.TokenVerifyBackoffMs < 0 {
+		return fmt.Errorf("redis token_verify_backoff_ms must not be negative, got %d", cfg.Redis.TokenVerifyBackoffMs)
 	}
 
 	// Kafka配置验证：检查broker地址和主题配置
-	if cfg.Kafka.Brokers == "" {
+	if strings.TrimSpace(cfg.Kafka.Brokers) == "" {
 		return fmt.Errorf("kafka brokers are required")
 	}
-	brokers := cfg.Kafka.GetKafkaBrokers()
-	if len(brokers) == 0 {
-		return fmt.Errorf("no valid kafka brokers found")
+	if len(cfg.Kafka.GetKafkaBrokers()) == 0 {
+		return fmt.Errorf("no valid kafka brokers found in %q", cfg.Kafka.Brokers)
 	}
 	if cfg.Kafka.Topic == "" {
 		return fmt.Errorf("kafka topic is required")
 	}
+	if cfg.Kafka.AuditTopic == "" {
+		cfg.Kafka.AuditTopic = "seckill_audit" // 默认审计主题
+	}
+	if cfg.Kafka.InitRetries <= 0 {
+		cfg.Kafka.InitRetries = 3 // 默认重试3次
+	}
+	if cfg.Kafka.HandlerTimeoutSeconds < 0 {
+		return fmt.Errorf("kafka handler_timeout_seconds must not be negative, got %d", cfg.Kafka.HandlerTimeoutSeconds)
+	}
+	if cfg.Kafka.HandlerAttempts < 0 {
+		return fmt.Errorf("kafka handler_attempts must not be negative, got %d", cfg.Kafka.HandlerAttempts)
+	}
 
 	// Etcd配置验证：确保主机地址和超时时间有效
-	if cfg.Etcd.Host == "" {
+	if strings.TrimSpace(cfg.Etcd.Host) == "" {
 		return fmt.Errorf("etcd host is required")
 	}
+	if len(cfg.Etcd.GetEtcdEndpoints()) == 0 {
+		return fmt.Errorf("no valid etcd endpoints found in %q", cfg.Etcd.Host)
+	}
 	if cfg.Etcd.DialTimeout <= 0 {
 		return fmt.Errorf("etcd dial timeout must be positive")
 	}
+	if cfg.Etcd.InitRetries <= 0 {
+		cfg.Etcd.InitRetries = 3 // 默认重试3次
+	}
 
 	// 日志配置验证和默认值设置
 	if cfg.Log.MaxSize <= 0 {
@@ -143,6 +791,61 @@ func (cfg *Config) Validate() error {
 	if cfg.Log.FilePath == "" {
 		cfg.Log.FilePath = "logs" // 默认日志目录为logs
 	}
+	switch cfg.Log.Network.Target {
+	case "", LogTargetSyslog:
+	case LogTargetTCP, LogTargetUDP:
+		if cfg.Log.Network.Address == "" {
+			return fmt.Errorf("log network address is required for %s target", cfg.Log.Network.Target)
+		}
+	default:
+		return fmt.Errorf("invalid log network target %q, expected syslog, tcp or udp", cfg.Log.Network.Target)
+	}
+	if cfg.Log.TraceBufferSize < 0 {
+		return fmt.Errorf("log trace_buffer_size must not be negative, got %d", cfg.Log.TraceBufferSize)
+	}
+	if cfg.Log.Network.Tag == "" {
+		cfg.Log.Network.Tag = "seckill_system" // 默认syslog标签
+	}
+
+	// 支付配置验证
+	if err := cfg.Seckill.Validate(); err != nil {
+		return err
+	}
+	if cfg.Payment.FailureGraceSeconds < 0 {
+		return fmt.Errorf("payment failure_grace_seconds must not be negative, got %d", cfg.Payment.FailureGraceSeconds)
+	}
+
+	// 分布式锁后端验证
+	if err := cfg.Lock.Validate(); err != nil {
+		return err
+	}
+
+	// 限流配置验证
+	if err := cfg.RateLimit.Validate(); err != nil {
+		return err
+	}
+
+	// 时间戳配置验证
+	if err := cfg.Time.Validate(); err != nil {
+		return err
+	}
+
+	// 令牌有效期配置验证
+	if err := cfg.Token.Validate(); err != nil {
+		return err
+	}
+	if cfg.ReadOnly.RetryAfterSeconds < 0 {
+		return fmt.Errorf("read_only retry_after_seconds must not be negative, got %d", cfg.ReadOnly.RetryAfterSeconds)
+	}
+
+	// 测试数据生成配置默认值设置
+	cfg.Seed.ApplyDefaults()
+	if cfg.Seed.GoodsCount() < 0 {
+		return fmt.Errorf("seed count must not be negative, got %d", cfg.Seed.GoodsCount())
+	}
+	if cfg.Seed.PurchaseLimit < 0 {
+		return fmt.Errorf("seed purchase_limit must not be negative, got %d", cfg.Seed.PurchaseLimit)
+	}
 
 	return nil
 }
@@ -155,28 +858,48 @@ func InitConfig(path string) error {
 		return fmt.Errorf("failed to read config file: %v", err)
 	}
 
-	// 解析YAML配置：使用yaml.v3库将YAML内容反序列化为Config结构体
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %v", err)
-	}
-
-	// 配置验证：调用Validate方法检查所有必需配置项
-	if err := cfg.Validate(); err != nil {
-		return fmt.Errorf("config validation failed: %v", err)
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return err
 	}
 
 	// 设置全局配置：将解析后的配置赋值给包级全局变量
-	AppConfig = &cfg
+	AppConfig = cfg
 
 	// 初始化日志系统：设置slog默认logger，包含控制台和文件输出
 	if err := initLogger(); err != nil {
 		return fmt.Errorf("failed to initialize logger: %v", err)
 	}
 
+	logConfigLoaded(path, cfg)
+	return nil
+}
+
+// parseConfig 解析并校验配置文档，YAML是JSON的超集，两种格式均可解析
+func parseConfig(data []byte) (*Config, error) {
+	// 解析YAML配置：使用yaml.v3库将YAML内容反序列化为Config结构体
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %v", err)
+	}
+
+	// 环境变量覆盖：容器部署时通过SECKILL_前缀的环境变量注入密码等配置，优先于文档中的值
+	if err := applyEnvOverrides(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to apply env overrides: %v", err)
+	}
+
+	// 配置验证：调用Validate方法检查所有必需配置项
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %v", err)
+	}
+	return &cfg, nil
+}
+
+// logConfigLoaded 记录配置加载成功日志，source为配置来源
+func logConfigLoaded(source string, cfg *Config) {
 	// 记录配置加载成功日志：使用结构化日志记录关键配置信息
 	slog.Info("Configuration loaded successfully",
-		"path", path,
+		"path", source,
 		"server_port", cfg.Server.Port,
 		"database", fmt.Sprintf("%s@%s:%d/%s",
 			cfg.Database.User,
@@ -184,6 +907,7 @@ func InitConfig(path string) error {
 			cfg.Database.Port,
 			cfg.Database.Name,
 		),
+		"redis_mode", cfg.Redis.ClientMode(),
 		"redis_nodes", cfg.Redis.ClusterNodes,
 		"kafka_brokers", cfg.Kafka.Brokers,
 		"kafka_topic", cfg.Kafka.Topic,
@@ -192,7 +916,6 @@ func InitConfig(path string) error {
 		"log_file_path", cfg.Log.FilePath,
 		"log_max_size", cfg.Log.MaxSize,
 	)
-	return nil
 }
 
 // initLogger 初始化slog日志系统
@@ -200,21 +923,15 @@ func InitConfig(path string) error {
 // 生产环境使用JSON格式，开发环境使用文本格式
 // 支持日志文件轮转，防止单个文件过大
 func initLogger() error {
-	// 设置日志级别：将字符串级别的日志级别转换为slog.Level类型
-	var level slog.Level
-	switch AppConfig.Log.Level {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
+	// 设置日志和接口响应的时间戳时区与格式
+	if err := ApplyTimeConfig(AppConfig.Time); err != nil {
+		return err
 	}
 
+	// 设置日志级别：各处理器共享logLevel，热加载时修改级别无需重建处理器
+	logLevel.Set(parseLogLevel(AppConfig.Log.Level))
+	level := logLevel
+
 	// 创建日志目录：如果目录不存在则递归创建
 	logDir := AppConfig.Log.FilePath
 	if err := os.MkdirAll(logDir, 0755); err != nil {
@@ -231,11 +948,25 @@ func initLogger() error {
 		return fmt.Errorf("failed to create file handler: %v", err)
 	}
 
+	activeFileHandler = fileHandler.(*rotatingFileHandler)
+
 	// 创建控制台日志处理器：用于开发时的实时查看
 	consoleHandler := createConsoleHandler(level)
 
-	// 创建多路处理器：同时向控制台和文件输出日志
-	multiHandler := newMultiHandler(consoleHandler, fileHandler)
+	// 创建多路处理器：同时向控制台和文件输出日志，配置了网络输出时一并发送
+	handlers := []slog.Handler{consoleHandler, fileHandler}
+	if AppConfig.Log.Network.Target != "" {
+		networkHandler, err := NewNetworkLogHandler(AppConfig.Log.Network, level)
+		if err != nil {
+			return fmt.Errorf("failed to create network log handler: %v", err)
+		}
+		handlers = append(handlers, networkHandler)
+	}
+	if AppConfig.Log.TraceBufferSize > 0 {
+		DefaultTraceBuffer = NewTraceBuffer(AppConfig.Log.TraceBufferSize)
+		handlers = append(handlers, NewTraceHandler(DefaultTraceBuffer, level))
+	}
+	multiHandler := newMultiHandler(handlers...)
 
 	// 设置全局默认logger：所有使用slog包的日志调用都会使用这个logger
 	logger := slog.New(multiHandler)
@@ -243,7 +974,7 @@ func initLogger() error {
 
 	// 记录日志系统初始化成功信息
 	slog.Info("Logger initialized successfully",
-		"level", level.String(),
+		"level", level.Level().String(),
 		"environment", AppConfig.Environment,
 		"log_file", logFilePath,
 		"max_size_mb", AppConfig.Log.MaxSize,
@@ -251,6 +982,28 @@ func initLogger() error {
 	return nil
 }
 
+// logLevel 全局日志级别，所有日志处理器共享，可在运行时调整
+var logLevel = new(slog.LevelVar)
+
+// parseLogLevel 将字符串级别的日志级别转换为slog.Level类型，未知级别按info处理
+func parseLogLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// LogLevel 返回当前生效的日志级别
+func LogLevel() slog.Level {
+	return logLevel.Level()
+}
+
 // generateLogFileName 生成基于时间戳的日志文件名
 // 格式：YYYYMMDD-HHMMSS.log，如：20250829-143056.log
 // 这种命名方式可以方便地按时间排序和查找日志文件
@@ -262,7 +1015,7 @@ func generateLogFileName() string {
 // createFileHandler 创建文件日志处理器
 // 打开或创建日志文件，根据环境选择日志格式
 // 包装为rotatingFileHandler以支持文件大小轮转
-func createFileHandler(filePath string, level slog.Level) (slog.Handler, error) {
+func createFileHandler(filePath string, level slog.Leveler) (slog.Handler, error) {
 	// 打开日志文件：使用追加模式，如果文件不存在则创建
 	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
@@ -273,11 +1026,13 @@ func createFileHandler(filePath string, level slog.Level) (slog.Handler, error)
 	var handler slog.Handler
 	if AppConfig.Environment == "production" {
 		handler = slog.NewJSONHandler(file, &slog.HandlerOptions{
-			Level: level,
+			Level:       level,
+			ReplaceAttr: replaceTimeAttr,
 		})
 	} else {
 		handler = slog.NewTextHandler(file, &slog.HandlerOptions{
-			Level: level,
+			Level:       level,
+			ReplaceAttr: replaceTimeAttr,
 		})
 	}
 
@@ -292,14 +1047,16 @@ func createFileHandler(filePath string, level slog.Level) (slog.Handler, error)
 
 // createConsoleHandler 创建控制台日志处理器
 // 根据运行环境选择适当的输出格式
-func createConsoleHandler(level slog.Level) slog.Handler {
+func createConsoleHandler(level slog.Leveler) slog.Handler {
 	if AppConfig.Environment == "production" {
 		return slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			Level: level,
+			Level:       level,
+			ReplaceAttr: replaceTimeAttr,
 		})
 	} else {
 		return slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level: level,
+			Level:       level,
+			ReplaceAttr: replaceTimeAttr,
 		})
 	}
 }
@@ -407,6 +1164,17 @@ func (r *rotatingFileHandler) WithGroup(name string) slog.Handler {
 	}
 }
 
+// FlushLogger 将已写入的日志同步到磁盘，未初始化文件日志时直接返回
+func FlushLogger() error {
+	if activeFileHandler == nil {
+		return nil
+	}
+	if err := activeFileHandler.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync log file: %v", err)
+	}
+	return nil
+}
+
 // rotateIfNeeded 检查并执行日志文件轮转
 // 当当前日志文件大小超过maxSize时：
 // 1. 关闭当前文件
@@ -447,9 +1215,9 @@ func (r *rotatingFileHandler) rotateIfNeeded() error {
 		// 根据原处理器类型创建新的处理器
 		if r.handler != nil {
 			if _, ok := r.handler.(*slog.TextHandler); ok {
-				r.handler = slog.NewTextHandler(newFile, nil)
+				r.handler = slog.NewTextHandler(newFile, &slog.HandlerOptions{ReplaceAttr: replaceTimeAttr})
 			} else if _, ok := r.handler.(*slog.JSONHandler); ok {
-				r.handler = slog.NewJSONHandler(newFile, nil)
+				r.handler = slog.NewJSONHandler(newFile, &slog.HandlerOptions{ReplaceAttr: replaceTimeAttr})
 			}
 		}
 
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvOverridePrefix 配置覆盖环境变量前缀
+// 变量名由前缀和yaml键路径组成，如 database.password 对应 SECKILL_DATABASE_PASSWORD
+const EnvOverridePrefix = "SECKILL"
+
+// maskedValue 敏感配置在日志中的显示值
+const maskedValue = "******"
+
+// applyEnvOverrides 使用环境变量覆盖配置项，未设置的环境变量保留YAML中的值
+// 支持字符串、数值、布尔、时长及逗号分隔的列表
+func applyEnvOverrides(cfg *Config) error {
+	return overrideStruct(reflect.ValueOf(cfg).Elem(), "", EnvOverridePrefix)
+}
+
+// overrideStruct 递归遍历结构体字段，按yaml键名拼接环境变量名并覆盖取值
+func overrideStruct(v reflect.Value, path, envPrefix string) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		fieldPath, envName := name, envPrefix+"_"+strings.ToUpper(name)
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		if field.Type.Kind() == reflect.Struct {
+			if err := overrideStruct(v.Field(i), fieldPath, envName); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := os.LookupEnv(envName)
+		if !ok {
+			continue
+		}
+		if err := setFromEnv(v.Field(i), value); err != nil {
+			return fmt.Errorf("invalid value for %s: %v", envName, err)
+		}
+		shown := value
+		if isSecretField(name) {
+			shown = maskedValue
+		}
+		slog.Info("Config value overridden by environment variable",
+			"field", fieldPath,
+			"env", envName,
+			"value", shown,
+		)
+	}
+	return nil
+}
+
+// setFromEnv 将环境变量取值写入字段，字符串原样写入，列表按逗号拆分，其余类型按YAML标量解析
+func setFromEnv(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+		return nil
+	case reflect.Map:
+		return fmt.Errorf("map fields cannot be overridden")
+	case reflect.Slice:
+		list := reflect.MakeSlice(field.Type(), 0, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			elem := reflect.New(field.Type().Elem())
+			if err := yaml.Unmarshal([]byte(item), elem.Interface()); err != nil {
+				return err
+			}
+			list = reflect.Append(list, elem.Elem())
+		}
+		field.Set(list)
+		return nil
+	default:
+		parsed := reflect.New(field.Type())
+		if err := yaml.Unmarshal([]byte(value), parsed.Interface()); err != nil {
+			return err
+		}
+		field.Set(parsed.Elem())
+		return nil
+	}
+}
+
+// isSecretField 判断配置项是否为敏感信息，敏感信息在日志中脱敏显示
+func isSecretField(name string) bool {
+	return strings.Contains(name, "password") || strings.Contains(name, "secret")
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// etcdConfigTimeout 从Etcd读取配置文档的超时时间
+const etcdConfigTimeout = 5 * time.Second
+
+// InitConfigFromEtcd 从Etcd键加载YAML或JSON格式的配置文档
+// 文档的解析和校验与InitConfig完全一致，本地配置文件仍是默认的加载方式
+func InitConfigFromEtcd(endpoints []string, key string) error {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: etcdConfigTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to etcd for config: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), etcdConfigTimeout)
+	defer cancel()
+	return InitConfigFromKV(ctx, client, key)
+}
+
+// InitConfigFromKV 从Etcd KV中读取配置文档，解析校验后设置全局配置并初始化日志
+func InitConfigFromKV(ctx context.Context, kv clientv3.KV, key string) error {
+	resp, err := kv.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read config from etcd: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return fmt.Errorf("config key %s not found in etcd", key)
+	}
+
+	cfg, err := parseConfig(resp.Kvs[0].Value)
+	if err != nil {
+		return err
+	}
+	AppConfig = cfg
+
+	if err := initLogger(); err != nil {
+		return fmt.Errorf("failed to initialize logger: %v", err)
+	}
+	logConfigLoaded("etcd:"+key, cfg)
+	return nil
+}
+
+// WatchConfigFromEtcd 监听Etcd中的配置文档，变更通过校验后替换全局配置并回调onChange
+// 校验失败的文档只记录日志，继续使用当前配置；ctx取消后停止监听
+// 已在启动时读取配置的组件不会自动生效，需要在onChange中自行处理
+func WatchConfigFromEtcd(ctx context.Context, endpoints []string, key string, onChange func(*Config)) error {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: etcdConfigTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to etcd for config watch: %v", err)
+	}
+	go func() {
+		defer client.Close()
+		WatchConfig(ctx, client, key, onChange)
+	}()
+	return nil
+}
+
+// WatchConfig 使用指定的Watcher监听配置文档变更，阻塞直到ctx取消或监听通道关闭
+func WatchConfig(ctx context.Context, watcher clientv3.Watcher, key string, onChange func(*Config)) {
+	for resp := range watcher.Watch(ctx, key) {
+		if err := resp.Err(); err != nil {
+			slog.Error("Config watch failed",
+				"key", key,
+				"error", err,
+			)
+			continue
+		}
+		for _, event := range resp.Events {
+			if event.Type != clientv3.EventTypePut {
+				slog.Warn("Config document deleted from etcd, keeping current config",
+					"key", key,
+				)
+				continue
+			}
+			cfg, err := parseConfig(event.Kv.Value)
+			if err != nil {
+				slog.Error("Invalid config document in etcd, keeping current config",
+					"key", key,
+					"error", err,
+				)
+				continue
+			}
+			AppConfig = cfg
+			if err := ApplyTimeConfig(cfg.Time); err != nil {
+				slog.Error("Failed to apply reloaded time config", "error", err)
+			}
+			slog.Info("Configuration reloaded from etcd",
+				"key", key,
+				"revision", event.Kv.ModRevision,
+			)
+			if onChange != nil {
+				onChange(cfg)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"log/syslog"
+	"net"
+	"sync"
+	"time"
+)
+
+// 网络日志连接相关常量
+const (
+	logNetworkDialTimeout  = 2 * time.Second  // 建立连接超时时间
+	logNetworkWriteTimeout = 2 * time.Second  // 单条日志写入超时时间
+	logNetworkRetryDelay   = 10 * time.Second // 连接失败后再次尝试连接的间隔
+)
+
+// NewNetworkLogHandler 创建网络日志处理器，日志以JSON格式写入syslog或TCP/UDP收集器
+// 连接在首次写入时建立，连接失败或写入失败时丢弃日志并在重试间隔后重连，不会阻塞或中断日志记录
+func NewNetworkLogHandler(nc LogNetworkConfig, level slog.Leveler) (slog.Handler, error) {
+	var dial func() (io.WriteCloser, error)
+	switch nc.Target {
+	case LogTargetSyslog:
+		network := ""
+		if nc.Address != "" {
+			network = "udp" // 远程syslog使用UDP
+		}
+		dial = func() (io.WriteCloser, error) {
+			return syslog.Dial(network, nc.Address, syslog.LOG_INFO|syslog.LOG_LOCAL0, nc.Tag)
+		}
+	case LogTargetTCP, LogTargetUDP:
+		dial = func() (io.WriteCloser, error) {
+			return net.DialTimeout(nc.Target, nc.Address, logNetworkDialTimeout)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported log network target %q", nc.Target)
+	}
+
+	writer := &networkLogWriter{dial: dial, target: nc.Target, address: nc.Address}
+	return slog.NewJSONHandler(writer, &slog.HandlerOptions{Level: level, ReplaceAttr: replaceTimeAttr}), nil
+}
+
+// networkLogWriter 带自动重连的网络日志写入器
+// slog的JSON处理器每条日志只调用一次Write，因此每次Write对应一条完整日志
+type networkLogWriter struct {
+	mu      sync.Mutex
+	dial    func() (io.WriteCloser, error) // 建立连接
+	conn    io.WriteCloser                 // 当前连接，未连接时为nil
+	retryAt time.Time                      // 下次允许重连的时间
+	target  string                         // 输出目标，用于错误信息
+	address string                         // 收集器地址，用于错误信息
+}
+
+// Write 写入一条日志，未连接且处于重试间隔内时直接丢弃
+func (w *networkLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		if time.Now().Before(w.retryAt) {
+			return 0, fmt.Errorf("log %s %s unavailable, record dropped", w.target, w.address)
+		}
+		conn, err := w.dial()
+		if err != nil {
+			w.retryAt = time.Now().Add(logNetworkRetryDelay)
+			return 0, fmt.Errorf("dial log %s %s failed: %v", w.target, w.address, err)
+		}
+		w.conn = conn
+	}
+
+	if deadliner, ok := w.conn.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		deadliner.SetWriteDeadline(time.Now().Add(logNetworkWriteTimeout))
+	}
+	n, err := w.conn.Write(p)
+	if err != nil {
+		// 写入失败时关闭连接，重试间隔后重新连接
+		w.conn.Close()
+		w.conn = nil
+		w.retryAt = time.Now().Add(logNetworkRetryDelay)
+		return n, fmt.Errorf("write log %s %s failed: %v", w.target, w.address, err)
+	}
+	return n, nil
+}
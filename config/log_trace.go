@@ -0,0 +1,161 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"seckill_system/model"
+	"sync"
+)
+
+// TraceAttrRequestId 日志中携带请求ID的属性名
+const TraceAttrRequestId = "request_id"
+
+// requestIdContextKey 请求ID在context中的键类型
+type requestIdContextKey struct{}
+
+// DefaultTraceBuffer 全局请求日志环形缓冲区，未启用时为nil
+var DefaultTraceBuffer *TraceBuffer
+
+// WithRequestId 返回携带请求ID的context
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, requestId)
+}
+
+// RequestIdFromContext 获取context中的请求ID，不存在时返回空字符串
+func RequestIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestId, _ := ctx.Value(requestIdContextKey{}).(string)
+	return requestId
+}
+
+// traceEntry 环形缓冲区中的一条日志
+type traceEntry struct {
+	requestId string            // 请求ID
+	record    model.TraceRecord // 日志记录
+}
+
+// TraceBuffer 固定容量的请求日志环形缓冲区，写满后覆盖最早的日志
+type TraceBuffer struct {
+	mu      sync.Mutex
+	entries []traceEntry // 环形存储
+	next    int          // 下一条日志的写入位置
+	full    bool         // 是否已写满一轮
+}
+
+// NewTraceBuffer 创建容量为size的请求日志缓冲区
+func NewTraceBuffer(size int) *TraceBuffer {
+	return &TraceBuffer{entries: make([]traceEntry, size)}
+}
+
+// Add 写入一条请求日志
+func (b *TraceBuffer) Add(requestId string, record model.TraceRecord) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.entries) == 0 {
+		return
+	}
+	b.entries[b.next] = traceEntry{requestId: requestId, record: record}
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Get 按写入顺序返回指定请求ID仍在缓冲区中的日志
+func (b *TraceBuffer) Get(requestId string) []model.TraceRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	start, count := 0, b.next
+	if b.full {
+		start, count = b.next, len(b.entries)
+	}
+	records := []model.TraceRecord{}
+	for i := 0; i < count; i++ {
+		entry := b.entries[(start+i)%len(b.entries)]
+		if entry.requestId == requestId {
+			records = append(records, entry.record)
+		}
+	}
+	return records
+}
+
+// NewTraceHandler 创建将带请求ID的日志写入缓冲区的处理器
+// 请求ID取自日志调用传入的context（slog.InfoContext等），或日志属性request_id；没有请求ID的日志被忽略
+func NewTraceHandler(buffer *TraceBuffer, level slog.Leveler) slog.Handler {
+	return &traceHandler{buffer: buffer, level: level}
+}
+
+// traceHandler 请求日志缓冲处理器
+type traceHandler struct {
+	buffer    *TraceBuffer // 日志缓冲区
+	level     slog.Leveler // 最低日志级别
+	attrs     []slog.Attr  // WithAttrs附加的属性，键已带分组前缀
+	group     string       // WithGroup设置的属性前缀
+	requestId string       // WithAttrs附加的请求ID
+}
+
+// Enabled 判断日志级别是否需要记录
+func (h *traceHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle 将带请求ID的日志写入缓冲区
+func (h *traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	requestId := RequestIdFromContext(ctx)
+	if requestId == "" {
+		requestId = h.requestId
+	}
+	attrs := make(map[string]any, len(h.attrs)+record.NumAttrs())
+	for _, attr := range h.attrs {
+		attrs[attr.Key] = attr.Value.Resolve().Any()
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == TraceAttrRequestId && h.group == "" {
+			if requestId == "" {
+				requestId = attr.Value.String()
+			}
+			return true
+		}
+		attrs[h.group+attr.Key] = attr.Value.Resolve().Any()
+		return true
+	})
+
+	if requestId == "" {
+		return nil
+	}
+	h.buffer.Add(requestId, model.TraceRecord{
+		Time:    model.NewTimestamp(record.Time),
+		Level:   record.Level.String(),
+		Message: record.Message,
+		Attrs:   attrs,
+	})
+	return nil
+}
+
+// WithAttrs 创建附加属性的处理器
+// 属性中的request_id（未分组时）作为后续日志的请求ID
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	next.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, attr := range attrs {
+		if attr.Key == TraceAttrRequestId && h.group == "" {
+			next.requestId = attr.Value.String()
+			continue
+		}
+		next.attrs = append(next.attrs, slog.Attr{Key: h.group + attr.Key, Value: attr.Value})
+	}
+	return &next
+}
+
+// WithGroup 创建属性带分组前缀的处理器
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := *h
+	next.group = h.group + name + "."
+	return &next
+}
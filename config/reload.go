@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ReloadConfig 重新读取并校验配置文件，仅热加载日志级别和数据库连接池大小
+// 其余发生变化的配置项需要重启才能生效，记录日志后保持当前值
+// 读取或校验失败时返回错误，继续使用当前配置
+// 数据库连接池由调用方根据返回的配置应用到已建立的连接上
+func ReloadConfig(path string) (*Config, error) {
+	if AppConfig == nil {
+		return nil, fmt.Errorf("config not initialized")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	next, err := parseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// 以当前配置为基础只替换可热加载的字段，避免已启动组件与全局配置不一致
+	cfg := *AppConfig
+	cfg.Log.Level = next.Log.Level
+	cfg.Database.MaxOpenConns = next.Database.MaxOpenConns
+	cfg.Database.MaxIdleConns = next.Database.MaxIdleConns
+
+	for _, field := range diffFields(reflect.ValueOf(cfg), reflect.ValueOf(*next), "") {
+		slog.Warn("Config change ignored, requires restart",
+			"path", path,
+			"field", field,
+		)
+	}
+
+	logLevel.Set(parseLogLevel(cfg.Log.Level))
+	AppConfig = &cfg
+	slog.Info("Configuration reloaded",
+		"path", path,
+		"log_level", logLevel.Level().String(),
+		"db_max_open_conns", cfg.Database.OpenConns(),
+		"db_max_idle_conns", cfg.Database.IdleConns(),
+	)
+	return &cfg, nil
+}
+
+// diffFields 比较两个同类型配置结构体，返回取值不同的字段路径（按yaml键名，如server.port）
+func diffFields(old, next reflect.Value, prefix string) []string {
+	var fields []string
+	for i := 0; i < old.NumField(); i++ {
+		field := old.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if field.Type.Kind() == reflect.Struct {
+			fields = append(fields, diffFields(old.Field(i), next.Field(i), name)...)
+			continue
+		}
+		if !reflect.DeepEqual(old.Field(i).Interface(), next.Field(i).Interface()) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
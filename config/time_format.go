@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"seckill_system/model"
+	"time"
+)
+
+// 默认时间戳配置
+const (
+	DefaultTimezone   = "UTC"        // 默认时区
+	DefaultTimeFormat = time.RFC3339 // 默认时间戳格式
+)
+
+// TimeConfig 定义日志和接口响应中时间戳的时区与格式
+type TimeConfig struct {
+	Timezone string `yaml:"timezone"` // IANA时区名称，如UTC、Asia/Shanghai，默认UTC
+	Format   string `yaml:"format"`   // Go时间格式布局，默认RFC3339
+}
+
+// Validate 校验时区和格式配置，未配置的项填充默认值
+func (tc *TimeConfig) Validate() error {
+	if tc.Timezone == "" {
+		tc.Timezone = DefaultTimezone
+	}
+	if tc.Format == "" {
+		tc.Format = DefaultTimeFormat
+	}
+	if _, err := time.LoadLocation(tc.Timezone); err != nil {
+		return fmt.Errorf("invalid time timezone %q: %v", tc.Timezone, err)
+	}
+	return nil
+}
+
+// ApplyTimeConfig 设置全局时间戳时区和格式，日志和model.Timestamp共用该设置
+func ApplyTimeConfig(tc TimeConfig) error {
+	if err := tc.Validate(); err != nil {
+		return err
+	}
+	location, _ := time.LoadLocation(tc.Timezone)
+	model.SetTimestampFormat(location, tc.Format)
+	return nil
+}
+
+// TimeLocation 返回配置的时区
+func TimeLocation() *time.Location {
+	return model.TimestampLocation()
+}
+
+// FormatTime 按配置的时区和格式格式化时间
+func FormatTime(t time.Time) string {
+	return model.FormatTimestamp(t)
+}
+
+// replaceTimeAttr slog的ReplaceAttr回调，将日志时间和时间类型属性按配置格式输出
+func replaceTimeAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindTime {
+		a.Value = slog.StringValue(FormatTime(a.Value.Time()))
+	}
+	return a
+}
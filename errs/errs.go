@@ -0,0 +1,133 @@
+// Package errs 定义在repository、handler、service之间共享的结构化错误
+// 各层使用%w包装这些错误，调用方通过errors.Is判断错误类别，通过errors.As获取错误码
+package errs
+
+import "errors"
+
+// registry 错误码到错误值的映射，用于按错误码重建错误
+var registry = make(map[string]*Error)
+
+// Error 带错误码和所属类别的业务错误
+// 同一个错误值在各处复用，errors.Is按指针判断；错误同时被视为其所有上级类别
+type Error struct {
+	Code    string // 机器可读的错误码
+	Message string // 错误信息
+	parent  *Error // 所属上级类别，为nil时为顶层类别
+}
+
+// Error 返回错误信息
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is 判断target是否为该错误的上级类别，使errors.Is(ErrTokenExpired, ErrInvalidToken)成立
+func (e *Error) Is(target error) bool {
+	for p := e.parent; p != nil; p = p.parent {
+		if p == target {
+			return true
+		}
+	}
+	return false
+}
+
+// newError 创建属于parent类别的错误
+func newError(parent *Error, code, message string) *Error {
+	e := &Error{Code: code, Message: message, parent: parent}
+	registry[code] = e
+	return e
+}
+
+// 错误类别，用于控制器映射HTTP状态码
+var (
+	ErrNotFound         = newError(nil, "not_found", "resource not found")                            // 资源不存在
+	ErrForbidden        = newError(nil, "forbidden", "operation not allowed")                         // 当前不允许执行该操作
+	ErrInvalidToken     = newError(nil, "invalid_token", "invalid token")                             // 令牌无效
+	ErrSoldOut          = newError(nil, "sold_out", "goods sold out")                                 // 库存不足
+	ErrRateLimited      = newError(nil, "rate_limited", "too many requests")                          // 超出限流
+	ErrSystemBusy       = newError(nil, "system_busy", "system busy, please try again")               // 系统繁忙，可稍后重试
+	ErrAlreadyPurchased = newError(nil, "already_purchased", "user has already purchased this goods") // 用户已抢到该商品，不能重复下单
+)
+
+// 资源不存在错误
+var (
+	ErrGoodsNotFound          = newError(ErrNotFound, "goods_not_found", "goods not found")                     // 商品不存在
+	ErrPromotionNotFound      = newError(ErrNotFound, "promotion_not_found", "promotion not found")             // 商品没有对应的秒杀促销活动
+	ErrStockNotFound          = newError(ErrNotFound, "stock_not_found", "goods stock not found")               // Redis中不存在库存
+	ErrOrderNotFound          = newError(ErrNotFound, "order_not_found", "order not found")                     // 订单不存在
+	ErrSeckillRequestNotFound = newError(ErrNotFound, "seckill_request_not_found", "seckill request not found") // 异步秒杀请求不存在或结果已过期
+)
+
+// 不允许操作错误
+var (
+	ErrSeckillDisabled       = newError(ErrForbidden, "seckill_disabled", "seckill system is temporarily disabled")               // 秒杀系统已关闭
+	ErrBlacklisted           = newError(ErrForbidden, "blacklisted", "user is in blacklist")                                      // 用户在黑名单中
+	ErrActivityNotAvailable  = newError(ErrForbidden, "activity_not_available", "seckill activity is not available")              // 不在秒杀活动时间内
+	ErrSeckillNotStarted     = newError(ErrActivityNotAvailable, "seckill_not_started", "seckill activity has not started")       // 秒杀活动尚未开始
+	ErrSeckillEnded          = newError(ErrActivityNotAvailable, "seckill_ended", "seckill activity has ended")                   // 秒杀活动已结束
+	ErrGoodsNotApproved      = newError(ErrForbidden, "goods_not_approved", "goods is not approved for seckill")                  // 商品不在秒杀准入名单中
+	ErrTokenQuotaExceeded    = newError(ErrForbidden, "token_quota_exceeded", "seckill token limit reached for this activity")    // 用户在本次活动中获取秒杀令牌的次数已达上限
+	ErrStaleStockGeneration  = newError(ErrForbidden, "stale_stock_generation", "stock generation is stale")                      // 扣减请求属于已重新开始的上一轮活动
+	ErrInvalidPromotionCount = newError(ErrForbidden, "invalid_promotion_count", "promotion count must not be negative")          // 促销库存为负数，拒绝预加载
+	ErrIdempotencyKeyReused  = newError(ErrForbidden, "idempotency_key_reused", "idempotency key was used for a different goods") // 幂等键已用于其他商品的秒杀请求
+	ErrAsyncSeckillDisabled  = newError(ErrForbidden, "async_seckill_disabled", "async seckill is not enabled")                   // 未配置异步秒杀主题
+)
+
+// 订单操作不允许错误
+var (
+	ErrOrderNotOwned          = newError(ErrForbidden, "order_not_owned", "order does not belong to user")           // 订单不属于当前用户
+	ErrInvalidOrderTransition = newError(ErrForbidden, "invalid_order_transition", "invalid order state transition") // 订单状态迁移不合法，如已支付的订单被取消
+)
+
+// 系统繁忙错误
+var (
+	ErrRateLimiterUnavailable = newError(ErrSystemBusy, "rate_limiter_unavailable", "rate limiter unavailable, please try again")          // 限流后端故障且配置为拒绝请求
+	ErrReadOnly               = newError(ErrSystemBusy, "read_only", "system is in read-only mode")                                        // 系统处于只读维护模式，写操作被拒绝
+	ErrIdempotencyInProgress  = newError(ErrSystemBusy, "idempotency_in_progress", "request with the same idempotency key is in progress") // 相同幂等键的请求仍在处理中
+)
+
+// 重复购买错误
+var (
+	ErrPurchaseLimitReached = newError(ErrAlreadyPurchased, "purchase_limit_reached", "purchase limit reached for this goods") // 用户购买件数已达活动限购数量
+)
+
+// 令牌无效错误，用户令牌和秒杀令牌共用
+var (
+	ErrTokenNotFound  = newError(ErrInvalidToken, "token_not_found", "token not found") // 令牌不存在或已被消费
+	ErrTokenExpired   = newError(ErrInvalidToken, "token_expired", "token expired")     // 令牌已过期
+	ErrTokenMismatch  = newError(ErrInvalidToken, "token_mismatch", "token mismatch")   // 令牌与用户或商品不匹配
+	ErrTokenMalformed = newError(ErrInvalidToken, "token_malformed", "token malformed") // 令牌长度或字符集不合法
+
+)
+
+// replayedError 按错误码重建的错误，保留原始错误信息并属于错误码对应的类别
+type replayedError struct {
+	message string
+	cause   *Error
+}
+
+// Error 返回原始错误信息
+func (e *replayedError) Error() string {
+	return e.message
+}
+
+// Unwrap 返回错误码对应的错误，使errors.Is和errors.As按原始错误判断
+func (e *replayedError) Unwrap() error {
+	return e.cause
+}
+
+// Replay 按错误码和错误信息重建之前返回过的错误，错误码未知时只保留错误信息
+func Replay(code, message string) error {
+	if e, ok := registry[code]; ok {
+		return &replayedError{message: message, cause: e}
+	}
+	return errors.New(message)
+}
+
+// Code 返回错误链中第一个结构化错误的错误码，不存在时返回空字符串
+func Code(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
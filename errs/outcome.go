@@ -0,0 +1,56 @@
+package errs
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// 请求结果分类，用于区分预期的业务结果和基础设施故障，分别记录日志级别和统计指标
+const (
+	OutcomeSuccess          = "success"           // 成功
+	OutcomeSoldOut          = "sold_out"          // 库存不足
+	OutcomeRateLimited      = "rate_limited"      // 超出限流
+	OutcomeInvalidToken     = "invalid_token"     // 令牌无效
+	OutcomeForbidden        = "forbidden"         // 当前不允许执行该操作
+	OutcomeNotFound         = "not_found"         // 资源不存在
+	OutcomeSystemBusy       = "system_busy"       // 系统繁忙，可稍后重试
+	OutcomeAlreadyPurchased = "already_purchased" // 用户已抢到该商品
+	OutcomeError            = "error"             // 未分类的错误，视为基础设施故障
+)
+
+// Outcome 返回错误对应的结果分类，err为nil时返回OutcomeSuccess
+func Outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, ErrSoldOut):
+		return OutcomeSoldOut
+	case errors.Is(err, ErrAlreadyPurchased):
+		return OutcomeAlreadyPurchased
+	case errors.Is(err, ErrRateLimited):
+		return OutcomeRateLimited
+	case errors.Is(err, ErrInvalidToken):
+		return OutcomeInvalidToken
+	case errors.Is(err, ErrForbidden):
+		return OutcomeForbidden
+	case errors.Is(err, ErrNotFound):
+		return OutcomeNotFound
+	case errors.Is(err, ErrSystemBusy):
+		return OutcomeSystemBusy
+	default:
+		return OutcomeError
+	}
+}
+
+// LogLevel 返回记录该结果时使用的日志级别
+// 成功、售罄和重复购买是正常业务结果记为Info，其他已分类的业务结果记为Warn，只有未分类的故障记为Error
+func LogLevel(err error) slog.Level {
+	switch Outcome(err) {
+	case OutcomeSuccess, OutcomeSoldOut, OutcomeAlreadyPurchased:
+		return slog.LevelInfo
+	case OutcomeError:
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
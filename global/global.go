@@ -8,6 +8,8 @@ import (
 	"os"
 	"seckill_system/config"
 	"seckill_system/model"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -20,20 +22,40 @@ import (
 
 // 全局变量定义
 var (
-	DBClient           *gorm.DB             // MySQL数据库客户端
-	RedisClusterClient *redis.ClusterClient // Redis集群客户端
-	KafkaWriter        *kafka.Writer        // Kafka生产者
-	KafkaReader        *kafka.Reader        // Kafka消费者
-	EtcdClient         *clientv3.Client     // Etcd客户端
-	BookStockCount     = 100                // 默认书籍库存数量
+	DBClient                  *gorm.DB              // MySQL数据库客户端
+	DBReadTimeout             time.Duration         // 只读查询单次执行超时（0表示不限制）
+	RedisClient               redis.UniversalClient // Redis客户端，集群模式为*redis.ClusterClient，单节点模式为*redis.Client
+	KafkaWriter               *kafka.Writer         // Kafka生产者
+	KafkaReader               *kafka.Reader         // Kafka消费者
+	KafkaAuditWriter          *kafka.Writer         // Kafka审计事件生产者（未开启审计时为nil）
+	KafkaDLQWriter            *kafka.Writer         // Kafka死信消息生产者（未配置死信主题时为nil）
+	KafkaLifecycleWriter      *kafka.Writer         // Kafka活动生命周期事件生产者（未配置生命周期主题时为nil）
+	KafkaSeckillRequestWriter *kafka.Writer         // Kafka异步秒杀请求生产者（未配置异步秒杀主题时为nil）
+	KafkaSeckillRequestReader *kafka.Reader         // Kafka异步秒杀请求消费者（未配置异步秒杀主题时为nil）
+	KafkaHandlerTimeout       time.Duration         // 单条订单消息的最长处理时间（0表示不限制）
+	KafkaHandlerAttempts      int                   // 处理失败的订单消息最多尝试次数
+	EtcdClient                *clientv3.Client      // Etcd客户端
+	EtcdCacheFallback         bool                  // Etcd读取失败时是否使用最近一次成功读取的值
+	RedisMaxScriptKeys        int                   // 单个Lua脚本允许的最大键数量（0表示使用默认值）
+	RedisTokenLength          int                   // 令牌长度（0表示使用默认值）
+	UserTokenTTL              time.Duration         // 用户令牌有效期（0表示使用默认值）
+	SeckillTokenTTL           time.Duration         // 秒杀令牌有效期（0表示使用默认值）
+	TokenTTLJitter            int                   // 令牌有效期随机抖动百分比（0表示不抖动）
+	TokenVerifyAttempts       int                   // 用户令牌校验最多执行次数（0表示使用默认值）
+	TokenVerifyBackoff        time.Duration         // 用户令牌校验首次重试前的等待时间（0表示使用默认值）
+	BookStockCount            = 100                 // 默认书籍库存数量
 )
 
 // Etcd相关配置键常量
 const (
-	EtcdKeySeckillEnabled = "/seckill/config/enabled"       // 秒杀开关配置键
-	EtcdKeyRateLimit      = "/seckill/config/rate_limit"    // 限流配置键
-	EtcdKeyStockPreload   = "/seckill/config/stock_preload" // 库存预加载配置键
-	EtcdKeyBlacklist      = "/seckill/blacklist/"           // 用户黑名单前缀
+	EtcdKeySeckillEnabled     = "/seckill/config/enabled"              // 秒杀开关配置键
+	EtcdKeyRateLimit          = "/seckill/config/rate_limit"           // 限流配置键
+	EtcdKeyIPRateLimit        = "/seckill/config/ip_rate_limit"        // 客户端IP限流配置键
+	EtcdKeyReadOnly           = "/seckill/config/read_only"            // 只读模式开关键，值为true时拒绝写接口
+	EtcdKeyStockPreload       = "/seckill/config/stock_preload"        // 库存预加载配置键
+	EtcdKeyRateLimitAllowlist = "/seckill/config/rate_limit_allowlist" // 限流豁免名单配置键（JSON）
+	EtcdKeyGoodsAllowlist     = "/seckill/config/goods_allowlist"      // 秒杀商品准入名单配置键（JSON）
+	EtcdKeyBlacklist          = "/seckill/blacklist/"                  // 用户黑名单前缀
 )
 
 // InitMySQL 初始化MySQL数据库连接
@@ -66,14 +88,16 @@ func InitMySQL() {
 	}
 
 	// 设置连接池参数
-	sqlDB.SetMaxOpenConns(100)                // 最大打开连接数
-	sqlDB.SetMaxIdleConns(20)                 // 最大空闲连接数
+	sqlDB.SetMaxOpenConns(cfg.OpenConns())    // 最大打开连接数
+	sqlDB.SetMaxIdleConns(cfg.IdleConns())    // 最大空闲连接数
 	sqlDB.SetConnMaxLifetime(3 * time.Minute) // 连接最大生命周期
+	DBReadTimeout = cfg.ReadTimeout()
 
 	slog.Info("MySQL connection established successfully",
 		"host", cfg.Host,
 		"port", cfg.Port,
 		"database", cfg.Name,
+		"read_timeout", DBReadTimeout,
 	)
 
 	// 初始化数据库表结构和测试数据
@@ -86,28 +110,53 @@ func InitMySQL() {
 // InitRedis 初始化Redis集群连接
 func InitRedis() {
 	cfg := config.AppConfig.Redis
-	nodes := cfg.GetRedisClusterNodes() // 获取Redis集群节点列表
-
-	// 创建Redis集群客户端
-	RedisClusterClient = redis.NewClusterClient(&redis.ClusterOptions{
-		Addrs:        nodes,        // 集群节点地址
-		Password:     cfg.Password, // 访问密码
-		PoolSize:     1000,         // 连接池大小
-		MinIdleConns: 10,           // 最小空闲连接数
-	})
+	nodes := cfg.GetRedisClusterNodes() // 获取Redis节点列表
+	mode := cfg.ClientMode()
+
+	if mode == config.RedisModeStandalone {
+		// 单节点模式，配置校验保证只有一个地址
+		RedisClient = redis.NewClient(&redis.Options{
+			Addr:         nodes[0],     // 节点地址
+			Password:     cfg.Password, // 访问密码
+			PoolSize:     1000,         // 连接池大小
+			MinIdleConns: 10,           // 最小空闲连接数
+		})
+	} else {
+		// 创建Redis集群客户端
+		RedisClient = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        nodes,        // 集群节点地址
+			Password:     cfg.Password, // 访问密码
+			PoolSize:     1000,         // 连接池大小
+			MinIdleConns: 10,           // 最小空闲连接数
+		})
+	}
+	RedisMaxScriptKeys = cfg.MaxScriptKeys
+	RedisTokenLength = cfg.TokenLength
+	UserTokenTTL = config.AppConfig.Token.UserTTL()
+	SeckillTokenTTL = config.AppConfig.Token.SeckillTTL()
+	TokenTTLJitter = config.AppConfig.Token.TTLJitterPercent
+	TokenVerifyAttempts = cfg.VerifyAttempts()
+	TokenVerifyBackoff = cfg.VerifyBackoff()
 
 	// 测试连接是否成功
-	if _, err := RedisClusterClient.Ping(context.Background()).Result(); err != nil {
-		slog.Error("failed to connect redis cluster",
+	if _, err := RedisClient.Ping(context.Background()).Result(); err != nil {
+		slog.Error("failed to connect redis",
 			"error", err,
+			"mode", mode,
 			"nodes", nodes,
 		)
 		os.Exit(1)
 	}
 
-	slog.Info("Redis cluster connected successfully", "nodes", nodes)
+	slog.Info("Redis connected successfully", "mode", mode, "nodes", nodes)
 }
 
+// Kafka启动检查相关常量
+const (
+	kafkaInitBackoff = 500 * time.Millisecond // broker全部不可达时的初始退避时间
+	kafkaDialTimeout = 3 * time.Second        // 单个broker连接超时时间
+)
+
 // InitKafka 初始化Kafka生产者和消费者
 func InitKafka() {
 	cfg := config.AppConfig.Kafka
@@ -130,13 +179,137 @@ func InitKafka() {
 		MaxBytes: 10e6,        // 最大读取字节数
 	})
 
+	// 开启审计时初始化审计事件生产者
+	if cfg.AuditEnabled {
+		KafkaAuditWriter = &kafka.Writer{
+			Addr:     kafka.TCP(brokers...), // broker地址
+			Topic:    cfg.AuditTopic,        // 审计主题名称
+			Balancer: &kafka.LeastBytes{},   // 负载均衡策略
+			Async:    true,                  // 异步模式
+		}
+	}
+
+	// 配置死信主题时初始化死信消息生产者
+	if cfg.DeadLetterTopic != "" {
+		KafkaDLQWriter = &kafka.Writer{
+			Addr:     kafka.TCP(brokers...), // broker地址
+			Topic:    cfg.DeadLetterTopic,   // 死信主题名称
+			Balancer: &kafka.LeastBytes{},   // 负载均衡策略
+		}
+	}
+	KafkaHandlerTimeout = cfg.HandlerTimeout()
+	KafkaHandlerAttempts = cfg.MaxHandlerAttempts()
+
+	// 配置生命周期主题时初始化活动生命周期事件生产者
+	if cfg.LifecycleTopic != "" {
+		KafkaLifecycleWriter = &kafka.Writer{
+			Addr:     kafka.TCP(brokers...), // broker地址
+			Topic:    cfg.LifecycleTopic,    // 生命周期主题名称
+			Balancer: &kafka.LeastBytes{},   // 负载均衡策略
+			Async:    true,                  // 异步模式
+		}
+	}
+
+	// 配置异步秒杀主题时初始化异步秒杀请求的生产者和消费者
+	// 生产者使用同步模式，写入成功后才向客户端返回请求ID
+	if cfg.SeckillRequestTopic != "" {
+		KafkaSeckillRequestWriter = &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),   // broker地址
+			Topic:    cfg.SeckillRequestTopic, // 异步秒杀主题名称
+			Balancer: &kafka.Hash{},           // 按用户ID分区，同一用户的请求按顺序处理
+		}
+		KafkaSeckillRequestReader = kafka.NewReader(kafka.ReaderConfig{
+			Brokers:  brokers,                          // broker地址
+			Topic:    cfg.SeckillRequestTopic,          // 异步秒杀主题名称
+			GroupID:  cfg.GroupID + "_seckill_request", // 使用独立的消费者组
+			MinBytes: 1,                                // 有请求即返回，减少排队延迟
+			MaxBytes: 10e6,                             // 最大读取字节数
+		})
+	}
+
+	// 检查broker连通性，Kafka客户端本身是惰性连接的，不检查会到首次收发消息时才发现故障
+	if err := CheckKafkaBrokers(brokers, cfg.InitRetries, kafkaInitBackoff, kafkaDialTimeout); err != nil {
+		if cfg.FailFast {
+			slog.Error("Kafka brokers unreachable, aborting startup", "error", err)
+			os.Exit(1)
+		}
+		slog.Error("Kafka brokers unreachable, order messages will fail until brokers recover",
+			"error", err,
+		)
+	}
+
 	slog.Info("Kafka clients initialized",
 		"brokers", brokers,
 		"topic", cfg.Topic,
 		"group_id", cfg.GroupID,
+		"audit_enabled", cfg.AuditEnabled,
+		"audit_topic", cfg.AuditTopic,
+		"dead_letter_topic", cfg.DeadLetterTopic,
+		"lifecycle_topic", cfg.LifecycleTopic,
+		"seckill_request_topic", cfg.SeckillRequestTopic,
+		"handler_timeout", KafkaHandlerTimeout,
+		"handler_attempts", KafkaHandlerAttempts,
 	)
 }
 
+// CheckKafkaBrokers 检查Kafka broker连通性
+// 每轮依次连接所有broker并拉取集群元数据，只要有一个broker可用即视为成功；
+// 全部不可达时按指数退避重试，最终仍失败则返回包含所有不可达broker的错误
+func CheckKafkaBrokers(brokers []string, retries int, backoff, dialTimeout time.Duration) error {
+	var unreachable []string
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff << (attempt - 1)) // 指数退避
+		}
+
+		unreachable = unreachable[:0]
+		var lastErr error
+		for _, broker := range brokers {
+			if err := checkKafkaBroker(broker, dialTimeout); err != nil {
+				unreachable = append(unreachable, broker)
+				lastErr = err
+			}
+		}
+
+		if len(unreachable) < len(brokers) {
+			if len(unreachable) > 0 {
+				// 部分broker不可用时客户端仍可通过其余broker工作
+				slog.Warn("Some kafka brokers are unreachable",
+					"unreachable", unreachable,
+					"available", len(brokers)-len(unreachable),
+				)
+			}
+			return nil
+		}
+		slog.Warn("All kafka brokers unreachable",
+			"brokers", brokers,
+			"attempt", attempt+1,
+			"error", lastErr,
+		)
+	}
+	return fmt.Errorf("all kafka brokers unreachable after %d retries: %s",
+		retries, strings.Join(unreachable, ", "))
+}
+
+// checkKafkaBroker 连接单个broker并拉取集群元数据
+func checkKafkaBroker(broker string, dialTimeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	dialer := &kafka.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", broker)
+	if err != nil {
+		return fmt.Errorf("dial kafka broker %s failed: %v", broker, err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+	if _, err := conn.Brokers(); err != nil {
+		return fmt.Errorf("fetch kafka metadata from %s failed: %v", broker, err)
+	}
+	return nil
+}
+
 // InitEtcd 初始化Etcd客户端连接
 func InitEtcd() {
 	cfg := config.AppConfig.Etcd
@@ -171,62 +344,115 @@ func InitEtcd() {
 	}
 
 	EtcdClient = client
-	slog.Info("Etcd connected successfully", "endpoints", endpoints)
+	EtcdCacheFallback = cfg.CacheFallback
+	slog.Info("Etcd connected successfully", "endpoints", endpoints, "cache_fallback", cfg.CacheFallback)
 
 	// 初始化Etcd中的默认配置
 	initEtcdConfig()
 }
 
+// etcdInitBackoff 写入默认配置失败时的初始退避时间
+const etcdInitBackoff = 200 * time.Millisecond
+
 // initEtcdConfig 初始化Etcd中的默认配置
 func initEtcdConfig() {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	cfg := config.AppConfig.Etcd
 
 	// 定义默认配置项
 	defaultConfigs := map[string]string{
 		EtcdKeySeckillEnabled: "true", // 默认开启秒杀
 		EtcdKeyRateLimit:      "10",   // 默认限流10次/分钟
+		EtcdKeyIPRateLimit:    "60",   // 默认每个IP限流60次/分钟
 		EtcdKeyStockPreload:   "true", // 默认开启库存预加载
 	}
 
-	// 遍历并设置默认配置
-	for key, value := range defaultConfigs {
-		// 检查配置是否已存在
-		resp, err := EtcdClient.Get(ctx, key)
-		if err != nil {
-			slog.Warn("Failed to check etcd key", "key", key, "error", err)
-			continue
-		}
+	err := SetEtcdDefaults(EtcdClient, defaultConfigs, cfg.InitRetries, etcdInitBackoff)
+	if err == nil {
+		return
+	}
+	if cfg.FailFast {
+		slog.Error("Failed to initialize etcd default config, aborting startup", "error", err)
+		os.Exit(1)
+	}
+	slog.Warn("Failed to initialize etcd default config, repositories will fall back to built-in defaults",
+		"error", err,
+	)
+}
 
-		// 如果配置不存在，则设置默认值
-		if len(resp.Kvs) == 0 {
-			_, err := EtcdClient.Put(ctx, key, value)
-			if err != nil {
-				slog.Warn("Failed to set etcd config", "key", key, "error", err)
-			} else {
-				slog.Info("Set default etcd config", "key", key, "value", value)
+// SetEtcdDefaults 写入Etcd中缺失的默认配置项
+// 每个配置项失败时按指数退避重试，最终仍失败的配置项汇总在返回的错误中
+func SetEtcdDefaults(kv clientv3.KV, defaults map[string]string, retries int, backoff time.Duration) error {
+	var failedKeys []string
+	for key, value := range defaults {
+		var err error
+		for attempt := 0; attempt <= retries; attempt++ {
+			if attempt > 0 {
+				time.Sleep(backoff << (attempt - 1)) // 指数退避
+			}
+			if err = setEtcdDefault(kv, key, value); err == nil {
+				break
 			}
+			slog.Warn("Failed to set etcd default config",
+				"key", key,
+				"attempt", attempt+1,
+				"error", err,
+			)
+		}
+		if err != nil {
+			failedKeys = append(failedKeys, key)
 		}
 	}
+
+	if len(failedKeys) > 0 {
+		sort.Strings(failedKeys)
+		return fmt.Errorf("failed to set etcd default config after %d retries: %s",
+			retries, strings.Join(failedKeys, ", "))
+	}
+	return nil
+}
+
+// setEtcdDefault 在配置项不存在时写入默认值
+func setEtcdDefault(kv clientv3.KV, key, value string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// 检查配置是否已存在
+	resp, err := kv.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("check etcd key failed: %v", err)
+	}
+	if len(resp.Kvs) > 0 {
+		return nil
+	}
+
+	// 如果配置不存在，则设置默认值
+	if _, err := kv.Put(ctx, key, value); err != nil {
+		return fmt.Errorf("put etcd key failed: %v", err)
+	}
+	slog.Info("Set default etcd config", "key", key, "value", value)
+	return nil
 }
 
 // initDatabase 初始化数据库表结构和测试数据
 func initDatabase() error {
-	// 自动迁移数据库表
-	if err := DBClient.AutoMigrate(
-		&model.Goods{},
-		&model.PromotionSecKill{},
-		&model.SuccessKilled{},
-	); err != nil {
-		return fmt.Errorf("failed to auto migrate tables: %v", err)
+	// 迁移数据库表
+	if err := MigrateDatabase(DBClient); err != nil {
+		return err
 	}
 
 	// 插入测试数据
-	return insertTestData(1000)
+	seed := config.AppConfig.Seed
+	return InsertTestData(seed.GoodsCount(), seed)
 }
 
-// insertTestData 向数据库插入测试数据
-func insertTestData(count int) error {
+// InsertTestData 向数据库插入测试数据
+// count为0时不插入任何数据；数据库中已有商品时跳过
+func InsertTestData(count int, seed config.SeedConfig) error {
+	if count <= 0 {
+		slog.Info("Test data insertion disabled by config")
+		return nil
+	}
+
 	// 检查是否已有数据
 	var existingCount int64
 	if err := DBClient.Model(&model.Goods{}).Count(&existingCount).Error; err != nil {
@@ -240,12 +466,12 @@ func insertTestData(count int) error {
 	// 在事务中同时插入商品和促销数据
 	return DBClient.Transaction(func(tx *gorm.DB) error {
 		// 生成商品数据
-		goods := generateGoodsData(count)
+		goods := GenerateGoodsData(count, seed)
 		if err := tx.CreateInBatches(goods, count).Error; err != nil {
 			return fmt.Errorf("failed to insert goods data: %v", err)
 		}
 		// 生成促销数据（直接使用内存中的商品数据）
-		promotions := generatePromotionData(goods)
+		promotions := generatePromotionData(goods, seed.PurchaseLimit)
 		if err := tx.CreateInBatches(promotions, count).Error; err != nil {
 			return fmt.Errorf("failed to insert promotion data: %v", err)
 		}
@@ -258,37 +484,41 @@ func insertTestData(count int) error {
 	})
 }
 
-// generateGoodsData 生成商品测试数据
-func generateGoodsData(count int) []model.Goods {
+// GenerateGoodsData 生成商品测试数据
+// 分类ID、商品类型和商品名称取自seed配置，未配置的项使用默认图书类目
+func GenerateGoodsData(count int, seed config.SeedConfig) []model.Goods {
+	seed.ApplyDefaults()
 	goods := make([]model.Goods, count)
-	categories := []int64{1, 2, 3, 4, 5}                                         // 商品分类ID
-	bookTypes := []string{"Computer", "Literature", "Science", "History", "Art"} // 书籍类型
+	categories := seed.Categories                   // 商品分类ID
+	itemTypes := seed.ItemTypes                     // 商品类型
+	lowerItemName := strings.ToLower(seed.ItemName) // 副标题使用小写商品名称
 
 	// 使用随机数生成器创建随机数据
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	for i := range goods {
 		originalCost := float64(r.Intn(480) + 20)     // 原始价格(20-500)
 		discount := 0.6 + r.Float64()*0.35            // 折扣(0.6-0.95)
-		bookType := bookTypes[r.Intn(len(bookTypes))] // 随机选择书籍类型
+		itemType := itemTypes[r.Intn(len(itemTypes))] // 随机选择商品类型
 		serialNumber := r.Intn(1000) + 1              // 序列号
 
 		goods[i] = model.Goods{
-			GoodsId:        int64(1000 + i),                                   // 商品ID
-			Title:          fmt.Sprintf("%s Book-%d", bookType, serialNumber), // 标题
-			SubTitle:       fmt.Sprintf("High-quality %s book", bookType),     // 副标题
-			OriginalCost:   originalCost,                                      // 原价
-			CurrentPrice:   originalCost * discount,                           // 当前价格
-			Discount:       discount,                                          // 折扣
-			IsFreeDelivery: int32(r.Intn(2)),                                  // 是否包邮(0或1)
-			CategoryId:     categories[r.Intn(len(categories))],               // 分类ID
-			LastUpdateTime: time.Now(),                                        // 最后更新时间
+			GoodsId:        int64(1000 + i),                                                // 商品ID
+			Title:          fmt.Sprintf("%s %s-%d", itemType, seed.ItemName, serialNumber), // 标题
+			SubTitle:       fmt.Sprintf("High-quality %s %s", itemType, lowerItemName),     // 副标题
+			OriginalCost:   originalCost,                                                   // 原价
+			CurrentPrice:   originalCost * discount,                                        // 当前价格
+			Discount:       discount,                                                       // 折扣
+			IsFreeDelivery: int32(r.Intn(2)),                                               // 是否包邮(0或1)
+			CategoryId:     categories[r.Intn(len(categories))],                            // 分类ID
+			LastUpdateTime: model.NewTimestamp(time.Now()),                                 // 最后更新时间
 		}
 	}
 	return goods
 }
 
 // generatePromotionData 生成促销测试数据，基于已生成的商品数据
-func generatePromotionData(goods []model.Goods) []model.PromotionSecKill {
+// purchaseLimit为每个用户最多购买的件数，0表示每人限购一件
+func generatePromotionData(goods []model.Goods, purchaseLimit int64) []model.PromotionSecKill {
 	promotions := make([]model.PromotionSecKill, len(goods))
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 
@@ -298,19 +528,38 @@ func generatePromotionData(goods []model.Goods) []model.PromotionSecKill {
 		endTime := time.Now().Add(time.Duration(r.Intn(48)+24) * time.Hour)   // 24-72小时后结束
 
 		promotions[i] = model.PromotionSecKill{
-			PsId:         int64(2000 + i),
-			GoodsId:      good.GoodsId,
-			PsCount:      int64(BookStockCount),
-			StartTime:    startTime,
-			EndTime:      endTime,
-			Status:       1,
-			CurrentPrice: good.CurrentPrice * 0.8,
-			Version:      0,
+			PsId:          int64(2000 + i),
+			GoodsId:       good.GoodsId,
+			PsCount:       int64(BookStockCount),
+			StartTime:     model.NewTimestamp(startTime),
+			EndTime:       model.NewTimestamp(endTime),
+			Status:        1,
+			CurrentPrice:  good.CurrentPrice * 0.8,
+			Version:       0,
+			PurchaseLimit: purchaseLimit,
 		}
 	}
 	return promotions
 }
 
+// ApplyMySQLPool 将连接池大小应用到已建立的数据库连接，用于配置热加载
+func ApplyMySQLPool(cfg config.MysqlConfig) error {
+	if DBClient == nil {
+		return fmt.Errorf("mysql client not initialized")
+	}
+	sqlDB, err := DBClient.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(cfg.OpenConns())
+	sqlDB.SetMaxIdleConns(cfg.IdleConns())
+	slog.Info("MySQL connection pool updated",
+		"max_open_conns", cfg.OpenConns(),
+		"max_idle_conns", cfg.IdleConns(),
+	)
+	return nil
+}
+
 // CloseMysql 关闭MySQL数据库连接
 func CloseMysql() {
 	if DBClient != nil {
@@ -321,11 +570,11 @@ func CloseMysql() {
 	}
 }
 
-// CloseRedis 关闭Redis集群连接
+// CloseRedis 关闭Redis连接
 func CloseRedis() {
-	if RedisClusterClient != nil {
-		RedisClusterClient.Close()
-		slog.Info("Redis cluster connection closed")
+	if RedisClient != nil {
+		RedisClient.Close()
+		slog.Info("Redis connection closed")
 	}
 }
 
@@ -337,6 +586,21 @@ func CloseKafka() {
 	if KafkaReader != nil {
 		KafkaReader.Close()
 	}
+	if KafkaAuditWriter != nil {
+		KafkaAuditWriter.Close()
+	}
+	if KafkaDLQWriter != nil {
+		KafkaDLQWriter.Close()
+	}
+	if KafkaLifecycleWriter != nil {
+		KafkaLifecycleWriter.Close()
+	}
+	if KafkaSeckillRequestWriter != nil {
+		KafkaSeckillRequestWriter.Close()
+	}
+	if KafkaSeckillRequestReader != nil {
+		KafkaSeckillRequestReader.Close()
+	}
 	slog.Info("Kafka clients closed")
 }
 
@@ -0,0 +1,121 @@
+package global
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"seckill_system/model"
+	"strings"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout 单个依赖检查的超时时间，避免某个依赖挂起拖慢探针
+const healthCheckTimeout = 2 * time.Second
+
+// errClientNotInitialized 依赖对应的全局客户端为nil
+var errClientNotInitialized = errors.New("client not initialized")
+
+// HealthCheck 并发检查MySQL、Redis、Kafka和Etcd的连通性，全部可用时报告Ready
+// 结果按固定顺序返回，每个依赖的检查受healthCheckTimeout限制
+func HealthCheck(ctx context.Context) model.HealthReport {
+	checks := []struct {
+		name  string
+		check func(context.Context) error
+	}{
+		{model.DependencyMySQL, checkMySQL},
+		{model.DependencyRedis, checkRedis},
+		{model.DependencyKafka, checkKafka},
+		{model.DependencyEtcd, checkEtcd},
+	}
+
+	report := model.HealthReport{
+		Ready:        true,
+		Dependencies: make([]model.DependencyHealth, len(checks)),
+	}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.check(checkCtx)
+			result := model.DependencyHealth{
+				Name:      c.name,
+				Healthy:   err == nil,
+				LatencyMs: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			report.Dependencies[i] = result
+		}()
+	}
+	wg.Wait()
+
+	for _, dep := range report.Dependencies {
+		report.Ready = report.Ready && dep.Healthy
+	}
+	return report
+}
+
+// checkMySQL 检查数据库连接
+func checkMySQL(ctx context.Context) error {
+	if DBClient == nil {
+		return errClientNotInitialized
+	}
+	sqlDB, err := DBClient.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// checkRedis 检查Redis连接
+func checkRedis(ctx context.Context) error {
+	if RedisClient == nil {
+		return errClientNotInitialized
+	}
+	return RedisClient.Ping(ctx).Err()
+}
+
+// checkKafka 检查Kafka生产者的broker连通性，任一broker可用即视为成功
+func checkKafka(ctx context.Context) error {
+	if KafkaWriter == nil || KafkaWriter.Addr == nil {
+		return errClientNotInitialized
+	}
+	timeout := healthCheckTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	var lastErr error
+	for _, broker := range strings.Split(KafkaWriter.Addr.String(), ",") {
+		if lastErr = checkKafkaBroker(broker, timeout); lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// checkEtcd 检查Etcd集群状态，任一端点返回状态即视为成功
+func checkEtcd(ctx context.Context) error {
+	if EtcdClient == nil || EtcdClient.Maintenance == nil {
+		return errClientNotInitialized
+	}
+	endpoints := EtcdClient.Endpoints()
+	if len(endpoints) == 0 {
+		return errors.New("no etcd endpoints configured")
+	}
+
+	var lastErr error
+	for _, endpoint := range endpoints {
+		if _, lastErr = EtcdClient.Status(ctx, endpoint); lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("etcd status failed: %v", lastErr)
+}
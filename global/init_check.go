@@ -0,0 +1,41 @@
+package global
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotInitialized 全局客户端尚未初始化
+// 仓库在构造时读取全局客户端，若在Init*之前构造（如调整了main中的初始化顺序），得到的仓库持有nil客户端
+var ErrNotInitialized = errors.New("global client not initialized")
+
+// CheckInitialized 检查所有全局客户端均已初始化，返回的错误列出缺失的客户端及对应的初始化函数
+func CheckInitialized() error {
+	var missing []string
+	if DBClient == nil {
+		missing = append(missing, "MySQL (global.InitMySQL)")
+	}
+	if RedisClient == nil {
+		missing = append(missing, "Redis (global.InitRedis)")
+	}
+	if KafkaWriter == nil || KafkaReader == nil {
+		missing = append(missing, "Kafka (global.InitKafka)")
+	}
+	if EtcdClient == nil {
+		missing = append(missing, "Etcd (global.InitEtcd)")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s; initialize clients before constructing repositories, services or routers",
+			ErrNotInitialized, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// MustClient 在仓库构造函数中校验全局客户端已初始化，未初始化时panic
+// name为客户端名称，initFunc为负责初始化该客户端的函数名
+func MustClient(name, initFunc string, ready bool) {
+	if !ready {
+		panic(fmt.Errorf("%w: %s client is nil, call global.%s before constructing repositories", ErrNotInitialized, name, initFunc))
+	}
+}
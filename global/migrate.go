@@ -0,0 +1,116 @@
+package global
+
+import (
+	"fmt"
+	"log/slog"
+	"seckill_system/model"
+
+	"gorm.io/gorm"
+)
+
+// legacySuccessKilledTable SQLite重建秒杀成功记录表时旧表的临时名称
+const legacySuccessKilledTable = "success_killed_legacy"
+
+// MigrateDatabase 迁移数据库表结构
+// 先将旧版以(goods_id, user_id)为联合主键的秒杀成功记录表迁移为自增主键，再自动迁移所有表，最后为未记录订单ID的历史订单回填订单ID
+func MigrateDatabase(db *gorm.DB) error {
+	if err := migrateLegacySuccessKilled(db); err != nil {
+		return fmt.Errorf("failed to migrate legacy success_killed table: %w", err)
+	}
+
+	// 自动迁移数据库表
+	if err := db.AutoMigrate(
+		&model.Goods{},
+		&model.PromotionSecKill{},
+		&model.SuccessKilled{},
+		&model.SeckillAuditLog{},
+	); err != nil {
+		return fmt.Errorf("failed to auto migrate tables: %v", err)
+	}
+
+	if err := backfillLegacyOrderIds(db); err != nil {
+		return fmt.Errorf("failed to backfill legacy order ids: %w", err)
+	}
+	return nil
+}
+
+// migrateLegacySuccessKilled 将没有id列的旧版秒杀成功记录表迁移为自增主键
+// AutoMigrate不会修改已有表的主键，直接添加非主键的自增列在MySQL上会失败（1075），因此需要在自动迁移前单独处理
+func migrateLegacySuccessKilled(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&model.SuccessKilled{}) {
+		return nil
+	}
+	hasId, err := hasColumn(db, &model.SuccessKilled{}, "id")
+	if err != nil || hasId {
+		return err
+	}
+
+	slog.Warn("Migrating legacy success_killed table to auto increment primary key",
+		"dialect", db.Dialector.Name(),
+	)
+	switch db.Dialector.Name() {
+	case "mysql":
+		// 删除联合主键和添加自增主键在同一条语句中完成，中途失败时表保持原样
+		if err := db.Exec("ALTER TABLE success_killed DROP PRIMARY KEY, " +
+			"ADD COLUMN id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY FIRST").Error; err != nil {
+			return err
+		}
+	case "sqlite":
+		// SQLite不支持修改主键，在事务中重建表并复制历史记录
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Migrator().RenameTable("success_killed", legacySuccessKilledTable); err != nil {
+				return err
+			}
+			if err := tx.Migrator().CreateTable(&model.SuccessKilled{}); err != nil {
+				return err
+			}
+			if err := tx.Exec("INSERT INTO success_killed (goods_id, user_id, state, create_time) " +
+				"SELECT goods_id, user_id, state, create_time FROM " + legacySuccessKilledTable).Error; err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(legacySuccessKilledTable)
+		})
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported dialect %q, rebuild success_killed with an auto increment id column manually",
+			db.Dialector.Name())
+	}
+
+	slog.Info("Legacy success_killed table migrated")
+	return nil
+}
+
+// hasColumn 按列名精确判断表中是否存在指定列
+// SQLite的Migrator.HasColumn按建表语句模糊匹配，goods_id等列会被误判为id列
+func hasColumn(db *gorm.DB, value any, name string) (bool, error) {
+	columns, err := db.Migrator().ColumnTypes(value)
+	if err != nil {
+		return false, err
+	}
+	for _, column := range columns {
+		if column.Name() == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// backfillLegacyOrderIds 为未记录订单ID的历史订单回填model.LegacyOrderId
+func backfillLegacyOrderIds(db *gorm.DB) error {
+	orderId := "CONCAT(user_id, '-', goods_id, '-0')"
+	if db.Dialector.Name() == "sqlite" {
+		orderId = "user_id || '-' || goods_id || '-0'"
+	}
+	result := db.Model(&model.SuccessKilled{}).
+		Where("order_id IS NULL OR order_id = ''").
+		Update("order_id", gorm.Expr(orderId))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		slog.Info("Legacy order ids backfilled", "count", result.RowsAffected)
+	}
+	return nil
+}
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
+	"seckill_system/errs"
 	"seckill_system/model"
 	"seckill_system/repository"
+	"seckill_system/web/metrics"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,18 +16,26 @@ import (
 
 // SeckillHandler 秒杀业务处理器
 type SeckillHandler struct {
-	redisRepo *repository.RedisRepository // Redis仓库操作
-	goodRepo  *repository.GoodRepository  // 商品仓库操作
-	kafkaRepo *repository.KafkaRepository // Kafka仓库操作
+	redisRepo  *repository.RedisRepository // Redis仓库操作
+	goodRepo   *repository.GoodRepository  // 商品仓库操作
+	kafkaRepo  *repository.KafkaRepository // Kafka仓库操作
+	promotions *repository.PromotionCache  // 促销信息缓存
+	firstSales sync.Map                    // 本实例确认已触发首单事件的商品ID及确认时间
 }
 
+// firstSaleCacheTTL 本实例确认首单事件已触发后不再访问Redis去重标记的时间
+// 其他实例重新预加载库存开始新一轮活动时，本实例最迟在到期后重新检查
+const firstSaleCacheTTL = time.Minute
+
 // NewSeckillHandler 创建秒杀处理器实例
 func NewSeckillHandler() *SeckillHandler {
-	return &SeckillHandler{
+	h := &SeckillHandler{
 		redisRepo: repository.NewRedisRepository(),
 		goodRepo:  repository.NewGoodRepository(),
 		kafkaRepo: repository.NewKafkaRepository(),
 	}
+	h.promotions = repository.NewPromotionCache(h.goodRepo, h.redisRepo)
+	return h
 }
 
 // CheckStock 检查商品库存
@@ -33,43 +43,92 @@ func (h *SeckillHandler) CheckStock(ctx context.Context, goodsId int64) (int64,
 	return h.redisRepo.GetGoodsStock(goodsId)
 }
 
-// CreateOrder 创建秒杀订单
-func (h *SeckillHandler) CreateOrder(ctx context.Context, userId, goodsId int64) (string, error) {
+// PurchaseLimit 返回商品秒杀活动每个用户最多购买的件数，优先读取促销缓存
+func (h *SeckillHandler) PurchaseLimit(goodsId int64) (int64, error) {
+	promotion, _, err := h.promotions.Get(goodsId)
+	if err != nil {
+		return 0, fmt.Errorf("get promotion failed: %w", err)
+	}
+	return promotion.PerUserLimit(), nil
+}
+
+// CreateOrder 创建秒杀订单，qty为购买数量
+func (h *SeckillHandler) CreateOrder(ctx context.Context, userId, goodsId, qty int64) (string, error) {
+	return h.createOrder(ctx, userId, goodsId, qty, false)
+}
+
+// CreateReservedOrder 使用签发令牌时已预占的一件Redis库存创建秒杀订单，不再扣减Redis库存
+// 下单失败时预占的库存归还到Redis
+func (h *SeckillHandler) CreateReservedOrder(ctx context.Context, userId, goodsId int64) (string, error) {
+	return h.createOrder(ctx, userId, goodsId, 1, true)
+}
+
+// createOrder 创建秒杀订单，stockReserved为true时Redis库存已在签发令牌时扣减
+func (h *SeckillHandler) createOrder(ctx context.Context, userId, goodsId, qty int64, stockReserved bool) (string, error) {
 	orderId := generateOrderId(userId, goodsId)
 
 	// 原子性库存预扣减
-	canSeckill, err := h.redisRepo.CheckAndDecrStock(goodsId)
-	if err != nil || !canSeckill {
-		return "", fmt.Errorf("stock check failed: %v", err)
+	if !stockReserved {
+		canSeckill, err := h.decrStock(goodsId, qty)
+		if err != nil || !canSeckill {
+			return "", fmt.Errorf("stock check failed: %w", err)
+		}
 	}
 
-	// 数据库事务（只包含数据库操作）
+	// 数据库事务（只包含数据库操作），死锁重试时整个事务回滚后重新执行，库存扣减和订单写入都在事务内
 	var orderSuccess bool
-	err = h.goodRepo.WithTransaction(func(tx *gorm.DB) error {
-		// 获取秒杀活动信息
-		promotion, err := h.goodRepo.GetPromotionByGoodsId(goodsId)
+	err := h.goodRepo.WithTransaction(func(tx *gorm.DB) error {
+		// 获取秒杀活动信息，优先读取缓存
+		promotion, fromCache, err := h.promotions.Get(goodsId)
 		if err != nil {
-			return fmt.Errorf("get promotion failed: %v", err)
+			return fmt.Errorf("get promotion failed: %w", err)
+		}
+
+		// 校验用户已购买件数，达到活动限购数量时拒绝下单
+		purchased, err := h.goodRepo.CountUserPurchases(tx, userId, goodsId)
+		if err != nil {
+			return err
+		}
+		if limit := promotion.PerUserLimit(); purchased+qty > limit {
+			return fmt.Errorf("%w: user %d goods %d purchased %d limit %d",
+				errs.ErrPurchaseLimitReached, userId, goodsId, purchased, limit)
 		}
 
 		// 乐观锁扣减库存
-		rowsAffected, err := h.goodRepo.OccReduceOnePromotionByGoodsId(goodsId, promotion.Version)
+		rowsAffected, err := h.goodRepo.OccReducePromotionByGoodsId(tx, goodsId, promotion.Version, qty)
 		if err != nil {
-			return fmt.Errorf("reduce promotion count failed: %v", err)
+			return fmt.Errorf("reduce promotion count failed: %w", err)
+		}
+		if rowsAffected == 0 && fromCache {
+			// 缓存中的版本号可能已落后于数据库，失效缓存后按数据库最新版本重试一次
+			h.promotions.Invalidate(goodsId)
+			if promotion, err = h.promotions.GetFresh(goodsId); err != nil {
+				return fmt.Errorf("get promotion failed: %w", err)
+			}
+			if rowsAffected, err = h.goodRepo.OccReducePromotionByGoodsId(tx, goodsId, promotion.Version, qty); err != nil {
+				return fmt.Errorf("reduce promotion count failed: %w", err)
+			}
 		}
 
 		if rowsAffected == 0 {
-			return errors.New("seckill failed, stock not enough")
+			return fmt.Errorf("%w: stock not enough", errs.ErrSoldOut) // 数据库库存不足或乐观锁版本冲突
 		}
 
 		// 创建秒杀成功记录
 		order := &model.SuccessKilled{
-			GoodsId: goodsId,
-			UserId:  userId,
-			State:   0,
+			OrderId:    orderId,
+			GoodsId:    goodsId,
+			UserId:     userId,
+			Quantity:   qty,
+			State:      0,
+			CreateTime: model.NewTimestamp(time.Now()), // 显式写入下单时间，不依赖ORM钩子
 		}
 		if err := h.goodRepo.AddSuccessKilled(tx, order); err != nil {
-			return fmt.Errorf("create order failed: %v", err)
+			if repository.IsDuplicateKeyError(err) {
+				// 订单表启动时已迁移为自增主键，主键冲突只会来自库外建立的唯一约束，按已购买处理，事务回滚后恢复Redis库存
+				return fmt.Errorf("%w: user %d goods %d", errs.ErrAlreadyPurchased, userId, goodsId)
+			}
+			return fmt.Errorf("create order failed: %w", err)
 		}
 
 		orderSuccess = true
@@ -77,13 +136,14 @@ func (h *SeckillHandler) CreateOrder(ctx context.Context, userId, goodsId int64)
 			"order_id", orderId,
 			"user_id", userId,
 			"goods_id", goodsId,
+			"quantity", qty,
 		)
 		return nil
 	})
 
-	// 如果数据库事务失败，恢复Redis库存
+	// 如果数据库事务失败，数据库库存随事务回滚，只需恢复Redis库存
 	if err != nil {
-		if _, restoreErr := h.redisRepo.IncrGoodsStock(goodsId); restoreErr != nil {
+		if _, restoreErr := h.redisRepo.IncrGoodsStockBy(goodsId, qty); restoreErr != nil {
 			slog.Error("Failed to restore stock after db failure",
 				"goods_id", goodsId,
 				"error", restoreErr,
@@ -91,6 +151,7 @@ func (h *SeckillHandler) CreateOrder(ctx context.Context, userId, goodsId int64)
 		}
 		return "", err
 	}
+	h.promotions.Invalidate(goodsId) // 版本号已变更
 
 	// 数据库成功后异步发送消息
 	if orderSuccess {
@@ -100,32 +161,274 @@ func (h *SeckillHandler) CreateOrder(ctx context.Context, userId, goodsId int64)
 	return orderId, nil
 }
 
-// asyncSendOrderMessage 异步发送订单消息
-func (h *SeckillHandler) asyncSendOrderMessage(ctx context.Context, orderId string, userId, goodsId int64) {
-	promotion, err := h.goodRepo.GetPromotionByGoodsId(goodsId)
+// CreateOrderRedisOnly 以Redis-only模式创建秒杀订单，qty为购买数量
+// 只通过Lua脚本原子扣减Redis库存防止超卖，不执行数据库事务；订单写入待写库队列，由FlushPendingOrders异步写入数据库
+func (h *SeckillHandler) CreateOrderRedisOnly(ctx context.Context, userId, goodsId, qty int64) (string, error) {
+	return h.createOrderRedisOnly(ctx, userId, goodsId, qty, false)
+}
+
+// CreateReservedOrderRedisOnly 以Redis-only模式使用签发令牌时已预占的一件库存创建秒杀订单
+func (h *SeckillHandler) CreateReservedOrderRedisOnly(ctx context.Context, userId, goodsId int64) (string, error) {
+	return h.createOrderRedisOnly(ctx, userId, goodsId, 1, true)
+}
+
+// createOrderRedisOnly 以Redis-only模式创建秒杀订单，stockReserved为true时Redis库存已在签发令牌时扣减
+func (h *SeckillHandler) createOrderRedisOnly(ctx context.Context, userId, goodsId, qty int64, stockReserved bool) (string, error) {
+	orderId := generateOrderId(userId, goodsId)
+
+	// 原子性库存扣减，Redis库存即为最终库存
+	if !stockReserved {
+		canSeckill, err := h.decrStock(goodsId, qty)
+		if err != nil || !canSeckill {
+			return "", fmt.Errorf("stock check failed: %w", err)
+		}
+	}
+
+	order := &model.PendingOrder{
+		OrderId:   orderId,
+		UserId:    userId,
+		GoodsId:   goodsId,
+		Quantity:  qty,
+		CreatedAt: time.Now(),
+	}
+	if err := h.redisRepo.PushPendingOrder(order); err != nil {
+		// 订单未能暂存，恢复Redis库存
+		if _, restoreErr := h.redisRepo.IncrGoodsStockBy(goodsId, qty); restoreErr != nil {
+			slog.Error("Failed to restore stock after pending order failure",
+				"goods_id", goodsId,
+				"error", restoreErr,
+			)
+		}
+		return "", err
+	}
+
+	slog.Info("Order created in redis",
+		"order_id", orderId,
+		"user_id", userId,
+		"goods_id", goodsId,
+		"quantity", qty,
+	)
+	return orderId, nil
+}
+
+// FlushPendingOrders 将Redis-only模式的待写库订单写入数据库，最多处理limit条
+// 每条订单在独立事务中扣减数据库库存并创建订单记录，成功后异步发送订单消息；写库失败的订单放回队列尾部，返回本次写入的数量
+func (h *SeckillHandler) FlushPendingOrders(ctx context.Context, limit int) (int, error) {
+	pending, err := h.redisRepo.PendingOrderLen()
 	if err != nil {
-		slog.Error("Failed to get promotion for async message",
-			"order_id", orderId,
+		return 0, fmt.Errorf("get pending order length failed: %v", err)
+	}
+	if int64(limit) > pending {
+		limit = int(pending) // 只处理本轮开始时已存在的订单，避免重复处理放回的订单
+	}
+
+	flushed := 0
+	for i := 0; i < limit; i++ {
+		order, found, err := h.redisRepo.PopPendingOrder()
+		if err != nil {
+			return flushed, err
+		}
+		if !found {
+			break
+		}
+
+		if err := h.flushPendingOrder(ctx, &order); err != nil {
+			continue
+		}
+		flushed++
+	}
+
+	if flushed > 0 {
+		slog.Info("Pending orders flushed to database",
+			"flushed", flushed,
+			"processed", limit,
+		)
+	}
+	return flushed, nil
+}
+
+// FlushPendingOrder 立即将待写库队列中的指定订单写入数据库，返回订单是否在队列中
+// 用于订单写库前收到支付结果的情况；写库失败的订单放回队列尾部
+func (h *SeckillHandler) FlushPendingOrder(ctx context.Context, orderId string) (bool, error) {
+	order, found, err := h.redisRepo.TakePendingOrder(orderId)
+	if err != nil || !found {
+		return false, err
+	}
+	if err := h.flushPendingOrder(ctx, &order); err != nil {
+		return true, err
+	}
+	slog.Info("Pending order flushed to database ahead of schedule",
+		"order_id", orderId,
+	)
+	return true, nil
+}
+
+// flushPendingOrder 将已从队列取出的订单写入数据库并异步发送订单消息，写库失败时放回队列尾部
+func (h *SeckillHandler) flushPendingOrder(ctx context.Context, order *model.PendingOrder) error {
+	if err := h.persistPendingOrder(order); err != nil {
+		order.Attempts++
+		order.Reason = err.Error()
+		slog.Error("Failed to flush pending order to database",
+			"order_id", order.OrderId,
+			"attempts", order.Attempts,
 			"error", err,
 		)
-		return
+		if pushErr := h.redisRepo.PushPendingOrder(order); pushErr != nil {
+			slog.Error("Failed to return pending order to queue",
+				"order_id", order.OrderId,
+				"user_id", order.UserId,
+				"goods_id", order.GoodsId,
+				"quantity", order.Quantity,
+				"error", pushErr,
+			)
+		}
+		return err
 	}
+	h.promotions.Invalidate(order.GoodsId) // 版本号已变更
+	go h.asyncSendOrderMessage(ctx, order.OrderId, order.UserId, order.GoodsId)
+	return nil
+}
 
+// persistPendingOrder 在事务中扣减数据库库存并创建订单记录
+func (h *SeckillHandler) persistPendingOrder(order *model.PendingOrder) error {
+	return h.goodRepo.WithTransaction(func(tx *gorm.DB) error {
+		rowsAffected, err := h.goodRepo.ReducePromotionCount(tx, order.GoodsId, order.Quantity)
+		if err != nil {
+			return fmt.Errorf("reduce promotion count failed: %w", err)
+		}
+		if rowsAffected == 0 {
+			// Redis库存为准，数据库库存不足说明两者已不一致，仍然记录订单
+			slog.Warn("Database promotion count lower than redis, order recorded without stock reduction",
+				"order_id", order.OrderId,
+				"goods_id", order.GoodsId,
+				"quantity", order.Quantity,
+			)
+		}
+
+		if err := h.goodRepo.AddSuccessKilled(tx, &model.SuccessKilled{
+			OrderId:    order.OrderId,
+			GoodsId:    order.GoodsId,
+			UserId:     order.UserId,
+			Quantity:   order.Quantity,
+			State:      0,
+			CreateTime: model.NewTimestamp(order.CreatedAt), // 使用下单时间而非写库时间
+		}); err != nil {
+			return fmt.Errorf("create order failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// asyncSendOrderMessage 异步发送订单消息
+func (h *SeckillHandler) asyncSendOrderMessage(ctx context.Context, orderId string, userId, goodsId int64) {
+	h.DeliverOrderMessage(ctx, orderId, userId, goodsId)
+}
+
+// DeliverOrderMessage 发送订单消息
+// 订单已在数据库创建成功，查询促销价格或发送失败时消息转入发件箱等待重试，不会丢弃
+func (h *SeckillHandler) DeliverOrderMessage(ctx context.Context, orderId string, userId, goodsId int64) error {
 	orderMsg := &model.OrderMessage{
 		OrderId:   orderId,
 		UserId:    userId,
 		GoodsId:   goodsId,
-		Price:     promotion.CurrentPrice,
 		Status:    model.OrderStatusCreated,
 		CreatedAt: time.Now(),
 	}
 
+	promotion, _, err := h.promotions.Get(goodsId)
+	if err != nil {
+		err = fmt.Errorf("get promotion for order message failed: %w", err)
+		h.saveToOutbox(orderMsg, true, err)
+		return err
+	}
+	orderMsg.Price = promotion.CurrentPrice
+
 	if err := h.sendOrderMessageWithRetry(ctx, orderMsg, 3); err != nil {
-		slog.Error("Failed to send async order message",
-			"order_id", orderId,
+		h.saveToOutbox(orderMsg, false, err)
+		return err
+	}
+	return nil
+}
+
+// saveToOutbox 将未发送的订单消息写入发件箱
+func (h *SeckillHandler) saveToOutbox(orderMsg *model.OrderMessage, pricePending bool, cause error) {
+	entry := &model.OrderOutboxEntry{
+		Message:      *orderMsg,
+		PricePending: pricePending,
+		Reason:       cause.Error(),
+		CreatedAt:    time.Now(),
+	}
+	if err := h.redisRepo.PushOrderOutbox(entry); err != nil {
+		// 发件箱也不可用时记录完整消息，便于人工补偿
+		slog.Error("Failed to save order message to outbox",
+			"order_id", orderMsg.OrderId,
+			"user_id", orderMsg.UserId,
+			"goods_id", orderMsg.GoodsId,
+			"cause", cause,
 			"error", err,
 		)
+		return
+	}
+	slog.Warn("Order message moved to outbox",
+		"order_id", orderMsg.OrderId,
+		"price_pending", pricePending,
+		"cause", cause,
+	)
+}
+
+// RetryOrderOutbox 重发发件箱中的订单消息，最多处理limit条
+// 仍然失败的消息放回发件箱尾部，返回本次成功发送的数量
+func (h *SeckillHandler) RetryOrderOutbox(ctx context.Context, limit int) (int, error) {
+	pending, err := h.redisRepo.OrderOutboxLen()
+	if err != nil {
+		return 0, fmt.Errorf("get outbox length failed: %v", err)
+	}
+	if int64(limit) > pending {
+		limit = int(pending) // 只处理本轮开始时已存在的消息，避免重复处理放回的消息
+	}
+
+	sent := 0
+	for i := 0; i < limit; i++ {
+		entry, found, err := h.redisRepo.PopOrderOutbox()
+		if err != nil {
+			return sent, err
+		}
+		if !found {
+			break
+		}
+
+		if err := h.resendOutboxEntry(ctx, &entry); err != nil {
+			entry.Attempts++
+			entry.Reason = err.Error()
+			if pushErr := h.redisRepo.PushOrderOutbox(&entry); pushErr != nil {
+				slog.Error("Failed to return order message to outbox",
+					"order_id", entry.Message.OrderId,
+					"error", pushErr,
+				)
+			}
+			continue
+		}
+		sent++
+	}
+
+	slog.Info("Order outbox retried",
+		"sent", sent,
+		"processed", limit,
+	)
+	return sent, nil
+}
+
+// resendOutboxEntry 重发单条发件箱消息，价格未确定时先补全价格
+func (h *SeckillHandler) resendOutboxEntry(ctx context.Context, entry *model.OrderOutboxEntry) error {
+	if entry.PricePending {
+		promotion, _, err := h.promotions.Get(entry.Message.GoodsId)
+		if err != nil {
+			return fmt.Errorf("get promotion for order message failed: %w", err)
+		}
+		entry.Message.Price = promotion.CurrentPrice
+		entry.PricePending = false
 	}
+	return h.kafkaRepo.SendOrderMessage(ctx, &entry.Message)
 }
 
 // sendOrderMessageWithRetry 带重试的Kafka消息发送
@@ -217,8 +520,102 @@ func (h *SeckillHandler) sendPaymentMessageWithRetry(ctx context.Context, orderI
 	return fmt.Errorf("failed to send payment message after %d retries: %v", maxRetries, lastErr)
 }
 
+// decrStock 原子扣减Redis库存并记录扣减耗时，扣减成功时触发首单和售罄生命周期事件
+func (h *SeckillHandler) decrStock(goodsId, qty int64) (bool, error) {
+	start := time.Now()
+	remaining, err := h.redisRepo.DecrStockBy(goodsId, qty)
+	metrics.ObserveStockDecr(goodsId, start)
+	if err != nil {
+		return false, err
+	}
+
+	h.EmitStockTaken(goodsId, remaining)
+	return true, nil
+}
+
+// EmitStockTaken 在Redis库存被扣减或被令牌预占后触发首单和售罄生命周期事件，remaining为扣减后的剩余库存
+// 本实例确认首单事件已触发后在firstSaleCacheTTL内跳过Redis去重标记，避免每笔订单多一次Redis往返
+func (h *SeckillHandler) EmitStockTaken(goodsId, remaining int64) {
+	if firedAt, ok := h.firstSales.Load(goodsId); !ok || time.Since(firedAt.(time.Time)) >= firstSaleCacheTTL {
+		if _, err := h.emitLifecycleEvent(context.Background(), model.LifecycleFirstSale, goodsId, remaining); err == nil {
+			h.firstSales.Store(goodsId, time.Now())
+		}
+	}
+	if remaining == 0 {
+		h.EmitLifecycleEvent(context.Background(), model.LifecycleSoldOut, goodsId, remaining)
+	}
+}
+
+// ResetFirstSale 清除本实例的首单事件确认记录，重新预加载库存开始新一轮活动后调用
+func (h *SeckillHandler) ResetFirstSale(goodsId int64) {
+	h.firstSales.Delete(goodsId)
+}
+
+// EmitLifecycleEvent 触发秒杀活动生命周期事件，记录结构化日志并发送到生命周期主题
+// 通过Redis标记去重，每个商品的每种事件只触发一次，重新预加载库存后重置；返回本次是否触发
+func (h *SeckillHandler) EmitLifecycleEvent(ctx context.Context, event string, goodsId, stock int64) bool {
+	emitted, err := h.emitLifecycleEvent(ctx, event, goodsId, stock)
+	if err != nil {
+		slog.Warn("Failed to mark activity lifecycle event",
+			"event", event,
+			"goods_id", goodsId,
+			"error", err,
+		)
+	}
+	return emitted
+}
+
+// emitLifecycleEvent 触发生命周期事件，返回本次是否触发；事件此前已触发时返回false和nil
+func (h *SeckillHandler) emitLifecycleEvent(ctx context.Context, event string, goodsId, stock int64) (bool, error) {
+	first, err := h.redisRepo.MarkLifecycleEvent(goodsId, event)
+	if err != nil {
+		return false, err
+	}
+	if !first {
+		return false, nil
+	}
+
+	slog.Info("Activity lifecycle event",
+		"event", event,
+		"goods_id", goodsId,
+		"stock", stock,
+	)
+	lifecycleEvent := &model.LifecycleEvent{
+		Event:     event,
+		GoodsId:   goodsId,
+		Stock:     stock,
+		Timestamp: time.Now(),
+	}
+	if err := h.kafkaRepo.SendLifecycleEvent(ctx, lifecycleEvent); err != nil {
+		slog.Warn("Failed to send activity lifecycle event",
+			"event", event,
+			"goods_id", goodsId,
+			"error", err,
+		)
+	}
+	return true, nil
+}
+
 // generateOrderId 生成唯一订单ID
 func generateOrderId(userId, goodsId int64) string {
 	// 格式: 用户ID-商品ID-时间戳
 	return fmt.Sprintf("%d-%d-%d", userId, goodsId, time.Now().UnixNano())
 }
+
+// ParseOrderId 从订单ID中解析用户ID和商品ID
+func ParseOrderId(orderId string) (userId, goodsId int64, err error) {
+	var nanos int64
+	if _, err := fmt.Sscanf(orderId, "%d-%d-%d", &userId, &goodsId, &nanos); err != nil {
+		return 0, 0, fmt.Errorf("invalid order id %q: %w", orderId, err)
+	}
+	return userId, goodsId, nil
+}
+
+// ParseOrderTime 从订单ID中解析下单时间
+func ParseOrderTime(orderId string) (time.Time, error) {
+	var userId, goodsId, nanos int64
+	if _, err := fmt.Sscanf(orderId, "%d-%d-%d", &userId, &goodsId, &nanos); err != nil {
+		return time.Time{}, fmt.Errorf("invalid order id %q: %w", orderId, err)
+	}
+	return time.Unix(0, nanos), nil
+}
@@ -0,0 +1,59 @@
+package model
+
+import "fmt"
+
+// goodsInfoFields 商品信息接口允许对外返回的字段，键为JSON字段名
+var goodsInfoFields = map[string]func(Goods) any{
+	"goods_id":         func(g Goods) any { return g.GoodsId },
+	"title":            func(g Goods) any { return g.Title },
+	"sub_title":        func(g Goods) any { return g.SubTitle },
+	"original_cost":    func(g Goods) any { return g.OriginalCost },
+	"current_price":    func(g Goods) any { return g.CurrentPrice },
+	"discount":         func(g Goods) any { return g.Discount },
+	"is_free_delivery": func(g Goods) any { return g.IsFreeDelivery },
+	"category_id":      func(g Goods) any { return g.CategoryId },
+	"last_update_time": func(g Goods) any { return g.LastUpdateTime },
+}
+
+// DefaultGoodsInfoFields 商品信息接口默认返回的字段，不包含分类ID和更新时间等内部字段
+var DefaultGoodsInfoFields = []string{
+	"goods_id", "title", "sub_title", "original_cost", "current_price", "discount", "is_free_delivery",
+}
+
+// GoodsInfo 商品信息接口对外返回的数据，只包含配置的字段
+type GoodsInfo map[string]any
+
+// ValidateGoodsInfoFields 检查字段名均为允许对外返回的商品字段
+func ValidateGoodsInfoFields(fields []string) error {
+	for _, field := range fields {
+		if _, ok := goodsInfoFields[field]; !ok {
+			return fmt.Errorf("unknown goods info field %q", field)
+		}
+	}
+	return nil
+}
+
+// ProjectGoodsInfo 按字段列表生成对外返回的商品信息
+// fields为空时使用默认字段，未知字段被忽略
+func ProjectGoodsInfo(good Goods, fields []string) GoodsInfo {
+	if len(fields) == 0 {
+		fields = DefaultGoodsInfoFields
+	}
+	info := make(GoodsInfo, len(fields))
+	for _, field := range fields {
+		if get, ok := goodsInfoFields[field]; ok {
+			info[field] = get(good)
+		}
+	}
+	return info
+}
+
+// SeckillInfo 商品信息接口附带的秒杀活动实时数据
+type SeckillInfo struct {
+	RemainingStock int64     `json:"remaining_stock"` // 剩余库存，库存未预加载时为活动总库存
+	SeckillPrice   float64   `json:"seckill_price"`   // 秒杀价格
+	StartTime      Timestamp `json:"start_time"`      // 秒杀开始时间
+	EndTime        Timestamp `json:"end_time"`        // 秒杀结束时间
+	StockPreloaded bool      `json:"stock_preloaded"` // 库存是否已预加载到Redis
+	PurchaseLimit  int64     `json:"purchase_limit"`  // 每个用户最多购买的件数
+}
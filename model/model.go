@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Goods 商品信息表
 type Goods struct {
@@ -12,27 +15,105 @@ type Goods struct {
 	Discount       float64   `gorm:"column:discount" json:"discount"`                                // 商品折扣
 	IsFreeDelivery int32     `gorm:"column:is_free_delivery" json:"is_free_delivery"`                // 是否包邮：0-不包邮，1-包邮
 	CategoryId     int64     `gorm:"index;column:category_id" json:"category_id"`                    // 商品分类ID，有索引
-	LastUpdateTime time.Time `gorm:"autoUpdateTime;column:last_update_time" json:"last_update_time"` // 最后更新时间，自动更新
+	LastUpdateTime Timestamp `gorm:"autoUpdateTime;column:last_update_time" json:"last_update_time"` // 最后更新时间，自动更新
 }
 
 // PromotionSecKill 秒杀活动表
 type PromotionSecKill struct {
-	PsId         int64     `gorm:"primaryKey;column:ps_id" json:"ps_id"`      // 秒杀活动ID，主键
-	GoodsId      int64     `gorm:"index;column:goods_id" json:"goods_id"`     // 商品ID，有索引
-	PsCount      int64     `gorm:"column:ps_count" json:"ps_count"`           // 秒杀商品数量
-	StartTime    time.Time `gorm:"column:start_time" json:"start_time"`       // 秒杀开始时间
-	EndTime      time.Time `gorm:"column:end_time" json:"end_time"`           // 秒杀结束时间
-	Status       int32     `gorm:"column:status" json:"status"`               // 秒杀状态：0-未开始，1-进行中，2-已结束
-	CurrentPrice float64   `gorm:"column:current_price" json:"current_price"` // 秒杀价格
-	Version      int64     `gorm:"column:version" json:"version"`             // 版本号，用于乐观锁控制并发
+	PsId          int64     `gorm:"primaryKey;column:ps_id" json:"ps_id"`        // 秒杀活动ID，主键
+	GoodsId       int64     `gorm:"index;column:goods_id" json:"goods_id"`       // 商品ID，有索引
+	PsCount       int64     `gorm:"column:ps_count" json:"ps_count"`             // 秒杀商品数量
+	StartTime     Timestamp `gorm:"column:start_time" json:"start_time"`         // 秒杀开始时间
+	EndTime       Timestamp `gorm:"column:end_time" json:"end_time"`             // 秒杀结束时间
+	Status        int32     `gorm:"column:status" json:"status"`                 // 秒杀状态：0-未开始，1-进行中，2-已结束
+	CurrentPrice  float64   `gorm:"column:current_price" json:"current_price"`   // 秒杀价格
+	Version       int64     `gorm:"column:version" json:"version"`               // 版本号，用于乐观锁控制并发
+	PurchaseLimit int64     `gorm:"column:purchase_limit" json:"purchase_limit"` // 每个用户最多购买的件数（已取消的订单不计入），0表示每人限购一件
+}
+
+// PerUserLimit 返回每个用户最多购买的件数，未配置时为1
+func (p PromotionSecKill) PerUserLimit() int64 {
+	return max(p.PurchaseLimit, 1)
 }
 
-// SuccessKilled 秒杀成功记录表
+// SuccessKilled 秒杀成功记录表，每条记录为一笔订单，同一用户在限购范围内可有多条记录
 type SuccessKilled struct {
-	GoodsId    int64     `gorm:"primaryKey;column:goods_id" json:"goods_id"`           // 商品ID，联合主键
-	UserId     int64     `gorm:"primaryKey;column:user_id" json:"user_id"`             // 用户ID，联合主键
-	State      int16     `gorm:"column:state" json:"state"`                            // 秒杀状态：0-成功未支付，1-已支付，2-已取消
-	CreateTime time.Time `gorm:"autoCreateTime;column:create_time" json:"create_time"` // 创建时间，自动生成
+	Id         int64     `gorm:"primaryKey;autoIncrement;column:id" json:"id"`                        // 记录ID，自增主键
+	OrderId    string    `gorm:"size:64;index;column:order_id" json:"order_id"`                       // 订单ID，历史记录为空
+	GoodsId    int64     `gorm:"index:idx_success_killed_goods_user;column:goods_id" json:"goods_id"` // 商品ID，与用户ID组成联合索引
+	UserId     int64     `gorm:"index:idx_success_killed_goods_user;column:user_id" json:"user_id"`   // 用户ID
+	Quantity   int64     `gorm:"default:1;column:quantity" json:"quantity"`                           // 购买件数，历史记录为1
+	State      int16     `gorm:"column:state" json:"state"`                                           // 秒杀状态：0-成功未支付，1-已支付，2-已取消
+	CreateTime Timestamp `gorm:"autoCreateTime;column:create_time" json:"create_time"`                // 创建时间，自动生成
+}
+
+// LegacyOrderId 返回迁移前未记录订单ID的历史订单回填的订单ID
+// 旧表以(goods_id, user_id)为联合主键，每个用户每件商品至多一条记录，回填的ID按用户和商品唯一
+func LegacyOrderId(userId, goodsId int64) string {
+	return fmt.Sprintf("%d-%d-0", userId, goodsId)
+}
+
+// SeckillAuditLog 秒杀下单审计记录表，每次使用令牌秒杀（无论成功失败）写入一条，用于对账
+type SeckillAuditLog struct {
+	Id          int64     `gorm:"primaryKey;autoIncrement;column:id" json:"id"`         // 记录ID，自增主键
+	UserId      int64     `gorm:"index;column:user_id" json:"user_id"`                  // 用户ID，有索引
+	GoodsId     int64     `gorm:"index;column:goods_id" json:"goods_id"`                // 商品ID，有索引
+	TokenPrefix string    `gorm:"size:16;column:token_prefix" json:"token_prefix"`      // 秒杀令牌前缀，不保存完整令牌
+	Result      string    `gorm:"size:32;column:result" json:"result"`                  // 结果分类：success、sold_out、invalid_token等
+	Reason      string    `gorm:"size:512;column:reason" json:"reason,omitempty"`       // 失败原因，成功时为空
+	CreateTime  Timestamp `gorm:"autoCreateTime;column:create_time" json:"create_time"` // 记录时间，自动生成
+}
+
+// MaxAuditReasonLength 审计记录失败原因的最大长度（字节），超出部分截断
+const MaxAuditReasonLength = 512
+
+// 秒杀成功记录状态常量
+const (
+	OrderStateUnpaid    int16 = 0 // 成功未支付
+	OrderStatePaid      int16 = 1 // 已支付
+	OrderStateCancelled int16 = 2 // 已取消
+)
+
+// orderStateNames 秒杀成功记录状态名称
+var orderStateNames = map[int16]string{
+	OrderStateUnpaid:    "unpaid",
+	OrderStatePaid:      "paid",
+	OrderStateCancelled: "cancelled",
+}
+
+// orderStateTransitions 秒杀成功记录允许的状态迁移
+// 未支付的订单可以支付或取消；已支付和已取消为终态，不允许再迁移
+var orderStateTransitions = map[int16][]int16{
+	OrderStateUnpaid: {OrderStatePaid, OrderStateCancelled},
+}
+
+// OrderStateName 返回订单状态名称，未知状态返回状态值
+func OrderStateName(state int16) string {
+	if name, ok := orderStateNames[state]; ok {
+		return name
+	}
+	return fmt.Sprintf("state(%d)", state)
+}
+
+// CanTransitionOrderState 判断订单状态能否从from迁移到to
+func CanTransitionOrderState(from, to int16) bool {
+	for _, allowed := range orderStateTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenLogPrefixLen 日志中记录的令牌前缀长度
+const tokenLogPrefixLen = 8
+
+// TokenPrefix 返回用于日志记录的令牌前缀，令牌长度不足时返回完整令牌
+func TokenPrefix(token string) string {
+	if len(token) <= tokenLogPrefixLen {
+		return token
+	}
+	return token[:tokenLogPrefixLen]
 }
 
 // RedisToken 用户令牌信息（Redis存储）
@@ -48,10 +129,116 @@ type RedisSeckillToken struct {
 	TokenId   string    `json:"token_id"`   // 秒杀令牌ID
 	UserId    int64     `json:"user_id"`    // 用户ID
 	GoodsId   int64     `json:"goods_id"`   // 商品ID
+	Reserved  bool      `json:"reserved"`   // 签发时是否已预占库存
 	ExpireAt  time.Time `json:"expire_at"`  // 令牌过期时间
 	CreatedAt time.Time `json:"created_at"` // 令牌创建时间
 }
 
+// 秒杀幂等记录状态
+const (
+	IdempotencyStatePending = "pending" // 首个请求仍在处理中
+	IdempotencyStateDone    = "done"    // 已处理完成，记录了订单ID或错误
+)
+
+// MaxIdempotencyKeyLength 幂等键最大长度
+const MaxIdempotencyKeyLength = 128
+
+// ValidIdempotencyKey 校验幂等键：非空、不超过最大长度且只包含可打印ASCII字符（不含空格）
+func ValidIdempotencyKey(key string) bool {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// SeckillIdempotencyRecord 携带幂等键的秒杀请求结果（Redis存储）
+type SeckillIdempotencyRecord struct {
+	State        string `json:"state"`                   // 记录状态
+	GoodsId      int64  `json:"goods_id"`                // 首个请求的商品ID，同一幂等键不能用于其他商品
+	OrderId      string `json:"order_id,omitempty"`      // 秒杀成功的订单ID
+	ErrorCode    string `json:"error_code,omitempty"`    // 秒杀失败的错误码
+	ErrorMessage string `json:"error_message,omitempty"` // 秒杀失败的错误信息
+}
+
+// 异步秒杀请求处理状态
+const (
+	AsyncSeckillPending = "pending" // 已受理，等待后台处理
+	AsyncSeckillSuccess = "success" // 下单成功
+	AsyncSeckillFailed  = "failed"  // 下单失败
+)
+
+// AsyncSeckillRequest 已通过令牌校验、等待后台下单的异步秒杀请求（Kafka消息）
+type AsyncSeckillRequest struct {
+	RequestId     string    `json:"request_id"`               // 请求ID，客户端按此查询处理结果
+	UserId        int64     `json:"user_id"`                  // 用户ID
+	GoodsId       int64     `json:"goods_id"`                 // 商品ID
+	TokenPrefix   string    `json:"token_prefix"`             // 已消费的秒杀令牌前缀，用于日志和审计
+	ClientIP      string    `json:"client_ip"`                // 客户端IP，用于审计事件
+	AcceptedAt    time.Time `json:"accepted_at"`              // 受理时间
+	StockReserved bool      `json:"stock_reserved,omitempty"` // 令牌兑换时仍持有预占的库存，下单时不再扣减Redis库存
+}
+
+// AsyncSeckillResult 异步秒杀请求的处理结果（Redis存储）
+type AsyncSeckillResult struct {
+	RequestId    string    `json:"request_id"`              // 请求ID
+	UserId       int64     `json:"user_id"`                 // 用户ID，只有本人可以查询结果
+	GoodsId      int64     `json:"goods_id"`                // 商品ID
+	Status       string    `json:"status"`                  // 处理状态：pending、success、failed
+	OrderId      string    `json:"order_id,omitempty"`      // 下单成功的订单ID
+	ErrorCode    string    `json:"error_code,omitempty"`    // 下单失败的错误码
+	ErrorMessage string    `json:"error_message,omitempty"` // 下单失败的错误信息
+	AcceptedAt   Timestamp `json:"accepted_at"`             // 受理时间
+	UpdatedAt    Timestamp `json:"updated_at"`              // 最后更新时间
+}
+
+// CachedGoods 商品信息缓存（Redis存储）
+type CachedGoods struct {
+	Goods    Goods     `json:"goods"`     // 商品信息
+	CachedAt time.Time `json:"cached_at"` // 缓存写入时间
+	ExpireAt time.Time `json:"expire_at"` // 缓存逻辑过期时间，过期后仍可作为降级数据
+}
+
+// ResetResult 单个商品的重置结果
+type ResetResult struct {
+	GoodsId int    `json:"goods_id"`        // 商品ID
+	Success bool   `json:"success"`         // 是否重置成功
+	Error   string `json:"error,omitempty"` // 失败原因
+}
+
+// LockInfo 当前持有的分布式锁
+type LockInfo struct {
+	Key        string `json:"key"`         // 锁键
+	LeaseId    int64  `json:"lease_id"`    // 锁绑定的租约ID，0表示未绑定租约
+	TTLSeconds int64  `json:"ttl_seconds"` // 租约剩余有效期（秒），-1表示租约已过期或未绑定租约
+}
+
+// BlacklistEntry 黑名单批量导入导出的条目
+type BlacklistEntry struct {
+	UserId   int64  `json:"user_id"`  // 用户ID
+	Reason   string `json:"reason"`   // 加入黑名单的原因
+	Duration string `json:"duration"` // 封禁时长（Go时长字符串，如"24h"），导出时为剩余时长
+}
+
+// BlacklistImportResult 黑名单批量导入中单个条目的处理结果
+type BlacklistImportResult struct {
+	Index   int    `json:"index"`           // 条目在请求数组中的下标
+	UserId  int64  `json:"user_id"`         // 用户ID
+	Success bool   `json:"success"`         // 是否加入黑名单成功
+	Error   string `json:"error,omitempty"` // 失败原因
+}
+
+// PreloadResult 批量预加载中单个商品的结果
+type PreloadResult struct {
+	Success bool   `json:"success"`         // 是否写入库存成功
+	Stock   int64  `json:"stock"`           // 写入的库存数量，失败时为0
+	Error   string `json:"error,omitempty"` // 失败原因
+}
+
 // OrderMessage 订单消息（用于消息队列）
 type OrderMessage struct {
 	OrderId   string    `json:"order_id"`   // 订单ID
@@ -62,12 +249,221 @@ type OrderMessage struct {
 	CreatedAt time.Time `json:"created_at"` // 订单创建时间
 }
 
+// OrderOutboxEntry 订单消息发件箱条目（订单已创建但消息未能发送，等待重试）
+type OrderOutboxEntry struct {
+	Message      OrderMessage `json:"message"`       // 待发送的订单消息
+	PricePending bool         `json:"price_pending"` // 价格未确定，重试时需重新查询促销价格
+	Reason       string       `json:"reason"`        // 最近一次失败原因
+	Attempts     int          `json:"attempts"`      // 已重试次数
+	CreatedAt    time.Time    `json:"created_at"`    // 进入发件箱时间
+}
+
+// PendingOrder Redis-only秒杀模式下已扣减Redis库存、等待异步写入数据库的订单
+type PendingOrder struct {
+	OrderId   string    `json:"order_id"`   // 订单ID
+	UserId    int64     `json:"user_id"`    // 用户ID
+	GoodsId   int64     `json:"goods_id"`   // 商品ID
+	Quantity  int64     `json:"quantity"`   // 购买数量
+	Attempts  int       `json:"attempts"`   // 写入数据库失败次数
+	Reason    string    `json:"reason"`     // 最近一次写入失败原因
+	CreatedAt time.Time `json:"created_at"` // 下单时间
+}
+
+// OrderStatus 秒杀订单状态查询结果
+type OrderStatus struct {
+	OrderId    string    `json:"order_id"`    // 订单ID
+	UserId     int64     `json:"user_id"`     // 用户ID
+	GoodsId    int64     `json:"goods_id"`    // 商品ID
+	State      int16     `json:"state"`       // 订单状态值
+	Status     string    `json:"status"`      // 订单状态名称：unpaid、paid或cancelled
+	CreateTime Timestamp `json:"create_time"` // 下单时间，位于配置的时区
+}
+
+// StockUpdate 库存变更消息（库存Lua脚本发布stock和delta，订阅方补充商品ID和接收时间）
+type StockUpdate struct {
+	GoodsId   int64     `json:"goods_id"`  // 商品ID
+	Stock     int64     `json:"stock"`     // 变更后剩余库存
+	Delta     int64     `json:"delta"`     // 库存变化量，扣减为负数
+	Timestamp time.Time `json:"timestamp"` // 变更时间
+}
+
+// 库存查询接口返回的库存状态
+const (
+	StockStatusLoaded    = "loaded"     // 库存已预加载到Redis
+	StockStatusNotLoaded = "not_loaded" // 库存尚未预加载，无法给出剩余数量
+)
+
+// 活动排期接口返回的活动阶段
+const (
+	ActivityPhaseUpcoming = "upcoming" // 尚未开始
+	ActivityPhaseActive   = "active"   // 进行中
+	ActivityPhaseEnded    = "ended"    // 已结束
+)
+
+// ScheduledPromotion 商品活动排期中的一场秒杀活动
+type ScheduledPromotion struct {
+	PsId         int64     `json:"ps_id"`         // 秒杀活动ID
+	GoodsId      int64     `json:"goods_id"`      // 商品ID
+	PsCount      int64     `json:"ps_count"`      // 秒杀库存
+	CurrentPrice float64   `json:"current_price"` // 秒杀价格
+	StartTime    Timestamp `json:"start_time"`    // 秒杀开始时间
+	EndTime      Timestamp `json:"end_time"`      // 秒杀结束时间
+	Phase        string    `json:"phase"`         // 按当前时间判断的活动阶段
+}
+
+// ActiveSeckill 进行中的秒杀活动及其实时库存
+type ActiveSeckill struct {
+	PsId         int64     `json:"ps_id"`         // 秒杀活动ID
+	GoodsId      int64     `json:"goods_id"`      // 商品ID
+	PsCount      int64     `json:"ps_count"`      // 数据库中的秒杀库存
+	CurrentPrice float64   `json:"current_price"` // 秒杀价格
+	StartTime    Timestamp `json:"start_time"`    // 秒杀开始时间
+	EndTime      Timestamp `json:"end_time"`      // 秒杀结束时间
+	Stock        int64     `json:"stock"`         // Redis实时库存
+	StockLoaded  bool      `json:"stock_loaded"`  // 库存是否已预加载到Redis
+	Sold         int64     `json:"sold"`          // 已售数量（不含已取消订单）
+}
+
+// SeckillTokenStats 商品秒杀令牌的签发和兑换统计，用于调整超发比例和发现刷令牌行为
+type SeckillTokenStats struct {
+	GoodsId  int64   `json:"goods_id"` // 商品ID
+	Issued   int64   `json:"issued"`   // 已签发的令牌数
+	Redeemed int64   `json:"redeemed"` // 已兑换（验证成功）的令牌数
+	Ratio    float64 `json:"ratio"`    // 签发数与兑换数之比，尚无兑换时为0
+}
+
+// NewSeckillTokenStats 根据签发数和兑换数计算令牌统计
+func NewSeckillTokenStats(goodsId, issued, redeemed int64) SeckillTokenStats {
+	stats := SeckillTokenStats{GoodsId: goodsId, Issued: issued, Redeemed: redeemed}
+	if redeemed > 0 {
+		stats.Ratio = float64(issued) / float64(redeemed)
+	}
+	return stats
+}
+
+// ShutdownSummary 服务关闭摘要，用于发布后核对已处理和未完成的工作
+type ShutdownSummary struct {
+	OrdersProcessed       int64         `json:"orders_processed"`        // 已处理的订单消息数
+	PaymentsProcessed     int64         `json:"payments_processed"`      // 已处理的支付消息数
+	ConsumersStopped      bool          `json:"consumers_stopped"`       // Kafka消费循环是否在超时前全部退出
+	ConsumersDrained      bool          `json:"consumers_drained"`       // 处理中的消息是否在超时前全部完成
+	OutboxPending         int64         `json:"outbox_pending"`          // 发件箱中未送达的订单消息数，-1表示查询失败
+	PaymentRetriesPending int64         `json:"payment_retries_pending"` // 等待支付重试的订单数，-1表示查询失败
+	LogFlushed            bool          `json:"log_flushed"`             // 日志是否已同步到磁盘
+	Duration              time.Duration `json:"duration"`                // 关闭耗时
+}
+
+// TraceRecord 按请求ID缓存的日志记录，用于排查单次请求
+type TraceRecord struct {
+	Time    Timestamp      `json:"time"`            // 日志时间
+	Level   string         `json:"level"`           // 日志级别
+	Message string         `json:"message"`         // 日志内容
+	Attrs   map[string]any `json:"attrs,omitempty"` // 日志属性
+}
+
+// EligibilityCheck 单项秒杀资格检查结果
+type EligibilityCheck struct {
+	Name   string `json:"name"`             // 检查项名称
+	Passed bool   `json:"passed"`           // 是否通过
+	Reason string `json:"reason,omitempty"` // 未通过原因
+}
+
+// SeckillEligibility 秒杀资格预检报告
+type SeckillEligibility struct {
+	UserId   int64              `json:"user_id"`  // 用户ID
+	GoodsId  int64              `json:"goods_id"` // 商品ID
+	Eligible bool               `json:"eligible"` // 是否满足全部条件
+	Checks   []EligibilityCheck `json:"checks"`   // 各项检查结果
+}
+
+// 秒杀资格检查项名称常量
+const (
+	CheckSeckillEnabled = "seckill_enabled"  // 秒杀系统已开启
+	CheckNotBlacklisted = "not_blacklisted"  // 用户不在黑名单
+	CheckGoodsExists    = "goods_exists"     // 商品存在
+	CheckInWindow       = "in_window"        // 处于秒杀活动时间内
+	CheckStockAvailable = "stock_available"  // 库存充足
+	CheckNotRateLimited = "not_rate_limited" // 未触发限流
+)
+
+// DependencyHealth 单个外部依赖的健康检查结果
+type DependencyHealth struct {
+	Name      string `json:"name"`            // 依赖名称
+	Healthy   bool   `json:"healthy"`         // 是否可用
+	LatencyMs int64  `json:"latency_ms"`      // 检查耗时（毫秒）
+	Error     string `json:"error,omitempty"` // 不可用原因
+}
+
+// HealthReport 就绪检查报告
+type HealthReport struct {
+	Ready        bool               `json:"ready"`        // 全部依赖是否可用
+	Dependencies []DependencyHealth `json:"dependencies"` // 各依赖检查结果
+}
+
+// 外部依赖名称常量
+const (
+	DependencyMySQL = "mysql"
+	DependencyRedis = "redis"
+	DependencyKafka = "kafka"
+	DependencyEtcd  = "etcd"
+)
+
+// RateLimitAllowlist 限流豁免名单（监控、管理工具等内部调用方）
+type RateLimitAllowlist struct {
+	UserIds []int64  `json:"user_ids" yaml:"user_ids"` // 豁免的用户ID
+	IPs     []string `json:"ips" yaml:"ips"`           // 豁免的客户端IP，支持CIDR网段
+}
+
+// GoodsAllowlist 秒杀商品准入名单，只有名单内的商品可以签发秒杀令牌
+type GoodsAllowlist struct {
+	GoodsIds []int64 `json:"goods_ids" yaml:"goods_ids"` // 准入的商品ID
+}
+
+// AuditEvent 秒杀审计事件（用于风控分析）
+type AuditEvent struct {
+	Action    string    `json:"action"`           // 操作类型
+	UserId    int64     `json:"user_id"`          // 用户ID
+	GoodsId   int64     `json:"goods_id"`         // 商品ID
+	Outcome   string    `json:"outcome"`          // 结果：success或failure
+	Reason    string    `json:"reason,omitempty"` // 失败原因
+	ClientIP  string    `json:"ip"`               // 客户端IP
+	Timestamp time.Time `json:"timestamp"`        // 事件时间
+}
+
+// 审计事件操作类型和结果常量
+const (
+	AuditActionSeckillToken = "seckill_token" // 获取秒杀令牌
+	AuditActionSeckill      = "seckill"       // 使用令牌秒杀
+	AuditOutcomeSuccess     = "success"       // 成功
+	AuditOutcomeFailure     = "failure"       // 失败
+)
+
+// LifecycleEvent 秒杀活动生命周期事件
+type LifecycleEvent struct {
+	Event     string    `json:"event"`     // 事件类型
+	GoodsId   int64     `json:"goods_id"`  // 商品ID
+	Stock     int64     `json:"stock"`     // 事件发生时的Redis库存，预加载时为初始库存
+	Timestamp time.Time `json:"timestamp"` // 事件时间
+}
+
+// 秒杀活动生命周期事件类型常量
+const (
+	LifecyclePreloaded = "preloaded"  // 库存已预加载到Redis
+	LifecycleFirstSale = "first_sale" // 售出第一件
+	LifecycleSoldOut   = "sold_out"   // Redis库存扣减到0
+	LifecycleEnded     = "ended"      // 到达活动结束时间
+)
+
+// LifecycleEvents 全部生命周期事件类型
+var LifecycleEvents = []string{LifecyclePreloaded, LifecycleFirstSale, LifecycleSoldOut, LifecycleEnded}
+
 // 订单状态常量
 const (
-	OrderStatusCreated       = iota // 0: 订单创建成功
-	OrderStatusPaid                 // 1: 支付成功
-	OrderStatusPaymentFailed        // 2: 支付失败
-	OrderStatusCancelled            // 3: 订单取消
+	OrderStatusCreated             = iota // 0: 订单创建成功
+	OrderStatusPaid                       // 1: 支付成功
+	OrderStatusPaymentFailed              // 2: 支付失败
+	OrderStatusCancelled                  // 3: 订单取消
+	OrderStatusPaymentPendingRetry        // 4: 支付失败等待重试，宽限期内未收到支付成功消息才取消
 )
 
 // ETCDConfig ETCD配置信息
@@ -91,3 +487,8 @@ func (PromotionSecKill) TableName() string {
 func (SuccessKilled) TableName() string {
 	return "success_killed"
 }
+
+// TableName 指定SeckillAuditLog模型对应的数据库表名
+func (SeckillAuditLog) TableName() string {
+	return "success_killed_audit"
+}
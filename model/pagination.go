@@ -0,0 +1,44 @@
+package model
+
+// Paginated 列表接口统一的分页结果
+type Paginated[T any] struct {
+	Items      []T   `json:"items"`       // 当前页数据，没有数据时为空数组
+	Page       int   `json:"page"`        // 当前页码，从1开始
+	Size       int   `json:"size"`        // 每页条数
+	Total      int64 `json:"total"`       // 满足条件的总条数
+	TotalPages int   `json:"total_pages"` // 总页数，没有数据时为0
+	HasNext    bool  `json:"has_next"`    // 是否还有下一页
+}
+
+// NewPaginated 根据当前页数据和总条数生成分页结果
+// page小于1时按第1页处理，size非正数时总页数为0
+func NewPaginated[T any](items []T, page, size int, total int64) Paginated[T] {
+	if items == nil {
+		items = []T{}
+	}
+	page = max(page, 1)
+	totalPages := 0
+	if size > 0 && total > 0 {
+		totalPages = int((total + int64(size) - 1) / int64(size))
+	}
+	return Paginated[T]{
+		Items:      items,
+		Page:       page,
+		Size:       size,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+	}
+}
+
+// Paginate 对内存中的完整列表分页，页码超出范围时返回空的当前页
+func Paginate[T any](all []T, page, size int) Paginated[T] {
+	page = max(page, 1)
+	var items []T
+	if size > 0 {
+		start := min((page-1)*size, len(all))
+		end := min(start+size, len(all))
+		items = all[start:end]
+	}
+	return NewPaginated(items, page, size, int64(len(all)))
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// timestampSettings 当前生效的时区和格式
+type timestampSettings struct {
+	location *time.Location
+	format   string
+}
+
+// activeTimestampSettings 全局时间戳设置，配置加载前使用UTC和RFC3339
+var activeTimestampSettings atomic.Pointer[timestampSettings]
+
+func init() {
+	activeTimestampSettings.Store(&timestampSettings{location: time.UTC, format: time.RFC3339})
+}
+
+// SetTimestampFormat 设置全局时间戳时区和格式，由config.ApplyTimeConfig调用
+func SetTimestampFormat(location *time.Location, format string) {
+	activeTimestampSettings.Store(&timestampSettings{location: location, format: format})
+}
+
+// TimestampLocation 返回配置的时区
+func TimestampLocation() *time.Location {
+	return activeTimestampSettings.Load().location
+}
+
+// FormatTimestamp 按配置的时区和格式格式化时间
+func FormatTimestamp(t time.Time) string {
+	settings := activeTimestampSettings.Load()
+	return t.In(settings.location).Format(settings.format)
+}
+
+// Timestamp 对外返回的时间戳，JSON序列化时按配置的时区和格式输出
+// 同时实现了driver.Valuer和sql.Scanner，可直接作为数据库字段使用（支持autoCreateTime/autoUpdateTime）
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp 由time.Time构造时间戳
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+// MarshalJSON 按配置的时区和格式输出时间戳
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + FormatTimestamp(t.Time) + `"`), nil
+}
+
+// UnmarshalJSON 解析RFC3339或配置格式的时间戳（Redis缓存中的数据按配置格式写入）
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if err := t.Time.UnmarshalJSON(data); err == nil {
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("invalid timestamp %s", data)
+	}
+	settings := activeTimestampSettings.Load()
+	parsed, err := time.ParseInLocation(settings.format, string(data[1:len(data)-1]), settings.location)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %s: %w", data, err)
+	}
+	t.Time = parsed
+	return nil
+}
+
+// Value 以time.Time写入数据库
+func (t Timestamp) Value() (driver.Value, error) {
+	return t.Time, nil
+}
+
+// Scan 从数据库读取时间，兼容驱动返回的time.Time和字符串
+func (t *Timestamp) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		t.Time = time.Time{}
+	case time.Time:
+		t.Time = v
+	case string:
+		return t.scanString(v)
+	case []byte:
+		return t.scanString(string(v))
+	default:
+		return fmt.Errorf("unsupported timestamp value %T", value)
+	}
+	return nil
+}
+
+// scanString 解析数据库中以字符串存储的时间
+func (t *Timestamp) scanString(s string) error {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999"} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid timestamp %q", s)
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// lockTokenLength 锁持有者令牌长度
+const lockTokenLength = 16
+
+// ErrLockNotHeld 释放锁时锁已过期或已被其他请求持有，未执行删除
+var ErrLockNotHeld = errors.New("distributed lock not held by caller")
+
+// DistributedLocker 分布式锁接口，Etcd和Redis仓库均实现该接口
+// 每次加锁生成唯一的持有者令牌作为锁的值，释放时只删除令牌仍匹配的锁，
+// 避免锁过期后被其他请求重新获取时，原持有者误删新持有者的锁
+type DistributedLocker interface {
+	// GetDistributedLock 尝试获取锁，ttl为锁自动过期时间（秒），成功时返回持有者令牌，锁已被占用时返回false
+	GetDistributedLock(ctx context.Context, key string, ttl int) (string, bool, error)
+	// ReleaseDistributedLock 使用加锁时返回的令牌释放锁，令牌不匹配时返回ErrLockNotHeld
+	ReleaseDistributedLock(ctx context.Context, key, token string) error
+}
+
+// RenewableLocker 支持自动续期的分布式锁，持锁期间后台持续续期，避免业务耗时超过ttl时锁提前过期
+type RenewableLocker interface {
+	DistributedLocker
+	// GetDistributedLockWithRenewal 获取锁并启动后台续期，返回持有者令牌和stop，stop停止续期且等待续期协程退出
+	// 获取失败时stop为nil；stop不释放锁，调用方仍需调用ReleaseDistributedLock
+	GetDistributedLockWithRenewal(ctx context.Context, key string, ttl int) (string, bool, context.CancelFunc, error)
+}
+
+// 编译期检查两种后端均实现RenewableLocker
+var (
+	_ RenewableLocker = (*ETCDRepository)(nil)
+	_ RenewableLocker = (*RedisRepository)(nil)
+)
+
+// newLockToken 生成锁持有者令牌
+func newLockToken() (string, error) {
+	token, err := generateRandomString(lockTokenLength)
+	if err != nil {
+		return "", fmt.Errorf("generate lock token failed: %v", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// etcdFallbackMaxEntries 降级缓存最多保存的键数量，超出后不再缓存新键（已缓存的键仍会更新）
+// 黑名单按用户缓存，限制数量避免大量用户访问时缓存无限增长
+const etcdFallbackMaxEntries = 100000
+
+// etcdCachedValue 最近一次成功读取的值及读取时间
+type etcdCachedValue struct {
+	value     any
+	fetchedAt time.Time
+}
+
+// etcdFallbackCache Etcd读取结果的内存缓存
+// Etcd读取成功时更新缓存，读取失败时返回最近一次成功读取的值，使秒杀主流程在Etcd故障期间继续可用
+type etcdFallbackCache struct {
+	mu      sync.RWMutex
+	entries map[string]etcdCachedValue // 键为Etcd键
+}
+
+// newEtcdFallbackCache 创建降级缓存
+func newEtcdFallbackCache() *etcdFallbackCache {
+	return &etcdFallbackCache{entries: make(map[string]etcdCachedValue)}
+}
+
+// store 记录键的最新值
+func (c *etcdFallbackCache) store(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= etcdFallbackMaxEntries {
+		return
+	}
+	c.entries[key] = etcdCachedValue{value: value, fetchedAt: time.Now()}
+}
+
+// load 读取键的缓存值
+func (c *etcdFallbackCache) load(key string) (etcdCachedValue, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cached, ok := c.entries[key]
+	return cached, ok
+}
+
+// withEtcdFallback 根据Etcd读取结果更新缓存或降级返回缓存值
+// 未开启降级、读取成功或没有缓存值时原样返回读取结果
+func withEtcdFallback[T any](cache *etcdFallbackCache, key string, value T, err error) (T, error) {
+	if cache == nil {
+		return value, err
+	}
+	if err == nil {
+		cache.store(key, value)
+		return value, nil
+	}
+
+	cached, ok := cache.load(key)
+	if !ok {
+		return value, err
+	}
+	slog.Warn("Etcd unavailable, serving last known value",
+		"key", key,
+		"value", cached.value,
+		"stale_for", time.Since(cached.fetchedAt).String(),
+		"error", err,
+	)
+	return cached.value.(T), nil
+}
@@ -5,27 +5,51 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"seckill_system/config"
 	"seckill_system/global"
+	"seckill_system/model"
+	"sort"
 	"strconv"
 	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
+// MinRateLimit 限流配置允许的最小值（次/分钟）
+const MinRateLimit = 1
+
+// DefaultIPRateLimit 客户端IP限流的默认值（次/分钟）
+// 高于用户限流，避免NAT或代理后的多个用户共用出口IP时被误伤
+const DefaultIPRateLimit = 60
+
 // ETCDRepository 封装与ETCD交互的仓库操作
 type ETCDRepository struct {
-	client *clientv3.Client // ETCD客户端实例
+	client   *clientv3.Client   // ETCD客户端实例
+	fallback *etcdFallbackCache // 降级缓存，未开启降级时为nil
 }
 
 // NewETCDRepository 创建ETCD仓库实例
+// ETCD客户端未初始化时panic
 func NewETCDRepository() *ETCDRepository {
-	return &ETCDRepository{
+	global.MustClient("Etcd", "InitEtcd", global.EtcdClient != nil)
+	repo := &ETCDRepository{
 		client: global.EtcdClient, // 使用全局ETCD客户端
 	}
+	if global.EtcdCacheFallback {
+		repo.fallback = newEtcdFallbackCache()
+	}
+	return repo
 }
 
 // GetSeckillEnabled 获取秒杀开关状态
+// 开启降级时Etcd读取失败返回最近一次成功读取的值
 func (e *ETCDRepository) GetSeckillEnabled(ctx context.Context) (bool, error) {
+	enabled, err := e.getSeckillEnabled(ctx)
+	return withEtcdFallback(e.fallback, global.EtcdKeySeckillEnabled, enabled, err)
+}
+
+// getSeckillEnabled 从Etcd读取秒杀开关状态
+func (e *ETCDRepository) getSeckillEnabled(ctx context.Context) (bool, error) {
 	// 从ETCD获取秒杀开关配置
 	resp, err := e.client.Get(ctx, global.EtcdKeySeckillEnabled)
 	if err != nil {
@@ -68,8 +92,45 @@ func (e *ETCDRepository) SetSeckillEnabled(ctx context.Context, enabled bool) er
 	return nil
 }
 
+// GetReadOnly 获取只读模式开关，未配置时为false
+func (e *ETCDRepository) GetReadOnly(ctx context.Context) (bool, error) {
+	resp, err := e.client.Get(ctx, global.EtcdKeyReadOnly)
+	if err != nil {
+		return false, fmt.Errorf("get read-only mode failed: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return false, nil
+	}
+	return string(resp.Kvs[0].Value) == "true", nil
+}
+
+// WatchReadOnly 监听只读模式开关变化，键被删除时视为关闭
+func (e *ETCDRepository) WatchReadOnly(ctx context.Context, callback func(readOnly bool)) {
+	rch := e.client.Watch(ctx, global.EtcdKeyReadOnly)
+
+	go func() {
+		for wresp := range rch {
+			for _, ev := range wresp.Events {
+				readOnly := ev.Type == clientv3.EventTypePut && string(ev.Kv.Value) == "true"
+				slog.Info("Read-only mode config changed",
+					"type", ev.Type,
+					"read_only", readOnly,
+				)
+				callback(readOnly)
+			}
+		}
+	}()
+}
+
 // GetRateLimitConfig 获取限流配置
+// 开启降级时Etcd读取失败返回最近一次成功读取的值
 func (e *ETCDRepository) GetRateLimitConfig(ctx context.Context) (int64, error) {
+	limit, err := e.getRateLimitConfig(ctx)
+	return withEtcdFallback(e.fallback, global.EtcdKeyRateLimit, limit, err)
+}
+
+// getRateLimitConfig 从Etcd读取限流配置
+func (e *ETCDRepository) getRateLimitConfig(ctx context.Context) (int64, error) {
 	// 从ETCD获取限流配置
 	resp, err := e.client.Get(ctx, global.EtcdKeyRateLimit)
 	if err != nil {
@@ -92,6 +153,15 @@ func (e *ETCDRepository) GetRateLimitConfig(ctx context.Context) (int64, error)
 		return 10, nil // 解析失败返回默认值
 	}
 
+	// 直接写入ETCD的值可能绕过接口校验，非正数会导致所有请求被拒绝，下限取1
+	if limit < MinRateLimit {
+		slog.Warn("Invalid rate limit config stored in etcd, clamping to minimum",
+			"value", limit,
+			"min", MinRateLimit,
+		)
+		limit = MinRateLimit
+	}
+
 	slog.Info("Retrieved rate limit config",
 		"key", global.EtcdKeyRateLimit,
 		"value", limit,
@@ -99,6 +169,35 @@ func (e *ETCDRepository) GetRateLimitConfig(ctx context.Context) (int64, error)
 	return limit, nil
 }
 
+// GetIPRateLimitConfig 获取客户端IP限流配置（次/分钟），未配置或解析失败时返回DefaultIPRateLimit
+func (e *ETCDRepository) GetIPRateLimitConfig(ctx context.Context) (int64, error) {
+	resp, err := e.client.Get(ctx, global.EtcdKeyIPRateLimit)
+	if err != nil {
+		return DefaultIPRateLimit, fmt.Errorf("get ip rate limit config failed: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return DefaultIPRateLimit, nil
+	}
+
+	limit, err := strconv.ParseInt(string(resp.Kvs[0].Value), 10, 64)
+	if err != nil {
+		slog.Warn("Failed to parse ip rate limit config, using default value",
+			"value", string(resp.Kvs[0].Value),
+			"default", DefaultIPRateLimit,
+			"error", err,
+		)
+		return DefaultIPRateLimit, nil
+	}
+	if limit < MinRateLimit {
+		slog.Warn("Invalid ip rate limit config stored in etcd, clamping to minimum",
+			"value", limit,
+			"min", MinRateLimit,
+		)
+		limit = MinRateLimit
+	}
+	return limit, nil
+}
+
 // SetRateLimitConfig 设置限流配置
 func (e *ETCDRepository) SetRateLimitConfig(ctx context.Context, limit int64) error {
 	// 将限流值转换为字符串并写入ETCD
@@ -114,35 +213,124 @@ func (e *ETCDRepository) SetRateLimitConfig(ctx context.Context, limit int64) er
 	return nil
 }
 
-// AddToBlacklist 添加用户到黑名单
-func (e *ETCDRepository) AddToBlacklist(ctx context.Context, userId int64, reason string, duration time.Duration) error {
+// GetRateLimitAllowlist 获取限流豁免名单，未配置时返回nil
+func (e *ETCDRepository) GetRateLimitAllowlist(ctx context.Context) (*model.RateLimitAllowlist, error) {
+	resp, err := e.client.Get(ctx, global.EtcdKeyRateLimitAllowlist)
+	if err != nil {
+		return nil, fmt.Errorf("get rate limit allowlist failed: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+
+	var allowlist model.RateLimitAllowlist
+	if err := json.Unmarshal(resp.Kvs[0].Value, &allowlist); err != nil {
+		return nil, fmt.Errorf("parse rate limit allowlist failed: %v", err)
+	}
+	return &allowlist, nil
+}
+
+// SetRateLimitAllowlist 设置限流豁免名单
+func (e *ETCDRepository) SetRateLimitAllowlist(ctx context.Context, allowlist *model.RateLimitAllowlist) error {
+	data, err := json.Marshal(allowlist)
+	if err != nil {
+		return fmt.Errorf("marshal rate limit allowlist failed: %v", err)
+	}
+
+	if _, err := e.client.Put(ctx, global.EtcdKeyRateLimitAllowlist, string(data)); err != nil {
+		return fmt.Errorf("set rate limit allowlist failed: %v", err)
+	}
+
+	slog.Info("Rate limit allowlist updated",
+		"key", global.EtcdKeyRateLimitAllowlist,
+		"user_ids", len(allowlist.UserIds),
+		"ips", len(allowlist.IPs),
+	)
+	return nil
+}
+
+// GetGoodsAllowlist 获取秒杀商品准入名单，未配置时返回nil
+func (e *ETCDRepository) GetGoodsAllowlist(ctx context.Context) (*model.GoodsAllowlist, error) {
+	resp, err := e.client.Get(ctx, global.EtcdKeyGoodsAllowlist)
+	if err != nil {
+		return nil, fmt.Errorf("get goods allowlist failed: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+
+	var allowlist model.GoodsAllowlist
+	if err := json.Unmarshal(resp.Kvs[0].Value, &allowlist); err != nil {
+		return nil, fmt.Errorf("parse goods allowlist failed: %v", err)
+	}
+	return &allowlist, nil
+}
+
+// SetGoodsAllowlist 设置秒杀商品准入名单
+func (e *ETCDRepository) SetGoodsAllowlist(ctx context.Context, allowlist *model.GoodsAllowlist) error {
+	data, err := json.Marshal(allowlist)
+	if err != nil {
+		return fmt.Errorf("marshal goods allowlist failed: %v", err)
+	}
+
+	if _, err := e.client.Put(ctx, global.EtcdKeyGoodsAllowlist, string(data)); err != nil {
+		return fmt.Errorf("set goods allowlist failed: %v", err)
+	}
+
+	slog.Info("Goods allowlist updated",
+		"key", global.EtcdKeyGoodsAllowlist,
+		"goods_ids", len(allowlist.GoodsIds),
+	)
+	return nil
+}
+
+// newBlacklistInfo 构造黑名单条目的存储内容，时间按配置的时区以RFC3339存储
+func newBlacklistInfo(userId int64, reason string, duration time.Duration, now time.Time) map[string]any {
+	now = now.In(config.TimeLocation())
+	return map[string]any{
+		"user_id":  userId,
+		"reason":   reason,
+		"add_time": now.Format(time.RFC3339),
+		"expire":   now.Add(duration).Format(time.RFC3339),
+	}
+}
+
+// AddToBlacklist 添加用户到黑名单，返回封禁是否需要人工复核
+// 封禁通过租约按时长自动过期；创建租约失败时改为不带租约写入，封禁照常生效但不会自动过期，
+// 条目标记needs_review，需要人工复核后手动移除
+func (e *ETCDRepository) AddToBlacklist(ctx context.Context, userId int64, reason string, duration time.Duration) (needsReview bool, err error) {
 	// 构造黑名单键名
 	key := fmt.Sprintf("%s%d", global.EtcdKeyBlacklist, userId)
 
 	// 构造黑名单信息结构
-	blacklistInfo := map[string]any{
-		"user_id":  userId,
-		"reason":   reason,
-		"add_time": time.Now().Format(time.RFC3339),
-		"expire":   time.Now().Add(duration).Format(time.RFC3339),
+	blacklistInfo := newBlacklistInfo(userId, reason, duration, time.Now())
+
+	// 创建租约实现自动过期，失败时降级为永久封禁
+	var opts []clientv3.OpOption
+	leaseResp, leaseErr := e.client.Grant(ctx, int64(duration.Seconds()))
+	if leaseErr == nil {
+		opts = append(opts, clientv3.WithLease(leaseResp.ID))
+	} else {
+		needsReview = true
+		blacklistInfo["needs_review"] = true
+		slog.Error("Failed to grant blacklist lease, adding user without expiry, manual review required",
+			"user_id", userId,
+			"reason", reason,
+			"duration", duration,
+			"error", leaseErr,
+		)
 	}
 
 	// 序列化为JSON
 	data, err := json.Marshal(blacklistInfo)
 	if err != nil {
-		return fmt.Errorf("marshal blacklist info failed: %v", err)
-	}
-
-	// 创建租约实现自动过期
-	leaseResp, err := e.client.Grant(ctx, int64(duration.Seconds()))
-	if err != nil {
-		return fmt.Errorf("grant lease failed: %v", err)
+		return false, fmt.Errorf("marshal blacklist info failed: %v", err)
 	}
 
-	// 写入ETCD并关联租约
-	_, err = e.client.Put(ctx, key, string(data), clientv3.WithLease(leaseResp.ID))
+	// 写入ETCD，租约创建成功时关联租约
+	_, err = e.client.Put(ctx, key, string(data), opts...)
 	if err != nil {
-		return fmt.Errorf("add to blacklist failed: %v", err)
+		return false, fmt.Errorf("add to blacklist failed: %v", err)
 	}
 
 	slog.Info("User added to blacklist",
@@ -150,8 +338,72 @@ func (e *ETCDRepository) AddToBlacklist(ctx context.Context, userId int64, reaso
 		"reason", reason,
 		"duration", duration,
 		"expire_time", blacklistInfo["expire"],
+		"needs_review", needsReview,
 	)
-	return nil
+	return needsReview, nil
+}
+
+// BlacklistAddition 批量加入黑名单的单个用户
+type BlacklistAddition struct {
+	UserId   int64         // 用户ID
+	Reason   string        // 加入黑名单的原因
+	Duration time.Duration // 封禁时长，不足1秒按1秒计
+}
+
+// maxBlacklistTxnOps 单个Etcd事务最多包含的写入操作数，与Etcd服务端--max-txn-ops默认值一致
+const maxBlacklistTxnOps = 128
+
+// BatchAddToBlacklist 批量添加用户到黑名单
+// 相同封禁时长的用户共用一个租约，写入按maxBlacklistTxnOps分批在事务中提交；
+// 返回写入失败的用户及其错误，租约创建或事务失败只影响对应的用户，全部成功时返回空映射
+func (e *ETCDRepository) BatchAddToBlacklist(ctx context.Context, additions []BlacklistAddition) map[int64]error {
+	failed := make(map[int64]error)
+	now := time.Now()
+
+	// 按封禁时长创建租约，同一批中相同时长的条目同时过期
+	leases := make(map[int64]clientv3.LeaseID)
+	var (
+		ops     []clientv3.Op
+		userIds []int64
+	)
+	for _, addition := range additions {
+		ttl := max(int64(addition.Duration.Seconds()), 1)
+		leaseId, ok := leases[ttl]
+		if !ok {
+			lease, err := e.client.Grant(ctx, ttl)
+			if err != nil {
+				failed[addition.UserId] = fmt.Errorf("grant lease failed: %v", err)
+				continue
+			}
+			leaseId = lease.ID
+			leases[ttl] = leaseId
+		}
+
+		data, err := json.Marshal(newBlacklistInfo(addition.UserId, addition.Reason, addition.Duration, now))
+		if err != nil {
+			failed[addition.UserId] = fmt.Errorf("marshal blacklist info failed: %v", err)
+			continue
+		}
+		key := fmt.Sprintf("%s%d", global.EtcdKeyBlacklist, addition.UserId)
+		ops = append(ops, clientv3.OpPut(key, string(data), clientv3.WithLease(leaseId)))
+		userIds = append(userIds, addition.UserId)
+	}
+
+	for start := 0; start < len(ops); start += maxBlacklistTxnOps {
+		end := min(start+maxBlacklistTxnOps, len(ops))
+		if _, err := e.client.Txn(ctx).Then(ops[start:end]...).Commit(); err != nil {
+			for _, userId := range userIds[start:end] {
+				failed[userId] = fmt.Errorf("add to blacklist failed: %v", err)
+			}
+		}
+	}
+
+	slog.Info("Users added to blacklist in batch",
+		"count", len(additions),
+		"failed", len(failed),
+		"leases", len(leases),
+	)
+	return failed
 }
 
 // RemoveFromBlacklist 从黑名单移除用户
@@ -170,9 +422,16 @@ func (e *ETCDRepository) RemoveFromBlacklist(ctx context.Context, userId int64)
 }
 
 // IsInBlacklist 检查用户是否在黑名单中
+// 开启降级时Etcd读取失败返回该用户最近一次成功检查的结果
 func (e *ETCDRepository) IsInBlacklist(ctx context.Context, userId int64) (bool, error) {
 	// 构造键名并查询
 	key := fmt.Sprintf("%s%d", global.EtcdKeyBlacklist, userId)
+	inBlacklist, err := e.isInBlacklist(ctx, key, userId)
+	return withEtcdFallback(e.fallback, key, inBlacklist, err)
+}
+
+// isInBlacklist 从Etcd检查黑名单键是否存在
+func (e *ETCDRepository) isInBlacklist(ctx context.Context, key string, userId int64) (bool, error) {
 	resp, err := e.client.Get(ctx, key)
 	if err != nil {
 		return false, fmt.Errorf("check blacklist failed: %v", err)
@@ -188,8 +447,15 @@ func (e *ETCDRepository) IsInBlacklist(ctx context.Context, userId int64) (bool,
 	return inBlacklist, nil
 }
 
+// 黑名单列表分页相关常量
+const (
+	DefaultBlacklistPageSize = 20  // 默认每页条数
+	MaxBlacklistPageSize     = 100 // 最大每页条数
+)
+
 // GetBlacklist 获取黑名单列表
-func (e *ETCDRepository) GetBlacklist(ctx context.Context) ([]map[string]any, error) {
+// 结果按加入时间倒序排列，limit大于0时只返回最近的limit条
+func (e *ETCDRepository) GetBlacklist(ctx context.Context, limit int) ([]map[string]any, error) {
 	// 使用前缀查询获取所有黑名单条目
 	resp, err := e.client.Get(ctx, global.EtcdKeyBlacklist, clientv3.WithPrefix())
 	if err != nil {
@@ -210,12 +476,38 @@ func (e *ETCDRepository) GetBlacklist(ctx context.Context) ([]map[string]any, er
 		blacklist = append(blacklist, info)
 	}
 
+	// 前缀查询按用户ID字典序返回，这里改为按加入时间倒序
+	blacklist = SortBlacklistByAddTime(blacklist, limit)
+
 	slog.Info("Retrieved blacklist",
 		"count", len(blacklist),
+		"limit", limit,
 	)
 	return blacklist, nil
 }
 
+// SortBlacklistByAddTime 按加入时间倒序排列黑名单条目
+// 无法解析add_time的条目排在最后，limit大于0时截断结果
+func SortBlacklistByAddTime(blacklist []map[string]any, limit int) []map[string]any {
+	sort.SliceStable(blacklist, func(i, j int) bool {
+		return blacklistAddTime(blacklist[i]).After(blacklistAddTime(blacklist[j]))
+	})
+	if limit > 0 && len(blacklist) > limit {
+		blacklist = blacklist[:limit]
+	}
+	return blacklist
+}
+
+// blacklistAddTime 解析黑名单条目的加入时间，解析失败返回零值
+func blacklistAddTime(info map[string]any) time.Time {
+	addTime, _ := info["add_time"].(string)
+	t, err := time.Parse(time.RFC3339, addTime)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 // WatchSeckillConfig 监听秒杀配置变化
 func (e *ETCDRepository) WatchSeckillConfig(ctx context.Context, callback func(key, value string)) {
 	// 创建监听通道
@@ -238,49 +530,237 @@ func (e *ETCDRepository) WatchSeckillConfig(ctx context.Context, callback func(k
 	}()
 }
 
-// GetDistributedLock 获取分布式锁
-func (e *ETCDRepository) GetDistributedLock(ctx context.Context, key string, ttl int) (bool, error) {
+// WatchRateLimitAllowlist 监听限流豁免名单变化
+// 名单被删除时回调参数为nil，内容无法解析时忽略本次变更
+func (e *ETCDRepository) WatchRateLimitAllowlist(ctx context.Context, callback func(allowlist *model.RateLimitAllowlist)) {
+	rch := e.client.Watch(ctx, global.EtcdKeyRateLimitAllowlist)
+
+	go func() {
+		for wresp := range rch {
+			for _, ev := range wresp.Events {
+				if ev.Type == clientv3.EventTypeDelete {
+					callback(nil)
+					continue
+				}
+
+				var allowlist model.RateLimitAllowlist
+				if err := json.Unmarshal(ev.Kv.Value, &allowlist); err != nil {
+					slog.Warn("Ignoring invalid rate limit allowlist",
+						"value", string(ev.Kv.Value),
+						"error", err,
+					)
+					continue
+				}
+				callback(&allowlist)
+			}
+		}
+	}()
+}
+
+// WatchGoodsAllowlist 监听秒杀商品准入名单变化
+// 名单被删除时回调参数为nil，内容无法解析时忽略本次变更
+func (e *ETCDRepository) WatchGoodsAllowlist(ctx context.Context, callback func(allowlist *model.GoodsAllowlist)) {
+	rch := e.client.Watch(ctx, global.EtcdKeyGoodsAllowlist)
+
+	go func() {
+		for wresp := range rch {
+			for _, ev := range wresp.Events {
+				if ev.Type == clientv3.EventTypeDelete {
+					callback(nil)
+					continue
+				}
+
+				var allowlist model.GoodsAllowlist
+				if err := json.Unmarshal(ev.Kv.Value, &allowlist); err != nil {
+					slog.Warn("Ignoring invalid goods allowlist",
+						"value", string(ev.Kv.Value),
+						"error", err,
+					)
+					continue
+				}
+				callback(&allowlist)
+			}
+		}
+	}()
+}
+
+// GetDistributedLock 获取分布式锁，锁的值为本次加锁生成的持有者令牌
+func (e *ETCDRepository) GetDistributedLock(ctx context.Context, key string, ttl int) (string, bool, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return "", false, err
+	}
+
 	// 创建租约
 	lease, err := e.client.Grant(ctx, int64(ttl))
 	if err != nil {
-		return false, fmt.Errorf("grant lease failed: %v", err)
+		return "", false, fmt.Errorf("grant lease failed: %v", err)
 	}
 
 	// 使用事务实现原子操作
 	resp, err := e.client.Txn(ctx).
-		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).        // 检查key不存在
-		Then(clientv3.OpPut(key, "locked", clientv3.WithLease(lease.ID))). // 写入锁
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).     // 检查key不存在
+		Then(clientv3.OpPut(key, token, clientv3.WithLease(lease.ID))). // 写入锁
 		Commit()
 	if err != nil {
-		return false, fmt.Errorf("etcd transaction failed: %v", err)
+		return "", false, fmt.Errorf("etcd transaction failed: %v", err)
 	}
 
-	if resp.Succeeded {
-		slog.Info("Distributed lock acquired",
+	if !resp.Succeeded {
+		slog.Info("Distributed lock acquisition failed, key already exists",
 			"key", key,
-			"ttl", ttl,
 		)
-	} else {
+		return "", false, nil
+	}
+	slog.Info("Distributed lock acquired",
+		"key", key,
+		"ttl", ttl,
+	)
+	return token, true, nil
+}
+
+// GetDistributedLockWithRenewal 获取分布式锁并通过租约KeepAlive持续续期，直到调用返回的stop
+// 续期使用独立的context，不受获取锁时ctx超时的影响
+func (e *ETCDRepository) GetDistributedLockWithRenewal(ctx context.Context, key string, ttl int) (string, bool, context.CancelFunc, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return "", false, nil, err
+	}
+
+	lease, err := e.client.Grant(ctx, int64(ttl))
+	if err != nil {
+		return "", false, nil, fmt.Errorf("grant lease failed: %v", err)
+	}
+
+	resp, err := e.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, token, clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil || !resp.Succeeded {
+		// 未持有锁时撤销租约，避免遗留无用租约
+		if _, revokeErr := e.client.Revoke(context.Background(), lease.ID); revokeErr != nil {
+			slog.Warn("Failed to revoke unused lock lease",
+				"key", key,
+				"lease_id", lease.ID,
+				"error", revokeErr,
+			)
+		}
+		if err != nil {
+			return "", false, nil, fmt.Errorf("etcd transaction failed: %v", err)
+		}
 		slog.Info("Distributed lock acquisition failed, key already exists",
 			"key", key,
 		)
+		return "", false, nil, nil
 	}
-	return resp.Succeeded, nil
+
+	keepCtx, cancel := context.WithCancel(context.Background())
+	keepAlive, err := e.client.KeepAlive(keepCtx, lease.ID)
+	if err != nil {
+		cancel()
+		// 无法续期时锁仍按ttl自动过期，释放锁后返回错误由调用方决定是否重试
+		if releaseErr := e.ReleaseDistributedLock(context.Background(), key, token); releaseErr != nil {
+			slog.Warn("Failed to release lock after keepalive failure",
+				"key", key,
+				"error", releaseErr,
+			)
+		}
+		return "", false, nil, fmt.Errorf("lease keepalive failed: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range keepAlive {
+			// 消费续期响应，通道关闭表示续期已停止
+		}
+		if keepCtx.Err() == nil {
+			slog.Warn("Distributed lock renewal stopped unexpectedly",
+				"key", key,
+				"lease_id", lease.ID,
+			)
+		}
+	}()
+
+	slog.Info("Distributed lock acquired with renewal",
+		"key", key,
+		"ttl", ttl,
+		"lease_id", lease.ID,
+	)
+	return token, true, func() {
+		cancel()
+		<-done
+	}, nil
 }
 
-// ReleaseDistributedLock 释放分布式锁
-func (e *ETCDRepository) ReleaseDistributedLock(ctx context.Context, key string) error {
-	// 删除锁键
-	_, err := e.client.Delete(ctx, key)
+// ReleaseDistributedLock 释放分布式锁，仅当锁的值仍为token时删除
+// 锁已过期或已被其他请求重新获取时不删除，返回ErrLockNotHeld
+func (e *ETCDRepository) ReleaseDistributedLock(ctx context.Context, key, token string) error {
+	resp, err := e.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.Value(key), "=", token)).
+		Then(clientv3.OpDelete(key)).
+		Commit()
 	if err != nil {
 		return fmt.Errorf("delete etcd key failed: %v", err)
 	}
+	if !resp.Succeeded {
+		slog.Warn("Distributed lock not released, no longer held by caller",
+			"key", key,
+		)
+		return ErrLockNotHeld
+	}
 	slog.Info("Distributed lock released",
 		"key", key,
 	)
 	return nil
 }
 
+// ListLocks 列出prefix命名空间下当前持有的锁键，按键字典序排列
+func (e *ETCDRepository) ListLocks(ctx context.Context, prefix string) ([]string, error) {
+	resp, err := e.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, fmt.Errorf("list locks failed: %v", err)
+	}
+	keys := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		keys = append(keys, string(kv.Key))
+	}
+	return keys, nil
+}
+
+// ListLockInfos 列出prefix命名空间下当前持有的锁及其租约剩余有效期，按键字典序排列
+// 单个租约查询失败时该锁的剩余有效期记为-1，不影响其余锁
+func (e *ETCDRepository) ListLockInfos(ctx context.Context, prefix string) ([]model.LockInfo, error) {
+	resp, err := e.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, fmt.Errorf("list locks failed: %v", err)
+	}
+
+	locks := make([]model.LockInfo, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		info := model.LockInfo{Key: string(kv.Key), LeaseId: kv.Lease, TTLSeconds: -1}
+		if kv.Lease != 0 {
+			ttlResp, err := e.client.TimeToLive(ctx, clientv3.LeaseID(kv.Lease))
+			if err != nil {
+				slog.Warn("Failed to get lock lease TTL",
+					"key", info.Key,
+					"lease_id", kv.Lease,
+					"error", err,
+				)
+			} else {
+				info.TTLSeconds = ttlResp.TTL
+			}
+		}
+		locks = append(locks, info)
+	}
+
+	slog.Info("Distributed locks listed",
+		"prefix", prefix,
+		"count", len(locks),
+	)
+	return locks, nil
+}
+
 // Close 关闭ETCD客户端连接
 func (e *ETCDRepository) Close() error {
 	if err := e.client.Close(); err != nil {
@@ -1,26 +1,97 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"seckill_system/errs"
 	"seckill_system/global"
 	"seckill_system/model"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// 商品搜索相关常量
+const (
+	DefaultSearchLimit = 20 // 默认返回条数
+	MaxSearchLimit     = 50 // 最大返回条数
+)
+
+// 活动列表分页相关常量
+const (
+	DefaultActivePageSize = 20  // 默认每页条数
+	MaxActivePageSize     = 100 // 最大每页条数
+)
+
+// 审计记录分页相关常量
+const (
+	DefaultAuditPageSize = 20  // 默认每页条数
+	MaxAuditPageSize     = 100 // 最大每页条数
+)
+
+// ErrPromotionNotFound 商品没有对应的秒杀促销活动
+var ErrPromotionNotFound = errs.ErrPromotionNotFound
+
+// likeEscaper LIKE通配符转义器，转义字符本身也需要转义
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
 // GoodRepository 商品数据访问层
 // 负责商品相关数据的数据库操作
 type GoodRepository struct {
-	db *gorm.DB // 数据库连接实例
+	db          *gorm.DB      // 数据库连接实例
+	readTimeout time.Duration // 只读查询单次执行超时，0表示不限制
 }
 
 // NewGoodRepository 创建商品仓库实例
+// 数据库客户端未初始化时panic
 func NewGoodRepository() *GoodRepository {
+	global.MustClient("MySQL", "InitMySQL", global.DBClient != nil)
 	return &GoodRepository{
-		db: global.DBClient, // 使用全局数据库客户端
+		db:          global.DBClient,      // 使用全局数据库客户端
+		readTimeout: global.DBReadTimeout, // 只读查询单次执行超时
+	}
+}
+
+// withReadRetry 执行只读查询，每次执行受readTimeout限制
+// 遇到超时、锁等待超时等瞬时错误时以指数退避重试，最多执行MaxReadAttempts次；fn可能被执行多次
+func (dao *GoodRepository) withReadRetry(op string, fn func(db *gorm.DB) error) error {
+	var err error
+	for attempt := 1; attempt <= MaxReadAttempts; attempt++ {
+		err = dao.readOnce(fn)
+		if err == nil || !IsTransientReadError(err) {
+			return err
+		}
+		if attempt == MaxReadAttempts {
+			break
+		}
+		delay := transactionRetryDelay(attempt)
+		slog.Warn("Transient database read error, retrying",
+			"operation", op,
+			"attempt", attempt,
+			"retry_in", delay,
+			"error", err,
+		)
+		time.Sleep(delay)
+	}
+	slog.Error("Database read retries exhausted",
+		"operation", op,
+		"attempts", MaxReadAttempts,
+		"error", err,
+	)
+	return fmt.Errorf("%s failed after %d attempts: %w", op, MaxReadAttempts, err)
+}
+
+// readOnce 以带超时的上下文执行一次只读查询
+func (dao *GoodRepository) readOnce(fn func(db *gorm.DB) error) error {
+	if dao.readTimeout <= 0 {
+		return fn(dao.db)
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), dao.readTimeout)
+	defer cancel()
+	return fn(dao.db.WithContext(ctx))
 }
 
 // ResetDataBase 重置数据库数据
@@ -74,16 +145,76 @@ func (dao *GoodRepository) ResetDataBase(goodsId int) error {
 	})
 }
 
+// ResetDataBaseBatch 在单个事务中批量重置多个商品
+// 无效或不存在的商品ID记录为失败并跳过，数据库错误则回滚整个事务
+func (dao *GoodRepository) ResetDataBaseBatch(goodsIds []int) ([]model.ResetResult, error) {
+	var results []model.ResetResult
+	err := dao.WithTransaction(func(tx *gorm.DB) error {
+		results = make([]model.ResetResult, 0, len(goodsIds))
+		for _, goodsId := range goodsIds {
+			// 参数验证
+			if goodsId <= 0 {
+				results = append(results, model.ResetResult{
+					GoodsId: goodsId,
+					Error:   fmt.Sprintf("invalid goodsId: %d", goodsId),
+				})
+				continue
+			}
+
+			// 验证商品是否存在
+			if _, err := dao.FindGoodById(int64(goodsId)); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					results = append(results, model.ResetResult{
+						GoodsId: goodsId,
+						Error:   fmt.Sprintf("goods not found: %d", goodsId),
+					})
+					continue
+				}
+				return fmt.Errorf("failed to find goods %d: %w", goodsId, err)
+			}
+
+			// 清除订单记录并重置促销库存
+			if err := dao.ClearOrderByGoodsId(tx, int64(goodsId)); err != nil {
+				return fmt.Errorf("failed to clear orders for goods %d: %w", goodsId, err)
+			}
+			if err := dao.ResetPromotionCountByGoodsId(tx, int64(goodsId), int64(global.BookStockCount)); err != nil {
+				return fmt.Errorf("failed to reset promotion count for goods %d: %w", goodsId, err)
+			}
+			results = append(results, model.ResetResult{GoodsId: goodsId, Success: true})
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Batch database reset failed",
+			"goods_ids", goodsIds,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	slog.Info("Batch database reset completed",
+		"goods_ids", goodsIds,
+		"stock_count", global.BookStockCount,
+	)
+	return results, nil
+}
+
 // FindGoodById 根据商品ID查询商品信息
 func (dao *GoodRepository) FindGoodById(goodsId int64) (model.Goods, error) {
 	var good model.Goods
-	// 根据goods_id查询商品信息
-	err := dao.db.Where("goods_id = ?", goodsId).First(&good).Error
+	// 根据goods_id查询商品信息，表被锁时超时重试
+	err := dao.withReadRetry("find goods", func(db *gorm.DB) error {
+		return db.Where("goods_id = ?", goodsId).First(&good).Error
+	})
 	if err != nil {
 		slog.Warn("Good not found in database",
 			"goods_id", goodsId,
 			"error", err,
 		)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 同时保留ErrRecordNotFound，兼容原有判断
+			err = fmt.Errorf("%w: goods %d: %w", errs.ErrGoodsNotFound, goodsId, err)
+		}
 	} else {
 		slog.Info("Good found in database",
 			"goods_id", goodsId,
@@ -93,16 +224,88 @@ func (dao *GoodRepository) FindGoodById(goodsId int64) (model.Goods, error) {
 	return good, err
 }
 
+// NormalizeSearchLimit 规范化搜索返回条数：非正数使用默认值，超过上限则截断
+func NormalizeSearchLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultSearchLimit
+	}
+	return min(limit, MaxSearchLimit)
+}
+
+// SearchGoodsByTitle 根据标题关键字模糊搜索商品
+// 关键字中的LIKE通配符会被转义，返回条数受MaxSearchLimit限制
+func (dao *GoodRepository) SearchGoodsByTitle(q string, limit int) ([]model.Goods, error) {
+	goods, _, err := dao.SearchGoodsByTitlePage(q, 0, limit)
+	return goods, err
+}
+
+// SearchGoodsByTitlePage 根据标题关键字分页模糊搜索商品，按商品ID排序
+// 返回当前页数据和匹配的总数，返回条数受MaxSearchLimit限制
+func (dao *GoodRepository) SearchGoodsByTitlePage(q string, offset, limit int) ([]model.Goods, int64, error) {
+	limit = NormalizeSearchLimit(limit)
+	pattern := "%" + EscapeLikePattern(q) + "%"
+
+	var total int64
+	err := dao.withReadRetry("count goods", func(db *gorm.DB) error {
+		return db.Model(&model.Goods{}).Where("title LIKE ? ESCAPE '!'", pattern).Count(&total).Error
+	})
+	if err != nil {
+		slog.Error("Failed to count goods by title",
+			"query", q,
+			"error", err,
+		)
+		return nil, 0, err
+	}
+
+	var goods []model.Goods
+	err = dao.withReadRetry("search goods", func(db *gorm.DB) error {
+		return db.Where("title LIKE ? ESCAPE '!'", pattern).
+			Order("goods_id").
+			Offset(offset).
+			Limit(limit).
+			Find(&goods).Error
+	})
+	if err != nil {
+		slog.Error("Failed to search goods by title",
+			"query", q,
+			"limit", limit,
+			"error", err,
+		)
+		return nil, 0, err
+	}
+
+	slog.Info("Goods searched by title",
+		"query", q,
+		"offset", offset,
+		"limit", limit,
+		"count", len(goods),
+		"total", total,
+	)
+	return goods, total, nil
+}
+
+// EscapeLikePattern 转义LIKE查询中的通配符
+// 使用'!'作为转义字符，兼容MySQL与SQLite
+func EscapeLikePattern(s string) string {
+	return likeEscaper.Replace(s)
+}
+
 // GetPromotionByGoodsId 根据商品ID获取秒杀促销信息
 func (dao *GoodRepository) GetPromotionByGoodsId(goodsId int64) (model.PromotionSecKill, error) {
 	var promotion model.PromotionSecKill
-	// 根据goods_id查询促销信息
-	err := dao.db.Where("goods_id = ?", goodsId).First(&promotion).Error
+	// 根据goods_id查询促销信息，表被锁时超时重试
+	err := dao.withReadRetry("find promotion", func(db *gorm.DB) error {
+		return db.Where("goods_id = ?", goodsId).First(&promotion).Error
+	})
 	if err != nil {
 		slog.Warn("Promotion not found in database",
 			"goods_id", goodsId,
 			"error", err,
 		)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 同时保留ErrRecordNotFound，兼容原有判断
+			err = fmt.Errorf("%w: goods %d: %w", ErrPromotionNotFound, goodsId, err)
+		}
 	} else {
 		slog.Info("Promotion found in database",
 			"goods_id", goodsId,
@@ -113,6 +316,108 @@ func (dao *GoodRepository) GetPromotionByGoodsId(goodsId int64) (model.Promotion
 	return promotion, err
 }
 
+// GetPromotionsByGoodsIds 一次查询多个商品的秒杀促销信息，以商品ID为键返回
+// 没有促销信息的商品不出现在返回结果中
+func (dao *GoodRepository) GetPromotionsByGoodsIds(goodsIds []int64) (map[int64]model.PromotionSecKill, error) {
+	result := make(map[int64]model.PromotionSecKill, len(goodsIds))
+	if len(goodsIds) == 0 {
+		return result, nil
+	}
+
+	var promotions []model.PromotionSecKill
+	err := dao.withReadRetry("find promotions", func(db *gorm.DB) error {
+		return db.Where("goods_id IN ?", goodsIds).Find(&promotions).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find promotions by goods ids failed: %w", err)
+	}
+	for _, promotion := range promotions {
+		result[promotion.GoodsId] = promotion
+	}
+
+	slog.Info("Promotions found in database",
+		"requested", len(goodsIds),
+		"found", len(result),
+	)
+	return result, nil
+}
+
+// GetPromotionSchedule 查询商品的全部秒杀促销（已结束、进行中和未开始），按开始时间排序
+// 商品没有促销时返回空列表
+func (dao *GoodRepository) GetPromotionSchedule(goodsId int64) ([]model.PromotionSecKill, error) {
+	var promotions []model.PromotionSecKill
+	err := dao.withReadRetry("find promotion schedule", func(db *gorm.DB) error {
+		return db.Where("goods_id = ?", goodsId).Order("start_time, ps_id").Find(&promotions).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find promotion schedule failed: %w", err)
+	}
+	return promotions, nil
+}
+
+// ListActivePromotions 分页查询在now时刻处于活动时间内的秒杀促销，按商品ID排序
+// 返回当前页数据和满足条件的总数
+func (dao *GoodRepository) ListActivePromotions(now time.Time, offset, limit int) ([]model.PromotionSecKill, int64, error) {
+	query := dao.db.Model(&model.PromotionSecKill{}).Where("start_time <= ? AND end_time >= ?", now, now)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count active promotions failed: %v", err)
+	}
+
+	var promotions []model.PromotionSecKill
+	if err := query.Order("goods_id").Offset(offset).Limit(limit).Find(&promotions).Error; err != nil {
+		return nil, 0, fmt.Errorf("list active promotions failed: %v", err)
+	}
+
+	slog.Info("Active promotions listed",
+		"offset", offset,
+		"limit", limit,
+		"count", len(promotions),
+		"total", total,
+	)
+	return promotions, total, nil
+}
+
+// ListPromotionsEndedBetween 查询结束时间位于(from, to]区间内的秒杀促销，按商品ID排序
+func (dao *GoodRepository) ListPromotionsEndedBetween(from, to time.Time) ([]model.PromotionSecKill, error) {
+	var promotions []model.PromotionSecKill
+	err := dao.withReadRetry("list ended promotions", func(db *gorm.DB) error {
+		return db.Where("end_time > ? AND end_time <= ?", from, to).Order("goods_id").Find(&promotions).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list ended promotions failed: %v", err)
+	}
+	return promotions, nil
+}
+
+// CountSoldByGoodsIds 统计各商品的已售数量（已取消的订单不计入）
+// 没有订单的商品不出现在返回结果中
+func (dao *GoodRepository) CountSoldByGoodsIds(goodsIds []int64) (map[int64]int64, error) {
+	sold := make(map[int64]int64, len(goodsIds))
+	if len(goodsIds) == 0 {
+		return sold, nil
+	}
+
+	var rows []struct {
+		GoodsId int64
+		Sold    int64
+	}
+	err := dao.db.Model(&model.SuccessKilled{}).
+		Select("goods_id, SUM(quantity) AS sold").
+		Where("goods_id IN ? AND state <> ?", goodsIds, model.OrderStateCancelled).
+		Group("goods_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("count sold by goods ids failed: %v", err)
+	}
+
+	for _, row := range rows {
+		sold[row.GoodsId] = row.Sold
+	}
+	return sold, nil
+}
+
 // OccReduceOnePromotionByGoodsId 使用乐观锁减少促销库存数量
 // 通过版本号控制并发安全，防止超卖
 func (dao *GoodRepository) OccReduceOnePromotionByGoodsId(goodsId int64, version int64) (int64, error) {
@@ -141,6 +446,246 @@ func (dao *GoodRepository) OccReduceOnePromotionByGoodsId(goodsId int64, version
 	return result.RowsAffected, result.Error
 }
 
+// OccReducePromotionByGoodsId 在事务中使用乐观锁按数量减少促销库存
+// 仅当版本号匹配且剩余库存不少于qty时扣减，扣减随事务提交或回滚
+func (dao *GoodRepository) OccReducePromotionByGoodsId(tx *gorm.DB, goodsId, version, qty int64) (int64, error) {
+	if qty <= 0 {
+		return 0, fmt.Errorf("invalid stock quantity: %d", qty)
+	}
+
+	// 更新促销库存：库存减qty，版本号加1
+	result := tx.Model(&model.PromotionSecKill{}).
+		Where("goods_id = ? AND version = ? AND ps_count >= ?", goodsId, version, qty). // 版本号匹配且库存充足
+		Updates(map[string]any{
+			"ps_count": gorm.Expr("ps_count - ?", qty), // 库存减qty
+			"version":  gorm.Expr("version + 1"),       // 版本号加1
+		})
+
+	if result.Error != nil {
+		slog.Error("Failed to reduce promotion count",
+			"goods_id", goodsId,
+			"version", version,
+			"quantity", qty,
+			"error", result.Error,
+		)
+	} else {
+		slog.Info("Promotion count reduced",
+			"goods_id", goodsId,
+			"version", version,
+			"quantity", qty,
+			"rows_affected", result.RowsAffected,
+		)
+	}
+	// 返回受影响的行数和错误信息
+	return result.RowsAffected, result.Error
+}
+
+// ReducePromotionCount 在事务中按数量扣减促销库存（不校验版本号）
+// 用于Redis-only模式异步写库，库存以Redis为准；剩余库存不足时不扣减，返回受影响行数0
+func (dao *GoodRepository) ReducePromotionCount(tx *gorm.DB, goodsId, qty int64) (int64, error) {
+	if qty <= 0 {
+		return 0, fmt.Errorf("invalid stock quantity: %d", qty)
+	}
+
+	result := tx.Model(&model.PromotionSecKill{}).
+		Where("goods_id = ? AND ps_count >= ?", goodsId, qty).
+		Updates(map[string]any{
+			"ps_count": gorm.Expr("ps_count - ?", qty), // 库存减qty
+			"version":  gorm.Expr("version + 1"),       // 版本号加1，使进行中的乐观锁扣减失效
+		})
+	if result.Error != nil {
+		slog.Error("Failed to reduce promotion count",
+			"goods_id", goodsId,
+			"quantity", qty,
+			"error", result.Error,
+		)
+	}
+	return result.RowsAffected, result.Error
+}
+
+// RestorePromotionCountByGoodsId 在事务中按数量归还促销库存，返回受影响的行数
+// 调用方负责保证同一订单只归还一次，例如先将订单状态迁移为已取消
+func (dao *GoodRepository) RestorePromotionCountByGoodsId(tx *gorm.DB, goodsId, qty int64) (int64, error) {
+	if qty <= 0 {
+		return 0, fmt.Errorf("invalid stock quantity: %d", qty)
+	}
+
+	result := tx.Model(&model.PromotionSecKill{}).
+		Where("goods_id = ?", goodsId).
+		Updates(map[string]any{
+			"ps_count": gorm.Expr("ps_count + ?", qty), // 归还库存
+			"version":  gorm.Expr("version + 1"),       // 版本号加1
+		})
+	if result.Error != nil {
+		slog.Error("Failed to restore promotion count",
+			"goods_id", goodsId,
+			"quantity", qty,
+			"error", result.Error,
+		)
+	}
+	return result.RowsAffected, result.Error
+}
+
+// HasUserOrder 查询用户是否已有指定商品的秒杀订单
+// 使用(goods_id, user_id)联合索引做存在性查询，只读取一行
+func (dao *GoodRepository) HasUserOrder(userId, goodsId int64) (bool, error) {
+	var found []int
+	err := dao.db.Model(&model.SuccessKilled{}).
+		Select("1").
+		Where("goods_id = ? AND user_id = ?", goodsId, userId).
+		Limit(1).
+		Find(&found).Error
+	if err != nil {
+		slog.Error("Failed to check user order",
+			"user_id", userId,
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return false, err
+	}
+	return len(found) > 0, nil
+}
+
+// CountUserPurchases 在事务中统计用户已购买指定商品的件数，已取消的订单不计入
+// 同一用户的下单由用户锁串行化
+func (dao *GoodRepository) CountUserPurchases(tx *gorm.DB, userId, goodsId int64) (int64, error) {
+	var count int64
+	err := tx.Model(&model.SuccessKilled{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("goods_id = ? AND user_id = ? AND state <> ?", goodsId, userId, model.OrderStateCancelled).
+		Scan(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("count user purchases failed: %w", err)
+	}
+	return count, nil
+}
+
+// ListUnpaidOrdersByUserId 查询用户所有未支付的秒杀订单，按商品ID排序
+func (dao *GoodRepository) ListUnpaidOrdersByUserId(userId int64) ([]model.SuccessKilled, error) {
+	var orders []model.SuccessKilled
+	err := dao.db.Where("user_id = ? AND state = ?", userId, model.OrderStateUnpaid).
+		Order("goods_id, id").
+		Find(&orders).Error
+	if err != nil {
+		slog.Error("Failed to list unpaid orders",
+			"user_id", userId,
+			"error", err,
+		)
+		return nil, err
+	}
+	return orders, nil
+}
+
+// FindSuccessKilledByGoodsAndUser 根据商品ID和用户ID查询秒杀成功记录，不存在时返回ErrOrderNotFound
+func (dao *GoodRepository) FindSuccessKilledByGoodsAndUser(goodsId, userId int64) (model.SuccessKilled, error) {
+	var order model.SuccessKilled
+	err := dao.db.Where("goods_id = ? AND user_id = ?", goodsId, userId).First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return order, fmt.Errorf("%w: user %d goods %d", errs.ErrOrderNotFound, userId, goodsId)
+	}
+	if err != nil {
+		slog.Error("Failed to find success killed record",
+			"user_id", userId,
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return order, err
+	}
+	return order, nil
+}
+
+// FindSuccessKilledByOrderId 根据订单ID查询秒杀成功记录，不存在时返回ErrOrderNotFound
+// 未记录订单ID的历史记录按订单ID中解析出的用户ID和商品ID匹配
+func (dao *GoodRepository) FindSuccessKilledByOrderId(orderId string, userId, goodsId int64) (model.SuccessKilled, error) {
+	order, err := findOrder(dao.db, orderId, userId, goodsId)
+	if err != nil && !errors.Is(err, errs.ErrOrderNotFound) {
+		slog.Error("Failed to find success killed record",
+			"order_id", orderId,
+			"error", err,
+		)
+	}
+	return order, err
+}
+
+// findOrder 按订单ID查询秒杀成功记录，找不到时回退到同一用户和商品下未记录订单ID或回填了历史订单ID的记录
+// 迁移前签发的订单ID与回填的历史订单ID不同，旧表每个用户每件商品至多一条记录，按用户和商品匹配不会混淆
+func findOrder(db *gorm.DB, orderId string, userId, goodsId int64) (model.SuccessKilled, error) {
+	var order model.SuccessKilled
+	if orderId != "" {
+		result := db.Where("order_id = ?", orderId).Limit(1).Find(&order)
+		if result.Error != nil {
+			return order, fmt.Errorf("query order failed: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			return order, nil
+		}
+	}
+
+	result := db.Where("goods_id = ? AND user_id = ? AND (order_id IS NULL OR order_id = '' OR order_id = ?)",
+		goodsId, userId, model.LegacyOrderId(userId, goodsId)).
+		Order("id").Limit(1).Find(&order)
+	if result.Error != nil {
+		return order, fmt.Errorf("query order failed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return order, fmt.Errorf("%w: order %s user %d goods %d", errs.ErrOrderNotFound, orderId, userId, goodsId)
+	}
+	return order, nil
+}
+
+// TransitionOrderState 在事务中将订单迁移到目标状态，返回本次是否改变了订单状态
+// 订单已处于目标状态时不做任何操作；迁移不合法时返回ErrInvalidOrderTransition，订单不存在时返回ErrOrderNotFound
+func (dao *GoodRepository) TransitionOrderState(tx *gorm.DB, orderId string, userId, goodsId int64, to int16) (bool, error) {
+	_, ok, err := transitionOrderState(tx, orderId, userId, goodsId, to)
+	return ok, err
+}
+
+// transitionOrderState 在事务中将订单迁移到目标状态，返回迁移前的订单记录和本次是否改变了订单状态
+func transitionOrderState(tx *gorm.DB, orderId string, userId, goodsId int64, to int16) (model.SuccessKilled, bool, error) {
+	order, err := findOrder(tx, orderId, userId, goodsId)
+	if err != nil {
+		return order, false, err
+	}
+	if order.State == to {
+		return order, false, nil // 重复的状态迁移
+	}
+	if !model.CanTransitionOrderState(order.State, to) {
+		return order, false, fmt.Errorf("%w: %s -> %s", errs.ErrInvalidOrderTransition,
+			model.OrderStateName(order.State), model.OrderStateName(to))
+	}
+
+	// 以当前状态为条件更新，并发的迁移只有一个生效
+	result := tx.Model(&model.SuccessKilled{}).
+		Where("id = ? AND state = ?", order.Id, order.State).
+		Update("state", to)
+	if result.Error != nil {
+		return order, false, fmt.Errorf("update order state failed: %w", result.Error)
+	}
+	return order, result.RowsAffected > 0, nil
+}
+
+// CancelUnpaidOrder 在事务中取消未支付的订单并按订单件数归还促销库存
+// 返回本次归还的件数，订单已被取消时返回0，重复调用不会重复归还库存；已支付的订单返回ErrInvalidOrderTransition
+func (dao *GoodRepository) CancelUnpaidOrder(tx *gorm.DB, orderId string, userId, goodsId int64) (int64, error) {
+	order, ok, err := transitionOrderState(tx, orderId, userId, goodsId, model.OrderStateCancelled)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	quantity := max(order.Quantity, 1)
+	if _, err := dao.RestorePromotionCountByGoodsId(tx, goodsId, quantity); err != nil {
+		return 0, fmt.Errorf("restore promotion count failed: %w", err)
+	}
+
+	slog.Info("Unpaid order cancelled",
+		"order_id", orderId,
+		"user_id", userId,
+		"goods_id", goodsId,
+		"quantity", quantity,
+	)
+	return quantity, nil
+}
+
 // AddSuccessKilled 添加秒杀成功记录
 // 在事务中创建秒杀成功订单
 func (dao *GoodRepository) AddSuccessKilled(tx *gorm.DB, order *model.SuccessKilled) error {
@@ -161,6 +706,34 @@ func (dao *GoodRepository) AddSuccessKilled(tx *gorm.DB, order *model.SuccessKil
 	return err
 }
 
+// AddAuditLog 写入秒杀下单审计记录
+func (dao *GoodRepository) AddAuditLog(log *model.SeckillAuditLog) error {
+	if err := dao.db.Create(log).Error; err != nil {
+		return fmt.Errorf("add seckill audit log failed: %v", err)
+	}
+	return nil
+}
+
+// ListAuditLogsByGoodsId 分页查询商品的秒杀下单审计记录，最新的在前
+// 返回当前页数据和该商品的记录总数
+func (dao *GoodRepository) ListAuditLogsByGoodsId(goodsId int64, offset, limit int) ([]model.SeckillAuditLog, int64, error) {
+	var (
+		logs  []model.SeckillAuditLog
+		total int64
+	)
+	err := dao.withReadRetry("list seckill audit logs", func(db *gorm.DB) error {
+		query := db.Model(&model.SeckillAuditLog{}).Where("goods_id = ?", goodsId)
+		if err := query.Count(&total).Error; err != nil {
+			return err
+		}
+		return query.Order("id DESC").Offset(offset).Limit(limit).Find(&logs).Error
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("list seckill audit logs failed: %v", err)
+	}
+	return logs, total, nil
+}
+
 // ClearOrderByGoodsId 清除指定商品的所有订单记录
 func (dao *GoodRepository) ClearOrderByGoodsId(tx *gorm.DB, goodsId int64) error {
 	result := tx.Where("goods_id = ?", goodsId).Delete(&model.SuccessKilled{})
@@ -180,6 +753,9 @@ func (dao *GoodRepository) ClearOrderByGoodsId(tx *gorm.DB, goodsId int64) error
 
 // ResetPromotionCountByGoodsId 重置指定商品的促销库存数量
 func (dao *GoodRepository) ResetPromotionCountByGoodsId(tx *gorm.DB, goodsId int64, count int64) error {
+	if count < 0 {
+		return fmt.Errorf("%w: goods %d reset to %d", errs.ErrInvalidPromotionCount, goodsId, count)
+	}
 	result := tx.Model(&model.PromotionSecKill{}).
 		Where("goods_id = ?", goodsId).
 		Updates(map[string]any{
@@ -204,10 +780,32 @@ func (dao *GoodRepository) ResetPromotionCountByGoodsId(tx *gorm.DB, goodsId int
 }
 
 // WithTransaction 执行数据库事务
-// 传入的事务函数会在事务中执行
+// 传入的事务函数会在事务中执行，遇到死锁或序列化失败时以指数退避重新执行整个事务，最多执行MaxTransactionAttempts次
+// 事务函数可能被执行多次，不应包含数据库以外的副作用
 func (dao *GoodRepository) WithTransaction(fn func(tx *gorm.DB) error) error {
 	slog.Info("Starting database transaction")
-	err := dao.db.Transaction(fn)
+	var err error
+	for attempt := 1; attempt <= MaxTransactionAttempts; attempt++ {
+		err = dao.db.Transaction(fn)
+		if err == nil || !IsRetryableTxError(err) {
+			break
+		}
+		if attempt == MaxTransactionAttempts {
+			slog.Error("Database transaction deadlock retries exhausted",
+				"attempts", attempt,
+				"error", err,
+			)
+			err = fmt.Errorf("transaction failed after %d attempts: %w", attempt, err)
+			break
+		}
+		delay := transactionRetryDelay(attempt)
+		slog.Warn("Database transaction deadlock, retrying",
+			"attempt", attempt,
+			"retry_in", delay,
+			"error", err,
+		)
+		time.Sleep(delay)
+	}
 	if err != nil {
 		slog.Error("Database transaction failed", "error", err)
 	} else {
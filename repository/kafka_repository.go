@@ -3,31 +3,197 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"seckill_system/global"
 	"seckill_system/model"
+	"strconv"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 )
 
+// ErrHandlerTimeout 消息处理超过最长处理时间
+var ErrHandlerTimeout = errors.New("kafka message handler timed out")
+
+// MessageReader Kafka消息读取接口，由*kafka.Reader实现
+type MessageReader interface {
+	ReadMessage(ctx context.Context) (kafka.Message, error)
+}
+
 // KafkaRepository 封装与Kafka交互的仓库操作
 type KafkaRepository struct {
-	writer *kafka.Writer // Kafka生产者客户端
-	reader *kafka.Reader // Kafka消费者客户端
+	writer          *kafka.Writer // Kafka生产者客户端
+	reader          *kafka.Reader // Kafka消费者客户端
+	auditWriter     *kafka.Writer // Kafka审计事件生产者，未开启审计时为nil
+	dlqWriter       *kafka.Writer // Kafka死信消息生产者，未配置死信主题时为nil
+	lifecycleWriter *kafka.Writer // Kafka活动生命周期事件生产者，未配置生命周期主题时为nil
+	handlerTimeout  time.Duration // 单条订单消息的最长处理时间，0表示不限制
+	handlerAttempts int           // 处理失败的订单消息最多尝试次数
+	requestWriter   *kafka.Writer // Kafka异步秒杀请求生产者，未配置异步秒杀主题时为nil
+	requestReader   *kafka.Reader // Kafka异步秒杀请求消费者，未配置异步秒杀主题时为nil
 }
 
 // NewKafkaRepository 创建Kafka仓库实例
+// Kafka生产者或消费者未初始化时panic，审计生产者为可选项
 func NewKafkaRepository() *KafkaRepository {
+	global.MustClient("Kafka", "InitKafka", global.KafkaWriter != nil && global.KafkaReader != nil)
 	return &KafkaRepository{
-		writer: global.KafkaWriter, // 使用全局Kafka生产者
-		reader: global.KafkaReader, // 使用全局Kafka消费者
+		writer:          global.KafkaWriter,               // 使用全局Kafka生产者
+		reader:          global.KafkaReader,               // 使用全局Kafka消费者
+		auditWriter:     global.KafkaAuditWriter,          // 使用全局审计事件生产者
+		dlqWriter:       global.KafkaDLQWriter,            // 使用全局死信消息生产者
+		lifecycleWriter: global.KafkaLifecycleWriter,      // 使用全局活动生命周期事件生产者
+		handlerTimeout:  global.KafkaHandlerTimeout,       // 单条订单消息的最长处理时间
+		handlerAttempts: global.KafkaHandlerAttempts,      // 处理失败的订单消息最多尝试次数
+		requestWriter:   global.KafkaSeckillRequestWriter, // 使用全局异步秒杀请求生产者
+		requestReader:   global.KafkaSeckillRequestReader, // 使用全局异步秒杀请求消费者
+	}
+}
+
+// AuditEnabled 是否已开启审计事件发送
+func (k *KafkaRepository) AuditEnabled() bool {
+	return k.auditWriter != nil
+}
+
+// SendAuditEvent 发送秒杀审计事件到审计主题
+func (k *KafkaRepository) SendAuditEvent(ctx context.Context, event *model.AuditEvent) error {
+	if k.auditWriter == nil {
+		return nil // 未开启审计
+	}
+
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal audit event failed: %v", err)
+	}
+
+	// 使用用户ID作为key，同一用户的事件路由到同一分区
+	msg := kafka.Message{
+		Key:   []byte(strconv.FormatInt(event.UserId, 10)),
+		Value: jsonData,
+		Headers: []kafka.Header{
+			{
+				Key:   "message_type",
+				Value: []byte("audit"), // 标识消息类型为审计事件
+			},
+		},
+	}
+
+	if err := k.auditWriter.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("send audit event failed: %v", err)
+	}
+	return nil
+}
+
+// SendLifecycleEvent 发送活动生命周期事件到生命周期主题，未配置生命周期主题时不发送
+func (k *KafkaRepository) SendLifecycleEvent(ctx context.Context, event *model.LifecycleEvent) error {
+	if k.lifecycleWriter == nil {
+		return nil
+	}
+
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal lifecycle event failed: %v", err)
+	}
+
+	// 使用商品ID作为key，同一商品的事件路由到同一分区并保持顺序
+	msg := kafka.Message{
+		Key:   []byte(strconv.FormatInt(event.GoodsId, 10)),
+		Value: jsonData,
+		Headers: []kafka.Header{
+			{
+				Key:   "message_type",
+				Value: []byte("lifecycle"), // 标识消息类型为活动生命周期事件
+			},
+		},
+	}
+
+	if err := k.lifecycleWriter.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("send lifecycle event failed: %v", err)
+	}
+	return nil
+}
+
+// SeckillRequestsEnabled 是否已配置异步秒杀主题
+func (k *KafkaRepository) SeckillRequestsEnabled() bool {
+	return k.requestWriter != nil && k.requestReader != nil
+}
+
+// SendSeckillRequest 发送异步秒杀请求到异步秒杀主题，写入成功后返回
+func (k *KafkaRepository) SendSeckillRequest(ctx context.Context, request *model.AsyncSeckillRequest) error {
+	if k.requestWriter == nil {
+		return errors.New("kafka seckill request writer not initialized")
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("marshal seckill request failed: %v", err)
+	}
+
+	// 使用用户ID作为key，同一用户的请求路由到同一分区并按顺序处理
+	msg := kafka.Message{
+		Key:   []byte(strconv.FormatInt(request.UserId, 10)),
+		Value: jsonData,
+		Headers: []kafka.Header{
+			{
+				Key:   "message_type",
+				Value: []byte("seckill_request"), // 标识消息类型为异步秒杀请求
+			},
+		},
+	}
+
+	if err := k.requestWriter.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("send seckill request failed: %v", err)
+	}
+	return nil
+}
+
+// ConsumeSeckillRequests 消费异步秒杀请求，未配置异步秒杀主题时返回错误
+func (k *KafkaRepository) ConsumeSeckillRequests(ctx context.Context, handler func(request model.AsyncSeckillRequest) error) error {
+	if k.requestReader == nil {
+		return errors.New("kafka seckill request reader not initialized")
+	}
+	return ConsumeSeckillRequestsFrom(ctx, k.requestReader, handler)
+}
+
+// ConsumeSeckillRequestsFrom 从reader持续消费异步秒杀请求，直到读取失败或ctx取消
+// 请求的处理结果由handler写入Redis，无法解析或处理失败的消息只记录日志后继续消费下一条
+func ConsumeSeckillRequestsFrom(ctx context.Context, reader MessageReader, handler func(request model.AsyncSeckillRequest) error) error {
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("read seckill request failed: %w", err)
+		}
+
+		var request model.AsyncSeckillRequest
+		if err := json.Unmarshal(msg.Value, &request); err != nil {
+			slog.Warn("Failed to unmarshal seckill request",
+				"error", err,
+				"offset", msg.Offset,
+				"partition", msg.Partition,
+			)
+			continue
+		}
+
+		if err := handler(request); err != nil {
+			slog.Error("Handle seckill request failed",
+				"request_id", request.RequestId,
+				"user_id", request.UserId,
+				"goods_id", request.GoodsId,
+				"offset", msg.Offset,
+				"partition", msg.Partition,
+				"error", err,
+			)
+		}
 	}
 }
 
 // SendOrderMessage 发送订单消息到Kafka
 func (k *KafkaRepository) SendOrderMessage(ctx context.Context, order *model.OrderMessage) error {
+	if k.writer == nil {
+		return errors.New("kafka order writer not initialized")
+	}
 	// 将订单消息序列化为JSON
 	jsonData, err := json.Marshal(order)
 	if err != nil {
@@ -109,14 +275,29 @@ func (k *KafkaRepository) SendPaymentMessage(ctx context.Context, orderId string
 	return nil
 }
 
-// ConsumeOrderMessages 消费订单消息
+// ConsumeOrderMessages 消费订单消息，每条消息的处理受最长处理时间限制，失败的消息按配置次数重试
+// 无法解析、重试耗尽或处理超时的消息转发死信主题
 func (k *KafkaRepository) ConsumeOrderMessages(ctx context.Context, handler func(message model.OrderMessage) error) error {
+	return ConsumeOrderMessagesFrom(ctx, k.reader, k.handlerTimeout, k.handlerAttempts, handler, k.SendDeadLetter)
+}
+
+// OrderHandlerRetryBackoff 订单消息处理失败后重试的基础等待时间，第n次重试等待n倍
+var OrderHandlerRetryBackoff = 100 * time.Millisecond
+
+// ConsumeOrderMessagesFrom 从reader持续消费订单消息，直到读取失败或ctx取消
+// 每条消息的处理受timeout限制（0表示不限制），处理返回错误时最多尝试attempts次（小于1时按1次）。
+// 无法解析、重试耗尽和处理超时的消息交给deadLetter（如转发死信主题）后继续消费下一条，
+// 超时的处理函数仍在后台运行直到返回，因此不再重试。消费者组自动提交偏移量，
+// 交给deadLetter的消息不会被重新投递，只能从死信主题重放
+func ConsumeOrderMessagesFrom(ctx context.Context, reader MessageReader, timeout time.Duration, attempts int,
+	handler func(message model.OrderMessage) error, deadLetter func(ctx context.Context, msg kafka.Message, reason string) error) error {
+	attempts = max(attempts, 1)
 	// 持续消费消息
 	for {
 		// 读取消息
-		msg, err := k.reader.ReadMessage(ctx)
+		msg, err := reader.ReadMessage(ctx)
 		if err != nil {
-			return fmt.Errorf("read kafka message failed: %v", err)
+			return fmt.Errorf("read kafka message failed: %w", err)
 		}
 
 		// 反序列化订单消息
@@ -128,7 +309,8 @@ func (k *KafkaRepository) ConsumeOrderMessages(ctx context.Context, handler func
 				"offset", msg.Offset,
 				"partition", msg.Partition,
 			)
-			continue // 跳过无法解析的消息
+			routeDeadLetter(ctx, deadLetter, msg, fmt.Sprintf("unmarshal failed: %v", err))
+			continue
 		}
 
 		// 记录收到的消息
@@ -140,17 +322,118 @@ func (k *KafkaRepository) ConsumeOrderMessages(ctx context.Context, handler func
 			"partition", msg.Partition,
 		)
 
-		// 调用处理函数处理消息
-		if err := handler(order); err != nil {
-			slog.Error("Handle order message failed",
+		// 调用处理函数处理消息，失败时退避重试，超时后不再等待
+		for attempt := 1; ; attempt++ {
+			err = handleWithTimeout(ctx, timeout, func() error { return handler(order) })
+			if ctx.Err() != nil {
+				return fmt.Errorf("order consumer stopped: %w", ctx.Err())
+			}
+			if err == nil || errors.Is(err, ErrHandlerTimeout) || attempt >= attempts {
+				break
+			}
+			slog.Warn("Handle order message failed, retrying",
+				"order_id", order.OrderId,
+				"attempt", attempt,
+				"max_attempts", attempts,
+				"error", err,
+			)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("order consumer stopped: %w", ctx.Err())
+			case <-time.After(time.Duration(attempt) * OrderHandlerRetryBackoff):
+			}
+		}
+
+		switch {
+		case errors.Is(err, ErrHandlerTimeout):
+			slog.Error("Order message handler timed out, skipping message",
+				"order_id", order.OrderId,
+				"timeout", timeout,
+				"offset", msg.Offset,
+				"partition", msg.Partition,
+			)
+			routeDeadLetter(ctx, deadLetter, msg, err.Error())
+		case err != nil:
+			slog.Error("Handle order message failed, giving up",
 				"order_id", order.OrderId,
+				"attempts", attempts,
+				"offset", msg.Offset,
+				"partition", msg.Partition,
 				"error", err,
 			)
-			// 不返回错误，继续处理下一条消息
+			routeDeadLetter(ctx, deadLetter, msg, fmt.Sprintf("handler failed after %d attempts: %v", attempts, err))
 		}
 	}
 }
 
+// routeDeadLetter 将无法处理的消息交给deadLetter，转发失败只记录日志，不中断消费
+func routeDeadLetter(ctx context.Context, deadLetter func(ctx context.Context, msg kafka.Message, reason string) error, msg kafka.Message, reason string) {
+	if deadLetter == nil {
+		return
+	}
+	if err := deadLetter(ctx, msg, reason); err != nil {
+		slog.Error("Failed to route order message to dead letter topic",
+			"offset", msg.Offset,
+			"partition", msg.Partition,
+			"reason", reason,
+			"error", err,
+		)
+	}
+}
+
+// handleWithTimeout 在timeout内等待fn返回，超时返回ErrHandlerTimeout，ctx取消时返回ctx的错误
+// timeout为0时同步执行fn
+func handleWithTimeout(ctx context.Context, timeout time.Duration, fn func() error) error {
+	if timeout <= 0 {
+		return fn()
+	}
+	done := make(chan error, 1) // 带缓冲，超时后处理函数返回时不会阻塞
+	go func() {
+		done <- fn()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return ErrHandlerTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SendDeadLetter 将无法处理的消息原样转发到死信主题，消息头记录来源位置、原因和失败时间，便于追踪和重放
+// 未配置死信主题时只记录日志
+func (k *KafkaRepository) SendDeadLetter(ctx context.Context, msg kafka.Message, reason string) error {
+	if k.dlqWriter == nil {
+		slog.Warn("Dead letter topic not configured, message dropped",
+			"topic", msg.Topic,
+			"partition", msg.Partition,
+			"offset", msg.Offset,
+			"reason", reason,
+		)
+		return nil
+	}
+
+	// 复制原消息头，避免修改调用方的消息
+	headers := make([]kafka.Header, 0, len(msg.Headers)+5)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: "dlq_reason", Value: []byte(reason)},
+		kafka.Header{Key: "dlq_source_topic", Value: []byte(msg.Topic)},
+		kafka.Header{Key: "dlq_source_partition", Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: "dlq_source_offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: "dlq_failed_at", Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+	dead := kafka.Message{Key: msg.Key, Value: msg.Value, Headers: headers}
+	if err := k.dlqWriter.WriteMessages(ctx, dead); err != nil {
+		return fmt.Errorf("send dead letter message failed: %v", err)
+	}
+	return nil
+}
+
 // ConsumePaymentMessages 消费支付消息（使用独立的消费者组）
 func (k *KafkaRepository) ConsumePaymentMessages(ctx context.Context, handler func(orderId string, status int32) error) error {
 	// 获取全局配置并创建专门的支付消息消费者
@@ -169,7 +452,7 @@ func (k *KafkaRepository) ConsumePaymentMessages(ctx context.Context, handler fu
 		// 读取消息
 		msg, err := paymentReader.ReadMessage(ctx)
 		if err != nil {
-			return fmt.Errorf("read payment message failed: %v", err)
+			return fmt.Errorf("read payment message failed: %w", err)
 		}
 
 		// 检查消息类型，只处理支付消息
@@ -233,6 +516,24 @@ func (k *KafkaRepository) Close() error {
 	if err := k.reader.Close(); err != nil {
 		return fmt.Errorf("close kafka reader failed: %v", err)
 	}
+	// 关闭审计事件生产者
+	if k.auditWriter != nil {
+		if err := k.auditWriter.Close(); err != nil {
+			return fmt.Errorf("close kafka audit writer failed: %v", err)
+		}
+	}
+	// 关闭死信消息生产者
+	if k.dlqWriter != nil {
+		if err := k.dlqWriter.Close(); err != nil {
+			return fmt.Errorf("close kafka dead letter writer failed: %v", err)
+		}
+	}
+	// 关闭活动生命周期事件生产者
+	if k.lifecycleWriter != nil {
+		if err := k.lifecycleWriter.Close(); err != nil {
+			return fmt.Errorf("close kafka lifecycle writer failed: %v", err)
+		}
+	}
 	slog.Info("Kafka repository closed")
 	return nil
 }
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"log/slog"
+	"seckill_system/model"
+	"time"
+)
+
+// PromotionCacheTTL 促销信息缓存有效期
+// 版本号变更时会主动失效缓存，有效期只用于限制异常情况下（如失效请求失败）的不一致时间
+const PromotionCacheTTL = 30 * time.Second
+
+// PromotionCache 带Redis缓存的促销信息读取器
+// 缓存值中包含版本号，乐观锁扣减成功或管理员重置后通过Invalidate失效；
+// 依赖版本号做乐观锁的调用方在扣减失败时应调用Invalidate并使用GetFresh重新读取
+type PromotionCache struct {
+	db    *GoodRepository  // 数据库读取
+	redis *RedisRepository // 缓存读写
+}
+
+// NewPromotionCache 创建促销信息缓存
+func NewPromotionCache(db *GoodRepository, redis *RedisRepository) *PromotionCache {
+	return &PromotionCache{db: db, redis: redis}
+}
+
+// Get 获取促销信息，缓存命中时不访问数据库
+// fromCache表示返回值来自缓存，其版本号可能已落后于数据库
+func (pc *PromotionCache) Get(goodsId int64) (promotion model.PromotionSecKill, fromCache bool, err error) {
+	cached, found, err := pc.redis.GetPromotionCache(goodsId)
+	if err != nil {
+		// 缓存不可用时回源数据库
+		slog.Warn("Failed to read promotion cache, falling back to database",
+			"goods_id", goodsId,
+			"error", err,
+		)
+	} else if found {
+		return cached, true, nil
+	}
+
+	promotion, err = pc.GetFresh(goodsId)
+	return promotion, false, err
+}
+
+// GetFresh 从数据库读取促销信息并写入缓存
+func (pc *PromotionCache) GetFresh(goodsId int64) (model.PromotionSecKill, error) {
+	promotion, err := pc.db.GetPromotionByGoodsId(goodsId)
+	if err != nil {
+		return promotion, err
+	}
+	if err := pc.redis.SetPromotionCache(promotion, PromotionCacheTTL); err != nil {
+		slog.Warn("Failed to cache promotion",
+			"goods_id", goodsId,
+			"error", err,
+		)
+	}
+	return promotion, nil
+}
+
+// Invalidate 使商品的促销缓存失效，在促销版本号变更后调用
+func (pc *PromotionCache) Invalidate(goodsId int64) {
+	if err := pc.redis.DeletePromotionCache(goodsId); err != nil {
+		slog.Warn("Failed to invalidate promotion cache",
+			"goods_id", goodsId,
+			"error", err,
+		)
+	}
+}
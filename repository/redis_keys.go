@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Redis键构造规则
+// Redis集群中单次EVAL访问的所有键必须位于同一个槽位，否则返回CROSSSLOT错误。
+// 与商品相关、可能在同一脚本中访问的键都使用哈希标签{goodsId}，
+// 集群只对花括号内的内容计算槽位，从而保证这些键落在同一槽位。
+// 新增多键脚本时，所有键都应通过本文件的函数构造，并在执行前调用ValidateScriptKeys校验。
+
+// redisClusterSlots Redis集群槽位总数
+const redisClusterSlots = 16384
+
+// DefaultMaxScriptKeys 单个Lua脚本默认允许的最大键数量
+const DefaultMaxScriptKeys = 4
+
+// Lua脚本键校验错误
+var (
+	ErrTooManyScriptKeys = errors.New("too many keys for lua script")       // 键数量超过上限
+	ErrCrossSlotKeys     = errors.New("lua script keys in different slots") // 键不在同一槽位
+)
+
+// goodsHashTag 返回商品相关键使用的哈希标签
+func goodsHashTag(goodsId int64) string {
+	return fmt.Sprintf("{%d}", goodsId)
+}
+
+// StockKey 返回商品库存键
+func StockKey(goodsId int64) string {
+	return "goods_stock:" + goodsHashTag(goodsId)
+}
+
+// StockGenerationKey 返回商品库存代次键，与库存键位于同一槽位
+func StockGenerationKey(goodsId int64) string {
+	return "goods_stock_gen:" + goodsHashTag(goodsId)
+}
+
+// DistributedLockKey 返回分布式锁键
+func DistributedLockKey(key string) string {
+	return "distributed_lock:" + key
+}
+
+// SeckillTokenKey 返回秒杀令牌键，与库存键位于同一槽位
+func SeckillTokenKey(goodsId int64, tokenId string) string {
+	return fmt.Sprintf("seckill_token:%s:%s", goodsHashTag(goodsId), tokenId)
+}
+
+// GoodsInfoKey 返回商品信息缓存键
+func GoodsInfoKey(goodsId int64) string {
+	return "goods_info:" + goodsHashTag(goodsId)
+}
+
+// PromotionCacheKey 返回促销信息缓存键
+func PromotionCacheKey(goodsId int64) string {
+	return "promotion_cache:" + goodsHashTag(goodsId)
+}
+
+// SeckillResultKey 返回用户秒杀结果缓存键
+func SeckillResultKey(userId, goodsId int64) string {
+	return fmt.Sprintf("seckill_result:%s:%d", goodsHashTag(goodsId), userId)
+}
+
+// SeckillTokenQuotaKey 返回用户在商品秒杀活动中已签发令牌次数的计数键
+func SeckillTokenQuotaKey(userId, goodsId int64) string {
+	return fmt.Sprintf("seckill_token_quota:%s:%d", goodsHashTag(goodsId), userId)
+}
+
+// SeckillTokenIssuedKey 返回商品已签发秒杀令牌数的计数键
+func SeckillTokenIssuedKey(goodsId int64) string {
+	return "seckill_token_issued:" + goodsHashTag(goodsId)
+}
+
+// SeckillTokenReservationsKey 返回商品预占库存令牌的有序集合键，成员为令牌ID，分值为令牌过期时间（毫秒），与库存键位于同一槽位
+func SeckillTokenReservationsKey(goodsId int64) string {
+	return "seckill_token_reservations:" + goodsHashTag(goodsId)
+}
+
+// SeckillTokenRedeemedKey 返回商品已兑换秒杀令牌数的计数键，与签发计数键位于同一槽位
+func SeckillTokenRedeemedKey(goodsId int64) string {
+	return "seckill_token_redeemed:" + goodsHashTag(goodsId)
+}
+
+// SeckillIdempotencyKey 返回用户秒杀请求幂等记录键
+func SeckillIdempotencyKey(userId int64, key string) string {
+	return fmt.Sprintf("seckill_idem:%d:%s", userId, key)
+}
+
+// AsyncSeckillResultKey 返回异步秒杀请求处理结果键
+func AsyncSeckillResultKey(requestId string) string {
+	return "seckill_async_result:" + requestId
+}
+
+// UserRateLimitKey 返回用户限流计数键
+func UserRateLimitKey(userId int64) string {
+	return fmt.Sprintf("user_rate_limit:%d", userId)
+}
+
+// IPRateLimitKey 返回客户端IP限流计数键
+func IPRateLimitKey(ip string) string {
+	return fmt.Sprintf("ip_rate_limit:%s", ip)
+}
+
+// UserTokenKey 返回用户令牌键
+func UserTokenKey(token string) string {
+	return fmt.Sprintf("user_token:%s", token)
+}
+
+// LifecycleEventKey 返回活动生命周期事件去重标记键
+func LifecycleEventKey(goodsId int64, event string) string {
+	return fmt.Sprintf("activity_lifecycle:%s:%s", goodsHashTag(goodsId), event)
+}
+
+// UserTokensIndexKey 返回用户已签发令牌索引集合键，成员为用户令牌和秒杀令牌的完整键
+func UserTokensIndexKey(userId int64) string {
+	return fmt.Sprintf("user_tokens_index:%d", userId)
+}
+
+// StockChannel 返回商品库存变更的发布订阅频道名
+func StockChannel(goodsId int64) string {
+	return fmt.Sprintf("stock_channel:%d", goodsId)
+}
+
+// KeySlot 计算键在Redis集群中的槽位
+// 键包含非空的哈希标签{...}时只对第一个标签内的内容计算CRC16
+func KeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % redisClusterSlots)
+}
+
+// ValidateScriptKeys 校验Lua脚本的键数量不超过maxKeys且全部位于同一槽位
+func ValidateScriptKeys(keys []string, maxKeys int) error {
+	if maxKeys > 0 && len(keys) > maxKeys {
+		return fmt.Errorf("%w: %d keys, max %d", ErrTooManyScriptKeys, len(keys), maxKeys)
+	}
+	for _, key := range keys[min(1, len(keys)):] {
+		if KeySlot(key) != KeySlot(keys[0]) {
+			return fmt.Errorf("%w: %s and %s", ErrCrossSlotKeys, keys[0], key)
+		}
+	}
+	return nil
+}
+
+// crc16 计算CRC16-XMODEM校验值（Redis集群槽位算法）
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -7,12 +7,17 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	mathrand "math/rand"
 	"os"
 	"path/filepath"
 	"runtime"
+	"seckill_system/config"
+	"seckill_system/errs"
 	"seckill_system/global"
 	"seckill_system/model"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -21,13 +26,38 @@ import (
 // RedisRepository Redis缓存仓库层
 // 负责用户令牌、秒杀令牌、库存管理、限流等缓存操作
 type RedisRepository struct {
-	client *redis.ClusterClient // Redis集群客户端
+	client          redis.UniversalClient // Redis客户端，集群和单节点模式通用
+	maxScriptKeys   int                   // 单个Lua脚本允许的最大键数量
+	tokenLength     int                   // 签发和校验的令牌长度
+	userTokenTTL    time.Duration         // 用户令牌有效期
+	seckillTokenTTL time.Duration         // 秒杀令牌有效期
+	ttlJitter       int                   // 令牌有效期随机抖动百分比
+	verifyAttempts  int                   // 用户令牌校验最多执行次数（含首次）
+	verifyBackoff   time.Duration         // 用户令牌校验首次重试前的等待时间
 }
 
 // 包级变量，存储所有Lua脚本
 var (
 	userRateLimitScript   *redis.Script
 	stockOperationsScript *redis.Script
+	reserveTokenScript    *redis.Script
+	consumeTokenScript    *redis.Script
+	releaseLockScript     *redis.Script
+	stockMultiDecrScript  *redis.Script
+	releaseQuotaScript    *redis.Script
+)
+
+// 商品信息缓存相关常量
+const (
+	GoodsInfoCacheTTL       = 5 * time.Minute // 缓存逻辑有效期
+	goodsInfoCacheRetention = 24 * time.Hour  // 缓存物理保留时间，用于数据库故障时降级
+)
+
+// 库存相关错误
+var (
+	ErrStockNotFound        = errs.ErrStockNotFound        // Redis中不存在库存
+	ErrGoodsSoldOut         = errs.ErrSoldOut              // 库存不足
+	ErrStaleStockGeneration = errs.ErrStaleStockGeneration // 库存代次已过期
 )
 
 // init 函数在包初始化时自动调用，用于加载Lua脚本
@@ -48,13 +78,86 @@ func init() {
 	}
 	stockOperationsScript = redis.NewScript(stockScript)
 
+	// 加载预占库存并签发令牌脚本
+	reserveScript, err := loadLuaScript("reserve_token.lua")
+	if err != nil {
+		slog.Error("Failed to load reserve token Lua script", "error", err)
+		panic(fmt.Sprintf("Failed to load reserve token Lua script: %v", err))
+	}
+	reserveTokenScript = redis.NewScript(reserveScript)
+
+	// 加载校验并消费令牌脚本
+	consumeScript, err := loadLuaScript("consume_token.lua")
+	if err != nil {
+		slog.Error("Failed to load consume token Lua script", "error", err)
+		panic(fmt.Sprintf("Failed to load consume token Lua script: %v", err))
+	}
+	consumeTokenScript = redis.NewScript(consumeScript)
+
+	// 加载校验持有者令牌并释放锁脚本
+	releaseScript, err := loadLuaScript("release_lock.lua")
+	if err != nil {
+		slog.Error("Failed to load release lock Lua script", "error", err)
+		panic(fmt.Sprintf("Failed to load release lock Lua script: %v", err))
+	}
+	releaseLockScript = redis.NewScript(releaseScript)
+
+	// 加载购物车多商品库存扣减脚本
+	multiDecrScript, err := loadLuaScript("stock_multi_decr.lua")
+	if err != nil {
+		slog.Error("Failed to load stock multi decrease Lua script", "error", err)
+		panic(fmt.Sprintf("Failed to load stock multi decrease Lua script: %v", err))
+	}
+	stockMultiDecrScript = redis.NewScript(multiDecrScript)
+
+	// 加载归还计数脚本
+	quotaScript, err := loadLuaScript("release_quota.lua")
+	if err != nil {
+		slog.Error("Failed to load release quota Lua script", "error", err)
+		panic(fmt.Sprintf("Failed to load release quota Lua script: %v", err))
+	}
+	releaseQuotaScript = redis.NewScript(quotaScript)
+
 	slog.Info("All Lua scripts loaded successfully")
 }
 
 // NewRedisRepository 创建Redis仓库实例
+// Redis客户端未初始化时panic
 func NewRedisRepository() *RedisRepository {
+	global.MustClient("Redis", "InitRedis", global.RedisClient != nil)
+	maxScriptKeys := global.RedisMaxScriptKeys
+	if maxScriptKeys <= 0 {
+		maxScriptKeys = DefaultMaxScriptKeys
+	}
+	tokenLength := global.RedisTokenLength
+	if tokenLength <= 0 {
+		tokenLength = DefaultTokenLength
+	}
+	userTokenTTL := global.UserTokenTTL
+	if userTokenTTL <= 0 {
+		userTokenTTL = config.DefaultUserTokenTTL
+	}
+	seckillTokenTTL := global.SeckillTokenTTL
+	if seckillTokenTTL <= 0 {
+		seckillTokenTTL = config.DefaultSeckillTokenTTL
+	}
+	verifyAttempts := global.TokenVerifyAttempts
+	if verifyAttempts <= 0 {
+		verifyAttempts = config.DefaultTokenVerifyAttempts
+	}
+	verifyBackoff := global.TokenVerifyBackoff
+	if verifyBackoff <= 0 {
+		verifyBackoff = config.DefaultTokenVerifyBackoffMs * time.Millisecond
+	}
 	return &RedisRepository{
-		client: global.RedisClusterClient,
+		client:          global.RedisClient,
+		maxScriptKeys:   maxScriptKeys,
+		tokenLength:     tokenLength,
+		userTokenTTL:    userTokenTTL,
+		seckillTokenTTL: seckillTokenTTL,
+		ttlJitter:       global.TokenTTLJitter,
+		verifyAttempts:  verifyAttempts,
+		verifyBackoff:   verifyBackoff,
 	}
 }
 
@@ -80,13 +183,14 @@ func loadLuaScript(filename string) (string, error) {
 
 // CheckAndDecrStock 原子性地检查并减少库存
 func (r *RedisRepository) CheckAndDecrStock(goodsId int64) (bool, error) {
-	key := fmt.Sprintf("goods_stock:%d", goodsId)
+	key := StockKey(goodsId)
 
 	result, err := stockOperationsScript.Run(
 		context.Background(),
 		r.client,
 		[]string{key},
-		"check_and_decr", // 命令参数
+		"check_and_decr",      // 命令参数
+		StockChannel(goodsId), // 库存变更频道
 	).Result()
 
 	if err != nil {
@@ -95,9 +199,9 @@ func (r *RedisRepository) CheckAndDecrStock(goodsId int64) (bool, error) {
 
 	switch result.(int64) {
 	case -1:
-		return false, errors.New("goods stock not found")
+		return false, ErrStockNotFound
 	case -2:
-		return false, errors.New("goods sold out")
+		return false, ErrGoodsSoldOut
 	case -99:
 		return false, errors.New("unknown stock operation command")
 	default:
@@ -109,16 +213,253 @@ func (r *RedisRepository) CheckAndDecrStock(goodsId int64) (bool, error) {
 	}
 }
 
+// CheckAndDecrStockBy 原子性地检查并按数量减少库存
+// 剩余库存不足qty时不扣减，返回ErrGoodsSoldOut
+func (r *RedisRepository) CheckAndDecrStockBy(goodsId, qty int64) (bool, error) {
+	if _, err := r.DecrStockBy(goodsId, qty); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DecrStockBy 原子性地检查并按数量减少库存，返回扣减后的剩余库存
+// 剩余库存不足qty时不扣减，返回ErrGoodsSoldOut
+func (r *RedisRepository) DecrStockBy(goodsId, qty int64) (int64, error) {
+	if qty <= 0 {
+		return 0, fmt.Errorf("invalid stock quantity: %d", qty)
+	}
+	key := StockKey(goodsId)
+
+	result, err := stockOperationsScript.Run(
+		context.Background(),
+		r.client,
+		[]string{key},
+		"check_and_decr_by",   // 命令参数
+		qty,                   // 扣减数量
+		StockChannel(goodsId), // 库存变更频道
+	).Result()
+
+	if err != nil {
+		return 0, fmt.Errorf("atomic stock decrease failed: %v", err)
+	}
+
+	switch result.(int64) {
+	case -1:
+		return 0, ErrStockNotFound
+	case -2:
+		return 0, ErrGoodsSoldOut
+	case -99:
+		return 0, errors.New("unknown stock operation command")
+	default:
+		slog.Info("Stock decreased atomically",
+			"goods_id", goodsId,
+			"quantity", qty,
+			"remaining_stock", result.(int64),
+		)
+		return result.(int64), nil
+	}
+}
+
+// CheckAndDecrStockMulti 按数量扣减购物车中多个商品的秒杀库存（goods_stock键）
+// items为商品ID到购买数量的映射；任一商品库存不存在或不足时全部不扣减，
+// 返回的错误包装ErrStockNotFound或ErrGoodsSoldOut并指明商品ID
+// 库存键位于同一槽位且不超过脚本键数量上限时由一个Lua脚本原子扣减，
+// 否则逐个商品检查并扣减，失败时归还已扣减的库存
+func (r *RedisRepository) CheckAndDecrStockMulti(items map[int64]int64) (bool, error) {
+	if len(items) == 0 {
+		return false, errors.New("no items to decrease stock")
+	}
+
+	// 按商品ID排序，保证键顺序稳定
+	goodsIds := make([]int64, 0, len(items))
+	for goodsId, qty := range items {
+		if qty <= 0 {
+			return false, fmt.Errorf("invalid stock quantity for goods %d: %d", goodsId, qty)
+		}
+		goodsIds = append(goodsIds, goodsId)
+	}
+	slices.Sort(goodsIds)
+
+	keys := make([]string, len(goodsIds))
+	args := make([]interface{}, 2*len(goodsIds))
+	for i, goodsId := range goodsIds {
+		keys[i] = StockKey(goodsId)
+		args[i] = items[goodsId]
+		args[len(goodsIds)+i] = StockChannel(goodsId)
+	}
+	if err := ValidateScriptKeys(keys, r.maxScriptKeys); err != nil {
+		// 集群模式下各商品库存键通常位于不同槽位，无法在一个脚本中扣减
+		return r.decrStockEach(goodsIds, items)
+	}
+
+	result, err := stockMultiDecrScript.Run(context.Background(), r.client, keys, args...).Int64Slice()
+	if err != nil {
+		return false, fmt.Errorf("atomic multi stock decrease failed: %v", err)
+	}
+	if len(result) != 2 {
+		return false, fmt.Errorf("unexpected multi stock decrease result: %v", result)
+	}
+
+	switch result[0] {
+	case 0:
+		slog.Info("Cart stock decreased atomically", "items", items)
+		return true, nil
+	case -1:
+		return false, fmt.Errorf("%w: goods %d", ErrStockNotFound, goodsIds[result[1]-1])
+	case -2:
+		return false, fmt.Errorf("%w: goods %d", ErrGoodsSoldOut, goodsIds[result[1]-1])
+	default:
+		return false, fmt.Errorf("unexpected multi stock decrease result: %v", result)
+	}
+}
+
+// decrStockEach 按商品ID顺序逐个检查并扣减库存，任一商品失败时归还此前已扣减的库存
+// 每个商品的扣减都检查剩余库存，不会超卖；归还前其他请求可能短暂看到较低的库存
+func (r *RedisRepository) decrStockEach(goodsIds []int64, items map[int64]int64) (bool, error) {
+	for i, goodsId := range goodsIds {
+		if _, err := r.DecrStockBy(goodsId, items[goodsId]); err != nil {
+			for _, decremented := range goodsIds[:i] {
+				if _, restoreErr := r.IncrGoodsStockBy(decremented, items[decremented]); restoreErr != nil {
+					slog.Error("Failed to restore cart stock after partial decrease",
+						"goods_id", decremented,
+						"quantity", items[decremented],
+						"error", restoreErr,
+					)
+				}
+			}
+			return false, fmt.Errorf("%w: goods %d", err, goodsId)
+		}
+	}
+	slog.Info("Cart stock decreased per goods", "items", items)
+	return true, nil
+}
+
+// SetStockWithGeneration 原子性地写入库存并递增库存代次，返回新代次
+// 用于重新开始活动，携带旧代次的扣减请求此后会被拒绝，不会消耗新一轮的库存
+func (r *RedisRepository) SetStockWithGeneration(goodsId, stock int64) (int64, error) {
+	keys := []string{StockKey(goodsId), StockGenerationKey(goodsId), SeckillTokenReservationsKey(goodsId)}
+	if err := ValidateScriptKeys(keys, r.maxScriptKeys); err != nil {
+		return 0, err
+	}
+	result, err := stockOperationsScript.Run(
+		context.Background(),
+		r.client,
+		keys,
+		"set_stock_gen",       // 命令参数
+		stock,                 // 库存数量
+		StockChannel(goodsId), // 库存变更频道
+	).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("atomic stock set with generation failed: %v", err)
+	}
+	if result == -99 {
+		return 0, errors.New("unknown stock operation command")
+	}
+
+	slog.Info("Stock set with new generation",
+		"goods_id", goodsId,
+		"stock", stock,
+		"generation", result,
+	)
+	return result, nil
+}
+
+// GetStockGeneration 获取商品当前的库存代次，从未设置过代次时返回0
+func (r *RedisRepository) GetStockGeneration(goodsId int64) (int64, error) {
+	generation, err := r.client.Get(context.Background(), StockGenerationKey(goodsId)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get stock generation failed: %v", err)
+	}
+	return generation, nil
+}
+
+// DecrStockByGeneration 校验库存代次后原子性地按数量减少库存，返回扣减后的剩余库存
+// 代次与当前代次不一致时不扣减，返回ErrStaleStockGeneration
+func (r *RedisRepository) DecrStockByGeneration(goodsId, qty, generation int64) (int64, error) {
+	if qty <= 0 {
+		return 0, fmt.Errorf("invalid stock quantity: %d", qty)
+	}
+	keys := []string{StockKey(goodsId), StockGenerationKey(goodsId)}
+	if err := ValidateScriptKeys(keys, r.maxScriptKeys); err != nil {
+		return 0, err
+	}
+
+	result, err := stockOperationsScript.Run(
+		context.Background(),
+		r.client,
+		keys,
+		"check_and_decr_gen",  // 命令参数
+		qty,                   // 扣减数量
+		generation,            // 期望的库存代次
+		StockChannel(goodsId), // 库存变更频道
+	).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("atomic stock decrease failed: %v", err)
+	}
+
+	switch result {
+	case -1:
+		return 0, ErrStockNotFound
+	case -2:
+		return 0, ErrGoodsSoldOut
+	case -3:
+		slog.Warn("Stale stock generation rejected",
+			"goods_id", goodsId,
+			"generation", generation,
+		)
+		return 0, ErrStaleStockGeneration
+	case -99:
+		return 0, errors.New("unknown stock operation command")
+	default:
+		slog.Info("Stock decreased atomically",
+			"goods_id", goodsId,
+			"quantity", qty,
+			"generation", generation,
+			"remaining_stock", result,
+		)
+		return result, nil
+	}
+}
+
+// lifecycleMarkerTTL 活动生命周期事件去重标记的保留时间
+const lifecycleMarkerTTL = 7 * 24 * time.Hour
+
+// MarkLifecycleEvent 标记商品活动的生命周期事件已触发，首次标记返回true
+func (r *RedisRepository) MarkLifecycleEvent(goodsId int64, event string) (bool, error) {
+	marked, err := r.client.SetNX(context.Background(), LifecycleEventKey(goodsId, event), time.Now().Unix(), lifecycleMarkerTTL).Result()
+	if err != nil {
+		return false, fmt.Errorf("mark lifecycle event failed: %v", err)
+	}
+	return marked, nil
+}
+
+// ResetLifecycleEvents 清除商品活动的全部生命周期事件标记，重新预加载库存后开始新一轮活动
+// 标记键使用相同哈希标签，可在一条命令中删除
+func (r *RedisRepository) ResetLifecycleEvents(goodsId int64) error {
+	keys := make([]string, len(model.LifecycleEvents))
+	for i, event := range model.LifecycleEvents {
+		keys[i] = LifecycleEventKey(goodsId, event)
+	}
+	if err := r.client.Del(context.Background(), keys...).Err(); err != nil {
+		return fmt.Errorf("reset lifecycle events failed: %v", err)
+	}
+	return nil
+}
+
 // CheckAndSetStock 原子性地检查并设置库存（如果不存在）
 func (r *RedisRepository) CheckAndSetStock(goodsId, stock int64) (bool, error) {
-	key := fmt.Sprintf("goods_stock:%d", goodsId)
+	key := StockKey(goodsId)
 
 	result, err := stockOperationsScript.Run(
 		context.Background(),
 		r.client,
 		[]string{key},
-		"check_and_set", // 命令参数
-		stock,           // 库存数量
+		"check_and_set",       // 命令参数
+		stock,                 // 库存数量
+		StockChannel(goodsId), // 库存变更频道
 	).Result()
 
 	if err != nil {
@@ -139,9 +480,51 @@ func (r *RedisRepository) CheckAndSetStock(goodsId, stock int64) (bool, error) {
 	return success, nil
 }
 
-// GetStockAtomic 原子性地获取库存
+// 库存校准结果
+const (
+	StockReconcileKept        = "kept"        // 缓存库存不高于目标值，保留
+	StockReconcileRepopulated = "repopulated" // 缓存库存不存在，已写入目标值
+	StockReconcileLowered     = "lowered"     // 缓存库存高于目标值，已下调
+)
+
+// ReconcileStock 原子性地以目标库存校准Redis库存，返回校准结果
+// 库存键不存在时写入目标值，高于目标值时下调，不高于目标值时保留，不会上调正在售卖中的库存
+func (r *RedisRepository) ReconcileStock(goodsId, target int64) (string, error) {
+	result, err := stockOperationsScript.Run(
+		context.Background(),
+		r.client,
+		[]string{StockKey(goodsId), SeckillTokenReservationsKey(goodsId)},
+		"reconcile",           // 命令参数
+		target,                // 目标库存
+		StockChannel(goodsId), // 库存变更频道
+	).Result()
+	if err != nil {
+		return "", fmt.Errorf("atomic stock reconcile failed: %v", err)
+	}
+
+	switch result.(int64) {
+	case 1:
+		slog.Warn("Evicted goods stock repopulated",
+			"goods_id", goodsId,
+			"stock", target,
+		)
+		return StockReconcileRepopulated, nil
+	case 2:
+		slog.Warn("Goods stock lowered to database value",
+			"goods_id", goodsId,
+			"stock", target,
+		)
+		return StockReconcileLowered, nil
+	case -99:
+		return "", errors.New("unknown stock operation command")
+	default:
+		return StockReconcileKept, nil
+	}
+}
+
+// GetStockAtomic 原子性地获取库存，库存未预加载时返回ErrStockNotFound
 func (r *RedisRepository) GetStockAtomic(goodsId int64) (int64, error) {
-	key := fmt.Sprintf("goods_stock:%d", goodsId)
+	key := StockKey(goodsId)
 
 	result, err := stockOperationsScript.Run(
 		context.Background(),
@@ -150,6 +533,9 @@ func (r *RedisRepository) GetStockAtomic(goodsId int64) (int64, error) {
 		"get_stock", // 命令参数
 	).Result()
 
+	if err == redis.Nil {
+		return 0, fmt.Errorf("%w: goods %d", ErrStockNotFound, goodsId)
+	}
 	if err != nil {
 		return 0, fmt.Errorf("atomic stock get failed: %v", err)
 	}
@@ -167,14 +553,14 @@ func (r *RedisRepository) GetStockAtomic(goodsId int64) (int64, error) {
 }
 
 // GenerateUserToken 生成用户认证令牌并存储到Redis
-// 令牌有效期为24小时
+// 令牌有效期由token.user_token_ttl配置，默认24小时
 func (r *RedisRepository) GenerateUserToken(userId int64) (string, error) {
 	// 生成随机令牌字符串
-	token, err := generateRandomString(32)
+	token, err := generateRandomString(r.tokenLength)
 	if err != nil {
 		return "", fmt.Errorf("generate secure token failed: %v", err)
 	}
-	expireAt := time.Now().Add(24 * time.Hour)
+	expireAt := time.Now().Add(r.jitteredTTL(r.userTokenTTL))
 
 	// 构建令牌数据结构
 	tokenData := model.RedisToken{
@@ -190,31 +576,60 @@ func (r *RedisRepository) GenerateUserToken(userId int64) (string, error) {
 		return "", fmt.Errorf("marshal token data failed: %v", err)
 	}
 
-	// 存储令牌到Redis，设置过期时间
-	key := fmt.Sprintf("user_token:%s", token)
-	err = r.client.Set(context.Background(), key, jsonData, time.Until(expireAt)).Err()
-	if err != nil {
+	// 存储令牌到Redis，设置过期时间，并加入用户令牌索引
+	key := UserTokenKey(token)
+	if err := r.storeIndexedToken(userId, key, jsonData, time.Until(expireAt)); err != nil {
 		return "", fmt.Errorf("store token to redis failed: %v", err)
 	}
 
 	slog.Info("User token generated",
 		"user_id", userId,
-		"token_prefix", token[:8],
+		"token_prefix", model.TokenPrefix(token),
 		"expire_at", expireAt,
 	)
 	return token, nil
 }
 
+// getUserToken 读取用户令牌数据，遇到Redis瞬时错误时以指数退避重试，最多执行verifyAttempts次
+// 令牌不存在（redis.Nil）是确定结果，立即返回不重试
+func (r *RedisRepository) getUserToken(key, token string) ([]byte, error) {
+	var err error
+	for attempt := 1; attempt <= r.verifyAttempts; attempt++ {
+		var data []byte
+		data, err = r.client.Get(context.Background(), key).Bytes()
+		if err == nil || err == redis.Nil {
+			return data, err
+		}
+		if attempt == r.verifyAttempts {
+			break
+		}
+		delay := r.verifyBackoff << (attempt - 1)
+		slog.Warn("Transient redis error verifying user token, retrying",
+			"token_prefix", model.TokenPrefix(token),
+			"attempt", attempt,
+			"retry_in", delay,
+			"error", err,
+		)
+		time.Sleep(delay)
+	}
+	return nil, err
+}
+
 // VerifyUserToken 验证用户令牌有效性并返回用户ID
 func (r *RedisRepository) VerifyUserToken(token string) (int64, error) {
-	key := fmt.Sprintf("user_token:%s", token)
-	data, err := r.client.Get(context.Background(), key).Bytes()
+	// 格式不合法的令牌直接拒绝，不访问Redis
+	if !ValidTokenFormat(token, r.tokenLength) {
+		slog.Warn("Malformed user token rejected", "token_length", len(token))
+		return 0, errs.ErrTokenMalformed
+	}
+	key := UserTokenKey(token)
+	data, err := r.getUserToken(key, token)
 	if err != nil {
 		if err == redis.Nil {
-			slog.Warn("User token not found", "token_prefix", token[:8])
-			return 0, errors.New("token not found")
+			slog.Warn("User token not found", "token_prefix", model.TokenPrefix(token))
+			return 0, errs.ErrTokenNotFound
 		}
-		return 0, fmt.Errorf("get token from redis failed: %v", err)
+		return 0, fmt.Errorf("get token from redis failed: %w", err)
 	}
 
 	// 反序列化令牌数据
@@ -226,25 +641,25 @@ func (r *RedisRepository) VerifyUserToken(token string) (int64, error) {
 	// 检查令牌是否过期
 	if time.Now().After(tokenData.ExpireAt) {
 		r.client.Del(context.Background(), key) // 删除过期令牌
-		slog.Warn("User token expired", "token_prefix", token[:8], "user_id", tokenData.UserId)
-		return 0, errors.New("token expired")
+		slog.Warn("User token expired", "token_prefix", model.TokenPrefix(token), "user_id", tokenData.UserId)
+		return 0, errs.ErrTokenExpired
 	}
 
 	slog.Info("User token verified successfully",
 		"user_id", tokenData.UserId,
-		"token_prefix", token[:8],
+		"token_prefix", model.TokenPrefix(token),
 	)
 	return tokenData.UserId, nil
 }
 
 // GenerateSeckillToken 生成秒杀令牌并存储到Redis
-// 令牌有效期为30分钟，用于控制秒杀请求
+// 令牌有效期由token.seckill_token_ttl配置，默认30分钟，并按token.ttl_jitter_percent随机浮动，用于控制秒杀请求
 func (r *RedisRepository) GenerateSeckillToken(userId, goodsId int64) (string, error) {
-	tokenId, err := generateRandomString(32)
+	tokenId, err := generateRandomString(r.tokenLength)
 	if err != nil {
 		return "", fmt.Errorf("generate secure token failed: %v", err)
 	}
-	expireAt := time.Now().Add(30 * time.Minute)
+	expireAt := time.Now().Add(r.jitteredTTL(r.seckillTokenTTL))
 
 	// 构建秒杀令牌数据结构
 	tokenData := model.RedisSeckillToken{
@@ -261,120 +676,495 @@ func (r *RedisRepository) GenerateSeckillToken(userId, goodsId int64) (string, e
 		return "", fmt.Errorf("marshal seckill token failed: %v", err)
 	}
 
-	// 存储秒杀令牌到Redis
-	key := fmt.Sprintf("seckill_token:%s", tokenId)
-	err = r.client.Set(context.Background(), key, jsonData, time.Until(expireAt)).Err()
-	if err != nil {
+	// 存储秒杀令牌到Redis，并加入用户令牌索引
+	key := SeckillTokenKey(goodsId, tokenId)
+	if err := r.storeIndexedToken(userId, key, jsonData, time.Until(expireAt)); err != nil {
 		return "", fmt.Errorf("store seckill token to redis failed: %v", err)
 	}
 
 	slog.Info("Seckill token generated",
 		"user_id", userId,
 		"goods_id", goodsId,
-		"token_id_prefix", tokenId[:8],
+		"token_id_prefix", model.TokenPrefix(tokenId),
 		"expire_at", expireAt,
 	)
+	r.incrTokenCounter(SeckillTokenIssuedKey(goodsId), goodsId)
 	return tokenId, nil
 }
 
-// VerifySeckillToken 验证秒杀令牌有效性
-// 验证成功后令牌会被删除（一次性使用）
-func (r *RedisRepository) VerifySeckillToken(tokenId string, userId, goodsId int64) (bool, error) {
-	key := fmt.Sprintf("seckill_token:%s", tokenId)
-	data, err := r.client.Get(context.Background(), key).Bytes()
+// incrTokenCounter 递增商品的令牌签发或兑换计数，计数只用于统计，失败时记录告警不影响令牌
+func (r *RedisRepository) incrTokenCounter(key string, goodsId int64) {
+	if err := r.client.Incr(context.Background(), key).Err(); err != nil {
+		slog.Warn("Failed to increment seckill token counter",
+			"key", key,
+			"goods_id", goodsId,
+			"error", err,
+		)
+	}
+}
+
+// GetSeckillTokenStats 获取商品已签发和已兑换的秒杀令牌数，两个计数键位于同一槽位，一次读取
+func (r *RedisRepository) GetSeckillTokenStats(goodsId int64) (model.SeckillTokenStats, error) {
+	values, err := r.client.MGet(context.Background(),
+		SeckillTokenIssuedKey(goodsId),
+		SeckillTokenRedeemedKey(goodsId),
+	).Result()
 	if err != nil {
-		if err == redis.Nil {
-			slog.Warn("Seckill token not found", "token_id_prefix", tokenId[:8])
-			return false, nil // 令牌不存在
+		return model.SeckillTokenStats{}, fmt.Errorf("get seckill token stats failed: %v", err)
+	}
+	counts := make([]int64, len(values))
+	for i, value := range values {
+		if value == nil {
+			continue // 计数键不存在表示尚未签发或兑换
+		}
+		s, _ := value.(string)
+		if counts[i], err = strconv.ParseInt(s, 10, 64); err != nil {
+			return model.SeckillTokenStats{}, fmt.Errorf("parse seckill token counter failed: %v", err)
 		}
-		return false, fmt.Errorf("get seckill token from redis failed: %v", err)
 	}
+	return model.NewSeckillTokenStats(goodsId, counts[0], counts[1]), nil
+}
 
-	// 反序列化秒杀令牌数据
-	var tokenData model.RedisSeckillToken
-	if err := json.Unmarshal(data, &tokenData); err != nil {
-		return false, fmt.Errorf("unmarshal seckill token failed: %v", err)
+// jitteredTTL 在ttl的±ttlJitter%范围内随机浮动（精确到秒），避免同一时段签发的令牌同时过期
+func (r *RedisRepository) jitteredTTL(ttl time.Duration) time.Duration {
+	spread := int64(ttl.Seconds()) * int64(r.ttlJitter) / 100
+	if spread <= 0 {
+		return ttl
 	}
+	return ttl + time.Duration(mathrand.Int63n(2*spread+1)-spread)*time.Second
+}
 
-	// 检查令牌是否过期
-	if time.Now().After(tokenData.ExpireAt) {
-		r.client.Del(context.Background(), key) // 删除过期令牌
-		slog.Warn("Seckill token expired",
-			"token_id_prefix", tokenId[:8],
-			"user_id", userId,
-			"goods_id", goodsId,
-		)
-		return false, errors.New("token expired")
+// storeIndexedToken 存储令牌并将令牌键加入用户令牌索引，用于按用户批量吊销
+// 令牌键与索引键可能位于不同槽位，使用普通流水线而非事务
+func (r *RedisRepository) storeIndexedToken(userId int64, key string, data []byte, ttl time.Duration) error {
+	indexKey := UserTokensIndexKey(userId)
+	_, err := r.client.Pipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		pipe.Set(context.Background(), key, data, ttl)
+		pipe.SAdd(context.Background(), indexKey, key)
+		pipe.Expire(context.Background(), indexKey, r.tokensIndexTTL(ttl))
+		return nil
+	})
+	return err
+}
+
+// tokensIndexTTL 返回用户令牌索引的过期时间，不短于最长的令牌有效期
+func (r *RedisRepository) tokensIndexTTL(ttl time.Duration) time.Duration {
+	return max(r.userTokenTTL, r.seckillTokenTTL, ttl)
+}
+
+// indexUserToken 将已存储的令牌键加入用户令牌索引，ttl为令牌有效期
+func (r *RedisRepository) indexUserToken(userId int64, key string, ttl time.Duration) error {
+	indexKey := UserTokensIndexKey(userId)
+	_, err := r.client.Pipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		pipe.SAdd(context.Background(), indexKey, key)
+		pipe.Expire(context.Background(), indexKey, r.tokensIndexTTL(ttl))
+		return nil
+	})
+	return err
+}
+
+// RevokeUserTokens 吊销用户已签发的全部用户令牌和秒杀令牌，返回实际删除的令牌数量
+// 通过用户令牌索引定位令牌键，已过期的令牌不计入；预占库存的令牌被删除后，其库存在令牌原定的过期时间后由预占清理归还
+func (r *RedisRepository) RevokeUserTokens(userId int64) (int64, error) {
+	ctx := context.Background()
+	indexKey := UserTokensIndexKey(userId)
+	keys, err := r.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("get user tokens index failed: %v", err)
+	}
+	if len(keys) == 0 {
+		return 0, nil
 	}
 
-	// 验证用户ID和商品ID是否匹配
-	if tokenData.UserId != userId || tokenData.GoodsId != goodsId {
-		slog.Warn("Seckill token mismatch",
-			"token_id_prefix", tokenId[:8],
-			"expected_user", userId,
-			"actual_user", tokenData.UserId,
-			"expected_goods", goodsId,
-			"actual_goods", tokenData.GoodsId,
-		)
-		return false, errors.New("token mismatch")
+	// 令牌键分布在不同槽位，逐个删除，由集群客户端按节点分组发送
+	cmds := make([]*redis.IntCmd, 0, len(keys))
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			cmds = append(cmds, pipe.Del(ctx, key))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("delete user tokens failed: %v", err)
+	}
+	var revoked int64
+	for _, cmd := range cmds {
+		revoked += cmd.Val()
 	}
 
-	// 验证成功后删除令牌（防止重复使用）
-	r.client.Del(context.Background(), key)
+	// 只移除已处理的成员，不影响吊销期间新签发的令牌
+	members := make([]any, len(keys))
+	for i, key := range keys {
+		members[i] = key
+	}
+	if err := r.client.SRem(ctx, indexKey, members...).Err(); err != nil {
+		return revoked, fmt.Errorf("clean user tokens index failed: %v", err)
+	}
 
-	slog.Info("Seckill token verified and consumed",
-		"token_id_prefix", tokenId[:8],
+	slog.Info("User tokens revoked",
 		"user_id", userId,
-		"goods_id", goodsId,
+		"indexed", len(keys),
+		"revoked", revoked,
 	)
-	return true, nil
+	return revoked, nil
 }
 
-// UserRateLimit 用户请求频率限制
-// 使用预加载的Lua脚本实现原子性的限流检查
-func (r *RedisRepository) UserRateLimit(userId int64, limit int64, duration time.Duration) (bool, error) {
-	key := fmt.Sprintf("user_rate_limit:%d", userId)
+// ReserveAndIssueToken 原子性地预占一个库存并签发秒杀令牌，返回令牌ID和预占后的剩余库存
+// 签发的令牌数量不会超过库存数量，库存不足时返回ErrGoodsSoldOut；签发前先归还已过期未兑换令牌预占的库存
+func (r *RedisRepository) ReserveAndIssueToken(userId, goodsId int64, ttl time.Duration) (string, int64, error) {
+	tokenId, err := generateRandomString(r.tokenLength)
+	if err != nil {
+		return "", 0, fmt.Errorf("generate secure token failed: %v", err)
+	}
+	expireAt := time.Now().Add(ttl)
 
-	// 使用预加载的Lua脚本执行限流逻辑
-	result, err := userRateLimitScript.Run(context.Background(), r.client, []string{key}, limit, int(duration.Seconds())).Result()
+	// 构建秒杀令牌数据结构，标记为已预占库存
+	tokenData := model.RedisSeckillToken{
+		TokenId:   tokenId,
+		UserId:    userId,
+		GoodsId:   goodsId,
+		Reserved:  true,
+		ExpireAt:  expireAt,
+		CreatedAt: time.Now(),
+	}
 
+	jsonData, err := json.Marshal(tokenData)
 	if err != nil {
-		return false, fmt.Errorf("execute rate limit script failed: %v", err)
+		return "", 0, fmt.Errorf("marshal seckill token failed: %v", err)
 	}
 
-	allowed := result.(int64) == 1
-	if !allowed {
-		slog.Info("User rate limit exceeded",
+	// 库存键、令牌键和预占记录键使用相同哈希标签，保证集群模式下位于同一槽位
+	keys := []string{StockKey(goodsId), SeckillTokenKey(goodsId, tokenId), SeckillTokenReservationsKey(goodsId)}
+	if err := ValidateScriptKeys(keys, r.maxScriptKeys); err != nil {
+		return "", 0, err
+	}
+	result, err := reserveTokenScript.Run(
+		context.Background(),
+		r.client,
+		keys,
+		string(jsonData),       // 令牌数据
+		int(ttl.Seconds()),     // 过期时间（秒），向下取整，令牌不会晚于预占记录过期
+		StockChannel(goodsId),  // 库存变更频道
+		tokenId,                // 预占记录成员
+		time.Now().UnixMilli(), // 当前时间，早于该时间的预占记录被归还
+		expireAt.UnixMilli(),   // 预占记录过期时间
+	).Result()
+	if err != nil {
+		return "", 0, fmt.Errorf("reserve stock and issue token failed: %v", err)
+	}
+
+	switch result.(int64) {
+	case -1:
+		return "", 0, ErrStockNotFound
+	case -2:
+		slog.Info("Goods sold out, seckill token not issued",
 			"user_id", userId,
-			"limit", limit,
-			"duration", duration,
+			"goods_id", goodsId,
 		)
-	} else {
-		slog.Info("User rate limit check passed",
+		return "", 0, ErrGoodsSoldOut
+	default:
+		// 令牌已由脚本写入，索引失败时令牌仍可使用，只是无法按用户吊销
+		if err := r.indexUserToken(userId, SeckillTokenKey(goodsId, tokenId), ttl); err != nil {
+			slog.Warn("Failed to index reserved seckill token",
+				"user_id", userId,
+				"goods_id", goodsId,
+				"error", err,
+			)
+		}
+		slog.Info("Stock reserved and seckill token issued",
 			"user_id", userId,
+			"goods_id", goodsId,
+			"token_id_prefix", model.TokenPrefix(tokenId),
+			"remaining_stock", result.(int64),
+			"expire_at", expireAt,
 		)
+		r.incrTokenCounter(SeckillTokenIssuedKey(goodsId), goodsId)
+		return tokenId, result.(int64), nil
 	}
-	return allowed, nil
 }
 
-// SetGoodsStock 设置商品库存到Redis
-func (r *RedisRepository) SetGoodsStock(goodsId int64, stock int64) error {
-	key := fmt.Sprintf("goods_stock:%d", goodsId)
-	err := r.client.Set(context.Background(), key, stock, 0).Err() // 0表示永不过期
-	if err != nil {
-		return err
-	}
-
-	slog.Info("Goods stock set in Redis",
-		"goods_id", goodsId,
-		"stock", stock,
-	)
-	return nil
+// SeckillTokenTTL 返回秒杀令牌有效期
+func (r *RedisRepository) SeckillTokenTTL() time.Duration {
+	return r.seckillTokenTTL
 }
 
-// GetGoodsStock 从Redis获取商品库存
+// ReleaseExpiredReservations 归还商品已过期未兑换令牌预占的库存，返回归还的件数
+// 预占记录的分值为令牌过期时间，早于now的记录对应的令牌已经过期，不会再被兑换
+func (r *RedisRepository) ReleaseExpiredReservations(goodsId int64, now time.Time) (int64, error) {
+	keys := []string{StockKey(goodsId), SeckillTokenReservationsKey(goodsId)}
+	if err := ValidateScriptKeys(keys, r.maxScriptKeys); err != nil {
+		return 0, err
+	}
+	released, err := stockOperationsScript.Run(
+		context.Background(),
+		r.client,
+		keys,
+		"release_expired_reservations", // 命令参数
+		now.UnixMilli(),                // 当前时间
+		StockChannel(goodsId),          // 库存变更频道
+	).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("release expired reservations failed: %v", err)
+	}
+	if released > 0 {
+		slog.Info("Expired seckill token reservations released",
+			"goods_id", goodsId,
+			"released", released,
+		)
+	}
+	return released, nil
+}
+
+// DeleteSeckillToken 删除未使用的秒杀令牌使其立即失效，返回令牌删除前是否存在
+// 令牌预占了库存时在同一脚本中归还
+func (r *RedisRepository) DeleteSeckillToken(goodsId int64, tokenId string) (bool, error) {
+	if !ValidTokenFormat(tokenId, r.tokenLength) {
+		return false, errs.ErrTokenMalformed
+	}
+	keys := []string{StockKey(goodsId), SeckillTokenReservationsKey(goodsId), SeckillTokenKey(goodsId, tokenId)}
+	if err := ValidateScriptKeys(keys, r.maxScriptKeys); err != nil {
+		return false, err
+	}
+	result, err := stockOperationsScript.Run(
+		context.Background(),
+		r.client,
+		keys,
+		"release_reservation", // 命令参数
+		tokenId,               // 预占记录成员
+		StockChannel(goodsId), // 库存变更频道
+	).Int64Slice()
+	if err != nil {
+		return false, fmt.Errorf("delete seckill token failed: %v", err)
+	}
+	if result[1] > 0 {
+		slog.Info("Seckill token reservation released",
+			"goods_id", goodsId,
+			"token_id_prefix", model.TokenPrefix(tokenId),
+		)
+	}
+	return result[0] > 0, nil
+}
+
+// VerifySeckillToken 验证秒杀令牌有效性
+// 校验与删除在同一个Lua脚本中原子执行（一次性使用），同一令牌的并发请求只有一个能验证成功
+// 令牌预占的库存在兑换后不再归还，需要跳过库存扣减的调用方应使用RedeemSeckillToken
+func (r *RedisRepository) VerifySeckillToken(tokenId string, userId, goodsId int64) (bool, error) {
+	valid, _, err := r.RedeemSeckillToken(tokenId, userId, goodsId)
+	return valid, err
+}
+
+// RedeemSeckillToken 校验并消费秒杀令牌，stockReserved表示令牌兑换时仍持有预占的库存，下单时不应再次扣减库存
+// 预占已被归还（库存重新写入或令牌已过期）的令牌按未预占处理
+func (r *RedisRepository) RedeemSeckillToken(tokenId string, userId, goodsId int64) (valid, stockReserved bool, err error) {
+	// 格式不合法的令牌直接拒绝，不执行消费脚本
+	if !ValidTokenFormat(tokenId, r.tokenLength) {
+		slog.Warn("Malformed seckill token rejected", "token_length", len(tokenId))
+		return false, false, errs.ErrTokenMalformed
+	}
+	keys := []string{SeckillTokenKey(goodsId, tokenId), SeckillTokenReservationsKey(goodsId)}
+	if err := ValidateScriptKeys(keys, r.maxScriptKeys); err != nil {
+		return false, false, err
+	}
+	result, err := consumeTokenScript.Run(
+		context.Background(),
+		r.client,
+		keys,
+		userId,  // 请求用户ID
+		goodsId, // 请求商品ID
+	).Slice()
+	if err != nil {
+		return false, false, fmt.Errorf("consume seckill token failed: %v", err)
+	}
+
+	status, _ := result[0].(int64)
+	if status == 0 {
+		slog.Warn("Seckill token not found", "token_id_prefix", model.TokenPrefix(tokenId))
+		return false, false, nil // 令牌不存在或已被其他请求消费
+	}
+
+	// 反序列化秒杀令牌数据
+	var tokenData model.RedisSeckillToken
+	data, _ := result[1].(string)
+	if err := json.Unmarshal([]byte(data), &tokenData); err != nil {
+		return false, false, fmt.Errorf("unmarshal seckill token failed: %v", err)
+	}
+	if status > 0 && len(result) > 2 {
+		reserved, _ := result[2].(int64)
+		stockReserved = reserved > 0
+	}
+
+	// 验证用户ID和商品ID是否匹配，不匹配时令牌由脚本保留
+	if status < 0 {
+		slog.Warn("Seckill token mismatch",
+			"token_id_prefix", model.TokenPrefix(tokenId),
+			"expected_user", userId,
+			"actual_user", tokenData.UserId,
+			"expected_goods", goodsId,
+			"actual_goods", tokenData.GoodsId,
+		)
+		return false, false, errs.ErrTokenMismatch
+	}
+
+	// 检查令牌是否过期，过期令牌已由脚本删除，仍持有的预占库存直接归还
+	if time.Now().After(tokenData.ExpireAt) {
+		slog.Warn("Seckill token expired",
+			"token_id_prefix", model.TokenPrefix(tokenId),
+			"user_id", userId,
+			"goods_id", goodsId,
+		)
+		if stockReserved {
+			if _, err := r.IncrGoodsStock(goodsId); err != nil {
+				slog.Error("Release expired token reservation failed", "goods_id", goodsId, "error", err)
+			}
+		}
+		return false, false, errs.ErrTokenExpired
+	}
+
+	slog.Info("Seckill token verified and consumed",
+		"token_id_prefix", model.TokenPrefix(tokenId),
+		"user_id", userId,
+		"goods_id", goodsId,
+	)
+	r.incrTokenCounter(SeckillTokenRedeemedKey(goodsId), goodsId)
+	return true, stockReserved, nil
+}
+
+// UserRateLimit 用户请求频率限制
+// 使用预加载的Lua脚本实现原子性的限流检查
+func (r *RedisRepository) UserRateLimit(userId int64, limit int64, duration time.Duration) (bool, error) {
+	key := UserRateLimitKey(userId)
+
+	// 使用预加载的Lua脚本执行限流逻辑
+	result, err := userRateLimitScript.Run(context.Background(), r.client, []string{key}, limit, int(duration.Seconds())).Result()
+
+	if err != nil {
+		return false, fmt.Errorf("execute rate limit script failed: %v", err)
+	}
+
+	allowed := result.(int64) == 1
+	if !allowed {
+		slog.Info("User rate limit exceeded",
+			"user_id", userId,
+			"limit", limit,
+			"duration", duration,
+		)
+	} else {
+		slog.Info("User rate limit check passed",
+			"user_id", userId,
+		)
+	}
+	return allowed, nil
+}
+
+// ReserveSeckillTokenQuota 占用用户在商品秒杀活动中的一次令牌签发次数，已达到limit次时返回false
+// 与用户限流使用同一Lua脚本，计数在ttl后过期，调用方通常传入活动剩余时间
+func (r *RedisRepository) ReserveSeckillTokenQuota(userId, goodsId, limit int64, ttl time.Duration) (bool, error) {
+	key := SeckillTokenQuotaKey(userId, goodsId)
+	seconds := max(int(ttl.Seconds()), 1)
+	result, err := userRateLimitScript.Run(context.Background(), r.client, []string{key}, limit, seconds).Result()
+	if err != nil {
+		return false, fmt.Errorf("execute token quota script failed: %v", err)
+	}
+	return result.(int64) == 1, nil
+}
+
+// ReleaseSeckillTokenQuota 归还一次已占用的令牌签发次数，令牌签发失败时调用
+// 计数已过期或已为0时不做操作，归还后保留原过期时间
+func (r *RedisRepository) ReleaseSeckillTokenQuota(userId, goodsId int64) error {
+	key := SeckillTokenQuotaKey(userId, goodsId)
+	if err := releaseQuotaScript.Run(context.Background(), r.client, []string{key}).Err(); err != nil {
+		return fmt.Errorf("execute token quota release script failed: %v", err)
+	}
+	return nil
+}
+
+// IPRateLimit 客户端IP请求频率限制
+// 与用户限流使用同一Lua脚本，防止同一IP轮换多个用户令牌绕过用户限流
+func (r *RedisRepository) IPRateLimit(ip string, limit int64, duration time.Duration) (bool, error) {
+	key := IPRateLimitKey(ip)
+
+	result, err := userRateLimitScript.Run(context.Background(), r.client, []string{key}, limit, int(duration.Seconds())).Result()
+	if err != nil {
+		return false, fmt.Errorf("execute ip rate limit script failed: %v", err)
+	}
+
+	allowed := result.(int64) == 1
+	if !allowed {
+		slog.Info("IP rate limit exceeded",
+			"client_ip", ip,
+			"limit", limit,
+			"duration", duration,
+		)
+	}
+	return allowed, nil
+}
+
+// GetUserRateCount 获取用户当前限流窗口内的请求次数（只读，不增加计数）
+func (r *RedisRepository) GetUserRateCount(userId int64) (int64, error) {
+	key := UserRateLimitKey(userId)
+	count, err := r.client.Get(context.Background(), key).Int64()
+	if err == redis.Nil {
+		return 0, nil // 窗口内尚无请求
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get user rate count failed: %v", err)
+	}
+	return count, nil
+}
+
+// SetGoodsStock 设置商品库存到Redis
+// 写入的库存来自数据库，未扣除令牌预占，因此同时清空预占记录，未兑换的令牌兑换时按未预占扣减库存
+func (r *RedisRepository) SetGoodsStock(goodsId int64, stock int64) error {
+	ctx := context.Background()
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, StockKey(goodsId), stock, 0) // 0表示永不过期
+		pipe.Del(ctx, SeckillTokenReservationsKey(goodsId))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Goods stock set in Redis",
+		"goods_id", goodsId,
+		"stock", stock,
+	)
+	return nil
+}
+
+// SetGoodsStockBatch 使用管道一次写入多个商品的库存
+// 返回写入失败的商品及其错误，全部成功时返回空映射
+func (r *RedisRepository) SetGoodsStockBatch(stocks map[int64]int64) map[int64]error {
+	failed := make(map[int64]error)
+	if len(stocks) == 0 {
+		return failed
+	}
+
+	ctx := context.Background()
+	cmds := make(map[int64]*redis.StatusCmd, len(stocks))
+	pipe := r.client.Pipeline()
+	for goodsId, stock := range stocks {
+		cmds[goodsId] = pipe.Set(ctx, StockKey(goodsId), stock, 0) // 0表示永不过期
+		pipe.Del(ctx, SeckillTokenReservationsKey(goodsId))        // 与SetGoodsStock一致，清空预占记录
+	}
+	pipe.Exec(ctx) // 各命令的错误在下方逐一检查
+
+	for goodsId, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			failed[goodsId] = fmt.Errorf("set goods stock failed: %w", err)
+		}
+	}
+
+	slog.Info("Goods stock set in Redis in batch",
+		"count", len(stocks),
+		"failed", len(failed),
+	)
+	return failed
+}
+
+// GetGoodsStock 从Redis获取商品库存
 func (r *RedisRepository) GetGoodsStock(goodsId int64) (int64, error) {
-	key := fmt.Sprintf("goods_stock:%d", goodsId)
+	key := StockKey(goodsId)
 	result, err := r.client.Get(context.Background(), key).Result()
 	if err != nil {
 		if err == redis.Nil {
@@ -396,10 +1186,41 @@ func (r *RedisRepository) GetGoodsStock(goodsId int64) (int64, error) {
 	return stock, nil
 }
 
+// GetGoodsStockBatch 批量获取商品库存
+// 集群模式下各商品库存键位于不同槽位，使用流水线逐个读取；未预加载的商品不出现在返回结果中
+func (r *RedisRepository) GetGoodsStockBatch(goodsIds []int64) (map[int64]int64, error) {
+	stocks := make(map[int64]int64, len(goodsIds))
+	if len(goodsIds) == 0 {
+		return stocks, nil
+	}
+
+	ctx := context.Background()
+	cmds := make([]*redis.StringCmd, len(goodsIds))
+	pipe := r.client.Pipeline()
+	for i, goodsId := range goodsIds {
+		cmds[i] = pipe.Get(ctx, StockKey(goodsId))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("batch get goods stock failed: %v", err)
+	}
+
+	for i, cmd := range cmds {
+		stock, err := cmd.Int64()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse stock for goods %d failed: %v", goodsIds[i], err)
+		}
+		stocks[goodsIds[i]] = stock
+	}
+	return stocks, nil
+}
+
 // DecrGoodsStock 减少商品库存（原子操作）
 // 返回减少后的库存值
 func (r *RedisRepository) DecrGoodsStock(goodsId int64) (int64, error) {
-	key := fmt.Sprintf("goods_stock:%d", goodsId)
+	key := StockKey(goodsId)
 	result, err := r.client.Decr(context.Background(), key).Result()
 	if err != nil {
 		return 0, err
@@ -415,7 +1236,7 @@ func (r *RedisRepository) DecrGoodsStock(goodsId int64) (int64, error) {
 // IncrGoodsStock 增加商品库存（原子操作）
 // 返回增加后的库存值
 func (r *RedisRepository) IncrGoodsStock(goodsId int64) (int64, error) {
-	key := fmt.Sprintf("goods_stock:%d", goodsId)
+	key := StockKey(goodsId)
 	result, err := r.client.Incr(context.Background(), key).Result()
 	if err != nil {
 		return 0, err
@@ -428,17 +1249,546 @@ func (r *RedisRepository) IncrGoodsStock(goodsId int64) (int64, error) {
 	return result, nil
 }
 
+// IncrGoodsStockBy 按数量增加商品库存（原子操作）
+// 返回增加后的库存值
+func (r *RedisRepository) IncrGoodsStockBy(goodsId, qty int64) (int64, error) {
+	key := StockKey(goodsId)
+	result, err := r.client.IncrBy(context.Background(), key, qty).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	slog.Info("Goods stock increased",
+		"goods_id", goodsId,
+		"quantity", qty,
+		"current_stock", result,
+	)
+	return result, nil
+}
+
+// orderOutboxKey 订单消息发件箱列表键
+const orderOutboxKey = "order_outbox"
+
+// pendingOrdersKey Redis-only模式待写入数据库的订单列表键
+const pendingOrdersKey = "seckill_pending_orders"
+
+// paymentPendingRetryKey 支付失败等待重试的订单有序集合键，分值为宽限期截止时间（毫秒）
+const paymentPendingRetryKey = "payment_pending_retry"
+
+// MarkPaymentPendingRetry 将支付失败的订单标记为等待重试，deadline为宽限期截止时间
+func (r *RedisRepository) MarkPaymentPendingRetry(orderId string, deadline time.Time) error {
+	err := r.client.ZAdd(context.Background(), paymentPendingRetryKey, &redis.Z{
+		Score:  float64(deadline.UnixMilli()),
+		Member: orderId,
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("mark payment pending retry failed: %v", err)
+	}
+	return nil
+}
+
+// ClearPaymentPendingRetry 清除订单的等待重试标记，返回订单此前是否处于等待重试状态
+func (r *RedisRepository) ClearPaymentPendingRetry(orderId string) (bool, error) {
+	removed, err := r.client.ZRem(context.Background(), paymentPendingRetryKey, orderId).Result()
+	if err != nil {
+		return false, fmt.Errorf("clear payment pending retry failed: %v", err)
+	}
+	return removed > 0, nil
+}
+
+// IsPaymentPendingRetry 判断订单是否处于支付失败等待重试状态
+func (r *RedisRepository) IsPaymentPendingRetry(orderId string) (bool, error) {
+	err := r.client.ZScore(context.Background(), paymentPendingRetryKey, orderId).Err()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get payment pending retry failed: %v", err)
+	}
+	return true, nil
+}
+
+// PaymentPendingRetryCount 获取等待支付重试的订单数量
+func (r *RedisRepository) PaymentPendingRetryCount() (int64, error) {
+	return r.client.ZCard(context.Background(), paymentPendingRetryKey).Result()
+}
+
+// ClaimExpiredPaymentRetries 领取宽限期已过的订单，最多limit条
+// 通过ZREM的返回值保证多个实例并发领取时每个订单只被一个实例领取
+func (r *RedisRepository) ClaimExpiredPaymentRetries(now time.Time, limit int64) ([]string, error) {
+	ctx := context.Background()
+	orderIds, err := r.client.ZRangeByScore(ctx, paymentPendingRetryKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get expired payment retries failed: %v", err)
+	}
+
+	claimed := make([]string, 0, len(orderIds))
+	for _, orderId := range orderIds {
+		removed, err := r.client.ZRem(ctx, paymentPendingRetryKey, orderId).Result()
+		if err != nil {
+			return claimed, fmt.Errorf("claim expired payment retry failed: %v", err)
+		}
+		if removed > 0 {
+			claimed = append(claimed, orderId)
+		}
+	}
+	return claimed, nil
+}
+
+// GetDistributedLock 使用SET NX获取分布式锁，ttl为锁自动过期时间（秒），锁的值为本次加锁生成的持有者令牌
+func (r *RedisRepository) GetDistributedLock(ctx context.Context, key string, ttl int) (string, bool, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return "", false, err
+	}
+	locked, err := r.client.SetNX(ctx, DistributedLockKey(key), token, time.Duration(ttl)*time.Second).Result()
+	if err != nil {
+		return "", false, fmt.Errorf("redis setnx lock failed: %v", err)
+	}
+
+	if !locked {
+		slog.Info("Distributed lock acquisition failed, key already exists",
+			"key", key,
+			"backend", "redis",
+		)
+		return "", false, nil
+	}
+	slog.Info("Distributed lock acquired",
+		"key", key,
+		"ttl", ttl,
+		"backend", "redis",
+	)
+	return token, true, nil
+}
+
+// GetDistributedLockWithRenewal 获取Redis分布式锁并每隔ttl的三分之一刷新过期时间，直到调用返回的stop
+// 续期使用独立的context，不受获取锁时ctx超时的影响
+func (r *RedisRepository) GetDistributedLockWithRenewal(ctx context.Context, key string, ttl int) (string, bool, context.CancelFunc, error) {
+	token, locked, err := r.GetDistributedLock(ctx, key, ttl)
+	if err != nil || !locked {
+		return "", false, nil, err
+	}
+
+	expiration := time.Duration(ttl) * time.Second
+	renewCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(max(expiration/3, time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+				renewed, err := r.client.Expire(renewCtx, DistributedLockKey(key), expiration).Result()
+				if renewCtx.Err() != nil {
+					return
+				}
+				if err != nil {
+					slog.Warn("Failed to renew distributed lock",
+						"key", key,
+						"backend", "redis",
+						"error", err,
+					)
+					continue
+				}
+				if !renewed {
+					// 锁已过期或被删除，无需继续续期
+					slog.Warn("Distributed lock renewal stopped, key no longer exists",
+						"key", key,
+						"backend", "redis",
+					)
+					return
+				}
+			}
+		}
+	}()
+	return token, true, func() {
+		cancel()
+		<-done
+	}, nil
+}
+
+// ReleaseDistributedLock 释放Redis分布式锁，仅当锁的值仍为token时删除
+// 锁已过期或已被其他请求重新获取时不删除，返回ErrLockNotHeld
+func (r *RedisRepository) ReleaseDistributedLock(ctx context.Context, key, token string) error {
+	released, err := releaseLockScript.Run(ctx, r.client, []string{DistributedLockKey(key)}, token).Int64()
+	if err != nil {
+		return fmt.Errorf("delete redis lock key failed: %v", err)
+	}
+	if released == 0 {
+		slog.Warn("Distributed lock not released, no longer held by caller",
+			"key", key,
+			"backend", "redis",
+		)
+		return ErrLockNotHeld
+	}
+	slog.Info("Distributed lock released",
+		"key", key,
+		"backend", "redis",
+	)
+	return nil
+}
+
+// PushOrderOutbox 将发送失败的订单消息追加到发件箱
+func (r *RedisRepository) PushOrderOutbox(entry *model.OrderOutboxEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal outbox entry failed: %v", err)
+	}
+	if err := r.client.RPush(context.Background(), orderOutboxKey, data).Err(); err != nil {
+		return fmt.Errorf("push outbox entry failed: %v", err)
+	}
+	return nil
+}
+
+// PopOrderOutbox 从发件箱头部取出一条订单消息，发件箱为空时found为false
+func (r *RedisRepository) PopOrderOutbox() (entry model.OrderOutboxEntry, found bool, err error) {
+	data, err := r.client.LPop(context.Background(), orderOutboxKey).Result()
+	if err == redis.Nil {
+		return entry, false, nil
+	}
+	if err != nil {
+		return entry, false, fmt.Errorf("pop outbox entry failed: %v", err)
+	}
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		return entry, false, fmt.Errorf("unmarshal outbox entry failed: %v", err)
+	}
+	return entry, true, nil
+}
+
+// OrderOutboxLen 获取发件箱中待重试的订单消息数量
+func (r *RedisRepository) OrderOutboxLen() (int64, error) {
+	return r.client.LLen(context.Background(), orderOutboxKey).Result()
+}
+
+// PushPendingOrder 将Redis-only模式的订单追加到待写库队列
+func (r *RedisRepository) PushPendingOrder(order *model.PendingOrder) error {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("marshal pending order failed: %v", err)
+	}
+	if err := r.client.RPush(context.Background(), pendingOrdersKey, data).Err(); err != nil {
+		return fmt.Errorf("push pending order failed: %v", err)
+	}
+	return nil
+}
+
+// PopPendingOrder 从待写库队列头部取出一条订单，队列为空时found为false
+func (r *RedisRepository) PopPendingOrder() (order model.PendingOrder, found bool, err error) {
+	data, err := r.client.LPop(context.Background(), pendingOrdersKey).Result()
+	if err == redis.Nil {
+		return order, false, nil
+	}
+	if err != nil {
+		return order, false, fmt.Errorf("pop pending order failed: %v", err)
+	}
+	if err := json.Unmarshal([]byte(data), &order); err != nil {
+		return order, false, fmt.Errorf("unmarshal pending order failed: %v", err)
+	}
+	return order, true, nil
+}
+
+// TakePendingOrder 从待写库队列中取出指定订单，订单不在队列中（未下单或已被写库任务取出）时found为false
+// 需要遍历整个队列，只用于订单尚未写库时收到支付结果等少见的情况
+func (r *RedisRepository) TakePendingOrder(orderId string) (order model.PendingOrder, found bool, err error) {
+	ctx := context.Background()
+	items, err := r.client.LRange(ctx, pendingOrdersKey, 0, -1).Result()
+	if err != nil {
+		return order, false, fmt.Errorf("list pending orders failed: %v", err)
+	}
+	for _, data := range items {
+		var candidate model.PendingOrder
+		if err := json.Unmarshal([]byte(data), &candidate); err != nil || candidate.OrderId != orderId {
+			continue
+		}
+		// 按原始数据删除，删除数为0说明已被写库任务并发取出
+		removed, err := r.client.LRem(ctx, pendingOrdersKey, 1, data).Result()
+		if err != nil {
+			return order, false, fmt.Errorf("remove pending order failed: %v", err)
+		}
+		return candidate, removed > 0, nil
+	}
+	return order, false, nil
+}
+
+// PendingOrderQuantities 统计待写库队列中各商品的购买数量，即Redis已扣减但数据库尚未扣减的库存
+func (r *RedisRepository) PendingOrderQuantities() (map[int64]int64, error) {
+	items, err := r.client.LRange(context.Background(), pendingOrdersKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list pending orders failed: %v", err)
+	}
+	quantities := make(map[int64]int64)
+	for _, data := range items {
+		var order model.PendingOrder
+		if err := json.Unmarshal([]byte(data), &order); err != nil {
+			return nil, fmt.Errorf("unmarshal pending order failed: %v", err)
+		}
+		quantities[order.GoodsId] += order.Quantity
+	}
+	return quantities, nil
+}
+
+// PendingOrderLen 获取待写库队列中的订单数量
+func (r *RedisRepository) PendingOrderLen() (int64, error) {
+	return r.client.LLen(context.Background(), pendingOrdersKey).Result()
+}
+
+// SubscribeStockChanges 订阅商品库存变更（由库存Lua脚本发布）
+// 返回前等待订阅确认；ctx取消后自动退订并关闭返回的通道
+func (r *RedisRepository) SubscribeStockChanges(ctx context.Context, goodsId int64) (<-chan model.StockUpdate, error) {
+	pubsub := r.client.Subscribe(ctx, StockChannel(goodsId))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("subscribe stock channel failed: %v", err)
+	}
+
+	updates := make(chan model.StockUpdate, 16)
+	go func() {
+		defer close(updates)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				update := model.StockUpdate{GoodsId: goodsId, Timestamp: time.Now()}
+				if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+					slog.Warn("Invalid stock update message",
+						"goods_id", goodsId,
+						"payload", msg.Payload,
+						"error", err,
+					)
+					continue
+				}
+				select {
+				case updates <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return updates, nil
+}
+
+// SetPromotionCache 缓存促销信息（包含版本号）
+func (r *RedisRepository) SetPromotionCache(promotion model.PromotionSecKill, ttl time.Duration) error {
+	jsonData, err := json.Marshal(promotion)
+	if err != nil {
+		return fmt.Errorf("marshal promotion cache failed: %v", err)
+	}
+	if err := r.client.Set(context.Background(), PromotionCacheKey(promotion.GoodsId), jsonData, ttl).Err(); err != nil {
+		return fmt.Errorf("store promotion cache failed: %v", err)
+	}
+	return nil
+}
+
+// GetPromotionCache 获取缓存的促销信息，缓存不存在时返回found=false
+func (r *RedisRepository) GetPromotionCache(goodsId int64) (promotion model.PromotionSecKill, found bool, err error) {
+	data, err := r.client.Get(context.Background(), PromotionCacheKey(goodsId)).Bytes()
+	if err == redis.Nil {
+		return promotion, false, nil
+	}
+	if err != nil {
+		return promotion, false, fmt.Errorf("get promotion cache failed: %v", err)
+	}
+	if err := json.Unmarshal(data, &promotion); err != nil {
+		return promotion, false, fmt.Errorf("unmarshal promotion cache failed: %v", err)
+	}
+	return promotion, true, nil
+}
+
+// DeletePromotionCache 删除促销信息缓存
+func (r *RedisRepository) DeletePromotionCache(goodsId int64) error {
+	if err := r.client.Del(context.Background(), PromotionCacheKey(goodsId)).Err(); err != nil {
+		return fmt.Errorf("delete promotion cache failed: %v", err)
+	}
+	return nil
+}
+
+// SetSeckillResult 缓存用户秒杀成功的订单ID
+func (r *RedisRepository) SetSeckillResult(userId, goodsId int64, orderId string, ttl time.Duration) error {
+	if err := r.client.Set(context.Background(), SeckillResultKey(userId, goodsId), orderId, ttl).Err(); err != nil {
+		return fmt.Errorf("store seckill result failed: %v", err)
+	}
+	return nil
+}
+
+// GetSeckillResult 获取缓存的用户秒杀订单ID，缓存不存在时返回found=false
+func (r *RedisRepository) GetSeckillResult(userId, goodsId int64) (orderId string, found bool, err error) {
+	orderId, err = r.client.Get(context.Background(), SeckillResultKey(userId, goodsId)).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("get seckill result failed: %v", err)
+	}
+	return orderId, true, nil
+}
+
+// ClaimSeckillIdempotencyKey 以处理中状态占用用户的秒杀幂等键
+// 占用成功返回claimed=true；幂等键已存在时返回已有记录
+func (r *RedisRepository) ClaimSeckillIdempotencyKey(userId int64, key string, goodsId int64, ttl time.Duration) (existing model.SeckillIdempotencyRecord, claimed bool, err error) {
+	ctx := context.Background()
+	redisKey := SeckillIdempotencyKey(userId, key)
+	pending, err := json.Marshal(model.SeckillIdempotencyRecord{State: model.IdempotencyStatePending, GoodsId: goodsId})
+	if err != nil {
+		return existing, false, fmt.Errorf("marshal idempotency record failed: %v", err)
+	}
+	claimed, err = r.client.SetNX(ctx, redisKey, pending, ttl).Result()
+	if err != nil {
+		return existing, false, fmt.Errorf("claim idempotency key failed: %v", err)
+	}
+	if claimed {
+		return existing, true, nil
+	}
+
+	data, err := r.client.Get(ctx, redisKey).Bytes()
+	if err == redis.Nil {
+		// 记录在两次操作之间过期，视为仍在处理中，由调用方稍后重试
+		return model.SeckillIdempotencyRecord{State: model.IdempotencyStatePending, GoodsId: goodsId}, false, nil
+	}
+	if err != nil {
+		return existing, false, fmt.Errorf("get idempotency record failed: %v", err)
+	}
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return existing, false, fmt.Errorf("unmarshal idempotency record failed: %v", err)
+	}
+	return existing, false, nil
+}
+
+// SetSeckillIdempotencyResult 写入幂等键对应的秒杀结果
+func (r *RedisRepository) SetSeckillIdempotencyResult(userId int64, key string, record model.SeckillIdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal idempotency record failed: %v", err)
+	}
+	if err := r.client.Set(context.Background(), SeckillIdempotencyKey(userId, key), data, ttl).Err(); err != nil {
+		return fmt.Errorf("store idempotency record failed: %v", err)
+	}
+	return nil
+}
+
+// DeleteSeckillIdempotencyKey 删除幂等记录，使使用同一幂等键的重试重新执行秒杀
+func (r *RedisRepository) DeleteSeckillIdempotencyKey(userId int64, key string) error {
+	if err := r.client.Del(context.Background(), SeckillIdempotencyKey(userId, key)).Err(); err != nil {
+		return fmt.Errorf("delete idempotency record failed: %v", err)
+	}
+	return nil
+}
+
+// SetAsyncSeckillResult 写入异步秒杀请求的处理结果，ttl后过期
+func (r *RedisRepository) SetAsyncSeckillResult(result model.AsyncSeckillResult, ttl time.Duration) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal async seckill result failed: %v", err)
+	}
+	if err := r.client.Set(context.Background(), AsyncSeckillResultKey(result.RequestId), data, ttl).Err(); err != nil {
+		return fmt.Errorf("store async seckill result failed: %v", err)
+	}
+	return nil
+}
+
+// GetAsyncSeckillResult 获取异步秒杀请求的处理结果，不存在或已过期时返回found=false
+func (r *RedisRepository) GetAsyncSeckillResult(requestId string) (result model.AsyncSeckillResult, found bool, err error) {
+	data, err := r.client.Get(context.Background(), AsyncSeckillResultKey(requestId)).Bytes()
+	if err == redis.Nil {
+		return result, false, nil
+	}
+	if err != nil {
+		return result, false, fmt.Errorf("get async seckill result failed: %v", err)
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, false, fmt.Errorf("unmarshal async seckill result failed: %v", err)
+	}
+	return result, true, nil
+}
+
+// SetGoodsInfoCache 缓存商品信息
+// 逻辑有效期为ttl，物理保留时间更长，以便数据库不可用时返回过期数据
+func (r *RedisRepository) SetGoodsInfoCache(good model.Goods, ttl time.Duration) error {
+	now := time.Now()
+	cached := model.CachedGoods{
+		Goods:    good,
+		CachedAt: now,
+		ExpireAt: now.Add(ttl),
+	}
+
+	jsonData, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("marshal goods info cache failed: %v", err)
+	}
+
+	key := GoodsInfoKey(good.GoodsId)
+	if err := r.client.Set(context.Background(), key, jsonData, goodsInfoCacheRetention).Err(); err != nil {
+		return fmt.Errorf("store goods info cache failed: %v", err)
+	}
+
+	slog.Info("Goods info cached in Redis",
+		"goods_id", good.GoodsId,
+		"expire_at", cached.ExpireAt,
+	)
+	return nil
+}
+
+// GetGoodsInfoCache 获取缓存的商品信息（包括逻辑上已过期的缓存）
+// 缓存不存在时返回found=false
+func (r *RedisRepository) GetGoodsInfoCache(goodsId int64) (cached model.CachedGoods, found bool, err error) {
+	key := GoodsInfoKey(goodsId)
+	data, err := r.client.Get(context.Background(), key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return cached, false, nil
+		}
+		return cached, false, fmt.Errorf("get goods info cache failed: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return cached, false, fmt.Errorf("unmarshal goods info cache failed: %v", err)
+	}
+	return cached, true, nil
+}
+
+// DefaultTokenLength 令牌默认长度
+const DefaultTokenLength = 32
+
+// tokenCharset 令牌字符集
+const tokenCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// ValidTokenFormat 校验令牌长度和字符集是否与签发规则一致
+func ValidTokenFormat(token string, length int) bool {
+	if len(token) != length {
+		return false
+	}
+	for i := 0; i < len(token); i++ {
+		if strings.IndexByte(tokenCharset, token[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // generateRandomString 生成指定长度的随机字符串
 // 用于生成令牌ID等随机标识
 func generateRandomString(length int) (string, error) {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	bytes := make([]byte, length)
 
 	if _, err := rand.Read(bytes); err != nil {
 		return "", fmt.Errorf("failed to generate random bytes: %v", err)
 	}
 	for i := range bytes {
-		bytes[i] = charset[bytes[i]%byte(len(charset))]
+		bytes[i] = tokenCharset[bytes[i]%byte(len(tokenCharset))]
 	}
 	return string(bytes), nil
 }
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// 事务重试相关常量
+const (
+	MaxTransactionAttempts    = 3                     // 死锁时事务最多执行次数（含首次）
+	transactionRetryBaseDelay = 10 * time.Millisecond // 首次重试前的等待时间，之后每次翻倍
+)
+
+// MaxReadAttempts 只读查询遇到瞬时错误时最多执行次数（含首次）
+const MaxReadAttempts = 3
+
+// MySQL可安全重试的事务错误码
+const (
+	mysqlErrDeadlock      = 1213    // ER_LOCK_DEADLOCK，事务已被MySQL整体回滚
+	sqlStateSerialization = "40001" // 序列化失败，标准SQLSTATE
+)
+
+// 唯一键冲突错误码
+const (
+	mysqlErrDupEntry           = 1062 // ER_DUP_ENTRY，主键或唯一索引重复
+	sqliteConstraintPrimaryKey = 1555 // SQLITE_CONSTRAINT_PRIMARYKEY
+	sqliteConstraintUnique     = 2067 // SQLITE_CONSTRAINT_UNIQUE
+)
+
+// MySQL只读查询的瞬时错误码
+const (
+	mysqlErrLockWaitTimeout = 1205 // ER_LOCK_WAIT_TIMEOUT，锁等待超时
+	mysqlErrQueryTimeout    = 3024 // ER_QUERY_TIMEOUT，超过max_execution_time
+)
+
+// IsRetryableTxError 判断事务错误是否为死锁或序列化失败
+// 这类错误发生时整个事务已回滚，重新执行事务是安全的；其余错误（包括锁等待超时）不重试
+func IsRetryableTxError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == mysqlErrDeadlock || string(mysqlErr.SQLState[:]) == sqlStateSerialization
+}
+
+// IsDuplicateKeyError 判断写入错误是否为主键或唯一索引冲突
+// 兼容MySQL错误码、开启TranslateError时GORM翻译后的错误以及测试使用的SQLite扩展错误码
+func IsDuplicateKeyError(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlErrDupEntry
+	}
+	var codeErr interface{ Code() int }
+	if errors.As(err, &codeErr) {
+		return codeErr.Code() == sqliteConstraintPrimaryKey || codeErr.Code() == sqliteConstraintUnique
+	}
+	return false
+}
+
+// IsTransientReadError 判断只读查询错误是否为瞬时错误
+// 包括单次查询超时、锁等待超时、死锁和序列化失败，表被锁（如在线变更表结构）时常见，只读查询重试是安全的
+func IsTransientReadError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || IsRetryableTxError(err) {
+		return true
+	}
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == mysqlErrLockWaitTimeout || mysqlErr.Number == mysqlErrQueryTimeout
+}
+
+// transactionRetryDelay 返回第attempt次失败后重试前的等待时间
+func transactionRetryDelay(attempt int) time.Duration {
+	return transactionRetryBaseDelay << (attempt - 1)
+}
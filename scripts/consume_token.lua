@@ -0,0 +1,24 @@
+-- 原子性地校验并消费秒杀令牌，并发请求同一令牌时只有一个能够成功
+-- KEYS[1]: 秒杀令牌key
+-- KEYS[2]: 预占记录key
+-- ARGV[1]: 请求用户ID
+-- ARGV[2]: 请求商品ID
+-- 返回: {1, 令牌数据, 是否仍持有预占库存}-校验通过且已删除, {0}-令牌不存在, {-1, 令牌数据}-用户或商品不匹配（令牌保留）
+local data = redis.call('GET', KEYS[1])
+if not data then
+    return {0}  -- 令牌不存在或已被消费
+end
+
+local token = cjson.decode(data)
+if tonumber(token.user_id) ~= tonumber(ARGV[1]) or tonumber(token.goods_id) ~= tonumber(ARGV[2]) then
+    return {-1, data}  -- 不匹配时不删除，避免他人使令牌失效
+end
+
+redis.call('DEL', KEYS[1])  -- 消费令牌
+
+-- 移除预占记录，预占已被归还（如库存重新写入）时按未预占处理
+local reserved = 0
+if token.reserved then
+    reserved = redis.call('ZREM', KEYS[2], token.token_id)
+end
+return {1, data, reserved}
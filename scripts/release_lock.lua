@@ -0,0 +1,8 @@
+-- 原子性地校验持有者令牌并释放分布式锁，避免误删其他请求重新获取的锁
+-- KEYS[1]: 锁key
+-- ARGV[1]: 加锁时生成的持有者令牌
+-- 返回: 1-已释放, 0-锁不存在或已被其他请求持有（未删除）
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('DEL', KEYS[1])
+end
+return 0
@@ -0,0 +1,10 @@
+-- 原子性地归还一次已占用的计数（令牌签发次数），保留计数key原有的过期时间
+-- key不存在（已过期）或计数已为0时不做任何操作，避免产生无过期时间的负数计数
+-- KEYS[1]: 计数key
+-- 返回: 1-已归还, 0-key不存在或计数已为0
+local current = tonumber(redis.call('GET', KEYS[1]))
+if current and current > 0 then
+    redis.call('DECR', KEYS[1])  -- DECR不改变key的过期时间
+    return 1
+end
+return 0
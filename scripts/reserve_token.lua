@@ -0,0 +1,32 @@
+-- 原子性地预占库存并签发秒杀令牌
+-- KEYS[1]: 库存key
+-- KEYS[2]: 秒杀令牌key
+-- KEYS[3]: 预占记录key（有序集合，成员为令牌ID，分值为令牌过期时间毫秒时间戳）
+-- ARGV[1]: 令牌数据(JSON)
+-- ARGV[2]: 令牌过期时间(秒)
+-- ARGV[3]: 库存变更发布订阅频道名
+-- ARGV[4]: 令牌ID
+-- ARGV[5]: 当前时间(毫秒时间戳)
+-- ARGV[6]: 令牌过期时间(毫秒时间戳)
+-- 返回: >=0-预占后剩余库存, -1-库存不存在, -2-库存不足
+local stock = redis.call('GET', KEYS[1])
+if not stock then
+    return -1  -- key不存在
+end
+
+-- 先归还已过期未兑换令牌预占的库存
+local released = redis.call('ZREMRANGEBYSCORE', KEYS[3], '-inf', ARGV[5])
+if released > 0 then
+    stock = redis.call('INCRBY', KEYS[1], released)
+    redis.call('PUBLISH', ARGV[3], cjson.encode({stock = stock, delta = released}))
+end
+
+if tonumber(stock) <= 0 then
+    return -2  -- 库存不足，不签发令牌
+end
+
+local remaining = redis.call('DECR', KEYS[1])
+redis.call('SET', KEYS[2], ARGV[1], 'EX', ARGV[2])  -- 签发令牌并设置过期时间
+redis.call('ZADD', KEYS[3], ARGV[6], ARGV[4])  -- 记录预占，令牌过期后归还库存
+redis.call('PUBLISH', ARGV[3], cjson.encode({stock = remaining, delta = -1}))  -- 发布库存变更
+return remaining
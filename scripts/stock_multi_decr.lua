@@ -0,0 +1,27 @@
+-- 原子性地按数量扣减购物车中多个商品的库存，全部充足时才扣减
+-- 先校验全部库存再统一扣减，任一商品不满足时不写入任何键，等同于整体回滚
+-- KEYS[1..n]: 商品库存key，须位于同一槽位
+-- ARGV[1..n]: 与KEYS一一对应的扣减数量
+-- ARGV[n+1..2n]: 与KEYS一一对应的库存变更频道（为空时不发布）
+-- 返回: {0, 0}-全部扣减成功, {-1, i}-第i个库存不存在, {-2, i}-第i个库存不足
+local n = #KEYS
+
+for i, key in ipairs(KEYS) do
+    local stock = redis.call('GET', key)
+    if not stock then
+        return {-1, i}  -- key不存在
+    end
+    if tonumber(stock) < tonumber(ARGV[i]) then
+        return {-2, i}  -- 库存不足
+    end
+end
+
+for i, key in ipairs(KEYS) do
+    local qty = tonumber(ARGV[i])
+    local new_stock = redis.call('DECRBY', key, qty)
+    local channel = ARGV[n + i]
+    if channel and channel ~= '' then
+        redis.call('PUBLISH', channel, cjson.encode({stock = new_stock, delta = -qty}))
+    end
+end
+return {0, 0}
@@ -1,6 +1,13 @@
 -- scripts/stock_operations.lua
+-- 发布库存变更消息（channel为空时不发布）
+local function publish_stock_change(channel, stock, delta)
+    if channel and channel ~= '' then
+        redis.call('publish', channel, cjson.encode({stock = stock, delta = delta}))
+    end
+end
+
 -- 原子性地检查并减少库存
-local function check_and_decr_stock(key)
+local function check_and_decr_stock(key, channel)
     local stock = redis.call('get', key)
     
     if not stock then
@@ -13,32 +20,133 @@ local function check_and_decr_stock(key)
     end
     
     local new_stock = redis.call('decr', key)
+    publish_stock_change(channel, new_stock, -1)
+    return new_stock
+end
+
+-- 原子性地检查并按数量减少库存，库存不足时不扣减
+local function check_and_decr_stock_by(key, qty, channel)
+    local stock = redis.call('get', key)
+
+    if not stock then
+        return -1  -- key不存在
+    end
+
+    stock = tonumber(stock)
+    if stock < qty then
+        return -2  -- 库存不足
+    end
+
+    local new_stock = redis.call('decrby', key, qty)
+    publish_stock_change(channel, new_stock, -qty)
     return new_stock
 end
 
 -- 原子性地检查并设置库存（如果库存不存在）
-local function check_and_set_stock(key, new_stock)
+local function check_and_set_stock(key, new_stock, channel)
     local existing = redis.call('exists', key)
     if existing == 0 then
         redis.call('set', key, new_stock)
+        publish_stock_change(channel, new_stock, new_stock)
         return 1  -- 设置成功
     else
         return 0  -- 库存已存在
     end
 end
 
+-- 以目标库存校准缓存库存：键不存在时写入目标值，缓存高于目标值时下调，低于目标值时保留
+-- 售卖过程中缓存偏低可能来自尚未落库的扣减，上调会导致超卖，因此只允许下调
+local function reconcile_stock(key, reservations_key, target, channel)
+    local stock = redis.call('get', key)
+    if not stock then
+        redis.call('set', key, target)
+        redis.call('del', reservations_key)  -- 数据库库存未扣除预占，重新写入后预占记录作废
+        publish_stock_change(channel, target, target)
+        return 1  -- 重新写入
+    end
+
+    stock = tonumber(stock)
+    if stock > target then
+        redis.call('set', key, target)
+        publish_stock_change(channel, target, target - stock)
+        return 2  -- 下调
+    end
+    return 0  -- 保留
+end
+
+-- 写入库存并递增库存代次，返回新代次
+-- 重新开始活动时调用，上一轮活动中携带旧代次的扣减请求随之失效
+local function set_stock_gen(key, gen_key, reservations_key, new_stock, channel)
+    local generation = redis.call('incr', gen_key)
+    redis.call('set', key, new_stock)
+    redis.call('del', reservations_key)  -- 上一轮的预占不再归还
+    publish_stock_change(channel, new_stock, new_stock)
+    return generation
+end
+
+-- 校验库存代次后按数量减少库存，代次不一致时不扣减
+local function check_and_decr_stock_gen(key, gen_key, qty, expected, channel)
+    local generation = tonumber(redis.call('get', gen_key) or '0')
+    if generation ~= expected then
+        return -3  -- 代次已过期
+    end
+    return check_and_decr_stock_by(key, qty, channel)
+end
+
+-- 归还预占的库存，库存键不存在（已淘汰或已重置）时不归还，由库存校准按数据库库存重新写入
+local function release_reserved_stock(key, count, channel)
+    if count > 0 and redis.call('exists', key) == 1 then
+        local new_stock = redis.call('incrby', key, count)
+        publish_stock_change(channel, new_stock, count)
+    end
+    return count
+end
+
+-- 归还已过期未兑换令牌预占的库存，返回归还的件数
+local function release_expired_reservations(key, reservations_key, now, channel)
+    local count = redis.call('zremrangebyscore', reservations_key, '-inf', now)
+    return release_reserved_stock(key, count, channel)
+end
+
+-- 删除令牌并归还其预占的库存，返回{令牌删除前是否存在, 归还件数}
+local function release_reservation(key, reservations_key, token_key, token_id, channel)
+    local deleted = redis.call('del', token_key)
+    local count = redis.call('zrem', reservations_key, token_id)
+    release_reserved_stock(key, count, channel)
+    return {deleted, count}
+end
+
 -- 主执行逻辑
+-- ARGV[1]: 命令名称，库存变更类命令的最后一个参数为发布订阅频道名
+-- KEYS[1]: 库存key，带代次的命令中KEYS[2]为库存代次key、KEYS[3]为预占记录key
+-- reconcile与预占相关命令中KEYS[2]为预占记录key，release_reservation的KEYS[3]为令牌key
 local command = ARGV[1]
 local key = KEYS[1]
 
 if command == 'check_and_decr' then
-    return check_and_decr_stock(key)
+    return check_and_decr_stock(key, ARGV[2])
+elseif command == 'check_and_decr_by' then
+    local qty = tonumber(ARGV[2])
+    return check_and_decr_stock_by(key, qty, ARGV[3])
 elseif command == 'check_and_set' then
     local new_stock = tonumber(ARGV[2])
-    return check_and_set_stock(key, new_stock)
+    return check_and_set_stock(key, new_stock, ARGV[3])
+elseif command == 'reconcile' then
+    local target = tonumber(ARGV[2])
+    return reconcile_stock(key, KEYS[2], target, ARGV[3])
+elseif command == 'set_stock_gen' then
+    local new_stock = tonumber(ARGV[2])
+    return set_stock_gen(key, KEYS[2], KEYS[3], new_stock, ARGV[3])
+elseif command == 'check_and_decr_gen' then
+    local qty = tonumber(ARGV[2])
+    local expected = tonumber(ARGV[3])
+    return check_and_decr_stock_gen(key, KEYS[2], qty, expected, ARGV[4])
+elseif command == 'release_expired_reservations' then
+    return release_expired_reservations(key, KEYS[2], ARGV[2], ARGV[3])
+elseif command == 'release_reservation' then
+    return release_reservation(key, KEYS[2], KEYS[3], ARGV[2], ARGV[3])
 elseif command == 'get_stock' then
-    local stock = redis.call('get', key)
-    return stock or 0
+    return redis.call('get', key)  -- key不存在时返回nil
 else
     return -99  -- 未知命令
 end
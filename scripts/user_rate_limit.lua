@@ -8,8 +8,8 @@ if current and tonumber(current) >= tonumber(ARGV[1]) then
     return 0  -- 超过限制
 else
     redis.call('INCR', KEYS[1])
-    if tonumber(redis.call('GET', KEYS[1])) == 1 then
-        redis.call('EXPIRE', KEYS[1], ARGV[2])  -- 第一次设置时设置过期时间
+    if redis.call('TTL', KEYS[1]) < 0 then
+        redis.call('EXPIRE', KEYS[1], ARGV[2])  -- 未设置过期时间时（首次计数）设置，计数归还到0后再次占用不重置过期时间
     end
     return 1  -- 未超过限制
 end
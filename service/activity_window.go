@@ -0,0 +1,57 @@
+package service
+
+import (
+	"seckill_system/errs"
+	"seckill_system/model"
+	"time"
+)
+
+// 秒杀活动时间窗口错误，均属于errs.ErrActivityNotAvailable类别
+var (
+	ErrSeckillNotStarted = errs.ErrSeckillNotStarted // 秒杀活动尚未开始
+	ErrSeckillEnded      = errs.ErrSeckillEnded      // 秒杀活动已结束
+)
+
+// ActivityWindowError 不在秒杀活动时间内的错误，附带活动起止时间供前端展示倒计时
+type ActivityWindowError struct {
+	Err       error           // ErrSeckillNotStarted或ErrSeckillEnded
+	StartTime model.Timestamp // 秒杀开始时间
+	EndTime   model.Timestamp // 秒杀结束时间
+}
+
+// Error 返回错误信息
+func (e *ActivityWindowError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap 返回具体的时间窗口错误，使errors.Is可以判断未开始或已结束
+func (e *ActivityWindowError) Unwrap() error {
+	return e.Err
+}
+
+// activityPhase 按now判断秒杀活动所处阶段，与checkActivityWindow的判断一致
+// 不使用数据库中的status字段，该字段不会随时间自动更新
+func activityPhase(promotion model.PromotionSecKill, now time.Time) string {
+	switch {
+	case now.Before(promotion.StartTime.Time):
+		return model.ActivityPhaseUpcoming
+	case now.After(promotion.EndTime.Time):
+		return model.ActivityPhaseEnded
+	default:
+		return model.ActivityPhaseActive
+	}
+}
+
+// checkActivityWindow 判断now是否在活动时间内，不在时返回ActivityWindowError
+func checkActivityWindow(promotion model.PromotionSecKill, now time.Time) error {
+	var err error
+	switch {
+	case now.Before(promotion.StartTime.Time):
+		err = ErrSeckillNotStarted
+	case now.After(promotion.EndTime.Time):
+		err = ErrSeckillEnded
+	default:
+		return nil
+	}
+	return &ActivityWindowError{Err: err, StartTime: promotion.StartTime, EndTime: promotion.EndTime}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"seckill_system/errs"
+	"seckill_system/model"
+	"seckill_system/web/metrics"
+	"time"
+)
+
+// SeckillRequestQueue 异步秒杀请求队列，由KafkaRepository实现
+type SeckillRequestQueue interface {
+	// SendSeckillRequest 发送异步秒杀请求，写入成功后返回
+	SendSeckillRequest(ctx context.Context, request *model.AsyncSeckillRequest) error
+}
+
+// asyncRequestIdBytes 异步秒杀请求ID的随机字节数，请求ID为其十六进制表示
+const asyncRequestIdBytes = 16
+
+// asyncEnqueueTimeout 异步秒杀请求写入队列的最长等待时间
+const asyncEnqueueTimeout = 3 * time.Second
+
+// SeckillAsync 校验并消费秒杀令牌后将下单请求放入队列，立即返回请求ID
+// 下单由后台消费者完成，客户端通过GetAsyncSeckillResult轮询结果；
+// 受理阶段被拒绝的请求与同步秒杀一样计入结果统计和审计，受理成功的请求在下单完成后记录
+func (gs *GoodService) SeckillAsync(userId, goodsId int64, tokenId, clientIP string) (string, error) {
+	requestId, goodsVerified, err := gs.acceptSeckillAsync(userId, goodsId, tokenId, clientIP)
+	if err != nil {
+		gs.outcomes.Record(model.AuditActionSeckill, err)
+		metrics.ObserveSeckill(metricsGoods(goodsId, goodsVerified), err)
+		gs.addSeckillAuditLog(userId, goodsId, tokenId, err)
+		gs.RecordAuditEvent(model.AuditActionSeckill, userId, goodsId, clientIP, err)
+	}
+	return requestId, err
+}
+
+// acceptSeckillAsync 受理异步秒杀请求：校验令牌、写入处理中结果并放入队列
+// goodsVerified表示令牌已通过校验或商品已确认售罄，含义同seckillWithToken
+func (gs *GoodService) acceptSeckillAsync(userId, goodsId int64, tokenId, clientIP string) (string, bool, error) {
+	if gs.AsyncQueue == nil {
+		return "", false, errs.ErrAsyncSeckillDisabled
+	}
+	if err := gs.recheckBlacklist(userId, goodsId); err != nil {
+		return "", false, err
+	}
+	stockReserved, err := gs.admitSeckillToken(userId, goodsId, tokenId)
+	if err != nil {
+		return "", errors.Is(err, errs.ErrSoldOut), err
+	}
+	// 令牌已消费，请求未能进入队列时归还令牌预占的库存
+	enqueued := false
+	if stockReserved {
+		defer func() {
+			if !enqueued {
+				gs.releaseReservedStock(goodsId)
+			}
+		}()
+	}
+
+	requestId, err := newAsyncRequestId()
+	if err != nil {
+		return "", true, err
+	}
+	now := time.Now()
+	result := model.AsyncSeckillResult{
+		RequestId:  requestId,
+		UserId:     userId,
+		GoodsId:    goodsId,
+		Status:     model.AsyncSeckillPending,
+		AcceptedAt: model.NewTimestamp(now),
+		UpdatedAt:  model.NewTimestamp(now),
+	}
+
+	// 先写入处理中结果再放入队列，避免消费者写入的最终结果被覆盖
+	ttl := gs.Seckill.AsyncResultTTL()
+	if err := gs.RedisRepo.SetAsyncSeckillResult(result, ttl); err != nil {
+		slog.Error("Failed to store pending async seckill result",
+			"request_id", requestId,
+			"user_id", userId,
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return "", true, fmt.Errorf("%w: store async seckill result failed: %v", errs.ErrSystemBusy, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), asyncEnqueueTimeout)
+	defer cancel()
+	request := &model.AsyncSeckillRequest{
+		RequestId:     requestId,
+		UserId:        userId,
+		GoodsId:       goodsId,
+		TokenPrefix:   model.TokenPrefix(tokenId),
+		ClientIP:      clientIP,
+		AcceptedAt:    now,
+		StockReserved: stockReserved,
+	}
+	if err := gs.AsyncQueue.SendSeckillRequest(ctx, request); err != nil {
+		err = fmt.Errorf("%w: enqueue seckill request failed: %v", errs.ErrSystemBusy, err)
+		slog.Error("Failed to enqueue async seckill request",
+			"request_id", requestId,
+			"user_id", userId,
+			"goods_id", goodsId,
+			"error", err,
+		)
+		gs.completeAsyncSeckill(result, "", err)
+		return "", true, err
+	}
+	enqueued = true
+
+	slog.Info("Async seckill request accepted",
+		"request_id", requestId,
+		"user_id", userId,
+		"goods_id", goodsId,
+		"token_id_prefix", request.TokenPrefix,
+	)
+	return requestId, true, nil
+}
+
+// HandleSeckillRequest 处理队列中的异步秒杀请求，下单并写入处理结果
+// 已有最终结果的请求（消息重复投递）直接跳过，不会重复下单
+func (gs *GoodService) HandleSeckillRequest(request model.AsyncSeckillRequest) error {
+	gs.inflight.Add(1)
+	defer gs.inflight.Done()
+
+	result, found, err := gs.RedisRepo.GetAsyncSeckillResult(request.RequestId)
+	if err != nil {
+		slog.Warn("Failed to read async seckill result, processing request anyway",
+			"request_id", request.RequestId,
+			"error", err,
+		)
+	} else if found && result.Status != model.AsyncSeckillPending {
+		slog.Info("Async seckill request already processed, skipping",
+			"request_id", request.RequestId,
+			"status", result.Status,
+		)
+		return nil
+	}
+	if !found {
+		// 结果已过期或读取失败时按消息内容重建
+		result = model.AsyncSeckillResult{
+			RequestId:  request.RequestId,
+			UserId:     request.UserId,
+			GoodsId:    request.GoodsId,
+			AcceptedAt: model.NewTimestamp(request.AcceptedAt),
+		}
+	}
+
+	orderId, err := gs.placeSeckillOrder(request.UserId, request.GoodsId, request.TokenPrefix, request.StockReserved)
+	gs.outcomes.Record(model.AuditActionSeckill, err)
+	metrics.ObserveSeckill(request.GoodsId, err)
+	gs.addSeckillAuditLog(request.UserId, request.GoodsId, request.TokenPrefix, err)
+	gs.RecordAuditEvent(model.AuditActionSeckill, request.UserId, request.GoodsId, request.ClientIP, err)
+	gs.completeAsyncSeckill(result, orderId, err)
+	return nil
+}
+
+// completeAsyncSeckill 写入异步秒杀请求的最终结果，写入失败只记录日志，客户端查询到的结果停留在处理中直到过期
+func (gs *GoodService) completeAsyncSeckill(result model.AsyncSeckillResult, orderId string, err error) {
+	result.Status = model.AsyncSeckillSuccess
+	result.OrderId = orderId
+	if err != nil {
+		result.Status = model.AsyncSeckillFailed
+		result.ErrorCode = errs.Code(err)
+		result.ErrorMessage = err.Error()
+	}
+	result.UpdatedAt = model.NewTimestamp(time.Now())
+	if setErr := gs.RedisRepo.SetAsyncSeckillResult(result, gs.Seckill.AsyncResultTTL()); setErr != nil {
+		slog.Error("Failed to store async seckill result",
+			"request_id", result.RequestId,
+			"user_id", result.UserId,
+			"goods_id", result.GoodsId,
+			"status", result.Status,
+			"error", setErr,
+		)
+	}
+}
+
+// GetAsyncSeckillResult 查询用户异步秒杀请求的处理结果
+// 请求不存在、结果已过期或请求不属于该用户时都返回ErrSeckillRequestNotFound，不暴露其他用户的请求
+func (gs *GoodService) GetAsyncSeckillResult(userId int64, requestId string) (model.AsyncSeckillResult, error) {
+	if len(requestId) != 2*asyncRequestIdBytes {
+		return model.AsyncSeckillResult{}, errs.ErrSeckillRequestNotFound
+	}
+	if _, err := hex.DecodeString(requestId); err != nil {
+		return model.AsyncSeckillResult{}, errs.ErrSeckillRequestNotFound
+	}
+
+	result, found, err := gs.RedisRepo.GetAsyncSeckillResult(requestId)
+	if err != nil {
+		slog.Error("Failed to get async seckill result",
+			"request_id", requestId,
+			"user_id", userId,
+			"error", err,
+		)
+		return result, err
+	}
+	if !found || result.UserId != userId {
+		return model.AsyncSeckillResult{}, errs.ErrSeckillRequestNotFound
+	}
+	return result, nil
+}
+
+// StartSeckillRequestConsumer 启动异步秒杀请求消费者，服务生命周期上下文取消时退出
+func (gs *GoodService) StartSeckillRequestConsumer() {
+	ctx := gs.lifecycleContext()
+	gs.consumers.Add(1)
+	go func() {
+		defer gs.consumers.Done()
+		slog.Info("Starting async seckill request consumer...")
+		err := gs.KafkaRepo.ConsumeSeckillRequests(ctx, gs.HandleSeckillRequest)
+		if errors.Is(err, context.Canceled) {
+			slog.Info("Async seckill request consumer stopped")
+			return
+		}
+		if err != nil {
+			slog.Error("Async seckill request consumer failed",
+				"error", err,
+			)
+		}
+	}()
+}
+
+// newAsyncRequestId 生成随机的异步秒杀请求ID
+func newAsyncRequestId() (string, error) {
+	buf := make([]byte, asyncRequestIdBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate async seckill request id failed: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -5,12 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"seckill_system/config"
+	"seckill_system/errs"
 	"seckill_system/global"
 	"seckill_system/handler"
 	"seckill_system/model"
 	"seckill_system/repository"
+	"seckill_system/web/metrics"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // 单例模式相关变量
@@ -19,6 +25,12 @@ var (
 	goodServiceOnce     sync.Once
 )
 
+// AuditPublisher 秒杀审计事件发布接口
+type AuditPublisher interface {
+	// SendAuditEvent 发送审计事件
+	SendAuditEvent(ctx context.Context, event *model.AuditEvent) error
+}
+
 // GoodService 秒杀商品服务，封装核心业务逻辑
 type GoodService struct {
 	GoodDB         *repository.GoodRepository  // 商品数据库操作
@@ -26,6 +38,26 @@ type GoodService struct {
 	KafkaRepo      *repository.KafkaRepository // Kafka消息队列操作
 	EtcdRepo       *repository.ETCDRepository  // ETCD配置中心操作
 	SeckillHandler *handler.SeckillHandler     // 秒杀处理器
+	Auditor        AuditPublisher              // 审计事件发布者，为nil时不发送审计事件
+	Allowlist      *RateLimitAllowlist         // 限流豁免名单，为nil时所有请求均受限流约束
+	ApprovedGoods  *GoodsAllowlist             // 秒杀商品准入名单，为nil时不限制商品
+	Locks          *LockFactory                // 分布式锁工厂，为nil时全部使用Etcd锁
+	PaymentGrace   time.Duration               // 支付失败后等待重试的宽限期，0表示立即取消订单
+	Seckill        config.SeckillConfig        // 秒杀下单策略，零值时全部商品使用db模式
+	Promotions     *repository.PromotionCache  // 促销信息缓存，为nil时直接读取数据库
+	RateLimitOpen  bool                        // Redis限流失败时是否放行请求，默认拒绝
+	AsyncQueue     SeckillRequestQueue         // 异步秒杀请求队列，为nil时不支持异步秒杀
+
+	ctx               context.Context    // 服务生命周期上下文，Shutdown时取消以停止Kafka消费循环
+	cancel            context.CancelFunc // 取消生命周期上下文
+	lifecycle         sync.Once          // 未通过NewGoodService创建时懒初始化生命周期上下文
+	consumers         sync.WaitGroup     // 运行中的Kafka消费循环
+	inflight          sync.WaitGroup     // 处理中的Kafka消息
+	ordersProcessed   atomic.Int64       // 已处理的订单消息数
+	paymentsProcessed atomic.Int64       // 已处理的支付消息数
+	outcomes          OutcomeCounter     // 获取令牌和秒杀请求的结果分类统计
+	soldOut           SoldOutCache       // 已确认售罄的商品和售罄拒绝日志采样
+	readOnly          atomic.Bool        // 是否处于只读模式，由Etcd开关驱动
 }
 
 // NewGoodService 创建商品服务实例
@@ -36,18 +68,62 @@ func NewGoodService() *GoodService {
 		KafkaRepo:      repository.NewKafkaRepository(),
 		EtcdRepo:       repository.NewETCDRepository(),
 		SeckillHandler: handler.NewSeckillHandler(),
+		Allowlist:      NewRateLimitAllowlist(config.AppConfig.RateLimit.Allowlist),
+		ApprovedGoods:  NewGoodsAllowlist(config.AppConfig.Seckill.GoodsAllowlist),
+	}
+	locks, err := NewLockFactory(config.AppConfig.Lock, service.EtcdRepo, service.RedisRepo)
+	if err != nil {
+		slog.Error("Invalid lock config, falling back to etcd locks",
+			"error", err,
+		)
+	}
+	service.Locks = locks
+	service.PaymentGrace = config.AppConfig.Payment.FailureGrace()
+	service.Seckill = config.AppConfig.Seckill
+	service.Promotions = repository.NewPromotionCache(service.GoodDB, service.RedisRepo)
+	service.RateLimitOpen = config.AppConfig.RateLimit.FailOpen()
+	service.ctx, service.cancel = context.WithCancel(context.Background())
+
+	if service.KafkaRepo.AuditEnabled() {
+		service.Auditor = service.KafkaRepo // 开启审计时通过Kafka发送审计事件
 	}
 
-	service.StartOrderConsumer()   // 启动订单消息消费者
-	service.StartPaymentConsumer() // 启动支付消息消费者
-	service.StartConfigWatcher()   // 启动配置变更监听
+	if service.KafkaRepo.SeckillRequestsEnabled() {
+		service.AsyncQueue = service.KafkaRepo // 配置异步秒杀主题时通过Kafka排队下单
+		service.StartSeckillRequestConsumer()  // 启动异步秒杀请求消费者
+	}
+
+	service.StartOrderConsumer()         // 启动订单消息消费者
+	service.StartPaymentConsumer()       // 启动支付消息消费者
+	service.StartConfigWatcher()         // 启动配置变更监听
+	service.StartAllowlistWatcher()      // 加载并监听限流豁免名单
+	service.StartGoodsAllowlistWatcher() // 加载并监听秒杀商品准入名单
+	service.StartReadOnlyWatcher()       // 加载并监听只读模式开关
+	service.StartPaymentRetrySweeper()   // 启动支付失败宽限期到期扫描
+	service.StartPendingOrderFlusher()   // 启动Redis-only模式订单写库
+	service.StartStockRefresher()        // 启动Redis库存定期校准
+	service.StartLifecycleWatcher()      // 启动活动结束事件扫描
 
 	slog.Info("GoodService initialized successfully")
 	return service
 }
 
+// lifecycleContext 返回服务生命周期上下文，未通过NewGoodService创建时懒初始化
+func (gs *GoodService) lifecycleContext() context.Context {
+	gs.lifecycle.Do(func() {
+		if gs.ctx == nil {
+			gs.ctx, gs.cancel = context.WithCancel(context.Background())
+		}
+	})
+	return gs.ctx
+}
+
 // GetGoodService 获取商品服务单例
+// 全局客户端未初始化时panic，检查在sync.Once之前执行，不会留下未完成初始化的单例
 func GetGoodService() *GoodService {
+	if err := global.CheckInitialized(); err != nil {
+		panic(err)
+	}
 	goodServiceOnce.Do(func() {
 		goodServiceInstance = NewGoodService()
 	})
@@ -91,28 +167,48 @@ func (gs *GoodService) VerifyUserToken(token string) (int64, error) {
 }
 
 // GenerateSeckillToken 生成秒杀令牌(包含多重校验)
-func (gs *GoodService) GenerateSeckillToken(userId, goodsId int64) (string, error) {
+// clientIP为请求方IP，用于限流豁免名单判断
+func (gs *GoodService) GenerateSeckillToken(userId, goodsId int64, clientIP string) (string, error) {
+	start := time.Now()
+	tokenId, goodsVerified, err := gs.generateSeckillToken(userId, goodsId, clientIP)
+	metrics.ObserveTokenGeneration(metricsGoods(goodsId, goodsVerified), start)
+	gs.outcomes.Record(model.AuditActionSeckillToken, err)
+	return tokenId, err
+}
+
+// metricsGoods 返回指标使用的商品ID，商品未经校验时返回metrics.UnknownGoods
+func metricsGoods(goodsId int64, goodsVerified bool) int64 {
+	if !goodsVerified {
+		return metrics.UnknownGoods
+	}
+	return goodsId
+}
+
+// generateSeckillToken 依次校验秒杀开关、黑名单、商品、活动时间、库存和限流后签发秒杀令牌
+// goodsVerified表示商品和活动已查询到（或已确认售罄），只有此时才按商品ID记录指标
+func (gs *GoodService) generateSeckillToken(userId, goodsId int64, clientIP string) (tokenId string, goodsVerified bool, err error) {
 	// 用户级锁，防止同一用户重复获取令牌
-	userLockKey := fmt.Sprintf("user_token_lock_%d_%d", userId, goodsId)
+	userLockKey := gs.lockKey(fmt.Sprintf("user_token_lock_%d_%d", userId, goodsId))
 
 	// 使用带超时的context
 	lockCtx, lockCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer lockCancel()
 
-	locked, err := gs.EtcdRepo.GetDistributedLock(lockCtx, userLockKey, 10)
+	locker := gs.locker(config.LockCategorySeckill)
+	lockToken, locked, err := locker.GetDistributedLock(lockCtx, userLockKey, 10)
 	if err != nil || !locked {
 		slog.Warn("Failed to acquire user token lock",
 			"user_id", userId,
 			"goods_id", goodsId,
 			"error", err,
 		)
-		return "", fmt.Errorf("please don't repeat request: %v", err)
+		return "", false, fmt.Errorf("%w: please don't repeat request", errs.ErrSystemBusy)
 	}
 	defer func() {
 		// 使用新的context释放锁，避免使用已取消的context
 		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 3*time.Second)
 		defer releaseCancel()
-		if releaseErr := gs.EtcdRepo.ReleaseDistributedLock(releaseCtx, userLockKey); releaseErr != nil {
+		if releaseErr := locker.ReleaseDistributedLock(releaseCtx, userLockKey, lockToken); releaseErr != nil {
 			slog.Warn("Failed to release user token lock",
 				"user_id", userId,
 				"goods_id", goodsId,
@@ -127,14 +223,14 @@ func (gs *GoodService) GenerateSeckillToken(userId, goodsId int64) (string, erro
 		slog.Error("Failed to check seckill enabled status",
 			"error", err,
 		)
-		return "", fmt.Errorf("check seckill enabled failed: %v", err)
+		return "", false, fmt.Errorf("check seckill enabled failed: %v", err)
 	}
 	if !enabled {
 		slog.Warn("Seckill system is disabled",
 			"user_id", userId,
 			"goods_id", goodsId,
 		)
-		return "", errors.New("seckill system is temporarily disabled")
+		return "", false, errs.ErrSeckillDisabled
 	}
 
 	// 检查用户是否在黑名单
@@ -144,14 +240,29 @@ func (gs *GoodService) GenerateSeckillToken(userId, goodsId int64) (string, erro
 			"user_id", userId,
 			"error", err,
 		)
-		return "", fmt.Errorf("check blacklist failed: %v", err)
+		return "", false, fmt.Errorf("check blacklist failed: %v", err)
 	}
 	if inBlacklist {
 		slog.Warn("User in blacklist attempted to get seckill token",
 			"user_id", userId,
 			"goods_id", goodsId,
 		)
-		return "", errors.New("user is in blacklist")
+		return "", false, errs.ErrBlacklisted
+	}
+
+	// 检查商品是否在秒杀准入名单中
+	if gs.ApprovedGoods != nil && !gs.ApprovedGoods.Allows(goodsId) {
+		slog.Warn("Goods not approved for seckill",
+			"user_id", userId,
+			"goods_id", goodsId,
+		)
+		return "", false, errs.ErrGoodsNotApproved
+	}
+
+	// 已确认售罄的商品直接拒绝，不再查询商品、促销和库存
+	if gs.soldOutCached(goodsId) {
+		gs.logSoldOut("Goods sold out, seckill token refused from cache", goodsId)
+		return "", true, errs.ErrSoldOut // 售罄标记只在确认商品库存后设置
 	}
 
 	// 检查商品是否存在
@@ -161,7 +272,7 @@ func (gs *GoodService) GenerateSeckillToken(userId, goodsId int64) (string, erro
 			"goods_id", goodsId,
 			"error", err,
 		)
-		return "", fmt.Errorf("find goods failed: %v", err)
+		return "", false, fmt.Errorf("find goods failed: %w", err)
 	}
 
 	// 检查秒杀活动时间
@@ -171,7 +282,7 @@ func (gs *GoodService) GenerateSeckillToken(userId, goodsId int64) (string, erro
 			"goods_id", goodsId,
 			"error", err,
 		)
-		return "", fmt.Errorf("find promotion failed: %v", err)
+		return "", false, fmt.Errorf("find promotion failed: %w", err)
 	}
 
 	now := time.Now()
@@ -180,369 +291,1720 @@ func (gs *GoodService) GenerateSeckillToken(userId, goodsId int64) (string, erro
 		"now", now,
 		"start_time", promotion.StartTime,
 		"end_time", promotion.EndTime,
-		"before_start", now.Before(promotion.StartTime),
-		"after_end", now.After(promotion.EndTime),
+		"before_start", now.Before(promotion.StartTime.Time),
+		"after_end", now.After(promotion.EndTime.Time),
 	)
 
-	if now.Before(promotion.StartTime) || now.After(promotion.EndTime) {
+	if err := checkActivityWindow(promotion, now); err != nil {
 		slog.Warn("Seckill activity not available at current time",
 			"goods_id", goodsId,
 			"now", now,
 			"start_time", promotion.StartTime,
 			"end_time", promotion.EndTime,
+			"error", err,
 		)
-		return "", errors.New("seckill activity is not available")
+		return "", true, err
+	}
+
+	// 促销库存为0（管理员清零或数据库库存已售完）时直接视为售罄，不再读取Redis库存
+	if promotion.PsCount <= 0 {
+		gs.logSoldOut("Promotion has no stock, seckill token refused", goodsId,
+			"ps_count", promotion.PsCount,
+		)
+		return "", true, errs.ErrSoldOut
 	}
 
 	// 检查库存
 	stock, err := gs.SeckillHandler.CheckStock(context.Background(), goodsId)
-	if err != nil || stock <= 0 {
+	if err != nil {
 		slog.Warn("Insufficient stock for seckill token",
 			"goods_id", goodsId,
 			"stock", stock,
 			"error", err,
 		)
-		return "", errors.New("goods sold out")
+		return "", true, errs.ErrSoldOut
 	}
-
-	// 限流检查
-	rateLimit, err := gs.EtcdRepo.GetRateLimitConfig(context.Background())
-	if err != nil {
-		rateLimit = 10 // 默认限流值
-		slog.Warn("Failed to get rate limit config, using default",
-			"default_limit", rateLimit,
-			"error", err,
+	if stock <= 0 {
+		// 售罄是正常业务结果，标记后后续请求直接拒绝，日志按采样记录
+		gs.markSoldOut(goodsId)
+		gs.logSoldOut("Insufficient stock for seckill token", goodsId,
+			"stock", stock,
 		)
+		return "", true, errs.ErrSoldOut
 	}
 
-	allowed, err := gs.RedisRepo.UserRateLimit(userId, rateLimit, time.Minute)
-	if err != nil {
-		slog.Error("Rate limit check failed",
-			"user_id", userId,
-			"error", err,
-		)
-		return "", fmt.Errorf("check user rate limit failed: %v", err)
+	// 限流检查
+	if err := gs.CheckUserRateLimit(userId, clientIP); err != nil {
+		return "", true, err
 	}
-	if !allowed {
-		slog.Warn("User rate limit exceeded",
-			"user_id", userId,
-			"limit", rateLimit,
-		)
-		return "", errors.New("too many requests")
+
+	// 活动内令牌签发次数检查，令牌过期后重新获取同样计数
+	if err := gs.reserveTokenQuota(userId, goodsId, promotion.EndTime.Time); err != nil {
+		return "", true, err
 	}
 
-	// 生成秒杀令牌
-	tokenId, err := gs.RedisRepo.GenerateSeckillToken(userId, goodsId)
+	// 生成秒杀令牌，开启库存预占时签发与扣减一件Redis库存原子完成
+	if gs.Seckill.ReserveStock {
+		var remaining int64
+		tokenId, remaining, err = gs.RedisRepo.ReserveAndIssueToken(userId, goodsId, gs.RedisRepo.SeckillTokenTTL())
+		if err == nil {
+			// 预占即扣减Redis库存，兑换预占令牌下单时不再扣减，首单和售罄事件在此触发
+			gs.SeckillHandler.EmitStockTaken(goodsId, remaining)
+		}
+	} else {
+		tokenId, err = gs.RedisRepo.GenerateSeckillToken(userId, goodsId)
+	}
+	if errors.Is(err, repository.ErrGoodsSoldOut) {
+		// 库存已全部被令牌预占
+		gs.releaseTokenQuota(userId, goodsId)
+		gs.markSoldOut(goodsId)
+		gs.logSoldOut("Stock fully reserved, seckill token refused", goodsId)
+		gs.emitLifecycleEvent(model.LifecycleSoldOut, goodsId, 0)
+		return "", true, errs.ErrSoldOut
+	}
 	if err != nil {
 		slog.Error("Failed to generate seckill token",
 			"user_id", userId,
 			"goods_id", goodsId,
 			"error", err,
 		)
-		return "", err
+		gs.releaseTokenQuota(userId, goodsId)
+		return "", true, err
 	}
 
+	metrics.ObserveTokenIssued(goodsId)
+
 	slog.Info("Seckill token generated successfully",
 		"user_id", userId,
 		"goods_id", goodsId,
-		"token_id_prefix", tokenId[:8],
+		"token_id_prefix", model.TokenPrefix(tokenId),
 	)
-	return tokenId, nil
-}
-
-// StartConfigWatcher 启动ETCD配置监听
-func (gs *GoodService) StartConfigWatcher() {
-	go func() {
-		slog.Info("Starting etcd config watcher...")
-		// 监听秒杀配置变更
-		gs.EtcdRepo.WatchSeckillConfig(context.Background(), func(key, value string) {
-			slog.Info("ETCD config changed",
-				"key", key,
-				"value", value,
-			)
-
-			// 根据不同的配置键处理变更
-			switch key {
-			case global.EtcdKeySeckillEnabled:
-				if value == "false" {
-					slog.Warn("Seckill system has been disabled via etcd config")
-				} else {
-					slog.Info("Seckill system has been enabled via etcd config")
-				}
-			case global.EtcdKeyRateLimit:
-				slog.Info("Rate limit config changed", "new_value", value)
-			case global.EtcdKeyStockPreload:
-				slog.Info("Stock preload config changed", "new_value", value)
-			}
-		})
-	}()
+	return tokenId, true, nil
 }
 
-// SetSeckillEnabled 设置秒杀开关状态
-func (gs *GoodService) SetSeckillEnabled(enabled bool) error {
-	err := gs.EtcdRepo.SetSeckillEnabled(context.Background(), enabled)
+// GetSeckillTokenStats 获取商品秒杀令牌的签发数、兑换数及两者之比
+func (gs *GoodService) GetSeckillTokenStats(goodsId int64) (model.SeckillTokenStats, error) {
+	stats, err := gs.RedisRepo.GetSeckillTokenStats(goodsId)
 	if err != nil {
-		slog.Error("Failed to set seckill enabled",
-			"enabled", enabled,
+		slog.Error("Failed to get seckill token stats",
+			"goods_id", goodsId,
 			"error", err,
 		)
-		return err
+		return stats, err
 	}
+	return stats, nil
+}
 
-	slog.Info("Seckill enabled status updated",
-		"enabled", enabled,
-	)
-	return nil
+// HasUserOrder 查询用户是否已秒杀成功指定商品
+func (gs *GoodService) HasUserOrder(userId, goodsId int64) (bool, error) {
+	return gs.GoodDB.HasUserOrder(userId, goodsId)
 }
 
-// SetRateLimit 设置限流值
-func (gs *GoodService) SetRateLimit(limit int64) error {
-	err := gs.EtcdRepo.SetRateLimitConfig(context.Background(), limit)
+// GetOrderStatus 根据订单ID查询秒杀订单状态，订单不存在时返回ErrOrderNotFound
+// Redis-only模式下订单写库前查询不到
+func (gs *GoodService) GetOrderStatus(orderId string) (model.OrderStatus, error) {
+	userId, goodsId, err := handler.ParseOrderId(orderId)
 	if err != nil {
-		slog.Error("Failed to set rate limit",
-			"limit", limit,
-			"error", err,
-		)
-		return err
+		return model.OrderStatus{}, err
+	}
+	order, err := gs.GoodDB.FindSuccessKilledByOrderId(orderId, userId, goodsId)
+	if err != nil {
+		return model.OrderStatus{}, err
 	}
 
-	slog.Info("Rate limit updated",
-		"limit", limit,
-	)
-	return nil
+	// 未记录下单时间的历史订单使用订单ID中的时间戳
+	createTime := order.CreateTime.Time
+	if createTime.IsZero() {
+		createTime, _ = handler.ParseOrderTime(orderId)
+	}
+	return model.OrderStatus{
+		OrderId:    orderId,
+		UserId:     order.UserId,
+		GoodsId:    order.GoodsId,
+		State:      order.State,
+		Status:     model.OrderStateName(order.State),
+		CreateTime: model.NewTimestamp(createTime.In(config.TimeLocation())),
+	}, nil
 }
 
-// AddToBlacklist 添加用户到黑名单
-func (gs *GoodService) AddToBlacklist(userId int64, reason string, duration time.Duration) error {
-	err := gs.EtcdRepo.AddToBlacklist(context.Background(), userId, reason, duration)
+// CancelUnpaidOrders 取消用户所有未支付的订单并归还库存，返回本次取消的订单数
+// 每个订单在独立事务中取消，已取消或已支付的订单不受影响，重复调用是幂等的
+func (gs *GoodService) CancelUnpaidOrders(userId int64) (cancelled int, err error) {
+	orders, err := gs.GoodDB.ListUnpaidOrdersByUserId(userId)
 	if err != nil {
-		slog.Error("Failed to add user to blacklist",
-			"user_id", userId,
-			"reason", reason,
-			"duration", duration,
-			"error", err,
-		)
-		return err
+		return 0, fmt.Errorf("list unpaid orders failed: %w", err)
 	}
 
-	slog.Info("User added to blacklist",
+	for _, order := range orders {
+		ok, err := gs.cancelOrder(order.OrderId, userId, order.GoodsId)
+		if errors.Is(err, errs.ErrInvalidOrderTransition) {
+			continue // 订单已被并发支付
+		}
+		if err != nil {
+			slog.Error("Failed to cancel unpaid order",
+				"user_id", userId,
+				"goods_id", order.GoodsId,
+				"cancelled", cancelled,
+				"error", err,
+			)
+			return cancelled, err
+		}
+		if ok {
+			cancelled++
+		}
+	}
+
+	slog.Info("Unpaid orders cancelled",
 		"user_id", userId,
-		"reason", reason,
-		"duration", duration,
+		"cancelled", cancelled,
 	)
-	return nil
+	return cancelled, nil
 }
 
-// RemoveFromBlacklist 从黑名单移除用户
-func (gs *GoodService) RemoveFromBlacklist(userId int64) error {
-	err := gs.EtcdRepo.RemoveFromBlacklist(context.Background(), userId)
-	if err != nil {
-		slog.Error("Failed to remove user from blacklist",
+// CheckUserRateLimit 用户限流检查，豁免名单中的用户或IP直接放行且不计入限流次数
+func (gs *GoodService) CheckUserRateLimit(userId int64, clientIP string) error {
+	if gs.Allowlist != nil && gs.Allowlist.Allows(userId, clientIP) {
+		slog.Debug("Rate limit skipped for allowlisted caller",
 			"user_id", userId,
-			"error", err,
+			"client_ip", clientIP,
 		)
-		return err
+		return nil
 	}
 
-	slog.Info("User removed from blacklist",
-		"user_id", userId,
-	)
-	return nil
-}
-
-// GetBlacklist 获取黑名单列表
-func (gs *GoodService) GetBlacklist() ([]map[string]any, error) {
-	blacklist, err := gs.EtcdRepo.GetBlacklist(context.Background())
+	rateLimit, err := gs.EtcdRepo.GetRateLimitConfig(context.Background())
 	if err != nil {
-		slog.Error("Failed to get blacklist",
+		rateLimit = 10 // 默认限流值
+		slog.Warn("Failed to get rate limit config, using default",
+			"default_limit", rateLimit,
 			"error", err,
 		)
-		return nil, err
 	}
 
-	slog.Info("Blacklist retrieved",
-		"count", len(blacklist),
-	)
-	return blacklist, nil
-}
-
-// VerifySeckillToken 验证秒杀令牌
-func (gs *GoodService) VerifySeckillToken(tokenId string, userId, goodsId int64) (bool, error) {
-	valid, err := gs.RedisRepo.VerifySeckillToken(tokenId, userId, goodsId)
+	allowed, err := gs.RedisRepo.UserRateLimit(userId, rateLimit, time.Minute)
 	if err != nil {
-		slog.Warn("Seckill token verification failed",
-			"token_id_prefix", tokenId[:8],
+		// 限流后端故障，按配置放行或拒绝
+		if gs.RateLimitOpen {
+			slog.Warn("Rate limiter unavailable, failing open",
+				"user_id", userId,
+				"error", err,
+			)
+			return nil
+		}
+		slog.Error("Rate limiter unavailable, failing closed",
 			"user_id", userId,
-			"goods_id", goodsId,
 			"error", err,
 		)
-		return false, err
+		return fmt.Errorf("%w: %v", errs.ErrRateLimiterUnavailable, err)
 	}
-
-	if valid {
-		slog.Info("Seckill token verified successfully",
-			"token_id_prefix", tokenId[:8],
-			"user_id", userId,
-			"goods_id", goodsId,
-		)
-	} else {
-		slog.Warn("Seckill token invalid",
-			"token_id_prefix", tokenId[:8],
+	if !allowed {
+		slog.Warn("User rate limit exceeded",
 			"user_id", userId,
-			"goods_id", goodsId,
+			"limit", rateLimit,
 		)
+		return errs.ErrRateLimited
 	}
-	return valid, nil
+	return nil
 }
 
-// FindGoodById 根据ID查询商品
-func (gs *GoodService) FindGoodById(goodsId int64) (model.Goods, error) {
-	good, err := gs.GoodDB.FindGoodById(goodsId)
+// reserveTokenQuota 占用用户在本次活动中的一次令牌签发次数，计数保留到活动结束
+// 未配置seckill.max_tokens_per_user时不限制
+func (gs *GoodService) reserveTokenQuota(userId, goodsId int64, endTime time.Time) error {
+	limit := gs.Seckill.MaxTokensPerUser
+	if limit <= 0 {
+		return nil
+	}
+	allowed, err := gs.RedisRepo.ReserveSeckillTokenQuota(userId, goodsId, int64(limit), time.Until(endTime))
 	if err != nil {
-		slog.Warn("Good not found",
+		slog.Error("Failed to check seckill token quota",
+			"user_id", userId,
 			"goods_id", goodsId,
 			"error", err,
 		)
-		return good, err
+		return fmt.Errorf("%w: check token quota failed: %v", errs.ErrSystemBusy, err)
 	}
-
-	slog.Info("Good found",
-		"goods_id", goodsId,
-		"title", good.Title,
-	)
-	return good, nil
-}
-
-// GetPromotionByGoodsId 获取商品秒杀活动信息
-func (gs *GoodService) GetPromotionByGoodsId(goodsId int64) (model.PromotionSecKill, error) {
-	promotion, err := gs.GoodDB.GetPromotionByGoodsId(goodsId)
-	if err != nil {
-		slog.Warn("Promotion not found",
+	if !allowed {
+		slog.Warn("Seckill token quota exceeded",
+			"user_id", userId,
 			"goods_id", goodsId,
-			"error", err,
+			"limit", limit,
 		)
-		return promotion, err
+		return errs.ErrTokenQuotaExceeded
 	}
-
-	slog.Info("Promotion found",
-		"goods_id", goodsId,
-		"ps_count", promotion.PsCount,
-		"current_price", promotion.CurrentPrice,
-	)
-	return promotion, nil
+	return nil
 }
 
-// PreloadGoodsStock 预加载商品库存到Redis
-func (gs *GoodService) PreloadGoodsStock(goodsId int64) error {
-	// 获取ETCD分布式锁，防止并发预加载
-	lockKey := fmt.Sprintf("preload_lock_%d", goodsId)
-	locked, err := gs.EtcdRepo.GetDistributedLock(context.Background(), lockKey, 30) // 30秒超时
-	if err != nil || !locked {
-		slog.Warn("Failed to acquire preload lock",
+// releaseTokenQuota 令牌签发失败时归还占用的签发次数，失败只记录日志
+func (gs *GoodService) releaseTokenQuota(userId, goodsId int64) {
+	if gs.Seckill.MaxTokensPerUser <= 0 {
+		return
+	}
+	if err := gs.RedisRepo.ReleaseSeckillTokenQuota(userId, goodsId); err != nil {
+		slog.Warn("Failed to release seckill token quota",
+			"user_id", userId,
 			"goods_id", goodsId,
 			"error", err,
 		)
-		return fmt.Errorf("failed to acquire preload lock for goods %d", goodsId)
 	}
-	defer gs.EtcdRepo.ReleaseDistributedLock(context.Background(), lockKey)
+}
 
-	promotion, err := gs.GetPromotionByGoodsId(goodsId)
+// CheckIPRateLimit 客户端IP限流检查，豁免名单中的IP直接放行且不计入限流次数
+// 限流后端故障时与用户限流一样按RateLimitOpen放行或拒绝
+func (gs *GoodService) CheckIPRateLimit(clientIP string) error {
+	if gs.Allowlist != nil && gs.Allowlist.AllowsIP(clientIP) {
+		return nil
+	}
+
+	rateLimit, err := gs.EtcdRepo.GetIPRateLimitConfig(context.Background())
 	if err != nil {
-		slog.Error("Failed to get promotion for preload",
-			"goods_id", goodsId,
+		slog.Warn("Failed to get ip rate limit config, using default",
+			"default_limit", rateLimit,
 			"error", err,
 		)
-		return err
 	}
 
-	err = gs.RedisRepo.SetGoodsStock(goodsId, promotion.PsCount)
+	allowed, err := gs.RedisRepo.IPRateLimit(clientIP, rateLimit, time.Minute)
 	if err != nil {
-		slog.Error("Failed to preload goods stock to Redis",
-			"goods_id", goodsId,
-			"stock", promotion.PsCount,
+		if gs.RateLimitOpen {
+			slog.Warn("IP rate limiter unavailable, failing open",
+				"client_ip", clientIP,
+				"error", err,
+			)
+			return nil
+		}
+		slog.Error("IP rate limiter unavailable, failing closed",
+			"client_ip", clientIP,
 			"error", err,
 		)
-		return err
+		return fmt.Errorf("%w: %v", errs.ErrRateLimiterUnavailable, err)
+	}
+	if !allowed {
+		slog.Warn("IP rate limit exceeded",
+			"client_ip", clientIP,
+			"limit", rateLimit,
+		)
+		return errs.ErrRateLimited
 	}
-
-	slog.Info("Goods stock preloaded to Redis",
-		"goods_id", goodsId,
-		"stock", promotion.PsCount,
-	)
 	return nil
 }
 
-// SeckillWithToken 使用令牌进行秒杀
-func (gs *GoodService) SeckillWithToken(userId, goodsId int64, tokenId string) (string, error) {
-	// 验证令牌有效性
-	valid, err := gs.VerifySeckillToken(tokenId, userId, goodsId)
-	if err != nil || !valid {
-		slog.Warn("Invalid seckill token",
-			"token_id_prefix", tokenId[:8],
-			"user_id", userId,
-			"goods_id", goodsId,
-			"error", err,
-		)
-		return "", fmt.Errorf("invalid seckill token: %v", err)
+// PrecheckSeckill 预检用户秒杀资格
+// 只执行只读检查，不消耗限流次数也不签发令牌；每项检查独立执行，便于前端展示全部原因
+func (gs *GoodService) PrecheckSeckill(userId, goodsId int64) model.SeckillEligibility {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	report := model.SeckillEligibility{UserId: userId, GoodsId: goodsId, Eligible: true}
+	record := func(name string, passed bool, reason string) {
+		report.Checks = append(report.Checks, model.EligibilityCheck{Name: name, Passed: passed, Reason: reason})
+		if !passed {
+			report.Eligible = false
+		}
+	}
+
+	// 检查秒杀系统是否开启
+	if enabled, err := gs.EtcdRepo.GetSeckillEnabled(ctx); err != nil {
+		record(model.CheckSeckillEnabled, false, err.Error())
+	} else if !enabled {
+		record(model.CheckSeckillEnabled, false, errs.ErrSeckillDisabled.Error())
+	} else {
+		record(model.CheckSeckillEnabled, true, "")
+	}
+
+	// 检查用户是否在黑名单
+	if inBlacklist, err := gs.EtcdRepo.IsInBlacklist(ctx, userId); err != nil {
+		record(model.CheckNotBlacklisted, false, err.Error())
+	} else if inBlacklist {
+		record(model.CheckNotBlacklisted, false, errs.ErrBlacklisted.Error())
+	} else {
+		record(model.CheckNotBlacklisted, true, "")
+	}
+
+	// 检查商品是否存在
+	if _, err := gs.GoodDB.FindGoodById(goodsId); err != nil {
+		record(model.CheckGoodsExists, false, fmt.Sprintf("find goods failed: %v", err))
+	} else {
+		record(model.CheckGoodsExists, true, "")
+	}
+
+	// 检查秒杀活动时间
+	if promotion, err := gs.GoodDB.GetPromotionByGoodsId(goodsId); err != nil {
+		record(model.CheckInWindow, false, fmt.Sprintf("find promotion failed: %v", err))
+	} else if now := time.Now(); now.Before(promotion.StartTime.Time) || now.After(promotion.EndTime.Time) {
+		record(model.CheckInWindow, false, errs.ErrActivityNotAvailable.Error())
+	} else {
+		record(model.CheckInWindow, true, "")
+	}
+
+	// 检查库存
+	if stock, err := gs.RedisRepo.GetGoodsStock(goodsId); err != nil {
+		record(model.CheckStockAvailable, false, err.Error())
+	} else if stock <= 0 {
+		record(model.CheckStockAvailable, false, errs.ErrSoldOut.Error())
+	} else {
+		record(model.CheckStockAvailable, true, "")
+	}
+
+	// 检查限流（只读取当前计数）
+	rateLimit, err := gs.EtcdRepo.GetRateLimitConfig(ctx)
+	if err != nil {
+		rateLimit = 10 // 默认限流值
+	}
+	if gs.Allowlist != nil && gs.Allowlist.Allows(userId, "") {
+		record(model.CheckNotRateLimited, true, "")
+	} else if count, err := gs.RedisRepo.GetUserRateCount(userId); err != nil {
+		record(model.CheckNotRateLimited, false, err.Error())
+	} else if count >= rateLimit {
+		record(model.CheckNotRateLimited, false, errs.ErrRateLimited.Error())
+	} else {
+		record(model.CheckNotRateLimited, true, "")
+	}
+
+	slog.Info("Seckill precheck completed",
+		"user_id", userId,
+		"goods_id", goodsId,
+		"eligible", report.Eligible,
+	)
+	return report
+}
+
+// ListActiveSeckills 分页列出进行中的秒杀活动，附带Redis实时库存和已售数量
+// page从1开始，pageSize非正数时使用默认值，超过上限时截断
+func (gs *GoodService) ListActiveSeckills(page, pageSize int) (model.Paginated[model.ActiveSeckill], error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = repository.DefaultActivePageSize
+	}
+	if pageSize > repository.MaxActivePageSize {
+		pageSize = repository.MaxActivePageSize
+	}
+	result := model.NewPaginated[model.ActiveSeckill](nil, page, pageSize, 0)
+
+	promotions, total, err := gs.GoodDB.ListActivePromotions(time.Now(), (page-1)*pageSize, pageSize)
+	if err != nil {
+		slog.Error("Failed to list active promotions",
+			"page", page,
+			"page_size", pageSize,
+			"error", err,
+		)
+		return result, err
+	}
+	result = model.NewPaginated[model.ActiveSeckill](nil, page, pageSize, total)
+	if len(promotions) == 0 {
+		return result, nil
+	}
+
+	goodsIds := make([]int64, len(promotions))
+	for i, promotion := range promotions {
+		goodsIds[i] = promotion.GoodsId
+	}
+	stocks, err := gs.RedisRepo.GetGoodsStockBatch(goodsIds)
+	if err != nil {
+		slog.Error("Failed to get live stock for active seckills",
+			"count", len(goodsIds),
+			"error", err,
+		)
+		return result, err
+	}
+	sold, err := gs.GoodDB.CountSoldByGoodsIds(goodsIds)
+	if err != nil {
+		slog.Error("Failed to count sold for active seckills",
+			"count", len(goodsIds),
+			"error", err,
+		)
+		return result, err
+	}
+
+	for _, promotion := range promotions {
+		stock, loaded := stocks[promotion.GoodsId]
+		result.Items = append(result.Items, model.ActiveSeckill{
+			PsId:         promotion.PsId,
+			GoodsId:      promotion.GoodsId,
+			PsCount:      promotion.PsCount,
+			CurrentPrice: promotion.CurrentPrice,
+			StartTime:    promotion.StartTime,
+			EndTime:      promotion.EndTime,
+			Stock:        stock,
+			StockLoaded:  loaded,
+			Sold:         sold[promotion.GoodsId],
+		})
+	}
+	return result, nil
+}
+
+// RetryOrderOutbox 重发发件箱中的订单消息，返回成功数量和剩余待重试数量
+func (gs *GoodService) RetryOrderOutbox(limit int) (int, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sent, err := gs.SeckillHandler.RetryOrderOutbox(ctx, limit)
+	if err != nil {
+		slog.Error("Failed to retry order outbox",
+			"sent", sent,
+			"error", err,
+		)
+		return sent, 0, err
+	}
+	remaining, err := gs.RedisRepo.OrderOutboxLen()
+	if err != nil {
+		return sent, 0, fmt.Errorf("get outbox length failed: %v", err)
+	}
+	return sent, remaining, nil
+}
+
+// SubscribeStockUpdates 订阅商品库存变更，ctx取消后返回的通道关闭
+func (gs *GoodService) SubscribeStockUpdates(ctx context.Context, goodsId int64) (<-chan model.StockUpdate, error) {
+	updates, err := gs.RedisRepo.SubscribeStockChanges(ctx, goodsId)
+	if err != nil {
+		slog.Error("Failed to subscribe stock updates",
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return nil, err
+	}
+	return updates, nil
+}
+
+// Outcomes 返回获取令牌和秒杀请求按结果分类的计数，键为操作类型和结果分类
+func (gs *GoodService) Outcomes() map[string]map[string]int64 {
+	return gs.outcomes.Snapshot()
+}
+
+// GetPromotionSchedule 查询商品的秒杀活动排期，按开始时间排序并标注每场活动当前所处阶段
+func (gs *GoodService) GetPromotionSchedule(goodsId int64) ([]model.ScheduledPromotion, error) {
+	promotions, err := gs.GoodDB.GetPromotionSchedule(goodsId)
+	if err != nil {
+		slog.Error("Failed to query promotion schedule",
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	now := time.Now()
+	schedule := make([]model.ScheduledPromotion, 0, len(promotions))
+	for _, promotion := range promotions {
+		schedule = append(schedule, model.ScheduledPromotion{
+			PsId:         promotion.PsId,
+			GoodsId:      promotion.GoodsId,
+			PsCount:      promotion.PsCount,
+			CurrentPrice: promotion.CurrentPrice,
+			StartTime:    promotion.StartTime,
+			EndTime:      promotion.EndTime,
+			Phase:        activityPhase(promotion, now),
+		})
+	}
+	return schedule, nil
+}
+
+// GetCurrentStock 原子读取商品当前Redis库存，库存尚未预加载时loaded为false
+// 只读查询，不校验用户也不计入限流
+func (gs *GoodService) GetCurrentStock(goodsId int64) (stock int64, loaded bool, err error) {
+	stock, err = gs.RedisRepo.GetStockAtomic(goodsId)
+	if errors.Is(err, errs.ErrStockNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return stock, true, nil
+}
+
+// GetGoodsStock 获取商品当前Redis库存
+func (gs *GoodService) GetGoodsStock(goodsId int64) (int64, error) {
+	return gs.RedisRepo.GetGoodsStock(goodsId)
+}
+
+// RecordAuditEvent 异步发送秒杀审计事件，发送失败只记录日志
+// err为nil表示操作成功，否则记录为失败并附带原因
+func (gs *GoodService) RecordAuditEvent(action string, userId, goodsId int64, clientIP string, err error) {
+	if gs.Auditor == nil {
+		return // 未开启审计
+	}
+
+	event := &model.AuditEvent{
+		Action:    action,
+		UserId:    userId,
+		GoodsId:   goodsId,
+		Outcome:   model.AuditOutcomeSuccess,
+		ClientIP:  clientIP,
+		Timestamp: time.Now(),
+	}
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Reason = err.Error()
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if sendErr := gs.Auditor.SendAuditEvent(ctx, event); sendErr != nil {
+			slog.Warn("Failed to send audit event",
+				"action", action,
+				"user_id", userId,
+				"goods_id", goodsId,
+				"error", sendErr,
+			)
+		}
+	}()
+}
+
+// StartConfigWatcher 启动ETCD配置监听
+func (gs *GoodService) StartConfigWatcher() {
+	go func() {
+		slog.Info("Starting etcd config watcher...")
+		// 监听秒杀配置变更
+		gs.EtcdRepo.WatchSeckillConfig(context.Background(), func(key, value string) {
+			slog.Info("ETCD config changed",
+				"key", key,
+				"value", value,
+			)
+
+			// 根据不同的配置键处理变更
+			switch key {
+			case global.EtcdKeySeckillEnabled:
+				if value == "false" {
+					slog.Warn("Seckill system has been disabled via etcd config")
+				} else {
+					slog.Info("Seckill system has been enabled via etcd config")
+				}
+			case global.EtcdKeyRateLimit:
+				slog.Info("Rate limit config changed", "new_value", value)
+			case global.EtcdKeyStockPreload:
+				slog.Info("Stock preload config changed", "new_value", value)
+			}
+		})
+	}()
+}
+
+// StartAllowlistWatcher 从ETCD加载限流豁免名单并监听变更
+// ETCD中未配置名单或读取失败时沿用配置文件中的名单
+func (gs *GoodService) StartAllowlistWatcher() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	allowlist, err := gs.EtcdRepo.GetRateLimitAllowlist(ctx)
+	if err != nil {
+		slog.Warn("Failed to load rate limit allowlist from etcd, using config file",
+			"error", err,
+		)
+	} else if allowlist != nil {
+		gs.Allowlist.Update(allowlist)
+	}
+
+	gs.EtcdRepo.WatchRateLimitAllowlist(context.Background(), gs.Allowlist.Update)
+}
+
+// StartGoodsAllowlistWatcher 从ETCD加载秒杀商品准入名单并监听变更
+// ETCD中未配置名单或读取失败时沿用配置文件中的名单
+func (gs *GoodService) StartGoodsAllowlistWatcher() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	allowlist, err := gs.EtcdRepo.GetGoodsAllowlist(ctx)
+	if err != nil {
+		slog.Warn("Failed to load goods allowlist from etcd, using config file",
+			"error", err,
+		)
+	} else if allowlist != nil {
+		gs.ApprovedGoods.Update(allowlist)
+	}
+
+	gs.EtcdRepo.WatchGoodsAllowlist(context.Background(), gs.ApprovedGoods.Update)
+}
+
+// StartReadOnlyWatcher 从ETCD加载只读模式开关并监听变更，读取失败时按非只读处理
+func (gs *GoodService) StartReadOnlyWatcher() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	readOnly, err := gs.EtcdRepo.GetReadOnly(ctx)
+	if err != nil {
+		slog.Warn("Failed to load read-only mode from etcd, assuming writable",
+			"error", err,
+		)
+	}
+	gs.SetReadOnlyState(readOnly)
+
+	gs.EtcdRepo.WatchReadOnly(context.Background(), gs.SetReadOnlyState)
+}
+
+// SetReadOnlyState 更新本地只读模式状态
+func (gs *GoodService) SetReadOnlyState(readOnly bool) {
+	if gs.readOnly.Swap(readOnly) == readOnly {
+		return
+	}
+	if readOnly {
+		slog.Warn("Read-only mode enabled, write endpoints are rejected")
+	} else {
+		slog.Info("Read-only mode disabled, write endpoints are accepted")
+	}
+}
+
+// ReadOnly 判断系统是否处于只读模式
+func (gs *GoodService) ReadOnly() bool {
+	return gs.readOnly.Load()
+}
+
+// SetSeckillEnabled 设置秒杀开关状态
+func (gs *GoodService) SetSeckillEnabled(enabled bool) error {
+	err := gs.EtcdRepo.SetSeckillEnabled(context.Background(), enabled)
+	if err != nil {
+		slog.Error("Failed to set seckill enabled",
+			"enabled", enabled,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.Info("Seckill enabled status updated",
+		"enabled", enabled,
+	)
+	return nil
+}
+
+// SetRateLimit 设置限流值
+func (gs *GoodService) SetRateLimit(limit int64) error {
+	err := gs.EtcdRepo.SetRateLimitConfig(context.Background(), limit)
+	if err != nil {
+		slog.Error("Failed to set rate limit",
+			"limit", limit,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.Info("Rate limit updated",
+		"limit", limit,
+	)
+	return nil
+}
+
+// AddToBlacklist 添加用户到黑名单，返回封禁是否需要人工复核
+// Etcd租约创建失败时封禁仍然生效但不会自动过期，needsReview为true，需要人工复核后手动移除
+func (gs *GoodService) AddToBlacklist(userId int64, reason string, duration time.Duration) (needsReview bool, err error) {
+	needsReview, err = gs.EtcdRepo.AddToBlacklist(context.Background(), userId, reason, duration)
+	if err != nil {
+		slog.Error("Failed to add user to blacklist",
+			"user_id", userId,
+			"reason", reason,
+			"duration", duration,
+			"error", err,
+		)
+		return false, err
+	}
+
+	slog.Info("User added to blacklist",
+		"user_id", userId,
+		"reason", reason,
+		"duration", duration,
+		"needs_review", needsReview,
+	)
+
+	// 吊销已签发的令牌，黑名单已生效，吊销失败只记录日志
+	if _, err := gs.RedisRepo.RevokeUserTokens(userId); err != nil {
+		slog.Error("Failed to revoke tokens of blacklisted user",
+			"user_id", userId,
+			"error", err,
+		)
+	}
+	return needsReview, nil
+}
+
+// defaultBlacklistImportReason 批量导入条目未填写原因时使用的默认原因
+const defaultBlacklistImportReason = "Bulk import"
+
+// ImportBlacklist 批量添加用户到黑名单，返回每个条目的处理结果
+// 用户ID非正数、时长无法解析或非正数、用户ID重复的条目不写入，不影响其他条目；
+// 加入成功的用户同时吊销已签发的令牌
+func (gs *GoodService) ImportBlacklist(entries []model.BlacklistEntry) []model.BlacklistImportResult {
+	results := make([]model.BlacklistImportResult, len(entries))
+	additions := make([]repository.BlacklistAddition, 0, len(entries))
+	indexes := make(map[int64]int, len(entries)) // 用户ID到结果下标
+	for i, entry := range entries {
+		results[i] = model.BlacklistImportResult{Index: i, UserId: entry.UserId}
+		addition, err := validateBlacklistEntry(entry)
+		if err == nil {
+			if _, duplicate := indexes[entry.UserId]; duplicate {
+				err = fmt.Errorf("duplicate user_id %d", entry.UserId)
+			}
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		indexes[entry.UserId] = i
+		additions = append(additions, addition)
+	}
+
+	var failed map[int64]error
+	if len(additions) > 0 {
+		failed = gs.EtcdRepo.BatchAddToBlacklist(context.Background(), additions)
+	}
+	for _, addition := range additions {
+		i := indexes[addition.UserId]
+		if err, ok := failed[addition.UserId]; ok {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Success = true
+
+		// 吊销已签发的令牌，黑名单已生效，吊销失败只记录日志
+		if _, err := gs.RedisRepo.RevokeUserTokens(addition.UserId); err != nil {
+			slog.Error("Failed to revoke tokens of blacklisted user",
+				"user_id", addition.UserId,
+				"error", err,
+			)
+		}
+	}
+
+	slog.Info("Blacklist imported",
+		"entries", len(entries),
+		"added", len(additions)-len(failed),
+	)
+	return results
+}
+
+// validateBlacklistEntry 校验黑名单导入条目并转换为写入参数，原因为空时使用默认原因
+func validateBlacklistEntry(entry model.BlacklistEntry) (repository.BlacklistAddition, error) {
+	if entry.UserId <= 0 {
+		return repository.BlacklistAddition{}, fmt.Errorf("invalid user_id %d", entry.UserId)
+	}
+	duration, err := time.ParseDuration(entry.Duration)
+	if err != nil {
+		return repository.BlacklistAddition{}, fmt.Errorf("invalid duration %q", entry.Duration)
+	}
+	if duration <= 0 {
+		return repository.BlacklistAddition{}, fmt.Errorf("duration must be positive, got %q", entry.Duration)
+	}
+	reason := entry.Reason
+	if reason == "" {
+		reason = defaultBlacklistImportReason
+	}
+	return repository.BlacklistAddition{UserId: entry.UserId, Reason: reason, Duration: duration}, nil
+}
+
+// ExportBlacklist 导出全部黑名单条目（按加入时间倒序），格式与批量导入一致
+// 时长为距过期的剩余时间（取整到秒），可直接重新导入；无法解析的条目和已过期的条目被跳过
+func (gs *GoodService) ExportBlacklist() ([]model.BlacklistEntry, error) {
+	blacklist, err := gs.EtcdRepo.GetBlacklist(context.Background(), 0)
+	if err != nil {
+		slog.Error("Failed to export blacklist",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	now := time.Now()
+	entries := make([]model.BlacklistEntry, 0, len(blacklist))
+	for _, info := range blacklist {
+		entry, ok := blacklistEntryFromInfo(info, now)
+		if !ok {
+			slog.Warn("Skipping unparsable or expired blacklist entry on export",
+				"entry", info,
+			)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	slog.Info("Blacklist exported",
+		"count", len(entries),
+	)
+	return entries, nil
+}
+
+// blacklistEntryFromInfo 将Etcd中存储的黑名单信息转换为导出条目
+func blacklistEntryFromInfo(info map[string]any, now time.Time) (model.BlacklistEntry, bool) {
+	userId, ok := info["user_id"].(float64) // JSON数字反序列化为float64
+	if !ok || userId <= 0 {
+		return model.BlacklistEntry{}, false
+	}
+	expireStr, _ := info["expire"].(string)
+	expire, err := time.Parse(time.RFC3339, expireStr)
+	if err != nil {
+		return model.BlacklistEntry{}, false
+	}
+	remaining := expire.Sub(now).Round(time.Second)
+	if remaining <= 0 {
+		return model.BlacklistEntry{}, false
+	}
+	reason, _ := info["reason"].(string)
+	return model.BlacklistEntry{
+		UserId:   int64(userId),
+		Reason:   reason,
+		Duration: remaining.String(),
+	}, true
+}
+
+// RemoveFromBlacklist 从黑名单移除用户
+func (gs *GoodService) RemoveFromBlacklist(userId int64) error {
+	err := gs.EtcdRepo.RemoveFromBlacklist(context.Background(), userId)
+	if err != nil {
+		slog.Error("Failed to remove user from blacklist",
+			"user_id", userId,
+			"error", err,
+		)
+		return err
+	}
+
+	slog.Info("User removed from blacklist",
+		"user_id", userId,
+	)
+	return nil
+}
+
+// GetBlacklist 分页获取黑名单列表（按加入时间倒序）
+// page从1开始，size非正数时使用默认值，超过上限时截断
+func (gs *GoodService) GetBlacklist(page, size int) (model.Paginated[map[string]any], error) {
+	if size <= 0 {
+		size = repository.DefaultBlacklistPageSize
+	}
+	size = min(size, repository.MaxBlacklistPageSize)
+	blacklist, err := gs.EtcdRepo.GetBlacklist(context.Background(), 0)
+	if err != nil {
+		slog.Error("Failed to get blacklist",
+			"error", err,
+		)
+		return model.NewPaginated[map[string]any](nil, page, size, 0), err
+	}
+
+	slog.Info("Blacklist retrieved",
+		"count", len(blacklist),
+	)
+	return model.Paginate(blacklist, page, size), nil
+}
+
+// VerifySeckillToken 验证秒杀令牌
+func (gs *GoodService) VerifySeckillToken(tokenId string, userId, goodsId int64) (bool, error) {
+	valid, _, err := gs.redeemSeckillToken(tokenId, userId, goodsId)
+	return valid, err
+}
+
+// redeemSeckillToken 验证并消费秒杀令牌，stockReserved表示令牌仍持有签发时预占的库存
+func (gs *GoodService) redeemSeckillToken(tokenId string, userId, goodsId int64) (valid, stockReserved bool, err error) {
+	valid, stockReserved, err = gs.RedisRepo.RedeemSeckillToken(tokenId, userId, goodsId)
+	if err != nil {
+		slog.Warn("Seckill token verification failed",
+			"token_id_prefix", model.TokenPrefix(tokenId),
+			"user_id", userId,
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return false, false, err
+	}
+
+	if valid {
+		metrics.ObserveTokenRedeemed(goodsId)
+		slog.Info("Seckill token verified successfully",
+			"token_id_prefix", model.TokenPrefix(tokenId),
+			"user_id", userId,
+			"goods_id", goodsId,
+		)
+	} else {
+		slog.Warn("Seckill token invalid",
+			"token_id_prefix", model.TokenPrefix(tokenId),
+			"user_id", userId,
+			"goods_id", goodsId,
+		)
+	}
+	return valid, stockReserved, nil
+}
+
+// releaseReservedStock 归还已兑换令牌预占但未用于下单的一件Redis库存
+func (gs *GoodService) releaseReservedStock(goodsId int64) {
+	if _, err := gs.RedisRepo.IncrGoodsStock(goodsId); err != nil {
+		slog.Error("Failed to release reserved stock",
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return
+	}
+	gs.clearSoldOut(goodsId)
+}
+
+// ExpireSeckillToken 强制使秒杀令牌失效，用于处置滥用，返回令牌是否存在
+// 令牌预占的库存同时归还
+func (gs *GoodService) ExpireSeckillToken(goodsId int64, tokenId string) (bool, error) {
+	existed, err := gs.RedisRepo.DeleteSeckillToken(goodsId, tokenId)
+	if existed {
+		gs.clearSoldOut(goodsId)
+	}
+	if err != nil {
+		slog.Error("Failed to expire seckill token",
+			"token_id_prefix", model.TokenPrefix(tokenId),
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return false, err
+	}
+
+	slog.Warn("Seckill token expired by admin",
+		"token_id_prefix", model.TokenPrefix(tokenId),
+		"goods_id", goodsId,
+		"existed", existed,
+	)
+	return existed, nil
+}
+
+// FindGoodById 根据ID查询商品
+func (gs *GoodService) FindGoodById(goodsId int64) (model.Goods, error) {
+	good, err := gs.GoodDB.FindGoodById(goodsId)
+	if err != nil {
+		slog.Warn("Good not found",
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return good, err
+	}
+
+	slog.Info("Good found",
+		"goods_id", goodsId,
+		"title", good.Title,
+	)
+	return good, nil
+}
+
+// GetGoodInfo 获取商品信息，数据库不可用时降级返回缓存数据
+// stale为true表示返回的是缓存中的（可能已过期的）数据
+func (gs *GoodService) GetGoodInfo(goodsId int64) (good model.Goods, stale bool, err error) {
+	good, err = gs.FindGoodById(goodsId)
+	if err == nil {
+		// 写入缓存供降级使用，失败不影响正常返回
+		if cacheErr := gs.RedisRepo.SetGoodsInfoCache(good, repository.GoodsInfoCacheTTL); cacheErr != nil {
+			slog.Warn("Failed to cache goods info",
+				"goods_id", goodsId,
+				"error", cacheErr,
+			)
+		}
+		return good, false, nil
+	}
+
+	// 商品不存在时不降级，直接返回错误
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return good, false, err
+	}
+
+	cached, found, cacheErr := gs.RedisRepo.GetGoodsInfoCache(goodsId)
+	if cacheErr != nil || !found {
+		slog.Error("Goods info unavailable, no cache to fall back on",
+			"goods_id", goodsId,
+			"db_error", err,
+			"cache_error", cacheErr,
+		)
+		return good, false, err
+	}
+
+	slog.Warn("Database unavailable, serving stale goods info from cache",
+		"goods_id", goodsId,
+		"cached_at", cached.CachedAt,
+		"expired", time.Now().After(cached.ExpireAt),
+		"db_error", err,
+	)
+	return cached.Goods, true, nil
+}
+
+// GetSeckillInfo 获取商品秒杀活动的剩余库存、价格和起止时间
+// 库存未预加载到Redis时以活动总库存作为剩余库存，商品没有秒杀活动时返回错误
+func (gs *GoodService) GetSeckillInfo(goodsId int64) (model.SeckillInfo, error) {
+	promotion, err := gs.GetPromotionByGoodsId(goodsId)
+	if err != nil {
+		return model.SeckillInfo{}, err
+	}
+	stocks, err := gs.RedisRepo.GetGoodsStockBatch([]int64{goodsId})
+	if err != nil {
+		return model.SeckillInfo{}, err
+	}
+
+	info := model.SeckillInfo{
+		RemainingStock: promotion.PsCount,
+		SeckillPrice:   promotion.CurrentPrice,
+		StartTime:      promotion.StartTime,
+		EndTime:        promotion.EndTime,
+		PurchaseLimit:  promotion.PerUserLimit(),
+	}
+	if stock, loaded := stocks[goodsId]; loaded {
+		info.RemainingStock = stock
+		info.StockPreloaded = true
+	}
+	return info, nil
+}
+
+// SearchGoodsByTitle 根据标题关键字分页搜索商品
+// page从1开始，size非正数时使用默认值，超过上限时截断
+func (gs *GoodService) SearchGoodsByTitle(q string, page, size int) (model.Paginated[model.Goods], error) {
+	page = max(page, 1)
+	size = repository.NormalizeSearchLimit(size)
+	goods, total, err := gs.GoodDB.SearchGoodsByTitlePage(q, (page-1)*size, size)
+	if err != nil {
+		slog.Error("Failed to search goods",
+			"query", q,
+			"error", err,
+		)
+		return model.NewPaginated[model.Goods](nil, page, size, 0), err
+	}
+
+	slog.Info("Goods search completed",
+		"query", q,
+		"count", len(goods),
+		"total", total,
+	)
+	return model.NewPaginated(goods, page, size, total), nil
+}
+
+// GetPromotionByGoodsId 获取商品秒杀活动信息
+// 配置了促销缓存时优先读取缓存
+func (gs *GoodService) GetPromotionByGoodsId(goodsId int64) (model.PromotionSecKill, error) {
+	var promotion model.PromotionSecKill
+	var err error
+	if gs.Promotions != nil {
+		promotion, _, err = gs.Promotions.Get(goodsId)
+	} else {
+		promotion, err = gs.GoodDB.GetPromotionByGoodsId(goodsId)
+	}
+	if err != nil {
+		slog.Warn("Promotion not found",
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return promotion, err
+	}
+
+	slog.Info("Promotion found",
+		"goods_id", goodsId,
+		"ps_count", promotion.PsCount,
+		"current_price", promotion.CurrentPrice,
+	)
+	return promotion, nil
+}
+
+// invalidatePromotion 使商品的促销缓存失效，未配置缓存时不执行任何操作
+func (gs *GoodService) invalidatePromotion(goodsId int64) {
+	if gs.Promotions != nil {
+		gs.Promotions.Invalidate(goodsId)
+	}
+}
+
+// locker 返回指定分类使用的分布式锁，未配置锁工厂时使用Etcd锁
+func (gs *GoodService) locker(category string) repository.DistributedLocker {
+	if gs.Locks == nil {
+		return gs.EtcdRepo
+	}
+	return gs.Locks.Locker(category)
+}
+
+// acquireRenewableLock 获取分布式锁，锁后端支持续期时持锁期间自动续期
+// 返回持有者令牌和stop，stop停止续期但不释放锁，获取失败时为nil
+func acquireRenewableLock(ctx context.Context, locker repository.DistributedLocker, key string, ttl int) (string, bool, context.CancelFunc, error) {
+	if renewable, ok := locker.(repository.RenewableLocker); ok {
+		return renewable.GetDistributedLockWithRenewal(ctx, key, ttl)
+	}
+	token, locked, err := locker.GetDistributedLock(ctx, key, ttl)
+	if err != nil || !locked {
+		return "", false, nil, err
+	}
+	return token, true, func() {}, nil
+}
+
+// lockKey 返回带命名空间前缀的锁键，未配置锁工厂时使用默认命名空间
+func (gs *GoodService) lockKey(name string) string {
+	if gs.Locks == nil {
+		return config.LockConfig{}.Key(name)
+	}
+	return gs.Locks.Key(name)
+}
+
+// PreloadGoodsStock 预加载商品库存到Redis，返回是否写入了库存
+// Redis库存已与促销库存一致时跳过加锁和写入，force为true时强制写入
+func (gs *GoodService) PreloadGoodsStock(goodsId int64, force bool) (bool, error) {
+	if !force && gs.preloadIsCurrent(goodsId) {
+		slog.Info("Goods stock already current, preload skipped",
+			"goods_id", goodsId,
+		)
+		return false, nil
+	}
+
+	// 获取分布式锁，防止并发预加载
+	lockKey := gs.lockKey(fmt.Sprintf("preload_lock_%d", goodsId))
+	locker := gs.locker(config.LockCategoryPreload)
+	lockToken, locked, err := locker.GetDistributedLock(context.Background(), lockKey, 30) // 30秒超时
+	if err != nil || !locked {
+		slog.Warn("Failed to acquire preload lock",
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return false, fmt.Errorf("failed to acquire preload lock for goods %d", goodsId)
+	}
+	defer locker.ReleaseDistributedLock(context.Background(), lockKey, lockToken)
+
+	promotion, err := gs.GetPromotionByGoodsId(goodsId)
+	if err != nil {
+		slog.Error("Failed to get promotion for preload",
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return false, err
+	}
+	if err := validatePromotionCount(promotion); err != nil {
+		slog.Warn("Invalid promotion count, preload refused",
+			"goods_id", goodsId,
+			"ps_count", promotion.PsCount,
+		)
+		return false, err
+	}
+
+	err = gs.RedisRepo.SetGoodsStock(goodsId, promotion.PsCount)
+	if err != nil {
+		slog.Error("Failed to preload goods stock to Redis",
+			"goods_id", goodsId,
+			"stock", promotion.PsCount,
+			"error", err,
+		)
+		return false, err
+	}
+
+	slog.Info("Goods stock preloaded to Redis",
+		"goods_id", goodsId,
+		"stock", promotion.PsCount,
+	)
+	gs.clearSoldOut(goodsId)
+
+	// 重新预加载后开始新一轮活动，清除上一轮的生命周期事件标记
+	gs.resetLifecycleEvents(goodsId)
+	gs.emitLifecycleEvent(model.LifecyclePreloaded, goodsId, promotion.PsCount)
+	return true, nil
+}
+
+// ListLocks 列出锁命名空间下当前持有的Etcd分布式锁及其租约剩余有效期
+// 使用Redis后端的锁不在Etcd中，不包含在结果内
+func (gs *GoodService) ListLocks() ([]model.LockInfo, error) {
+	namespace := gs.lockKey("")
+	locks, err := gs.EtcdRepo.ListLockInfos(context.Background(), namespace)
+	if err != nil {
+		slog.Error("Failed to list distributed locks",
+			"namespace", namespace,
+			"error", err,
+		)
+		return nil, err
+	}
+	return locks, nil
+}
+
+// PreloadGoodsStockBatch 批量预加载多个商品的库存到Redis，返回每个商品的预加载结果
+// 整批共用一把分布式锁，一次查询全部促销信息，并通过管道写入库存；与单个预加载不同，总是写入库存
+// 单个商品失败（如没有促销信息）不影响其余商品，获取锁或查询促销信息失败时整批返回错误
+func (gs *GoodService) PreloadGoodsStockBatch(goodsIds []int64) (map[int64]model.PreloadResult, error) {
+	lockKey := gs.lockKey("preload_batch_lock")
+	locker := gs.locker(config.LockCategoryPreload)
+	lockToken, locked, err := locker.GetDistributedLock(context.Background(), lockKey, 30) // 30秒超时
+	if err != nil || !locked {
+		slog.Warn("Failed to acquire batch preload lock",
+			"count", len(goodsIds),
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to acquire batch preload lock")
+	}
+	defer locker.ReleaseDistributedLock(context.Background(), lockKey, lockToken)
+
+	promotions, err := gs.GoodDB.GetPromotionsByGoodsIds(goodsIds)
+	if err != nil {
+		slog.Error("Failed to get promotions for batch preload",
+			"count", len(goodsIds),
+			"error", err,
+		)
+		return nil, err
+	}
+
+	results := make(map[int64]model.PreloadResult, len(goodsIds))
+	stocks := make(map[int64]int64, len(promotions))
+	for _, goodsId := range goodsIds {
+		promotion, found := promotions[goodsId]
+		if !found {
+			results[goodsId] = model.PreloadResult{
+				Error: fmt.Sprintf("%v: goods %d", errs.ErrPromotionNotFound, goodsId),
+			}
+			continue
+		}
+		if err := validatePromotionCount(promotion); err != nil {
+			results[goodsId] = model.PreloadResult{Error: err.Error()}
+			continue
+		}
+		stocks[goodsId] = promotion.PsCount
+	}
+
+	failed := gs.RedisRepo.SetGoodsStockBatch(stocks)
+	for goodsId, stock := range stocks {
+		if err := failed[goodsId]; err != nil {
+			slog.Error("Failed to preload goods stock to Redis",
+				"goods_id", goodsId,
+				"stock", stock,
+				"error", err,
+			)
+			results[goodsId] = model.PreloadResult{Error: err.Error()}
+			continue
+		}
+		results[goodsId] = model.PreloadResult{Success: true, Stock: stock}
+		gs.clearSoldOut(goodsId)
+
+		// 重新预加载后开始新一轮活动，清除上一轮的生命周期事件标记
+		gs.resetLifecycleEvents(goodsId)
+		gs.emitLifecycleEvent(model.LifecyclePreloaded, goodsId, stock)
+	}
+
+	slog.Info("Goods stock preloaded to Redis in batch",
+		"count", len(goodsIds),
+		"succeeded", len(stocks)-len(failed),
+	)
+	return results, nil
+}
+
+// resetLifecycleEvents 清除商品上一轮活动的生命周期事件标记及本实例的首单事件确认记录
+func (gs *GoodService) resetLifecycleEvents(goodsId int64) {
+	if err := gs.RedisRepo.ResetLifecycleEvents(goodsId); err != nil {
+		slog.Warn("Failed to reset activity lifecycle events",
+			"goods_id", goodsId,
+			"error", err,
+		)
+	}
+	if gs.SeckillHandler != nil {
+		gs.SeckillHandler.ResetFirstSale(goodsId)
+	}
+}
+
+// emitLifecycleEvent 触发秒杀活动生命周期事件，未配置秒杀处理器时忽略
+func (gs *GoodService) emitLifecycleEvent(event string, goodsId, stock int64) bool {
+	if gs.SeckillHandler == nil {
+		return false
+	}
+	return gs.SeckillHandler.EmitLifecycleEvent(context.Background(), event, goodsId, stock)
+}
+
+// validatePromotionCount 校验促销库存不为负数
+// 库存为0是合法的"无库存"状态，预加载后获取令牌和秒杀均返回售罄
+func validatePromotionCount(promotion model.PromotionSecKill) error {
+	if promotion.PsCount < 0 {
+		return fmt.Errorf("%w: goods %d has ps_count %d", errs.ErrInvalidPromotionCount, promotion.GoodsId, promotion.PsCount)
+	}
+	return nil
+}
+
+// preloadIsCurrent 判断Redis中的库存是否已与促销库存一致
+// 任一查询失败时返回false，交由正常预加载流程处理
+func (gs *GoodService) preloadIsCurrent(goodsId int64) bool {
+	promotion, err := gs.GoodDB.GetPromotionByGoodsId(goodsId)
+	if err != nil {
+		return false
+	}
+	stocks, err := gs.RedisRepo.GetGoodsStockBatch([]int64{goodsId})
+	if err != nil {
+		return false
+	}
+	stock, loaded := stocks[goodsId]
+	return loaded && promotion.PsCount >= 0 && stock == promotion.PsCount // 负数库存交由预加载流程拒绝
+}
+
+// SeckillWithToken 使用令牌进行秒杀
+func (gs *GoodService) SeckillWithToken(userId, goodsId int64, tokenId string) (string, error) {
+	orderId, goodsVerified, err := gs.seckillWithToken(userId, goodsId, tokenId)
+	gs.outcomes.Record(model.AuditActionSeckill, err)
+	metrics.ObserveSeckill(metricsGoods(goodsId, goodsVerified), err)
+	gs.addSeckillAuditLog(userId, goodsId, tokenId, err)
+	return orderId, err
+}
+
+// SeckillWithIdempotencyKey 使用令牌进行秒杀，携带幂等键时相同幂等键的重试直接返回首次请求的结果
+// 成功和业务失败的结果在幂等窗口内保留；系统繁忙等临时错误不保留，重试时重新执行秒杀；
+// 幂等记录读写失败时按未携带幂等键处理
+func (gs *GoodService) SeckillWithIdempotencyKey(userId, goodsId int64, tokenId, idempotencyKey string) (string, error) {
+	if idempotencyKey == "" {
+		return gs.SeckillWithToken(userId, goodsId, tokenId)
+	}
+
+	ttl := gs.Seckill.IdempotencyTTL()
+	existing, claimed, err := gs.RedisRepo.ClaimSeckillIdempotencyKey(userId, idempotencyKey, goodsId, ttl)
+	if err != nil {
+		slog.Warn("Failed to claim seckill idempotency key, processing without it",
+			"user_id", userId,
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return gs.SeckillWithToken(userId, goodsId, tokenId)
+	}
+	if !claimed {
+		return replaySeckillResult(userId, goodsId, existing)
+	}
+
+	orderId, err := gs.SeckillWithToken(userId, goodsId, tokenId)
+	if err != nil && (errs.Code(err) == "" || errors.Is(err, errs.ErrSystemBusy)) {
+		// 临时错误释放幂等键，允许客户端使用同一幂等键重试
+		if delErr := gs.RedisRepo.DeleteSeckillIdempotencyKey(userId, idempotencyKey); delErr != nil {
+			slog.Warn("Failed to release seckill idempotency key",
+				"user_id", userId,
+				"goods_id", goodsId,
+				"error", delErr,
+			)
+		}
+		return orderId, err
+	}
+
+	record := model.SeckillIdempotencyRecord{State: model.IdempotencyStateDone, GoodsId: goodsId, OrderId: orderId}
+	if err != nil {
+		record.ErrorCode = errs.Code(err)
+		record.ErrorMessage = err.Error()
+	}
+	if setErr := gs.RedisRepo.SetSeckillIdempotencyResult(userId, idempotencyKey, record, ttl); setErr != nil {
+		slog.Warn("Failed to store seckill idempotency result",
+			"user_id", userId,
+			"goods_id", goodsId,
+			"error", setErr,
+		)
+	}
+	return orderId, err
+}
+
+// replaySeckillResult 返回幂等记录中首次请求的秒杀结果
+func replaySeckillResult(userId, goodsId int64, record model.SeckillIdempotencyRecord) (string, error) {
+	if record.GoodsId != goodsId {
+		slog.Warn("Seckill idempotency key reused for different goods",
+			"user_id", userId,
+			"goods_id", goodsId,
+			"original_goods_id", record.GoodsId,
+		)
+		return "", errs.ErrIdempotencyKeyReused
+	}
+	if record.State != model.IdempotencyStateDone {
+		return "", errs.ErrIdempotencyInProgress
+	}
+
+	slog.Info("Repeated seckill request with idempotency key, returning original result",
+		"user_id", userId,
+		"goods_id", goodsId,
+		"order_id", record.OrderId,
+		"error_code", record.ErrorCode,
+	)
+	if record.ErrorMessage != "" {
+		return "", errs.Replay(record.ErrorCode, record.ErrorMessage)
+	}
+	return record.OrderId, nil
+}
+
+// addSeckillAuditLog 将一次使用令牌秒杀的结果写入数据库审计表
+// 写入失败只记录日志，不影响秒杀结果；未配置数据库仓库时不记录
+func (gs *GoodService) addSeckillAuditLog(userId, goodsId int64, tokenId string, err error) {
+	if gs.GoodDB == nil {
+		return
+	}
+	auditLog := &model.SeckillAuditLog{
+		UserId:      userId,
+		GoodsId:     goodsId,
+		TokenPrefix: model.TokenPrefix(tokenId),
+		Result:      errs.Outcome(err),
+	}
+	if err != nil {
+		auditLog.Reason = err.Error()
+		if len(auditLog.Reason) > model.MaxAuditReasonLength {
+			auditLog.Reason = auditLog.Reason[:model.MaxAuditReasonLength]
+		}
+	}
+	if addErr := gs.GoodDB.AddAuditLog(auditLog); addErr != nil {
+		slog.Warn("Failed to write seckill audit log",
+			"user_id", userId,
+			"goods_id", goodsId,
+			"result", auditLog.Result,
+			"error", addErr,
+		)
+	}
+}
+
+// ListSeckillAuditLogs 分页查询商品的秒杀下单审计记录，最新的在前
+func (gs *GoodService) ListSeckillAuditLogs(goodsId int64, page, size int) (model.Paginated[model.SeckillAuditLog], error) {
+	if size <= 0 {
+		size = repository.DefaultAuditPageSize
+	}
+	size = min(size, repository.MaxAuditPageSize)
+	page = max(page, 1)
+
+	logs, total, err := gs.GoodDB.ListAuditLogsByGoodsId(goodsId, (page-1)*size, size)
+	if err != nil {
+		slog.Error("Failed to list seckill audit logs",
+			"goods_id", goodsId,
+			"page", page,
+			"size", size,
+			"error", err,
+		)
+		return model.NewPaginated[model.SeckillAuditLog](nil, page, size, 0), err
+	}
+	return model.NewPaginated(logs, page, size, total), nil
+}
+
+// recheckBlacklist 开启下单黑名单复查时检查用户是否在黑名单中
+// 黑名单读取失败（且无降级缓存）时放行，获取令牌时已检查过黑名单，不因Etcd故障阻断下单
+func (gs *GoodService) recheckBlacklist(userId, goodsId int64) error {
+	if !gs.Seckill.RecheckBlacklist {
+		return nil
+	}
+	inBlacklist, err := gs.EtcdRepo.IsInBlacklist(context.Background(), userId)
+	if err != nil {
+		slog.Warn("Failed to recheck blacklist before seckill, allowing request",
+			"user_id", userId,
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return nil
+	}
+	if inBlacklist {
+		slog.Warn("Blacklisted user attempted to redeem seckill token",
+			"user_id", userId,
+			"goods_id", goodsId,
+		)
+		return errs.ErrBlacklisted
+	}
+	return nil
+}
+
+// seckillWithToken 校验并消费令牌后在用户锁内创建订单
+// goodsVerified表示令牌已通过校验（签发时商品和活动已查询到）或商品已确认售罄，只有此时才按商品ID记录指标
+func (gs *GoodService) seckillWithToken(userId, goodsId int64, tokenId string) (string, bool, error) {
+	// 获取令牌后被加入黑名单的用户即使持有有效令牌也不能下单
+	if err := gs.recheckBlacklist(userId, goodsId); err != nil {
+		return "", false, err
+	}
+
+	// 短时间内的重复提交直接返回已创建的订单，不再校验令牌
+	if gs.resultCacheTTL(goodsId) > 0 {
+		if orderId, found := gs.cachedSeckillResult(userId, goodsId); found {
+			return orderId, true, nil
+		}
+	}
+
+	stockReserved, err := gs.admitSeckillToken(userId, goodsId, tokenId)
+	if err != nil {
+		return "", errors.Is(err, errs.ErrSoldOut), err
+	}
+	orderId, err := gs.placeSeckillOrder(userId, goodsId, tokenId, stockReserved)
+	return orderId, true, err
+}
+
+// admitSeckillToken 校验并消费秒杀令牌，返回令牌是否仍持有预占的库存
+// 已确认售罄的商品直接拒绝且不消费令牌；开启库存预占时售罄不影响已签发令牌兑换，不做该检查
+func (gs *GoodService) admitSeckillToken(userId, goodsId int64, tokenId string) (bool, error) {
+	// 已确认售罄的商品直接拒绝，不消费令牌，也不获取分布式锁
+	if !gs.Seckill.ReserveStock && gs.soldOutCached(goodsId) {
+		gs.logSoldOut("Goods sold out, seckill refused from cache", goodsId,
+			"user_id", userId,
+		)
+		return false, errs.ErrSoldOut
+	}
+
+	// 验证令牌有效性
+	valid, stockReserved, err := gs.redeemSeckillToken(tokenId, userId, goodsId)
+	if err != nil || !valid {
+		slog.Warn("Invalid seckill token",
+			"token_id_prefix", model.TokenPrefix(tokenId),
+			"user_id", userId,
+			"goods_id", goodsId,
+			"error", err,
+		)
+		if err == nil {
+			err = errs.ErrTokenNotFound // 令牌不存在或已被其他请求消费
+		}
+		return false, fmt.Errorf("invalid seckill token: %w", err)
+	}
+	return stockReserved, nil
+}
+
+// placeSeckillOrder 在用户级分布式锁内为已通过令牌校验的请求下单，同步和异步秒杀共用
+// tokenId只用于日志，可以传入令牌前缀；stockReserved为true时使用令牌预占的库存下单，未进入下单流程就返回时归还该库存
+func (gs *GoodService) placeSeckillOrder(userId, goodsId int64, tokenId string, stockReserved bool) (string, error) {
+	ordering := false
+	if stockReserved {
+		defer func() {
+			if !ordering {
+				gs.releaseReservedStock(goodsId) // 下单流程失败时已自行归还
+			}
+		}()
 	}
 
 	// 改进分布式锁机制，避免死锁和锁竞争问题
-	lockKey := fmt.Sprintf("seckill_user_%d", userId)
+	lockKey := gs.lockKey(fmt.Sprintf("seckill_user_%d", userId))
+
+	// 使用独立的context获取锁
+	lockCtx, lockCancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer lockCancel()
+
+	// 持锁期间自动续期，下单事务耗时超过TTL时锁也不会提前过期
+	locker := gs.locker(config.LockCategorySeckill)
+	lockToken, locked, stopRenewal, err := acquireRenewableLock(lockCtx, locker, lockKey, 10)
+	if err != nil {
+		slog.Error("Failed to acquire distributed lock for seckill",
+			"user_id", userId,
+			"goods_id", goodsId,
+			"error", err,
+		)
+		return "", fmt.Errorf("%w: failed to acquire lock: %v", errs.ErrSystemBusy, err)
+	}
+	if !locked {
+		slog.Warn("Distributed lock acquisition failed for seckill",
+			"user_id", userId,
+			"goods_id", goodsId,
+		)
+		return "", errs.ErrSystemBusy
+	}
+
+	// 使用新的context执行业务逻辑，避免锁过期影响业务
+	businessCtx := context.Background()
+	defer func() {
+		// 先停止续期再使用新的context释放锁
+		stopRenewal()
+		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer releaseCancel()
+		if releaseErr := locker.ReleaseDistributedLock(releaseCtx, lockKey, lockToken); releaseErr != nil {
+			slog.Warn("Failed to release distributed lock after seckill",
+				"user_id", userId,
+				"goods_id", goodsId,
+				"error", releaseErr,
+			)
+		}
+	}()
+
+	// 获取锁后再次检查，防止并发的重复提交使用不同令牌重复扣减库存
+	cacheTTL := gs.resultCacheTTL(goodsId)
+	if cacheTTL > 0 {
+		if orderId, found := gs.cachedSeckillResult(userId, goodsId); found {
+			return orderId, nil
+		}
+	}
+
+	ordering = true
+	orderId, err := gs.createSeckillOrder(businessCtx, userId, goodsId, stockReserved)
+	if errors.Is(err, errs.ErrSoldOut) {
+		// 乐观锁冲突也返回售罄，只有Redis库存确实为0时才标记售罄
+		if stock, stockErr := gs.SeckillHandler.CheckStock(businessCtx, goodsId); stockErr == nil && stock <= 0 {
+			gs.markSoldOut(goodsId)
+		}
+		gs.logSoldOut("Seckill failed", goodsId,
+			"user_id", userId,
+			"outcome", errs.Outcome(err),
+			"token_id_prefix", model.TokenPrefix(tokenId),
+			"error", err,
+		)
+		return "", err
+	}
+	if err != nil {
+		// 售罄等预期的业务结果不按错误记录，避免污染错误监控
+		slog.Log(context.Background(), errs.LogLevel(err), "Seckill failed",
+			"user_id", userId,
+			"outcome", errs.Outcome(err),
+			"goods_id", goodsId,
+			"token_id_prefix", model.TokenPrefix(tokenId),
+			"error", err,
+		)
+		return "", err // 错误已由下单流程说明原因，不再重复包装
+	}
+
+	if cacheTTL > 0 {
+		if err := gs.RedisRepo.SetSeckillResult(userId, goodsId, orderId, cacheTTL); err != nil {
+			slog.Warn("Failed to cache seckill result",
+				"user_id", userId,
+				"goods_id", goodsId,
+				"error", err,
+			)
+		}
+	}
+
+	slog.Info("Seckill successful",
+		"user_id", userId,
+		"goods_id", goodsId,
+		"order_id", orderId,
+		"token_id_prefix", model.TokenPrefix(tokenId),
+	)
+	return orderId, nil
+}
+
+// createSeckillOrder 按商品下单模式创建一件商品的订单，每个令牌购买一件
+// stockReserved为true时使用令牌预占的Redis库存，不再扣减
+func (gs *GoodService) createSeckillOrder(ctx context.Context, userId, goodsId int64, stockReserved bool) (string, error) {
+	redisOnly := gs.Seckill.ModeFor(goodsId) == config.SeckillModeRedis // 仅扣减Redis库存，订单异步写库
+	switch {
+	case stockReserved && redisOnly:
+		return gs.SeckillHandler.CreateReservedOrderRedisOnly(ctx, userId, goodsId)
+	case stockReserved:
+		return gs.SeckillHandler.CreateReservedOrder(ctx, userId, goodsId)
+	case redisOnly:
+		return gs.SeckillHandler.CreateOrderRedisOnly(ctx, userId, goodsId, 1)
+	default:
+		return gs.SeckillHandler.CreateOrder(ctx, userId, goodsId, 1)
+	}
+}
+
+// soldOutCached 商品是否已在本实例确认售罄，未开启售罄缓存时总是返回false
+func (gs *GoodService) soldOutCached(goodsId int64) bool {
+	return gs.Seckill.SoldOutCacheTTL() > 0 && gs.soldOut.IsSoldOut(goodsId, time.Now())
+}
+
+// markSoldOut 在确认Redis库存为0后标记商品售罄，未开启售罄缓存时不标记
+func (gs *GoodService) markSoldOut(goodsId int64) {
+	if ttl := gs.Seckill.SoldOutCacheTTL(); ttl > 0 {
+		gs.soldOut.Mark(goodsId, time.Now().Add(ttl))
+	}
+}
 
-	// 使用独立的context获取锁
-	lockCtx, lockCancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer lockCancel()
+// clearSoldOut 清除商品的售罄标记，在本实例归还或重新写入Redis库存后调用
+func (gs *GoodService) clearSoldOut(goodsId int64) {
+	gs.soldOut.Clear(goodsId)
+}
+
+// logSoldOut 按采样间隔记录售罄拒绝日志，rejections为本轮售罄以来的累计拒绝次数
+func (gs *GoodService) logSoldOut(msg string, goodsId int64, args ...any) {
+	sampled, rejections := gs.soldOut.Sample(goodsId, gs.Seckill.SoldOutLogEvery())
+	if !sampled {
+		return
+	}
+	slog.Info(msg, append([]any{"goods_id", goodsId, "rejections", rejections}, args...)...)
+}
 
-	locked, err := gs.EtcdRepo.GetDistributedLock(lockCtx, lockKey, 10) // 延长TTL到10秒
+// resultCacheTTL 返回商品秒杀结果的缓存时间，0表示不缓存
+// 结果缓存按用户和商品去重，活动限购多件时同一用户的后续购买是合法请求，不缓存；读取限购失败时按限购1件处理
+func (gs *GoodService) resultCacheTTL(goodsId int64) time.Duration {
+	ttl := gs.Seckill.ResultCacheTTL()
+	if ttl <= 0 {
+		return 0
+	}
+	limit, err := gs.SeckillHandler.PurchaseLimit(goodsId)
 	if err != nil {
-		slog.Error("Failed to acquire distributed lock for seckill",
-			"user_id", userId,
+		slog.Warn("Failed to read purchase limit for seckill result cache",
 			"goods_id", goodsId,
 			"error", err,
 		)
-		return "", fmt.Errorf("system busy, failed to acquire lock: %v", err)
+		return ttl
 	}
-	if !locked {
-		slog.Warn("Distributed lock acquisition failed for seckill",
-			"user_id", userId,
-			"goods_id", goodsId,
-		)
-		return "", errors.New("system busy, please try again")
+	if limit > 1 {
+		return 0
 	}
+	return ttl
+}
 
-	// 使用新的context执行业务逻辑，避免锁过期影响业务
-	businessCtx := context.Background()
-	defer func() {
-		// 使用新的context释放锁
-		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer releaseCancel()
-		if releaseErr := gs.EtcdRepo.ReleaseDistributedLock(releaseCtx, lockKey); releaseErr != nil {
-			slog.Warn("Failed to release distributed lock after seckill",
-				"user_id", userId,
-				"goods_id", goodsId,
-				"error", releaseErr,
-			)
-		}
-	}()
-
-	orderId, err := gs.SeckillHandler.CreateOrder(businessCtx, userId, goodsId)
+// cachedSeckillResult 获取缓存时间窗口内用户已秒杀成功的订单ID，调用前需确认商品开启了结果缓存
+// 读取失败时返回found=false，按正常流程处理
+func (gs *GoodService) cachedSeckillResult(userId, goodsId int64) (string, bool) {
+	orderId, found, err := gs.RedisRepo.GetSeckillResult(userId, goodsId)
 	if err != nil {
-		slog.Error("Seckill failed",
+		slog.Warn("Failed to read seckill result cache",
 			"user_id", userId,
 			"goods_id", goodsId,
-			"token_id_prefix", tokenId[:8],
 			"error", err,
 		)
-		return "", fmt.Errorf("seckill failed: %v", err)
+		return "", false
 	}
-
-	slog.Info("Seckill successful",
-		"user_id", userId,
-		"goods_id", goodsId,
-		"order_id", orderId,
-		"token_id_prefix", tokenId[:8],
-	)
-	return orderId, nil
+	if found {
+		slog.Info("Duplicate seckill submission, returning cached result",
+			"user_id", userId,
+			"goods_id", goodsId,
+			"order_id", orderId,
+		)
+	}
+	return orderId, found
 }
 
 // SimulatePayment 模拟支付
@@ -564,43 +2026,19 @@ func (gs *GoodService) SimulatePayment(orderId string, success bool) error {
 	return nil
 }
 
-// StartOrderConsumer 启动订单消息消费者
+// StartOrderConsumer 启动订单消息消费者，服务生命周期上下文取消时退出
 func (gs *GoodService) StartOrderConsumer() {
+	ctx := gs.lifecycleContext()
+	gs.consumers.Add(1)
 	go func() {
+		defer gs.consumers.Done()
 		slog.Info("Starting order message consumer...")
 		// 消费订单消息
-		err := gs.KafkaRepo.ConsumeOrderMessages(context.Background(), func(order model.OrderMessage) error {
-			slog.Info("Processing order message from Kafka",
-				"order_id", order.OrderId,
-				"user_id", order.UserId,
-				"goods_id", order.GoodsId,
-				"status", order.Status,
-				"price", order.Price,
-			)
-
-			// 根据订单状态处理
-			switch order.Status {
-			case model.OrderStatusCreated:
-				// 订单创建成功处理
-				slog.Info("Order created, triggering follow-up actions",
-					"order_id", order.OrderId,
-				)
-
-			case model.OrderStatusPaid:
-				// 支付成功处理
-				slog.Info("Order paid, updating order status",
-					"order_id", order.OrderId,
-				)
-
-			case model.OrderStatusPaymentFailed:
-				// 支付失败处理
-				slog.Warn("Order payment failed, need to restore stock",
-					"order_id", order.OrderId,
-				)
-			}
-
-			return nil
-		})
+		err := gs.KafkaRepo.ConsumeOrderMessages(ctx, gs.HandleOrderMessage)
+		if errors.Is(err, context.Canceled) {
+			slog.Info("Order consumer stopped")
+			return
+		}
 		if err != nil {
 			slog.Error("Order consumer failed",
 				"error", err,
@@ -609,34 +2047,64 @@ func (gs *GoodService) StartOrderConsumer() {
 	}()
 }
 
-// StartPaymentConsumer 启动支付消息消费者
+// HandleOrderMessage 处理订单消息
+func (gs *GoodService) HandleOrderMessage(order model.OrderMessage) error {
+	gs.inflight.Add(1)
+	defer gs.inflight.Done()
+	defer gs.ordersProcessed.Add(1)
+
+	slog.Info("Processing order message from Kafka",
+		"order_id", order.OrderId,
+		"user_id", order.UserId,
+		"goods_id", order.GoodsId,
+		"status", order.Status,
+		"price", order.Price,
+	)
+
+	// 根据订单状态处理
+	switch order.Status {
+	case model.OrderStatusCreated:
+		// 订单创建成功处理
+		slog.Info("Order created, triggering follow-up actions",
+			"order_id", order.OrderId,
+		)
+
+	case model.OrderStatusPaid:
+		// 支付成功处理
+		slog.Info("Order paid, updating order status",
+			"order_id", order.OrderId,
+		)
+
+	case model.OrderStatusPaymentFailed:
+		// 支付失败处理
+		slog.Warn("Order payment failed, need to restore stock",
+			"order_id", order.OrderId,
+		)
+	}
+
+	return nil
+}
+
+// StartPaymentConsumer 启动支付消息消费者，服务生命周期上下文取消时退出
 func (gs *GoodService) StartPaymentConsumer() {
+	ctx := gs.lifecycleContext()
+	gs.consumers.Add(1)
 	go func() {
+		defer gs.consumers.Done()
 		slog.Info("Starting payment message consumer...")
 		// 消费支付消息
-		err := gs.KafkaRepo.ConsumePaymentMessages(context.Background(), func(orderId string, status int32) error {
+		err := gs.KafkaRepo.ConsumePaymentMessages(ctx, func(orderId string, status int32) error {
 			slog.Info("Processing payment message from Kafka",
 				"order_id", orderId,
 				"status", status,
 			)
 
-			// 根据支付状态处理
-			switch status {
-			case model.OrderStatusPaid:
-				// 支付成功处理
-				slog.Info("Payment successful",
-					"order_id", orderId,
-				)
-
-			case model.OrderStatusPaymentFailed:
-				// 支付失败处理
-				slog.Warn("Payment failed, restoring stock",
-					"order_id", orderId,
-				)
-			}
-
-			return nil
+			return gs.HandlePaymentResult(orderId, status)
 		})
+		if errors.Is(err, context.Canceled) {
+			slog.Info("Payment consumer stopped")
+			return
+		}
 		if err != nil {
 			slog.Error("Payment consumer failed",
 				"error", err,
@@ -645,6 +2113,455 @@ func (gs *GoodService) StartPaymentConsumer() {
 	}()
 }
 
+// 支付失败宽限期扫描相关常量
+const (
+	paymentRetrySweepInterval = time.Second // 扫描间隔
+	paymentRetrySweepBatch    = 100         // 单次扫描最多取消的订单数
+)
+
+// HandlePaymentResult 处理支付结果消息
+// 支付失败时订单先进入等待重试状态，宽限期内收到支付成功消息则保留订单，否则由扫描任务取消
+func (gs *GoodService) HandlePaymentResult(orderId string, status int32) error {
+	gs.inflight.Add(1)
+	defer gs.inflight.Done()
+	defer gs.paymentsProcessed.Add(1)
+
+	switch status {
+	case model.OrderStatusPaid:
+		pending, err := gs.RedisRepo.ClearPaymentPendingRetry(orderId)
+		if err != nil {
+			return err
+		}
+		if err := gs.markOrderPaid(orderId); err != nil {
+			slog.Log(context.Background(), errs.LogLevel(err), "Failed to mark order paid",
+				"order_id", orderId,
+				"outcome", errs.Outcome(err),
+				"error", err,
+			)
+			return err
+		}
+		if pending {
+			slog.Info("Payment succeeded within grace period, order kept",
+				"order_id", orderId,
+			)
+			return nil
+		}
+		slog.Info("Payment successful",
+			"order_id", orderId,
+		)
+
+	case model.OrderStatusPaymentFailed:
+		if gs.PaymentGrace <= 0 {
+			gs.cancelUnpaidOrder(orderId)
+			return nil
+		}
+		deadline := time.Now().Add(gs.PaymentGrace)
+		if err := gs.RedisRepo.MarkPaymentPendingRetry(orderId, deadline); err != nil {
+			return err
+		}
+		slog.Warn("Payment failed, order pending retry",
+			"order_id", orderId,
+			"status", model.OrderStatusPaymentPendingRetry,
+			"cancel_at", deadline,
+		)
+	}
+	return nil
+}
+
+// SweepPaymentRetries 取消宽限期已过且未收到支付成功消息的订单，返回本次取消的订单ID
+func (gs *GoodService) SweepPaymentRetries(now time.Time) ([]string, error) {
+	orderIds, err := gs.RedisRepo.ClaimExpiredPaymentRetries(now, paymentRetrySweepBatch)
+	for _, orderId := range orderIds {
+		gs.cancelUnpaidOrder(orderId)
+	}
+	if err != nil {
+		slog.Error("Failed to sweep payment retries",
+			"cancelled", len(orderIds),
+			"error", err,
+		)
+	}
+	return orderIds, err
+}
+
+// StartPaymentRetrySweeper 启动支付失败宽限期到期扫描，未配置宽限期时不启动，服务生命周期上下文取消时退出
+func (gs *GoodService) StartPaymentRetrySweeper() {
+	if gs.PaymentGrace <= 0 {
+		return
+	}
+	ctx := gs.lifecycleContext()
+	gs.consumers.Add(1)
+	go func() {
+		defer gs.consumers.Done()
+		slog.Info("Starting payment retry sweeper...",
+			"grace", gs.PaymentGrace,
+		)
+		ticker := time.NewTicker(paymentRetrySweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("Payment retry sweeper stopped")
+				return
+			case now := <-ticker.C:
+				gs.SweepPaymentRetries(now)
+			}
+		}
+	}()
+}
+
+// stockRefreshPageSize 库存校准每次查询的进行中活动数
+const stockRefreshPageSize = 100
+
+// RefreshStockCache 以数据库库存校准进行中活动的Redis库存，返回各商品的校准结果
+// 目标库存为促销库存减去Redis-only模式尚未写库的数量；库存键被淘汰时重新写入，缓存高于目标值时下调，
+// 缓存低于目标值可能来自尚未落库的扣减，保留不变。多实例通过维护锁协调，未获取到锁时跳过本轮
+func (gs *GoodService) RefreshStockCache(now time.Time) (map[int64]string, error) {
+	lockKey := gs.lockKey("stock_refresh_lock")
+	locker := gs.locker(config.LockCategoryMaintenance)
+	lockToken, locked, err := locker.GetDistributedLock(context.Background(), lockKey, 30) // 30秒超时
+	if err != nil || !locked {
+		slog.Info("Stock refresh lock held by another instance, skipped",
+			"error", err,
+		)
+		return nil, err
+	}
+	defer locker.ReleaseDistributedLock(context.Background(), lockKey, lockToken)
+
+	results := make(map[int64]string)
+	for offset := 0; ; offset += stockRefreshPageSize {
+		promotions, total, err := gs.GoodDB.ListActivePromotions(now, offset, stockRefreshPageSize)
+		if err != nil {
+			return results, err
+		}
+		// 先读数据库库存再读待写库数量：期间写库完成的订单只会使目标值偏高，不会误下调缓存
+		pending, err := gs.RedisRepo.PendingOrderQuantities()
+		if err != nil {
+			return results, err
+		}
+		for _, promotion := range promotions {
+			// 先归还过期未兑换令牌预占的库存，预占不影响数据库库存，校准只会下调缓存
+			if released, err := gs.RedisRepo.ReleaseExpiredReservations(promotion.GoodsId, now); err != nil {
+				slog.Warn("Failed to release expired stock reservations",
+					"goods_id", promotion.GoodsId,
+					"error", err,
+				)
+			} else if released > 0 {
+				gs.clearSoldOut(promotion.GoodsId)
+			}
+			target := max(promotion.PsCount-pending[promotion.GoodsId], 0)
+			result, err := gs.RedisRepo.ReconcileStock(promotion.GoodsId, target)
+			if err != nil {
+				slog.Error("Failed to reconcile goods stock",
+					"goods_id", promotion.GoodsId,
+					"target", target,
+					"error", err,
+				)
+				continue
+			}
+			results[promotion.GoodsId] = result
+			if result == repository.StockReconcileRepopulated {
+				gs.clearSoldOut(promotion.GoodsId) // 库存键被淘汰后重新写入
+			}
+		}
+		if len(promotions) == 0 || int64(offset+len(promotions)) >= total {
+			break
+		}
+	}
+	return results, nil
+}
+
+// StartStockRefresher 启动Redis库存定期校准任务，未配置校准间隔时不启动，服务生命周期上下文取消时退出
+func (gs *GoodService) StartStockRefresher() {
+	interval := gs.Seckill.StockRefreshInterval()
+	if interval <= 0 {
+		return
+	}
+	ctx := gs.lifecycleContext()
+	gs.consumers.Add(1)
+	go func() {
+		defer gs.consumers.Done()
+		slog.Info("Starting stock cache refresher...",
+			"interval", interval,
+		)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("Stock cache refresher stopped")
+				return
+			case now := <-ticker.C:
+				if _, err := gs.RefreshStockCache(now); err != nil {
+					slog.Error("Failed to refresh stock cache",
+						"error", err,
+					)
+				}
+			}
+		}
+	}()
+}
+
+// 活动结束事件扫描相关常量
+const (
+	lifecycleSweepInterval = 10 * time.Second // 扫描间隔
+	lifecycleSweepLookback = 24 * time.Hour   // 回看窗口，覆盖服务停机期间结束的活动
+)
+
+// StartLifecycleWatcher 启动活动结束事件扫描任务，服务生命周期上下文取消时退出
+func (gs *GoodService) StartLifecycleWatcher() {
+	ctx := gs.lifecycleContext()
+	gs.consumers.Add(1)
+	go func() {
+		defer gs.consumers.Done()
+		slog.Info("Starting activity lifecycle watcher...",
+			"interval", lifecycleSweepInterval,
+		)
+		ticker := time.NewTicker(lifecycleSweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("Activity lifecycle watcher stopped")
+				return
+			case now := <-ticker.C:
+				if _, err := gs.EmitEndedActivities(now); err != nil {
+					slog.Error("Failed to emit ended activity events",
+						"error", err,
+					)
+				}
+			}
+		}
+	}()
+}
+
+// EmitEndedActivities 为回看窗口内已到结束时间的活动触发结束事件，返回本次触发的数量
+// 事件通过Redis标记去重，多实例同时扫描或重复扫描同一活动只触发一次
+func (gs *GoodService) EmitEndedActivities(now time.Time) (int, error) {
+	promotions, err := gs.GoodDB.ListPromotionsEndedBetween(now.Add(-lifecycleSweepLookback), now)
+	if err != nil || len(promotions) == 0 {
+		return 0, err
+	}
+
+	goodsIds := make([]int64, len(promotions))
+	for i, promotion := range promotions {
+		goodsIds[i] = promotion.GoodsId
+	}
+	stocks, err := gs.RedisRepo.GetGoodsStockBatch(goodsIds)
+	if err != nil {
+		return 0, err
+	}
+
+	emitted := 0
+	for _, goodsId := range goodsIds {
+		if gs.emitLifecycleEvent(model.LifecycleEnded, goodsId, stocks[goodsId]) {
+			emitted++
+		}
+	}
+	return emitted, nil
+}
+
+// pendingOrderFlushBatch Redis-only模式每轮最多写库的订单数
+const pendingOrderFlushBatch = 500
+
+// StartPendingOrderFlusher 启动Redis-only模式订单写库任务
+// 未配置任何Redis-only商品时不启动；服务生命周期上下文取消时再写库一次后退出，关闭前已受理的订单尽量落库
+func (gs *GoodService) StartPendingOrderFlusher() {
+	if !gs.Seckill.UsesRedisMode() {
+		return
+	}
+	ctx := gs.lifecycleContext()
+	gs.consumers.Add(1)
+	go func() {
+		defer gs.consumers.Done()
+		interval := gs.Seckill.FlushInterval()
+		slog.Info("Starting pending order flusher...",
+			"interval", interval,
+		)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				gs.flushPendingOrders()
+				slog.Info("Pending order flusher stopped")
+				return
+			case <-ticker.C:
+				gs.flushPendingOrders()
+			}
+		}
+	}()
+}
+
+// flushPendingOrders 写入一批Redis-only模式的待写库订单，失败只记录日志
+// 使用独立的context，关闭时生命周期上下文已取消，最后一次写库仍需执行
+func (gs *GoodService) flushPendingOrders() {
+	if _, err := gs.SeckillHandler.FlushPendingOrders(context.Background(), pendingOrderFlushBatch); err != nil {
+		slog.Error("Failed to flush pending orders",
+			"error", err,
+		)
+	}
+}
+
+// cancelUnpaidOrder 取消支付失败的订单并归还数据库和Redis库存
+// 以订单状态保证幂等：重放的消息遇到已取消的订单不会重复归还库存，订单已支付时跳过
+func (gs *GoodService) cancelUnpaidOrder(orderId string) {
+	slog.Warn("Payment failed, cancelling order",
+		"order_id", orderId,
+		"status", model.OrderStatusCancelled,
+	)
+	userId, goodsId, err := handler.ParseOrderId(orderId)
+	if err != nil {
+		slog.Error("Failed to cancel order",
+			"order_id", orderId,
+			"error", err,
+		)
+		return
+	}
+	if _, err := gs.cancelOrder(orderId, userId, goodsId); err != nil {
+		slog.Log(context.Background(), errs.LogLevel(err), "Failed to cancel order",
+			"order_id", orderId,
+			"outcome", errs.Outcome(err),
+			"error", err,
+		)
+	}
+}
+
+// cancelOrder 在独立事务中取消未支付的订单，并按订单件数同步归还Redis库存，返回本次是否取消了订单
+// 未记录订单ID的历史订单按用户ID和商品ID匹配
+func (gs *GoodService) cancelOrder(orderId string, userId, goodsId int64) (bool, error) {
+	var quantity int64
+	if err := gs.withPendingOrderFlushed(orderId, func() error {
+		return gs.GoodDB.WithTransaction(func(tx *gorm.DB) error {
+			var txErr error
+			quantity, txErr = gs.GoodDB.CancelUnpaidOrder(tx, orderId, userId, goodsId)
+			return txErr
+		})
+	}); err != nil || quantity == 0 {
+		return false, err
+	}
+	gs.invalidatePromotion(goodsId) // 版本号已变更
+
+	// 数据库已归还库存，同步归还Redis库存；订单已取消的重复消息不会走到这里
+	if _, err := gs.RedisRepo.IncrGoodsStockBy(goodsId, quantity); err != nil {
+		slog.Error("Failed to restore redis stock after order cancellation",
+			"user_id", userId,
+			"goods_id", goodsId,
+			"error", err,
+		)
+	}
+	gs.clearSoldOut(goodsId)
+	return true, nil
+}
+
+// markOrderPaid 将订单标记为已支付，订单已取消时返回ErrInvalidOrderTransition
+func (gs *GoodService) markOrderPaid(orderId string) error {
+	userId, goodsId, err := handler.ParseOrderId(orderId)
+	if err != nil {
+		return err
+	}
+	return gs.withPendingOrderFlushed(orderId, func() error {
+		return gs.GoodDB.WithTransaction(func(tx *gorm.DB) error {
+			_, err := gs.GoodDB.TransitionOrderState(tx, orderId, userId, goodsId, model.OrderStatePaid)
+			return err
+		})
+	})
+}
+
+// withPendingOrderFlushed 执行订单的数据库操作，订单不存在时先将Redis-only模式待写库队列中的该订单写库，再重试一次
+// 支付结果可能早于定时写库任务到达；订单不在队列中时也重试一次，写库任务可能刚好并发写入了该订单
+func (gs *GoodService) withPendingOrderFlushed(orderId string, op func() error) error {
+	err := op()
+	if !errors.Is(err, errs.ErrOrderNotFound) || gs.SeckillHandler == nil {
+		return err
+	}
+	if _, flushErr := gs.SeckillHandler.FlushPendingOrder(context.Background(), orderId); flushErr != nil {
+		return fmt.Errorf("flush pending order %s failed: %w", orderId, flushErr)
+	}
+	return op()
+}
+
+// Shutdown 优雅关闭服务，start为开始关闭的时间
+// 取消生命周期上下文停止Kafka消费循环和定时任务（订单写库任务退出前再写库一次），等待其退出和处理中的消息完成（受ctx超时限制），
+// 统计未完成的工作，同步日志并输出关闭摘要。需在关闭Kafka客户端之前调用
+func (gs *GoodService) Shutdown(ctx context.Context, start time.Time) model.ShutdownSummary {
+	summary := model.ShutdownSummary{
+		OutboxPending:         -1,
+		PaymentRetriesPending: -1,
+	}
+
+	// 停止消费循环，不再读取新消息
+	gs.lifecycleContext()
+	gs.cancel()
+	if summary.ConsumersStopped = waitGroupWithin(ctx, &gs.consumers); !summary.ConsumersStopped {
+		slog.Warn("Timed out waiting for Kafka consumers to stop",
+			"error", ctx.Err(),
+		)
+	}
+
+	// 等待处理中的消息完成
+	if summary.ConsumersDrained = waitGroupWithin(ctx, &gs.inflight); !summary.ConsumersDrained {
+		slog.Warn("Timed out waiting for in-flight messages",
+			"error", ctx.Err(),
+		)
+	}
+	summary.OrdersProcessed = gs.ordersProcessed.Load()
+	summary.PaymentsProcessed = gs.paymentsProcessed.Load()
+
+	// 统计未完成的工作
+	if pending, err := gs.RedisRepo.OrderOutboxLen(); err != nil {
+		slog.Error("Failed to get outbox length on shutdown",
+			"error", err,
+		)
+	} else {
+		summary.OutboxPending = pending
+	}
+	if pending, err := gs.RedisRepo.PaymentPendingRetryCount(); err != nil {
+		slog.Error("Failed to get payment retry count on shutdown",
+			"error", err,
+		)
+	} else {
+		summary.PaymentRetriesPending = pending
+	}
+
+	// 先同步此前的日志，再输出摘要
+	if err := config.FlushLogger(); err != nil {
+		slog.Error("Failed to flush logs on shutdown",
+			"error", err,
+		)
+	} else {
+		summary.LogFlushed = true
+	}
+	summary.Duration = time.Since(start)
+
+	slog.Info("Shutdown summary",
+		"orders_processed", summary.OrdersProcessed,
+		"payments_processed", summary.PaymentsProcessed,
+		"consumers_stopped", summary.ConsumersStopped,
+		"consumers_drained", summary.ConsumersDrained,
+		"outbox_pending", summary.OutboxPending,
+		"payment_retries_pending", summary.PaymentRetriesPending,
+		"log_flushed", summary.LogFlushed,
+		"duration", summary.Duration,
+	)
+	config.FlushLogger() // 确保摘要本身落盘
+	return summary
+}
+
+// waitGroupWithin 等待wg完成，ctx超时前完成返回true
+func waitGroupWithin(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 // ResetDataBase 重置数据库
 func (gs *GoodService) ResetDataBase(goodsId int) error {
 	err := gs.GoodDB.ResetDataBase(goodsId)
@@ -655,9 +2572,32 @@ func (gs *GoodService) ResetDataBase(goodsId int) error {
 		)
 		return err
 	}
+	gs.invalidatePromotion(int64(goodsId)) // 重置后版本号归零
 
 	slog.Info("Database reset successfully",
 		"goods_id", goodsId,
 	)
 	return nil
 }
+
+// ResetDataBaseBatch 批量重置数据库
+func (gs *GoodService) ResetDataBaseBatch(goodsIds []int) ([]model.ResetResult, error) {
+	results, err := gs.GoodDB.ResetDataBaseBatch(goodsIds)
+	if err != nil {
+		slog.Error("Failed to reset database in batch",
+			"goods_ids", goodsIds,
+			"error", err,
+		)
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Success {
+			gs.invalidatePromotion(int64(result.GoodsId))
+		}
+	}
+
+	slog.Info("Database reset in batch",
+		"goods_ids", goodsIds,
+	)
+	return results, nil
+}
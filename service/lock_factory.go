@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+	"seckill_system/config"
+	"seckill_system/repository"
+)
+
+// LockFactory 分布式锁工厂，按锁分类返回配置的锁后端
+type LockFactory struct {
+	cfg   config.LockConfig            // 各分类锁后端配置
+	etcd  repository.DistributedLocker // Etcd锁实现
+	redis repository.DistributedLocker // Redis锁实现
+}
+
+// NewLockFactory 创建分布式锁工厂，配置的后端无效时返回错误
+func NewLockFactory(cfg config.LockConfig, etcd, redis repository.DistributedLocker) (*LockFactory, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if etcd == nil || redis == nil {
+		return nil, fmt.Errorf("lock factory requires both etcd and redis lockers")
+	}
+	return &LockFactory{cfg: cfg, etcd: etcd, redis: redis}, nil
+}
+
+// Locker 返回指定分类使用的分布式锁
+func (f *LockFactory) Locker(category string) repository.DistributedLocker {
+	if f.cfg.Backend(category) == config.LockBackendRedis {
+		return f.redis
+	}
+	return f.etcd
+}
+
+// Key 返回带命名空间前缀的锁键
+func (f *LockFactory) Key(name string) string {
+	return f.cfg.Key(name)
+}
+
+// Backend 返回指定分类使用的锁后端名称
+func (f *LockFactory) Backend(category string) string {
+	return f.cfg.Backend(category)
+}
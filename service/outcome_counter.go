@@ -0,0 +1,41 @@
+package service
+
+import (
+	"seckill_system/errs"
+	"sync"
+)
+
+// OutcomeCounter 按操作和结果分类统计请求数，售罄、限流等业务结果与故障分开计数
+// 零值可直接使用
+type OutcomeCounter struct {
+	mu     sync.Mutex
+	counts map[string]map[string]int64 // 操作 -> 结果分类 -> 次数
+}
+
+// Record 按错误对应的结果分类记录一次操作，err为nil表示成功
+func (c *OutcomeCounter) Record(action string, err error) {
+	outcome := errs.Outcome(err)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]map[string]int64)
+	}
+	if c.counts[action] == nil {
+		c.counts[action] = make(map[string]int64)
+	}
+	c.counts[action][outcome]++
+}
+
+// Snapshot 返回当前各操作各结果分类的计数副本
+func (c *OutcomeCounter) Snapshot() map[string]map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := make(map[string]map[string]int64, len(c.counts))
+	for action, outcomes := range c.counts {
+		snapshot[action] = make(map[string]int64, len(outcomes))
+		for outcome, count := range outcomes {
+			snapshot[action][outcome] = count
+		}
+	}
+	return snapshot
+}
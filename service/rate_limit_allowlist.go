@@ -0,0 +1,109 @@
+package service
+
+import (
+	"log/slog"
+	"net"
+	"seckill_system/model"
+	"strings"
+	"sync"
+)
+
+// RateLimitAllowlist 限流豁免名单缓存，名单内的用户或IP跳过限流检查
+// 启动时以配置文件为基础，Etcd中存在名单时以Etcd为准，并随Etcd变更实时更新
+type RateLimitAllowlist struct {
+	mu       sync.RWMutex
+	fallback model.RateLimitAllowlist // 配置文件中的名单，Etcd名单被删除时恢复使用
+	userIds  map[int64]struct{}       // 豁免的用户ID
+	ips      map[string]struct{}      // 豁免的单个IP
+	networks []*net.IPNet             // 豁免的IP网段
+}
+
+// NewRateLimitAllowlist 创建限流豁免名单缓存，fallback为配置文件中的名单
+func NewRateLimitAllowlist(fallback model.RateLimitAllowlist) *RateLimitAllowlist {
+	a := &RateLimitAllowlist{fallback: fallback}
+	a.Update(nil)
+	return a
+}
+
+// Update 替换缓存中的名单，传入nil时恢复为配置文件中的名单
+func (a *RateLimitAllowlist) Update(allowlist *model.RateLimitAllowlist) {
+	if allowlist == nil {
+		allowlist = &a.fallback
+	}
+
+	userIds := make(map[int64]struct{}, len(allowlist.UserIds))
+	for _, userId := range allowlist.UserIds {
+		userIds[userId] = struct{}{}
+	}
+
+	ips := make(map[string]struct{}, len(allowlist.IPs))
+	var networks []*net.IPNet
+	for _, entry := range allowlist.IPs {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				slog.Warn("Ignoring invalid allowlist network",
+					"network", entry,
+					"error", err,
+				)
+				continue
+			}
+			networks = append(networks, network)
+			continue
+		}
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			slog.Warn("Ignoring invalid allowlist ip",
+				"ip", entry,
+			)
+			continue
+		}
+		ips[ip.String()] = struct{}{}
+	}
+
+	a.mu.Lock()
+	a.userIds, a.ips, a.networks = userIds, ips, networks
+	a.mu.Unlock()
+
+	slog.Info("Rate limit allowlist loaded",
+		"user_ids", len(userIds),
+		"ips", len(ips),
+		"networks", len(networks),
+	)
+}
+
+// Allows 判断用户或客户端IP是否在豁免名单中，clientIP为空时只检查用户
+func (a *RateLimitAllowlist) Allows(userId int64, clientIP string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if _, ok := a.userIds[userId]; ok {
+		return true
+	}
+	return a.allowsIP(clientIP)
+}
+
+// AllowsIP 判断客户端IP是否在豁免名单中
+func (a *RateLimitAllowlist) AllowsIP(clientIP string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.allowsIP(clientIP)
+}
+
+// allowsIP 判断客户端IP是否在豁免名单中，调用方需持有读锁
+func (a *RateLimitAllowlist) allowsIP(clientIP string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	if _, ok := a.ips[ip.String()]; ok {
+		return true
+	}
+	for _, network := range a.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
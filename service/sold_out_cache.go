@@ -0,0 +1,61 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// SoldOutCache 记录本实例已确认售罄的商品，并对售罄拒绝日志按商品采样
+// 售罄标记到期后自动失效，其他实例归还的库存最迟在到期后可见；本实例归还库存时通过Clear立即失效
+// 零值可直接使用
+type SoldOutCache struct {
+	mu         sync.Mutex
+	until      map[int64]time.Time // 商品ID -> 售罄标记失效时间
+	rejections map[int64]int64     // 商品ID -> 本轮售罄以来的拒绝次数
+}
+
+// Mark 将商品标记为售罄直到until
+func (c *SoldOutCache) Mark(goodsId int64, until time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.until == nil {
+		c.until = make(map[int64]time.Time)
+	}
+	c.until[goodsId] = until
+}
+
+// IsSoldOut 商品在now时是否仍标记为售罄，过期的标记会被清除
+func (c *SoldOutCache) IsSoldOut(goodsId int64, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	until, found := c.until[goodsId]
+	if !found {
+		return false
+	}
+	if !now.Before(until) {
+		delete(c.until, goodsId)
+		return false
+	}
+	return true
+}
+
+// Clear 清除商品的售罄标记和拒绝计数，在库存归还或重新预加载后调用
+func (c *SoldOutCache) Clear(goodsId int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.until, goodsId)
+	delete(c.rejections, goodsId)
+}
+
+// Sample 记录一次售罄拒绝，返回本次是否需要记录日志以及累计拒绝次数
+// 每个商品的第1次拒绝及此后每every次拒绝记录一次日志
+func (c *SoldOutCache) Sample(goodsId int64, every int64) (bool, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rejections == nil {
+		c.rejections = make(map[int64]int64)
+	}
+	c.rejections[goodsId]++
+	count := c.rejections[goodsId]
+	return every <= 1 || (count-1)%every == 0, count
+}
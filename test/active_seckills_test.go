@@ -0,0 +1,109 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupActiveSeckills 准备3个进行中、1个已结束、1个未开始的秒杀活动
+func setupActiveSeckills(t *testing.T) *service.GoodService {
+	// 商品1有2笔有效订单和1笔已取消订单，商品3未预加载库存
+	cancelled := CreateTestOrder(3, 1)
+	cancelled.State = model.OrderStateCancelled
+	f := SetupTestService(t, WithGoods(100, 1, 2, 3), WithoutRedisStock(),
+		WithOrders(CreateTestOrder(1, 1), CreateTestOrder(2, 1), cancelled))
+
+	ended := CreateTestPromotion(4, 100)
+	ended.StartTime, ended.EndTime = model.NewTimestamp(time.Now().Add(-2*time.Hour)), model.NewTimestamp(time.Now().Add(-time.Hour))
+	upcoming := CreateTestPromotion(5, 100)
+	upcoming.StartTime, upcoming.EndTime = model.NewTimestamp(time.Now().Add(time.Hour)), model.NewTimestamp(time.Now().Add(2*time.Hour))
+	assert.NoError(t, f.DB.Create(&ended).Error)
+	assert.NoError(t, f.DB.Create(&upcoming).Error)
+
+	gs := f.Service
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(1, 98))
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(2, 100))
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(4, 7))
+	return gs
+}
+
+// TestListActiveSeckills_OnlyActive 测试只返回进行中的活动并带有实时库存和已售数量
+func TestListActiveSeckills_OnlyActive(t *testing.T) {
+	gs := setupActiveSeckills(t)
+
+	result, err := gs.ListActiveSeckills(1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), result.Total)
+	assert.Len(t, result.Items, 3)
+
+	assert.Equal(t, model.ActiveSeckill{
+		PsId: 1001, GoodsId: 1, PsCount: 100, CurrentPrice: 50.0,
+		StartTime: result.Items[0].StartTime, EndTime: result.Items[0].EndTime,
+		Stock: 98, StockLoaded: true, Sold: 2,
+	}, result.Items[0])
+
+	assert.Equal(t, int64(2), result.Items[1].GoodsId)
+	assert.Equal(t, int64(100), result.Items[1].Stock)
+	assert.True(t, result.Items[1].StockLoaded)
+	assert.Zero(t, result.Items[1].Sold)
+
+	assert.Equal(t, int64(3), result.Items[2].GoodsId)
+	assert.False(t, result.Items[2].StockLoaded)
+}
+
+// TestListActiveSeckills_Pagination 测试分页及分页参数规范化
+func TestListActiveSeckills_Pagination(t *testing.T) {
+	gs := setupActiveSeckills(t)
+
+	result, err := gs.ListActiveSeckills(2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), result.Total)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, int64(3), result.Items[0].GoodsId)
+
+	result, err = gs.ListActiveSeckills(3, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Items)
+	assert.NotNil(t, result.Items)
+
+	result, err = gs.ListActiveSeckills(0, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, repository.MaxActivePageSize, result.Size)
+}
+
+// TestListActiveSeckills_API 测试管理接口返回分页结果并校验参数
+func TestListActiveSeckills_API(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	gs := setupActiveSeckills(t)
+	r := router.NewAdminRouter(&controller.GoodController{GoodService: gs})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/seckills/active?admin=1&page=1&page_size=2", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Code int                                  `json:"code"`
+		Data model.Paginated[model.ActiveSeckill] `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(3), resp.Data.Total)
+	assert.Equal(t, 2, resp.Data.Size)
+	assert.Equal(t, 2, resp.Data.TotalPages)
+	assert.True(t, resp.Data.HasNext)
+	assert.Len(t, resp.Data.Items, 2)
+
+	assert.Equal(t, http.StatusBadRequest, serve(r, "GET", "/api/admin/seckills/active?admin=1&page=0"))
+	assert.Equal(t, http.StatusBadRequest, serve(r, "GET", "/api/admin/seckills/active?admin=1&page_size=abc"))
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setPromotionWindow 修改测试商品的秒杀活动起止时间
+func setPromotionWindow(t *testing.T, start, end time.Time) {
+	require.NoError(t, global.DBClient.Model(&model.PromotionSecKill{}).
+		Where("goods_id = ?", 1).
+		Updates(map[string]any{"start_time": start, "end_time": end}).Error)
+}
+
+// TestGenerateSeckillToken_ActivityWindow 测试活动未开始和已结束返回不同的错误并附带起止时间
+func TestGenerateSeckillToken_ActivityWindow(t *testing.T) {
+	gs, _ := setupGoodsAllowlistService(t, model.GoodsAllowlist{})
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	end := start.Add(time.Hour)
+
+	setPromotionWindow(t, start, end)
+	_, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	assert.ErrorIs(t, err, service.ErrSeckillNotStarted)
+	assert.ErrorIs(t, err, errs.ErrActivityNotAvailable)
+	assert.NotErrorIs(t, err, service.ErrSeckillEnded)
+	var window *service.ActivityWindowError
+	require.ErrorAs(t, err, &window)
+	assert.True(t, start.Equal(window.StartTime.Time))
+	assert.True(t, end.Equal(window.EndTime.Time))
+
+	setPromotionWindow(t, start.Add(-3*time.Hour), end.Add(-3*time.Hour))
+	_, err = gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	assert.ErrorIs(t, err, service.ErrSeckillEnded)
+	assert.ErrorIs(t, err, errs.ErrForbidden)
+	assert.Equal(t, "seckill_ended", errs.Code(err))
+}
+
+// TestGetSeckillToken_ActivityWindowCode 测试令牌接口对未开始和已结束返回不同业务码及活动起止时间
+func TestGetSeckillToken_ActivityWindowCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	gs, _ := setupGoodsAllowlistService(t, model.GoodsAllowlist{})
+	userToken, err := gs.RedisRepo.GenerateUserToken(100)
+	require.NoError(t, err)
+	r := gin.New()
+	r.POST("/api/seckill/token", (&controller.GoodController{GoodService: gs}).GetSeckillToken)
+
+	requestToken := func() (int, map[string]any) {
+		req := httptest.NewRequest("POST", "/api/seckill/token?gid=1", nil)
+		req.Header.Set("Authorization", userToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body
+	}
+
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	end := start.Add(time.Hour)
+	cases := []struct {
+		name       string
+		start, end time.Time
+		code       int
+	}{
+		{"not started", start, end, controller.CodeSeckillNotStarted},
+		{"ended", start.Add(-3 * time.Hour), end.Add(-3 * time.Hour), controller.CodeSeckillEnded},
+	}
+	for _, c := range cases {
+		setPromotionWindow(t, c.start, c.end)
+		status, body := requestToken()
+		assert.Equal(t, http.StatusForbidden, status, c.name)
+		assert.Equal(t, float64(c.code), body["code"], c.name)
+		data, ok := body["data"].(map[string]any)
+		require.True(t, ok, c.name)
+		assert.Equal(t, c.start.Format(time.RFC3339Nano), data["start_time"], c.name)
+		assert.Equal(t, c.end.Format(time.RFC3339Nano), data["end_time"], c.name)
+	}
+
+	// 活动时间内正常签发令牌
+	setPromotionWindow(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	status, body := requestToken()
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(0), body["code"])
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// serve 向路由引擎发送请求并返回响应状态码
+func serve(r *gin.Engine, method, path string) int {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w.Code
+}
+
+// noopAuth 测试用认证中间件，直接放行
+func noopAuth(c *gin.Context) {
+	c.Next()
+}
+
+// TestRouter_AdminOnSeparatePort 测试独立管理端口时管理接口只在管理路由中提供
+func TestRouter_AdminOnSeparatePort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	goodController := &controller.GoodController{}
+	public := router.NewRouter(goodController, noopAuth, false)
+	admin := router.NewAdminRouter(goodController)
+
+	// 缺少goods_ids参数时批量重置接口在参数校验阶段返回400，无需访问数据库
+	assert.Equal(t, http.StatusBadRequest, serve(admin, "POST", "/api/admin/reset_db/batch?admin=1"))
+	assert.Equal(t, http.StatusNotFound, serve(public, "POST", "/api/admin/reset_db/batch?admin=1"))
+
+	// 管理路由仍然校验管理员权限
+	assert.Equal(t, http.StatusForbidden, serve(admin, "POST", "/api/admin/reset_db/batch"))
+
+	// pprof只在管理路由中提供
+	assert.Equal(t, http.StatusOK, serve(admin, "GET", "/debug/pprof/"))
+	assert.Equal(t, http.StatusNotFound, serve(public, "GET", "/debug/pprof/"))
+
+	// 公共接口不在管理路由中提供
+	assert.Equal(t, http.StatusBadRequest, serve(public, "GET", "/api/goods/abc/ws"))
+	assert.Equal(t, http.StatusNotFound, serve(admin, "GET", "/api/goods/abc/ws"))
+}
+
+// TestRouter_AdminOnPublicPort 测试未配置独立管理端口时公共路由包含管理接口
+func TestRouter_AdminOnPublicPort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	public := router.NewRouter(&controller.GoodController{}, noopAuth, true)
+
+	assert.Equal(t, http.StatusBadRequest, serve(public, "POST", "/api/admin/reset_db/batch?admin=1"))
+	assert.Equal(t, http.StatusNotFound, serve(public, "GET", "/debug/pprof/"))
+}
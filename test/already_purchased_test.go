@@ -0,0 +1,78 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"seckill_system/errs"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/web/controller"
+	"seckill_system/web/middleware"
+	"seckill_system/web/router"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestCreateOrder_AlreadyPurchased 测试重复下单返回ErrAlreadyPurchased，恢复Redis库存且不扣减数据库库存
+func TestCreateOrder_AlreadyPurchased(t *testing.T) {
+	gs := SetupTestService(t, WithOrders(CreateTestOrder(100, 1)), WithSeckillEnabled(10), WithSeckillHandler()).Service
+
+	orderId, err := gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 1)
+	assert.ErrorIs(t, err, errs.ErrAlreadyPurchased)
+	assert.Empty(t, orderId)
+	assert.Equal(t, errs.OutcomeAlreadyPurchased, errs.Outcome(err))
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), stock)
+	promotion, err := gs.GoodDB.GetPromotionByGoodsId(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), promotion.PsCount)
+
+	// 其他用户正常下单
+	_, err = gs.SeckillHandler.CreateOrder(context.Background(), 101, 1, 1)
+	assert.NoError(t, err)
+}
+
+// TestSeckillAPI_AlreadyPurchased 测试已抢到商品的用户再次下单时接口返回409和明确提示
+func TestSeckillAPI_AlreadyPurchased(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	gs := SetupTestService(t, WithOrders(CreateTestOrder(100, 1)), WithSeckillEnabled(10), WithSeckillHandler()).Service
+	r := router.NewRouter(&controller.GoodController{GoodService: gs}, middleware.NewAuthMiddleware(gs), false)
+
+	resp := callAPI(t, r, "GET", "/api/auth/create_user_token?user_id=100", "", http.StatusOK)
+	var userToken struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &userToken))
+	tokenId, err := gs.RedisRepo.GenerateSeckillToken(100, 1)
+	require.NoError(t, err)
+
+	resp = callAPI(t, r, "POST", "/api/seckill?gid=1&token="+tokenId, userToken.Token, http.StatusConflict)
+	assert.Equal(t, -1, resp.Code)
+	assert.Equal(t, "You have already secured this item", resp.Message)
+	assert.Contains(t, resp.Error, errs.ErrPurchaseLimitReached.Error())
+}
+
+// TestIsDuplicateKeyError 测试唯一键冲突错误识别
+func TestIsDuplicateKeyError(t *testing.T) {
+	assert.True(t, repository.IsDuplicateKeyError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}))
+	assert.True(t, repository.IsDuplicateKeyError(gorm.ErrDuplicatedKey))
+	assert.False(t, repository.IsDuplicateKeyError(&mysql.MySQLError{Number: 1213, Message: "Deadlock"}))
+	assert.False(t, repository.IsDuplicateKeyError(errors.New("connection refused")))
+	assert.False(t, repository.IsDuplicateKeyError(nil))
+
+	// SQLite主键冲突
+	db := SetupTestDB(t)
+	order := CreateTestOrder(100, 1)
+	require.NoError(t, db.Create(&order).Error)
+	duplicate := model.SuccessKilled{Id: order.Id, GoodsId: 1, UserId: 101}
+	assert.True(t, repository.IsDuplicateKeyError(db.Create(&duplicate).Error))
+}
@@ -0,0 +1,234 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"seckill_system/config"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"seckill_system/web/middleware"
+	"seckill_system/web/router"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSeckillQueue 记录放入队列的异步秒杀请求，err不为nil时模拟写入失败
+type recordingSeckillQueue struct {
+	mu       sync.Mutex
+	requests []model.AsyncSeckillRequest
+	err      error
+}
+
+// SendSeckillRequest 记录一条请求
+func (q *recordingSeckillQueue) SendSeckillRequest(ctx context.Context, request *model.AsyncSeckillRequest) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.err != nil {
+		return q.err
+	}
+	q.requests = append(q.requests, *request)
+	return nil
+}
+
+// drain 取出已放入队列的全部请求
+func (q *recordingSeckillQueue) drain() []model.AsyncSeckillRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	requests := q.requests
+	q.requests = nil
+	return requests
+}
+
+// newAsyncSeckillService 创建开启异步秒杀的服务，商品1库存为stock
+func newAsyncSeckillService(t *testing.T, stock int64) (*service.GoodService, *recordingSeckillQueue) {
+	t.Helper()
+	gs := SetupTestService(t, WithGoods(stock, 1), WithRedisMode()).Service
+	queue := &recordingSeckillQueue{}
+	gs.AsyncQueue = queue
+	return gs, queue
+}
+
+// TestSeckillAsync_AcceptThenProcess 测试受理时只消费令牌不扣减库存，消费者处理后写入下单结果
+func TestSeckillAsync_AcceptThenProcess(t *testing.T) {
+	gs, queue := newAsyncSeckillService(t, 2)
+	tokenId := mustSeckillToken(t, gs, 100, 1)
+
+	requestId, err := gs.SeckillAsync(100, 1, tokenId, "203.0.113.7")
+	require.NoError(t, err)
+	assert.Len(t, requestId, 32)
+
+	result, err := gs.GetAsyncSeckillResult(100, requestId)
+	require.NoError(t, err)
+	assert.Equal(t, model.AsyncSeckillPending, result.Status)
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stock)
+
+	// 令牌在受理时已消费
+	_, err = gs.SeckillAsync(100, 1, tokenId, "203.0.113.7")
+	assert.ErrorIs(t, err, errs.ErrTokenNotFound)
+
+	requests := queue.drain()
+	require.Len(t, requests, 1)
+	assert.Equal(t, requestId, requests[0].RequestId)
+	assert.Equal(t, model.TokenPrefix(tokenId), requests[0].TokenPrefix)
+	assert.Equal(t, "203.0.113.7", requests[0].ClientIP)
+
+	require.NoError(t, gs.HandleSeckillRequest(requests[0]))
+	result, err = gs.GetAsyncSeckillResult(100, requestId)
+	require.NoError(t, err)
+	assert.Equal(t, model.AsyncSeckillSuccess, result.Status)
+	assert.NotEmpty(t, result.OrderId)
+	assert.Empty(t, result.ErrorCode)
+
+	// 重复投递的消息不会再次下单
+	require.NoError(t, gs.HandleSeckillRequest(requests[0]))
+	again, err := gs.GetAsyncSeckillResult(100, requestId)
+	require.NoError(t, err)
+	assert.Equal(t, result.OrderId, again.OrderId)
+	stock, err = gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stock)
+	assert.Equal(t, map[string]int64{errs.OutcomeSuccess: 1, errs.OutcomeInvalidToken: 1}, gs.Outcomes()[model.AuditActionSeckill])
+}
+
+// TestSeckillAsync_FailureRecorded 测试下单失败时结果中记录错误码
+func TestSeckillAsync_FailureRecorded(t *testing.T) {
+	gs, queue := newAsyncSeckillService(t, 1)
+
+	first, err := gs.SeckillAsync(100, 1, mustSeckillToken(t, gs, 100, 1), "")
+	require.NoError(t, err)
+	second, err := gs.SeckillAsync(101, 1, mustSeckillToken(t, gs, 101, 1), "")
+	require.NoError(t, err)
+	for _, request := range queue.drain() {
+		require.NoError(t, gs.HandleSeckillRequest(request))
+	}
+
+	result, err := gs.GetAsyncSeckillResult(100, first)
+	require.NoError(t, err)
+	assert.Equal(t, model.AsyncSeckillSuccess, result.Status)
+
+	result, err = gs.GetAsyncSeckillResult(101, second)
+	require.NoError(t, err)
+	assert.Equal(t, model.AsyncSeckillFailed, result.Status)
+	assert.Equal(t, errs.ErrSoldOut.Code, result.ErrorCode)
+	assert.Empty(t, result.OrderId)
+}
+
+// TestSeckillAsync_ResultVisibleOnlyToOwner 测试其他用户和格式不合法的请求ID都查询不到结果
+func TestSeckillAsync_ResultVisibleOnlyToOwner(t *testing.T) {
+	gs, _ := newAsyncSeckillService(t, 1)
+	requestId, err := gs.SeckillAsync(100, 1, mustSeckillToken(t, gs, 100, 1), "")
+	require.NoError(t, err)
+
+	_, err = gs.GetAsyncSeckillResult(101, requestId)
+	assert.ErrorIs(t, err, errs.ErrSeckillRequestNotFound)
+	_, err = gs.GetAsyncSeckillResult(100, "not-a-request-id")
+	assert.ErrorIs(t, err, errs.ErrSeckillRequestNotFound)
+	_, err = gs.GetAsyncSeckillResult(100, "0123456789abcdef0123456789abcdef")
+	assert.ErrorIs(t, err, errs.ErrSeckillRequestNotFound)
+}
+
+// TestSeckillAsync_Rejected 测试未开启异步秒杀或放入队列失败时拒绝请求
+func TestSeckillAsync_Rejected(t *testing.T) {
+	gs, queue := newAsyncSeckillService(t, 1)
+
+	queue.err = errors.New("broker unavailable")
+	_, err := gs.SeckillAsync(100, 1, mustSeckillToken(t, gs, 100, 1), "")
+	assert.ErrorIs(t, err, errs.ErrSystemBusy)
+
+	gs.AsyncQueue = nil
+	_, err = gs.SeckillAsync(100, 1, mustSeckillToken(t, gs, 100, 1), "")
+	assert.ErrorIs(t, err, errs.ErrAsyncSeckillDisabled)
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stock)
+}
+
+// TestSeckillAsyncAPI 测试异步秒杀接口返回202和请求ID，轮询接口返回处理结果
+func TestSeckillAsyncAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	gs, queue := newAsyncSeckillService(t, 1)
+	kv := SetupTestEtcd(t)
+	kv.Data[global.EtcdKeyRateLimit] = "10"
+	gs.EtcdRepo = repository.NewETCDRepository()
+	r := router.NewRouter(&controller.GoodController{GoodService: gs}, middleware.NewAuthMiddleware(gs), false)
+
+	resp := callAPI(t, r, "GET", "/api/auth/create_user_token?user_id=100", "", http.StatusOK)
+	var userToken struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &userToken))
+
+	resp = callAPI(t, r, "POST", "/api/seckill/async?gid=1&token="+mustSeckillToken(t, gs, 100, 1), userToken.Token, http.StatusAccepted)
+	var accepted struct {
+		RequestId string `json:"request_id"`
+		Status    string `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &accepted))
+	assert.Equal(t, model.AsyncSeckillPending, accepted.Status)
+
+	var result model.AsyncSeckillResult
+	resp = callAPI(t, r, "GET", "/api/seckill/result/"+accepted.RequestId, userToken.Token, http.StatusOK)
+	require.NoError(t, json.Unmarshal(resp.Data, &result))
+	assert.Equal(t, model.AsyncSeckillPending, result.Status)
+
+	for _, request := range queue.drain() {
+		require.NoError(t, gs.HandleSeckillRequest(request))
+	}
+	resp = callAPI(t, r, "GET", "/api/seckill/result/"+accepted.RequestId, userToken.Token, http.StatusOK)
+	require.NoError(t, json.Unmarshal(resp.Data, &result))
+	assert.Equal(t, model.AsyncSeckillSuccess, result.Status)
+	assert.NotEmpty(t, result.OrderId)
+
+	callAPI(t, r, "GET", "/api/seckill/result/0123456789abcdef0123456789abcdef", userToken.Token, http.StatusNotFound)
+	callAPI(t, r, "POST", "/api/seckill/async?gid=1&token="+absentToken, userToken.Token, http.StatusForbidden)
+	callAPI(t, r, "POST", "/api/seckill/async?gid=1", userToken.Token, http.StatusBadRequest)
+}
+
+// TestConsumeSeckillRequests_SkipsMalformed 测试消费循环跳过无法解析的消息并继续处理后续请求
+func TestConsumeSeckillRequests_SkipsMalformed(t *testing.T) {
+	data, err := json.Marshal(model.AsyncSeckillRequest{RequestId: "r1", UserId: 100, GoodsId: 1})
+	require.NoError(t, err)
+	reader := &sliceMessageReader{messages: []kafka.Message{
+		{Offset: 0, Value: []byte("{not json")},
+		{Offset: 1, Value: data},
+	}}
+
+	var handled []model.AsyncSeckillRequest
+	err = repository.ConsumeSeckillRequestsFrom(context.Background(), reader, func(request model.AsyncSeckillRequest) error {
+		handled = append(handled, request)
+		return errors.New("handler failure does not stop the loop")
+	})
+	assert.ErrorIs(t, err, io.EOF)
+	require.Len(t, handled, 1)
+	assert.Equal(t, "r1", handled[0].RequestId)
+}
+
+// TestSeckillAsync_Validate 测试异步秒杀结果保留时间为负数时配置校验失败
+func TestSeckillAsync_Validate(t *testing.T) {
+	preserveAppConfig(t)
+
+	t.Setenv("SECKILL_SECKILL_ASYNC_RESULT_SECONDS", "-1")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "async_result_seconds must not be negative")
+
+	t.Setenv("SECKILL_SECKILL_ASYNC_RESULT_SECONDS", "0")
+	t.Setenv("SECKILL_KAFKA_SECKILL_REQUEST_TOPIC", "seckill_requests")
+	require.NoError(t, loadConfigDocument(t, 8100))
+	assert.Equal(t, "seckill_requests", config.AppConfig.Kafka.SeckillRequestTopic)
+	assert.Equal(t, config.DefaultSeckillAsyncResultSeconds*time.Second, config.AppConfig.Seckill.AsyncResultTTL())
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"seckill_system/model"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// MockAuditPublisher 审计事件发布者的模拟实现
+type MockAuditPublisher struct {
+	Events chan *model.AuditEvent // 已发送的审计事件
+}
+
+// SendAuditEvent 记录审计事件
+func (m *MockAuditPublisher) SendAuditEvent(ctx context.Context, event *model.AuditEvent) error {
+	m.Events <- event
+	return nil
+}
+
+// waitAuditEvent 等待异步发送的审计事件
+func waitAuditEvent(t *testing.T, publisher *MockAuditPublisher) *model.AuditEvent {
+	select {
+	case event := <-publisher.Events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("audit event not produced")
+		return nil
+	}
+}
+
+// TestRecordAuditEvent_Success 测试成功操作产生success审计事件
+func TestRecordAuditEvent_Success(t *testing.T) {
+	publisher := &MockAuditPublisher{Events: make(chan *model.AuditEvent, 1)}
+	gs := &service.GoodService{Auditor: publisher}
+
+	gs.RecordAuditEvent(model.AuditActionSeckillToken, 1001, 1, "10.0.0.1", nil)
+
+	event := waitAuditEvent(t, publisher)
+	assert.Equal(t, model.AuditActionSeckillToken, event.Action)
+	assert.Equal(t, int64(1001), event.UserId)
+	assert.Equal(t, int64(1), event.GoodsId)
+	assert.Equal(t, model.AuditOutcomeSuccess, event.Outcome)
+	assert.Empty(t, event.Reason)
+	assert.Equal(t, "10.0.0.1", event.ClientIP)
+	assert.False(t, event.Timestamp.IsZero())
+}
+
+// TestRecordAuditEvent_Failure 测试失败操作产生failure审计事件并附带原因
+func TestRecordAuditEvent_Failure(t *testing.T) {
+	publisher := &MockAuditPublisher{Events: make(chan *model.AuditEvent, 1)}
+	gs := &service.GoodService{Auditor: publisher}
+
+	gs.RecordAuditEvent(model.AuditActionSeckill, 1002, 2, "10.0.0.2", errors.New("invalid token"))
+
+	event := waitAuditEvent(t, publisher)
+	assert.Equal(t, model.AuditActionSeckill, event.Action)
+	assert.Equal(t, model.AuditOutcomeFailure, event.Outcome)
+	assert.Equal(t, "invalid token", event.Reason)
+}
+
+// TestRecordAuditEvent_Disabled 测试未开启审计时不发送事件
+func TestRecordAuditEvent_Disabled(t *testing.T) {
+	gs := &service.GoodService{}
+	assert.NotPanics(t, func() {
+		gs.RecordAuditEvent(model.AuditActionSeckill, 1003, 3, "10.0.0.3", nil)
+	})
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAuthTestRouter 创建使用真实Redis令牌验证的认证测试路由
+func newAuthTestRouter(t *testing.T) (*gin.Engine, *repository.RedisRepository) {
+	SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+	gs := &service.GoodService{RedisRepo: redisRepo}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/protected", middleware.NewAuthMiddleware(gs), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetInt64("userId")})
+	})
+	return r, redisRepo
+}
+
+// authRequest 携带Authorization请求头访问受保护接口
+func authRequest(r *gin.Engine, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestAuthMiddleware_ShortToken 测试过短的令牌返回401而不是panic
+func TestAuthMiddleware_ShortToken(t *testing.T) {
+	r, _ := newAuthTestRouter(t)
+
+	var w *httptest.ResponseRecorder
+	assert.NotPanics(t, func() {
+		w = authRequest(r, "abc")
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// TestAuthMiddleware_ValidToken 测试有效令牌通过认证并写入用户ID
+func TestAuthMiddleware_ValidToken(t *testing.T) {
+	r, redisRepo := newAuthTestRouter(t)
+	token, err := redisRepo.GenerateUserToken(100)
+	assert.NoError(t, err)
+
+	w := authRequest(r, token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":100}`, w.Body.String())
+}
+
+// TestAuthMiddleware_MissingToken 测试缺少令牌返回401
+func TestAuthMiddleware_MissingToken(t *testing.T) {
+	r, _ := newAuthTestRouter(t)
+
+	w := authRequest(r, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,148 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// setupBlacklistImport 使用模拟KV和Lease创建商品服务
+func setupBlacklistImport(t *testing.T) (*service.GoodService, *MockEtcdKV, *MockEtcdLease) {
+	t.Helper()
+	SetupTestRedis(t)
+	kv := SetupTestEtcd(t)
+	lease := NewMockEtcdLease()
+	global.EtcdClient.Lease = lease
+	gs := &service.GoodService{
+		RedisRepo: repository.NewRedisRepository(),
+		EtcdRepo:  repository.NewETCDRepository(),
+	}
+	return gs, kv, lease
+}
+
+// TestBatchAddToBlacklist_SharesLeasePerDuration 测试批量写入时相同时长的用户共用租约，超过单个事务上限时分批提交
+func TestBatchAddToBlacklist_SharesLeasePerDuration(t *testing.T) {
+	gs, kv, lease := setupBlacklistImport(t)
+
+	var additions []repository.BlacklistAddition
+	for userId := int64(1); userId <= 200; userId++ {
+		duration := time.Hour
+		if userId%2 == 0 {
+			duration = 24 * time.Hour
+		}
+		additions = append(additions, repository.BlacklistAddition{UserId: userId, Reason: "attack", Duration: duration})
+	}
+	failed := gs.EtcdRepo.BatchAddToBlacklist(context.Background(), additions)
+	assert.Empty(t, failed)
+	assert.Len(t, lease.Expiry, 2)
+
+	for userId := int64(1); userId <= 200; userId++ {
+		inBlacklist, err := gs.EtcdRepo.IsInBlacklist(context.Background(), userId)
+		require.NoError(t, err)
+		assert.True(t, inBlacklist, "user %d", userId)
+	}
+	assert.Equal(t, kv.Leases[global.EtcdKeyBlacklist+"1"], kv.Leases[global.EtcdKeyBlacklist+"3"])
+	assert.NotEqual(t, kv.Leases[global.EtcdKeyBlacklist+"1"], kv.Leases[global.EtcdKeyBlacklist+"2"])
+	assert.NotEqual(t, clientv3.NoLease, kv.Leases[global.EtcdKeyBlacklist+"1"])
+}
+
+// TestImportBlacklist_PerEntryResults 测试格式错误的条目不影响其他条目，成功的用户令牌被吊销
+func TestImportBlacklist_PerEntryResults(t *testing.T) {
+	gs, _, _ := setupBlacklistImport(t)
+	userToken, err := gs.RedisRepo.GenerateUserToken(1)
+	require.NoError(t, err)
+
+	results := gs.ImportBlacklist([]model.BlacklistEntry{
+		{UserId: 1, Reason: "bot", Duration: "24h"},
+		{UserId: 0, Reason: "bad id", Duration: "1h"},
+		{UserId: 2, Duration: "forever"},
+		{UserId: 3, Duration: "-1h"},
+		{UserId: 1, Reason: "duplicate", Duration: "1h"},
+		{UserId: 4, Duration: "30m"},
+	})
+	require.Len(t, results, 6)
+
+	assert.True(t, results[0].Success)
+	assert.True(t, results[5].Success)
+	for _, i := range []int{1, 2, 3, 4} {
+		assert.False(t, results[i].Success, "entry %d", i)
+		assert.NotEmpty(t, results[i].Error, "entry %d", i)
+		assert.Equal(t, i, results[i].Index)
+	}
+
+	for userId, want := range map[int64]bool{1: true, 2: false, 3: false, 4: true} {
+		inBlacklist, err := gs.EtcdRepo.IsInBlacklist(context.Background(), userId)
+		require.NoError(t, err)
+		assert.Equal(t, want, inBlacklist, "user %d", userId)
+	}
+
+	// 已导入用户的令牌被吊销
+	_, err = gs.RedisRepo.VerifyUserToken(userToken)
+	assert.Error(t, err)
+}
+
+// TestBlacklistImportExport_API 测试导入接口返回逐条结果，导出接口返回同样格式的条目
+func TestBlacklistImportExport_API(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	gs, _, _ := setupBlacklistImport(t)
+	r := router.NewAdminRouter(&controller.GoodController{GoodService: gs})
+
+	body := `[{"user_id":10,"reason":"bot","duration":"2h"},{"user_id":-1,"duration":"1h"},{"user_id":11,"duration":"1h"}]`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/blacklist/import?admin=1", bytes.NewBufferString(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var importResp struct {
+		Code int `json:"code"`
+		Data struct {
+			Results   []model.BlacklistImportResult `json:"results"`
+			Succeeded int                           `json:"succeeded"`
+			Failed    int                           `json:"failed"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &importResp))
+	assert.Equal(t, 2, importResp.Data.Succeeded)
+	assert.Equal(t, 1, importResp.Data.Failed)
+	require.Len(t, importResp.Data.Results, 3)
+	assert.False(t, importResp.Data.Results[1].Success)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/blacklist/export?admin=1", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var exportResp struct {
+		Code int                    `json:"code"`
+		Data []model.BlacklistEntry `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exportResp))
+	require.Len(t, exportResp.Data, 2)
+	exported := make(map[int64]model.BlacklistEntry)
+	for _, entry := range exportResp.Data {
+		exported[entry.UserId] = entry
+	}
+	assert.Equal(t, "bot", exported[10].Reason)
+	duration, err := time.ParseDuration(exported[10].Duration)
+	require.NoError(t, err)
+	assert.InDelta(t, (2 * time.Hour).Seconds(), duration.Seconds(), 5)
+	assert.Equal(t, "Bulk import", exported[11].Reason)
+
+	assert.Equal(t, http.StatusBadRequest, serve(r, "POST", "/api/admin/blacklist/import?admin=1"))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/blacklist/import?admin=1", bytes.NewBufferString("[]")))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
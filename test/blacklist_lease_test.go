@@ -0,0 +1,67 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"seckill_system/global"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddToBlacklist_LeaseGrantFailure 测试创建租约失败时降级为不带租约的永久封禁，条目标记需要人工复核
+func TestAddToBlacklist_LeaseGrantFailure(t *testing.T) {
+	gs, kv, lease := setupBlacklistImport(t)
+	lease.GrantErr = errors.New("mock lease unavailable")
+	key := fmt.Sprintf("%s%d", global.EtcdKeyBlacklist, 100)
+
+	needsReview, err := gs.AddToBlacklist(100, "abuse", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, needsReview)
+
+	// 封禁照常生效，但未关联租约，不会自动过期
+	blacklisted, err := gs.EtcdRepo.IsInBlacklist(context.Background(), 100)
+	require.NoError(t, err)
+	assert.True(t, blacklisted)
+	assert.NotContains(t, kv.Leases, key)
+
+	var info map[string]any
+	require.NoError(t, json.Unmarshal([]byte(kv.Data[key]), &info))
+	assert.Equal(t, true, info["needs_review"])
+	assert.Equal(t, "abuse", info["reason"])
+}
+
+// TestAddToBlacklist_LeaseGranted 测试租约创建成功时条目关联租约且不需要人工复核
+func TestAddToBlacklist_LeaseGranted(t *testing.T) {
+	gs, kv, _ := setupBlacklistImport(t)
+	key := fmt.Sprintf("%s%d", global.EtcdKeyBlacklist, 100)
+
+	needsReview, err := gs.AddToBlacklist(100, "abuse", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, needsReview)
+	assert.Contains(t, kv.Leases, key)
+	assert.NotContains(t, kv.Data[key], "needs_review")
+}
+
+// TestAddToBlacklistAPI_ReportsNeedsReview 测试接口在租约创建失败时仍返回成功，并通过needs_review提示人工复核
+func TestAddToBlacklistAPI_ReportsNeedsReview(t *testing.T) {
+	gs, _, lease := setupBlacklistImport(t)
+	r := router.NewAdminRouter(&controller.GoodController{GoodService: gs})
+
+	resp := callAPI(t, r, "POST", "/api/admin/blacklist/add?admin=1&user_id=100&duration=1h", "", http.StatusOK)
+	assert.Equal(t, 0, resp.Code)
+	assert.JSONEq(t, `{"needs_review":false}`, string(resp.Data))
+
+	lease.GrantErr = errors.New("mock lease unavailable")
+	resp = callAPI(t, r, "POST", "/api/admin/blacklist/add?admin=1&user_id=101&duration=1h", "", http.StatusOK)
+	assert.Equal(t, 0, resp.Code)
+	assert.JSONEq(t, `{"needs_review":true}`, string(resp.Data))
+	assert.Contains(t, resp.Message, "manual review")
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"errors"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBlacklistRecheckService 创建开启或关闭下单黑名单复查的商品服务，并为用户100签发秒杀令牌
+func newBlacklistRecheckService(t *testing.T, recheck bool) (*service.GoodService, *MockEtcdKV, string) {
+	t.Helper()
+	f := SetupTestService(t, WithSeckillEnabled(10), WithRedisMode())
+	f.Service.Seckill.RecheckBlacklist = recheck
+
+	tokenId, err := f.Service.RedisRepo.GenerateSeckillToken(100, 1)
+	require.NoError(t, err)
+	return f.Service, f.Etcd, tokenId
+}
+
+// TestBlacklistRecheck_BlacklistedAfterTokenIssued 测试获取令牌后被加入黑名单的用户无法使用有效令牌下单
+func TestBlacklistRecheck_BlacklistedAfterTokenIssued(t *testing.T) {
+	gs, kv, tokenId := newBlacklistRecheckService(t, true)
+	kv.Data[global.EtcdKeyBlacklist+"100"] = `{"user_id":100,"reason":"abuse"}` // 签发令牌后加入黑名单
+
+	orderId, err := gs.SeckillWithToken(100, 1, tokenId)
+	assert.ErrorIs(t, err, errs.ErrBlacklisted)
+	assert.Empty(t, orderId)
+
+	// 未扣减库存，令牌未被消费
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), stock)
+	valid, err := gs.RedisRepo.VerifySeckillToken(tokenId, 100, 1)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+// TestBlacklistRecheck_Disabled 测试关闭复查时持有有效令牌的黑名单用户仍可下单
+func TestBlacklistRecheck_Disabled(t *testing.T) {
+	gs, kv, tokenId := newBlacklistRecheckService(t, false)
+	kv.Data[global.EtcdKeyBlacklist+"100"] = `{"user_id":100,"reason":"abuse"}` // 签发令牌后加入黑名单
+
+	orderId, err := gs.SeckillWithToken(100, 1, tokenId)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, orderId)
+}
+
+// TestBlacklistRecheck_EtcdUnavailableAllows 测试黑名单读取失败时放行，不因Etcd故障阻断下单
+func TestBlacklistRecheck_EtcdUnavailableAllows(t *testing.T) {
+	gs, kv, tokenId := newBlacklistRecheckService(t, true)
+	kv.GetErr = errors.New("etcd unavailable")
+
+	orderId, err := gs.SeckillWithToken(100, 1, tokenId)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, orderId)
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"seckill_system/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blacklistEntry 构造测试用黑名单条目
+func blacklistEntry(userId int64, addTime time.Time) map[string]any {
+	return map[string]any{
+		"user_id":  float64(userId), // JSON反序列化后数字为float64
+		"reason":   "test",
+		"add_time": addTime.Format(time.RFC3339),
+	}
+}
+
+// TestSortBlacklistByAddTime 测试黑名单按加入时间倒序排列
+func TestSortBlacklistByAddTime(t *testing.T) {
+	now := time.Now()
+	// 按用户ID字典序排列（模拟Etcd前缀查询结果）
+	blacklist := []map[string]any{
+		blacklistEntry(1, now.Add(-3*time.Hour)),
+		blacklistEntry(2, now.Add(-1*time.Hour)),
+		blacklistEntry(3, now.Add(-2*time.Hour)),
+	}
+
+	sorted := repository.SortBlacklistByAddTime(blacklist, 0)
+
+	assert.Len(t, sorted, 3)
+	assert.Equal(t, float64(2), sorted[0]["user_id"]) // 最近加入的在前
+	assert.Equal(t, float64(3), sorted[1]["user_id"])
+	assert.Equal(t, float64(1), sorted[2]["user_id"])
+}
+
+// TestSortBlacklistByAddTime_Limit 测试黑名单返回条数限制
+func TestSortBlacklistByAddTime_Limit(t *testing.T) {
+	now := time.Now()
+	blacklist := []map[string]any{
+		blacklistEntry(1, now.Add(-3*time.Hour)),
+		blacklistEntry(2, now.Add(-1*time.Hour)),
+		blacklistEntry(3, now.Add(-2*time.Hour)),
+	}
+
+	sorted := repository.SortBlacklistByAddTime(blacklist, 2)
+
+	assert.Len(t, sorted, 2)                          // 只返回最近的2条
+	assert.Equal(t, float64(2), sorted[0]["user_id"]) // 最近加入
+	assert.Equal(t, float64(3), sorted[1]["user_id"]) // 次近加入
+}
+
+// TestSortBlacklistByAddTime_InvalidTime 测试无法解析加入时间的条目排在最后
+func TestSortBlacklistByAddTime_InvalidTime(t *testing.T) {
+	now := time.Now()
+	blacklist := []map[string]any{
+		{"user_id": float64(1), "add_time": "not-a-time"},
+		blacklistEntry(2, now.Add(-1*time.Hour)),
+		{"user_id": float64(3)}, // 缺少add_time字段
+	}
+
+	sorted := repository.SortBlacklistByAddTime(blacklist, 0)
+
+	assert.Len(t, sorted, 3)
+	assert.Equal(t, float64(2), sorted[0]["user_id"]) // 有效时间排在最前
+	assert.Equal(t, float64(1), sorted[1]["user_id"]) // 无效条目保持原有相对顺序
+	assert.Equal(t, float64(3), sorted[2]["user_id"])
+}
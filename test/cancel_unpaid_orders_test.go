@@ -0,0 +1,79 @@
+package test
+
+import (
+	"seckill_system/model"
+	"seckill_system/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupCancelOrdersService 创建商品1-4库存为5的商品服务并写入用户100的已支付、未支付和已取消订单
+// 商品1、3的订单未支付，商品2已支付，商品4已取消；用户200在商品1也有未支付订单
+func setupCancelOrdersService(t *testing.T) (*service.GoodService, *gorm.DB) {
+	f := SetupTestService(t, WithGoods(5, 1, 2, 3, 4), WithOrders(
+		model.SuccessKilled{GoodsId: 1, UserId: 100, State: model.OrderStateUnpaid},
+		model.SuccessKilled{GoodsId: 2, UserId: 100, State: model.OrderStatePaid},
+		model.SuccessKilled{GoodsId: 3, UserId: 100, State: model.OrderStateUnpaid},
+		model.SuccessKilled{GoodsId: 4, UserId: 100, State: model.OrderStateCancelled},
+		model.SuccessKilled{GoodsId: 1, UserId: 200, State: model.OrderStateUnpaid},
+	))
+	return f.Service, f.DB
+}
+
+// orderState 查询订单状态
+func orderState(t *testing.T, db *gorm.DB, userId, goodsId int64) int16 {
+	var order model.SuccessKilled
+	assert.NoError(t, db.Where("goods_id = ? AND user_id = ?", goodsId, userId).First(&order).Error)
+	return order.State
+}
+
+// TestCancelUnpaidOrders_OnlyUnpaid 测试只取消用户自己未支付的订单并归还库存
+func TestCancelUnpaidOrders_OnlyUnpaid(t *testing.T) {
+	gs, db := setupCancelOrdersService(t)
+
+	cancelled, err := gs.CancelUnpaidOrders(100)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cancelled)
+
+	assert.Equal(t, model.OrderStateCancelled, orderState(t, db, 100, 1))
+	assert.Equal(t, model.OrderStatePaid, orderState(t, db, 100, 2))
+	assert.Equal(t, model.OrderStateCancelled, orderState(t, db, 100, 3))
+	assert.Equal(t, model.OrderStateCancelled, orderState(t, db, 100, 4))
+	assert.Equal(t, model.OrderStateUnpaid, orderState(t, db, 200, 1))
+
+	expected := map[int64]int64{1: 6, 2: 5, 3: 6, 4: 5}
+	for goodsId, stock := range expected {
+		var promotion model.PromotionSecKill
+		assert.NoError(t, db.Where("goods_id = ?", goodsId).First(&promotion).Error)
+		assert.Equal(t, stock, promotion.PsCount, goodsId)
+
+		redisStock, err := gs.RedisRepo.GetGoodsStock(goodsId)
+		assert.NoError(t, err)
+		assert.Equal(t, stock, redisStock, goodsId)
+	}
+}
+
+// TestCancelUnpaidOrders_Idempotent 测试重复取消不会重复归还库存
+func TestCancelUnpaidOrders_Idempotent(t *testing.T) {
+	gs, db := setupCancelOrdersService(t)
+
+	_, err := gs.CancelUnpaidOrders(100)
+	assert.NoError(t, err)
+	cancelled, err := gs.CancelUnpaidOrders(100)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cancelled)
+
+	// 已取消的订单再次取消不生效
+	restored, err := gs.GoodDB.CancelUnpaidOrder(db, "", 100, 1)
+	assert.NoError(t, err)
+	assert.Zero(t, restored)
+
+	var promotion model.PromotionSecKill
+	assert.NoError(t, db.Where("goods_id = ?", 1).First(&promotion).Error)
+	assert.Equal(t, int64(6), promotion.PsCount)
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), stock)
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"seckill_system/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedisClusterNodes_FiltersEmpty 测试集群节点去除空白并过滤空条目
+func TestRedisClusterNodes_FiltersEmpty(t *testing.T) {
+	cases := []struct {
+		nodes string
+		want  []string
+	}{
+		{"127.0.0.1:7000,127.0.0.1:7001", []string{"127.0.0.1:7000", "127.0.0.1:7001"}},
+		{" 127.0.0.1:7000 , 127.0.0.1:7001 ", []string{"127.0.0.1:7000", "127.0.0.1:7001"}},
+		{"127.0.0.1:7000,,127.0.0.1:7001,", []string{"127.0.0.1:7000", "127.0.0.1:7001"}},
+		{"", nil},
+		{"   ", nil},
+		{" , ,", nil},
+	}
+	for _, c := range cases {
+		rc := config.RedisConfig{ClusterNodes: c.nodes}
+		assert.Equal(t, c.want, rc.GetRedisClusterNodes(), "cluster_nodes %q", c.nodes)
+	}
+}
+
+// TestRedisClusterNodes_Validate 测试过滤后为空的节点列表无法通过配置校验
+func TestRedisClusterNodes_Validate(t *testing.T) {
+	preserveAppConfig(t)
+
+	t.Setenv("SECKILL_REDIS_CLUSTER_NODES", "   ")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "redis cluster nodes are required")
+
+	t.Setenv("SECKILL_REDIS_CLUSTER_NODES", " , ,")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "no valid redis cluster nodes")
+
+	t.Setenv("SECKILL_REDIS_CLUSTER_NODES", "127.0.0.1:7000, ,127.0.0.1:7001")
+	require.NoError(t, loadConfigDocument(t, 8100))
+	assert.Equal(t, []string{"127.0.0.1:7000", "127.0.0.1:7001"}, config.AppConfig.Redis.GetRedisClusterNodes())
+}
+
+// TestKafkaBrokersAndEtcdEndpoints_FilterEmpty 测试Kafka broker和Etcd端点去除空白并过滤空条目
+func TestKafkaBrokersAndEtcdEndpoints_FilterEmpty(t *testing.T) {
+	cases := []struct {
+		addresses string
+		want      []string
+	}{
+		{"127.0.0.1:9092", []string{"127.0.0.1:9092"}},
+		{"127.0.0.1:9092, 127.0.0.1:9094 ,", []string{"127.0.0.1:9092", "127.0.0.1:9094"}},
+		{",,127.0.0.1:9092,,", []string{"127.0.0.1:9092"}},
+		{"  ", nil},
+		{" , ", nil},
+	}
+	for _, c := range cases {
+		kc := config.KafkaConfig{Brokers: c.addresses}
+		assert.Equal(t, c.want, kc.GetKafkaBrokers(), "brokers %q", c.addresses)
+		ec := config.EtcdConfig{Host: c.addresses}
+		assert.Equal(t, c.want, ec.GetEtcdEndpoints(), "etcd host %q", c.addresses)
+	}
+}
+
+// TestKafkaBrokersAndEtcdEndpoints_Validate 测试过滤后为空的broker和端点列表无法通过配置校验
+func TestKafkaBrokersAndEtcdEndpoints_Validate(t *testing.T) {
+	preserveAppConfig(t)
+
+	t.Setenv("SECKILL_KAFKA_BROKERS", " ")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "kafka brokers are required")
+	t.Setenv("SECKILL_KAFKA_BROKERS", ", ,")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "no valid kafka brokers")
+	t.Setenv("SECKILL_KAFKA_BROKERS", "127.0.0.1:9092,")
+
+	t.Setenv("SECKILL_ETCD_HOST", "  ")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "etcd host is required")
+	t.Setenv("SECKILL_ETCD_HOST", ",")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "no valid etcd endpoints")
+
+	t.Setenv("SECKILL_ETCD_HOST", " 127.0.0.1:2379 , 127.0.0.1:22379")
+	require.NoError(t, loadConfigDocument(t, 8100))
+	assert.Equal(t, []string{"127.0.0.1:9092"}, config.AppConfig.Kafka.GetKafkaBrokers())
+	assert.Equal(t, []string{"127.0.0.1:2379", "127.0.0.1:22379"}, config.AppConfig.Etcd.GetEtcdEndpoints())
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"context"
+	"log/slog"
+	"seckill_system/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// envOverrideValues 返回环境变量覆盖日志中各字段记录的取值
+func (h *logRecorder) envOverrideValues() map[string]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	values := make(map[string]string)
+	for _, record := range h.records {
+		if record.Message != "Config value overridden by environment variable" {
+			continue
+		}
+		var field, value string
+		record.Attrs(func(attr slog.Attr) bool {
+			switch attr.Key {
+			case "field":
+				field = attr.Value.String()
+			case "value":
+				value = attr.Value.String()
+			}
+			return true
+		})
+		values[field] = value
+	}
+	return values
+}
+
+// loadConfigDocument 从模拟Etcd加载配置文档，与本地文件共用解析流程
+func loadConfigDocument(t *testing.T, port int) error {
+	t.Helper()
+	kv := NewMockEtcdKV()
+	kv.Data[configDocumentKey] = configDocument(port, t.TempDir())
+	return config.InitConfigFromKV(context.Background(), kv, configDocumentKey)
+}
+
+// TestEnvOverride_TakesPrecedence 测试环境变量优先于配置文档，敏感字段在日志中脱敏
+func TestEnvOverride_TakesPrecedence(t *testing.T) {
+	preserveAppConfig(t)
+	logs := captureLogs(t)
+	t.Setenv("SECKILL_DATABASE_PASSWORD", "s3cret")
+	t.Setenv("SECKILL_REDIS_CLUSTER_NODES", "10.0.0.1:7000,10.0.0.2:7000")
+	t.Setenv("SECKILL_SERVER_PORT", "9100")
+	t.Setenv("SECKILL_TOKEN_SECKILL_TOKEN_TTL", "10m")
+	t.Setenv("SECKILL_SERVER_INFLIGHT_EXEMPT_PATHS", "/health, /metrics")
+
+	require.NoError(t, loadConfigDocument(t, 8100))
+	cfg := config.AppConfig
+	assert.Equal(t, "s3cret", cfg.Database.Password)
+	assert.Equal(t, "10.0.0.1:7000,10.0.0.2:7000", cfg.Redis.ClusterNodes)
+	assert.Equal(t, 9100, cfg.Server.Port)
+	assert.Equal(t, 10*time.Minute, cfg.Token.SeckillTokenTTL)
+	assert.Equal(t, []string{"/health", "/metrics"}, cfg.Server.InflightExemptPaths)
+	assert.Equal(t, "root", cfg.Database.User) // 未设置环境变量的字段保留文档中的值
+
+	values := logs.envOverrideValues()
+	assert.Equal(t, "******", values["database.password"])
+	assert.Equal(t, "9100", values["server.port"])
+	assert.Len(t, values, 5)
+}
+
+// TestEnvOverride_InvalidValue 测试环境变量取值无法解析时加载失败
+func TestEnvOverride_InvalidValue(t *testing.T) {
+	preserveAppConfig(t)
+	t.Setenv("SECKILL_SERVER_PORT", "eighty")
+
+	err := loadConfigDocument(t, 8100)
+	assert.ErrorContains(t, err, "SECKILL_SERVER_PORT")
+}
+
+// TestEnvOverride_ValidatedAfterOverride 测试覆盖后的配置仍需通过校验
+func TestEnvOverride_ValidatedAfterOverride(t *testing.T) {
+	preserveAppConfig(t)
+	t.Setenv("SECKILL_DATABASE_HOST", "")
+
+	err := loadConfigDocument(t, 8100)
+	assert.ErrorContains(t, err, "validation failed")
+}
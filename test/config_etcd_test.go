@@ -0,0 +1,148 @@
+package test
+
+import (
+	"context"
+	"log/slog"
+	"seckill_system/config"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// configDocumentKey 测试使用的配置文档键
+const configDocumentKey = "/seckill/config/document"
+
+// configDocument 返回一份有效的YAML配置文档，日志写入dir
+func configDocument(port int, dir string) string {
+	return `
+server:
+  port: ` + strconv.Itoa(port) + `
+database:
+  host: 127.0.0.1
+  port: 3306
+  user: root
+  name: seckill_db
+redis:
+  cluster_nodes: 127.0.0.1:7000
+kafka:
+  brokers: 127.0.0.1:9092
+  topic: seckill_orders
+etcd:
+  host: 127.0.0.1:2379
+  dial_timeout: 5
+log:
+  file_path: ` + dir + `
+seckill:
+  mode: redis
+`
+}
+
+// preserveAppConfig 测试结束后恢复全局配置和默认logger
+func preserveAppConfig(t *testing.T) {
+	t.Helper()
+	previousConfig, previousLogger := config.AppConfig, slog.Default()
+	t.Cleanup(func() {
+		config.AppConfig = previousConfig
+		slog.SetDefault(previousLogger)
+	})
+}
+
+// TestInitConfigFromKV_LoadsAndValidates 测试从Etcd读取的配置文档按文件配置相同的方式解析和校验
+func TestInitConfigFromKV_LoadsAndValidates(t *testing.T) {
+	preserveAppConfig(t)
+	kv := NewMockEtcdKV()
+	kv.Data[configDocumentKey] = configDocument(8100, t.TempDir())
+
+	assert.NoError(t, config.InitConfigFromKV(context.Background(), kv, configDocumentKey))
+	assert.Equal(t, 8100, config.AppConfig.Server.Port)
+	assert.Equal(t, config.SeckillModeRedis, config.AppConfig.Seckill.Mode)
+	assert.Equal(t, "127.0.0.1", config.AppConfig.Server.AdminHost) // 校验时填充的默认值
+	assert.Equal(t, 3, config.AppConfig.Kafka.InitRetries)
+}
+
+// TestInitConfigFromKV_JSONDocument 测试JSON格式的配置文档
+func TestInitConfigFromKV_JSONDocument(t *testing.T) {
+	preserveAppConfig(t)
+	kv := NewMockEtcdKV()
+	kv.Data[configDocumentKey] = `{
+		"server": {"port": 8200},
+		"database": {"host": "127.0.0.1", "port": 3306, "user": "root", "name": "seckill_db"},
+		"redis": {"cluster_nodes": "127.0.0.1:7000"},
+		"kafka": {"brokers": "127.0.0.1:9092", "topic": "seckill_orders"},
+		"etcd": {"host": "127.0.0.1:2379", "dial_timeout": 5},
+		"log": {"file_path": "` + t.TempDir() + `"}
+	}`
+
+	assert.NoError(t, config.InitConfigFromKV(context.Background(), kv, configDocumentKey))
+	assert.Equal(t, 8200, config.AppConfig.Server.Port)
+}
+
+// TestInitConfigFromKV_Invalid 测试缺失或校验失败的配置文档不替换全局配置
+func TestInitConfigFromKV_Invalid(t *testing.T) {
+	preserveAppConfig(t)
+	current := &config.Config{}
+	config.AppConfig = current
+	kv := NewMockEtcdKV()
+
+	err := config.InitConfigFromKV(context.Background(), kv, configDocumentKey)
+	assert.ErrorContains(t, err, "not found")
+
+	kv.Data[configDocumentKey] = configDocument(70000, t.TempDir())
+	err = config.InitConfigFromKV(context.Background(), kv, configDocumentKey)
+	assert.ErrorContains(t, err, "config validation failed")
+
+	kv.Data[configDocumentKey] = "server: [unterminated"
+	err = config.InitConfigFromKV(context.Background(), kv, configDocumentKey)
+	assert.ErrorContains(t, err, "failed to unmarshal config")
+	assert.Same(t, current, config.AppConfig)
+}
+
+// fakeWatcher 通过通道推送事件的模拟Watcher
+type fakeWatcher struct {
+	events chan clientv3.WatchResponse
+}
+
+// Watch 返回事件通道
+func (w *fakeWatcher) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	return w.events
+}
+
+// RequestProgress 无操作
+func (w *fakeWatcher) RequestProgress(ctx context.Context) error {
+	return nil
+}
+
+// Close 无操作
+func (w *fakeWatcher) Close() error {
+	return nil
+}
+
+// putEvent 构造写入配置文档的监听事件
+func putEvent(value string) clientv3.WatchResponse {
+	return clientv3.WatchResponse{Events: []*clientv3.Event{{
+		Type: clientv3.EventTypePut,
+		Kv:   &mvccpb.KeyValue{Key: []byte(configDocumentKey), Value: []byte(value)},
+	}}}
+}
+
+// TestWatchConfig_Reload 测试有效的配置变更替换全局配置，无效变更被忽略
+func TestWatchConfig_Reload(t *testing.T) {
+	preserveAppConfig(t)
+	config.AppConfig = &config.Config{}
+	watcher := &fakeWatcher{events: make(chan clientv3.WatchResponse, 2)}
+	watcher.events <- putEvent(configDocument(70000, t.TempDir())) // 端口无效
+	watcher.events <- putEvent(configDocument(8300, t.TempDir()))
+	close(watcher.events)
+
+	var reloaded []*config.Config
+	config.WatchConfig(context.Background(), watcher, configDocumentKey, func(cfg *config.Config) {
+		reloaded = append(reloaded, cfg)
+	})
+
+	assert.Len(t, reloaded, 1)
+	assert.Equal(t, 8300, config.AppConfig.Server.Port)
+	assert.Same(t, reloaded[0], config.AppConfig)
+}
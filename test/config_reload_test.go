@@ -0,0 +1,107 @@
+package test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"seckill_system/config"
+	"seckill_system/global"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reloadDocument 返回指定端口、日志级别和最大打开连接数的配置文档，日志写入dir
+func reloadDocument(port int, dir, level string, maxOpenConns int) string {
+	return `
+server:
+  port: ` + strconv.Itoa(port) + `
+database:
+  host: 127.0.0.1
+  port: 3306
+  user: root
+  name: seckill_db
+  max_open_conns: ` + strconv.Itoa(maxOpenConns) + `
+redis:
+  cluster_nodes: 127.0.0.1:7000
+kafka:
+  brokers: 127.0.0.1:9092
+  topic: seckill_orders
+etcd:
+  host: 127.0.0.1:2379
+  dial_timeout: 5
+log:
+  file_path: ` + dir + `
+  level: ` + level + `
+`
+}
+
+// setupReloadConfig 写入配置文件并以其初始化全局配置，返回配置文件路径
+func setupReloadConfig(t *testing.T) (string, string) {
+	t.Helper()
+	preserveAppConfig(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "conf.yaml")
+	writeReloadConfig(t, path, reloadDocument(8300, dir, "info", 0))
+	require.NoError(t, config.InitConfig(path))
+	return path, dir
+}
+
+// writeReloadConfig 覆盖写入配置文件
+func writeReloadConfig(t *testing.T, path, document string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(document), 0644))
+}
+
+// TestReloadConfig_AppliesLogLevelAndPool 测试热加载日志级别和数据库连接池大小
+func TestReloadConfig_AppliesLogLevelAndPool(t *testing.T) {
+	path, dir := setupReloadConfig(t)
+	assert.Equal(t, slog.LevelInfo, config.LogLevel())
+	assert.False(t, slog.Default().Enabled(t.Context(), slog.LevelDebug))
+	assert.Equal(t, config.DefaultDBMaxOpenConns, config.AppConfig.Database.OpenConns())
+
+	writeReloadConfig(t, path, reloadDocument(8300, dir, "debug", 30))
+	cfg, err := config.ReloadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, config.LogLevel())
+	assert.True(t, slog.Default().Enabled(t.Context(), slog.LevelDebug)) // 已有处理器无需重建即按新级别输出
+	assert.Equal(t, "debug", config.AppConfig.Log.Level)
+	assert.Equal(t, 30, config.AppConfig.Database.OpenConns())
+
+	db := SetupTestDB(t)
+	require.NoError(t, global.ApplyMySQLPool(cfg.Database))
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	assert.Equal(t, 30, sqlDB.Stats().MaxOpenConnections)
+}
+
+// TestReloadConfig_RestartOnlyFieldsIgnored 测试需要重启的配置项变化只记录日志并保持当前值
+func TestReloadConfig_RestartOnlyFieldsIgnored(t *testing.T) {
+	path, dir := setupReloadConfig(t)
+	logs := captureLogs(t)
+
+	writeReloadConfig(t, path, reloadDocument(8400, dir, "warn", 0))
+	_, err := config.ReloadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 8300, config.AppConfig.Server.Port)
+	assert.Equal(t, slog.LevelWarn, config.LogLevel())
+	assert.Equal(t, []slog.Level{slog.LevelWarn}, logs.levelsOf("Config change ignored, requires restart"))
+}
+
+// TestReloadConfig_InvalidKeepsCurrent 测试配置文件无效时返回错误并继续使用当前配置
+func TestReloadConfig_InvalidKeepsCurrent(t *testing.T) {
+	path, _ := setupReloadConfig(t)
+	current := config.AppConfig
+
+	writeReloadConfig(t, path, "server:\n  port: 8300\nlog:\n  level: debug\n") // 缺少数据库等必需配置
+	_, err := config.ReloadConfig(path)
+	assert.Error(t, err)
+	assert.Same(t, current, config.AppConfig)
+	assert.Equal(t, slog.LevelInfo, config.LogLevel())
+
+	_, err = config.ReloadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+	assert.Same(t, current, config.AppConfig)
+}
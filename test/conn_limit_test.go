@@ -0,0 +1,128 @@
+package test
+
+import (
+	"errors"
+	"net"
+	"seckill_system/config"
+	"seckill_system/web/middleware"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startConnLimitServer 启动带单IP连接数上限的TCP服务，被接受的连接通过返回的通道交给测试
+func startConnLimitServer(t *testing.T, maxPerIP int, exemptCIDRs []string) (string, <-chan net.Conn) {
+	t.Helper()
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener, err := middleware.NewConnLimitListener(inner, maxPerIP, exemptCIDRs)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	accepted := make(chan net.Conn, 64)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+			accepted <- conn
+		}
+	}()
+	return inner.Addr().String(), accepted
+}
+
+// dial 建立客户端连接
+func dial(t *testing.T, addr string) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// assertClosedByServer 断言服务端已关闭连接（读取返回错误）
+func assertClosedByServer(t *testing.T, conn net.Conn) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err := conn.Read(make([]byte, 1))
+	require.Error(t, err)
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		assert.False(t, netErr.Timeout(), "connection should be closed by server, not time out")
+	}
+}
+
+// TestConnLimit_RejectsExcessFromOneIP 测试同一IP的连接数达到上限后新连接被直接关闭，连接关闭后名额释放
+func TestConnLimit_RejectsExcessFromOneIP(t *testing.T) {
+	const maxPerIP = 3
+	addr, accepted := startConnLimitServer(t, maxPerIP, nil)
+
+	// 上限内的连接全部被接受
+	var serverConns []net.Conn
+	for i := 0; i < maxPerIP; i++ {
+		dial(t, addr)
+		select {
+		case conn := <-accepted:
+			serverConns = append(serverConns, conn)
+		case <-time.After(2 * time.Second):
+			t.Fatal("connection within the cap was not accepted")
+		}
+	}
+
+	// 模拟同一IP继续打开大量连接，超出上限的连接都被关闭且不交给服务处理
+	for i := 0; i < 20; i++ {
+		assertClosedByServer(t, dial(t, addr))
+	}
+	assert.Empty(t, accepted)
+
+	// 关闭一个连接后释放名额，新连接可以被接受
+	require.NoError(t, serverConns[0].Close())
+	dial(t, addr)
+	select {
+	case <-accepted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection was not accepted after a slot was released")
+	}
+}
+
+// TestConnLimit_ExemptCIDR 测试豁免网段内的连接不受上限约束
+func TestConnLimit_ExemptCIDR(t *testing.T) {
+	addr, accepted := startConnLimitServer(t, 1, []string{"127.0.0.0/8"})
+	for i := 0; i < 5; i++ {
+		dial(t, addr)
+		select {
+		case <-accepted:
+		case <-time.After(2 * time.Second):
+			t.Fatal("connection from exempt range was not accepted")
+		}
+	}
+}
+
+// TestConnLimit_Disabled 测试上限为0时直接返回原监听器
+func TestConnLimit_Disabled(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer inner.Close()
+	listener, err := middleware.NewConnLimitListener(inner, 0, nil)
+	require.NoError(t, err)
+	assert.Same(t, inner, listener)
+}
+
+// TestConnLimit_Config 测试连接数上限配置的默认值和校验
+func TestConnLimit_Config(t *testing.T) {
+	preserveAppConfig(t)
+	require.NoError(t, loadConfigDocument(t, 8100))
+	assert.Equal(t, 0, config.AppConfig.Server.MaxConnsPerIP)
+	assert.Equal(t, config.DefaultConnLimitExemptCIDRs, config.AppConfig.Server.ConnLimitExemptCIDRs)
+
+	t.Setenv("SECKILL_SERVER_MAX_CONNS_PER_IP", "-1")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "max_conns_per_ip must not be negative")
+
+	t.Setenv("SECKILL_SERVER_MAX_CONNS_PER_IP", "100")
+	t.Setenv("SECKILL_SERVER_CONN_LIMIT_EXEMPT_CIDRS", "10.0.0.0/8, not-a-cidr")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "invalid CIDR")
+}
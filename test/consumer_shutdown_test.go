@@ -0,0 +1,76 @@
+package test
+
+import (
+	"context"
+	"seckill_system/config"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestShutdown_StopsKafkaConsumers 测试关闭服务时取消上下文使阻塞在读取消息的消费循环退出
+func TestShutdown_StopsKafkaConsumers(t *testing.T) {
+	SetupTestRedis(t)
+	SetupTestKafka(t) // Broker不可达，消费循环会一直阻塞在读取消息
+	gs := &service.GoodService{
+		RedisRepo: repository.NewRedisRepository(),
+		KafkaRepo: repository.NewKafkaRepository(),
+	}
+	gs.StartOrderConsumer()
+	gs.StartPaymentConsumer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	summary := gs.Shutdown(ctx, start)
+
+	assert.True(t, summary.ConsumersStopped)
+	assert.True(t, summary.ConsumersDrained)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// TestShutdown_StopsBackgroundTasks 测试关闭服务时定时扫描任务随生命周期上下文退出，关闭等待其结束
+func TestShutdown_StopsBackgroundTasks(t *testing.T) {
+	SetupTestRedis(t)
+	gs := &service.GoodService{
+		RedisRepo:    repository.NewRedisRepository(),
+		Seckill:      config.SeckillConfig{StockRefreshSeconds: 60},
+		PaymentGrace: time.Minute,
+	}
+	gs.StartLifecycleWatcher()
+	gs.StartStockRefresher()
+	gs.StartPaymentRetrySweeper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	summary := gs.Shutdown(ctx, start)
+
+	assert.True(t, summary.ConsumersStopped)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// TestShutdown_FlushesPendingOrders 测试关闭服务时订单写库任务退出前写入已受理的Redis-only订单
+func TestShutdown_FlushesPendingOrders(t *testing.T) {
+	f := SetupTestService(t, WithGoods(5, 1), WithSeckillConfig(config.SeckillConfig{Mode: config.SeckillModeRedis, FlushIntervalSeconds: 60}))
+	gs, db := f.Service, f.DB
+	_, err := gs.SeckillHandler.CreateOrderRedisOnly(context.Background(), 100, 1, 1)
+	assert.NoError(t, err)
+	gs.StartPendingOrderFlusher() // 写库间隔60秒，测试期间不会按周期写库
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	summary := gs.Shutdown(ctx, time.Now())
+	assert.True(t, summary.ConsumersStopped)
+
+	pending, err := gs.RedisRepo.PendingOrderLen()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), pending)
+	var orders int64
+	assert.NoError(t, db.Model(&model.SuccessKilled{}).Where("goods_id = ?", 1).Count(&orders).Error)
+	assert.Equal(t, int64(1), orders)
+}
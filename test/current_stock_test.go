@@ -0,0 +1,81 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/errs"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getCurrentStock 请求商品库存接口并返回状态码和data字段
+func getCurrentStock(t *testing.T, r *gin.Engine, id string) (int, map[string]any) {
+	req := httptest.NewRequest("GET", "/api/goods/"+id+"/stock", nil)
+	req.RemoteAddr = "203.0.113.7:1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp.Data
+}
+
+// TestGetStockAtomic_NotLoaded 测试库存未预加载时原子读取返回ErrStockNotFound
+func TestGetStockAtomic_NotLoaded(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+
+	_, err := repo.GetStockAtomic(1)
+	assert.ErrorIs(t, err, errs.ErrStockNotFound)
+
+	assert.NoError(t, repo.SetGoodsStock(1, 0))
+	stock, err := repo.GetStockAtomic(1)
+	assert.NoError(t, err)
+	assert.Zero(t, stock)
+}
+
+// TestCurrentStock_Endpoint 测试库存查询接口区分未预加载、有库存和售罄
+func TestCurrentStock_Endpoint(t *testing.T) {
+	r, gs, _ := setupIPRateLimitRouter(t)
+
+	status, data := getCurrentStock(t, r, "1")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, model.StockStatusNotLoaded, data["status"])
+	assert.NotContains(t, data, "stock")
+	assert.NotContains(t, data, "sold_out")
+
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(1, 5))
+	status, data = getCurrentStock(t, r, "1")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, model.StockStatusLoaded, data["status"])
+	assert.Equal(t, float64(5), data["stock"])
+	assert.Equal(t, false, data["sold_out"])
+
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(1, 0))
+	_, data = getCurrentStock(t, r, "1")
+	assert.Equal(t, float64(0), data["stock"])
+	assert.Equal(t, true, data["sold_out"])
+
+	status, _ = getCurrentStock(t, r, "abc")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+// TestCurrentStock_NoAuthNoRateLimit 测试库存查询接口无需认证且不计入限流
+func TestCurrentStock_NoAuthNoRateLimit(t *testing.T) {
+	r, gs, mr := setupIPRateLimitRouter(t)
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(1, 5))
+
+	// IP限流为2次/分钟，多次查询均不受影响
+	for i := 0; i < 5; i++ {
+		status, _ := getCurrentStock(t, r, "1")
+		assert.Equal(t, http.StatusOK, status, "request %d", i+1)
+	}
+	assert.False(t, mr.Exists(repository.IPRateLimitKey("203.0.113.7")))
+}
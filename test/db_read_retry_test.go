@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"seckill_system/global"
+	"seckill_system/repository"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// lockWaitTimeoutErr 模拟MySQL锁等待超时错误
+var lockWaitTimeoutErr = &mysql.MySQLError{Number: 1205, SQLState: [5]byte{'H', 'Y', '0', '0', '0'}, Message: "Lock wait timeout exceeded; try restarting transaction"}
+
+// injectQueryFailures 在查询执行前注入错误，模拟被锁住的表
+// fail返回非nil时该次查询失败，返回已执行的查询次数计数器
+func injectQueryFailures(t *testing.T, db *gorm.DB, fail func(tx *gorm.DB, call int64) error) *atomic.Int64 {
+	var calls atomic.Int64
+	err := db.Callback().Query().Before("gorm:query").Register("test:inject_failure", func(tx *gorm.DB) {
+		if err := fail(tx, calls.Add(1)); err != nil {
+			tx.AddError(err)
+		}
+	})
+	assert.NoError(t, err)
+	return &calls
+}
+
+// setDBReadTimeout 设置只读查询超时，测试结束后恢复
+func setDBReadTimeout(t *testing.T, timeout time.Duration) {
+	previous := global.DBReadTimeout
+	global.DBReadTimeout = timeout
+	t.Cleanup(func() { global.DBReadTimeout = previous })
+}
+
+// TestIsTransientReadError 测试只读查询瞬时错误的识别
+func TestIsTransientReadError(t *testing.T) {
+	assert.True(t, repository.IsTransientReadError(lockWaitTimeoutErr))
+	assert.True(t, repository.IsTransientReadError(&mysql.MySQLError{Number: 3024}))
+	assert.True(t, repository.IsTransientReadError(fmt.Errorf("query: %w", deadlockErr)))
+	assert.True(t, repository.IsTransientReadError(fmt.Errorf("query: %w", context.DeadlineExceeded)))
+	assert.False(t, repository.IsTransientReadError(gorm.ErrRecordNotFound))
+	assert.False(t, repository.IsTransientReadError(&mysql.MySQLError{Number: 1146})) // 表不存在
+	assert.False(t, repository.IsTransientReadError(nil))
+}
+
+// TestFindGoodById_RetriesLockWaitTimeout 测试锁等待超时后重试查询成功
+func TestFindGoodById_RetriesLockWaitTimeout(t *testing.T) {
+	db := SetupTestDB(t)
+	good := CreateTestGoods(1)
+	assert.NoError(t, db.Create(&good).Error)
+	calls := injectQueryFailures(t, db, func(_ *gorm.DB, call int64) error {
+		if call == 1 {
+			return lockWaitTimeoutErr
+		}
+		return nil
+	})
+	repo := repository.NewGoodRepository()
+
+	found, err := repo.FindGoodById(1)
+	assert.NoError(t, err)
+	assert.Equal(t, good.Title, found.Title)
+	assert.Equal(t, int64(2), calls.Load())
+}
+
+// TestFindGoodById_PerCallTimeout 测试查询阻塞超过单次超时后重试，而不是一直等待
+func TestFindGoodById_PerCallTimeout(t *testing.T) {
+	db := SetupTestDB(t)
+	good := CreateTestGoods(1)
+	assert.NoError(t, db.Create(&good).Error)
+	setDBReadTimeout(t, 20*time.Millisecond)
+	calls := injectQueryFailures(t, db, func(tx *gorm.DB, call int64) error {
+		if call == 1 {
+			<-tx.Statement.Context.Done() // 表被锁，阻塞到超时
+			return tx.Statement.Context.Err()
+		}
+		return nil
+	})
+	repo := repository.NewGoodRepository()
+
+	start := time.Now()
+	found, err := repo.FindGoodById(1)
+	assert.NoError(t, err)
+	assert.Equal(t, good.Title, found.Title)
+	assert.Equal(t, int64(2), calls.Load())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+// TestFindGoodById_RetriesExhausted 测试持续锁等待超时在最大次数后返回错误，且不视为商品不存在
+func TestFindGoodById_RetriesExhausted(t *testing.T) {
+	db := SetupTestDB(t)
+	calls := injectQueryFailures(t, db, func(_ *gorm.DB, _ int64) error { return lockWaitTimeoutErr })
+	repo := repository.NewGoodRepository()
+
+	_, err := repo.FindGoodById(1)
+	assert.ErrorIs(t, err, lockWaitTimeoutErr)
+	assert.False(t, errors.Is(err, gorm.ErrRecordNotFound))
+	assert.Equal(t, int64(repository.MaxReadAttempts), calls.Load())
+}
+
+// TestFindGoodById_NotFoundNotRetried 测试商品不存在时不重试
+func TestFindGoodById_NotFoundNotRetried(t *testing.T) {
+	db := SetupTestDB(t)
+	calls := injectQueryFailures(t, db, func(_ *gorm.DB, _ int64) error { return nil })
+	repo := repository.NewGoodRepository()
+
+	_, err := repo.FindGoodById(1)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Equal(t, int64(1), calls.Load())
+}
+
+// TestGetGoodInfo_LockedTableFallsBackToCache 测试表持续被锁时降级返回缓存数据
+func TestGetGoodInfo_LockedTableFallsBackToCache(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	good := CreateTestGoods(1)
+	assert.NoError(t, db.Create(&good).Error)
+	gs := newGoodsInfoService()
+
+	// 先正常查询一次写入缓存，再模拟表被锁
+	_, stale, err := gs.GetGoodInfo(1)
+	assert.NoError(t, err)
+	assert.False(t, stale)
+	injectQueryFailures(t, db, func(_ *gorm.DB, _ int64) error { return lockWaitTimeoutErr })
+
+	result, stale, err := gs.GetGoodInfo(1)
+	assert.NoError(t, err)
+	assert.True(t, stale)
+	assert.Equal(t, good.Title, result.Title)
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"context"
+	"seckill_system/repository"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -19,21 +20,48 @@ func TestETCDRepository_DistributedLock(t *testing.T) {
 	ctx := context.Background()
 
 	// 第一次获取锁应该成功
-	locked, err := mockETCD.GetDistributedLock(ctx, "test-key", 10)
+	token, locked, err := mockETCD.GetDistributedLock(ctx, "test-key", 10)
 	assert.NoError(t, err) // 验证没有错误发生
 	assert.True(t, locked) // 验证成功获取到锁
 
 	// 第二次获取相同锁应该失败（锁已被占用）
-	locked, err = mockETCD.GetDistributedLock(ctx, "test-key", 10)
+	_, locked, err = mockETCD.GetDistributedLock(ctx, "test-key", 10)
 	assert.NoError(t, err)  // 验证没有错误发生
 	assert.False(t, locked) // 验证获取锁失败（因为锁已被占用）
 
 	// 释放锁
-	err = mockETCD.ReleaseDistributedLock(ctx, "test-key")
+	err = mockETCD.ReleaseDistributedLock(ctx, "test-key", token)
 	assert.NoError(t, err) // 验证释放锁操作没有错误
 
 	// 释放后可以重新获取锁
-	locked, err = mockETCD.GetDistributedLock(ctx, "test-key", 10)
+	_, locked, err = mockETCD.GetDistributedLock(ctx, "test-key", 10)
 	assert.NoError(t, err) // 验证没有错误发生
 	assert.True(t, locked) // 验证成功重新获取到锁
 }
+
+// TestETCDRepository_ReleaseRequiresToken 测试锁过期后被其他请求重新获取时，原持有者释放不会删除新持有者的锁
+func TestETCDRepository_ReleaseRequiresToken(t *testing.T) {
+	etcdRepo, kv, _ := setupLockList(t)
+	ctx := context.Background()
+	key := "/seckill/locks/seckill_user_1"
+
+	staleToken, locked, err := etcdRepo.GetDistributedLock(ctx, key, 10)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+	assert.Equal(t, staleToken, kv.Data[key]) // 锁的值为持有者令牌
+
+	// 模拟租约过期后锁被其他请求重新获取
+	_, err = kv.Delete(ctx, key)
+	assert.NoError(t, err)
+	token, locked, err := etcdRepo.GetDistributedLock(ctx, key, 10)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+	assert.NotEqual(t, staleToken, token)
+
+	assert.ErrorIs(t, etcdRepo.ReleaseDistributedLock(ctx, key, staleToken), repository.ErrLockNotHeld)
+	assert.Equal(t, token, kv.Data[key])
+
+	assert.NoError(t, etcdRepo.ReleaseDistributedLock(ctx, key, token))
+	assert.NotContains(t, kv.Data, key)
+	assert.ErrorIs(t, etcdRepo.ReleaseDistributedLock(ctx, key, token), repository.ErrLockNotHeld)
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/handler"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestErrs_Hierarchy 测试错误归属上级类别以及错误码提取
+func TestErrs_Hierarchy(t *testing.T) {
+	wrapped := fmt.Errorf("invalid seckill token: %w", errs.ErrTokenExpired)
+	assert.ErrorIs(t, wrapped, errs.ErrTokenExpired)
+	assert.ErrorIs(t, wrapped, errs.ErrInvalidToken)
+	assert.NotErrorIs(t, wrapped, errs.ErrTokenMismatch)
+	assert.NotErrorIs(t, wrapped, errs.ErrNotFound)
+	assert.Equal(t, "token_expired", errs.Code(wrapped))
+	assert.Equal(t, "", errs.Code(errors.New("plain")))
+
+	assert.ErrorIs(t, errs.ErrPromotionNotFound, errs.ErrNotFound)
+	assert.ErrorIs(t, errs.ErrBlacklisted, errs.ErrForbidden)
+	assert.NotErrorIs(t, errs.ErrNotFound, errs.ErrPromotionNotFound) // 类别不属于其下级错误
+	assert.Equal(t, "too many requests", errs.ErrRateLimited.Error())
+}
+
+// TestErrs_ThroughLayers 测试各层包装后仍能识别售罄、限流、令牌无效和资源不存在
+func TestErrs_ThroughLayers(t *testing.T) {
+	SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	h := handler.NewSeckillHandler()
+	redisRepo := repository.NewRedisRepository()
+
+	// 售罄：Redis Lua脚本 → 下单流程
+	assert.NoError(t, redisRepo.SetGoodsStock(1, 0))
+	_, err := h.CreateOrder(context.Background(), 1, 1, 1)
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+	_, err = h.CreateOrderRedisOnly(context.Background(), 1, 1, 1)
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+
+	// 促销不存在：数据库 → 事务 → 下单流程，同时属于资源不存在类别
+	assert.NoError(t, redisRepo.SetGoodsStock(2, 1))
+	_, err = h.CreateOrder(context.Background(), 1, 2, 1)
+	assert.ErrorIs(t, err, errs.ErrPromotionNotFound)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	// 令牌无效：Redis → 服务层
+	gs := &service.GoodService{RedisRepo: redisRepo}
+	_, err = gs.SeckillWithToken(1, 1, absentToken)
+	assert.ErrorIs(t, err, errs.ErrTokenNotFound)
+	assert.ErrorIs(t, err, errs.ErrInvalidToken)
+
+	tokenId, err := redisRepo.GenerateSeckillToken(1, 1)
+	assert.NoError(t, err)
+	_, err = gs.SeckillWithToken(2, 1, tokenId)
+	assert.ErrorIs(t, err, errs.ErrTokenMismatch)
+
+	// 用户令牌不存在
+	_, err = redisRepo.VerifyUserToken(absentToken)
+	assert.ErrorIs(t, err, errs.ErrInvalidToken)
+}
+
+// TestErrs_RateLimited 测试限流错误可被识别
+func TestErrs_RateLimited(t *testing.T) {
+	gs, kv := setupAllowlistService(t, model.RateLimitAllowlist{})
+	kv.Data[global.EtcdKeyRateLimit] = "1"
+
+	assert.NoError(t, gs.CheckUserRateLimit(9, ""))
+	err := gs.CheckUserRateLimit(9, "")
+	assert.ErrorIs(t, err, errs.ErrRateLimited)
+	assert.Equal(t, "rate_limited", errs.Code(err))
+}
+
+// TestErrs_ControllerStatus 测试控制器按错误类别返回HTTP状态码
+func TestErrs_ControllerStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetupTestRedis(t)
+	SetupTestEtcd(t) // 秒杀接口先经过客户端IP限流，读取Etcd中的IP限流配置
+	redisRepo := repository.NewRedisRepository()
+	goodController := &controller.GoodController{GoodService: &service.GoodService{RedisRepo: redisRepo, EtcdRepo: repository.NewETCDRepository()}}
+	r := router.NewRouter(goodController, noopAuth, false)
+
+	userToken, err := redisRepo.GenerateUserToken(1)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/seckill?gid=1&token="+absentToken, nil)
+	req.Header.Set("Authorization", userToken)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid seckill token: token not found")
+}
@@ -0,0 +1,51 @@
+package test
+
+import (
+	"seckill_system/global"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSetEtcdDefaults_RetriesTransientFailure 测试临时写入失败会被重试并最终成功
+func TestSetEtcdDefaults_RetriesTransientFailure(t *testing.T) {
+	kv := NewMockEtcdKV()
+	kv.PutFailures = 2 // 前两次写入失败
+
+	err := global.SetEtcdDefaults(kv, map[string]string{
+		global.EtcdKeyRateLimit: "10",
+	}, 3, time.Millisecond)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, kv.PutCalls) // 两次失败后第三次成功
+	assert.Equal(t, "10", kv.Data[global.EtcdKeyRateLimit])
+}
+
+// TestSetEtcdDefaults_PersistentFailure 测试重试耗尽后返回包含失败配置项的错误
+func TestSetEtcdDefaults_PersistentFailure(t *testing.T) {
+	kv := NewMockEtcdKV()
+	kv.PutFailures = 100 // 持续失败
+
+	err := global.SetEtcdDefaults(kv, map[string]string{
+		global.EtcdKeyRateLimit: "10",
+	}, 2, time.Millisecond)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), global.EtcdKeyRateLimit)
+	assert.Equal(t, 3, kv.PutCalls) // 首次尝试加两次重试
+}
+
+// TestSetEtcdDefaults_KeepsExistingValue 测试已存在的配置不会被覆盖
+func TestSetEtcdDefaults_KeepsExistingValue(t *testing.T) {
+	kv := NewMockEtcdKV()
+	kv.Data[global.EtcdKeySeckillEnabled] = "false"
+
+	err := global.SetEtcdDefaults(kv, map[string]string{
+		global.EtcdKeySeckillEnabled: "true",
+	}, 3, time.Millisecond)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, kv.PutCalls)
+	assert.Equal(t, "false", kv.Data[global.EtcdKeySeckillEnabled])
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enableEtcdCacheFallback 开启Etcd降级缓存，测试结束后恢复
+func enableEtcdCacheFallback(t *testing.T, enabled bool) {
+	t.Helper()
+	previous := global.EtcdCacheFallback
+	global.EtcdCacheFallback = enabled
+	t.Cleanup(func() { global.EtcdCacheFallback = previous })
+}
+
+// TestEtcdFallback_ServesLastKnownValues 测试Etcd不可用时返回最近一次成功读取的值
+func TestEtcdFallback_ServesLastKnownValues(t *testing.T) {
+	enableEtcdCacheFallback(t, true)
+	kv := SetupTestEtcd(t)
+	kv.Data[global.EtcdKeySeckillEnabled] = "false"
+	kv.Data[global.EtcdKeyRateLimit] = "5"
+	kv.Data[global.EtcdKeyBlacklist+"100"] = `{"user_id":100}`
+	repo := repository.NewETCDRepository()
+	ctx := context.Background()
+
+	enabled, err := repo.GetSeckillEnabled(ctx)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+	limit, err := repo.GetRateLimitConfig(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), limit)
+	blacklisted, err := repo.IsInBlacklist(ctx, 100)
+	require.NoError(t, err)
+	assert.True(t, blacklisted)
+	blacklisted, err = repo.IsInBlacklist(ctx, 101)
+	require.NoError(t, err)
+	assert.False(t, blacklisted)
+
+	kv.GetErr = errors.New("etcd unavailable")
+
+	enabled, err = repo.GetSeckillEnabled(ctx)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+	limit, err = repo.GetRateLimitConfig(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), limit)
+	blacklisted, err = repo.IsInBlacklist(ctx, 100)
+	assert.NoError(t, err)
+	assert.True(t, blacklisted)
+	blacklisted, err = repo.IsInBlacklist(ctx, 101)
+	assert.NoError(t, err)
+	assert.False(t, blacklisted)
+
+	// 从未成功读取过的用户没有缓存值，返回错误
+	_, err = repo.IsInBlacklist(ctx, 102)
+	assert.Error(t, err)
+
+	// Etcd恢复后读取最新值
+	kv.GetErr = nil
+	kv.Data[global.EtcdKeySeckillEnabled] = "true"
+	enabled, err = repo.GetSeckillEnabled(ctx)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+}
+
+// TestEtcdFallback_DisabledPropagatesErrors 测试未开启降级时Etcd错误原样返回
+func TestEtcdFallback_DisabledPropagatesErrors(t *testing.T) {
+	enableEtcdCacheFallback(t, false)
+	kv := SetupTestEtcd(t)
+	kv.Data[global.EtcdKeySeckillEnabled] = "true"
+	repo := repository.NewETCDRepository()
+	ctx := context.Background()
+
+	enabled, err := repo.GetSeckillEnabled(ctx)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	kv.GetErr = errors.New("etcd unavailable")
+	_, err = repo.GetSeckillEnabled(ctx)
+	assert.Error(t, err)
+	_, err = repo.IsInBlacklist(ctx, 100)
+	assert.Error(t, err)
+}
+
+// TestEtcdFallback_PrecheckPassesDuringOutage 测试Etcd故障期间秒杀资格预检使用缓存值通过开关和黑名单检查
+func TestEtcdFallback_PrecheckPassesDuringOutage(t *testing.T) {
+	enableEtcdCacheFallback(t, true)
+	f := SetupTestService(t, WithSeckillEnabled(3))
+
+	report := f.Service.PrecheckSeckill(100, 1)
+	require.True(t, report.Eligible, "%+v", report.Checks)
+
+	f.Etcd.GetErr = errors.New("etcd unavailable")
+	report = f.Service.PrecheckSeckill(100, 1)
+	assert.True(t, report.Eligible, "%+v", report.Checks)
+	assert.True(t, findCheck(t, report, model.CheckSeckillEnabled).Passed)
+	assert.True(t, findCheck(t, report, model.CheckNotBlacklisted).Passed)
+}
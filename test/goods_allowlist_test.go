@@ -0,0 +1,71 @@
+package test
+
+import (
+	"context"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupGoodsAllowlistService 在满足全部秒杀条件的环境中创建带商品准入名单的商品服务
+func setupGoodsAllowlistService(t *testing.T, fallback model.GoodsAllowlist) (*service.GoodService, *MockEtcdKV) {
+	f := SetupTestService(t, WithSeckillEnabled(3), WithSeckillHandler())
+	f.Service.ApprovedGoods = service.NewGoodsAllowlist(fallback)
+	return f.Service, f.Etcd
+}
+
+// TestGoodsAllowlist_NotApprovedRejected 测试不在准入名单中的商品即使处于活动时间内也被拒绝
+func TestGoodsAllowlist_NotApprovedRejected(t *testing.T) {
+	gs, _ := setupGoodsAllowlistService(t, model.GoodsAllowlist{GoodsIds: []int64{2}})
+
+	_, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	assert.ErrorIs(t, err, errs.ErrGoodsNotApproved)
+	assert.ErrorIs(t, err, errs.ErrForbidden)
+
+	// 拒绝时不消耗限流次数
+	count, err := gs.RedisRepo.GetUserRateCount(100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+// TestGoodsAllowlist_ApprovedAllowed 测试准入名单中的商品正常签发令牌
+func TestGoodsAllowlist_ApprovedAllowed(t *testing.T) {
+	gs, _ := setupGoodsAllowlistService(t, model.GoodsAllowlist{GoodsIds: []int64{1}})
+
+	tokenId, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokenId)
+}
+
+// TestGoodsAllowlist_EmptyConfigUnrestricted 测试配置文件名单为空且Etcd未配置时不限制商品
+func TestGoodsAllowlist_EmptyConfigUnrestricted(t *testing.T) {
+	gs, _ := setupGoodsAllowlistService(t, model.GoodsAllowlist{})
+
+	_, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	assert.NoError(t, err)
+}
+
+// TestGoodsAllowlist_EtcdOverridesConfig 测试Etcd中的名单覆盖配置文件，删除后恢复配置文件名单
+func TestGoodsAllowlist_EtcdOverridesConfig(t *testing.T) {
+	gs, kv := setupGoodsAllowlistService(t, model.GoodsAllowlist{})
+	kv.Data[global.EtcdKeyGoodsAllowlist] = `{"goods_ids":[2]}`
+
+	allowlist, err := gs.EtcdRepo.GetGoodsAllowlist(context.Background())
+	assert.NoError(t, err)
+	gs.ApprovedGoods.Update(allowlist)
+	_, err = gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	assert.ErrorIs(t, err, errs.ErrGoodsNotApproved)
+
+	// Etcd中的空名单同样启用准入检查
+	gs.ApprovedGoods.Update(&model.GoodsAllowlist{})
+	assert.False(t, gs.ApprovedGoods.Allows(1))
+
+	// 名单被删除后恢复为配置文件中的名单（不限制）
+	gs.ApprovedGoods.Update(nil)
+	_, err = gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	assert.NoError(t, err)
+}
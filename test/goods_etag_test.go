@@ -0,0 +1,98 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/model"
+	"seckill_system/web/controller"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newGoodsInfoRouter 创建商品信息接口测试路由
+func newGoodsInfoRouter(etagEnabled bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	goodController := &controller.GoodController{
+		GoodService: newGoodsInfoService(),
+		ETagEnabled: etagEnabled,
+	}
+	r.GET("/api/goods/:id", goodController.GetGoodInfo)
+	return r
+}
+
+// getGoodInfo 请求商品信息，ifNoneMatch非空时携带条件请求头
+func getGoodInfo(r *gin.Engine, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/goods/1", nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestGoodsETag_Stable 测试ETag对同一商品和更新时间保持稳定
+func TestGoodsETag_Stable(t *testing.T) {
+	good := CreateTestGoods(1)
+	good.LastUpdateTime = model.NewTimestamp(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	etag := controller.GoodsETag(good)
+	assert.Equal(t, etag, controller.GoodsETag(good))
+	assert.Regexp(t, `^"[0-9a-f]{16}"$`, etag)
+
+	// 标题等内容变化但更新时间不变时ETag不变
+	good.Title = "Another Title"
+	assert.Equal(t, etag, controller.GoodsETag(good))
+
+	// 更新时间变化后ETag变化
+	good.LastUpdateTime = model.NewTimestamp(good.LastUpdateTime.Add(time.Second))
+	assert.NotEqual(t, etag, controller.GoodsETag(good))
+
+	// 不同商品ETag不同
+	other := CreateTestGoods(2)
+	other.LastUpdateTime = model.NewTimestamp(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	assert.NotEqual(t, etag, controller.GoodsETag(other))
+}
+
+// TestGetGoodInfo_NotModified 测试If-None-Match匹配时返回无响应体的304
+func TestGetGoodInfo_NotModified(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	good := CreateTestGoods(1)
+	assert.NoError(t, db.Create(&good).Error)
+	r := newGoodsInfoRouter(true)
+
+	first := getGoodInfo(r, "")
+	assert.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	second := getGoodInfo(r, etag)
+	assert.Equal(t, http.StatusNotModified, second.Code)
+	assert.Empty(t, second.Body.String())
+
+	// 弱校验和多值列表同样匹配
+	assert.Equal(t, http.StatusNotModified, getGoodInfo(r, `"other", W/`+etag).Code)
+
+	// 不匹配时返回完整数据
+	third := getGoodInfo(r, `"outdated"`)
+	assert.Equal(t, http.StatusOK, third.Code)
+	assert.Equal(t, etag, third.Header().Get("ETag"))
+}
+
+// TestGetGoodInfo_ETagDisabled 测试关闭ETag时始终返回完整数据
+func TestGetGoodInfo_ETagDisabled(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	good := CreateTestGoods(1)
+	assert.NoError(t, db.Create(&good).Error)
+	r := newGoodsInfoRouter(false)
+
+	w := getGoodInfo(r, "*")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"net/http"
+	"seckill_system/config"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGoodsIdRange_Check 测试商品ID范围校验
+func TestGoodsIdRange_Check(t *testing.T) {
+	idRange := config.GoodsIdRange{Min: 1, Max: 1000}
+	assert.NoError(t, idRange.Check(1))
+	assert.NoError(t, idRange.Check(1000))
+	assert.Error(t, idRange.Check(0))
+	assert.Error(t, idRange.Check(-1))
+	assert.ErrorContains(t, idRange.Check(1001), "out of range [1, 1000]")
+
+	// 零值只要求为正数
+	unbounded := config.GoodsIdRange{}
+	assert.NoError(t, unbounded.Check(99999999999999999))
+	assert.Error(t, unbounded.Check(0))
+}
+
+// TestGoodsIdRange_Controller 测试超出范围的商品ID在访问Redis和数据库前返回400
+func TestGoodsIdRange_Controller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	good := CreateTestGoods(1000)
+	assert.NoError(t, db.Create(&good).Error)
+	goodController := &controller.GoodController{
+		GoodService:  newGoodsInfoService(),
+		GoodsIdRange: config.GoodsIdRange{Min: 1, Max: 1000},
+	}
+	r := router.NewRouter(goodController, noopAuth, true)
+
+	outOfRange := []string{
+		"/api/goods/99999999999999999",
+		"/api/goods/0",
+		"/api/goods/1001/ws",
+		"/api/order/exists?gid=1001",
+		"/api/seckill/precheck?gid=99999999999999999",
+	}
+	for _, path := range outOfRange {
+		assert.Equal(t, http.StatusBadRequest, serve(r, "GET", path), path)
+	}
+	assert.Equal(t, http.StatusBadRequest, serve(r, "POST", "/api/admin/preload/1001?admin=1"))
+	assert.Equal(t, http.StatusBadRequest, serve(r, "POST", "/api/admin/reset_db?admin=1&goods_id=1001"))
+	assert.Equal(t, http.StatusBadRequest, serve(r, "POST", "/api/admin/reset_db/batch?admin=1&goods_ids=1,1001"))
+
+	// 范围内的ID正常进入业务处理
+	assert.Equal(t, http.StatusOK, serve(r, "GET", "/api/goods/1000"))
+	assert.Equal(t, http.StatusOK, serve(r, "GET", "/api/order/exists?gid=1000"))
+}
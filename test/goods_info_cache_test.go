@@ -0,0 +1,93 @@
+package test
+
+import (
+	"encoding/json"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// newGoodsInfoService 创建仅依赖数据库和Redis的商品服务
+func newGoodsInfoService() *service.GoodService {
+	return &service.GoodService{
+		GoodDB:    repository.NewGoodRepository(),
+		RedisRepo: repository.NewRedisRepository(),
+	}
+}
+
+// closeTestDB 关闭数据库连接以模拟数据库不可用
+func closeTestDB(t *testing.T, db *gorm.DB) {
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, sqlDB.Close())
+}
+
+// TestGoodService_GetGoodInfo_CachesOnSuccess 测试数据库正常时返回最新数据并写入缓存
+func TestGoodService_GetGoodInfo_CachesOnSuccess(t *testing.T) {
+	db := SetupTestDB(t)
+	mr := SetupTestRedis(t)
+	good := CreateTestGoods(1)
+	assert.NoError(t, db.Create(&good).Error)
+	gs := newGoodsInfoService()
+
+	result, stale, err := gs.GetGoodInfo(1)
+	assert.NoError(t, err)
+	assert.False(t, stale)
+	assert.Equal(t, good.Title, result.Title)
+	assert.True(t, mr.Exists(repository.GoodsInfoKey(1))) // 已写入降级缓存
+}
+
+// TestGoodService_GetGoodInfo_ServesStaleCache 测试数据库不可用时返回过期缓存
+func TestGoodService_GetGoodInfo_ServesStaleCache(t *testing.T) {
+	db := SetupTestDB(t)
+	mr := SetupTestRedis(t)
+	gs := newGoodsInfoService()
+
+	// 写入一条逻辑上已过期的缓存
+	cached := model.CachedGoods{
+		Goods:    CreateTestGoods(1),
+		CachedAt: time.Now().Add(-time.Hour),
+		ExpireAt: time.Now().Add(-30 * time.Minute),
+	}
+	data, err := json.Marshal(cached)
+	assert.NoError(t, err)
+	assert.NoError(t, mr.Set(repository.GoodsInfoKey(1), string(data)))
+
+	closeTestDB(t, db)
+
+	result, stale, err := gs.GetGoodInfo(1)
+	assert.NoError(t, err)
+	assert.True(t, stale) // 标记为降级数据
+	assert.Equal(t, int64(1), result.GoodsId)
+	assert.Equal(t, "Test Book", result.Title)
+}
+
+// TestGoodService_GetGoodInfo_NoCache 测试数据库不可用且无缓存时返回错误
+func TestGoodService_GetGoodInfo_NoCache(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	gs := newGoodsInfoService()
+
+	closeTestDB(t, db)
+
+	_, stale, err := gs.GetGoodInfo(1)
+	assert.Error(t, err)
+	assert.False(t, stale)
+}
+
+// TestGoodService_GetGoodInfo_NotFoundSkipsCache 测试商品不存在时不使用缓存降级
+func TestGoodService_GetGoodInfo_NotFoundSkipsCache(t *testing.T) {
+	SetupTestDB(t)
+	SetupTestRedis(t)
+	gs := newGoodsInfoService()
+	assert.NoError(t, gs.RedisRepo.SetGoodsInfoCache(CreateTestGoods(1), time.Minute))
+
+	_, stale, err := gs.GetGoodInfo(1)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.False(t, stale)
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/config"
+	"seckill_system/model"
+	"seckill_system/web/controller"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fetchGoodInfo 请求商品信息并返回good_info字段
+func fetchGoodInfo(t *testing.T, fields []string) map[string]any {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	goodController := &controller.GoodController{
+		GoodService: newGoodsInfoService(),
+		InfoFields:  fields,
+	}
+	r.GET("/api/goods/:id", goodController.GetGoodInfo)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/goods/1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data struct {
+			GoodInfo map[string]any `json:"good_info"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data.GoodInfo
+}
+
+// TestGetGoodInfo_DefaultFields 测试默认只返回对外字段，不包含内部字段
+func TestGetGoodInfo_DefaultFields(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	good := CreateTestGoods(1)
+	good.CategoryId = 42
+	assert.NoError(t, db.Create(&good).Error)
+
+	info := fetchGoodInfo(t, nil)
+	keys := make([]string, 0, len(info))
+	for k := range info {
+		keys = append(keys, k)
+	}
+	assert.ElementsMatch(t, model.DefaultGoodsInfoFields, keys)
+	assert.NotContains(t, info, "category_id")
+	assert.NotContains(t, info, "last_update_time")
+	assert.Equal(t, float64(1), info["goods_id"])
+	assert.Equal(t, good.Title, info["title"])
+}
+
+// TestGetGoodInfo_ConfiguredFields 测试按配置的字段列表返回
+func TestGetGoodInfo_ConfiguredFields(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	good := CreateTestGoods(1)
+	good.CategoryId = 42
+	assert.NoError(t, db.Create(&good).Error)
+
+	info := fetchGoodInfo(t, []string{"goods_id", "category_id"})
+	assert.Len(t, info, 2)
+	assert.Equal(t, float64(1), info["goods_id"])
+	assert.Equal(t, float64(42), info["category_id"])
+}
+
+// TestProjectGoodsInfo_IgnoresUnknown 测试未知字段不会出现在结果中
+func TestProjectGoodsInfo_IgnoresUnknown(t *testing.T) {
+	info := model.ProjectGoodsInfo(CreateTestGoods(1), []string{"title", "PsCount"})
+	assert.Equal(t, model.GoodsInfo{"title": CreateTestGoods(1).Title}, info)
+}
+
+// TestValidate_GoodsInfoFields 测试配置未知字段时校验失败
+func TestValidate_GoodsInfoFields(t *testing.T) {
+	preserveAppConfig(t)
+
+	t.Setenv("SECKILL_SERVER_GOODS_INFO_FIELDS", "goods_id,password")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), `unknown goods info field "password"`)
+
+	t.Setenv("SECKILL_SERVER_GOODS_INFO_FIELDS", "goods_id,last_update_time")
+	require.NoError(t, loadConfigDocument(t, 8100))
+	assert.Equal(t, []string{"goods_id", "last_update_time"}, config.AppConfig.Server.GoodsInfoFields)
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// seedSearchGoods 写入用于搜索测试的商品数据
+func seedSearchGoods(t *testing.T, titles map[int64]string) {
+	db := SetupTestDB(t)
+	for goodsId, title := range titles {
+		good := CreateTestGoods(goodsId)
+		good.Title = title
+		if err := db.Create(&good).Error; err != nil {
+			t.Fatalf("failed to seed goods: %v", err)
+		}
+	}
+}
+
+// TestEscapeLikePattern 测试LIKE通配符转义
+func TestEscapeLikePattern(t *testing.T) {
+	assert.Equal(t, "Go Book", repository.EscapeLikePattern("Go Book")) // 普通字符不变
+	assert.Equal(t, "100!%", repository.EscapeLikePattern("100%"))      // 转义百分号
+	assert.Equal(t, "a!_b", repository.EscapeLikePattern("a_b"))        // 转义下划线
+	assert.Equal(t, "wow!!", repository.EscapeLikePattern("wow!"))      // 转义字符本身
+}
+
+// TestGoodRepository_SearchGoodsByTitle 测试按标题关键字搜索商品
+func TestGoodRepository_SearchGoodsByTitle(t *testing.T) {
+	seedSearchGoods(t, map[int64]string{
+		1: "Computer Book-1",
+		2: "Computer Book-2",
+		3: "History Book-3",
+	})
+	repo := repository.NewGoodRepository()
+
+	goods, err := repo.SearchGoodsByTitle("computer", 10)
+	assert.NoError(t, err)
+	assert.Len(t, goods, 2)                     // 只匹配包含关键字的商品（不区分大小写）
+	assert.Equal(t, int64(1), goods[0].GoodsId) // 结果按商品ID排序
+	assert.Equal(t, int64(2), goods[1].GoodsId)
+
+	goods, err = repo.SearchGoodsByTitle("Art", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, goods) // 无匹配结果
+}
+
+// TestGoodRepository_SearchGoodsByTitle_EscapesWildcards 测试通配符不会被当作模式匹配
+func TestGoodRepository_SearchGoodsByTitle_EscapesWildcards(t *testing.T) {
+	seedSearchGoods(t, map[int64]string{
+		1: "100% Cotton",
+		2: "1000 Tips",
+		3: "snake_case Guide",
+		4: "snakeXcase Guide",
+	})
+	repo := repository.NewGoodRepository()
+
+	// "%"只匹配字面量百分号
+	goods, err := repo.SearchGoodsByTitle("100%", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, goods, 1) {
+		assert.Equal(t, "100% Cotton", goods[0].Title)
+	}
+
+	// "_"只匹配字面量下划线，不匹配任意单个字符
+	goods, err = repo.SearchGoodsByTitle("snake_case", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, goods, 1) {
+		assert.Equal(t, "snake_case Guide", goods[0].Title)
+	}
+
+	// 单独的"%"不应匹配全部商品
+	goods, err = repo.SearchGoodsByTitle("%", 10)
+	assert.NoError(t, err)
+	assert.Len(t, goods, 1)
+}
+
+// TestGoodRepository_SearchGoodsByTitle_Limit 测试返回条数上限
+func TestGoodRepository_SearchGoodsByTitle_Limit(t *testing.T) {
+	titles := make(map[int64]string)
+	for i := int64(1); i <= repository.MaxSearchLimit+10; i++ {
+		titles[i] = "Science Book"
+	}
+	seedSearchGoods(t, titles)
+	repo := repository.NewGoodRepository()
+
+	goods, err := repo.SearchGoodsByTitle("Science", 5)
+	assert.NoError(t, err)
+	assert.Len(t, goods, 5) // 按请求数量返回
+
+	goods, err = repo.SearchGoodsByTitle("Science", 1000)
+	assert.NoError(t, err)
+	assert.Len(t, goods, repository.MaxSearchLimit) // 超过上限时截断
+
+	goods, err = repo.SearchGoodsByTitle("Science", 0)
+	assert.NoError(t, err)
+	assert.Len(t, goods, repository.DefaultSearchLimit) // 非正数使用默认值
+}
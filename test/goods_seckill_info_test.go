@@ -0,0 +1,94 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"seckill_system/config"
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goodInfoData 解析商品信息接口响应的data字段
+func goodInfoData(t *testing.T, body []byte) map[string]any {
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp.Data
+}
+
+// TestGetGoodInfo_SeckillInfo 测试商品信息附带秒杀剩余库存、价格和起止时间
+func TestGetGoodInfo_SeckillInfo(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	good := CreateTestGoods(1)
+	promotion := CreateTestPromotion(1, 100)
+	assert.NoError(t, db.Create(&good).Error)
+	assert.NoError(t, db.Create(&promotion).Error)
+	r := newGoodsInfoRouter(false)
+
+	// 库存未预加载时以活动总库存作为剩余库存
+	w := getGoodInfo(r, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	data := goodInfoData(t, w.Body.Bytes())
+	assert.Equal(t, float64(100), data["remaining_stock"])
+	assert.Equal(t, false, data["stock_preloaded"])
+	assert.Equal(t, promotion.CurrentPrice, data["seckill_price"])
+	assert.Equal(t, config.FormatTime(promotion.StartTime.Time), data["start_time"])
+	assert.Equal(t, config.FormatTime(promotion.EndTime.Time), data["end_time"])
+
+	// 预加载后返回Redis中的实时库存
+	assert.NoError(t, repository.NewRedisRepository().SetGoodsStock(1, 37))
+	data = goodInfoData(t, getGoodInfo(r, "").Body.Bytes())
+	assert.Equal(t, float64(37), data["remaining_stock"])
+	assert.Equal(t, true, data["stock_preloaded"])
+
+	// 库存售罄时剩余库存为0而不是回退到活动总库存
+	assert.NoError(t, repository.NewRedisRepository().SetGoodsStock(1, 0))
+	data = goodInfoData(t, getGoodInfo(r, "").Body.Bytes())
+	assert.Equal(t, float64(0), data["remaining_stock"])
+	assert.Equal(t, true, data["stock_preloaded"])
+}
+
+// TestGetGoodInfo_NoPromotion 测试商品没有秒杀活动时不返回秒杀字段
+func TestGetGoodInfo_NoPromotion(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	good := CreateTestGoods(1)
+	assert.NoError(t, db.Create(&good).Error)
+
+	w := getGoodInfo(newGoodsInfoRouter(false), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	data := goodInfoData(t, w.Body.Bytes())
+	assert.Contains(t, data, "good_info")
+	for _, key := range []string{"remaining_stock", "seckill_price", "start_time", "end_time", "stock_preloaded"} {
+		assert.NotContains(t, data, key)
+	}
+}
+
+// TestGetGoodInfo_ETagTracksStock 测试剩余库存变化后ETag随之变化
+func TestGetGoodInfo_ETagTracksStock(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	good := CreateTestGoods(1)
+	promotion := CreateTestPromotion(1, 100)
+	assert.NoError(t, db.Create(&good).Error)
+	assert.NoError(t, db.Create(&promotion).Error)
+	redisRepo := repository.NewRedisRepository()
+	assert.NoError(t, redisRepo.SetGoodsStock(1, 100))
+	r := newGoodsInfoRouter(true)
+
+	etag := getGoodInfo(r, "").Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, http.StatusNotModified, getGoodInfo(r, etag).Code)
+
+	_, err := redisRepo.DecrGoodsStock(1)
+	assert.NoError(t, err)
+	w := getGoodInfo(r, etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	assert.Equal(t, float64(99), goodInfoData(t, w.Body.Bytes())["remaining_stock"])
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// healthByName 按依赖名称索引检查结果
+func healthByName(report model.HealthReport) map[string]model.DependencyHealth {
+	byName := make(map[string]model.DependencyHealth, len(report.Dependencies))
+	for _, dep := range report.Dependencies {
+		byName[dep.Name] = dep
+	}
+	return byName
+}
+
+// TestHealthCheck_ReportsEachDependency 测试就绪检查逐项报告依赖状态，任一不可用时不就绪
+func TestHealthCheck_ReportsEachDependency(t *testing.T) {
+	SetupTestDB(t)
+	mr := SetupTestRedis(t)
+	SetupTestKafka(t) // Broker不可达
+	SetupTestEtcd(t)  // 模拟客户端不支持状态检查
+
+	report := global.HealthCheck(context.Background())
+	assert.False(t, report.Ready)
+	assert.Len(t, report.Dependencies, 4)
+	deps := healthByName(report)
+	assert.True(t, deps[model.DependencyMySQL].Healthy)
+	assert.True(t, deps[model.DependencyRedis].Healthy)
+	assert.False(t, deps[model.DependencyKafka].Healthy)
+	assert.NotEmpty(t, deps[model.DependencyKafka].Error)
+	assert.False(t, deps[model.DependencyEtcd].Healthy)
+
+	// Redis宕机后报告为不可用
+	mr.Close()
+	deps = healthByName(global.HealthCheck(context.Background()))
+	assert.False(t, deps[model.DependencyRedis].Healthy)
+	assert.True(t, deps[model.DependencyMySQL].Healthy)
+}
+
+// TestHealthEndpoints 测试存活探针始终返回200，就绪探针在依赖不可用时返回503及各依赖状态
+func TestHealthEndpoints(t *testing.T) {
+	SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	SetupTestEtcd(t)
+	r := router.NewRouter(&controller.GoodController{}, noopAuth, false)
+
+	assert.Equal(t, http.StatusOK, serve(r, "GET", "/health"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var body struct {
+		Data model.HealthReport `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.False(t, body.Data.Ready)
+	assert.Len(t, body.Data.Dependencies, 4)
+	assert.True(t, healthByName(body.Data)[model.DependencyRedis].Healthy)
+}
@@ -0,0 +1,262 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/config"
+	"seckill_system/global"
+	"seckill_system/handler"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"seckill_system/web/middleware"
+	"seckill_system/web/router"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	metadataAPI "github.com/segmentio/kafka-go/protocol/metadata"
+	produceAPI "github.com/segmentio/kafka-go/protocol/produce"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureTransport 内存Kafka传输层，元数据请求返回单分区主题，生产请求记录消息
+type captureTransport struct {
+	mu       sync.Mutex
+	messages []kafka.Message // 已写入的消息
+}
+
+// RoundTrip 处理Kafka写入者发出的元数据和生产请求
+func (c *captureTransport) RoundTrip(ctx context.Context, addr net.Addr, req kafka.Request) (kafka.Response, error) {
+	switch r := req.(type) {
+	case *metadataAPI.Request:
+		res := &metadataAPI.Response{
+			Brokers: []metadataAPI.ResponseBroker{{NodeID: 0, Host: "127.0.0.1", Port: 9092}},
+		}
+		for _, topic := range r.TopicNames {
+			res.Topics = append(res.Topics, metadataAPI.ResponseTopic{
+				Name:       topic,
+				Partitions: []metadataAPI.ResponsePartition{{PartitionIndex: 0, LeaderID: 0}},
+			})
+		}
+		return res, nil
+	case *produceAPI.Request:
+		res := &produceAPI.Response{}
+		for _, topic := range r.Topics {
+			for _, partition := range topic.Partitions {
+				if err := c.record(topic.Topic, partition.RecordSet.Records); err != nil {
+					return nil, err
+				}
+			}
+			res.Topics = append(res.Topics, produceAPI.ResponseTopic{
+				Topic:      topic.Topic,
+				Partitions: []produceAPI.ResponsePartition{{Partition: 0}},
+			})
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("unexpected kafka request %T", req)
+	}
+}
+
+// record 读取生产请求中的全部消息
+func (c *captureTransport) record(topic string, records protocol.RecordReader) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		rec, err := records.ReadRecord()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		value, err := protocol.ReadAll(rec.Value)
+		if err != nil {
+			return err
+		}
+		msg := kafka.Message{Topic: topic, Value: value}
+		for _, h := range rec.Headers {
+			msg.Headers = append(msg.Headers, kafka.Header{Key: h.Key, Value: h.Value})
+		}
+		c.messages = append(c.messages, msg)
+	}
+}
+
+// byType 返回message_type请求头为指定值的消息
+func (c *captureTransport) byType(messageType string) []kafka.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var matched []kafka.Message
+	for _, msg := range c.messages {
+		for _, h := range msg.Headers {
+			if h.Key == "message_type" && string(h.Value) == messageType {
+				matched = append(matched, msg)
+			}
+		}
+	}
+	return matched
+}
+
+// setupCaptureKafka 使用内存传输层替换全局Kafka写入者，测试结束后恢复
+func setupCaptureKafka(t *testing.T) *captureTransport {
+	t.Helper()
+	SetupTestKafka(t) // 消费者指向不可达地址，本测试不消费消息
+	transport := &captureTransport{}
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP("127.0.0.1:9092"),
+		Topic:        "seckill_test_orders",
+		Transport:    transport,
+		BatchTimeout: time.Millisecond,
+	}
+	previous := global.KafkaWriter
+	global.KafkaWriter = writer
+	t.Cleanup(func() {
+		global.KafkaWriter = previous
+		writer.Close()
+	})
+	return transport
+}
+
+// apiResponse 公共接口统一的JSON响应结构
+type apiResponse struct {
+	Code    int             `json:"code"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+	Message string          `json:"message"`
+}
+
+// callAPI 发送请求并解析统一响应结构，token非空时携带Authorization请求头
+func callAPI(t *testing.T, r http.Handler, method, path, token string, wantStatus int) apiResponse {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, wantStatus, w.Code, "%s %s: %s", method, path, w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("X-Request-Id"), "%s %s", method, path)
+
+	var resp apiResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), "%s %s", method, path)
+	assert.NotEmpty(t, resp.Message, "%s %s", method, path)
+	return resp
+}
+
+// TestHTTPFlow_SeckillToPayment 通过公共路由完成生成用户令牌、获取秒杀令牌、下单、支付和查询订单的完整流程
+func TestHTTPFlow_SeckillToPayment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	kv := SetupTestEtcd(t)
+	kafkaMessages := setupCaptureKafka(t)
+
+	good := CreateTestGoods(1)
+	promotion := CreateTestPromotion(1, 10)
+	require.NoError(t, db.Create(&good).Error)
+	require.NoError(t, db.Create(&promotion).Error)
+	kv.Data[global.EtcdKeySeckillEnabled] = "true"
+	kv.Data[global.EtcdKeyRateLimit] = "3"
+
+	gs := &service.GoodService{
+		GoodDB:         repository.NewGoodRepository(),
+		RedisRepo:      repository.NewRedisRepository(),
+		KafkaRepo:      repository.NewKafkaRepository(),
+		EtcdRepo:       repository.NewETCDRepository(),
+		SeckillHandler: handler.NewSeckillHandler(),
+	}
+	locks, err := service.NewLockFactory(config.LockConfig{Seckill: config.LockBackendRedis}, gs.EtcdRepo, gs.RedisRepo)
+	require.NoError(t, err)
+	gs.Locks = locks
+	require.NoError(t, gs.RedisRepo.SetGoodsStock(1, 10))
+
+	// 与InitRouter相同的路由和中间件组装，服务使用测试后端
+	r := router.NewRouter(&controller.GoodController{GoodService: gs}, middleware.NewAuthMiddleware(gs), false,
+		middleware.InflightLimitMiddleware())
+
+	// 1. 生成用户令牌
+	resp := callAPI(t, r, "GET", "/api/auth/create_user_token?user_id=100", "", http.StatusOK)
+	assert.Equal(t, 0, resp.Code)
+	var userToken struct {
+		UserId int64  `json:"user_id"`
+		Token  string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &userToken))
+	assert.Equal(t, int64(100), userToken.UserId)
+	require.NotEmpty(t, userToken.Token)
+
+	// 未携带用户令牌的秒杀请求被认证中间件拒绝
+	resp = callAPI(t, r, "POST", "/api/seckill/token?gid=1", "", http.StatusUnauthorized)
+	assert.Equal(t, -1, resp.Code)
+
+	// 2. 获取秒杀令牌
+	resp = callAPI(t, r, "POST", "/api/seckill/token?gid=1", userToken.Token, http.StatusOK)
+	assert.Equal(t, 0, resp.Code)
+	var seckillToken struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &seckillToken))
+	require.NotEmpty(t, seckillToken.Token)
+
+	// 3. 使用秒杀令牌下单
+	resp = callAPI(t, r, "POST", "/api/seckill?gid=1&token="+seckillToken.Token, userToken.Token, http.StatusOK)
+	assert.Equal(t, 0, resp.Code)
+	var order struct {
+		OrderId string `json:"order_id"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &order))
+	require.NotEmpty(t, order.OrderId)
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), stock)
+
+	// 下单后订单为未支付状态
+	resp = callAPI(t, r, "GET", "/api/order/"+order.OrderId, userToken.Token, http.StatusOK)
+	var status model.OrderStatus
+	require.NoError(t, json.Unmarshal(resp.Data, &status))
+	assert.Equal(t, order.OrderId, status.OrderId)
+	assert.Equal(t, int64(100), status.UserId)
+	assert.Equal(t, int64(1), status.GoodsId)
+	assert.Equal(t, "unpaid", status.Status)
+
+	// 4. 模拟支付，支付结果消息写入Kafka
+	resp = callAPI(t, r, "POST", "/api/payment/simulate?order_id="+order.OrderId+"&success=true", userToken.Token, http.StatusOK)
+	assert.Equal(t, 0, resp.Code)
+	payments := kafkaMessages.byType("payment")
+	require.Len(t, payments, 1)
+	var payment struct {
+		OrderId string `json:"order_id"`
+		Status  int32  `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(payments[0].Value, &payment))
+	assert.Equal(t, order.OrderId, payment.OrderId)
+	assert.Equal(t, int32(model.OrderStatusPaid), payment.Status)
+
+	// 由支付消费者处理支付结果消息
+	require.NoError(t, gs.HandlePaymentResult(payment.OrderId, payment.Status))
+
+	// 5. 查询订单，状态为已支付
+	resp = callAPI(t, r, "GET", "/api/order/"+order.OrderId, userToken.Token, http.StatusOK)
+	assert.Equal(t, 0, resp.Code)
+	require.NoError(t, json.Unmarshal(resp.Data, &status))
+	assert.Equal(t, "paid", status.Status)
+
+	// 其他用户无法查询该订单
+	other := callAPI(t, r, "GET", "/api/auth/create_user_token?user_id=101", "", http.StatusOK)
+	require.NoError(t, json.Unmarshal(other.Data, &userToken))
+	resp = callAPI(t, r, "GET", "/api/order/"+order.OrderId, userToken.Token, http.StatusForbidden)
+	assert.Equal(t, -1, resp.Code)
+}
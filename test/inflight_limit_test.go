@@ -0,0 +1,80 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/web/middleware"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInflightLimit_ShedsExcess 测试并发达到上限时多余请求立即返回503，上限内的请求正常完成
+func TestInflightLimit_ShedsExcess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const maxInflight = 3
+	entered := make(chan struct{}, maxInflight)
+	release := make(chan struct{})
+
+	r := gin.New()
+	r.Use(middleware.NewInflightLimitMiddleware(maxInflight, []string{"/health"}))
+	r.GET("/api/slow", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	r.GET("/health", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// 占满全部并发槽位
+	var wg sync.WaitGroup
+	codes := make([]int, maxInflight)
+	for i := 0; i < maxInflight; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = serve(r, "GET", "/api/slow")
+		}(i)
+	}
+	for i := 0; i < maxInflight; i++ {
+		select {
+		case <-entered:
+		case <-time.After(2 * time.Second):
+			t.Fatal("requests within the cap did not start")
+		}
+	}
+
+	// 超出上限的请求不排队，立即返回503
+	for i := 0; i < 5; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/api/slow", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	}
+	// 豁免路径不受上限约束
+	assert.Equal(t, http.StatusOK, serve(r, "GET", "/health"))
+
+	close(release)
+	wg.Wait()
+	for _, code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+
+	// 槽位释放后恢复处理（entered已被读空，release已关闭，处理函数不会阻塞）
+	assert.Equal(t, http.StatusOK, serve(r, "GET", "/api/slow"))
+}
+
+// TestInflightLimit_Disabled 测试上限为0时不限制并发
+func TestInflightLimit_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.NewInflightLimitMiddleware(0, nil))
+	r.GET("/api/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	assert.Equal(t, http.StatusOK, serve(r, "GET", "/api/ping"))
+}
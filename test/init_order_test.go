@@ -0,0 +1,53 @@
+package test
+
+import (
+	"seckill_system/global"
+	"seckill_system/repository"
+	"seckill_system/web/controller"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recoverError 执行fn并返回其panic的错误，未panic时返回nil
+func recoverError(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, _ = r.(error)
+		}
+	}()
+	fn()
+	return nil
+}
+
+// TestInitOrder_ControllerBeforeInit 测试在初始化全局客户端前构造控制器时给出明确的失败信息
+func TestInitOrder_ControllerBeforeInit(t *testing.T) {
+	assert.ErrorIs(t, global.CheckInitialized(), global.ErrNotInitialized)
+
+	err := recoverError(func() { controller.NewGoodController() })
+	assert.ErrorIs(t, err, global.ErrNotInitialized)
+	for _, initFunc := range []string{"global.InitMySQL", "global.InitRedis", "global.InitKafka", "global.InitEtcd"} {
+		assert.ErrorContains(t, err, initFunc)
+	}
+}
+
+// TestInitOrder_RepositoryBeforeClient 测试仓库在对应客户端初始化前构造时panic并指明初始化函数
+func TestInitOrder_RepositoryBeforeClient(t *testing.T) {
+	SetupTestDB(t)
+	assert.NotPanics(t, func() { repository.NewGoodRepository() })
+
+	err := recoverError(func() { repository.NewRedisRepository() })
+	assert.ErrorIs(t, err, global.ErrNotInitialized)
+	assert.ErrorContains(t, err, "Redis client is nil, call global.InitRedis")
+
+	err = recoverError(func() { repository.NewKafkaRepository() })
+	assert.ErrorContains(t, err, "call global.InitKafka")
+	err = recoverError(func() { repository.NewETCDRepository() })
+	assert.ErrorContains(t, err, "call global.InitEtcd")
+
+	// 全部客户端就绪后检查通过
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	SetupTestEtcd(t)
+	assert.NoError(t, global.CheckInitialized())
+}
@@ -55,10 +55,10 @@ type KafkaRepository interface {
 type ETCDRepository interface {
 	// GetSeckillEnabled 获取秒杀开关状态
 	GetSeckillEnabled(ctx context.Context) (bool, error)
-	// GetDistributedLock 获取分布式锁
-	GetDistributedLock(ctx context.Context, key string, ttl int) (bool, error)
-	// ReleaseDistributedLock 释放分布式锁
-	ReleaseDistributedLock(ctx context.Context, key string) error
+	// GetDistributedLock 获取分布式锁，返回持有者令牌
+	GetDistributedLock(ctx context.Context, key string, ttl int) (string, bool, error)
+	// ReleaseDistributedLock 使用持有者令牌释放分布式锁
+	ReleaseDistributedLock(ctx context.Context, key, token string) error
 	// IsInBlacklist 检查用户是否在黑名单中
 	IsInBlacklist(ctx context.Context, userId int64) (bool, error)
 	// GetRateLimitConfig 获取限流配置
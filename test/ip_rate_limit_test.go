@@ -0,0 +1,122 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIPRateLimit_Redis 测试IP限流按IP独立计数并在窗口结束后重置
+func TestIPRateLimit_Redis(t *testing.T) {
+	mr := SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+
+	for i := 0; i < 2; i++ {
+		allowed, err := redisRepo.IPRateLimit("203.0.113.7", 2, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err := redisRepo.IPRateLimit("203.0.113.7", 2, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, mr.TTL(repository.IPRateLimitKey("203.0.113.7")))
+
+	// 其他IP和用户限流计数不受影响
+	allowed, err = redisRepo.IPRateLimit("2001:db8::1", 2, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.False(t, mr.Exists(repository.UserRateLimitKey(1)))
+
+	mr.FastForward(time.Minute)
+	allowed, err = redisRepo.IPRateLimit("203.0.113.7", 2, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// TestGetIPRateLimitConfig 测试IP限流配置的默认值、解析和下限
+func TestGetIPRateLimitConfig(t *testing.T) {
+	kv := SetupTestEtcd(t)
+	etcdRepo := repository.NewETCDRepository()
+	ctx := context.Background()
+
+	limit, err := etcdRepo.GetIPRateLimitConfig(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(repository.DefaultIPRateLimit), limit)
+
+	cases := map[string]int64{"5": 5, "abc": repository.DefaultIPRateLimit, "0": repository.MinRateLimit}
+	for value, expected := range cases {
+		kv.Data[global.EtcdKeyIPRateLimit] = value
+		limit, err := etcdRepo.GetIPRateLimitConfig(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, limit, value)
+	}
+}
+
+// setupIPRateLimitRouter 创建IP限流为2次/分钟的公共路由
+func setupIPRateLimitRouter(t *testing.T) (*gin.Engine, *service.GoodService, *miniredis.Miniredis) {
+	gin.SetMode(gin.TestMode)
+	mr := SetupTestRedis(t)
+	kv := SetupTestEtcd(t)
+	kv.Data[global.EtcdKeyIPRateLimit] = "2"
+	gs := &service.GoodService{
+		RedisRepo: repository.NewRedisRepository(),
+		EtcdRepo:  repository.NewETCDRepository(),
+	}
+	return router.NewRouter(&controller.GoodController{GoodService: gs}, noopAuth, false), gs, mr
+}
+
+// seckillFrom 从指定IP发起秒杀请求，返回HTTP状态码
+func seckillFrom(r *gin.Engine, path, ip string) int {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", path, nil)
+	req.RemoteAddr = ip + ":40000"
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+// TestIPRateLimitMiddleware_SeckillRoutes 测试同一IP在令牌和下单接口上共享限流计数，超出后返回429
+func TestIPRateLimitMiddleware_SeckillRoutes(t *testing.T) {
+	r, _, _ := setupIPRateLimitRouter(t)
+
+	assert.NotEqual(t, http.StatusTooManyRequests, seckillFrom(r, "/api/seckill/token", "203.0.113.7"))
+	assert.NotEqual(t, http.StatusTooManyRequests, seckillFrom(r, "/api/seckill", "203.0.113.7"))
+	assert.Equal(t, http.StatusTooManyRequests, seckillFrom(r, "/api/seckill/token", "203.0.113.7"))
+	assert.Equal(t, http.StatusTooManyRequests, seckillFrom(r, "/api/seckill", "203.0.113.7"))
+
+	// 其他IP不受影响
+	assert.NotEqual(t, http.StatusTooManyRequests, seckillFrom(r, "/api/seckill/token", "203.0.113.8"))
+}
+
+// TestIPRateLimitMiddleware_AllowlistedIP 测试豁免名单中的IP不受IP限流约束
+func TestIPRateLimitMiddleware_AllowlistedIP(t *testing.T) {
+	r, gs, mr := setupIPRateLimitRouter(t)
+	gs.Allowlist = service.NewRateLimitAllowlist(model.RateLimitAllowlist{IPs: []string{"10.0.0.0/8"}})
+
+	for i := 0; i < 5; i++ {
+		assert.NotEqual(t, http.StatusTooManyRequests, seckillFrom(r, "/api/seckill/token", "10.1.2.3"))
+	}
+	assert.False(t, mr.Exists(repository.IPRateLimitKey("10.1.2.3")))
+}
+
+// TestIPRateLimitMiddleware_BackendFailure 测试Redis故障时按RateLimitOpen放行或返回503
+func TestIPRateLimitMiddleware_BackendFailure(t *testing.T) {
+	r, gs, mr := setupIPRateLimitRouter(t)
+	mr.SetError("LOADING Redis is loading the dataset in memory")
+
+	assert.Equal(t, http.StatusServiceUnavailable, seckillFrom(r, "/api/seckill/token", "203.0.113.7"))
+
+	gs.RateLimitOpen = true
+	assert.NotEqual(t, http.StatusServiceUnavailable, seckillFrom(r, "/api/seckill/token", "203.0.113.7"))
+}
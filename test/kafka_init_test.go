@@ -0,0 +1,53 @@
+package test
+
+import (
+	"net"
+	"seckill_system/global"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// unreachableAddr 返回一个当前没有监听的本地地址
+func unreachableAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+// TestCheckKafkaBrokers_AllUnreachable 测试所有broker不可达时重试后返回错误
+func TestCheckKafkaBrokers_AllUnreachable(t *testing.T) {
+	brokers := []string{unreachableAddr(t), unreachableAddr(t)}
+
+	start := time.Now()
+	err := global.CheckKafkaBrokers(brokers, 2, 10*time.Millisecond, 200*time.Millisecond)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "all kafka brokers unreachable after 2 retries")
+	assert.Contains(t, err.Error(), brokers[0])
+	assert.Contains(t, err.Error(), brokers[1])
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond) // 10ms + 20ms退避
+}
+
+// TestCheckKafkaBrokers_NotKafka 测试可连接但不是Kafka的地址视为不可用
+func TestCheckKafkaBrokers_NotKafka(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close() // 立即断开，不响应Kafka协议
+		}
+	}()
+
+	err = global.CheckKafkaBrokers([]string{listener.Addr().String()}, 0, time.Millisecond, 200*time.Millisecond)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), listener.Addr().String())
+}
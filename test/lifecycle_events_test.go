@@ -0,0 +1,182 @@
+package test
+
+import (
+	"context"
+	"log/slog"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/handler"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lifecycleEventCounts 统计已记录的各类活动生命周期事件数量
+func (h *logRecorder) lifecycleEventCounts(goodsId int64) map[string]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	counts := make(map[string]int)
+	for _, record := range h.records {
+		if record.Message != "Activity lifecycle event" {
+			continue
+		}
+		var event string
+		var id int64
+		record.Attrs(func(attr slog.Attr) bool {
+			switch attr.Key {
+			case "event":
+				event = attr.Value.String()
+			case "goods_id":
+				id = attr.Value.Int64()
+			}
+			return true
+		})
+		if id == goodsId {
+			counts[event]++
+		}
+	}
+	return counts
+}
+
+// TestLifecycle_SoldOutFiresOnce 测试并发抢购时最后一件售出只触发一次售罄事件，首单事件也只触发一次
+func TestLifecycle_SoldOutFiresOnce(t *testing.T) {
+	SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	h := handler.NewSeckillHandler()
+	redisRepo := repository.NewRedisRepository()
+	assert.NoError(t, redisRepo.SetGoodsStock(1, 5))
+	recorder := captureLogs(t)
+
+	const buyers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func(userId int64) {
+			defer wg.Done()
+			_, _ = h.CreateOrderRedisOnly(context.Background(), userId, 1, 1)
+		}(int64(100 + i))
+	}
+	wg.Wait()
+
+	stock, err := redisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), stock)
+	counts := recorder.lifecycleEventCounts(1)
+	assert.Equal(t, 1, counts[model.LifecycleSoldOut])
+	assert.Equal(t, 1, counts[model.LifecycleFirstSale])
+}
+
+// TestLifecycle_SoldOutNotFiredBeforeLastUnit 测试库存未扣减到0时不触发售罄事件
+func TestLifecycle_SoldOutNotFiredBeforeLastUnit(t *testing.T) {
+	SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	h := handler.NewSeckillHandler()
+	assert.NoError(t, repository.NewRedisRepository().SetGoodsStock(1, 3))
+	recorder := captureLogs(t)
+
+	_, err := h.CreateOrderRedisOnly(context.Background(), 100, 1, 2)
+	assert.NoError(t, err)
+	counts := recorder.lifecycleEventCounts(1)
+	assert.Equal(t, 1, counts[model.LifecycleFirstSale])
+	assert.Zero(t, counts[model.LifecycleSoldOut])
+
+	_, err = h.CreateOrderRedisOnly(context.Background(), 101, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, recorder.lifecycleEventCounts(1)[model.LifecycleSoldOut])
+}
+
+// TestLifecycle_PreloadStartsNewRound 测试预加载触发预加载事件并重置去重标记
+func TestLifecycle_PreloadStartsNewRound(t *testing.T) {
+	gs := SetupTestService(t, WithGoods(1, 1), WithoutRedisStock(), WithRedisMode()).Service
+	recorder := captureLogs(t)
+
+	updated, err := gs.PreloadGoodsStock(1, true)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	_, err = gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	assert.NoError(t, err)
+
+	// 强制重新预加载后同一商品的事件可以再次触发
+	_, err = gs.PreloadGoodsStock(1, true)
+	assert.NoError(t, err)
+	_, err = gs.SeckillWithToken(101, 1, mustSeckillToken(t, gs, 101, 1))
+	assert.NoError(t, err)
+
+	counts := recorder.lifecycleEventCounts(1)
+	assert.Equal(t, 2, counts[model.LifecyclePreloaded])
+	assert.Equal(t, 2, counts[model.LifecycleFirstSale])
+	assert.Equal(t, 2, counts[model.LifecycleSoldOut])
+}
+
+// TestLifecycle_EndedFiresOnce 测试到达结束时间的活动只触发一次结束事件
+func TestLifecycle_EndedFiresOnce(t *testing.T) {
+	gs := SetupTestService(t, WithGoods(0), WithRedisMode()).Service
+	now := time.Now()
+	ended := CreateTestPromotion(1, 10)
+	ended.EndTime = model.NewTimestamp(now.Add(-time.Minute))
+	active := CreateTestPromotion(2, 10)
+	assert.NoError(t, global.DBClient.Create(&[]model.PromotionSecKill{ended, active}).Error)
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(1, 3))
+	recorder := captureLogs(t)
+
+	emitted, err := gs.EmitEndedActivities(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, emitted)
+	emitted, err = gs.EmitEndedActivities(now.Add(10 * time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, emitted)
+
+	assert.Equal(t, 1, recorder.lifecycleEventCounts(1)[model.LifecycleEnded])
+	assert.Zero(t, recorder.lifecycleEventCounts(2)[model.LifecycleEnded])
+}
+
+// TestLifecycle_FirstSaleCheckedOncePerInstance 测试本实例确认首单事件已触发后，后续订单不再检查Redis去重标记
+func TestLifecycle_FirstSaleCheckedOncePerInstance(t *testing.T) {
+	f := SetupTestService(t, WithGoods(3, 1), WithSeckillHandler())
+	h := f.Service.SeckillHandler
+	recorder := captureLogs(t)
+
+	_, err := h.CreateOrderRedisOnly(context.Background(), 100, 1, 1)
+	assert.NoError(t, err)
+	assert.True(t, f.Redis.Exists(repository.LifecycleEventKey(1, model.LifecycleFirstSale)))
+
+	// 删除去重标记后再次下单，本实例已确认首单事件触发，不会访问Redis重新写入标记
+	f.Redis.Del(repository.LifecycleEventKey(1, model.LifecycleFirstSale))
+	_, err = h.CreateOrderRedisOnly(context.Background(), 101, 1, 1)
+	assert.NoError(t, err)
+	assert.False(t, f.Redis.Exists(repository.LifecycleEventKey(1, model.LifecycleFirstSale)))
+	assert.Equal(t, 1, recorder.lifecycleEventCounts(1)[model.LifecycleFirstSale])
+}
+
+// TestLifecycle_ReservedTokensFireEvents 测试开启令牌库存预占时签发令牌预占库存触发首单和售罄事件，兑换预占令牌不重复触发
+func TestLifecycle_ReservedTokensFireEvents(t *testing.T) {
+	gs, _ := setupReserveStockService(t, 2)
+	recorder := captureLogs(t)
+
+	first, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	require.NoError(t, err)
+	counts := recorder.lifecycleEventCounts(1)
+	assert.Equal(t, 1, counts[model.LifecycleFirstSale])
+	assert.Zero(t, counts[model.LifecycleSoldOut])
+
+	second, err := gs.GenerateSeckillToken(101, 1, "203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, 1, recorder.lifecycleEventCounts(1)[model.LifecycleSoldOut])
+	_, err = gs.GenerateSeckillToken(102, 1, "203.0.113.7")
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+
+	_, err = gs.SeckillWithToken(100, 1, first)
+	require.NoError(t, err)
+	_, err = gs.SeckillWithToken(101, 1, second)
+	require.NoError(t, err)
+	counts = recorder.lifecycleEventCounts(1)
+	assert.Equal(t, 1, counts[model.LifecycleFirstSale])
+	assert.Equal(t, 1, counts[model.LifecycleSoldOut])
+}
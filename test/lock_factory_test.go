@@ -0,0 +1,184 @@
+package test
+
+import (
+	"context"
+	"seckill_system/config"
+	"seckill_system/handler"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingLocker 记录加锁调用的模拟锁
+type recordingLocker struct {
+	acquired []string // 已加锁的键
+}
+
+// GetDistributedLock 记录加锁键并返回成功
+func (l *recordingLocker) GetDistributedLock(ctx context.Context, key string, ttl int) (string, bool, error) {
+	l.acquired = append(l.acquired, key)
+	return "token", true, nil
+}
+
+// ReleaseDistributedLock 释放锁
+func (l *recordingLocker) ReleaseDistributedLock(ctx context.Context, key, token string) error {
+	return nil
+}
+
+// TestLockFactory_CategoryBackends 测试各分类返回配置的锁后端
+func TestLockFactory_CategoryBackends(t *testing.T) {
+	etcd := &recordingLocker{}
+	redis := &recordingLocker{}
+	factory, err := service.NewLockFactory(config.LockConfig{
+		Seckill:     config.LockBackendRedis,
+		Preload:     config.LockBackendEtcd,
+		Maintenance: config.LockBackendRedis,
+	}, etcd, redis)
+	assert.NoError(t, err)
+
+	assert.Same(t, redis, factory.Locker(config.LockCategorySeckill))
+	assert.Same(t, etcd, factory.Locker(config.LockCategoryPreload))
+	assert.Same(t, redis, factory.Locker(config.LockCategoryMaintenance))
+	assert.Equal(t, config.LockBackendRedis, factory.Backend(config.LockCategorySeckill))
+}
+
+// TestLockFactory_DefaultsToEtcd 测试未配置或未知分类时使用Etcd锁
+func TestLockFactory_DefaultsToEtcd(t *testing.T) {
+	etcd := &recordingLocker{}
+	redis := &recordingLocker{}
+	factory, err := service.NewLockFactory(config.LockConfig{}, etcd, redis)
+	assert.NoError(t, err)
+
+	for _, category := range []string{config.LockCategorySeckill, config.LockCategoryPreload, config.LockCategoryMaintenance, "unknown"} {
+		assert.Same(t, etcd, factory.Locker(category), category)
+	}
+}
+
+// TestLockFactory_InvalidBackend 测试无效的锁后端配置被拒绝
+func TestLockFactory_InvalidBackend(t *testing.T) {
+	_, err := service.NewLockFactory(config.LockConfig{Preload: "zookeeper"}, &recordingLocker{}, &recordingLocker{})
+	assert.ErrorContains(t, err, "preload")
+
+	_, err = service.NewLockFactory(config.LockConfig{}, &recordingLocker{}, nil)
+	assert.Error(t, err)
+}
+
+// TestRedisDistributedLock 测试Redis锁的互斥、释放和过期
+func TestRedisDistributedLock(t *testing.T) {
+	mr := SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+	ctx := context.Background()
+
+	token, locked, err := redisRepo.GetDistributedLock(ctx, "seckill_user_1", 10)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+	assert.Equal(t, 10*time.Second, mr.TTL(repository.DistributedLockKey("seckill_user_1")))
+
+	_, locked, err = redisRepo.GetDistributedLock(ctx, "seckill_user_1", 10)
+	assert.NoError(t, err)
+	assert.False(t, locked, "lock should be exclusive")
+
+	assert.NoError(t, redisRepo.ReleaseDistributedLock(ctx, "seckill_user_1", token))
+	staleToken, locked, err := redisRepo.GetDistributedLock(ctx, "seckill_user_1", 10)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	// 锁过期后可被重新获取，原持有者释放时不会删除新持有者的锁
+	mr.FastForward(11 * time.Second)
+	token, locked, err = redisRepo.GetDistributedLock(ctx, "seckill_user_1", 10)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+	assert.ErrorIs(t, redisRepo.ReleaseDistributedLock(ctx, "seckill_user_1", staleToken), repository.ErrLockNotHeld)
+	assert.True(t, mr.Exists(repository.DistributedLockKey("seckill_user_1")))
+	assert.NoError(t, redisRepo.ReleaseDistributedLock(ctx, "seckill_user_1", token))
+	assert.False(t, mr.Exists(repository.DistributedLockKey("seckill_user_1")))
+}
+
+// TestPreloadGoodsStock_UsesConfiguredLocker 测试预加载使用配置的锁后端
+func TestPreloadGoodsStock_UsesConfiguredLocker(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	promotion := CreateTestPromotion(1, 50)
+	assert.NoError(t, db.Create(&promotion).Error)
+
+	etcd := &recordingLocker{}
+	redis := &recordingLocker{}
+	factory, err := service.NewLockFactory(config.LockConfig{Preload: config.LockBackendRedis}, etcd, redis)
+	assert.NoError(t, err)
+
+	gs := &service.GoodService{
+		GoodDB:    repository.NewGoodRepository(),
+		RedisRepo: repository.NewRedisRepository(),
+		Locks:     factory,
+	}
+	updated, err := gs.PreloadGoodsStock(1, false)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	assert.Equal(t, []string{"/seckill/locks/preload_lock_1"}, redis.acquired)
+	assert.Empty(t, etcd.acquired)
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), stock)
+}
+
+// TestPreloadGoodsStock_RedisLockHeld 测试Redis预加载锁被占用时拒绝并发预加载
+func TestPreloadGoodsStock_RedisLockHeld(t *testing.T) {
+	SetupTestDB(t)
+	SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+	factory, err := service.NewLockFactory(config.LockConfig{Preload: config.LockBackendRedis}, &recordingLocker{}, redisRepo)
+	assert.NoError(t, err)
+
+	_, locked, err := redisRepo.GetDistributedLock(context.Background(), factory.Key("preload_lock_1"), 30)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	gs := &service.GoodService{
+		GoodDB:    repository.NewGoodRepository(),
+		RedisRepo: redisRepo,
+		Locks:     factory,
+	}
+	_, err = gs.PreloadGoodsStock(1, true)
+	assert.ErrorContains(t, err, "failed to acquire preload lock")
+}
+
+// TestLockConfig_Namespace 测试锁键带有默认或配置的命名空间前缀
+func TestLockConfig_Namespace(t *testing.T) {
+	assert.Equal(t, "/seckill/locks/preload_lock_1", config.LockConfig{}.Key("preload_lock_1"))
+	assert.Equal(t, "/team/locks/seckill_user_7", config.LockConfig{Namespace: "/team/locks"}.Key("seckill_user_7"))
+	assert.Equal(t, "/team/locks/seckill_user_7", config.LockConfig{Namespace: "/team/locks/"}.Key("seckill_user_7"))
+
+	assert.Error(t, config.LockConfig{Namespace: "locks/"}.Validate())
+	_, err := service.NewLockFactory(config.LockConfig{Namespace: "locks/"}, &recordingLocker{}, &recordingLocker{})
+	assert.Error(t, err)
+}
+
+// TestSeckillLocks_UseNamespace 测试秒杀下单使用的锁键带有配置的命名空间前缀
+func TestSeckillLocks_UseNamespace(t *testing.T) {
+	SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	seckillLocks := &recordingLocker{}
+	factory, err := service.NewLockFactory(config.LockConfig{Seckill: config.LockBackendRedis, Namespace: "/ns/"}, &recordingLocker{}, seckillLocks)
+	assert.NoError(t, err)
+	gs := &service.GoodService{
+		RedisRepo:      repository.NewRedisRepository(),
+		SeckillHandler: handler.NewSeckillHandler(),
+		Locks:          factory,
+		Seckill:        config.SeckillConfig{Mode: config.SeckillModeRedis},
+	}
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(1, 10))
+
+	_, err = gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	assert.NoError(t, err)
+
+	assert.NotEmpty(t, seckillLocks.acquired)
+	for _, key := range seckillLocks.acquired {
+		assert.True(t, strings.HasPrefix(key, "/ns/"), key)
+	}
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/config"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupLockList 使用模拟KV和Lease创建Etcd仓库
+func setupLockList(t *testing.T) (*repository.ETCDRepository, *MockEtcdKV, *MockEtcdLease) {
+	kv := SetupTestEtcd(t)
+	lease := NewMockEtcdLease()
+	global.EtcdClient.Lease = lease
+	return repository.NewETCDRepository(), kv, lease
+}
+
+// TestListLocks_AcquiredAndReleased 测试已获取的锁出现在列表中，释放后不再出现
+func TestListLocks_AcquiredAndReleased(t *testing.T) {
+	etcdRepo, kv, _ := setupLockList(t)
+	ctx := context.Background()
+	kv.Data["/seckill/config/enabled"] = "true" // 命名空间外的键不计入
+
+	tokens := make(map[string]string)
+	for _, key := range []string{"/seckill/locks/seckill_user_1", "/seckill/locks/preload_lock_2"} {
+		token, locked, err := etcdRepo.GetDistributedLock(ctx, key, 30)
+		assert.NoError(t, err)
+		assert.True(t, locked)
+		tokens[key] = token
+	}
+	_, locked, err := etcdRepo.GetDistributedLock(ctx, "/seckill/locks/seckill_user_1", 30)
+	assert.NoError(t, err)
+	assert.False(t, locked, "held lock should not be acquired twice")
+
+	keys, err := etcdRepo.ListLocks(ctx, "/seckill/locks/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/seckill/locks/preload_lock_2", "/seckill/locks/seckill_user_1"}, keys)
+
+	assert.NoError(t, etcdRepo.ReleaseDistributedLock(ctx, "/seckill/locks/seckill_user_1", tokens["/seckill/locks/seckill_user_1"]))
+	keys, err = etcdRepo.ListLocks(ctx, "/seckill/locks/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/seckill/locks/preload_lock_2"}, keys)
+
+	assert.NoError(t, etcdRepo.ReleaseDistributedLock(ctx, "/seckill/locks/preload_lock_2", tokens["/seckill/locks/preload_lock_2"]))
+	keys, err = etcdRepo.ListLocks(ctx, "/seckill/locks/")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+// TestListLockInfos_LeaseTTL 测试锁列表包含租约剩余有效期，租约查询失败时记为-1
+func TestListLockInfos_LeaseTTL(t *testing.T) {
+	etcdRepo, _, lease := setupLockList(t)
+	ctx := context.Background()
+	_, locked, err := etcdRepo.GetDistributedLock(ctx, "/seckill/locks/preload_lock_1", 30)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	locks, err := etcdRepo.ListLockInfos(ctx, "/seckill/locks/")
+	assert.NoError(t, err)
+	assert.Len(t, locks, 1)
+	assert.Equal(t, "/seckill/locks/preload_lock_1", locks[0].Key)
+	assert.NotZero(t, locks[0].LeaseId)
+	assert.InDelta(t, 30, locks[0].TTLSeconds, 1)
+
+	lease.TTLErr = errors.New("lease service unavailable")
+	locks, err = etcdRepo.ListLockInfos(ctx, "/seckill/locks/")
+	assert.NoError(t, err)
+	assert.Len(t, locks, 1)
+	assert.Equal(t, int64(-1), locks[0].TTLSeconds)
+}
+
+// TestListLocks_Controller 测试管理接口返回配置命名空间下的锁及剩余有效期
+func TestListLocks_Controller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	etcdRepo, _, _ := setupLockList(t)
+	factory, err := service.NewLockFactory(config.LockConfig{Namespace: "/custom/locks"}, etcdRepo, &recordingLocker{})
+	assert.NoError(t, err)
+	gs := &service.GoodService{EtcdRepo: etcdRepo, Locks: factory}
+	r := router.NewRouter(&controller.GoodController{GoodService: gs}, noopAuth, true)
+
+	ctx := context.Background()
+	for _, key := range []string{factory.Key("maintenance_cleanup"), "/seckill/locks/other"} {
+		_, locked, err := etcdRepo.GetDistributedLock(ctx, key, 60)
+		assert.NoError(t, err)
+		assert.True(t, locked)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/locks?admin=1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data struct {
+			Count int              `json:"count"`
+			Locks []model.LockInfo `json:"locks"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Data.Count)
+	assert.Equal(t, "/custom/locks/maintenance_cleanup", resp.Data.Locks[0].Key)
+	assert.InDelta(t, 60, resp.Data.Locks[0].TTLSeconds, 1)
+}
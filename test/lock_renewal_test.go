@@ -0,0 +1,102 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"seckill_system/config"
+	"seckill_system/handler"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEtcdLockRenewal_KeepAliveUntilStopped 测试Etcd锁持有期间租约持续续期，stop后停止续期但不释放锁
+func TestEtcdLockRenewal_KeepAliveUntilStopped(t *testing.T) {
+	etcdRepo, kv, lease := setupLockList(t)
+	ctx := context.Background()
+	key := "/seckill/locks/seckill_user_1"
+
+	token, locked, stop, err := etcdRepo.GetDistributedLockWithRenewal(ctx, key, 10)
+	require.NoError(t, err)
+	require.True(t, locked)
+	leaseId := kv.Leases[key]
+	assert.True(t, lease.Renewing(leaseId))
+
+	// 锁被占用时获取失败，且为本次尝试创建的租约被撤销
+	_, locked, otherStop, err := etcdRepo.GetDistributedLockWithRenewal(ctx, key, 10)
+	assert.NoError(t, err)
+	assert.False(t, locked)
+	assert.Nil(t, otherStop)
+	assert.Len(t, lease.Expiry, 1)
+
+	stop()
+	assert.False(t, lease.Renewing(leaseId))
+	assert.Contains(t, kv.Data, key)
+
+	assert.NoError(t, etcdRepo.ReleaseDistributedLock(ctx, key, token))
+	assert.NotContains(t, kv.Data, key)
+}
+
+// TestEtcdLockRenewal_KeepAliveFailure 测试无法续期时释放锁并返回错误
+func TestEtcdLockRenewal_KeepAliveFailure(t *testing.T) {
+	etcdRepo, kv, lease := setupLockList(t)
+	lease.KeepAliveErr = errors.New("lease keepalive unavailable")
+
+	_, locked, stop, err := etcdRepo.GetDistributedLockWithRenewal(context.Background(), "/seckill/locks/seckill_user_1", 10)
+	assert.ErrorContains(t, err, "keepalive")
+	assert.False(t, locked)
+	assert.Nil(t, stop)
+	assert.Empty(t, kv.Data)
+}
+
+// TestRedisLockRenewal_ExtendsExpiry 测试Redis锁持有期间过期时间被刷新，stop后不再续期
+func TestRedisLockRenewal_ExtendsExpiry(t *testing.T) {
+	mr := SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+	key := repository.DistributedLockKey("seckill_user_1")
+
+	_, locked, stop, err := redisRepo.GetDistributedLockWithRenewal(context.Background(), "seckill_user_1", 1)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	mr.FastForward(700 * time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return mr.TTL(key) > 500*time.Millisecond
+	}, 2*time.Second, 20*time.Millisecond, "lock expiry should be renewed")
+
+	stop()
+	mr.FastForward(2 * time.Second)
+	assert.False(t, mr.Exists(key))
+}
+
+// TestSeckillWithToken_StopsLockRenewal 测试秒杀下单持锁期间续期，完成后停止续期并释放锁
+func TestSeckillWithToken_StopsLockRenewal(t *testing.T) {
+	SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	etcdRepo, kv, lease := setupLockList(t)
+	redisRepo := repository.NewRedisRepository()
+	factory, err := service.NewLockFactory(config.LockConfig{}, etcdRepo, redisRepo)
+	require.NoError(t, err)
+	gs := &service.GoodService{
+		RedisRepo:      redisRepo,
+		EtcdRepo:       etcdRepo,
+		SeckillHandler: handler.NewSeckillHandler(),
+		Locks:          factory,
+		Seckill:        config.SeckillConfig{Mode: config.SeckillModeRedis},
+	}
+	require.NoError(t, gs.RedisRepo.SetGoodsStock(1, 10))
+
+	_, err = gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	assert.NoError(t, err)
+
+	assert.Len(t, lease.Expiry, 1) // 用户锁的租约
+	for id := range lease.Expiry {
+		assert.False(t, lease.Renewing(id))
+	}
+	assert.Empty(t, kv.Leases)
+}
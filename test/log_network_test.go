@@ -0,0 +1,127 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"seckill_system/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNetworkLogHandler_TCP 测试日志以JSON行写入TCP收集器
+func TestNetworkLogHandler_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan map[string]any, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var record map[string]any
+			if json.Unmarshal(scanner.Bytes(), &record) == nil {
+				received <- record
+			}
+		}
+	}()
+
+	handler, err := config.NewNetworkLogHandler(config.LogNetworkConfig{
+		Target:  config.LogTargetTCP,
+		Address: listener.Addr().String(),
+	}, slog.LevelInfo)
+	assert.NoError(t, err)
+	logger := slog.New(handler)
+	logger.Info("Order created", "order_id", "1-1-1")
+	logger.Debug("Filtered by level")
+	logger.Warn("Stock low", "goods_id", 1)
+
+	for _, expected := range []string{"Order created", "Stock low"} {
+		select {
+		case record := <-received:
+			assert.Equal(t, expected, record["msg"])
+		case <-time.After(2 * time.Second):
+			t.Fatalf("record %q not delivered", expected)
+		}
+	}
+}
+
+// TestNetworkLogHandler_UDP 测试日志写入UDP收集器
+func TestNetworkLogHandler_UDP(t *testing.T) {
+	sink, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer sink.Close()
+
+	handler, err := config.NewNetworkLogHandler(config.LogNetworkConfig{
+		Target:  config.LogTargetUDP,
+		Address: sink.LocalAddr().String(),
+	}, slog.LevelInfo)
+	assert.NoError(t, err)
+	assert.NoError(t, handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "Payment successful", 0)))
+
+	buf := make([]byte, 4096)
+	sink.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := sink.ReadFrom(buf)
+	assert.NoError(t, err)
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf[:n], &record))
+	assert.Equal(t, "Payment successful", record["msg"])
+}
+
+// TestNetworkLogHandler_Syslog 测试日志带标签写入远程syslog
+func TestNetworkLogHandler_Syslog(t *testing.T) {
+	sink, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer sink.Close()
+
+	handler, err := config.NewNetworkLogHandler(config.LogNetworkConfig{
+		Target:  config.LogTargetSyslog,
+		Address: sink.LocalAddr().String(),
+		Tag:     "seckill_test",
+	}, slog.LevelInfo)
+	assert.NoError(t, err)
+	slog.New(handler).Info("Seckill started")
+
+	buf := make([]byte, 4096)
+	sink.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := sink.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf[:n]), "seckill_test")
+	assert.Contains(t, string(buf[:n]), `"msg":"Seckill started"`)
+}
+
+// TestNetworkLogHandler_Unreachable 测试收集器不可用时丢弃日志而不阻塞
+func TestNetworkLogHandler_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close() // 关闭后该地址不再接受连接
+
+	handler, err := config.NewNetworkLogHandler(config.LogNetworkConfig{
+		Target:  config.LogTargetTCP,
+		Address: address,
+	}, slog.LevelInfo)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		err = handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "dropped", 0))
+		assert.Error(t, err)
+	}
+	assert.Less(t, time.Since(start), time.Second, "retries should back off instead of dialing on every record")
+}
+
+// TestNetworkLogHandler_InvalidTarget 测试不支持的输出目标
+func TestNetworkLogHandler_InvalidTarget(t *testing.T) {
+	_, err := config.NewNetworkLogHandler(config.LogNetworkConfig{Target: "kafka"}, slog.LevelInfo)
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/model"
+	"seckill_system/web/controller"
+	"seckill_system/web/metrics"
+	"seckill_system/web/router"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// metricValue 返回指标在指定商品下的当前值，计数器返回计数，直方图返回样本数
+// 指标注册表为全局共享，测试通过比较前后差值断言；goodsId为metrics.UnknownGoods时查询未校验商品的标签
+func metricValue(t *testing.T, name string, goodsId int64, labels ...string) float64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+	goodsLabel := strconv.FormatInt(goodsId, 10)
+	if goodsId == metrics.UnknownGoods {
+		goodsLabel = metrics.UnknownGoodsLabel
+	}
+	want := map[string]string{"goods_id": goodsLabel}
+	for i := 0; i+1 < len(labels); i += 2 {
+		want[labels[i]] = labels[i+1]
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, pair := range metric.GetLabel() {
+				if want[pair.GetName()] == pair.GetValue() {
+					matched++
+				}
+			}
+			if matched != len(want) {
+				continue
+			}
+			if metric.GetHistogram() != nil {
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+// TestMetrics_SeckillCounters 测试秒杀请求按商品统计尝试、成功和售罄次数以及库存扣减耗时，令牌无效的请求不计入该商品
+func TestMetrics_SeckillCounters(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+	const goodsId = 901
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(goodsId, 1))
+
+	_, err := gs.SeckillWithToken(1, goodsId, mustSeckillToken(t, gs, 1, goodsId))
+	assert.NoError(t, err)
+	_, err = gs.SeckillWithToken(2, goodsId, mustSeckillToken(t, gs, 2, goodsId))
+	assert.Error(t, err)
+	_, err = gs.SeckillWithToken(3, goodsId, absentToken)
+	assert.Error(t, err) // 令牌无效，记录到unknown标签
+
+	assert.Equal(t, float64(2), metricValue(t, "seckill_attempts_total", goodsId))
+	assert.Equal(t, float64(1), metricValue(t, "seckill_successes_total", goodsId))
+	assert.Equal(t, float64(1), metricValue(t, "seckill_sold_out_total", goodsId))
+	assert.Equal(t, float64(2), metricValue(t, "seckill_stock_decr_seconds", goodsId))
+	assert.Zero(t, metricValue(t, "seckill_attempts_total", goodsId+1), "other goods should not be affected")
+}
+
+// TestMetrics_TokenGenerationLatency 测试秒杀令牌签发耗时按商品记录，商品不存在时记录到unknown标签
+func TestMetrics_TokenGenerationLatency(t *testing.T) {
+	gs, _ := setupGoodsAllowlistService(t, model.GoodsAllowlist{})
+	before := metricValue(t, "seckill_token_generation_seconds", 1)
+	unknownBefore := metricValue(t, "seckill_token_generation_seconds", metrics.UnknownGoods)
+
+	_, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	assert.NoError(t, err)
+	_, err = gs.GenerateSeckillToken(100, 999, "203.0.113.7")
+	assert.Error(t, err)
+
+	assert.Equal(t, before+1, metricValue(t, "seckill_token_generation_seconds", 1))
+	assert.Equal(t, unknownBefore+1, metricValue(t, "seckill_token_generation_seconds", metrics.UnknownGoods))
+	assert.Zero(t, metricValue(t, "seckill_token_generation_seconds", 999), "client supplied goods id should not become a label")
+}
+
+// TestMetrics_SeckillUnknownGoods 测试令牌校验失败的秒杀请求记录到unknown标签，不按客户端传入的商品ID打标签
+func TestMetrics_SeckillUnknownGoods(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+	const goodsId = 903
+	before := metricValue(t, "seckill_failures_total", metrics.UnknownGoods, "outcome", "invalid_token")
+
+	_, err := gs.SeckillWithToken(1, goodsId, absentToken)
+	assert.Error(t, err)
+
+	assert.Equal(t, before+1, metricValue(t, "seckill_failures_total", metrics.UnknownGoods, "outcome", "invalid_token"))
+	assert.Zero(t, metricValue(t, "seckill_attempts_total", goodsId))
+}
+
+// TestMetrics_Endpoint 测试公共路由提供Prometheus文本格式的指标接口
+func TestMetrics_Endpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics.ObserveSeckill(902, nil)
+	r := router.NewRouter(&controller.GoodController{}, noopAuth, false)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, w.Body.String(), `seckill_successes_total{goods_id="902"} 1`)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"fmt"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrateDatabase_LegacySuccessKilled 测试旧版联合主键的秒杀成功记录表迁移为自增主键并回填订单ID
+func TestMigrateDatabase_LegacySuccessKilled(t *testing.T) {
+	db := openTestDB(t)
+	require.NoError(t, db.Exec(`CREATE TABLE success_killed (
+		goods_id integer, user_id integer, state integer, create_time datetime,
+		PRIMARY KEY (goods_id, user_id))`).Error)
+	placed := time.Now().Add(-time.Hour)
+	require.NoError(t, db.Exec("INSERT INTO success_killed (goods_id, user_id, state, create_time) VALUES (1, 100, 1, ?), (2, 100, 0, ?)",
+		placed, placed).Error)
+
+	require.NoError(t, global.MigrateDatabase(db))
+
+	// 历史记录保留状态并回填订单ID
+	var orders []model.SuccessKilled
+	require.NoError(t, db.Order("goods_id").Find(&orders).Error)
+	require.Len(t, orders, 2)
+	assert.Equal(t, model.LegacyOrderId(100, 1), orders[0].OrderId)
+	assert.Equal(t, int16(1), orders[0].State)
+	assert.Equal(t, model.LegacyOrderId(100, 2), orders[1].OrderId)
+	assert.NotZero(t, orders[0].Id)
+
+	// 同一用户可以再次购买同一商品
+	require.NoError(t, db.Create(&model.SuccessKilled{OrderId: "100-1-1", GoodsId: 1, UserId: 100}).Error)
+
+	// 迁移前签发的订单ID仍能查到历史订单
+	repo := repository.NewGoodRepository()
+	order, err := repo.FindSuccessKilledByOrderId(fmt.Sprintf("100-2-%d", placed.UnixNano()), 100, 2)
+	require.NoError(t, err)
+	assert.Equal(t, orders[1].Id, order.Id)
+
+	// 重复执行迁移不影响已迁移的表
+	require.NoError(t, global.MigrateDatabase(db))
+	var count int64
+	require.NoError(t, db.Model(&model.SuccessKilled{}).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
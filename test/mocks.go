@@ -3,10 +3,17 @@ package test
 import (
 	"context"
 	"errors"
+	"reflect"
 	"seckill_system/model"
+	"seckill_system/repository"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
 	"gorm.io/gorm"
 )
 
@@ -200,9 +207,9 @@ func (m *MockRedisRepository) UserRateLimit(userId int64, limit int64, duration
 // MockKafkaRepository Kafka仓库的模拟实现
 type MockKafkaRepository struct {
 	Messages       []any // 消息存储
-	ShouldError    bool          // 是否模拟错误
-	SendOrderErr   error         // 发送订单消息错误
-	SendPaymentErr error         // 发送支付消息错误
+	ShouldError    bool  // 是否模拟错误
+	SendOrderErr   error // 发送订单消息错误
+	SendPaymentErr error // 发送支付消息错误
 }
 
 // NewMockKafkaRepository 创建模拟Kafka仓库实例
@@ -237,8 +244,9 @@ func (m *MockKafkaRepository) SendPaymentMessage(ctx context.Context, orderId st
 type MockETCDRepository struct {
 	Configs     map[string]string // 配置数据
 	Blacklist   map[int64]bool    // 黑名单数据
-	Locks       map[string]bool   // 分布式锁状态
+	Locks       map[string]string // 分布式锁持有者令牌
 	ShouldError bool              // 是否模拟错误
+	lockSeq     int               // 已签发的锁令牌数量
 }
 
 // NewMockETCDRepository 创建模拟ETCD仓库实例
@@ -249,7 +257,7 @@ func NewMockETCDRepository() *MockETCDRepository {
 			"/seckill/config/rate_limit": "10",   // 默认限流10
 		},
 		Blacklist: make(map[int64]bool),
-		Locks:     make(map[string]bool),
+		Locks:     make(map[string]string),
 	}
 }
 
@@ -262,22 +270,27 @@ func (m *MockETCDRepository) GetSeckillEnabled(ctx context.Context) (bool, error
 }
 
 // GetDistributedLock 获取分布式锁
-func (m *MockETCDRepository) GetDistributedLock(ctx context.Context, key string, ttl int) (bool, error) {
+func (m *MockETCDRepository) GetDistributedLock(ctx context.Context, key string, ttl int) (string, bool, error) {
 	if m.ShouldError {
-		return false, errors.New("mock error")
+		return "", false, errors.New("mock error")
 	}
-	if m.Locks[key] {
-		return false, nil // 锁已被占用，获取失败
+	if _, held := m.Locks[key]; held {
+		return "", false, nil // 锁已被占用，获取失败
 	}
-	m.Locks[key] = true // 获取锁成功
-	return true, nil
+	m.lockSeq++
+	token := "token-" + strconv.Itoa(m.lockSeq)
+	m.Locks[key] = token // 获取锁成功
+	return token, true, nil
 }
 
-// ReleaseDistributedLock 释放分布式锁
-func (m *MockETCDRepository) ReleaseDistributedLock(ctx context.Context, key string) error {
+// ReleaseDistributedLock 释放分布式锁，令牌不匹配时不释放
+func (m *MockETCDRepository) ReleaseDistributedLock(ctx context.Context, key, token string) error {
 	if m.ShouldError {
 		return errors.New("mock error")
 	}
+	if m.Locks[key] != token {
+		return repository.ErrLockNotHeld
+	}
 	delete(m.Locks, key) // 释放锁
 	return nil
 }
@@ -308,3 +321,233 @@ func (m *MockETCDRepository) GetRateLimitConfig(ctx context.Context) (int64, err
 	}
 	return limit, nil
 }
+
+// MockEtcdKV Etcd KV接口的模拟实现，支持Get（含前缀查询）、Put、Delete和单键比较的事务
+type MockEtcdKV struct {
+	clientv3.KV                             // 未实现的方法调用时会panic
+	Data        map[string]string           // 键值数据
+	Leases      map[string]clientv3.LeaseID // 键绑定的租约ID
+	PutFailures int                         // 前N次Put调用返回错误
+	PutCalls    int                         // Put调用次数
+	GetErr      error                       // 非nil时Get调用返回该错误，模拟Etcd不可用
+}
+
+// NewMockEtcdKV 创建模拟Etcd KV实例
+func NewMockEtcdKV() *MockEtcdKV {
+	return &MockEtcdKV{
+		Data:   make(map[string]string),
+		Leases: make(map[string]clientv3.LeaseID),
+	}
+}
+
+// Get 获取键值，WithPrefix时按键字典序返回前缀下的全部键值
+func (m *MockEtcdKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	if m.GetErr != nil {
+		return nil, m.GetErr
+	}
+	op := clientv3.OpGet(key, opts...)
+	keys := []string{key}
+	if end := op.RangeBytes(); len(end) > 0 {
+		keys = keys[:0]
+		for k := range m.Data {
+			if k >= key && k < string(end) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+	}
+
+	resp := &clientv3.GetResponse{}
+	for _, k := range keys {
+		value, exists := m.Data[k]
+		if !exists {
+			continue
+		}
+		kv := &mvccpb.KeyValue{Key: []byte(k), Lease: int64(m.Leases[k]), CreateRevision: 1}
+		if !op.IsKeysOnly() {
+			kv.Value = []byte(value)
+		}
+		resp.Kvs = append(resp.Kvs, kv)
+	}
+	resp.Count = int64(len(resp.Kvs))
+	return resp, nil
+}
+
+// Put 写入键值，前PutFailures次调用模拟临时故障
+func (m *MockEtcdKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	m.PutCalls++
+	if m.PutCalls <= m.PutFailures {
+		return nil, errors.New("mock etcd unavailable")
+	}
+	m.put(clientv3.OpPut(key, val, opts...))
+	return &clientv3.PutResponse{}, nil
+}
+
+// put 执行写入操作并记录租约
+func (m *MockEtcdKV) put(op clientv3.Op) {
+	key := string(op.KeyBytes())
+	m.Data[key] = string(op.ValueBytes())
+	// clientv3.Op未导出租约ID，通过反射读取
+	if lease := clientv3.LeaseID(reflect.ValueOf(op).FieldByName("leaseID").Int()); lease != 0 {
+		m.Leases[key] = lease
+	} else {
+		delete(m.Leases, key)
+	}
+}
+
+// Delete 删除键值
+func (m *MockEtcdKV) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	_, exists := m.Data[key]
+	delete(m.Data, key)
+	delete(m.Leases, key)
+	resp := &clientv3.DeleteResponse{}
+	if exists {
+		resp.Deleted = 1
+	}
+	return resp, nil
+}
+
+// Txn 创建模拟事务，支持比较键的创建版本（键存在时为1，不存在时为0）和键的值是否相等
+func (m *MockEtcdKV) Txn(ctx context.Context) clientv3.Txn {
+	return &mockEtcdTxn{kv: m}
+}
+
+// mockEtcdTxn 模拟Etcd事务
+type mockEtcdTxn struct {
+	kv      *MockEtcdKV    // 所属的模拟KV
+	cmps    []clientv3.Cmp // 比较条件
+	thenOps []clientv3.Op  // 条件成立时执行的操作
+	elseOps []clientv3.Op  // 条件不成立时执行的操作
+}
+
+// If 设置比较条件
+func (txn *mockEtcdTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	txn.cmps = append(txn.cmps, cs...)
+	return txn
+}
+
+// Then 设置条件成立时执行的操作
+func (txn *mockEtcdTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	txn.thenOps = append(txn.thenOps, ops...)
+	return txn
+}
+
+// Else 设置条件不成立时执行的操作
+func (txn *mockEtcdTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	txn.elseOps = append(txn.elseOps, ops...)
+	return txn
+}
+
+// Commit 比较条件并执行对应分支的写入和删除操作
+func (txn *mockEtcdTxn) Commit() (*clientv3.TxnResponse, error) {
+	succeeded := true
+	for _, cmp := range txn.cmps {
+		if cmp.Result != etcdserverpb.Compare_EQUAL {
+			return nil, errors.New("mock etcd txn only supports equality comparisons")
+		}
+		value, exists := txn.kv.Data[string(cmp.KeyBytes())]
+		switch target := cmp.TargetUnion.(type) {
+		case *etcdserverpb.Compare_CreateRevision:
+			createRevision := int64(0)
+			if exists {
+				createRevision = 1
+			}
+			succeeded = succeeded && createRevision == target.CreateRevision
+		case *etcdserverpb.Compare_Value:
+			succeeded = succeeded && exists && value == string(target.Value)
+		default:
+			return nil, errors.New("mock etcd txn only supports create revision and value comparisons")
+		}
+	}
+
+	ops := txn.elseOps
+	if succeeded {
+		ops = txn.thenOps
+	}
+	for _, op := range ops {
+		switch {
+		case op.IsPut():
+			txn.kv.put(op)
+		case op.IsDelete():
+			txn.kv.Delete(context.Background(), string(op.KeyBytes()))
+		}
+	}
+	return &clientv3.TxnResponse{Succeeded: succeeded}, nil
+}
+
+// MockEtcdLease Etcd Lease接口的模拟实现，支持创建、查询剩余有效期、续期和撤销租约
+type MockEtcdLease struct {
+	clientv3.Lease                                // 未实现的方法调用时会panic
+	nextId         clientv3.LeaseID               // 下一个分配的租约ID
+	Expiry         map[clientv3.LeaseID]time.Time // 租约过期时间
+	GrantErr       error                          // 不为nil时Grant返回该错误
+	TTLErr         error                          // 不为nil时TimeToLive返回该错误
+	KeepAliveErr   error                          // 不为nil时KeepAlive返回该错误
+
+	mu       sync.Mutex                // 保护renewing
+	renewing map[clientv3.LeaseID]bool // 正在续期的租约
+}
+
+// NewMockEtcdLease 创建模拟Etcd Lease实例
+func NewMockEtcdLease() *MockEtcdLease {
+	return &MockEtcdLease{
+		Expiry:   make(map[clientv3.LeaseID]time.Time),
+		renewing: make(map[clientv3.LeaseID]bool),
+	}
+}
+
+// KeepAlive 开始续期租约，ctx取消后停止续期并关闭响应通道
+func (l *MockEtcdLease) KeepAlive(ctx context.Context, id clientv3.LeaseID) (<-chan *clientv3.LeaseKeepAliveResponse, error) {
+	if l.KeepAliveErr != nil {
+		return nil, l.KeepAliveErr
+	}
+	l.mu.Lock()
+	l.renewing[id] = true
+	l.mu.Unlock()
+
+	ch := make(chan *clientv3.LeaseKeepAliveResponse, 1)
+	ch <- &clientv3.LeaseKeepAliveResponse{ID: id}
+	go func() {
+		<-ctx.Done()
+		l.mu.Lock()
+		delete(l.renewing, id)
+		l.mu.Unlock()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// Renewing 返回租约是否正在续期
+func (l *MockEtcdLease) Renewing(id clientv3.LeaseID) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.renewing[id]
+}
+
+// Grant 创建租约
+func (l *MockEtcdLease) Grant(ctx context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error) {
+	if l.GrantErr != nil {
+		return nil, l.GrantErr
+	}
+	l.nextId++
+	l.Expiry[l.nextId] = time.Now().Add(time.Duration(ttl) * time.Second)
+	return &clientv3.LeaseGrantResponse{ID: l.nextId, TTL: ttl}, nil
+}
+
+// TimeToLive 返回租约剩余有效期（秒），租约不存在或已过期时为-1
+func (l *MockEtcdLease) TimeToLive(ctx context.Context, id clientv3.LeaseID, opts ...clientv3.LeaseOption) (*clientv3.LeaseTimeToLiveResponse, error) {
+	if l.TTLErr != nil {
+		return nil, l.TTLErr
+	}
+	resp := &clientv3.LeaseTimeToLiveResponse{ID: id, TTL: -1}
+	if expiry, exists := l.Expiry[id]; exists && time.Now().Before(expiry) {
+		resp.TTL = int64(time.Until(expiry).Round(time.Second) / time.Second)
+	}
+	return resp, nil
+}
+
+// Revoke 撤销租约
+func (l *MockEtcdLease) Revoke(ctx context.Context, id clientv3.LeaseID) (*clientv3.LeaseRevokeResponse, error) {
+	delete(l.Expiry, id)
+	return &clientv3.LeaseRevokeResponse{}, nil
+}
@@ -0,0 +1,157 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// sliceMessageReader 依次返回预置消息的读取器，读完后返回io.EOF结束消费循环
+type sliceMessageReader struct {
+	messages []kafka.Message
+}
+
+// ReadMessage 返回下一条预置消息
+func (r *sliceMessageReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	if len(r.messages) == 0 {
+		return kafka.Message{}, io.EOF
+	}
+	msg := r.messages[0]
+	r.messages = r.messages[1:]
+	return msg, nil
+}
+
+// orderKafkaMessage 构造订单消息
+func orderKafkaMessage(t *testing.T, orderId string, offset int64) kafka.Message {
+	data, err := json.Marshal(model.OrderMessage{OrderId: orderId, Status: model.OrderStatusCreated})
+	assert.NoError(t, err)
+	return kafka.Message{Topic: "seckill_test_orders", Offset: offset, Value: data}
+}
+
+// TestConsumeOrderMessages_SlowHandlerTimedOut 测试处理超时的消息被转交死信处理，消费循环继续处理后续消息
+func TestConsumeOrderMessages_SlowHandlerTimedOut(t *testing.T) {
+	reader := &sliceMessageReader{messages: []kafka.Message{
+		orderKafkaMessage(t, "1-1-1", 0),
+		orderKafkaMessage(t, "2-1-1", 1),
+	}}
+	release := make(chan struct{})
+	defer close(release) // 测试结束后释放挂起的处理函数
+
+	var mu sync.Mutex
+	var handled, timedOut []string
+	handler := func(order model.OrderMessage) error {
+		if order.OrderId == "1-1-1" {
+			<-release // 模拟挂起的处理函数
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, order.OrderId)
+		return nil
+	}
+	onTimeout := func(ctx context.Context, msg kafka.Message, reason string) error {
+		assert.Equal(t, repository.ErrHandlerTimeout.Error(), reason)
+		mu.Lock()
+		defer mu.Unlock()
+		timedOut = append(timedOut, string(msg.Value))
+		return nil
+	}
+
+	start := time.Now()
+	err := repository.ConsumeOrderMessagesFrom(context.Background(), reader, 20*time.Millisecond, 3, handler, onTimeout)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Less(t, time.Since(start), time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"2-1-1"}, handled)
+	assert.Len(t, timedOut, 1)
+	assert.Contains(t, timedOut[0], "1-1-1")
+}
+
+// TestConsumeOrderMessages_CancelWhileHandling 测试处理中ctx取消时消费循环立即退出
+func TestConsumeOrderMessages_CancelWhileHandling(t *testing.T) {
+	reader := &sliceMessageReader{messages: []kafka.Message{orderKafkaMessage(t, "1-1-1", 0)}}
+	release := make(chan struct{})
+	defer close(release)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	handler := func(order model.OrderMessage) error {
+		cancel()
+		<-release
+		return nil
+	}
+	err := repository.ConsumeOrderMessagesFrom(ctx, reader, time.Minute, 1, handler, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// deadLetterRecorder 记录转交死信处理的消息和原因
+type deadLetterRecorder struct {
+	mu       sync.Mutex
+	messages []kafka.Message
+	reasons  []string
+}
+
+// route 记录一条死信消息
+func (r *deadLetterRecorder) route(ctx context.Context, msg kafka.Message, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	r.reasons = append(r.reasons, reason)
+	return nil
+}
+
+// TestConsumeOrderMessages_UnparsableToDeadLetter 测试无法解析的消息转交死信处理，不调用处理函数
+func TestConsumeOrderMessages_UnparsableToDeadLetter(t *testing.T) {
+	bad := kafka.Message{Topic: "seckill_test_orders", Partition: 2, Offset: 7, Value: []byte("{not json")}
+	reader := &sliceMessageReader{messages: []kafka.Message{bad, orderKafkaMessage(t, "1-1-1", 8)}}
+	dlq := &deadLetterRecorder{}
+	var handled []string
+	handler := func(order model.OrderMessage) error {
+		handled = append(handled, order.OrderId)
+		return nil
+	}
+
+	err := repository.ConsumeOrderMessagesFrom(context.Background(), reader, time.Minute, 3, handler, dlq.route)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []string{"1-1-1"}, handled)
+	assert.Len(t, dlq.messages, 1)
+	assert.Equal(t, bad, dlq.messages[0]) // 原始消息（含分区和偏移量）原样转交
+	assert.Contains(t, dlq.reasons[0], "unmarshal failed")
+}
+
+// TestConsumeOrderMessages_RetryThenDeadLetter 测试处理失败的消息按次数重试，耗尽后转交死信处理，重试成功的消息不转交
+func TestConsumeOrderMessages_RetryThenDeadLetter(t *testing.T) {
+	previous := repository.OrderHandlerRetryBackoff
+	repository.OrderHandlerRetryBackoff = time.Millisecond
+	t.Cleanup(func() { repository.OrderHandlerRetryBackoff = previous })
+
+	reader := &sliceMessageReader{messages: []kafka.Message{
+		orderKafkaMessage(t, "1-1-1", 0),
+		orderKafkaMessage(t, "2-1-1", 1),
+	}}
+	dlq := &deadLetterRecorder{}
+	calls := make(map[string]int)
+	handler := func(order model.OrderMessage) error {
+		calls[order.OrderId]++
+		if order.OrderId == "1-1-1" || calls[order.OrderId] == 1 {
+			return errors.New("database unavailable") // 1-1-1始终失败，2-1-1首次失败后成功
+		}
+		return nil
+	}
+
+	err := repository.ConsumeOrderMessagesFrom(context.Background(), reader, time.Minute, 3, handler, dlq.route)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, map[string]int{"1-1-1": 3, "2-1-1": 2}, calls)
+	assert.Len(t, dlq.messages, 1)
+	assert.Contains(t, string(dlq.messages[0].Value), "1-1-1")
+	assert.Equal(t, "handler failed after 3 attempts: database unavailable", dlq.reasons[0])
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"seckill_system/config"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOrderCreateTime_DBMode 测试db模式下单记录下单时间，查询结果位于配置的时区
+func TestOrderCreateTime_DBMode(t *testing.T) {
+	applyTimeConfig(t, shanghaiTimeConfig)
+	gs := SetupTestService(t, WithGoods(5, 1), WithSeckillHandler()).Service
+	h := gs.SeckillHandler
+
+	before := time.Now()
+	orderId, err := h.CreateOrder(context.Background(), 100, 1, 1)
+	require.NoError(t, err)
+	after := time.Now()
+
+	status, err := gs.GetOrderStatus(orderId)
+	require.NoError(t, err)
+	assert.WithinRange(t, status.CreateTime.Time, before.Add(-time.Millisecond), after.Add(time.Millisecond))
+	assert.Equal(t, config.TimeLocation(), status.CreateTime.Location())
+}
+
+// TestOrderCreateTime_RedisOnlyMode 测试Redis-only模式订单写库后保留下单时间而不是写库时间
+func TestOrderCreateTime_RedisOnlyMode(t *testing.T) {
+	gs := SetupTestService(t, WithGoods(5, 1), WithSeckillHandler()).Service
+	h := gs.SeckillHandler
+
+	before := time.Now()
+	orderId, err := h.CreateOrderRedisOnly(context.Background(), 100, 1, 1)
+	require.NoError(t, err)
+	flushStart := time.Now()
+	flushed, err := h.FlushPendingOrders(context.Background(), 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, flushed)
+
+	status, err := gs.GetOrderStatus(orderId)
+	require.NoError(t, err)
+	assert.WithinRange(t, status.CreateTime.Time, before.Add(-time.Millisecond), flushStart)
+}
+
+// TestOrderCreateTime_LegacyRecord 测试未记录下单时间的历史订单使用订单ID中的时间戳
+func TestOrderCreateTime_LegacyRecord(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.Exec("INSERT INTO success_killed (goods_id, user_id, state) VALUES (1, 100, 0)").Error)
+	gs := &service.GoodService{GoodDB: repository.NewGoodRepository()}
+
+	placed := time.Date(2025, 8, 29, 6, 30, 56, 0, time.UTC)
+	status, err := gs.GetOrderStatus(fmt.Sprintf("100-1-%d", placed.UnixNano()))
+	require.NoError(t, err)
+	assert.True(t, placed.Equal(status.CreateTime.Time), "create time %v", status.CreateTime)
+}
+
+// TestGetOrderStatus_CreateTimeFormatted 测试订单状态接口按配置的时区和格式返回下单时间
+func TestGetOrderStatus_CreateTimeFormatted(t *testing.T) {
+	applyTimeConfig(t, shanghaiTimeConfig)
+	r := newOrderStatusRouter(t)
+
+	w := getOrderStatus(r, "100", "100-1-1700000000000000000")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data struct {
+			CreateTime string `json:"create_time"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	created, err := time.Parse(shanghaiTimeConfig.Format, resp.Data.CreateTime)
+	require.NoError(t, err, "create_time %q", resp.Data.CreateTime)
+	assert.Regexp(t, `\+0800$`, resp.Data.CreateTime)
+	assert.WithinDuration(t, time.Now(), created, time.Minute)
+}
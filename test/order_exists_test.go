@@ -0,0 +1,30 @@
+package test
+
+import (
+	"seckill_system/model"
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGoodRepository_HasUserOrder 测试查询用户是否已有商品订单
+func TestGoodRepository_HasUserOrder(t *testing.T) {
+	db := SetupTestDB(t)
+	assert.NoError(t, db.Create(&model.SuccessKilled{GoodsId: 1, UserId: 100}).Error)
+	repo := repository.NewGoodRepository()
+
+	exists, err := repo.HasUserOrder(100, 1)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// 同一用户的其他商品
+	exists, err = repo.HasUserOrder(100, 2)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	// 同一商品的其他用户
+	exists, err = repo.HasUserOrder(101, 1)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
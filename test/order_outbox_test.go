@@ -0,0 +1,74 @@
+package test
+
+import (
+	"context"
+	"seckill_system/handler"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// TestGoodRepository_GetPromotionByGoodsId_NotFound 测试促销不存在时返回类型化错误
+func TestGoodRepository_GetPromotionByGoodsId_NotFound(t *testing.T) {
+	SetupTestDB(t)
+	repo := repository.NewGoodRepository()
+
+	_, err := repo.GetPromotionByGoodsId(1)
+	assert.ErrorIs(t, err, repository.ErrPromotionNotFound)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound) // 兼容原有判断
+}
+
+// TestSeckillHandler_DeliverOrderMessage_MissingPromotion 测试促销缺失时订单消息转入发件箱
+func TestSeckillHandler_DeliverOrderMessage_MissingPromotion(t *testing.T) {
+	SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	h := handler.NewSeckillHandler()
+	redisRepo := repository.NewRedisRepository()
+
+	err := h.DeliverOrderMessage(context.Background(), "100-1-1", 100, 1)
+	assert.ErrorIs(t, err, repository.ErrPromotionNotFound)
+
+	// 消息未丢失，进入发件箱等待重试
+	count, err := redisRepo.OrderOutboxLen()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	entry, found, err := redisRepo.PopOrderOutbox()
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "100-1-1", entry.Message.OrderId)
+	assert.Equal(t, int64(100), entry.Message.UserId)
+	assert.Equal(t, int64(1), entry.Message.GoodsId)
+	assert.Equal(t, int32(model.OrderStatusCreated), entry.Message.Status)
+	assert.True(t, entry.PricePending)
+	assert.Contains(t, entry.Reason, "promotion not found")
+}
+
+// TestSeckillHandler_RetryOrderOutbox_StillMissing 测试重试时促销仍缺失，消息保留在发件箱
+func TestSeckillHandler_RetryOrderOutbox_StillMissing(t *testing.T) {
+	SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	h := handler.NewSeckillHandler()
+	redisRepo := repository.NewRedisRepository()
+
+	assert.Error(t, h.DeliverOrderMessage(context.Background(), "100-1-1", 100, 1))
+
+	sent, err := h.RetryOrderOutbox(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	entry, found, err := redisRepo.PopOrderOutbox()
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 1, entry.Attempts)
+	assert.True(t, entry.PricePending)
+
+	_, found, err = redisRepo.PopOrderOutbox()
+	assert.NoError(t, err)
+	assert.False(t, found) // 重试不会重复放入
+}
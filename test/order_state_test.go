@@ -0,0 +1,151 @@
+package test
+
+import (
+	"seckill_system/errs"
+	"seckill_system/model"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// TestCanTransitionOrderState 枚举所有订单状态迁移，只有未支付到已支付或已取消是合法的
+func TestCanTransitionOrderState(t *testing.T) {
+	states := []int16{model.OrderStateUnpaid, model.OrderStatePaid, model.OrderStateCancelled}
+	legal := map[[2]int16]bool{
+		{model.OrderStateUnpaid, model.OrderStatePaid}:      true,
+		{model.OrderStateUnpaid, model.OrderStateCancelled}: true,
+	}
+	for _, from := range states {
+		for _, to := range states {
+			name := model.OrderStateName(from) + "->" + model.OrderStateName(to)
+			assert.Equal(t, legal[[2]int16{from, to}], model.CanTransitionOrderState(from, to), name)
+		}
+	}
+	assert.False(t, model.CanTransitionOrderState(9, model.OrderStatePaid))
+	assert.Equal(t, "state(9)", model.OrderStateName(9))
+}
+
+// setupOrderStateService 创建商品1库存为5的商品服务，并写入用户100在商品1的指定状态订单
+func setupOrderStateService(t *testing.T, state int16) (*service.GoodService, *gorm.DB) {
+	f := SetupTestService(t, WithGoods(5, 1), WithOrders(model.SuccessKilled{GoodsId: 1, UserId: 100, State: state}))
+	return f.Service, f.DB
+}
+
+// TestTransitionOrderState 测试仓库层的合法迁移、重复迁移、非法迁移和订单不存在
+func TestTransitionOrderState(t *testing.T) {
+	gs, db := setupOrderStateService(t, model.OrderStateUnpaid)
+
+	transition := func(userId int64, to int16) (ok bool, err error) {
+		err = gs.GoodDB.WithTransaction(func(tx *gorm.DB) error {
+			var txErr error
+			ok, txErr = gs.GoodDB.TransitionOrderState(tx, "", userId, 1, to)
+			return txErr
+		})
+		return ok, err
+	}
+
+	ok, err := transition(100, model.OrderStatePaid)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, model.OrderStatePaid, orderState(t, db, 100, 1))
+
+	ok, err = transition(100, model.OrderStatePaid)
+	assert.NoError(t, err)
+	assert.False(t, ok, "repeated transition should be a no-op")
+
+	_, err = transition(100, model.OrderStateCancelled)
+	assert.ErrorIs(t, err, errs.ErrInvalidOrderTransition)
+	assert.ErrorIs(t, err, errs.ErrForbidden)
+	assert.ErrorContains(t, err, "paid -> cancelled")
+	assert.Equal(t, model.OrderStatePaid, orderState(t, db, 100, 1))
+
+	_, err = transition(200, model.OrderStatePaid)
+	assert.ErrorIs(t, err, errs.ErrOrderNotFound)
+}
+
+// TestCancelUnpaidOrder_PaidOrderRejected 测试已支付的订单不能被取消且不归还库存
+func TestCancelUnpaidOrder_PaidOrderRejected(t *testing.T) {
+	gs, db := setupOrderStateService(t, model.OrderStatePaid)
+
+	err := gs.GoodDB.WithTransaction(func(tx *gorm.DB) error {
+		_, err := gs.GoodDB.CancelUnpaidOrder(tx, "", 100, 1)
+		return err
+	})
+	assert.ErrorIs(t, err, errs.ErrInvalidOrderTransition)
+
+	var promotion model.PromotionSecKill
+	assert.NoError(t, db.Where("goods_id = ?", 1).First(&promotion).Error)
+	assert.Equal(t, int64(5), promotion.PsCount)
+	assert.Equal(t, model.OrderStatePaid, orderState(t, db, 100, 1))
+}
+
+// TestPaymentResult_SuccessMarksOrderPaid 测试支付成功将未支付订单标记为已支付
+func TestPaymentResult_SuccessMarksOrderPaid(t *testing.T) {
+	gs, db := setupOrderStateService(t, model.OrderStateUnpaid)
+
+	assert.NoError(t, gs.HandlePaymentResult("100-1-1", model.OrderStatusPaid))
+	assert.Equal(t, model.OrderStatePaid, orderState(t, db, 100, 1))
+
+	// 重复的支付成功消息是幂等的
+	assert.NoError(t, gs.HandlePaymentResult("100-1-1", model.OrderStatusPaid))
+}
+
+// TestPaymentResult_FailureCancelsOrder 测试未配置宽限期时支付失败取消订单并归还库存
+func TestPaymentResult_FailureCancelsOrder(t *testing.T) {
+	gs, db := setupOrderStateService(t, model.OrderStateUnpaid)
+
+	assert.NoError(t, gs.HandlePaymentResult("100-1-1", model.OrderStatusPaymentFailed))
+	assert.Equal(t, model.OrderStateCancelled, orderState(t, db, 100, 1))
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), stock)
+}
+
+// TestPaymentResult_FailureReplayIdempotent 测试重放的支付失败消息不会重复归还数据库和Redis库存
+func TestPaymentResult_FailureReplayIdempotent(t *testing.T) {
+	gs, db := setupOrderStateService(t, model.OrderStateUnpaid)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, gs.HandlePaymentResult("100-1-1", model.OrderStatusPaymentFailed))
+	}
+	assert.Equal(t, model.OrderStateCancelled, orderState(t, db, 100, 1))
+
+	var promotion model.PromotionSecKill
+	assert.NoError(t, db.Where("goods_id = ?", 1).First(&promotion).Error)
+	assert.Equal(t, int64(6), promotion.PsCount)
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), stock)
+}
+
+// TestPaymentResult_SuccessAfterCancelRejected 测试已取消的订单收到支付成功消息时返回非法迁移错误
+func TestPaymentResult_SuccessAfterCancelRejected(t *testing.T) {
+	gs, db := setupOrderStateService(t, model.OrderStateCancelled)
+
+	err := gs.HandlePaymentResult("100-1-1", model.OrderStatusPaid)
+	assert.ErrorIs(t, err, errs.ErrInvalidOrderTransition)
+	assert.Equal(t, model.OrderStateCancelled, orderState(t, db, 100, 1))
+
+	assert.ErrorContains(t, gs.HandlePaymentResult("bad-order", model.OrderStatusPaid), "invalid order id")
+}
+
+// TestSweepPaymentRetries_SkipsPaidOrder 测试宽限期到期时订单已支付则扫描任务不取消订单
+func TestSweepPaymentRetries_SkipsPaidOrder(t *testing.T) {
+	gs, db := setupOrderStateService(t, model.OrderStateUnpaid)
+	gs.PaymentGrace = time.Minute
+
+	assert.NoError(t, gs.HandlePaymentResult("100-1-1", model.OrderStatusPaymentFailed))
+	// 订单在宽限期内被其他途径标记为已支付
+	assert.NoError(t, db.Model(&model.SuccessKilled{}).Where("user_id = ?", 100).Update("state", model.OrderStatePaid).Error)
+
+	cancelled, err := gs.SweepPaymentRetries(time.Now().Add(2 * time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"100-1-1"}, cancelled)
+	assert.Equal(t, model.OrderStatePaid, orderState(t, db, 100, 1))
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), stock)
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/errs"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGoodRepository_FindSuccessKilledByGoodsAndUser 测试按商品和用户查询秒杀成功记录
+func TestGoodRepository_FindSuccessKilledByGoodsAndUser(t *testing.T) {
+	db := SetupTestDB(t)
+	assert.NoError(t, db.Create(&model.SuccessKilled{GoodsId: 1, UserId: 100, State: model.OrderStatePaid}).Error)
+	repo := repository.NewGoodRepository()
+
+	order, err := repo.FindSuccessKilledByGoodsAndUser(1, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, model.OrderStatePaid, order.State)
+
+	_, err = repo.FindSuccessKilledByGoodsAndUser(1, 101)
+	assert.ErrorIs(t, err, errs.ErrOrderNotFound)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+}
+
+// newOrderStatusRouter 创建订单状态查询路由，认证中间件将请求头X-User-Id作为当前用户
+func newOrderStatusRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	db := SetupTestDB(t)
+	assert.NoError(t, db.Create(&model.SuccessKilled{GoodsId: 1, UserId: 100, State: model.OrderStateUnpaid}).Error)
+	assert.NoError(t, db.Create(&model.SuccessKilled{GoodsId: 2, UserId: 100, State: model.OrderStateCancelled}).Error)
+
+	goodController := &controller.GoodController{GoodService: &service.GoodService{GoodDB: repository.NewGoodRepository()}}
+	auth := func(c *gin.Context) {
+		userId, _ := strconv.ParseInt(c.GetHeader("X-User-Id"), 10, 64)
+		c.Set("userId", userId)
+		c.Next()
+	}
+	return router.NewRouter(goodController, auth, false)
+}
+
+// getOrderStatus 以指定用户请求订单状态接口
+func getOrderStatus(r *gin.Engine, userId, orderId string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/order/"+orderId, nil)
+	req.Header.Set("X-User-Id", userId)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestGetOrderStatus_API 测试订单状态查询接口返回可读状态、订单不存在时返回404
+func TestGetOrderStatus_API(t *testing.T) {
+	r := newOrderStatusRouter(t)
+
+	w := getOrderStatus(r, "100", "100-1-1700000000000000000")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data model.OrderStatus `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "100-1-1700000000000000000", resp.Data.OrderId)
+	assert.Equal(t, int64(1), resp.Data.GoodsId)
+	assert.Equal(t, "unpaid", resp.Data.Status)
+
+	w = getOrderStatus(r, "100", "100-2-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"cancelled"`)
+
+	w = getOrderStatus(r, "100", "100-3-1")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "order not found")
+
+	w = getOrderStatus(r, "100", "not-an-order")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestGetOrderStatus_NotOwner 测试查询其他用户的订单返回403
+func TestGetOrderStatus_NotOwner(t *testing.T) {
+	r := newOrderStatusRouter(t)
+
+	w := getOrderStatus(r, "200", "100-1-1")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), errs.ErrOrderNotOwned.Error())
+
+	// 订单不存在时同样返回403，不暴露其他用户的订单是否存在
+	w = getOrderStatus(r, "200", "100-3-1")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
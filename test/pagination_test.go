@@ -0,0 +1,115 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewPaginated_Metadata 测试分页元数据在首页、末页、整页、越界页和空结果时的计算
+func TestNewPaginated_Metadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		page, size int
+		total      int64
+		totalPages int
+		hasNext    bool
+	}{
+		{"first page", 1, 10, 25, 3, true},
+		{"middle page", 2, 10, 25, 3, true},
+		{"last partial page", 3, 10, 25, 3, false},
+		{"last full page", 2, 10, 20, 2, false},
+		{"beyond last page", 5, 10, 20, 2, false},
+		{"empty result", 1, 10, 0, 0, false},
+		{"single item", 1, 10, 1, 1, false},
+		{"page below one", 0, 10, 25, 3, true},
+		{"zero size", 1, 0, 25, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := model.NewPaginated[int](nil, tt.page, tt.size, tt.total)
+			assert.Equal(t, tt.totalPages, p.TotalPages)
+			assert.Equal(t, tt.hasNext, p.HasNext)
+			assert.Equal(t, max(tt.page, 1), p.Page)
+			assert.NotNil(t, p.Items)
+		})
+	}
+}
+
+// TestPaginate_InMemory 测试内存列表分页在末页和越界页时的切片
+func TestPaginate_InMemory(t *testing.T) {
+	all := []int{1, 2, 3, 4, 5}
+
+	p := model.Paginate(all, 1, 2)
+	assert.Equal(t, []int{1, 2}, p.Items)
+	assert.True(t, p.HasNext)
+
+	p = model.Paginate(all, 3, 2)
+	assert.Equal(t, []int{5}, p.Items)
+	assert.Equal(t, int64(5), p.Total)
+	assert.Equal(t, 3, p.TotalPages)
+	assert.False(t, p.HasNext)
+
+	p = model.Paginate(all, 4, 2)
+	assert.Empty(t, p.Items)
+	assert.NotNil(t, p.Items)
+
+	p = model.Paginate([]int{}, 1, 2)
+	assert.Empty(t, p.Items)
+	assert.Equal(t, 0, p.TotalPages)
+	assert.False(t, p.HasNext)
+}
+
+// TestSearchGoods_PaginatedAPI 测试商品搜索接口返回统一的分页元数据并兼容limit参数
+func TestSearchGoods_PaginatedAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	titles := make(map[int64]string)
+	for i := int64(1); i <= 5; i++ {
+		titles[i] = "Science Book"
+	}
+	titles[6] = "Art Book"
+	seedSearchGoods(t, titles)
+	goodController := &controller.GoodController{GoodService: &service.GoodService{GoodDB: repository.NewGoodRepository()}}
+	r := router.NewRouter(goodController, noopAuth, false)
+
+	search := func(query string) (int, model.Paginated[model.Goods]) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/api/goods/search?"+query, nil))
+		var resp struct {
+			Data model.Paginated[model.Goods] `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Data
+	}
+
+	code, data := search("q=Science&page=3&size=2")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, data.Items, 1)
+	assert.Equal(t, int64(5), data.Items[0].GoodsId)
+	assert.Equal(t, int64(5), data.Total)
+	assert.Equal(t, 3, data.TotalPages)
+	assert.False(t, data.HasNext)
+
+	code, data = search("q=Science&limit=4")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 4, data.Size)
+	assert.True(t, data.HasNext)
+
+	code, data = search("q=Nothing")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, data.Items)
+	assert.Equal(t, repository.DefaultSearchLimit, data.Size)
+	assert.Equal(t, 0, data.TotalPages)
+
+	code, _ = search("q=Science&size=-1")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"seckill_system/model"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newPaymentGraceService 创建带支付失败宽限期的商品服务，并写入用户1-4在商品1的未支付订单
+func newPaymentGraceService(t *testing.T, grace time.Duration) *service.GoodService {
+	var orders []model.SuccessKilled
+	for userId := int64(1); userId <= 4; userId++ {
+		orders = append(orders, model.SuccessKilled{GoodsId: 1, UserId: userId, State: model.OrderStateUnpaid})
+	}
+	gs := SetupTestService(t, WithOrders(orders...)).Service
+	gs.PaymentGrace = grace
+	return gs
+}
+
+// TestPaymentGrace_SuccessWithinWindow 测试宽限期内收到支付成功消息时订单不被取消
+func TestPaymentGrace_SuccessWithinWindow(t *testing.T) {
+	gs := newPaymentGraceService(t, time.Minute)
+
+	assert.NoError(t, gs.HandlePaymentResult("1-1-1", model.OrderStatusPaymentFailed))
+	pending, err := gs.RedisRepo.IsPaymentPendingRetry("1-1-1")
+	assert.NoError(t, err)
+	assert.True(t, pending)
+
+	assert.NoError(t, gs.HandlePaymentResult("1-1-1", model.OrderStatusPaid))
+	pending, err = gs.RedisRepo.IsPaymentPendingRetry("1-1-1")
+	assert.NoError(t, err)
+	assert.False(t, pending)
+
+	cancelled, err := gs.SweepPaymentRetries(time.Now().Add(2 * time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, cancelled)
+}
+
+// TestPaymentGrace_CancelAfterWindow 测试宽限期结束前不取消，结束后只取消一次
+func TestPaymentGrace_CancelAfterWindow(t *testing.T) {
+	gs := newPaymentGraceService(t, time.Minute)
+
+	assert.NoError(t, gs.HandlePaymentResult("2-1-1", model.OrderStatusPaymentFailed))
+	assert.NoError(t, gs.HandlePaymentResult("3-1-1", model.OrderStatusPaymentFailed))
+	assert.NoError(t, gs.HandlePaymentResult("3-1-1", model.OrderStatusPaid))
+
+	cancelled, err := gs.SweepPaymentRetries(time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, cancelled, "orders should not be cancelled within the grace window")
+
+	cancelled, err = gs.SweepPaymentRetries(time.Now().Add(time.Minute + time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2-1-1"}, cancelled)
+
+	cancelled, err = gs.SweepPaymentRetries(time.Now().Add(2 * time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, cancelled, "cancelled orders should not be claimed twice")
+}
+
+// TestPaymentGrace_Disabled 测试未配置宽限期时支付失败立即取消而不进入等待状态
+func TestPaymentGrace_Disabled(t *testing.T) {
+	gs := newPaymentGraceService(t, 0)
+
+	assert.NoError(t, gs.HandlePaymentResult("4-1-1", model.OrderStatusPaymentFailed))
+	pending, err := gs.RedisRepo.IsPaymentPendingRetry("4-1-1")
+	assert.NoError(t, err)
+	assert.False(t, pending)
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"fmt"
+	"seckill_system/global"
+	"seckill_system/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// findCheck 按名称查找检查项结果
+func findCheck(t *testing.T, report model.SeckillEligibility, name string) model.EligibilityCheck {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("check %s not found in report", name)
+	return model.EligibilityCheck{}
+}
+
+// assertOnlyFailed 断言只有指定检查项未通过
+func assertOnlyFailed(t *testing.T, report model.SeckillEligibility, name, reason string) {
+	assert.False(t, report.Eligible)
+	for _, check := range report.Checks {
+		if check.Name == name {
+			assert.False(t, check.Passed)
+			assert.Contains(t, check.Reason, reason)
+		} else {
+			assert.True(t, check.Passed, "check %s should pass", check.Name)
+		}
+	}
+}
+
+// TestPrecheckSeckill_AllPassed 测试满足全部条件时报告可秒杀
+func TestPrecheckSeckill_AllPassed(t *testing.T) {
+	f := SetupTestService(t, WithSeckillEnabled(3))
+
+	report := f.Service.PrecheckSeckill(100, 1)
+
+	assert.True(t, report.Eligible)
+	assert.Len(t, report.Checks, 6)
+	for _, check := range report.Checks {
+		assert.True(t, check.Passed, "check %s should pass", check.Name)
+		assert.Empty(t, check.Reason)
+	}
+}
+
+// TestPrecheckSeckill_Disabled 测试秒杀关闭时报告原因
+func TestPrecheckSeckill_Disabled(t *testing.T) {
+	f := SetupTestService(t, WithSeckillEnabled(3))
+	f.Etcd.Data[global.EtcdKeySeckillEnabled] = "false"
+
+	report := f.Service.PrecheckSeckill(100, 1)
+	assertOnlyFailed(t, report, model.CheckSeckillEnabled, "disabled")
+}
+
+// TestPrecheckSeckill_Blacklisted 测试黑名单用户报告原因
+func TestPrecheckSeckill_Blacklisted(t *testing.T) {
+	f := SetupTestService(t, WithSeckillEnabled(3))
+	f.Etcd.Data[fmt.Sprintf("%s%d", global.EtcdKeyBlacklist, 100)] = `{"user_id":100}`
+
+	report := f.Service.PrecheckSeckill(100, 1)
+	assertOnlyFailed(t, report, model.CheckNotBlacklisted, "blacklist")
+}
+
+// TestPrecheckSeckill_GoodsNotFound 测试商品不存在时报告原因
+func TestPrecheckSeckill_GoodsNotFound(t *testing.T) {
+	f := SetupTestService(t, WithSeckillEnabled(3))
+	assert.NoError(t, f.Service.RedisRepo.SetGoodsStock(2, 10))
+
+	report := f.Service.PrecheckSeckill(100, 2)
+
+	assert.False(t, report.Eligible)
+	assert.False(t, findCheck(t, report, model.CheckGoodsExists).Passed)
+	assert.False(t, findCheck(t, report, model.CheckInWindow).Passed) // 无商品也无促销活动
+	assert.True(t, findCheck(t, report, model.CheckStockAvailable).Passed)
+}
+
+// TestPrecheckSeckill_NotInWindow 测试不在活动时间内报告原因
+func TestPrecheckSeckill_NotInWindow(t *testing.T) {
+	f := SetupTestService(t, WithSeckillEnabled(3))
+	assert.NoError(t, global.DBClient.Model(&model.PromotionSecKill{}).
+		Where("goods_id = ?", 1).
+		Update("start_time", time.Now().Add(time.Hour)).Error)
+
+	report := f.Service.PrecheckSeckill(100, 1)
+	assertOnlyFailed(t, report, model.CheckInWindow, "not available")
+}
+
+// TestPrecheckSeckill_SoldOut 测试库存为0时报告原因
+func TestPrecheckSeckill_SoldOut(t *testing.T) {
+	f := SetupTestService(t, WithSeckillEnabled(3))
+	assert.NoError(t, f.Service.RedisRepo.SetGoodsStock(1, 0))
+
+	report := f.Service.PrecheckSeckill(100, 1)
+	assertOnlyFailed(t, report, model.CheckStockAvailable, "sold out")
+}
+
+// TestPrecheckSeckill_RateLimited 测试触发限流时报告原因
+func TestPrecheckSeckill_RateLimited(t *testing.T) {
+	f := SetupTestService(t, WithSeckillEnabled(3))
+	assert.NoError(t, f.Redis.Set("user_rate_limit:100", "3"))
+
+	report := f.Service.PrecheckSeckill(100, 1)
+	assertOnlyFailed(t, report, model.CheckNotRateLimited, "too many requests")
+}
+
+// TestPrecheckSeckill_DoesNotConsumeRateLimit 测试预检不消耗限流次数
+func TestPrecheckSeckill_DoesNotConsumeRateLimit(t *testing.T) {
+	f := SetupTestService(t, WithSeckillEnabled(3))
+
+	for i := 0; i < 5; i++ {
+		assert.True(t, f.Service.PrecheckSeckill(100, 1).Eligible)
+	}
+	assert.False(t, f.Redis.Exists("user_rate_limit:100"))
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/config"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetPromotionsByGoodsIds 测试一次查询多个商品的促销信息，缺失的商品不出现在结果中
+func TestGetPromotionsByGoodsIds(t *testing.T) {
+	db := SetupTestDB(t)
+	for goodsId, stock := range map[int64]int64{1: 50, 2: 20} {
+		promotion := CreateTestPromotion(goodsId, stock)
+		assert.NoError(t, db.Create(&promotion).Error)
+	}
+
+	promotions, err := repository.NewGoodRepository().GetPromotionsByGoodsIds([]int64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Len(t, promotions, 2)
+	assert.Equal(t, int64(50), promotions[1].PsCount)
+	assert.Equal(t, int64(20), promotions[2].PsCount)
+
+	empty, err := repository.NewGoodRepository().GetPromotionsByGoodsIds(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+// TestPreloadGoodsStockBatch_PartialFailure 测试批量预加载共用一把锁写入全部库存，缺少促销信息的商品单独失败
+func TestPreloadGoodsStockBatch_PartialFailure(t *testing.T) {
+	f := setupPreload(t)
+	promotion := CreateTestPromotion(2, 20)
+	assert.NoError(t, f.DB.Create(&promotion).Error)
+
+	results, err := f.Service.PreloadGoodsStockBatch([]int64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/seckill/locks/preload_batch_lock"}, f.locker.acquired)
+	assert.Equal(t, model.PreloadResult{Success: true, Stock: 50}, results[1])
+	assert.Equal(t, model.PreloadResult{Success: true, Stock: 20}, results[2])
+	assert.False(t, results[3].Success)
+	assert.Contains(t, results[3].Error, "promotion not found")
+
+	stocks, err := f.Service.RedisRepo.GetGoodsStockBatch([]int64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int64{1: 50, 2: 20}, stocks)
+}
+
+// TestPreloadGoodsStockBatch_Controller 测试批量预加载接口的请求体校验和逐个商品结果
+func TestPreloadGoodsStockBatch_Controller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	f := setupPreload(t)
+	goodController := &controller.GoodController{
+		GoodService:  f.Service,
+		GoodsIdRange: config.GoodsIdRange{Min: 1, Max: 1000},
+	}
+	r := router.NewRouter(goodController, noopAuth, true)
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/admin/preload/batch?admin=1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, body := range []string{"", "{}", "[]", `["1"]`, "[1, 1001]"} {
+		assert.Equal(t, http.StatusBadRequest, post(body).Code, body)
+	}
+	assert.Empty(t, f.locker.acquired)
+
+	w := post("[1, 1, 2]")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data struct {
+			Results map[string]model.PreloadResult `json:"results"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Data.Results, 2)
+	assert.Equal(t, model.PreloadResult{Success: true, Stock: 50}, resp.Data.Results["1"])
+	assert.False(t, resp.Data.Results["2"].Success)
+
+	// 单个商品的预加载接口不受批量路由影响
+	assert.Equal(t, http.StatusOK, serve(r, "POST", "/api/admin/preload/1?admin=1&force=true"))
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"seckill_system/config"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// preloadFixture 预加载幂等测试环境
+type preloadFixture struct {
+	*ServiceFixture
+	locker *recordingLocker // 记录预加载加锁次数
+}
+
+// setupPreload 准备库存为50的促销活动，预加载锁由recordingLocker记录
+func setupPreload(t *testing.T) *preloadFixture {
+	f := SetupTestService(t, WithGoods(50, 1), WithoutRedisStock())
+	locker := &recordingLocker{}
+	factory, err := service.NewLockFactory(config.LockConfig{Preload: config.LockBackendRedis}, &recordingLocker{}, locker)
+	assert.NoError(t, err)
+	f.Service.Locks = factory
+	return &preloadFixture{ServiceFixture: f, locker: locker}
+}
+
+// TestPreloadGoodsStock_UnchangedIsNoop 测试库存未变化时重复预加载不加锁也不写入
+func TestPreloadGoodsStock_UnchangedIsNoop(t *testing.T) {
+	f := setupPreload(t)
+
+	updated, err := f.Service.PreloadGoodsStock(1, false)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	assert.Len(t, f.locker.acquired, 1)
+
+	updated, err = f.Service.PreloadGoodsStock(1, false)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+	assert.Len(t, f.locker.acquired, 1, "unchanged preload should not acquire the lock")
+}
+
+// TestPreloadGoodsStock_ChangedWrites 测试促销库存或Redis库存变化时重新写入
+func TestPreloadGoodsStock_ChangedWrites(t *testing.T) {
+	f := setupPreload(t)
+	_, err := f.Service.PreloadGoodsStock(1, false)
+	assert.NoError(t, err)
+
+	// 促销库存变化
+	assert.NoError(t, f.DB.Model(&model.PromotionSecKill{}).Where("goods_id = ?", 1).Update("ps_count", 80).Error)
+	updated, err := f.Service.PreloadGoodsStock(1, false)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	f.Redis.CheckGet(t, repository.StockKey(1), "80")
+
+	// Redis库存与促销库存不一致
+	f.Redis.Set(repository.StockKey(1), "3")
+	updated, err = f.Service.PreloadGoodsStock(1, false)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	f.Redis.CheckGet(t, repository.StockKey(1), "80")
+}
+
+// TestPreloadGoodsStock_Force 测试强制预加载在库存未变化时仍然写入
+func TestPreloadGoodsStock_Force(t *testing.T) {
+	f := setupPreload(t)
+	_, err := f.Service.PreloadGoodsStock(1, false)
+	assert.NoError(t, err)
+
+	updated, err := f.Service.PreloadGoodsStock(1, true)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	assert.Len(t, f.locker.acquired, 2)
+}
+
+// TestPreloadGoodsStock_ZeroStockNotLoaded 测试促销库存为0且Redis中无库存键时仍然写入
+func TestPreloadGoodsStock_ZeroStockNotLoaded(t *testing.T) {
+	f := setupPreload(t)
+	assert.NoError(t, f.DB.Model(&model.PromotionSecKill{}).Where("goods_id = ?", 1).Update("ps_count", 0).Error)
+
+	updated, err := f.Service.PreloadGoodsStock(1, false)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	f.Redis.CheckGet(t, repository.StockKey(1), "0")
+}
@@ -0,0 +1,123 @@
+package test
+
+import (
+	"context"
+	"seckill_system/handler"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPromotionCache_HitAndMiss 测试未命中时读取数据库并写入缓存，命中时不访问数据库
+func TestPromotionCache_HitAndMiss(t *testing.T) {
+	db := SetupTestDB(t)
+	mr := SetupTestRedis(t)
+	promotion := CreateTestPromotion(1, 10)
+	assert.NoError(t, db.Create(&promotion).Error)
+	cache := repository.NewPromotionCache(repository.NewGoodRepository(), repository.NewRedisRepository())
+
+	got, fromCache, err := cache.Get(1)
+	assert.NoError(t, err)
+	assert.False(t, fromCache)
+	assert.Equal(t, int64(10), got.PsCount)
+	assert.True(t, mr.Exists(repository.PromotionCacheKey(1)))
+	assert.Equal(t, repository.PromotionCacheTTL, mr.TTL(repository.PromotionCacheKey(1)))
+
+	// 绕过缓存直接修改数据库，命中缓存时仍返回缓存中的数据
+	assert.NoError(t, db.Model(&model.PromotionSecKill{}).Where("goods_id = ?", 1).Update("current_price", 99).Error)
+	got, fromCache, err = cache.Get(1)
+	assert.NoError(t, err)
+	assert.True(t, fromCache)
+	assert.Equal(t, float64(50), got.CurrentPrice)
+
+	// 失效后重新读取数据库
+	cache.Invalidate(1)
+	got, fromCache, err = cache.Get(1)
+	assert.NoError(t, err)
+	assert.False(t, fromCache)
+	assert.Equal(t, float64(99), got.CurrentPrice)
+
+	// 促销不存在时不写入缓存
+	_, _, err = cache.Get(2)
+	assert.ErrorIs(t, err, repository.ErrPromotionNotFound)
+	assert.False(t, mr.Exists(repository.PromotionCacheKey(2)))
+}
+
+// TestPromotionCache_InvalidatedOnVersionChange 测试下单扣减库存使版本号变更后缓存失效
+func TestPromotionCache_InvalidatedOnVersionChange(t *testing.T) {
+	db := SetupTestDB(t)
+	mr := SetupTestRedis(t)
+	SetupTestKafka(t)
+	promotion := CreateTestPromotion(1, 10)
+	assert.NoError(t, db.Create(&promotion).Error)
+	redisRepo := repository.NewRedisRepository()
+	assert.NoError(t, redisRepo.SetGoodsStock(1, 10))
+	h := handler.NewSeckillHandler()
+	cache := repository.NewPromotionCache(repository.NewGoodRepository(), redisRepo)
+
+	before, _, err := cache.Get(1)
+	assert.NoError(t, err)
+
+	_, err = h.CreateOrder(context.Background(), 1, 1, 1)
+	assert.NoError(t, err)
+	assert.False(t, mr.Exists(repository.PromotionCacheKey(1)))
+
+	after, fromCache, err := cache.Get(1)
+	assert.NoError(t, err)
+	assert.False(t, fromCache)
+	assert.Equal(t, before.Version+1, after.Version)
+	assert.Equal(t, int64(9), after.PsCount)
+}
+
+// TestPromotionCache_StaleVersionRetried 测试缓存版本号落后于数据库时下单仍能成功
+func TestPromotionCache_StaleVersionRetried(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	promotion := CreateTestPromotion(1, 10)
+	assert.NoError(t, db.Create(&promotion).Error)
+	redisRepo := repository.NewRedisRepository()
+	assert.NoError(t, redisRepo.SetGoodsStock(1, 10))
+	h := handler.NewSeckillHandler()
+
+	// 写入缓存后由其他实例更新了版本号（本实例的缓存失效未送达）
+	cache := repository.NewPromotionCache(repository.NewGoodRepository(), redisRepo)
+	_, _, err := cache.Get(1)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Model(&model.PromotionSecKill{}).Where("goods_id = ?", 1).Update("version", 7).Error)
+
+	_, err = h.CreateOrder(context.Background(), 1, 1, 1)
+	assert.NoError(t, err)
+
+	var stored model.PromotionSecKill
+	assert.NoError(t, db.Where("goods_id = ?", 1).First(&stored).Error)
+	assert.Equal(t, int64(8), stored.Version)
+	assert.Equal(t, int64(9), stored.PsCount)
+}
+
+// TestPromotionCache_InvalidatedOnReset 测试管理员重置后缓存失效
+func TestPromotionCache_InvalidatedOnReset(t *testing.T) {
+	db := SetupTestDB(t)
+	mr := SetupTestRedis(t)
+	good := CreateTestGoods(1)
+	assert.NoError(t, db.Create(&good).Error)
+	promotion := CreateTestPromotion(1, 3)
+	assert.NoError(t, db.Create(&promotion).Error)
+	goodDB := repository.NewGoodRepository()
+	redisRepo := repository.NewRedisRepository()
+	gs := &service.GoodService{
+		GoodDB:     goodDB,
+		RedisRepo:  redisRepo,
+		Promotions: repository.NewPromotionCache(goodDB, redisRepo),
+	}
+
+	_, err := gs.GetPromotionByGoodsId(1)
+	assert.NoError(t, err)
+	assert.True(t, mr.Exists(repository.PromotionCacheKey(1)))
+
+	assert.NoError(t, gs.ResetDataBase(1))
+	assert.False(t, mr.Exists(repository.PromotionCacheKey(1)))
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createScheduledPromotions 为商品1创建已结束、进行中和未开始的三场活动（乱序插入），另为商品2创建一场活动
+func createScheduledPromotions(t *testing.T) {
+	t.Helper()
+	db := SetupTestDB(t)
+	now := time.Now()
+	promotions := []model.PromotionSecKill{
+		{PsId: 3, GoodsId: 1, PsCount: 30, StartTime: model.NewTimestamp(now.Add(24 * time.Hour)), EndTime: model.NewTimestamp(now.Add(25 * time.Hour)), CurrentPrice: 30},
+		{PsId: 1, GoodsId: 1, PsCount: 10, StartTime: model.NewTimestamp(now.Add(-48 * time.Hour)), EndTime: model.NewTimestamp(now.Add(-47 * time.Hour)), CurrentPrice: 10},
+		{PsId: 2, GoodsId: 1, PsCount: 20, StartTime: model.NewTimestamp(now.Add(-time.Hour)), EndTime: model.NewTimestamp(now.Add(time.Hour)), CurrentPrice: 20},
+		{PsId: 4, GoodsId: 2, PsCount: 40, StartTime: model.NewTimestamp(now.Add(-time.Hour)), EndTime: model.NewTimestamp(now.Add(time.Hour)), CurrentPrice: 40},
+	}
+	require.NoError(t, db.Create(&promotions).Error)
+}
+
+// TestGetPromotionSchedule_Repository 测试按开始时间返回商品的全部活动，不包含其他商品的活动
+func TestGetPromotionSchedule_Repository(t *testing.T) {
+	createScheduledPromotions(t)
+	repo := repository.NewGoodRepository()
+
+	promotions, err := repo.GetPromotionSchedule(1)
+	require.NoError(t, err)
+	require.Len(t, promotions, 3)
+	assert.Equal(t, []int64{1, 2, 3}, []int64{promotions[0].PsId, promotions[1].PsId, promotions[2].PsId})
+
+	promotions, err = repo.GetPromotionSchedule(99)
+	require.NoError(t, err)
+	assert.Empty(t, promotions)
+}
+
+// TestGetPromotionSchedule_Endpoint 测试活动排期接口按开始时间排序并按当前时间标注活动阶段
+func TestGetPromotionSchedule_Endpoint(t *testing.T) {
+	r, gs, _ := setupIPRateLimitRouter(t)
+	createScheduledPromotions(t)
+	gs.GoodDB = repository.NewGoodRepository()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/goods/1/schedule", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data struct {
+			GoodsId    int64                      `json:"goods_id"`
+			Promotions []model.ScheduledPromotion `json:"promotions"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(1), resp.Data.GoodsId)
+	require.Len(t, resp.Data.Promotions, 3)
+	phases := make([]string, 0, 3)
+	for _, promotion := range resp.Data.Promotions {
+		phases = append(phases, promotion.Phase)
+	}
+	assert.Equal(t, []string{model.ActivityPhaseEnded, model.ActivityPhaseActive, model.ActivityPhaseUpcoming}, phases)
+	assert.Equal(t, int64(20), resp.Data.Promotions[1].PsCount)
+
+	// 没有活动的商品返回空列表
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/goods/99/schedule", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"promotions":[]`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/goods/abc/schedule", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"context"
+	"seckill_system/errs"
+	"seckill_system/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestPurchaseLimit_AllowsUpToLimit 测试用户在限购数量内可多次下单，达到上限后拒绝并恢复库存
+func TestPurchaseLimit_AllowsUpToLimit(t *testing.T) {
+	f := SetupTestService(t, WithPurchaseLimit(2), WithSeckillHandler())
+	gs, db := f.Service, f.DB
+
+	first, err := gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 1)
+	require.NoError(t, err)
+	second, err := gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 1)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	_, err = gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 1)
+	assert.ErrorIs(t, err, errs.ErrPurchaseLimitReached)
+	assert.ErrorIs(t, err, errs.ErrAlreadyPurchased)
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), stock)
+	promotion, err := gs.GoodDB.GetPromotionByGoodsId(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), promotion.PsCount)
+
+	var orders []model.SuccessKilled
+	require.NoError(t, db.Where("goods_id = ? AND user_id = ?", 1, 100).Order("id").Find(&orders).Error)
+	require.Len(t, orders, 2)
+	assert.Equal(t, []string{first, second}, []string{orders[0].OrderId, orders[1].OrderId})
+}
+
+// TestPurchaseLimit_DefaultsToOne 测试未配置限购数量时每人限购一件
+func TestPurchaseLimit_DefaultsToOne(t *testing.T) {
+	gs := SetupTestService(t, WithPurchaseLimit(0), WithSeckillHandler()).Service
+
+	_, err := gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 1)
+	require.NoError(t, err)
+	_, err = gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 1)
+	assert.ErrorIs(t, err, errs.ErrPurchaseLimitReached)
+
+	info, err := gs.GetSeckillInfo(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), info.PurchaseLimit)
+}
+
+// TestPurchaseLimit_OrdersTrackedSeparately 测试同一用户的多个订单按订单ID分别支付和取消，已取消的订单不计入限购
+func TestPurchaseLimit_OrdersTrackedSeparately(t *testing.T) {
+	gs := SetupTestService(t, WithPurchaseLimit(2), WithSeckillHandler()).Service
+
+	first, err := gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 1)
+	require.NoError(t, err)
+	second, err := gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 1)
+	require.NoError(t, err)
+
+	// 只有第一个订单被标记为已支付
+	require.NoError(t, gs.GoodDB.WithTransaction(func(tx *gorm.DB) error {
+		_, err := gs.GoodDB.TransitionOrderState(tx, first, 100, 1, model.OrderStatePaid)
+		return err
+	}))
+	status, err := gs.GetOrderStatus(first)
+	require.NoError(t, err)
+	assert.Equal(t, "paid", status.Status)
+	status, err = gs.GetOrderStatus(second)
+	require.NoError(t, err)
+	assert.Equal(t, "unpaid", status.Status)
+
+	// 取消未支付的第二个订单后可以再购买一件
+	cancelled, err := gs.CancelUnpaidOrders(100)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cancelled)
+	status, err = gs.GetOrderStatus(first)
+	require.NoError(t, err)
+	assert.Equal(t, "paid", status.Status)
+
+	_, err = gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 1)
+	assert.NoError(t, err)
+	_, err = gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 1)
+	assert.ErrorIs(t, err, errs.ErrPurchaseLimitReached)
+}
+
+// TestPurchaseLimit_QuantityPersisted 测试多件订单记录购买件数，限购按件数统计，取消时按件数归还数据库和Redis库存
+func TestPurchaseLimit_QuantityPersisted(t *testing.T) {
+	f := SetupTestService(t, WithPurchaseLimit(3), WithSeckillHandler())
+	gs, db := f.Service, f.DB
+
+	orderId, err := gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 2)
+	require.NoError(t, err)
+	var order model.SuccessKilled
+	require.NoError(t, db.Where("order_id = ?", orderId).First(&order).Error)
+	assert.Equal(t, int64(2), order.Quantity)
+
+	// 已购2件，再买2件超过限购3件
+	_, err = gs.SeckillHandler.CreateOrder(context.Background(), 100, 1, 2)
+	assert.ErrorIs(t, err, errs.ErrPurchaseLimitReached)
+	sold, err := gs.GoodDB.CountSoldByGoodsIds([]int64{1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), sold[1])
+
+	cancelled, err := gs.CancelUnpaidOrders(100)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cancelled)
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), stock)
+	promotion, err := gs.GoodDB.GetPromotionByGoodsId(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), promotion.PsCount)
+}
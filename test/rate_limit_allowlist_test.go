@@ -0,0 +1,122 @@
+package test
+
+import (
+	"context"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupAllowlistService 创建限流为2次/分钟且带豁免名单的商品服务
+func setupAllowlistService(t *testing.T, allowlist model.RateLimitAllowlist) (*service.GoodService, *MockEtcdKV) {
+	SetupTestRedis(t)
+	kv := SetupTestEtcd(t)
+	kv.Data[global.EtcdKeyRateLimit] = "2"
+
+	gs := &service.GoodService{
+		RedisRepo: repository.NewRedisRepository(),
+		EtcdRepo:  repository.NewETCDRepository(),
+		Allowlist: service.NewRateLimitAllowlist(allowlist),
+	}
+	return gs, kv
+}
+
+// TestCheckUserRateLimit_AllowlistedUser 测试豁免用户不受限流而其他用户受限
+func TestCheckUserRateLimit_AllowlistedUser(t *testing.T) {
+	gs, _ := setupAllowlistService(t, model.RateLimitAllowlist{UserIds: []int64{1}})
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, gs.CheckUserRateLimit(1, "203.0.113.7"))
+	}
+
+	assert.NoError(t, gs.CheckUserRateLimit(2, "203.0.113.7"))
+	assert.NoError(t, gs.CheckUserRateLimit(2, "203.0.113.7"))
+	assert.EqualError(t, gs.CheckUserRateLimit(2, "203.0.113.7"), "too many requests")
+
+	// 豁免用户的请求不计入限流次数
+	count, err := gs.RedisRepo.GetUserRateCount(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+// TestCheckUserRateLimit_AllowlistedIP 测试豁免IP及网段内的请求不受限流
+func TestCheckUserRateLimit_AllowlistedIP(t *testing.T) {
+	gs, _ := setupAllowlistService(t, model.RateLimitAllowlist{IPs: []string{"192.0.2.10", "10.0.0.0/8"}})
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, gs.CheckUserRateLimit(3, "192.0.2.10"))
+		assert.NoError(t, gs.CheckUserRateLimit(4, "10.1.2.3"))
+	}
+
+	assert.NoError(t, gs.CheckUserRateLimit(3, "192.0.2.11"))
+	assert.NoError(t, gs.CheckUserRateLimit(3, "192.0.2.11"))
+	assert.EqualError(t, gs.CheckUserRateLimit(3, "192.0.2.11"), "too many requests")
+}
+
+// TestRateLimitAllowlist_InvalidEntries 测试无效的IP条目被忽略
+func TestRateLimitAllowlist_InvalidEntries(t *testing.T) {
+	allowlist := service.NewRateLimitAllowlist(model.RateLimitAllowlist{
+		IPs: []string{"not-an-ip", "10.0.0.0/33", " 192.0.2.1 "},
+	})
+
+	assert.True(t, allowlist.AllowsIP("192.0.2.1"))
+	assert.False(t, allowlist.AllowsIP("not-an-ip"))
+	assert.False(t, allowlist.AllowsIP(""))
+	assert.False(t, allowlist.Allows(0, "10.0.0.1"))
+}
+
+// TestRateLimitAllowlist_EtcdUpdate 测试Etcd名单覆盖配置名单，删除后恢复配置名单
+func TestRateLimitAllowlist_EtcdUpdate(t *testing.T) {
+	gs, _ := setupAllowlistService(t, model.RateLimitAllowlist{UserIds: []int64{1}})
+
+	assert.NoError(t, gs.EtcdRepo.SetRateLimitAllowlist(context.Background(), &model.RateLimitAllowlist{
+		UserIds: []int64{5},
+		IPs:     []string{"198.51.100.0/24"},
+	}))
+	loaded, err := gs.EtcdRepo.GetRateLimitAllowlist(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5}, loaded.UserIds)
+
+	gs.Allowlist.Update(loaded)
+	assert.True(t, gs.Allowlist.Allows(5, ""))
+	assert.True(t, gs.Allowlist.AllowsIP("198.51.100.20"))
+	assert.False(t, gs.Allowlist.Allows(1, ""))
+
+	// 名单被删除时恢复为配置文件中的名单
+	gs.Allowlist.Update(nil)
+	assert.True(t, gs.Allowlist.Allows(1, ""))
+	assert.False(t, gs.Allowlist.Allows(5, ""))
+}
+
+// TestGetRateLimitAllowlist_Missing 测试Etcd未配置或内容无效时的返回
+func TestGetRateLimitAllowlist_Missing(t *testing.T) {
+	_, kv := setupAllowlistService(t, model.RateLimitAllowlist{})
+	etcdRepo := repository.NewETCDRepository()
+
+	allowlist, err := etcdRepo.GetRateLimitAllowlist(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, allowlist)
+
+	kv.Data[global.EtcdKeyRateLimitAllowlist] = "{invalid"
+	_, err = etcdRepo.GetRateLimitAllowlist(context.Background())
+	assert.Error(t, err)
+}
+
+// TestPrecheckSeckill_AllowlistedUser 测试豁免用户的资格预检不受限流影响
+func TestPrecheckSeckill_AllowlistedUser(t *testing.T) {
+	f := SetupTestService(t, WithSeckillEnabled(3))
+	f.Service.Allowlist = service.NewRateLimitAllowlist(model.RateLimitAllowlist{UserIds: []int64{100}})
+	for i := 0; i < 3; i++ {
+		_, err := f.Service.RedisRepo.UserRateLimit(100, 3, time.Minute)
+		assert.NoError(t, err)
+	}
+
+	report := f.Service.PrecheckSeckill(100, 1)
+	assert.True(t, findCheck(t, report, model.CheckNotRateLimited).Passed)
+	assert.True(t, report.Eligible)
+}
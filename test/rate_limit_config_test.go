@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"seckill_system/config"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetRateLimitConfig_ClampsInvalidValues 测试ETCD中的非正数限流值被修正为最小值
+func TestGetRateLimitConfig_ClampsInvalidValues(t *testing.T) {
+	kv := SetupTestEtcd(t)
+	etcdRepo := repository.NewETCDRepository()
+
+	cases := map[string]int64{
+		"-5":  repository.MinRateLimit,
+		"0":   repository.MinRateLimit,
+		"1":   1,
+		"50":  50,
+		"abc": 10, // 无法解析时使用默认值
+	}
+	for stored, expected := range cases {
+		kv.Data[global.EtcdKeyRateLimit] = stored
+		limit, err := etcdRepo.GetRateLimitConfig(context.Background())
+		assert.NoError(t, err, stored)
+		assert.Equal(t, expected, limit, stored)
+	}
+}
+
+// TestCheckUserRateLimit_NegativeStoredLimit 测试ETCD中为负数限流值时用户仍可请求一次
+func TestCheckUserRateLimit_NegativeStoredLimit(t *testing.T) {
+	gs, kv := setupAllowlistService(t, model.RateLimitAllowlist{})
+	kv.Data[global.EtcdKeyRateLimit] = "-3"
+
+	assert.NoError(t, gs.CheckUserRateLimit(7, ""))
+	assert.EqualError(t, gs.CheckUserRateLimit(7, ""), "too many requests")
+}
+
+// setupFailingLimiterService 创建Redis限流不可用的商品服务
+func setupFailingLimiterService(t *testing.T, failOpen bool) *service.GoodService {
+	mr := SetupTestRedis(t)
+	kv := SetupTestEtcd(t)
+	kv.Data[global.EtcdKeyRateLimit] = "2"
+	gs := &service.GoodService{
+		RedisRepo:     repository.NewRedisRepository(),
+		EtcdRepo:      repository.NewETCDRepository(),
+		RateLimitOpen: failOpen,
+	}
+	mr.Close() // 模拟Redis宕机
+	return gs
+}
+
+// TestCheckUserRateLimit_FailOpen 测试配置为放行时限流后端故障不拒绝请求
+func TestCheckUserRateLimit_FailOpen(t *testing.T) {
+	gs := setupFailingLimiterService(t, true)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, gs.CheckUserRateLimit(7, ""))
+	}
+}
+
+// TestCheckUserRateLimit_FailClosed 测试配置为拒绝时限流后端故障返回系统繁忙
+func TestCheckUserRateLimit_FailClosed(t *testing.T) {
+	gs := setupFailingLimiterService(t, false)
+
+	err := gs.CheckUserRateLimit(7, "")
+	assert.ErrorIs(t, err, errs.ErrRateLimiterUnavailable)
+	assert.ErrorIs(t, err, errs.ErrSystemBusy)
+	assert.NotErrorIs(t, err, errs.ErrRateLimited)
+}
+
+// TestRateLimitConfig_FailureMode 测试限流故障处理方式配置
+func TestRateLimitConfig_FailureMode(t *testing.T) {
+	assert.False(t, config.RateLimitConfig{}.FailOpen())
+	assert.NoError(t, config.RateLimitConfig{}.Validate())
+	assert.True(t, config.RateLimitConfig{FailureMode: config.RateLimitFailOpen}.FailOpen())
+	assert.False(t, config.RateLimitConfig{FailureMode: config.RateLimitFailClosed}.FailOpen())
+	assert.Error(t, config.RateLimitConfig{FailureMode: "maybe"}.Validate())
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/config"
+	"seckill_system/global"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"seckill_system/web/middleware"
+	"seckill_system/web/router"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// readOnlyEvent 构造只读模式开关的监听事件
+func readOnlyEvent(eventType mvccpb.Event_EventType, value string) clientv3.WatchResponse {
+	return clientv3.WatchResponse{Events: []*clientv3.Event{{
+		Type: eventType,
+		Kv:   &mvccpb.KeyValue{Key: []byte(global.EtcdKeyReadOnly), Value: []byte(value)},
+	}}}
+}
+
+// TestReadOnly_BlocksSeckillAllowsGoodsInfo 测试只读模式拒绝秒杀等写接口，商品信息等读接口正常返回
+func TestReadOnly_BlocksSeckillAllowsGoodsInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	kv := SetupTestEtcd(t)
+	good := CreateTestGoods(1)
+	assert.NoError(t, db.Create(&good).Error)
+
+	kv.Data[global.EtcdKeyReadOnly] = "true"
+	watcher := &fakeWatcher{events: make(chan clientv3.WatchResponse, 1)}
+	global.EtcdClient.Watcher = watcher
+	gs := newGoodsInfoService()
+	gs.EtcdRepo = repository.NewETCDRepository()
+	gs.StartReadOnlyWatcher()
+	assert.True(t, gs.ReadOnly())
+
+	r := router.NewRouter(&controller.GoodController{GoodService: gs}, noopAuth, false)
+	for _, path := range []string{"/api/seckill/token?gid=1", "/api/seckill?gid=1", "/api/payment/simulate", "/api/orders/cancel_all"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", path, nil))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, path)
+		assert.Equal(t, "60", w.Header().Get("Retry-After"), path)
+		assert.Contains(t, w.Body.String(), "read-only mode", path)
+	}
+	assert.Equal(t, http.StatusOK, serve(r, "GET", "/api/goods/1"))
+
+	// 删除开关后恢复写接口
+	watcher.events <- readOnlyEvent(mvccpb.DELETE, "")
+	assert.Eventually(t, func() bool { return !gs.ReadOnly() }, time.Second, 10*time.Millisecond)
+	assert.NotEqual(t, http.StatusServiceUnavailable, serve(r, "POST", "/api/seckill/token?gid=1"))
+}
+
+// TestReadOnly_WatcherToggles 测试只读模式开关随Etcd变更切换，未配置时为可写
+func TestReadOnly_WatcherToggles(t *testing.T) {
+	SetupTestEtcd(t)
+	watcher := &fakeWatcher{events: make(chan clientv3.WatchResponse, 2)}
+	global.EtcdClient.Watcher = watcher
+	gs := &service.GoodService{EtcdRepo: repository.NewETCDRepository()}
+	gs.StartReadOnlyWatcher()
+	assert.False(t, gs.ReadOnly())
+
+	watcher.events <- readOnlyEvent(mvccpb.PUT, "true")
+	assert.Eventually(t, gs.ReadOnly, time.Second, 10*time.Millisecond)
+	watcher.events <- readOnlyEvent(mvccpb.PUT, "false")
+	assert.Eventually(t, func() bool { return !gs.ReadOnly() }, time.Second, 10*time.Millisecond)
+}
+
+// staticReadOnly 固定返回只读状态的ReadOnlyChecker
+type staticReadOnly bool
+
+// ReadOnly 返回固定的只读状态
+func (s staticReadOnly) ReadOnly() bool {
+	return bool(s)
+}
+
+// TestReadOnlyMiddleware_ConfiguredResponse 测试只读模式响应使用配置的提示信息和Retry-After
+func TestReadOnlyMiddleware_ConfiguredResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	cfg := config.ReadOnlyConfig{Message: "Upgrading, back at 02:00", RetryAfterSeconds: 300}
+	r.Any("/write", middleware.NewReadOnlyMiddleware(staticReadOnly(true), cfg), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/write", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "300", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "Upgrading, back at 02:00")
+
+	for _, method := range []string{"GET", "HEAD", "OPTIONS"} {
+		assert.Equal(t, http.StatusOK, serve(r, method, "/write"), method)
+	}
+}
@@ -0,0 +1,43 @@
+package test
+
+import (
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestKeySlot_KnownValues 测试槽位计算与Redis集群CLUSTER KEYSLOT结果一致
+func TestKeySlot_KnownValues(t *testing.T) {
+	assert.Equal(t, 12739, repository.KeySlot("123456789"))                         // CRC16-XMODEM校验值0x31C3
+	assert.Equal(t, 12182, repository.KeySlot("foo"))                               // CLUSTER KEYSLOT foo
+	assert.Equal(t, repository.KeySlot("bar"), repository.KeySlot("{bar}x"))        // 只对哈希标签计算
+	assert.NotEqual(t, repository.KeySlot("bar"), repository.KeySlot("foo{}{bar}")) // 空标签不生效，按整个键计算
+}
+
+// TestRedisKeys_RelatedKeysShareSlot 测试同一商品的相关键位于同一槽位
+func TestRedisKeys_RelatedKeysShareSlot(t *testing.T) {
+	for _, goodsId := range []int64{1, 42, 1001, 999999} {
+		slot := repository.KeySlot(repository.StockKey(goodsId))
+		assert.Equal(t, slot, repository.KeySlot(repository.SeckillTokenKey(goodsId, "abcdef0123456789")))
+		assert.Equal(t, slot, repository.KeySlot(repository.GoodsInfoKey(goodsId)))
+	}
+}
+
+// TestValidateScriptKeys 测试Lua脚本键数量和槽位校验
+func TestValidateScriptKeys(t *testing.T) {
+	// 同一商品的键可以在同一脚本中使用
+	keys := []string{repository.StockKey(1), repository.SeckillTokenKey(1, "token")}
+	assert.NoError(t, repository.ValidateScriptKeys(keys, repository.DefaultMaxScriptKeys))
+
+	// 不同商品的键位于不同槽位
+	crossSlot := []string{repository.StockKey(1), repository.StockKey(2)}
+	assert.ErrorIs(t, repository.ValidateScriptKeys(crossSlot, repository.DefaultMaxScriptKeys), repository.ErrCrossSlotKeys)
+
+	// 键数量超过上限
+	assert.ErrorIs(t, repository.ValidateScriptKeys(keys, 1), repository.ErrTooManyScriptKeys)
+
+	// 空键列表和单键总是合法
+	assert.NoError(t, repository.ValidateScriptKeys(nil, repository.DefaultMaxScriptKeys))
+	assert.NoError(t, repository.ValidateScriptKeys(keys[:1], repository.DefaultMaxScriptKeys))
+}
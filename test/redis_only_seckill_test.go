@@ -0,0 +1,167 @@
+package test
+
+import (
+	"context"
+	"seckill_system/config"
+	"seckill_system/handler"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSeckillConfig_ModeFor 测试全局与按商品覆盖的下单模式
+func TestSeckillConfig_ModeFor(t *testing.T) {
+	assert.Equal(t, config.SeckillModeDB, config.SeckillConfig{}.ModeFor(1))
+	assert.False(t, config.SeckillConfig{}.UsesRedisMode())
+
+	sc := config.SeckillConfig{
+		Mode:       config.SeckillModeDB,
+		GoodsModes: map[int64]string{1001: config.SeckillModeRedis},
+	}
+	assert.NoError(t, sc.Validate())
+	assert.Equal(t, config.SeckillModeRedis, sc.ModeFor(1001))
+	assert.Equal(t, config.SeckillModeDB, sc.ModeFor(1002))
+	assert.True(t, sc.UsesRedisMode())
+
+	assert.Error(t, config.SeckillConfig{Mode: "memory"}.Validate())
+	assert.Error(t, config.SeckillConfig{GoodsModes: map[int64]string{1: ""}}.Validate())
+	assert.Error(t, config.SeckillConfig{FlushIntervalSeconds: -1}.Validate())
+}
+
+// TestSeckillHandler_CreateOrderRedisOnly_NoOversell 测试Redis-only模式并发下单不超卖且不访问数据库
+func TestSeckillHandler_CreateOrderRedisOnly_NoOversell(t *testing.T) {
+	db := SetupTestDB(t) // 不创建促销记录，db模式下单会因促销不存在而失败
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	h := handler.NewSeckillHandler()
+	redisRepo := repository.NewRedisRepository()
+	assert.NoError(t, redisRepo.SetGoodsStock(1, 5))
+
+	var wg sync.WaitGroup
+	var succeeded atomic.Int64
+	for userId := int64(1); userId <= 20; userId++ {
+		wg.Add(1)
+		go func(userId int64) {
+			defer wg.Done()
+			if _, err := h.CreateOrderRedisOnly(context.Background(), userId, 1, 1); err == nil {
+				succeeded.Add(1)
+			}
+		}(userId)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(5), succeeded.Load())
+	stock, err := redisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), stock)
+	pending, err := redisRepo.PendingOrderLen()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), pending)
+
+	// 下单过程未写数据库
+	var orders int64
+	assert.NoError(t, db.Model(&model.SuccessKilled{}).Count(&orders).Error)
+	assert.Equal(t, int64(0), orders)
+
+	// 同样条件下db模式需要数据库事务，促销不存在时失败并恢复Redis库存
+	assert.NoError(t, redisRepo.SetGoodsStock(2, 5))
+	_, err = h.CreateOrder(context.Background(), 1, 2, 1)
+	assert.Error(t, err)
+}
+
+// TestSeckillHandler_FlushPendingOrders 测试Redis-only模式订单异步写入数据库
+func TestSeckillHandler_FlushPendingOrders(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	h := handler.NewSeckillHandler()
+	redisRepo := repository.NewRedisRepository()
+	promotion := CreateTestPromotion(1, 5)
+	assert.NoError(t, db.Create(&promotion).Error)
+	assert.NoError(t, redisRepo.SetGoodsStock(1, 5))
+
+	for userId := int64(1); userId <= 3; userId++ {
+		_, err := h.CreateOrderRedisOnly(context.Background(), userId, 1, 1)
+		assert.NoError(t, err)
+	}
+
+	flushed, err := h.FlushPendingOrders(context.Background(), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, flushed)
+
+	var orders int64
+	assert.NoError(t, db.Model(&model.SuccessKilled{}).Where("goods_id = ?", 1).Count(&orders).Error)
+	assert.Equal(t, int64(3), orders)
+	var stored model.PromotionSecKill
+	assert.NoError(t, db.Where("goods_id = ?", 1).First(&stored).Error)
+	assert.Equal(t, int64(2), stored.PsCount)
+
+	pending, err := redisRepo.PendingOrderLen()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), pending)
+}
+
+// TestSeckillHandler_FlushPendingOrders_Failure 测试写库失败的订单放回队列等待下次写入
+func TestSeckillHandler_FlushPendingOrders_Failure(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	h := handler.NewSeckillHandler()
+	redisRepo := repository.NewRedisRepository()
+	assert.NoError(t, redisRepo.SetGoodsStock(1, 5))
+
+	// 订单表不可写，写库失败
+	_, err := h.CreateOrderRedisOnly(context.Background(), 1, 1, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Migrator().DropTable(&model.SuccessKilled{}))
+	assert.NoError(t, err)
+
+	flushed, err := h.FlushPendingOrders(context.Background(), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, flushed)
+
+	order, found, err := redisRepo.PopPendingOrder()
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 1, order.Attempts)
+	assert.NotEmpty(t, order.Reason)
+}
+
+// TestPaymentResult_BeforePendingOrderFlush 测试Redis-only订单写库前收到支付失败消息时先写库再取消，归还数据库和Redis库存
+func TestPaymentResult_BeforePendingOrderFlush(t *testing.T) {
+	f := SetupTestService(t, WithGoods(5, 1), WithRedisMode())
+	gs := f.Service
+	failed, err := gs.SeckillHandler.CreateOrderRedisOnly(context.Background(), 100, 1, 2)
+	require.NoError(t, err)
+	paid, err := gs.SeckillHandler.CreateOrderRedisOnly(context.Background(), 101, 1, 1)
+	require.NoError(t, err)
+
+	require.NoError(t, gs.HandlePaymentResult(failed, model.OrderStatusPaymentFailed))
+	require.NoError(t, gs.HandlePaymentResult(paid, model.OrderStatusPaid))
+
+	var failedOrder, paidOrder model.SuccessKilled
+	require.NoError(t, f.DB.Where("order_id = ?", failed).First(&failedOrder).Error)
+	assert.Equal(t, model.OrderStateCancelled, failedOrder.State)
+	require.NoError(t, f.DB.Where("order_id = ?", paid).First(&paidOrder).Error)
+	assert.Equal(t, model.OrderStatePaid, paidOrder.State)
+
+	// 两笔订单都已写库，定时写库任务不会再写入
+	pending, err := gs.RedisRepo.PendingOrderLen()
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+	flushed, err := gs.SeckillHandler.FlushPendingOrders(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Zero(t, flushed)
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), stock)
+	var promotion model.PromotionSecKill
+	require.NoError(t, f.DB.Where("goods_id = ?", 1).First(&promotion).Error)
+	assert.Equal(t, int64(4), promotion.PsCount)
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"seckill_system/config"
+	"seckill_system/global"
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedisStandalone_InitRedisUsesSingleNodeClient 测试standalone模式创建单节点客户端，仓库操作正常
+func TestRedisStandalone_InitRedisUsesSingleNodeClient(t *testing.T) {
+	preserveAppConfig(t)
+	mr := miniredis.RunT(t)
+	t.Setenv("SECKILL_REDIS_MODE", config.RedisModeStandalone)
+	t.Setenv("SECKILL_REDIS_CLUSTER_NODES", mr.Addr())
+	require.NoError(t, loadConfigDocument(t, 8100))
+
+	previous := global.RedisClient
+	t.Cleanup(func() {
+		global.CloseRedis()
+		global.RedisClient = previous
+	})
+	global.InitRedis()
+
+	_, ok := global.RedisClient.(*redis.Client)
+	require.True(t, ok, "standalone mode should use *redis.Client, got %T", global.RedisClient)
+
+	repo := repository.NewRedisRepository()
+	require.NoError(t, repo.SetGoodsStock(1, 5))
+	stock, err := repo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stock)
+}
+
+// TestRedisMode_Validation 测试Redis部署模式校验
+func TestRedisMode_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		nodes   string
+		wantErr bool
+	}{
+		{"default cluster", "", "127.0.0.1:7000,127.0.0.1:7001", false},
+		{"cluster", config.RedisModeCluster, "127.0.0.1:7000,127.0.0.1:7001", false},
+		{"standalone single node", config.RedisModeStandalone, "127.0.0.1:6379", false},
+		{"standalone multiple nodes", config.RedisModeStandalone, "127.0.0.1:6379,127.0.0.1:6380", true},
+		{"unknown mode", "sentinel", "127.0.0.1:6379", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preserveAppConfig(t)
+			t.Setenv("SECKILL_REDIS_MODE", tt.mode)
+			t.Setenv("SECKILL_REDIS_CLUSTER_NODES", tt.nodes)
+			err := loadConfigDocument(t, 8100)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
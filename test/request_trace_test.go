@@ -0,0 +1,120 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/config"
+	"seckill_system/model"
+	"seckill_system/web/controller"
+	"seckill_system/web/middleware"
+	"seckill_system/web/router"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTraceBuffer_GetAndEvict 测试按请求ID读取日志以及缓冲区写满后覆盖最早的日志
+func TestTraceBuffer_GetAndEvict(t *testing.T) {
+	buffer := config.NewTraceBuffer(4)
+	buffer.Add("req-a", model.TraceRecord{Message: "a1"})
+	buffer.Add("req-b", model.TraceRecord{Message: "b1"})
+	buffer.Add("req-a", model.TraceRecord{Message: "a2"})
+
+	records := buffer.Get("req-a")
+	assert.Len(t, records, 2)
+	assert.Equal(t, "a1", records[0].Message)
+	assert.Equal(t, "a2", records[1].Message)
+	assert.NotNil(t, buffer.Get("unknown"))
+	assert.Empty(t, buffer.Get("unknown"))
+
+	// 再写入3条后缓冲区回绕，req-a的a1和req-b的b1被覆盖
+	for i := 0; i < 3; i++ {
+		buffer.Add("req-c", model.TraceRecord{Message: fmt.Sprintf("c%d", i)})
+	}
+	records = buffer.Get("req-a")
+	assert.Len(t, records, 1)
+	assert.Equal(t, "a2", records[0].Message)
+	assert.Empty(t, buffer.Get("req-b"))
+	assert.Len(t, buffer.Get("req-c"), 3)
+	assert.Equal(t, "c0", buffer.Get("req-c")[0].Message)
+}
+
+// TestTraceHandler_RequestId 测试请求ID从context或日志属性获取，没有请求ID的日志被忽略
+func TestTraceHandler_RequestId(t *testing.T) {
+	buffer := config.NewTraceBuffer(10)
+	logger := slog.New(config.NewTraceHandler(buffer, slog.LevelInfo))
+
+	ctx := config.WithRequestId(context.Background(), "req-ctx")
+	logger.InfoContext(ctx, "Seckill token generated", "goods_id", int64(1))
+	logger.DebugContext(ctx, "Filtered by level")
+	logger.Info("Order created", config.TraceAttrRequestId, "req-attr")
+	logger.With(config.TraceAttrRequestId, "req-with").WithGroup("order").Warn("Stock low", "goods_id", 2)
+	logger.Info("No request id")
+
+	records := buffer.Get("req-ctx")
+	assert.Len(t, records, 1)
+	assert.Equal(t, "Seckill token generated", records[0].Message)
+	assert.Equal(t, "INFO", records[0].Level)
+	assert.Equal(t, int64(1), records[0].Attrs["goods_id"])
+	assert.Len(t, buffer.Get("req-attr"), 1)
+
+	records = buffer.Get("req-with")
+	assert.Len(t, records, 1)
+	assert.Equal(t, int64(2), records[0].Attrs["order.goods_id"])
+}
+
+// TestRequestTrace_AdminEndpoint 测试请求ID中间件与日志回放管理接口
+func TestRequestTrace_AdminEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buffer := config.NewTraceBuffer(100)
+	previous := slog.Default()
+	slog.SetDefault(slog.New(config.NewTraceHandler(buffer, slog.LevelInfo)))
+	defer slog.SetDefault(previous)
+
+	goodController := &controller.GoodController{Traces: buffer}
+	r := router.NewRouter(goodController, noopAuth, true)
+
+	// 客户端传入的合法请求ID被沿用
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/admin/reset_db/batch?admin=1", nil)
+	req.Header.Set(middleware.RequestIdHeader, "client-req-1")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "client-req-1", w.Header().Get(middleware.RequestIdHeader))
+
+	// 非法请求ID被替换为随机ID
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/goods/search", nil)
+	req.Header.Set(middleware.RequestIdHeader, "bad id\n")
+	r.ServeHTTP(w, req)
+	generated := w.Header().Get(middleware.RequestIdHeader)
+	assert.Len(t, generated, 32)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/trace/client-req-1?admin=1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data struct {
+			RequestId string              `json:"request_id"`
+			Records   []model.TraceRecord `json:"records"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "client-req-1", resp.Data.RequestId)
+	if assert.Len(t, resp.Data.Records, 2) {
+		assert.Equal(t, "Request started", resp.Data.Records[0].Message)
+		assert.Equal(t, "Request completed", resp.Data.Records[1].Message)
+		assert.Equal(t, float64(http.StatusNotFound), resp.Data.Records[1].Attrs["status"])
+		assert.False(t, resp.Data.Records[1].Time.Before(resp.Data.Records[0].Time.Time))
+	}
+
+	assert.Equal(t, http.StatusNotFound, serve(r, "GET", "/api/admin/trace/missing?admin=1"))
+
+	// 未启用缓冲区时返回404
+	disabled := router.NewAdminRouter(&controller.GoodController{})
+	assert.Equal(t, http.StatusNotFound, serve(disabled, "GET", "/api/admin/trace/"+generated+"?admin=1"))
+}
@@ -0,0 +1,249 @@
+package test
+
+import (
+	"encoding/json"
+	"seckill_system/config"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedisRepository_ReserveAndIssueToken 测试预占库存并签发令牌
+func TestRedisRepository_ReserveAndIssueToken(t *testing.T) {
+	mr := SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	assert.NoError(t, repo.SetGoodsStock(1, 2))
+
+	tokenId, remaining, err := repo.ReserveAndIssueToken(100, 1, 10*time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, tokenId, 32)
+	assert.Equal(t, int64(1), remaining)
+
+	// 库存被预占一个
+	stock, err := repo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stock)
+
+	// 令牌已写入且带有过期时间
+	data, err := mr.Get(repository.SeckillTokenKey(1, tokenId))
+	assert.NoError(t, err)
+	var token model.RedisSeckillToken
+	assert.NoError(t, json.Unmarshal([]byte(data), &token))
+	assert.Equal(t, int64(100), token.UserId)
+	assert.Equal(t, int64(1), token.GoodsId)
+	assert.True(t, token.Reserved)
+	assert.Equal(t, 10*time.Minute, mr.TTL(repository.SeckillTokenKey(1, tokenId)))
+
+	// 签发的令牌可正常验证
+	valid, err := repo.VerifySeckillToken(tokenId, 100, 1)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+// TestRedisRepository_ReserveAndIssueToken_SoldOut 测试库存不足时不签发令牌
+func TestRedisRepository_ReserveAndIssueToken_SoldOut(t *testing.T) {
+	mr := SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	assert.NoError(t, repo.SetGoodsStock(1, 0))
+
+	tokenId, _, err := repo.ReserveAndIssueToken(100, 1, time.Minute)
+	assert.ErrorIs(t, err, repository.ErrGoodsSoldOut)
+	assert.Empty(t, tokenId)
+	assert.Equal(t, []string{repository.StockKey(1)}, mr.Keys()) // 除库存外没有写入任何令牌
+
+	// 库存不存在时返回ErrStockNotFound
+	_, _, err = repo.ReserveAndIssueToken(100, 2, time.Minute)
+	assert.ErrorIs(t, err, repository.ErrStockNotFound)
+}
+
+// TestRedisRepository_ReserveAndIssueToken_Concurrent 测试并发签发时令牌数不超过库存
+func TestRedisRepository_ReserveAndIssueToken_Concurrent(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	const stock, users = 5, 50
+	assert.NoError(t, repo.SetGoodsStock(1, stock))
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		issued  int
+		soldOut int
+	)
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func(userId int64) {
+			defer wg.Done()
+			_, _, err := repo.ReserveAndIssueToken(userId, 1, time.Minute)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				issued++
+			} else if assert.ErrorIs(t, err, repository.ErrGoodsSoldOut) {
+				soldOut++
+			}
+		}(int64(i + 1))
+	}
+	wg.Wait()
+
+	assert.Equal(t, stock, issued)        // 恰好签发库存数量的令牌
+	assert.Equal(t, users-stock, soldOut) // 其余请求均返回售罄
+	remaining, err := repo.GetStockAtomic(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), remaining) // 库存不会被扣成负数
+}
+
+// setupReserveStockService 创建开启令牌库存预占的商品服务，数据库库存10件，Redis库存为stock
+func setupReserveStockService(t *testing.T, stock int64) (*service.GoodService, *miniredis.Miniredis) {
+	f := SetupTestService(t, WithSeckillEnabled(3), WithSeckillConfig(config.SeckillConfig{ReserveStock: true}))
+	require.NoError(t, f.Service.RedisRepo.SetGoodsStock(1, stock))
+	return f.Service, f.Redis
+}
+
+// TestReserveStock_GenerateTokenReserves 测试开启预占后签发令牌即扣减Redis库存，库存全部预占后拒绝签发
+func TestReserveStock_GenerateTokenReserves(t *testing.T) {
+	gs, _ := setupReserveStockService(t, 2)
+
+	_, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	require.NoError(t, err)
+	_, err = gs.GenerateSeckillToken(101, 1, "203.0.113.7")
+	require.NoError(t, err)
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stock)
+
+	_, err = gs.GenerateSeckillToken(102, 1, "203.0.113.7")
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+}
+
+// TestReserveStock_RedeemDoesNotDecrementAgain 测试兑换预占令牌下单时不再扣减Redis库存，数据库库存扣减一件
+func TestReserveStock_RedeemDoesNotDecrementAgain(t *testing.T) {
+	gs, _ := setupReserveStockService(t, 2)
+	tokenId, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	require.NoError(t, err)
+
+	orderId, err := gs.SeckillWithToken(100, 1, tokenId)
+	require.NoError(t, err)
+	assert.NotEmpty(t, orderId)
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stock) // 只有签发时预占的一件
+
+	var promotion model.PromotionSecKill
+	require.NoError(t, global.DBClient.Where("goods_id = ?", 1).First(&promotion).Error)
+	assert.Equal(t, int64(9), promotion.PsCount)
+}
+
+// TestReserveStock_AsyncRedeemDoesNotDecrementAgain 测试异步秒杀兑换预占令牌时消费者不再扣减Redis库存
+func TestReserveStock_AsyncRedeemDoesNotDecrementAgain(t *testing.T) {
+	gs, queue := newAsyncSeckillService(t, 2)
+	tokenId, _, err := gs.RedisRepo.ReserveAndIssueToken(100, 1, time.Minute)
+	require.NoError(t, err)
+
+	requestId, err := gs.SeckillAsync(100, 1, tokenId, "203.0.113.7")
+	require.NoError(t, err)
+	requests := queue.drain()
+	require.Len(t, requests, 1)
+	assert.True(t, requests[0].StockReserved)
+	require.NoError(t, gs.HandleSeckillRequest(requests[0]))
+
+	result, err := gs.GetAsyncSeckillResult(100, requestId)
+	require.NoError(t, err)
+	assert.Equal(t, model.AsyncSeckillSuccess, result.Status)
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stock)
+}
+
+// TestReserveStock_ExpiredReservationReleased 测试过期未兑换令牌预占的库存由校准任务归还，之后兑换该令牌按未预占扣减
+func TestReserveStock_ExpiredReservationReleased(t *testing.T) {
+	gs, _ := setupReserveStockService(t, 1)
+	tokenId, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	require.NoError(t, err)
+
+	_, err = gs.RefreshStockCache(time.Now().Add(gs.RedisRepo.SeckillTokenTTL() + time.Second))
+	require.NoError(t, err)
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stock)
+
+	// 预占已归还，迟到的兑换重新扣减库存，不会超卖
+	_, err = gs.SeckillWithToken(100, 1, tokenId)
+	require.NoError(t, err)
+	stock, err = gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stock)
+}
+
+// TestRedisRepository_ReserveAndIssueToken_ReleasesExpired 测试签发前归还已过期的预占
+func TestRedisRepository_ReserveAndIssueToken_ReleasesExpired(t *testing.T) {
+	mr := SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	require.NoError(t, repo.SetGoodsStock(1, 0))
+	_, err := mr.ZAdd(repository.SeckillTokenReservationsKey(1), 1, "expired-token")
+	require.NoError(t, err)
+
+	_, _, err = repo.ReserveAndIssueToken(100, 1, time.Minute)
+	require.NoError(t, err) // 过期预占归还的一件被新令牌预占
+	stock, err := repo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stock)
+}
+
+// TestRedisRepository_ExpiredReservedTokenReturnsStock 测试兑换已过期的预占令牌失败并归还库存
+func TestRedisRepository_ExpiredReservedTokenReturnsStock(t *testing.T) {
+	mr := SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	require.NoError(t, repo.SetGoodsStock(1, 0))
+	tokenId := "0123456789abcdef0123456789abcdef"
+	data, err := json.Marshal(model.RedisSeckillToken{
+		TokenId:  tokenId,
+		UserId:   100,
+		GoodsId:  1,
+		Reserved: true,
+		ExpireAt: time.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+	require.NoError(t, mr.Set(repository.SeckillTokenKey(1, tokenId), string(data)))
+	_, err = mr.ZAdd(repository.SeckillTokenReservationsKey(1), float64(time.Now().Add(time.Minute).UnixMilli()), tokenId)
+	require.NoError(t, err)
+
+	valid, reserved, err := repo.RedeemSeckillToken(tokenId, 100, 1)
+	assert.ErrorIs(t, err, errs.ErrTokenExpired)
+	assert.False(t, valid)
+	assert.False(t, reserved)
+	stock, err := repo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stock)
+}
+
+// TestReserveStock_ExpireTokenReturnsStock 测试管理员使预占令牌失效时归还库存
+func TestReserveStock_ExpireTokenReturnsStock(t *testing.T) {
+	gs, _ := setupReserveStockService(t, 1)
+	tokenId, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	require.NoError(t, err)
+
+	existed, err := gs.ExpireSeckillToken(1, tokenId)
+	require.NoError(t, err)
+	assert.True(t, existed)
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stock)
+
+	// 重复失效不会再次归还
+	existed, err = gs.ExpireSeckillToken(1, tokenId)
+	require.NoError(t, err)
+	assert.False(t, existed)
+	stock, err = gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stock)
+}
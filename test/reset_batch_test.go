@@ -0,0 +1,62 @@
+package test
+
+import (
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// seedResetData 写入商品、已扣减的促销库存和订单记录
+func seedResetData(t *testing.T, db *gorm.DB, goodsIds ...int64) {
+	for _, goodsId := range goodsIds {
+		good := CreateTestGoods(goodsId)
+		promotion := CreateTestPromotion(goodsId, 3) // 模拟已被秒杀过的库存
+		promotion.Version = 5
+		order := CreateTestOrder(1, goodsId)
+		assert.NoError(t, db.Create(&good).Error)
+		assert.NoError(t, db.Create(&promotion).Error)
+		assert.NoError(t, db.Create(&order).Error)
+	}
+}
+
+// TestGoodRepository_ResetDataBaseBatch 测试批量重置指定商品且不影响其他商品
+func TestGoodRepository_ResetDataBaseBatch(t *testing.T) {
+	db := SetupTestDB(t)
+	seedResetData(t, db, 1, 2, 3)
+	repo := repository.NewGoodRepository()
+
+	results, err := repo.ResetDataBaseBatch([]int{1, 2, 99, 0})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ResetResult{
+		{GoodsId: 1, Success: true},
+		{GoodsId: 2, Success: true},
+		{GoodsId: 99, Error: "goods not found: 99"},
+		{GoodsId: 0, Error: "invalid goodsId: 0"},
+	}, results)
+
+	// 指定商品的库存和版本号被重置，订单被清空
+	for _, goodsId := range []int64{1, 2} {
+		var promotion model.PromotionSecKill
+		assert.NoError(t, db.Where("goods_id = ?", goodsId).First(&promotion).Error)
+		assert.Equal(t, int64(global.BookStockCount), promotion.PsCount)
+		assert.Equal(t, int64(0), promotion.Version)
+
+		var orders int64
+		assert.NoError(t, db.Model(&model.SuccessKilled{}).Where("goods_id = ?", goodsId).Count(&orders).Error)
+		assert.Equal(t, int64(0), orders)
+	}
+
+	// 未指定的商品保持不变
+	var promotion model.PromotionSecKill
+	assert.NoError(t, db.Where("goods_id = ?", 3).First(&promotion).Error)
+	assert.Equal(t, int64(3), promotion.PsCount)
+	assert.Equal(t, int64(5), promotion.Version)
+
+	var orders int64
+	assert.NoError(t, db.Model(&model.SuccessKilled{}).Where("goods_id = ?", 3).Count(&orders).Error)
+	assert.Equal(t, int64(1), orders)
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/errs"
+	"seckill_system/model"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSeckillAuditLog_RecordsSuccessAndFailures 测试使用令牌秒杀的成功和失败结果都写入审计表
+func TestSeckillAuditLog_RecordsSuccessAndFailures(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+	require.NoError(t, gs.RedisRepo.SetGoodsStock(1, 1))
+
+	tokenId, err := gs.RedisRepo.GenerateSeckillToken(100, 1)
+	require.NoError(t, err)
+	_, err = gs.SeckillWithToken(100, 1, tokenId)
+	require.NoError(t, err)
+
+	// 令牌已被消费
+	_, err = gs.SeckillWithToken(100, 1, tokenId)
+	assert.ErrorIs(t, err, errs.ErrInvalidToken)
+
+	// 库存不足
+	soldOutToken, err := gs.RedisRepo.GenerateSeckillToken(101, 1)
+	require.NoError(t, err)
+	_, err = gs.SeckillWithToken(101, 1, soldOutToken)
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+
+	// 其他商品的记录不出现在结果中
+	_, err = gs.SeckillWithToken(100, 2, "unknown-token")
+	assert.Error(t, err)
+
+	result, err := gs.ListSeckillAuditLogs(1, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), result.Total)
+	require.Len(t, result.Items, 3)
+
+	// 最新的在前
+	soldOut, invalid, success := result.Items[0], result.Items[1], result.Items[2]
+	assert.Equal(t, int64(101), soldOut.UserId)
+	assert.Equal(t, errs.OutcomeSoldOut, soldOut.Result)
+	assert.NotEmpty(t, soldOut.Reason)
+	assert.Equal(t, errs.OutcomeInvalidToken, invalid.Result)
+	assert.Equal(t, int64(100), success.UserId)
+	assert.Equal(t, int64(1), success.GoodsId)
+	assert.Equal(t, errs.OutcomeSuccess, success.Result)
+	assert.Empty(t, success.Reason)
+	assert.Equal(t, model.TokenPrefix(tokenId), success.TokenPrefix)
+	assert.NotEqual(t, tokenId, success.TokenPrefix) // 不保存完整令牌
+	assert.False(t, success.CreateTime.IsZero())
+}
+
+// TestSeckillAuditLog_API 测试审计记录管理接口分页返回并校验参数
+func TestSeckillAuditLog_API(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	gs := SetupTestService(t, WithRedisMode()).Service
+	for i := 0; i < 3; i++ {
+		_, err := gs.SeckillWithToken(int64(100+i), 1, "missing-token")
+		assert.Error(t, err)
+	}
+	r := router.NewAdminRouter(&controller.GoodController{GoodService: gs})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/audit?admin=1&goods_id=1&page=1&size=2", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Code int                                    `json:"code"`
+		Data model.Paginated[model.SeckillAuditLog] `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 0, resp.Code)
+	assert.Equal(t, int64(3), resp.Data.Total)
+	assert.True(t, resp.Data.HasNext)
+	require.Len(t, resp.Data.Items, 2)
+	assert.Equal(t, int64(102), resp.Data.Items[0].UserId)
+
+	assert.Equal(t, http.StatusBadRequest, serve(r, "GET", "/api/admin/audit?admin=1"))
+	assert.Equal(t, http.StatusBadRequest, serve(r, "GET", "/api/admin/audit?admin=1&goods_id=abc"))
+	assert.Equal(t, http.StatusBadRequest, serve(r, "GET", "/api/admin/audit?admin=1&goods_id=1&page=0"))
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"context"
+	"log/slog"
+	"seckill_system/config"
+	"seckill_system/service"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 秒杀热点路径基准测试，使用miniredis和sqlite，不依赖外部服务
+// CI中建议固定迭代次数运行：go test ./test/ -run '^$' -bench . -benchmem -benchtime 2000x
+
+// benchGoodsId 基准测试使用的商品ID
+const benchGoodsId = 1
+
+// discardLogs 基准测试期间丢弃日志，避免日志输出影响测量结果
+func discardLogs(b *testing.B) {
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+	b.Cleanup(func() { slog.SetDefault(previous) })
+}
+
+// setupBenchService 准备库存为stock的商品、促销和秒杀服务，秒杀锁使用Redis后端
+func setupBenchService(b *testing.B, stock int64, mode string) *service.GoodService {
+	discardLogs(b)
+	return SetupTestService(b,
+		WithGoods(stock, benchGoodsId),
+		WithSeckillEnabled(1000000),
+		WithSeckillConfig(config.SeckillConfig{Mode: mode}),
+	).Service
+}
+
+// BenchmarkGenerateSeckillToken 获取秒杀令牌：用户锁、开关、黑名单、商品、活动时间、库存和限流检查
+func BenchmarkGenerateSeckillToken(b *testing.B) {
+	gs := setupBenchService(b, 1000, config.SeckillModeDB)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gs.GenerateSeckillToken(int64(i+1), benchGoodsId, "203.0.113.7"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCreateOrder db模式下单：Redis预扣库存后在数据库事务中乐观锁扣减并创建订单
+func BenchmarkCreateOrder(b *testing.B) {
+	gs := setupBenchService(b, int64(b.N), config.SeckillModeDB)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gs.SeckillHandler.CreateOrder(ctx, int64(i+1), benchGoodsId, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCreateOrderRedisOnly redis模式下单：Lua脚本扣减库存后订单进入待写库队列
+func BenchmarkCreateOrderRedisOnly(b *testing.B) {
+	gs := setupBenchService(b, int64(b.N), config.SeckillModeRedis)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gs.SeckillHandler.CreateOrderRedisOnly(ctx, int64(i+1), benchGoodsId, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkLuaStockDecr 库存扣减Lua脚本
+func BenchmarkLuaStockDecr(b *testing.B) {
+	gs := setupBenchService(b, int64(b.N), config.SeckillModeDB)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ok, err := gs.RedisRepo.CheckAndDecrStockBy(benchGoodsId, 1); err != nil || !ok {
+			b.Fatalf("decrease stock failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkLuaReserveAndConsumeToken 预占库存签发令牌与校验消费令牌两个Lua脚本
+func BenchmarkLuaReserveAndConsumeToken(b *testing.B) {
+	gs := setupBenchService(b, int64(b.N), config.SeckillModeDB)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		userId := int64(i + 1)
+		tokenId, _, err := gs.RedisRepo.ReserveAndIssueToken(userId, benchGoodsId, time.Minute)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if valid, err := gs.RedisRepo.VerifySeckillToken(tokenId, userId, benchGoodsId); err != nil || !valid {
+			b.Fatalf("consume token failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkLuaUserRateLimit 用户限流Lua脚本
+func BenchmarkLuaUserRateLimit(b *testing.B) {
+	gs := setupBenchService(b, 1, config.SeckillModeDB)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gs.RedisRepo.UserRateLimit(int64(i%1000+1), 1000000, time.Minute); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSeckillParallel 并发秒杀：多个用户同时使用令牌下单（令牌校验、用户锁、redis模式扣减库存）
+// 令牌在计时前签发，只测量秒杀请求本身
+func BenchmarkSeckillParallel(b *testing.B) {
+	gs := setupBenchService(b, int64(b.N), config.SeckillModeRedis)
+	tokens := make([]string, b.N)
+	for i := range tokens {
+		tokenId, err := gs.RedisRepo.GenerateSeckillToken(int64(i+1), benchGoodsId)
+		if err != nil {
+			b.Fatal(err)
+		}
+		tokens[i] = tokenId
+	}
+
+	var next atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			i := next.Add(1) - 1
+			if _, err := gs.SeckillWithToken(i+1, benchGoodsId, tokens[i]); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
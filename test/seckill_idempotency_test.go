@@ -0,0 +1,104 @@
+package test
+
+import (
+	"seckill_system/errs"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSeckillIdempotency_RepeatReturnsOriginalOrder 测试相同幂等键的重试返回首次请求的订单且不再扣减库存
+func TestSeckillIdempotency_RepeatReturnsOriginalOrder(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service // 关闭结果缓存，只验证幂等键
+	tokenId := mustSeckillToken(t, gs, 100, 1)
+
+	orderId, err := gs.SeckillWithIdempotencyKey(100, 1, tokenId, "retry-key-1")
+	require.NoError(t, err)
+	require.NotEmpty(t, orderId)
+
+	// 令牌已被消费，重新执行会失败；携带相同幂等键的重试直接返回原订单
+	repeat, err := gs.SeckillWithIdempotencyKey(100, 1, tokenId, "retry-key-1")
+	assert.NoError(t, err)
+	assert.Equal(t, orderId, repeat)
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), stock)
+	pending, err := gs.RedisRepo.PendingOrderLen()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pending)
+
+	// 不同的幂等键不复用结果
+	_, err = gs.SeckillWithIdempotencyKey(100, 1, tokenId, "retry-key-2")
+	assert.ErrorIs(t, err, errs.ErrInvalidToken)
+}
+
+// TestSeckillIdempotency_RepeatReturnsOriginalError 测试业务失败的结果同样按幂等键保留，重试返回相同类别和信息的错误
+func TestSeckillIdempotency_RepeatReturnsOriginalError(t *testing.T) {
+	gs := SetupTestService(t, WithGoods(0, 1), WithRedisMode()).Service
+
+	_, first := gs.SeckillWithIdempotencyKey(100, 1, mustSeckillToken(t, gs, 100, 1), "sold-out-key")
+	require.ErrorIs(t, first, errs.ErrSoldOut)
+
+	// 补充库存后使用相同幂等键重试，仍返回首次请求的售罄结果
+	require.NoError(t, gs.RedisRepo.SetGoodsStock(1, 10))
+	_, repeat := gs.SeckillWithIdempotencyKey(100, 1, mustSeckillToken(t, gs, 100, 1), "sold-out-key")
+	assert.ErrorIs(t, repeat, errs.ErrSoldOut)
+	assert.Equal(t, first.Error(), repeat.Error())
+	assert.Equal(t, errs.Code(first), errs.Code(repeat))
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), stock)
+}
+
+// TestSeckillIdempotency_KeyConflicts 测试幂等键用于其他商品时被拒绝，首个请求处理中时重试返回系统繁忙
+func TestSeckillIdempotency_KeyConflicts(t *testing.T) {
+	gs := SetupTestService(t, WithGoods(10, 1, 2), WithRedisMode()).Service
+
+	_, err := gs.SeckillWithIdempotencyKey(100, 1, mustSeckillToken(t, gs, 100, 1), "shared-key")
+	require.NoError(t, err)
+	_, err = gs.SeckillWithIdempotencyKey(100, 2, mustSeckillToken(t, gs, 100, 2), "shared-key")
+	assert.ErrorIs(t, err, errs.ErrIdempotencyKeyReused)
+
+	// 模拟首个请求仍在处理中
+	_, claimed, err := gs.RedisRepo.ClaimSeckillIdempotencyKey(100, "inflight-key", 2, time.Minute)
+	require.NoError(t, err)
+	require.True(t, claimed)
+	_, err = gs.SeckillWithIdempotencyKey(100, 2, mustSeckillToken(t, gs, 100, 2), "inflight-key")
+	assert.ErrorIs(t, err, errs.ErrIdempotencyInProgress)
+	assert.ErrorIs(t, err, errs.ErrSystemBusy)
+
+	stock, err := gs.RedisRepo.GetGoodsStock(2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), stock)
+}
+
+// TestSeckillIdempotency_Expires 测试幂等记录在保留时间后过期，幂等键按用户隔离
+func TestSeckillIdempotency_Expires(t *testing.T) {
+	f := SetupTestService(t, WithRedisMode())
+	gs, mr := f.Service, f.Redis
+	gs.Seckill.IdempotencySeconds = 60
+
+	_, err := gs.SeckillWithIdempotencyKey(100, 1, mustSeckillToken(t, gs, 100, 1), "expiring-key")
+	require.NoError(t, err)
+	assert.True(t, mr.Exists(repository.SeckillIdempotencyKey(100, "expiring-key")))
+	assert.False(t, mr.Exists(repository.SeckillIdempotencyKey(200, "expiring-key")))
+
+	mr.FastForward(61 * time.Second)
+	assert.False(t, mr.Exists(repository.SeckillIdempotencyKey(100, "expiring-key")))
+}
+
+// TestValidIdempotencyKey 测试幂等键格式校验
+func TestValidIdempotencyKey(t *testing.T) {
+	assert.True(t, model.ValidIdempotencyKey("3f2b9c1e-7a4d-4e8f-9b21-0c5d6e7f8a9b"))
+	assert.False(t, model.ValidIdempotencyKey(""))
+	assert.False(t, model.ValidIdempotencyKey("has space"))
+	assert.False(t, model.ValidIdempotencyKey("中文"))
+	assert.False(t, model.ValidIdempotencyKey(strings.Repeat("a", model.MaxIdempotencyKeyLength+1)))
+}
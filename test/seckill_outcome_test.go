@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"seckill_system/errs"
+	"seckill_system/model"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// logRecorder 记录日志消息和级别的slog处理器
+type logRecorder struct {
+	mu      sync.Mutex
+	records []slog.Record // 已记录的日志
+}
+
+// Enabled 记录所有级别
+func (h *logRecorder) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+// Handle 记录一条日志
+func (h *logRecorder) Handle(ctx context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, record)
+	return nil
+}
+
+// WithAttrs 测试中不区分属性
+func (h *logRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h
+}
+
+// WithGroup 测试中不区分分组
+func (h *logRecorder) WithGroup(name string) slog.Handler {
+	return h
+}
+
+// levelsOf 返回指定消息的日志级别
+func (h *logRecorder) levelsOf(message string) []slog.Level {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var levels []slog.Level
+	for _, record := range h.records {
+		if record.Message == message {
+			levels = append(levels, record.Level)
+		}
+	}
+	return levels
+}
+
+// captureLogs 将默认logger替换为logRecorder，测试结束后恢复
+func captureLogs(t *testing.T) *logRecorder {
+	recorder := &logRecorder{}
+	previous := slog.Default()
+	slog.SetDefault(slog.New(recorder))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return recorder
+}
+
+// TestOutcome_Classification 测试各类错误的结果分类和日志级别
+func TestOutcome_Classification(t *testing.T) {
+	cases := []struct {
+		err     error
+		outcome string
+		level   slog.Level
+	}{
+		{nil, errs.OutcomeSuccess, slog.LevelInfo},
+		{fmt.Errorf("stock check failed: %w", errs.ErrSoldOut), errs.OutcomeSoldOut, slog.LevelInfo},
+		{fmt.Errorf("%w: user 100 goods 1", errs.ErrAlreadyPurchased), errs.OutcomeAlreadyPurchased, slog.LevelInfo},
+		{errs.ErrRateLimited, errs.OutcomeRateLimited, slog.LevelWarn},
+		{fmt.Errorf("invalid seckill token: %w", errs.ErrTokenExpired), errs.OutcomeInvalidToken, slog.LevelWarn},
+		{errs.ErrBlacklisted, errs.OutcomeForbidden, slog.LevelWarn},
+		{errs.ErrGoodsNotFound, errs.OutcomeNotFound, slog.LevelWarn},
+		{errs.ErrRateLimiterUnavailable, errs.OutcomeSystemBusy, slog.LevelWarn},
+		{errors.New("database connection refused"), errs.OutcomeError, slog.LevelError},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.outcome, errs.Outcome(c.err), c.outcome)
+		assert.Equal(t, c.level, errs.LogLevel(c.err), c.outcome)
+	}
+}
+
+// TestSeckillOutcome_SoldOutLoggedAsInfo 测试售罄不按错误记录日志并单独计数
+func TestSeckillOutcome_SoldOutLoggedAsInfo(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(1, 1))
+	_, err := gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	assert.NoError(t, err)
+
+	logs := captureLogs(t)
+	_, err = gs.SeckillWithToken(101, 1, mustSeckillToken(t, gs, 101, 1))
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+
+	assert.Equal(t, []slog.Level{slog.LevelInfo}, logs.levelsOf("Seckill failed"))
+	assert.Equal(t, map[string]int64{errs.OutcomeSuccess: 1, errs.OutcomeSoldOut: 1}, gs.Outcomes()[model.AuditActionSeckill])
+}
+
+// TestSeckillOutcome_InvalidTokenLoggedAsWarn 测试令牌无效记录为Warn并单独计数
+func TestSeckillOutcome_InvalidTokenLoggedAsWarn(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+	logs := captureLogs(t)
+
+	_, err := gs.SeckillWithToken(100, 1, absentToken)
+	assert.ErrorIs(t, err, errs.ErrInvalidToken)
+
+	assert.Equal(t, []slog.Level{slog.LevelWarn}, logs.levelsOf("Invalid seckill token"))
+	assert.Empty(t, logs.levelsOf("Seckill failed"))
+	assert.Equal(t, map[string]int64{errs.OutcomeInvalidToken: 1}, gs.Outcomes()[model.AuditActionSeckill])
+}
+
+// TestSeckillOutcome_InfraFailureCountedAsError 测试Redis故障计为error，与业务结果分开
+func TestSeckillOutcome_InfraFailureCountedAsError(t *testing.T) {
+	f := SetupTestService(t, WithRedisMode())
+	gs, mr := f.Service, f.Redis
+	tokenId := mustSeckillToken(t, gs, 100, 1)
+	mr.Close()
+
+	_, err := gs.SeckillWithToken(100, 1, tokenId)
+	assert.Error(t, err)
+	assert.Equal(t, errs.OutcomeError, errs.Outcome(err))
+	assert.Equal(t, map[string]int64{errs.OutcomeError: 1}, gs.Outcomes()[model.AuditActionSeckill])
+}
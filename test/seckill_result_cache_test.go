@@ -0,0 +1,119 @@
+package test
+
+import (
+	"seckill_system/config"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSeckillResultCache_DuplicateReturnsCachedOrder 测试窗口内的重复提交返回缓存订单且不再扣减库存
+func TestSeckillResultCache_DuplicateReturnsCachedOrder(t *testing.T) {
+	gs := SetupTestService(t, WithSeckillConfig(config.SeckillConfig{Mode: config.SeckillModeRedis, ResultCacheSeconds: 5})).Service
+
+	first, err := gs.RedisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+	second, err := gs.RedisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+
+	orderId, err := gs.SeckillWithToken(100, 1, first)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, orderId)
+
+	duplicate, err := gs.SeckillWithToken(100, 1, second)
+	assert.NoError(t, err)
+	assert.Equal(t, orderId, duplicate)
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), stock)
+	pending, err := gs.RedisRepo.PendingOrderLen()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pending)
+
+	// 重复提交未消耗令牌
+	valid, err := gs.RedisRepo.VerifySeckillToken(second, 100, 1)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+// TestSeckillResultCache_Expired 测试缓存过期后重新走完整的下单流程
+func TestSeckillResultCache_Expired(t *testing.T) {
+	f := SetupTestService(t, WithSeckillConfig(config.SeckillConfig{Mode: config.SeckillModeRedis, ResultCacheSeconds: 5}))
+	gs, mr := f.Service, f.Redis
+
+	orderId, err := gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	assert.NoError(t, err)
+
+	mr.FastForward(6 * time.Second)
+	assert.False(t, mr.Exists(repository.SeckillResultKey(100, 1)))
+
+	next, err := gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	assert.NoError(t, err)
+	assert.NotEqual(t, orderId, next)
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), stock)
+}
+
+// TestSeckillResultCache_Disabled 测试未开启结果缓存时不写入缓存
+func TestSeckillResultCache_Disabled(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+
+	_, err := gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	assert.NoError(t, err)
+
+	_, found, err := gs.RedisRepo.GetSeckillResult(100, 1)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.Error(t, config.SeckillConfig{ResultCacheSeconds: -1}.Validate())
+}
+
+// TestSeckillResultCache_SkippedForMultiPurchaseLimit 测试活动限购多件时窗口内的后续购买正常下单，不返回缓存的订单
+func TestSeckillResultCache_SkippedForMultiPurchaseLimit(t *testing.T) {
+	gs := SetupTestService(t,
+		WithPurchaseLimit(3),
+		WithSeckillConfig(config.SeckillConfig{Mode: config.SeckillModeRedis, ResultCacheSeconds: 5}),
+	).Service
+
+	first, err := gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	require.NoError(t, err)
+	second, err := gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	// 异步秒杀受理时已消费令牌，处理时同样不能返回缓存的订单
+	queue := &recordingSeckillQueue{}
+	gs.AsyncQueue = queue
+	requestId, err := gs.SeckillAsync(100, 1, mustSeckillToken(t, gs, 100, 1), "")
+	require.NoError(t, err)
+	for _, request := range queue.drain() {
+		require.NoError(t, gs.HandleSeckillRequest(request))
+	}
+	result, err := gs.GetAsyncSeckillResult(100, requestId)
+	require.NoError(t, err)
+	assert.Equal(t, model.AsyncSeckillSuccess, result.Status)
+	assert.NotContains(t, []string{first, second}, result.OrderId)
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), stock)
+	_, found, err := gs.RedisRepo.GetSeckillResult(100, 1)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+// mustSeckillToken 为用户生成秒杀令牌
+func mustSeckillToken(t *testing.T, gs *service.GoodService, userId, goodsId int64) string {
+	t.Helper()
+	tokenId, err := gs.RedisRepo.GenerateSeckillToken(userId, goodsId)
+	assert.NoError(t, err)
+	return tokenId
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"seckill_system/config"
+	"seckill_system/global"
+	"seckill_system/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGenerateGoodsData_CustomSeed 测试使用自定义分类和商品类型生成数据
+func TestGenerateGoodsData_CustomSeed(t *testing.T) {
+	seed := config.SeedConfig{
+		Categories: []int64{7, 8},
+		ItemTypes:  []string{"Laptop", "Phone"},
+		ItemName:   "Device",
+	}
+
+	goods := global.GenerateGoodsData(50, seed)
+
+	assert.Len(t, goods, 50)
+	for _, good := range goods {
+		assert.Contains(t, seed.Categories, good.CategoryId) // 分类来自自定义配置
+		assert.True(t,
+			strings.HasPrefix(good.Title, "Laptop Device-") || strings.HasPrefix(good.Title, "Phone Device-"),
+			"unexpected title: %s", good.Title,
+		)
+		assert.Contains(t, good.SubTitle, "device") // 副标题使用小写商品名称
+		assert.NotContains(t, good.Title, "Book")   // 不再出现默认的图书标签
+	}
+}
+
+// TestGenerateGoodsData_DefaultSeed 测试未配置时使用默认图书类目
+func TestGenerateGoodsData_DefaultSeed(t *testing.T) {
+	defaults := config.DefaultSeedConfig()
+
+	goods := global.GenerateGoodsData(20, config.SeedConfig{})
+
+	assert.Len(t, goods, 20)
+	for _, good := range goods {
+		assert.Contains(t, defaults.Categories, good.CategoryId)
+		assert.Contains(t, good.Title, " Book-")
+		assert.True(t, strings.HasPrefix(good.SubTitle, "High-quality "))
+		assert.True(t, strings.HasSuffix(good.SubTitle, " book"))
+	}
+}
+
+// TestInsertTestData_Disabled 测试数量为0时不插入任何数据
+func TestInsertTestData_Disabled(t *testing.T) {
+	db := SetupTestDB(t)
+
+	assert.NoError(t, global.InsertTestData(0, config.SeedConfig{}))
+
+	var goodsCount, promotionCount int64
+	assert.NoError(t, db.Model(&model.Goods{}).Count(&goodsCount).Error)
+	assert.NoError(t, db.Model(&model.PromotionSecKill{}).Count(&promotionCount).Error)
+	assert.Zero(t, goodsCount)
+	assert.Zero(t, promotionCount)
+}
+
+// TestInsertTestData_CustomCount 测试插入指定数量的商品和促销数据
+func TestInsertTestData_CustomCount(t *testing.T) {
+	db := SetupTestDB(t)
+
+	assert.NoError(t, global.InsertTestData(25, config.SeedConfig{}))
+
+	var goodsCount, promotionCount int64
+	assert.NoError(t, db.Model(&model.Goods{}).Count(&goodsCount).Error)
+	assert.NoError(t, db.Model(&model.PromotionSecKill{}).Count(&promotionCount).Error)
+	assert.Equal(t, int64(25), goodsCount)
+	assert.Equal(t, int64(25), promotionCount)
+}
+
+// TestInsertTestData_PurchaseLimit 测试生成的促销数据使用配置的限购数量
+func TestInsertTestData_PurchaseLimit(t *testing.T) {
+	db := SetupTestDB(t)
+
+	assert.NoError(t, global.InsertTestData(5, config.SeedConfig{PurchaseLimit: 3}))
+
+	var promotions []model.PromotionSecKill
+	assert.NoError(t, db.Find(&promotions).Error)
+	assert.Len(t, promotions, 5)
+	for _, promotion := range promotions {
+		assert.Equal(t, int64(3), promotion.PurchaseLimit)
+	}
+}
+
+// TestSeedConfig_GoodsCount 测试未配置数量时使用默认值，显式配置0时保留0
+func TestSeedConfig_GoodsCount(t *testing.T) {
+	seed := config.SeedConfig{}
+	seed.ApplyDefaults()
+	assert.Equal(t, config.DefaultSeedCount, seed.GoodsCount())
+
+	zero := 0
+	seed = config.SeedConfig{Count: &zero}
+	seed.ApplyDefaults()
+	assert.Equal(t, 0, seed.GoodsCount())
+}
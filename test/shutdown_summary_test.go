@@ -0,0 +1,58 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestShutdownSummary_PendingCounts 测试关闭摘要反映已处理消息数和未完成的工作
+func TestShutdownSummary_PendingCounts(t *testing.T) {
+	SetupTestRedis(t)
+	gs := &service.GoodService{
+		RedisRepo:    repository.NewRedisRepository(),
+		PaymentGrace: time.Minute,
+	}
+
+	// 模拟2条已处理的订单消息、3条支付失败消息（进入等待重试）和1条发件箱积压消息
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, gs.HandleOrderMessage(model.OrderMessage{OrderId: fmt.Sprintf("%d-1-1", i), Status: model.OrderStatusCreated}))
+	}
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, gs.HandlePaymentResult(fmt.Sprintf("%d-2-1", i), model.OrderStatusPaymentFailed))
+	}
+	assert.NoError(t, gs.RedisRepo.PushOrderOutbox(&model.OrderOutboxEntry{Message: model.OrderMessage{OrderId: "9-3-1"}}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	summary := gs.Shutdown(ctx, start)
+
+	assert.Equal(t, int64(2), summary.OrdersProcessed)
+	assert.Equal(t, int64(3), summary.PaymentsProcessed)
+	assert.True(t, summary.ConsumersDrained)
+	assert.Equal(t, int64(1), summary.OutboxPending)
+	assert.Equal(t, int64(3), summary.PaymentRetriesPending)
+	assert.True(t, summary.LogFlushed)
+	assert.Greater(t, summary.Duration, time.Duration(0))
+	assert.LessOrEqual(t, summary.Duration, time.Since(start))
+}
+
+// TestShutdownSummary_RedisUnavailable 测试Redis不可用时未完成数量标记为未知
+func TestShutdownSummary_RedisUnavailable(t *testing.T) {
+	mr := SetupTestRedis(t)
+	gs := &service.GoodService{RedisRepo: repository.NewRedisRepository()}
+	mr.Close()
+
+	summary := gs.Shutdown(context.Background(), time.Now())
+
+	assert.True(t, summary.ConsumersDrained)
+	assert.Equal(t, int64(-1), summary.OutboxPending)
+	assert.Equal(t, int64(-1), summary.PaymentRetriesPending)
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"log/slog"
+	"seckill_system/config"
+	"seckill_system/errs"
+	"seckill_system/model"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSoldOutCache_ShortCircuitsAfterSellOut 测试确认售罄后的下单请求直接拒绝，不再校验令牌和扣减库存
+func TestSoldOutCache_ShortCircuitsAfterSellOut(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+	gs.Seckill.SoldOutCacheSeconds = 60
+	require.NoError(t, gs.RedisRepo.SetGoodsStock(1, 1))
+
+	_, err := gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	require.NoError(t, err)
+	_, err = gs.SeckillWithToken(101, 1, mustSeckillToken(t, gs, 101, 1))
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+
+	// 其他实例写入的库存在售罄标记有效期内不可见，请求不读取也不扣减库存
+	require.NoError(t, gs.RedisRepo.SetGoodsStock(1, 5))
+	_, err = gs.SeckillWithToken(102, 1, absentToken)
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stock)
+
+	// 其他商品不受影响
+	_, err = gs.SeckillWithToken(102, 2, absentToken)
+	assert.ErrorIs(t, err, errs.ErrInvalidToken)
+}
+
+// TestSoldOutCache_DisabledByDefault 测试未配置缓存时间时每次请求都按正常流程处理
+func TestSoldOutCache_DisabledByDefault(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+	require.NoError(t, gs.RedisRepo.SetGoodsStock(1, 0))
+
+	_, err := gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+	_, err = gs.SeckillWithToken(101, 1, absentToken)
+	assert.ErrorIs(t, err, errs.ErrInvalidToken)
+}
+
+// TestSoldOutCache_LogsSampled 测试售罄后的拒绝日志按采样间隔记录，结果统计不受采样影响
+func TestSoldOutCache_LogsSampled(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+	gs.Seckill.SoldOutCacheSeconds = 60
+	gs.Seckill.SoldOutLogSample = 10
+	require.NoError(t, gs.RedisRepo.SetGoodsStock(1, 0))
+	logs := captureLogs(t)
+
+	_, err := gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+	for userId := int64(101); userId < 125; userId++ {
+		_, err := gs.SeckillWithToken(userId, 1, absentToken)
+		assert.ErrorIs(t, err, errs.ErrSoldOut)
+	}
+
+	// 第1、11、21次拒绝记录日志
+	assert.Equal(t, []slog.Level{slog.LevelInfo}, logs.levelsOf("Seckill failed"))
+	assert.Len(t, logs.levelsOf("Goods sold out, seckill refused from cache"), 2)
+	assert.Empty(t, logs.levelsOf("Invalid seckill token"))
+	assert.Equal(t, map[string]int64{errs.OutcomeSoldOut: 25}, gs.Outcomes()[model.AuditActionSeckill])
+}
+
+// TestSoldOutCache_ClearedOnCancel 测试取消订单归还库存后立即清除售罄标记
+func TestSoldOutCache_ClearedOnCancel(t *testing.T) {
+	gs := SetupTestService(t, WithPurchaseLimit(1), WithSeckillHandler()).Service
+	locks, err := service.NewLockFactory(config.LockConfig{Seckill: config.LockBackendRedis}, gs.RedisRepo, gs.RedisRepo)
+	require.NoError(t, err)
+	gs.Locks = locks
+	gs.Seckill.SoldOutCacheSeconds = 60
+	require.NoError(t, gs.RedisRepo.SetGoodsStock(1, 1))
+
+	_, err = gs.SeckillWithToken(100, 1, mustSeckillToken(t, gs, 100, 1))
+	require.NoError(t, err)
+	_, err = gs.SeckillWithToken(101, 1, mustSeckillToken(t, gs, 101, 1))
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+	_, err = gs.SeckillWithToken(101, 1, absentToken)
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+
+	cancelled, err := gs.CancelUnpaidOrders(100)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cancelled)
+
+	_, err = gs.SeckillWithToken(101, 1, mustSeckillToken(t, gs, 101, 1))
+	assert.NoError(t, err)
+}
+
+// TestSoldOutCache_Expires 测试售罄标记到期后失效，清除后重新开始日志采样
+func TestSoldOutCache_Expires(t *testing.T) {
+	var cache service.SoldOutCache
+	now := time.Now()
+	cache.Mark(1, now.Add(time.Second))
+
+	assert.True(t, cache.IsSoldOut(1, now))
+	assert.False(t, cache.IsSoldOut(2, now))
+	assert.False(t, cache.IsSoldOut(1, now.Add(time.Second)))
+	assert.False(t, cache.IsSoldOut(1, now))
+
+	sampled, count := cache.Sample(1, 3)
+	assert.True(t, sampled)
+	assert.Equal(t, int64(1), count)
+	sampled, _ = cache.Sample(1, 3)
+	assert.False(t, sampled)
+	cache.Clear(1)
+	sampled, count = cache.Sample(1, 3)
+	assert.True(t, sampled)
+	assert.Equal(t, int64(1), count)
+}
+
+// TestSoldOutCache_Validate 测试售罄缓存配置为负数时校验失败
+func TestSoldOutCache_Validate(t *testing.T) {
+	preserveAppConfig(t)
+
+	t.Setenv("SECKILL_SECKILL_SOLD_OUT_CACHE_SECONDS", "-1")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "sold_out_cache_seconds must not be negative")
+
+	t.Setenv("SECKILL_SECKILL_SOLD_OUT_CACHE_SECONDS", "2")
+	t.Setenv("SECKILL_SECKILL_SOLD_OUT_LOG_SAMPLE", "-1")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "sold_out_log_sample must not be negative")
+
+	t.Setenv("SECKILL_SECKILL_SOLD_OUT_LOG_SAMPLE", "10")
+	require.NoError(t, loadConfigDocument(t, 8100))
+	assert.Equal(t, int64(10), config.AppConfig.Seckill.SoldOutLogEvery())
+}
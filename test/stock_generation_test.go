@@ -0,0 +1,68 @@
+package test
+
+import (
+	"errors"
+	"seckill_system/errs"
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStockGeneration_StaleDecrementRejected 测试重新开始活动后携带旧代次的扣减被拒绝且不消耗新库存
+func TestStockGeneration_StaleDecrementRejected(t *testing.T) {
+	SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+
+	first, err := redisRepo.SetStockWithGeneration(1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), first)
+	remaining, err := redisRepo.DecrStockByGeneration(1, 2, first)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), remaining)
+
+	// 重新开始活动，代次递增
+	second, err := redisRepo.SetStockWithGeneration(1, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), second)
+	current, err := redisRepo.GetStockGeneration(1)
+	assert.NoError(t, err)
+	assert.Equal(t, second, current)
+
+	_, err = redisRepo.DecrStockByGeneration(1, 1, first)
+	assert.ErrorIs(t, err, repository.ErrStaleStockGeneration)
+	assert.True(t, errors.Is(err, errs.ErrForbidden))
+	stock, err := redisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), stock)
+
+	remaining, err = redisRepo.DecrStockByGeneration(1, 1, second)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), remaining)
+}
+
+// TestStockGeneration_DecrementErrors 测试带代次扣减的库存不存在、库存不足和未设置代次的情况
+func TestStockGeneration_DecrementErrors(t *testing.T) {
+	SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+
+	_, err := redisRepo.DecrStockByGeneration(1, 1, 0)
+	assert.ErrorIs(t, err, repository.ErrStockNotFound)
+
+	// 未经代次写入的库存视为第0代
+	require.NoError(t, redisRepo.SetGoodsStock(1, 1))
+	generation, err := redisRepo.GetStockGeneration(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), generation)
+	_, err = redisRepo.DecrStockByGeneration(1, 2, 0)
+	assert.ErrorIs(t, err, repository.ErrGoodsSoldOut)
+	_, err = redisRepo.DecrStockByGeneration(1, 1, 1)
+	assert.ErrorIs(t, err, repository.ErrStaleStockGeneration)
+	remaining, err := redisRepo.DecrStockByGeneration(1, 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), remaining)
+
+	_, err = redisRepo.DecrStockByGeneration(1, 0, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setMultiStock 写入多个商品的秒杀库存
+func setMultiStock(t *testing.T, repo *repository.RedisRepository, stocks map[int64]int64) {
+	t.Helper()
+	require.Empty(t, repo.SetGoodsStockBatch(stocks))
+}
+
+// assertGoodsStock 断言GetGoodsStock报告的商品库存
+func assertGoodsStock(t *testing.T, repo *repository.RedisRepository, expected map[int64]int64) {
+	t.Helper()
+	for goodsId, stock := range expected {
+		actual, err := repo.GetGoodsStock(goodsId)
+		require.NoError(t, err)
+		assert.Equal(t, stock, actual, "goods %d", goodsId)
+	}
+}
+
+// TestCheckAndDecrStockMulti_AllSucceed 测试库存全部充足时按数量扣减所有商品，扣减的是GetGoodsStock报告的秒杀库存
+func TestCheckAndDecrStockMulti_AllSucceed(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	setMultiStock(t, repo, map[int64]int64{1: 5, 2: 3, 3: 1})
+
+	ok, err := repo.CheckAndDecrStockMulti(map[int64]int64{1: 2, 2: 3, 3: 1})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assertGoodsStock(t, repo, map[int64]int64{1: 3, 2: 0, 3: 0})
+
+	// 单个商品的键位于同一槽位，由脚本原子扣减
+	ok, err = repo.CheckAndDecrStockMulti(map[int64]int64{1: 1})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assertGoodsStock(t, repo, map[int64]int64{1: 2})
+}
+
+// TestCheckAndDecrStockMulti_InsufficientRollsBackAll 测试任一商品库存不足或不存在时所有商品都不扣减
+func TestCheckAndDecrStockMulti_InsufficientRollsBackAll(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	setMultiStock(t, repo, map[int64]int64{1: 5, 2: 1, 3: 4})
+
+	ok, err := repo.CheckAndDecrStockMulti(map[int64]int64{1: 2, 2: 2, 3: 1})
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, repository.ErrGoodsSoldOut)
+	assert.ErrorContains(t, err, "goods 2")
+
+	ok, err = repo.CheckAndDecrStockMulti(map[int64]int64{1: 1, 4: 1})
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, repository.ErrStockNotFound)
+	assert.ErrorContains(t, err, "goods 4")
+
+	ok, err = repo.CheckAndDecrStockMulti(map[int64]int64{1: 6})
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, repository.ErrGoodsSoldOut)
+
+	assertGoodsStock(t, repo, map[int64]int64{1: 5, 2: 1, 3: 4})
+}
+
+// TestCheckAndDecrStockMulti_SharesSeckillStock 测试购物车扣减与单商品下单共用库存，不会合计超卖
+func TestCheckAndDecrStockMulti_SharesSeckillStock(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	setMultiStock(t, repo, map[int64]int64{1: 2, 2: 2})
+
+	_, err := repo.CheckAndDecrStock(1)
+	require.NoError(t, err)
+	ok, err := repo.CheckAndDecrStockMulti(map[int64]int64{1: 2, 2: 1})
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, repository.ErrGoodsSoldOut)
+
+	ok, err = repo.CheckAndDecrStockMulti(map[int64]int64{1: 1, 2: 1})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, err = repo.CheckAndDecrStock(1)
+	assert.ErrorIs(t, err, repository.ErrGoodsSoldOut)
+	assertGoodsStock(t, repo, map[int64]int64{1: 0, 2: 1})
+}
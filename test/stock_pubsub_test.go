@@ -0,0 +1,110 @@
+package test
+
+import (
+	"context"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// receiveStockUpdate 在超时时间内读取一条库存变更消息
+func receiveStockUpdate(t *testing.T, updates <-chan model.StockUpdate) (model.StockUpdate, bool) {
+	select {
+	case update, ok := <-updates:
+		return update, ok
+	case <-time.After(500 * time.Millisecond):
+		return model.StockUpdate{}, false
+	}
+}
+
+// TestRedisRepository_SubscribeStockChanges 测试扣减库存后订阅方收到变更消息
+func TestRedisRepository_SubscribeStockChanges(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	assert.NoError(t, repo.SetGoodsStock(1, 5))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates, err := repo.SubscribeStockChanges(ctx, 1)
+	assert.NoError(t, err)
+
+	ok, err := repo.CheckAndDecrStock(1)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	update, received := receiveStockUpdate(t, updates)
+	assert.True(t, received)
+	assert.Equal(t, int64(1), update.GoodsId)
+	assert.Equal(t, int64(4), update.Stock)
+	assert.Equal(t, int64(-1), update.Delta)
+	assert.False(t, update.Timestamp.IsZero())
+
+	ok, err = repo.CheckAndDecrStockBy(1, 3)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	update, received = receiveStockUpdate(t, updates)
+	assert.True(t, received)
+	assert.Equal(t, int64(1), update.Stock)
+	assert.Equal(t, int64(-3), update.Delta)
+}
+
+// TestRedisRepository_SubscribeStockChanges_NoPublishOnFailure 测试扣减失败时不发布消息
+func TestRedisRepository_SubscribeStockChanges_NoPublishOnFailure(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	assert.NoError(t, repo.SetGoodsStock(1, 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates, err := repo.SubscribeStockChanges(ctx, 1)
+	assert.NoError(t, err)
+
+	_, err = repo.CheckAndDecrStockBy(1, 2)
+	assert.ErrorIs(t, err, repository.ErrGoodsSoldOut)
+
+	_, received := receiveStockUpdate(t, updates)
+	assert.False(t, received)
+}
+
+// TestRedisRepository_SubscribeStockChanges_Reserve 测试预占库存签发令牌时发布消息
+func TestRedisRepository_SubscribeStockChanges_Reserve(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	assert.NoError(t, repo.SetGoodsStock(2, 3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates, err := repo.SubscribeStockChanges(ctx, 2)
+	assert.NoError(t, err)
+
+	_, _, err = repo.ReserveAndIssueToken(100, 2, time.Minute)
+	assert.NoError(t, err)
+
+	update, received := receiveStockUpdate(t, updates)
+	assert.True(t, received)
+	assert.Equal(t, int64(2), update.GoodsId)
+	assert.Equal(t, int64(2), update.Stock)
+	assert.Equal(t, int64(-1), update.Delta)
+}
+
+// TestRedisRepository_SubscribeStockChanges_Cancel 测试取消订阅后通道关闭
+func TestRedisRepository_SubscribeStockChanges_Cancel(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, err := repo.SubscribeStockChanges(ctx, 1)
+	assert.NoError(t, err)
+
+	cancel()
+	select {
+	case _, ok := <-updates:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("stock update channel not closed after cancel")
+	}
+}
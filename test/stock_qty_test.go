@@ -0,0 +1,111 @@
+package test
+
+import (
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRedisRepository_CheckAndDecrStockBy 测试按数量扣减库存
+func TestRedisRepository_CheckAndDecrStockBy(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	assert.NoError(t, repo.SetGoodsStock(1, 5))
+
+	ok, err := repo.CheckAndDecrStockBy(1, 3)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	stock, err := repo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stock)
+}
+
+// TestRedisRepository_CheckAndDecrStockBy_Insufficient 测试购买数量超过剩余库存时不扣减
+func TestRedisRepository_CheckAndDecrStockBy_Insufficient(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	assert.NoError(t, repo.SetGoodsStock(1, 2))
+
+	ok, err := repo.CheckAndDecrStockBy(1, 3)
+	assert.ErrorIs(t, err, repository.ErrGoodsSoldOut)
+	assert.False(t, ok)
+
+	// 库存保持不变
+	stock, err := repo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stock)
+
+	// 剩余库存恰好足够时可以扣减
+	ok, err = repo.CheckAndDecrStockBy(1, 2)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	stock, err = repo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), stock)
+}
+
+// TestRedisRepository_CheckAndDecrStockBy_InvalidQty 测试非法购买数量
+func TestRedisRepository_CheckAndDecrStockBy_InvalidQty(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	assert.NoError(t, repo.SetGoodsStock(1, 5))
+
+	_, err := repo.CheckAndDecrStockBy(1, 0)
+	assert.Error(t, err)
+
+	_, err = repo.CheckAndDecrStockBy(2, 1)
+	assert.ErrorIs(t, err, repository.ErrStockNotFound)
+}
+
+// TestRedisRepository_DecrStockBy_ReturnsRemaining 测试按数量扣减返回剩余库存，且不影响单件扣减命令
+func TestRedisRepository_DecrStockBy_ReturnsRemaining(t *testing.T) {
+	SetupTestRedis(t)
+	repo := repository.NewRedisRepository()
+	assert.NoError(t, repo.SetGoodsStock(1, 5))
+
+	remaining, err := repo.DecrStockBy(1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), remaining)
+
+	// 剩余库存不足时整体拒绝，不部分扣减
+	_, err = repo.DecrStockBy(1, 3)
+	assert.ErrorIs(t, err, repository.ErrGoodsSoldOut)
+
+	// 不传数量的单件扣减命令仍然可用
+	ok, err := repo.CheckAndDecrStock(1)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	remaining, err = repo.DecrStockBy(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), remaining)
+}
+
+// TestGoodRepository_OccReducePromotionByGoodsId 测试数据库按数量扣减促销库存
+func TestGoodRepository_OccReducePromotionByGoodsId(t *testing.T) {
+	db := SetupTestDB(t)
+	promotion := CreateTestPromotion(1, 3)
+	assert.NoError(t, db.Create(&promotion).Error)
+	repo := repository.NewGoodRepository()
+
+	// 数量超过剩余库存，不扣减
+	rows, err := repo.OccReducePromotionByGoodsId(db, 1, 0, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), rows)
+
+	rows, err = repo.OccReducePromotionByGoodsId(db, 1, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+
+	assert.NoError(t, db.Where("goods_id = ?", 1).First(&promotion).Error)
+	assert.Equal(t, int64(1), promotion.PsCount)
+	assert.Equal(t, int64(1), promotion.Version)
+
+	// 剩余1件，再购买2件失败
+	rows, err = repo.OccReducePromotionByGoodsId(db, 1, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), rows)
+}
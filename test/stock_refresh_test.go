@@ -0,0 +1,81 @@
+package test
+
+import (
+	"context"
+	"seckill_system/config"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupStockRefreshService 创建商品服务并写入商品1-3的进行中促销（库存10）和商品4的已结束促销
+func setupStockRefreshService(t *testing.T) (*service.GoodService, *miniredis.Miniredis) {
+	f := SetupTestService(t, WithGoods(10, 1, 2, 3), WithoutRedisStock(), WithSeckillHandler())
+	ended := CreateTestPromotion(4, 10)
+	ended.EndTime = model.NewTimestamp(time.Now().Add(-time.Minute))
+	assert.NoError(t, f.DB.Create(&ended).Error)
+	return f.Service, f.Redis
+}
+
+// TestRefreshStockCache_Reconcile 测试被淘汰的库存重新写入，偏低的库存保留，偏高的库存下调
+func TestRefreshStockCache_Reconcile(t *testing.T) {
+	gs, mr := setupStockRefreshService(t)
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(2, 4))  // 售卖中，已扣减6件
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(3, 15)) // 高于数据库库存
+
+	results, err := gs.RefreshStockCache(time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]string{
+		1: repository.StockReconcileRepopulated,
+		2: repository.StockReconcileKept,
+		3: repository.StockReconcileLowered,
+	}, results)
+
+	for goodsId, want := range map[int64]int64{1: 10, 2: 4, 3: 10} {
+		stock, err := gs.RedisRepo.GetGoodsStock(goodsId)
+		assert.NoError(t, err)
+		assert.Equal(t, want, stock, "goods %d", goodsId)
+	}
+	assert.False(t, mr.Exists(repository.StockKey(4)), "ended promotions should not be reloaded")
+}
+
+// TestRefreshStockCache_SubtractsPendingOrders 测试Redis-only模式未写库的订单数量从目标库存中扣除
+func TestRefreshStockCache_SubtractsPendingOrders(t *testing.T) {
+	gs, _ := setupStockRefreshService(t)
+	assert.NoError(t, gs.RedisRepo.PushPendingOrder(&model.PendingOrder{OrderId: "100-1-1", UserId: 100, GoodsId: 1, Quantity: 2}))
+	assert.NoError(t, gs.RedisRepo.PushPendingOrder(&model.PendingOrder{OrderId: "200-1-1", UserId: 200, GoodsId: 1, Quantity: 1}))
+	assert.NoError(t, gs.RedisRepo.SetGoodsStock(2, 7))
+
+	results, err := gs.RefreshStockCache(time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, repository.StockReconcileRepopulated, results[1])
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), stock)
+
+	// 实时库存低于数据库库存时不上调
+	stock, err = gs.RedisRepo.GetGoodsStock(2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), stock)
+}
+
+// TestRefreshStockCache_LockHeld 测试其他实例持有校准锁时跳过本轮校准
+func TestRefreshStockCache_LockHeld(t *testing.T) {
+	gs, mr := setupStockRefreshService(t)
+	_, locked, err := gs.RedisRepo.GetDistributedLock(context.Background(), gs.Locks.Key("stock_refresh_lock"), 30)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	results, err := gs.RefreshStockCache(time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+	assert.False(t, mr.Exists(repository.StockKey(1)))
+
+	assert.Error(t, config.SeckillConfig{StockRefreshSeconds: -1}.Validate())
+}
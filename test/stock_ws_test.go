@@ -0,0 +1,67 @@
+package test
+
+import (
+	"net/http/httptest"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"seckill_system/web/controller"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStockWebSocket_PushesDecrement 测试库存扣减后推送更新到已连接的客户端
+func TestStockWebSocket_PushesDecrement(t *testing.T) {
+	SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+	assert.NoError(t, redisRepo.SetGoodsStock(1, 5))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	goodController := &controller.GoodController{
+		GoodService: &service.GoodService{RedisRepo: redisRepo},
+	}
+	r.GET("/api/goods/:id/ws", goodController.StockWebSocket)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/goods/1/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	// 连接建立后先收到当前库存快照
+	var snapshot model.StockUpdate
+	assert.NoError(t, conn.ReadJSON(&snapshot))
+	assert.Equal(t, int64(1), snapshot.GoodsId)
+	assert.Equal(t, int64(5), snapshot.Stock)
+
+	// 模拟一次库存扣减
+	ok, err := redisRepo.CheckAndDecrStock(1)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	var update model.StockUpdate
+	assert.NoError(t, conn.ReadJSON(&update))
+	assert.Equal(t, int64(1), update.GoodsId)
+	assert.Equal(t, int64(4), update.Stock)
+	assert.Equal(t, int64(-1), update.Delta)
+}
+
+// TestStockWebSocket_InvalidId 测试非法商品ID返回400
+func TestStockWebSocket_InvalidId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	goodController := &controller.GoodController{GoodService: &service.GoodService{}}
+	r.GET("/api/goods/:id/ws", goodController.StockWebSocket)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/goods/abc/ws", nil))
+	assert.Equal(t, 400, w.Code)
+}
@@ -1,8 +1,24 @@
 package test
 
 import (
+	"path/filepath"
+	"seckill_system/config"
+	"seckill_system/global"
+	"seckill_system/handler"
 	"seckill_system/model"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"strconv"
+	"testing"
 	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/go-redis/redis/v8"
+	"github.com/segmentio/kafka-go"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 // Test helpers - 测试辅助函数包，提供创建测试数据的工具函数
@@ -15,15 +31,15 @@ import (
 //   - model.Goods: 填充了测试数据的商品对象
 func CreateTestGoods(goodsId int64) model.Goods {
 	return model.Goods{
-		GoodsId:        goodsId,         // 商品ID
-		Title:          "Test Book",     // 商品标题
-		SubTitle:       "Test Subtitle", // 商品副标题
-		OriginalCost:   100.0,           // 原价
-		CurrentPrice:   80.0,            // 当前价格
-		Discount:       0.8,             // 折扣率
-		IsFreeDelivery: 1,               // 是否包邮 (1-是, 0-否)
-		CategoryId:     1,               // 分类ID
-		LastUpdateTime: time.Now(),      // 最后更新时间
+		GoodsId:        goodsId,                        // 商品ID
+		Title:          "Test Book",                    // 商品标题
+		SubTitle:       "Test Subtitle",                // 商品副标题
+		OriginalCost:   100.0,                          // 原价
+		CurrentPrice:   80.0,                           // 当前价格
+		Discount:       0.8,                            // 折扣率
+		IsFreeDelivery: 1,                              // 是否包邮 (1-是, 0-否)
+		CategoryId:     1,                              // 分类ID
+		LastUpdateTime: model.NewTimestamp(time.Now()), // 最后更新时间
 	}
 }
 
@@ -37,14 +53,14 @@ func CreateTestGoods(goodsId int64) model.Goods {
 func CreateTestPromotion(goodsId int64, stock int64) model.PromotionSecKill {
 	now := time.Now()
 	return model.PromotionSecKill{
-		PsId:         1000 + goodsId,          // 促销ID (基于商品ID生成)
-		GoodsId:      goodsId,                 // 关联的商品ID
-		PsCount:      stock,                   // 促销库存数量
-		StartTime:    now.Add(-1 * time.Hour), // 开始时间 (1小时前)
-		EndTime:      now.Add(1 * time.Hour),  // 结束时间 (1小时后)
-		Status:       1,                       // 状态 (1-启用)
-		CurrentPrice: 50.0,                    // 促销价格
-		Version:      0,                       // 版本号 (用于乐观锁)
+		PsId:         1000 + goodsId,                              // 促销ID (基于商品ID生成)
+		GoodsId:      goodsId,                                     // 关联的商品ID
+		PsCount:      stock,                                       // 促销库存数量
+		StartTime:    model.NewTimestamp(now.Add(-1 * time.Hour)), // 开始时间 (1小时前)
+		EndTime:      model.NewTimestamp(now.Add(1 * time.Hour)),  // 结束时间 (1小时后)
+		Status:       1,                                           // 状态 (1-启用)
+		CurrentPrice: 50.0,                                        // 促销价格
+		Version:      0,                                           // 版本号 (用于乐观锁)
 	}
 }
 
@@ -57,9 +73,272 @@ func CreateTestPromotion(goodsId int64, stock int64) model.PromotionSecKill {
 //   - model.SuccessKilled: 填充了测试数据的秒杀成功订单对象
 func CreateTestOrder(userId, goodsId int64) model.SuccessKilled {
 	return model.SuccessKilled{
-		GoodsId:    goodsId,    // 商品ID
-		UserId:     userId,     // 用户ID
-		State:      0,          // 订单状态 (0-待支付)
-		CreateTime: time.Now(), // 创建时间
+		GoodsId:    goodsId,                        // 商品ID
+		UserId:     userId,                         // 用户ID
+		State:      0,                              // 订单状态 (0-待支付)
+		CreateTime: model.NewTimestamp(time.Now()), // 创建时间
+	}
+}
+
+// SetupTestDB 创建基于临时文件SQLite的测试数据库并替换全局数据库客户端
+// 参数:
+//   - t: 测试上下文，测试结束时自动关闭连接
+//
+// 返回:
+//   - *gorm.DB: 已完成表结构迁移的数据库连接
+func SetupTestDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	db := openTestDB(t)
+	if err := db.AutoMigrate(
+		&model.Goods{},
+		&model.PromotionSecKill{},
+		&model.SuccessKilled{},
+		&model.SeckillAuditLog{},
+	); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+// openTestDB 打开未建表的测试数据库并替换全局客户端，测试结束后恢复
+func openTestDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	// 使用WAL模式的临时文件库，允许事务外的并发读
+	dsn := filepath.Join(t.TempDir(), "seckill_test.db") + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent), // 测试时关闭SQL日志
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+
+	// 替换全局客户端，测试结束后恢复
+	previous := global.DBClient
+	global.DBClient = db
+	t.Cleanup(func() {
+		global.DBClient = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// SetupTestRedis 启动内存Redis服务并替换全局Redis集群客户端
+// 参数:
+//   - t: 测试上下文，测试结束时自动关闭服务和客户端
+//
+// 返回:
+//   - *miniredis.Miniredis: 内存Redis服务，可用于直接读写测试数据
+func SetupTestRedis(t testing.TB) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClusterClient(&redis.ClusterOptions{
+		Addrs: []string{mr.Addr()}, // miniredis以单节点集群方式响应CLUSTER SLOTS
+	})
+
+	// 替换全局客户端，测试结束后恢复
+	previous := global.RedisClient
+	global.RedisClient = client
+	t.Cleanup(func() {
+		global.RedisClient = previous
+		client.Close()
+	})
+	return mr
+}
+
+// SetupTestKafka 使用指向不可达地址的生产者和消费者替换全局Kafka客户端
+// 满足仓库构造时的初始化检查；发送消息会快速失败，订单消息进入发件箱
+// 参数:
+//   - t: 测试上下文，测试结束时关闭客户端并恢复原客户端
+func SetupTestKafka(t testing.TB) {
+	t.Helper()
+	unreachable := "127.0.0.1:1"
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(unreachable),
+		Topic:        "seckill_test_orders",
+		MaxAttempts:  1,
+		BatchTimeout: time.Millisecond,
+		WriteTimeout: time.Second,
+	}
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{unreachable},
+		Topic:   "seckill_test_orders",
+	})
+
+	// 替换全局客户端，测试结束后恢复
+	previousWriter, previousReader := global.KafkaWriter, global.KafkaReader
+	global.KafkaWriter, global.KafkaReader = writer, reader
+	t.Cleanup(func() {
+		global.KafkaWriter, global.KafkaReader = previousWriter, previousReader
+		writer.Close()
+		reader.Close()
+	})
+}
+
+// SetupTestEtcd 使用模拟KV替换全局Etcd客户端
+// 参数:
+//   - t: 测试上下文，测试结束时恢复原客户端
+//
+// 返回:
+//   - *MockEtcdKV: 模拟KV存储，可用于直接写入配置和黑名单
+func SetupTestEtcd(t testing.TB) *MockEtcdKV {
+	t.Helper()
+	kv := NewMockEtcdKV()
+
+	// 替换全局客户端，测试结束后恢复
+	previous := global.EtcdClient
+	global.EtcdClient = &clientv3.Client{KV: kv}
+	t.Cleanup(func() {
+		global.EtcdClient = previous
+	})
+	return kv
+}
+
+// ServiceFixture 商品服务测试环境
+type ServiceFixture struct {
+	Service *service.GoodService // 商品服务，GoodDB和RedisRepo总是可用
+	DB      *gorm.DB             // 测试数据库
+	Redis   *miniredis.Miniredis // 内存Redis
+	Etcd    *MockEtcdKV          // 模拟Etcd存储，未使用WithSeckillEnabled时为nil
+}
+
+// serviceOptions SetupTestService的可选配置
+type serviceOptions struct {
+	goodsIds       []int64                         // 创建商品和促销的商品ID
+	stock          int64                           // 促销库存和预加载的Redis库存
+	preloadStock   bool                            // 是否将库存写入Redis
+	promotion      []func(*model.PromotionSecKill) // 写入前修改促销
+	orders         []model.SuccessKilled           // 预置的订单
+	seckillEnabled bool                            // 是否开启秒杀并配置限流
+	rateLimit      int                             // 每分钟限流次数
+	seckill        bool                            // 是否创建下单处理器和Redis分布式锁
+	seckillConfig  config.SeckillConfig            // 秒杀配置
+}
+
+// ServiceOption 定制SetupTestService创建的测试环境，未指定时创建商品1及库存10的进行中促销并预加载Redis库存
+type ServiceOption func(*serviceOptions)
+
+// WithGoods 创建指定商品及库存为stock的进行中促销，不传商品ID时不创建任何商品
+func WithGoods(stock int64, goodsIds ...int64) ServiceOption {
+	return func(o *serviceOptions) {
+		o.stock = stock
+		o.goodsIds = goodsIds
+	}
+}
+
+// WithoutRedisStock 不预加载Redis库存，用于测试预加载流程
+func WithoutRedisStock() ServiceOption {
+	return func(o *serviceOptions) { o.preloadStock = false }
+}
+
+// WithPromotion 在写入数据库前修改每个促销
+func WithPromotion(mutate func(*model.PromotionSecKill)) ServiceOption {
+	return func(o *serviceOptions) { o.promotion = append(o.promotion, mutate) }
+}
+
+// WithPurchaseLimit 设置每个促销的每人限购件数
+func WithPurchaseLimit(limit int64) ServiceOption {
+	return WithPromotion(func(p *model.PromotionSecKill) { p.PurchaseLimit = limit })
+}
+
+// WithOrders 预置订单记录
+func WithOrders(orders ...model.SuccessKilled) ServiceOption {
+	return func(o *serviceOptions) { o.orders = append(o.orders, orders...) }
+}
+
+// WithSeckillEnabled 使用模拟Etcd开启秒杀，每个用户每分钟限流rateLimit次
+func WithSeckillEnabled(rateLimit int) ServiceOption {
+	return func(o *serviceOptions) {
+		o.seckillEnabled = true
+		o.rateLimit = rateLimit
+	}
+}
+
+// WithSeckillHandler 创建下单处理器，秒杀、预加载和维护锁均使用Redis后端
+func WithSeckillHandler() ServiceOption {
+	return func(o *serviceOptions) { o.seckill = true }
+}
+
+// WithSeckillConfig 替换秒杀配置，同时创建下单处理器
+func WithSeckillConfig(cfg config.SeckillConfig) ServiceOption {
+	return func(o *serviceOptions) {
+		o.seckill = true
+		o.seckillConfig = cfg
+	}
+}
+
+// WithRedisMode 使用Redis-only下单模式，同时创建下单处理器；需要其他秒杀配置时改用WithSeckillConfig
+func WithRedisMode() ServiceOption {
+	return func(o *serviceOptions) {
+		o.seckill = true
+		o.seckillConfig.Mode = config.SeckillModeRedis
+	}
+}
+
+// SetupTestService 准备数据库、Redis和商品服务，按选项写入商品、促销、订单和配置
+// 参数:
+//   - t: 测试上下文，测试结束时恢复全部全局客户端
+//   - opts: 与默认环境不同的配置
+//
+// 返回:
+//   - *ServiceFixture: 商品服务及其依赖的测试替身
+func SetupTestService(t testing.TB, opts ...ServiceOption) *ServiceFixture {
+	t.Helper()
+	o := &serviceOptions{goodsIds: []int64{1}, stock: 10, preloadStock: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	f := &ServiceFixture{DB: SetupTestDB(t), Redis: SetupTestRedis(t)}
+	f.Service = &service.GoodService{
+		GoodDB:    repository.NewGoodRepository(),
+		RedisRepo: repository.NewRedisRepository(),
+		Seckill:   o.seckillConfig,
+	}
+	for _, goodsId := range o.goodsIds {
+		good := CreateTestGoods(goodsId)
+		promotion := CreateTestPromotion(goodsId, o.stock)
+		for _, mutate := range o.promotion {
+			mutate(&promotion)
+		}
+		if err := f.DB.Create(&good).Error; err != nil {
+			t.Fatalf("failed to create test goods: %v", err)
+		}
+		if err := f.DB.Create(&promotion).Error; err != nil {
+			t.Fatalf("failed to create test promotion: %v", err)
+		}
+		if o.preloadStock {
+			if err := f.Service.RedisRepo.SetGoodsStock(goodsId, o.stock); err != nil {
+				t.Fatalf("failed to preload test stock: %v", err)
+			}
+		}
+	}
+	if len(o.orders) > 0 {
+		if err := f.DB.Create(&o.orders).Error; err != nil {
+			t.Fatalf("failed to create test orders: %v", err)
+		}
+	}
+
+	if o.seckillEnabled {
+		f.Etcd = SetupTestEtcd(t)
+		f.Etcd.Data[global.EtcdKeySeckillEnabled] = "true"
+		f.Etcd.Data[global.EtcdKeyRateLimit] = strconv.Itoa(o.rateLimit)
+		f.Service.EtcdRepo = repository.NewETCDRepository()
+	}
+	if o.seckill {
+		SetupTestKafka(t)
+		locks, err := service.NewLockFactory(config.LockConfig{
+			Seckill:     config.LockBackendRedis,
+			Preload:     config.LockBackendRedis,
+			Maintenance: config.LockBackendRedis,
+		}, f.Service.RedisRepo, f.Service.RedisRepo)
+		if err != nil {
+			t.Fatalf("failed to create test locks: %v", err)
+		}
+		f.Service.Locks = locks
+		f.Service.KafkaRepo = repository.NewKafkaRepository()
+		f.Service.SeckillHandler = handler.NewSeckillHandler()
 	}
+	return f
 }
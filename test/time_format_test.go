@@ -0,0 +1,112 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/config"
+	"seckill_system/model"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shanghaiTimeConfig 东八区、非默认格式的时间戳配置
+var shanghaiTimeConfig = config.TimeConfig{Timezone: "Asia/Shanghai", Format: "2006-01-02 15:04:05 -0700"}
+
+// applyTimeConfig 设置时间戳配置，测试结束后恢复默认值
+func applyTimeConfig(t *testing.T, tc config.TimeConfig) {
+	t.Helper()
+	assert.NoError(t, config.ApplyTimeConfig(tc))
+	t.Cleanup(func() { config.ApplyTimeConfig(config.TimeConfig{}) })
+}
+
+// TestTimeConfig_Defaults 测试未配置时默认使用UTC和RFC3339
+func TestTimeConfig_Defaults(t *testing.T) {
+	tc := config.TimeConfig{}
+	assert.NoError(t, tc.Validate())
+	assert.Equal(t, config.DefaultTimezone, tc.Timezone)
+	assert.Equal(t, config.DefaultTimeFormat, tc.Format)
+
+	local := time.Date(2025, 8, 29, 22, 30, 56, 0, time.FixedZone("CST", 8*3600))
+	assert.Equal(t, "2025-08-29T14:30:56Z", config.FormatTime(local))
+	assert.Equal(t, time.UTC, config.TimeLocation())
+
+	invalid := config.TimeConfig{Timezone: "Mars/Olympus"}
+	assert.ErrorContains(t, invalid.Validate(), "Mars/Olympus")
+	assert.Error(t, config.ApplyTimeConfig(invalid))
+}
+
+// TestTimeConfig_LogOutput 测试日志时间和时间类型属性按配置的时区和格式输出
+func TestTimeConfig_LogOutput(t *testing.T) {
+	applyTimeConfig(t, shanghaiTimeConfig)
+	sink, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer sink.Close()
+
+	handler, err := config.NewNetworkLogHandler(config.LogNetworkConfig{
+		Target:  config.LogTargetUDP,
+		Address: sink.LocalAddr().String(),
+	}, slog.LevelInfo)
+	assert.NoError(t, err)
+	logged := time.Date(2025, 8, 29, 6, 30, 56, 0, time.UTC)
+	record := slog.NewRecord(logged, slog.LevelInfo, "Token issued", 0)
+	record.AddAttrs(slog.Time("expire_at", logged.Add(time.Hour)))
+	assert.NoError(t, handler.Handle(context.Background(), record))
+
+	buf := make([]byte, 4096)
+	sink.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := sink.ReadFrom(buf)
+	assert.NoError(t, err)
+	var fields map[string]any
+	assert.NoError(t, json.Unmarshal(buf[:n], &fields))
+	assert.Equal(t, "2025-08-29 14:30:56 +0800", fields["time"])
+	assert.Equal(t, "2025-08-29 15:30:56 +0800", fields["expire_at"])
+}
+
+// TestTimestamp_JSON 测试model.Timestamp按配置的时区和格式序列化，并能解析配置格式和RFC3339
+func TestTimestamp_JSON(t *testing.T) {
+	applyTimeConfig(t, shanghaiTimeConfig)
+	created := time.Date(2025, 8, 29, 6, 30, 56, 123456789, time.UTC)
+	status := model.OrderStatus{OrderId: "9007199254740993", CreateTime: model.NewTimestamp(created)}
+
+	data, err := json.Marshal(status)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"create_time":"2025-08-29 14:30:56 +0800"`)
+
+	var decoded model.OrderStatus
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, created.Truncate(time.Second).Equal(decoded.CreateTime.Time), "create time %v", decoded.CreateTime)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"create_time":"2025-08-29T06:30:56.123456789Z"}`), &decoded))
+	assert.True(t, created.Equal(decoded.CreateTime.Time), "create time %v", decoded.CreateTime)
+	assert.Error(t, json.Unmarshal([]byte(`{"create_time":"not a timestamp"}`), &decoded))
+}
+
+// TestTimestamp_NonTimeStringUnchanged 测试形如RFC3339的普通字符串（如封禁原因）在接口响应中原样输出
+func TestTimestamp_NonTimeStringUnchanged(t *testing.T) {
+	applyTimeConfig(t, shanghaiTimeConfig)
+	gin.SetMode(gin.TestMode)
+	gs, _, _ := setupBlacklistImport(t)
+	r := router.NewAdminRouter(&controller.GoodController{GoodService: gs})
+
+	const reason = "2025-08-29T06:30:56Z"
+	body := `[{"user_id":10,"reason":"` + reason + `","duration":"1h"}]`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/blacklist/import?admin=1", bytes.NewBufferString(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/blacklist/export?admin=1", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"reason":"`+reason+`"`)
+}
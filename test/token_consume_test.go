@@ -0,0 +1,88 @@
+package test
+
+import (
+	"encoding/json"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVerifySeckillToken_ConcurrentConsume 测试同一令牌的并发消费只有一个成功
+func TestVerifySeckillToken_ConcurrentConsume(t *testing.T) {
+	SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+	tokenId, err := redisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+
+	const consumers = 50
+	var wg sync.WaitGroup
+	var succeeded, failed atomic.Int64
+	start := make(chan struct{})
+	for i := 0; i < consumers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			valid, err := redisRepo.VerifySeckillToken(tokenId, 100, 1)
+			if err != nil {
+				failed.Add(1)
+				return
+			}
+			if valid {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), succeeded.Load())
+	assert.Equal(t, int64(0), failed.Load()) // 落败的请求视为令牌不存在，而不是错误
+
+	valid, err := redisRepo.VerifySeckillToken(tokenId, 100, 1)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
+
+// TestVerifySeckillToken_MismatchKeepsToken 测试用户不匹配时令牌不被消费
+func TestVerifySeckillToken_MismatchKeepsToken(t *testing.T) {
+	SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+	tokenId, err := redisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+
+	valid, err := redisRepo.VerifySeckillToken(tokenId, 200, 1)
+	assert.ErrorContains(t, err, "token mismatch")
+	assert.False(t, valid)
+
+	valid, err = redisRepo.VerifySeckillToken(tokenId, 100, 1)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+// TestVerifySeckillToken_Expired 测试过期令牌被消费并返回错误
+func TestVerifySeckillToken_Expired(t *testing.T) {
+	mr := SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+
+	// 令牌数据中的过期时间已过但键仍存在（Redis过期存在延迟）
+	data, err := json.Marshal(model.RedisSeckillToken{
+		TokenId:  expiredToken,
+		UserId:   100,
+		GoodsId:  1,
+		ExpireAt: time.Now().Add(-time.Second),
+	})
+	assert.NoError(t, err)
+	key := repository.SeckillTokenKey(1, expiredToken)
+	assert.NoError(t, mr.Set(key, string(data)))
+
+	valid, err := redisRepo.VerifySeckillToken(expiredToken, 100, 1)
+	assert.ErrorContains(t, err, "token expired")
+	assert.False(t, valid)
+	assert.False(t, mr.Exists(key))
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/errs"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDeleteSeckillToken_VerificationFails 测试删除令牌后验证返回不存在
+func TestDeleteSeckillToken_VerificationFails(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+	tokenId, err := gs.RedisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+
+	existed, err := gs.ExpireSeckillToken(1, tokenId)
+	assert.NoError(t, err)
+	assert.True(t, existed)
+
+	valid, err := gs.RedisRepo.VerifySeckillToken(tokenId, 100, 1)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	_, err = gs.SeckillWithToken(100, 1, tokenId)
+	assert.ErrorIs(t, err, errs.ErrTokenNotFound)
+
+	// 再次删除时令牌已不存在
+	existed, err = gs.ExpireSeckillToken(1, tokenId)
+	assert.NoError(t, err)
+	assert.False(t, existed)
+
+	stock, err := gs.RedisRepo.GetGoodsStock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), stock)
+}
+
+// TestExpireSeckillTokenEndpoint 测试管理员强制失效令牌接口
+func TestExpireSeckillTokenEndpoint(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+	tokenId, err := gs.RedisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+	r := router.NewAdminRouter(&controller.GoodController{GoodService: gs})
+
+	expire := func(gid, token string) (int, bool) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST",
+			fmt.Sprintf("/api/admin/seckill/token/expire?admin=1&gid=%s&token=%s", gid, token), nil))
+		var body struct {
+			Data struct {
+				Existed bool `json:"existed"`
+			} `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body.Data.Existed
+	}
+
+	code, existed := expire("1", tokenId)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, existed)
+
+	code, existed = expire("1", tokenId)
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, existed)
+
+	code, _ = expire("abc", tokenId)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = expire("1", "not-a-token")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, http.StatusForbidden, serve(r, "POST", "/api/admin/seckill/token/expire?gid=1&token="+tokenId))
+}
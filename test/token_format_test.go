@@ -0,0 +1,118 @@
+package test
+
+import (
+	"context"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/repository"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+// 格式合法但未在Redis中签发的令牌
+const (
+	absentToken  = "missingtoken00000000000000000000"
+	expiredToken = "expiredtoken00000000000000000000"
+)
+
+// commandRecorder 记录经过Redis客户端的命令
+type commandRecorder struct {
+	commands []string // 已执行的命令名称
+}
+
+// BeforeProcess 记录单条命令
+func (h *commandRecorder) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	h.commands = append(h.commands, cmd.Name())
+	return ctx, nil
+}
+
+// AfterProcess 单条命令执行后不做处理
+func (h *commandRecorder) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return nil
+}
+
+// BeforeProcessPipeline 记录管道中的命令
+func (h *commandRecorder) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	for _, cmd := range cmds {
+		h.commands = append(h.commands, cmd.Name())
+	}
+	return ctx, nil
+}
+
+// AfterProcessPipeline 管道执行后不做处理
+func (h *commandRecorder) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
+
+// malformedTokens 长度或字符集不合法的令牌
+var malformedTokens = []string{
+	"",
+	"abc",
+	strings.Repeat("a", 31),
+	strings.Repeat("a", 33),
+	strings.Repeat("a", 4096),
+	strings.Repeat("a", 31) + "-",
+	strings.Repeat("a", 31) + "}",
+	strings.Repeat("a", 30) + "中",
+}
+
+// TestTokenFormat_MalformedRejectedWithoutRedis 测试格式不合法的令牌在访问Redis前被拒绝
+func TestTokenFormat_MalformedRejectedWithoutRedis(t *testing.T) {
+	SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+	recorder := &commandRecorder{}
+	global.RedisClient.AddHook(recorder)
+
+	for _, token := range malformedTokens {
+		_, err := redisRepo.VerifyUserToken(token)
+		assert.ErrorIs(t, err, errs.ErrTokenMalformed, token)
+		assert.ErrorIs(t, err, errs.ErrInvalidToken, token)
+
+		valid, err := redisRepo.VerifySeckillToken(token, 100, 1)
+		assert.ErrorIs(t, err, errs.ErrTokenMalformed, token)
+		assert.False(t, valid)
+	}
+	assert.Empty(t, recorder.commands)
+
+	// 格式合法的令牌仍然查询Redis
+	_, err := redisRepo.VerifyUserToken(absentToken)
+	assert.ErrorIs(t, err, errs.ErrTokenNotFound)
+	assert.NotEmpty(t, recorder.commands)
+}
+
+// TestTokenFormat_ConfiguredLength 测试令牌按配置长度签发和校验
+func TestTokenFormat_ConfiguredLength(t *testing.T) {
+	SetupTestRedis(t)
+	global.RedisTokenLength = 48
+	t.Cleanup(func() { global.RedisTokenLength = 0 })
+	redisRepo := repository.NewRedisRepository()
+
+	userToken, err := redisRepo.GenerateUserToken(100)
+	assert.NoError(t, err)
+	assert.Len(t, userToken, 48)
+	userId, err := redisRepo.VerifyUserToken(userToken)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), userId)
+
+	tokenId, err := redisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+	assert.Len(t, tokenId, 48)
+	valid, err := redisRepo.VerifySeckillToken(tokenId, 100, 1)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// 默认长度的令牌在配置长度下视为不合法
+	_, err = redisRepo.VerifyUserToken(absentToken)
+	assert.ErrorIs(t, err, errs.ErrTokenMalformed)
+}
+
+// TestValidTokenFormat 测试令牌格式校验规则
+func TestValidTokenFormat(t *testing.T) {
+	assert.True(t, repository.ValidTokenFormat(absentToken, repository.DefaultTokenLength))
+	for _, token := range malformedTokens {
+		assert.False(t, repository.ValidTokenFormat(token, repository.DefaultTokenLength), token)
+	}
+}
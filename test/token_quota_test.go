@@ -0,0 +1,110 @@
+package test
+
+import (
+	"context"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSeckillTokenQuota_RejectsBeyondCap 测试同一用户在活动中超过签发上限的令牌请求被拒绝
+func TestSeckillTokenQuota_RejectsBeyondCap(t *testing.T) {
+	gs, kv := setupGoodsAllowlistService(t, model.GoodsAllowlist{})
+	kv.Data[global.EtcdKeyRateLimit] = "100" // 放宽限流，只验证签发上限
+	gs.Seckill.MaxTokensPerUser = 3
+
+	for i := 0; i < 3; i++ {
+		tokenId, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+		require.NoError(t, err, "request %d", i+1)
+		assert.NotEmpty(t, tokenId)
+	}
+
+	_, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	assert.ErrorIs(t, err, errs.ErrTokenQuotaExceeded)
+	assert.ErrorIs(t, err, errs.ErrForbidden)
+	assert.NotErrorIs(t, err, errs.ErrRateLimited)
+
+	// 其他用户不受影响
+	_, err = gs.GenerateSeckillToken(101, 1, "203.0.113.7")
+	assert.NoError(t, err)
+}
+
+// TestSeckillTokenQuota_ExpiresWithActivity 测试签发计数保留到活动结束
+func TestSeckillTokenQuota_ExpiresWithActivity(t *testing.T) {
+	gs, kv := setupGoodsAllowlistService(t, model.GoodsAllowlist{})
+	kv.Data[global.EtcdKeyRateLimit] = "100"
+	gs.Seckill.MaxTokensPerUser = 1
+
+	_, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+	require.NoError(t, err)
+	ttl, err := global.RedisClient.TTL(context.Background(), repository.SeckillTokenQuotaKey(100, 1)).Result()
+	require.NoError(t, err)
+	assert.True(t, ttl > 59*time.Minute && ttl <= time.Hour, "quota ttl %v should follow activity end", ttl)
+}
+
+// TestSeckillTokenQuota_ReleaseGuarded 测试归还签发次数不会产生负数计数，也不会重置计数的过期时间
+func TestSeckillTokenQuota_ReleaseGuarded(t *testing.T) {
+	SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+	ctx := context.Background()
+	key := repository.SeckillTokenQuotaKey(100, 1)
+
+	// 计数已过期时归还不创建key
+	require.NoError(t, redisRepo.ReleaseSeckillTokenQuota(100, 1))
+	exists, err := global.RedisClient.Exists(ctx, key).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists)
+
+	allowed, err := redisRepo.ReserveSeckillTokenQuota(100, 1, 1, time.Hour)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	// 归还到0后保留原过期时间，重复归还不会低于0
+	require.NoError(t, redisRepo.ReleaseSeckillTokenQuota(100, 1))
+	require.NoError(t, redisRepo.ReleaseSeckillTokenQuota(100, 1))
+	count, err := global.RedisClient.Get(ctx, key).Int64()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	ttl, err := global.RedisClient.TTL(ctx, key).Result()
+	require.NoError(t, err)
+	assert.True(t, ttl > 59*time.Minute && ttl <= time.Hour, "quota ttl %v should be kept", ttl)
+
+	// 再次占用不重置过期时间，上限仍然生效
+	allowed, err = redisRepo.ReserveSeckillTokenQuota(100, 1, 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	ttl, err = global.RedisClient.TTL(ctx, key).Result()
+	require.NoError(t, err)
+	assert.True(t, ttl > 59*time.Minute, "quota ttl %v should not be reset", ttl)
+	allowed, err = redisRepo.ReserveSeckillTokenQuota(100, 1, 1, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestSeckillTokenQuota_Unlimited 测试未配置签发上限时不限制次数
+func TestSeckillTokenQuota_Unlimited(t *testing.T) {
+	gs, kv := setupGoodsAllowlistService(t, model.GoodsAllowlist{})
+	kv.Data[global.EtcdKeyRateLimit] = "100"
+
+	for i := 0; i < 5; i++ {
+		_, err := gs.GenerateSeckillToken(100, 1, "203.0.113.7")
+		require.NoError(t, err, "request %d", i+1)
+	}
+}
+
+// TestSeckillTokenQuota_Validate 测试签发上限为负数时配置校验失败
+func TestSeckillTokenQuota_Validate(t *testing.T) {
+	preserveAppConfig(t)
+
+	t.Setenv("SECKILL_SECKILL_MAX_TOKENS_PER_USER", "-1")
+	assert.ErrorContains(t, loadConfigDocument(t, 8100), "max_tokens_per_user must not be negative")
+
+	t.Setenv("SECKILL_SECKILL_MAX_TOKENS_PER_USER", "3")
+	require.NoError(t, loadConfigDocument(t, 8100))
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// grantOnlyLease 只支持创建租约的模拟Lease，用于测试写入带租约的黑名单
+type grantOnlyLease struct {
+	clientv3.Lease
+}
+
+// Grant 返回固定ID的租约
+func (grantOnlyLease) Grant(ctx context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error) {
+	return &clientv3.LeaseGrantResponse{ID: 1, TTL: ttl}, nil
+}
+
+// TestRevokeUserTokens 测试吊销用户全部令牌后验证失败，其他用户的令牌不受影响
+func TestRevokeUserTokens(t *testing.T) {
+	mr := SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+
+	userToken, err := redisRepo.GenerateUserToken(100)
+	assert.NoError(t, err)
+	seckillToken1, err := redisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+	seckillToken2, err := redisRepo.GenerateSeckillToken(100, 2)
+	assert.NoError(t, err)
+	otherToken, err := redisRepo.GenerateSeckillToken(200, 1)
+	assert.NoError(t, err)
+	assert.True(t, mr.Exists(repository.UserTokensIndexKey(100)))
+
+	revoked, err := redisRepo.RevokeUserTokens(100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), revoked)
+	assert.False(t, mr.Exists(repository.UserTokensIndexKey(100)))
+
+	_, err = redisRepo.VerifyUserToken(userToken)
+	assert.ErrorIs(t, err, errs.ErrTokenNotFound)
+	for _, tokenId := range []string{seckillToken1, seckillToken2} {
+		valid, err := redisRepo.VerifySeckillToken(tokenId, 100, 1)
+		assert.NoError(t, err)
+		assert.False(t, valid)
+	}
+	valid, err := redisRepo.VerifySeckillToken(otherToken, 200, 1)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// 没有可吊销的令牌
+	revoked, err = redisRepo.RevokeUserTokens(100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), revoked)
+}
+
+// TestRevokeUserTokens_SkipsConsumedTokens 测试已消费或过期的令牌不计入吊销数量
+func TestRevokeUserTokens_SkipsConsumedTokens(t *testing.T) {
+	SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+
+	consumed, err := redisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+	_, err = redisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+	valid, err := redisRepo.VerifySeckillToken(consumed, 100, 1)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	revoked, err := redisRepo.RevokeUserTokens(100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), revoked)
+}
+
+// TestAddToBlacklist_RevokesTokens 测试加入黑名单时吊销用户已签发的令牌
+func TestAddToBlacklist_RevokesTokens(t *testing.T) {
+	SetupTestRedis(t)
+	SetupTestEtcd(t)
+	global.EtcdClient.Lease = grantOnlyLease{}
+	gs := &service.GoodService{
+		RedisRepo: repository.NewRedisRepository(),
+		EtcdRepo:  repository.NewETCDRepository(),
+	}
+
+	userToken, err := gs.RedisRepo.GenerateUserToken(100)
+	assert.NoError(t, err)
+	seckillToken, err := gs.RedisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+
+	needsReview, err := gs.AddToBlacklist(100, "abuse", time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, needsReview)
+
+	_, err = gs.RedisRepo.VerifyUserToken(userToken)
+	assert.ErrorIs(t, err, errs.ErrTokenNotFound)
+	valid, err := gs.RedisRepo.VerifySeckillToken(seckillToken, 100, 1)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
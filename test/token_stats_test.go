@@ -0,0 +1,81 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"seckill_system/errs"
+	"seckill_system/model"
+	"seckill_system/web/controller"
+	"seckill_system/web/router"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSeckillTokenStats_CountsIssuedAndRedeemed 测试按商品统计签发和兑换的令牌数，验证失败的令牌不计为兑换
+func TestSeckillTokenStats_CountsIssuedAndRedeemed(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+
+	var tokens []string
+	for userId := int64(100); userId < 104; userId++ {
+		tokens = append(tokens, mustSeckillToken(t, gs, userId, 1))
+	}
+	mustSeckillToken(t, gs, 100, 2)
+
+	for i, userId := range []int64{100, 101} {
+		valid, err := gs.VerifySeckillToken(tokens[i], userId, 1)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	}
+	// 重复兑换和用户不匹配都不计入兑换数
+	valid, err := gs.VerifySeckillToken(tokens[0], 100, 1)
+	require.NoError(t, err)
+	assert.False(t, valid)
+	_, err = gs.VerifySeckillToken(tokens[2], 999, 1)
+	assert.ErrorIs(t, err, errs.ErrTokenMismatch)
+
+	stats, err := gs.GetSeckillTokenStats(1)
+	require.NoError(t, err)
+	assert.Equal(t, model.SeckillTokenStats{GoodsId: 1, Issued: 4, Redeemed: 2, Ratio: 2}, stats)
+
+	stats, err = gs.GetSeckillTokenStats(2)
+	require.NoError(t, err)
+	assert.Equal(t, model.SeckillTokenStats{GoodsId: 2, Issued: 1}, stats)
+
+	stats, err = gs.GetSeckillTokenStats(3)
+	require.NoError(t, err)
+	assert.Equal(t, model.SeckillTokenStats{GoodsId: 3}, stats)
+}
+
+// TestSeckillTokenStats_Ratio 测试签发数与兑换数之比的计算
+func TestSeckillTokenStats_Ratio(t *testing.T) {
+	assert.Equal(t, 0.0, model.NewSeckillTokenStats(1, 5, 0).Ratio)
+	assert.Equal(t, 2.5, model.NewSeckillTokenStats(1, 5, 2).Ratio)
+	assert.Equal(t, 1.0, model.NewSeckillTokenStats(1, 3, 3).Ratio)
+}
+
+// TestSeckillTokenStatsEndpoint 测试管理员令牌统计接口
+func TestSeckillTokenStatsEndpoint(t *testing.T) {
+	gs := SetupTestService(t, WithRedisMode()).Service
+	for userId := int64(100); userId < 103; userId++ {
+		mustSeckillToken(t, gs, userId, 1)
+	}
+	valid, err := gs.VerifySeckillToken(mustSeckillToken(t, gs, 103, 1), 103, 1)
+	require.NoError(t, err)
+	require.True(t, valid)
+	r := router.NewAdminRouter(&controller.GoodController{GoodService: gs})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/seckill/token/stats/1?admin=1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data model.SeckillTokenStats `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, model.SeckillTokenStats{GoodsId: 1, Issued: 4, Redeemed: 1, Ratio: 4}, body.Data)
+
+	assert.Equal(t, http.StatusBadRequest, serve(r, "GET", "/api/admin/seckill/token/stats/abc?admin=1"))
+	assert.Equal(t, http.StatusForbidden, serve(r, "GET", "/api/admin/seckill/token/stats/1"))
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"seckill_system/config"
+	"seckill_system/global"
+	"seckill_system/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// TestTokenConfig_ParseAndDefaults 测试令牌有效期从时长字符串解析，未配置时使用默认值
+func TestTokenConfig_ParseAndDefaults(t *testing.T) {
+	var tc config.TokenConfig
+	assert.NoError(t, yaml.Unmarshal([]byte("user_token_ttl: 2h\nseckill_token_ttl: 90s\n"), &tc))
+	assert.NoError(t, tc.Validate())
+	assert.Equal(t, 2*time.Hour, tc.UserTTL())
+	assert.Equal(t, 90*time.Second, tc.SeckillTTL())
+
+	var empty config.TokenConfig
+	assert.NoError(t, empty.Validate())
+	assert.Equal(t, config.DefaultUserTokenTTL, empty.UserTTL())
+	assert.Equal(t, config.DefaultSeckillTokenTTL, empty.SeckillTTL())
+
+	assert.Error(t, yaml.Unmarshal([]byte("user_token_ttl: forever\n"), &tc))
+	assert.ErrorContains(t, config.TokenConfig{SeckillTokenTTL: 500 * time.Millisecond}.Validate(), "seckill_token_ttl")
+	assert.ErrorContains(t, config.TokenConfig{UserTokenTTL: -time.Hour}.Validate(), "user_token_ttl")
+}
+
+// TestRedisRepository_UsesConfiguredTokenTTL 测试签发的令牌使用配置的有效期
+func TestRedisRepository_UsesConfiguredTokenTTL(t *testing.T) {
+	mr := SetupTestRedis(t)
+	previousUser, previousSeckill := global.UserTokenTTL, global.SeckillTokenTTL
+	global.UserTokenTTL, global.SeckillTokenTTL = 2*time.Hour, 90*time.Second
+	t.Cleanup(func() { global.UserTokenTTL, global.SeckillTokenTTL = previousUser, previousSeckill })
+	redisRepo := repository.NewRedisRepository()
+
+	userToken, err := redisRepo.GenerateUserToken(100)
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(2*time.Hour), float64(mr.TTL(repository.UserTokenKey(userToken))), float64(time.Second))
+
+	seckillToken, err := redisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(90*time.Second), float64(mr.TTL(repository.SeckillTokenKey(1, seckillToken))), float64(time.Second))
+
+	// 令牌在配置的有效期后过期
+	mr.FastForward(91 * time.Second)
+	valid, err := redisRepo.VerifySeckillToken(seckillToken, 100, 1)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+	_, err = redisRepo.VerifyUserToken(userToken)
+	assert.NoError(t, err)
+}
+
+// TestRedisRepository_DefaultTokenTTL 测试未配置时保持原有的有效期
+func TestRedisRepository_DefaultTokenTTL(t *testing.T) {
+	mr := SetupTestRedis(t)
+	redisRepo := repository.NewRedisRepository()
+
+	userToken, err := redisRepo.GenerateUserToken(100)
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(24*time.Hour), float64(mr.TTL(repository.UserTokenKey(userToken))), float64(time.Second))
+	seckillToken, err := redisRepo.GenerateSeckillToken(100, 1)
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(30*time.Minute), float64(mr.TTL(repository.SeckillTokenKey(1, seckillToken))), float64(time.Second))
+}
+
+// TestRedisRepository_TokenTTLJitter 测试配置抖动后令牌有效期在基准值±抖动比例内分散
+func TestRedisRepository_TokenTTLJitter(t *testing.T) {
+	mr := SetupTestRedis(t)
+	previousUser, previousSeckill, previousJitter := global.UserTokenTTL, global.SeckillTokenTTL, global.TokenTTLJitter
+	global.UserTokenTTL, global.SeckillTokenTTL, global.TokenTTLJitter = time.Hour, 10*time.Minute, 10
+	t.Cleanup(func() {
+		global.UserTokenTTL, global.SeckillTokenTTL, global.TokenTTLJitter = previousUser, previousSeckill, previousJitter
+	})
+	redisRepo := repository.NewRedisRepository()
+
+	userTTLs := make(map[time.Duration]bool)
+	seckillTTLs := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		userToken, err := redisRepo.GenerateUserToken(int64(i))
+		assert.NoError(t, err)
+		userTTL := mr.TTL(repository.UserTokenKey(userToken)).Round(time.Second)
+		assert.GreaterOrEqual(t, userTTL, 54*time.Minute)
+		assert.LessOrEqual(t, userTTL, 66*time.Minute)
+		userTTLs[userTTL] = true
+
+		seckillToken, err := redisRepo.GenerateSeckillToken(int64(i), 1)
+		assert.NoError(t, err)
+		seckillTTL := mr.TTL(repository.SeckillTokenKey(1, seckillToken)).Round(time.Second)
+		assert.GreaterOrEqual(t, seckillTTL, 9*time.Minute)
+		assert.LessOrEqual(t, seckillTTL, 11*time.Minute)
+		seckillTTLs[seckillTTL] = true
+
+		// 令牌数据中的过期时间与键的有效期一致，抖动后令牌仍可正常校验
+		userId, err := redisRepo.VerifyUserToken(userToken)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(i), userId)
+	}
+	assert.Greater(t, len(userTTLs), 1, "user token expiries should be spread out")
+	assert.Greater(t, len(seckillTTLs), 1, "seckill token expiries should be spread out")
+}
+
+// TestTokenConfig_JitterValidation 测试抖动百分比须在0到50之间
+func TestTokenConfig_JitterValidation(t *testing.T) {
+	assert.NoError(t, config.TokenConfig{TTLJitterPercent: 10}.Validate())
+	assert.NoError(t, config.TokenConfig{TTLJitterPercent: 50}.Validate())
+	assert.ErrorContains(t, config.TokenConfig{TTLJitterPercent: -1}.Validate(), "ttl_jitter_percent")
+	assert.ErrorContains(t, config.TokenConfig{TTLJitterPercent: 51}.Validate(), "ttl_jitter_percent")
+}
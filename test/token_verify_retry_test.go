@@ -0,0 +1,122 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"seckill_system/config"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/repository"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// errRedisBlip 模拟Redis集群瞬时故障
+var errRedisBlip = errors.New("CLUSTERDOWN The cluster is down")
+
+// flakyGetHook 使前failures次GET命令失败，并记录GET命令执行次数
+type flakyGetHook struct {
+	failures int // 剩余需要失败的GET次数
+	gets     int // 已执行的GET次数
+}
+
+// BeforeProcess 对GET命令注入瞬时错误
+func (h *flakyGetHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	if cmd.Name() != "get" {
+		return ctx, nil
+	}
+	h.gets++
+	if h.failures > 0 {
+		h.failures--
+		return ctx, errRedisBlip
+	}
+	return ctx, nil
+}
+
+// AfterProcess 单条命令执行后不做处理
+func (h *flakyGetHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	return nil
+}
+
+// BeforeProcessPipeline 管道命令不注入错误
+func (h *flakyGetHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+// AfterProcessPipeline 管道执行后不做处理
+func (h *flakyGetHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	return nil
+}
+
+// setupTokenVerifyRetry 配置令牌校验重试次数和退避时间，测试结束后恢复
+func setupTokenVerifyRetry(t *testing.T, attempts int, backoff time.Duration) {
+	t.Helper()
+	previousAttempts, previousBackoff := global.TokenVerifyAttempts, global.TokenVerifyBackoff
+	global.TokenVerifyAttempts, global.TokenVerifyBackoff = attempts, backoff
+	t.Cleanup(func() { global.TokenVerifyAttempts, global.TokenVerifyBackoff = previousAttempts, previousBackoff })
+}
+
+// TestVerifyUserToken_RetriesTransientError 测试Redis瞬时错误被重试后校验成功
+func TestVerifyUserToken_RetriesTransientError(t *testing.T) {
+	SetupTestRedis(t)
+	setupTokenVerifyRetry(t, 3, time.Millisecond)
+	redisRepo := repository.NewRedisRepository()
+	token, err := redisRepo.GenerateUserToken(100)
+	assert.NoError(t, err)
+
+	hook := &flakyGetHook{failures: 2}
+	global.RedisClient.AddHook(hook)
+
+	userId, err := redisRepo.VerifyUserToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), userId)
+	assert.Equal(t, 3, hook.gets)
+}
+
+// TestVerifyUserToken_RetriesExhausted 测试瞬时错误持续超过重试次数时返回错误
+func TestVerifyUserToken_RetriesExhausted(t *testing.T) {
+	SetupTestRedis(t)
+	setupTokenVerifyRetry(t, 2, time.Millisecond)
+	redisRepo := repository.NewRedisRepository()
+	token, err := redisRepo.GenerateUserToken(100)
+	assert.NoError(t, err)
+
+	hook := &flakyGetHook{failures: 5}
+	global.RedisClient.AddHook(hook)
+
+	_, err = redisRepo.VerifyUserToken(token)
+	assert.ErrorIs(t, err, errRedisBlip)
+	assert.NotErrorIs(t, err, errs.ErrInvalidToken)
+	assert.Equal(t, 2, hook.gets)
+}
+
+// TestVerifyUserToken_NotFoundNotRetried 测试令牌不存在时立即返回，不重试
+func TestVerifyUserToken_NotFoundNotRetried(t *testing.T) {
+	SetupTestRedis(t)
+	setupTokenVerifyRetry(t, 3, time.Second)
+	redisRepo := repository.NewRedisRepository()
+	hook := &flakyGetHook{}
+	global.RedisClient.AddHook(hook)
+
+	start := time.Now()
+	_, err := redisRepo.VerifyUserToken(absentToken)
+	assert.ErrorIs(t, err, errs.ErrTokenNotFound)
+	assert.Equal(t, 1, hook.gets)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+// TestRedisConfig_TokenVerifyRetry 测试令牌校验重试配置的解析、默认值和校验
+func TestRedisConfig_TokenVerifyRetry(t *testing.T) {
+	var rc config.RedisConfig
+	assert.NoError(t, yaml.Unmarshal([]byte("token_verify_attempts: 5\ntoken_verify_backoff_ms: 25\n"), &rc))
+	assert.Equal(t, 5, rc.VerifyAttempts())
+	assert.Equal(t, 25*time.Millisecond, rc.VerifyBackoff())
+
+	var empty config.RedisConfig
+	assert.Equal(t, config.DefaultTokenVerifyAttempts, empty.VerifyAttempts())
+	assert.Equal(t, config.DefaultTokenVerifyBackoffMs*time.Millisecond, empty.VerifyBackoff())
+}
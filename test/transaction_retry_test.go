@@ -0,0 +1,117 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"seckill_system/handler"
+	"seckill_system/model"
+	"seckill_system/repository"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// deadlockErr 模拟MySQL死锁错误
+var deadlockErr = &mysql.MySQLError{Number: 1213, SQLState: [5]byte{'4', '0', '0', '0', '1'}, Message: "Deadlock found when trying to get lock"}
+
+// TestIsRetryableTxError 测试死锁、序列化失败与其他错误的区分
+func TestIsRetryableTxError(t *testing.T) {
+	assert.True(t, repository.IsRetryableTxError(deadlockErr))
+	assert.True(t, repository.IsRetryableTxError(fmt.Errorf("create order failed: %w", deadlockErr)))
+	assert.True(t, repository.IsRetryableTxError(&mysql.MySQLError{Number: 3101, SQLState: [5]byte{'4', '0', '0', '0', '1'}}))
+	assert.False(t, repository.IsRetryableTxError(&mysql.MySQLError{Number: 1205, SQLState: [5]byte{'H', 'Y', '0', '0', '0'}})) // 锁等待超时
+	assert.False(t, repository.IsRetryableTxError(&mysql.MySQLError{Number: 1062, SQLState: [5]byte{'2', '3', '0', '0', '0'}})) // 唯一键冲突
+	assert.False(t, repository.IsRetryableTxError(errors.New("seckill failed, stock not enough")))
+	assert.False(t, repository.IsRetryableTxError(nil))
+}
+
+// TestWithTransaction_RetriesDeadlock 测试死锁时重新执行事务，之前失败的尝试被回滚
+func TestWithTransaction_RetriesDeadlock(t *testing.T) {
+	db := SetupTestDB(t)
+	repo := repository.NewGoodRepository()
+
+	attempts := 0
+	err := repo.WithTransaction(func(tx *gorm.DB) error {
+		attempts++
+		if err := tx.Create(&model.SuccessKilled{GoodsId: 1, UserId: int64(attempts)}).Error; err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return fmt.Errorf("create order failed: %w", deadlockErr)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	// 只有最后一次成功的事务写入生效
+	var orders []model.SuccessKilled
+	assert.NoError(t, db.Find(&orders).Error)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, int64(3), orders[0].UserId)
+}
+
+// TestWithTransaction_RetriesExhausted 测试持续死锁时在最大次数后返回错误
+func TestWithTransaction_RetriesExhausted(t *testing.T) {
+	SetupTestDB(t)
+	repo := repository.NewGoodRepository()
+
+	attempts := 0
+	err := repo.WithTransaction(func(tx *gorm.DB) error {
+		attempts++
+		return deadlockErr
+	})
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, deadlockErr)
+	assert.Equal(t, repository.MaxTransactionAttempts, attempts)
+}
+
+// TestWithTransaction_NoRetryOnOtherErrors 测试非死锁错误不重试
+func TestWithTransaction_NoRetryOnOtherErrors(t *testing.T) {
+	SetupTestDB(t)
+	repo := repository.NewGoodRepository()
+
+	attempts := 0
+	businessErr := errors.New("seckill failed, stock not enough")
+	err := repo.WithTransaction(func(tx *gorm.DB) error {
+		attempts++
+		return businessErr
+	})
+	assert.ErrorIs(t, err, businessErr)
+	assert.Equal(t, 1, attempts)
+}
+
+// TestCreateOrder_DeadlockRetryReducesStockOnce 测试下单事务首次写订单遇到死锁后重试，数据库库存只按购买数量扣减一次
+func TestCreateOrder_DeadlockRetryReducesStockOnce(t *testing.T) {
+	db := SetupTestDB(t)
+	SetupTestRedis(t)
+	SetupTestKafka(t)
+	promotion := CreateTestPromotion(1, 10)
+	promotion.PurchaseLimit = 2
+	require.NoError(t, db.Create(&promotion).Error)
+	require.NoError(t, repository.NewRedisRepository().SetGoodsStock(1, 10))
+
+	// 第一次写订单时返回死锁错误，触发整个事务重试
+	orderWrites := 0
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("test:deadlock_once", func(tx *gorm.DB) {
+		if tx.Statement.Table != "success_killed" {
+			return
+		}
+		orderWrites++
+		if orderWrites == 1 {
+			tx.AddError(deadlockErr)
+		}
+	}))
+
+	_, err := handler.NewSeckillHandler().CreateOrder(context.Background(), 100, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, orderWrites)
+
+	var stored model.PromotionSecKill
+	require.NoError(t, db.Where("goods_id = ?", 1).First(&stored).Error)
+	assert.Equal(t, int64(8), stored.PsCount)
+}
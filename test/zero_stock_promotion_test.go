@@ -0,0 +1,79 @@
+package test
+
+import (
+	"seckill_system/errs"
+	"seckill_system/repository"
+	"seckill_system/service"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupPromotionCount 创建促销库存为psCount且未预加载的商品1及完整依赖的商品服务
+func setupPromotionCount(t *testing.T, psCount int64) (*service.GoodService, *miniredis.Miniredis) {
+	f := SetupTestService(t, WithGoods(psCount, 1), WithoutRedisStock(), WithSeckillEnabled(10), WithSeckillHandler())
+	return f.Service, f.Redis
+}
+
+// TestZeroCountPromotion_TokenReportsSoldOut 测试促销库存为0时预加载成功，获取令牌返回售罄而不是错误
+func TestZeroCountPromotion_TokenReportsSoldOut(t *testing.T) {
+	gs, mr := setupPromotionCount(t, 0)
+
+	updated, err := gs.PreloadGoodsStock(1, true)
+	require.NoError(t, err)
+	assert.True(t, updated)
+	mr.CheckGet(t, repository.StockKey(1), "0")
+
+	_, err = gs.GenerateSeckillToken(100, 1, "")
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+	assert.Equal(t, errs.OutcomeSoldOut, errs.Outcome(err))
+
+	stock, loaded, err := gs.GetCurrentStock(1)
+	require.NoError(t, err)
+	assert.True(t, loaded)
+	assert.Equal(t, int64(0), stock)
+}
+
+// TestZeroCountPromotion_SeckillReportsSoldOut 测试库存被清零后持有令牌的秒杀返回售罄，不扣减出负库存也不创建订单
+func TestZeroCountPromotion_SeckillReportsSoldOut(t *testing.T) {
+	gs, mr := setupPromotionCount(t, 0)
+	_, err := gs.PreloadGoodsStock(1, true)
+	require.NoError(t, err)
+
+	tokenId, err := gs.RedisRepo.GenerateSeckillToken(100, 1) // 库存清零前签发的令牌
+	require.NoError(t, err)
+
+	orderId, err := gs.SeckillWithToken(100, 1, tokenId)
+	assert.ErrorIs(t, err, errs.ErrSoldOut)
+	assert.Empty(t, orderId)
+	mr.CheckGet(t, repository.StockKey(1), "0")
+
+	hasOrder, err := gs.GoodDB.HasUserOrder(100, 1)
+	require.NoError(t, err)
+	assert.False(t, hasOrder)
+}
+
+// TestNegativeCountPromotion_PreloadRejected 测试促销库存为负数时拒绝预加载，不写入Redis
+func TestNegativeCountPromotion_PreloadRejected(t *testing.T) {
+	gs, mr := setupPromotionCount(t, -5)
+
+	updated, err := gs.PreloadGoodsStock(1, true)
+	assert.ErrorIs(t, err, errs.ErrInvalidPromotionCount)
+	assert.False(t, updated)
+	assert.False(t, mr.Exists(repository.StockKey(1)))
+
+	results, err := gs.PreloadGoodsStockBatch([]int64{1})
+	require.NoError(t, err)
+	assert.False(t, results[1].Success)
+	assert.Contains(t, results[1].Error, "promotion count must not be negative")
+	assert.False(t, mr.Exists(repository.StockKey(1)))
+
+	// 重置促销库存同样拒绝负数
+	err = gs.GoodDB.WithTransaction(func(tx *gorm.DB) error {
+		return gs.GoodDB.ResetPromotionCountByGoodsId(tx, 1, -1)
+	})
+	assert.ErrorIs(t, err, errs.ErrInvalidPromotionCount)
+}
@@ -1,34 +1,223 @@
 package controller
 
 import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"seckill_system/config"
+	"seckill_system/errs"
+	"seckill_system/global"
+	"seckill_system/handler"
+	"seckill_system/model"
 	"seckill_system/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 )
 
+// maxBatchResetSize 单次批量重置允许的最大商品数量
+const maxBatchResetSize = 100
+
+// maxBatchPreloadSize 单次批量预加载允许的最大商品数量
+const maxBatchPreloadSize = 1000
+
+// maxBlacklistImportSize 单次批量导入黑名单允许的最大条目数
+const maxBlacklistImportSize = 10000
+
+// 库存推送WebSocket相关常量
+const (
+	maxStockWSConnections = 1000             // 最大WebSocket连接数
+	stockWSPingInterval   = 30 * time.Second // 心跳间隔
+	stockWSPongWait       = 60 * time.Second // 等待客户端心跳响应的超时时间
+	stockWSWriteTimeout   = 10 * time.Second // 单次写入超时时间
+)
+
+// stockWSUpgrader WebSocket协议升级器
+var stockWSUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true }, // 允许跨域连接
+}
+
 // GoodController 处理商品相关请求的控制器
 type GoodController struct {
-	GoodService *service.GoodService // 商品服务实例
+	GoodService   *service.GoodService // 商品服务实例
+	ETagEnabled   bool                 // 商品信息接口是否支持ETag条件请求
+	InfoFields    []string             // 商品信息接口返回的字段，为空时使用默认字段
+	GoodsIdRange  config.GoodsIdRange  // 有效商品ID范围，零值时只要求为正数
+	Traces        *config.TraceBuffer  // 请求日志缓冲区，为nil时不支持日志回放
+	wsConnections atomic.Int64         // 当前库存推送WebSocket连接数
 }
 
 // NewGoodController 创建GoodController实例
 func NewGoodController() *GoodController {
-	return &GoodController{
-		GoodService: service.GetGoodService(),
+	controller := &GoodController{GoodService: service.GetGoodService()}
+	if config.AppConfig != nil {
+		controller.ETagEnabled = config.AppConfig.Server.GoodsETag
+		controller.InfoFields = config.AppConfig.Server.GoodsInfoFields
+		controller.GoodsIdRange = config.AppConfig.Server.GoodsIdRange
+	}
+	controller.Traces = config.DefaultTraceBuffer
+	return controller
+}
+
+// Health 存活探针接口，进程能处理请求即返回200，不检查外部依赖
+func (g *GoodController) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "ok",
+	})
+}
+
+// Ready 就绪探针接口，检查MySQL、Redis、Kafka和Etcd，全部可用时返回200，否则返回503
+func (g *GoodController) Ready(c *gin.Context) {
+	report := global.HealthCheck(c.Request.Context())
+	if !report.Ready {
+		slog.Warn("Readiness check failed",
+			"dependencies", report.Dependencies,
+		)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    -1,
+			"data":    report,
+			"message": "Service not ready",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    report,
+		"message": "Service ready",
+	})
+}
+
+// parseGoodsId 解析商品ID并校验是否在有效范围内
+func (g *GoodController) parseGoodsId(raw string) (int64, error) {
+	goodsId, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if err := g.GoodsIdRange.Check(goodsId); err != nil {
+		return 0, err
+	}
+	return goodsId, nil
+}
+
+// parsePagination 解析列表接口的分页参数page和size，未指定时page为1、size为0（由服务层使用默认值）
+// sizeAlias为兼容旧版本的每页条数参数名，size未指定时读取；参数不是正整数时返回错误
+func parsePagination(c *gin.Context, sizeAlias string) (page, size int, err error) {
+	page = 1
+	params := []struct {
+		name   string
+		target *int
+	}{
+		{"page", &page},
+		{"size", &size},
+	}
+	if c.Query("size") == "" {
+		params[1].name = sizeAlias
 	}
+	for _, param := range params {
+		valueStr := c.Query(param.name)
+		if valueStr == "" {
+			continue
+		}
+		value, err := strconv.Atoi(valueStr)
+		if err != nil || value <= 0 {
+			return 0, 0, fmt.Errorf("invalid %s parameter", param.name)
+		}
+		*param.target = value
+	}
+	return page, size, nil
+}
+
+// 秒杀令牌接口不在活动时间内时返回的业务码，便于前端区分"稍后再来"和"已经错过"
+const (
+	CodeSeckillNotStarted = -2 // 秒杀活动尚未开始
+	CodeSeckillEnded      = -3 // 秒杀活动已结束
+)
+
+// activityWindowResponse 不在秒杀活动时间内时生成带业务码和活动起止时间的响应，其他错误返回false
+func activityWindowResponse(err error) (gin.H, bool) {
+	var window *service.ActivityWindowError
+	if !errors.As(err, &window) {
+		return nil, false
+	}
+	code := CodeSeckillNotStarted
+	if errors.Is(err, errs.ErrSeckillEnded) {
+		code = CodeSeckillEnded
+	}
+	return gin.H{
+		"code":  code,
+		"error": err.Error(),
+		"data": gin.H{
+			"start_time": window.StartTime,
+			"end_time":   window.EndTime,
+		},
+		"message": "Failed to generate seckill token",
+	}, true
+}
+
+// errorStatus 根据结构化错误类别返回HTTP状态码，未分类的错误返回500
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errs.ErrForbidden), errors.Is(err, errs.ErrInvalidToken):
+		return http.StatusForbidden
+	case errors.Is(err, errs.ErrSoldOut), errors.Is(err, errs.ErrAlreadyPurchased):
+		return http.StatusConflict
+	case errors.Is(err, errs.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, errs.ErrSystemBusy):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GoodsETag 根据商品ID和最后更新时间生成ETag
+// 同一商品未更新时ETag保持不变
+func GoodsETag(good model.Goods) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%d-%d", good.GoodsId, good.LastUpdateTime.UnixNano())))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// goodsInfoETag 生成商品信息接口的ETag，附带秒杀数据时剩余库存变化也会使ETag变化
+func goodsInfoETag(good model.Goods, seckill *model.SeckillInfo) string {
+	if seckill == nil {
+		return GoodsETag(good)
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s-%d-%t-%d-%d-%d", GoodsETag(good), seckill.RemainingStock,
+		seckill.StockPreloaded, seckill.StartTime.UnixNano(), seckill.EndTime.UnixNano(), seckill.PurchaseLimit)))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// etagMatches 判断If-None-Match请求头是否与ETag匹配（弱比较）
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
 }
 
 // GetGoodInfo 获取商品信息接口
 func (g *GoodController) GetGoodInfo(c *gin.Context) {
 	// 从路径参数中获取商品ID
 	id := c.Param("id")
-	gid, err := strconv.Atoi(id)
+	gid, err := g.parseGoodsId(id)
 	if err != nil {
 		slog.Warn("Invalid good ID in request",
 			"id", id,
@@ -43,8 +232,8 @@ func (g *GoodController) GetGoodInfo(c *gin.Context) {
 		return
 	}
 
-	// 调用服务层获取商品信息
-	good, err := g.GoodService.FindGoodById(int64(gid))
+	// 调用服务层获取商品信息（数据库不可用时可能返回缓存数据）
+	good, stale, err := g.GoodService.GetGoodInfo(gid)
 	if err != nil {
 		slog.Error("Failed to query product data",
 			"goods_id", gid,
@@ -62,23 +251,116 @@ func (g *GoodController) GetGoodInfo(c *gin.Context) {
 	slog.Info("Product data queried successfully",
 		"goods_id", gid,
 		"title", good.Title,
+		"stale", stale,
 	)
-	// 返回商品信息
+
+	// 附带秒杀活动的剩余库存和起止时间，商品没有秒杀活动或查询失败时不返回这些字段
+	var seckill *model.SeckillInfo
+	if info, err := g.GoodService.GetSeckillInfo(gid); err == nil {
+		seckill = &info
+	} else if !errors.Is(err, errs.ErrPromotionNotFound) {
+		slog.Warn("Failed to query seckill info for product",
+			"goods_id", gid,
+			"error", err,
+		)
+	}
+
+	// 条件请求：商品和库存未变化时返回304，降级的缓存数据不参与协商
+	if g.ETagEnabled && !stale {
+		etag := goodsInfoETag(good, seckill)
+		c.Header("ETag", etag)
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	// 返回商品信息，只包含配置的对外字段
+	data := gin.H{
+		"good_info": model.ProjectGoodsInfo(good, g.InfoFields),
+		"stale":     stale,
+	}
+	if seckill != nil {
+		data["remaining_stock"] = seckill.RemainingStock
+		data["seckill_price"] = seckill.SeckillPrice
+		data["start_time"] = seckill.StartTime
+		data["end_time"] = seckill.EndTime
+		data["stock_preloaded"] = seckill.StockPreloaded
+		data["purchase_limit"] = seckill.PurchaseLimit
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"code": 0,
-		"data": gin.H{
-			"good_info": good,
-		},
+		"code":    0,
+		"data":    data,
 		"message": "Product data queried successfully",
 	})
 }
 
+// SearchGoods 按标题关键字搜索商品接口
+func (g *GoodController) SearchGoods(c *gin.Context) {
+	// 获取搜索关键字
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		slog.Warn("Missing q parameter in goods search request")
+		// 返回缺少关键字响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   "missing q parameter",
+			"message": "Search keyword is required",
+		})
+		return
+	}
+
+	// 解析分页参数，兼容旧版本的limit参数
+	page, size, err := parsePagination(c, "limit")
+	if err != nil {
+		slog.Warn("Invalid pagination parameter in goods search request",
+			"query", q,
+			"error", err,
+		)
+		// 返回参数无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Page and size must be positive integers",
+		})
+		return
+	}
+
+	// 执行搜索
+	goods, err := g.GoodService.SearchGoodsByTitle(q, page, size)
+	if err != nil {
+		slog.Error("Failed to search goods",
+			"query", q,
+			"error", err,
+		)
+		// 返回搜索失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to search goods",
+		})
+		return
+	}
+
+	slog.Info("Goods searched successfully via API",
+		"query", q,
+		"count", len(goods.Items),
+		"total", goods.Total,
+	)
+	// 返回搜索结果
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    goods,
+		"message": "Goods searched successfully",
+	})
+}
+
 // GetSeckillToken 获取秒杀令牌接口
 func (g *GoodController) GetSeckillToken(c *gin.Context) {
 	// 从请求头获取授权令牌
 	token := c.GetHeader("Authorization")
 	if token == "" {
-		slog.Warn("Missing authorization token in request")
+		slog.WarnContext(c.Request.Context(), "Missing authorization token in request")
 		// 返回未授权响应
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    -1,
@@ -91,7 +373,7 @@ func (g *GoodController) GetSeckillToken(c *gin.Context) {
 	// 验证用户令牌
 	userId, err := g.GoodService.VerifyUserToken(token)
 	if err != nil {
-		slog.Warn("Invalid user token",
+		slog.WarnContext(c.Request.Context(), "Invalid user token",
 			"token", token,
 			"error", err,
 		)
@@ -106,9 +388,9 @@ func (g *GoodController) GetSeckillToken(c *gin.Context) {
 
 	// 获取商品ID
 	goodsIdStr := c.Query("gid")
-	goodsId, err := strconv.ParseInt(goodsIdStr, 10, 64)
+	goodsId, err := g.parseGoodsId(goodsIdStr)
 	if err != nil {
-		slog.Warn("Invalid goods ID in request",
+		slog.WarnContext(c.Request.Context(), "Invalid goods ID in request",
 			"user_id", userId,
 			"goods_id_str", goodsIdStr,
 			"error", err,
@@ -123,15 +405,23 @@ func (g *GoodController) GetSeckillToken(c *gin.Context) {
 	}
 
 	// 生成秒杀令牌
-	tokenId, err := g.GoodService.GenerateSeckillToken(userId, goodsId)
+	tokenId, err := g.GoodService.GenerateSeckillToken(userId, goodsId, c.ClientIP())
+	g.GoodService.RecordAuditEvent(model.AuditActionSeckillToken, userId, goodsId, c.ClientIP(), err)
 	if err != nil {
-		slog.Error("Failed to generate seckill token",
+		// 售罄、限流等预期的业务结果按Info/Warn记录，只有未分类的故障记为Error
+		slog.Log(c.Request.Context(), errs.LogLevel(err), "Failed to generate seckill token",
 			"user_id", userId,
 			"goods_id", goodsId,
+			"outcome", errs.Outcome(err),
 			"error", err,
 		)
-		// 返回生成令牌失败响应
-		c.JSON(http.StatusInternalServerError, gin.H{
+		// 不在活动时间内时返回区分未开始和已结束的业务码及活动起止时间
+		if resp, ok := activityWindowResponse(err); ok {
+			c.JSON(errorStatus(err), resp)
+			return
+		}
+		// 返回生成令牌失败响应，状态码由错误类别决定
+		c.JSON(errorStatus(err), gin.H{
 			"code":    -1,
 			"error":   err.Error(),
 			"message": "Failed to generate seckill token",
@@ -139,10 +429,10 @@ func (g *GoodController) GetSeckillToken(c *gin.Context) {
 		return
 	}
 
-	slog.Info("Seckill token generated successfully",
+	slog.InfoContext(c.Request.Context(), "Seckill token generated successfully",
 		"user_id", userId,
 		"goods_id", goodsId,
-		"token_id_prefix", tokenId[:8],
+		"token_id_prefix", model.TokenPrefix(tokenId),
 	)
 	// 返回秒杀令牌
 	c.JSON(http.StatusOK, gin.H{
@@ -157,7 +447,7 @@ func (g *GoodController) SeckillWithToken(c *gin.Context) {
 	// 验证用户令牌
 	token := c.GetHeader("Authorization")
 	if token == "" {
-		slog.Warn("Missing authorization token in seckill request")
+		slog.WarnContext(c.Request.Context(), "Missing authorization token in seckill request")
 		// 返回未授权响应
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    -1,
@@ -170,7 +460,7 @@ func (g *GoodController) SeckillWithToken(c *gin.Context) {
 	// 验证用户令牌并获取用户ID
 	userId, err := g.GoodService.VerifyUserToken(token)
 	if err != nil {
-		slog.Warn("Invalid user token in seckill request",
+		slog.WarnContext(c.Request.Context(), "Invalid user token in seckill request",
 			"token", token,
 			"error", err,
 		)
@@ -185,9 +475,9 @@ func (g *GoodController) SeckillWithToken(c *gin.Context) {
 
 	// 获取商品ID
 	goodsIdStr := c.Query("gid")
-	goodsId, err := strconv.ParseInt(goodsIdStr, 10, 64)
+	goodsId, err := g.parseGoodsId(goodsIdStr)
 	if err != nil {
-		slog.Warn("Invalid goods ID in seckill request",
+		slog.WarnContext(c.Request.Context(), "Invalid goods ID in seckill request",
 			"user_id", userId,
 			"goods_id_str", goodsIdStr,
 			"error", err,
@@ -204,7 +494,7 @@ func (g *GoodController) SeckillWithToken(c *gin.Context) {
 	// 获取秒杀令牌
 	tokenId := c.Query("token")
 	if tokenId == "" {
-		slog.Warn("Missing seckill token in request",
+		slog.WarnContext(c.Request.Context(), "Missing seckill token in request",
 			"user_id", userId,
 			"goods_id", goodsId,
 		)
@@ -217,29 +507,50 @@ func (g *GoodController) SeckillWithToken(c *gin.Context) {
 		return
 	}
 
+	// 可选的幂等键，客户端超时重试时携带相同的幂等键可得到首次请求的结果
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if idempotencyKey != "" && !model.ValidIdempotencyKey(idempotencyKey) {
+		slog.WarnContext(c.Request.Context(), "Invalid idempotency key in seckill request",
+			"user_id", userId,
+			"goods_id", goodsId,
+		)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   "invalid idempotency key",
+			"message": fmt.Sprintf("Idempotency-Key must be 1-%d printable ASCII characters without spaces", model.MaxIdempotencyKeyLength),
+		})
+		return
+	}
+
 	// 执行秒杀操作
-	orderId, err := g.GoodService.SeckillWithToken(userId, goodsId, tokenId)
+	orderId, err := g.GoodService.SeckillWithIdempotencyKey(userId, goodsId, tokenId, idempotencyKey)
+	g.GoodService.RecordAuditEvent(model.AuditActionSeckill, userId, goodsId, c.ClientIP(), err)
 	if err != nil {
-		slog.Error("Seckill failed",
+		slog.Log(c.Request.Context(), errs.LogLevel(err), "Seckill failed",
 			"user_id", userId,
 			"goods_id", goodsId,
-			"token_id_prefix", tokenId[:8],
+			"outcome", errs.Outcome(err),
+			"token_id_prefix", model.TokenPrefix(tokenId),
 			"error", err,
 		)
-		// 返回秒杀失败响应
-		c.JSON(http.StatusInternalServerError, gin.H{
+		// 返回秒杀失败响应，状态码由错误类别决定；已抢到的用户给出明确提示，避免客户端继续重试
+		message := "Seckill failed"
+		if errors.Is(err, errs.ErrAlreadyPurchased) {
+			message = "You have already secured this item"
+		}
+		c.JSON(errorStatus(err), gin.H{
 			"code":    -1,
 			"error":   err.Error(),
-			"message": "Seckill failed",
+			"message": message,
 		})
 		return
 	}
 
-	slog.Info("Seckill successful via API",
+	slog.InfoContext(c.Request.Context(), "Seckill successful via API",
 		"user_id", userId,
 		"goods_id", goodsId,
 		"order_id", orderId,
-		"token_id_prefix", tokenId[:8],
+		"token_id_prefix", model.TokenPrefix(tokenId),
 	)
 	// 返回订单ID
 	c.JSON(http.StatusOK, gin.H{
@@ -249,6 +560,91 @@ func (g *GoodController) SeckillWithToken(c *gin.Context) {
 	})
 }
 
+// SeckillAsync 异步秒杀接口
+// 校验并消费秒杀令牌后将下单请求放入队列，立即返回请求ID，客户端通过GetAsyncSeckillResult轮询结果
+func (g *GoodController) SeckillAsync(c *gin.Context) {
+	// 用户ID由认证中间件写入上下文
+	userId := c.GetInt64("userId")
+
+	// 获取商品ID
+	goodsIdStr := c.Query("gid")
+	goodsId, err := g.parseGoodsId(goodsIdStr)
+	if err != nil {
+		// 返回商品ID无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Invalid good ID",
+		})
+		return
+	}
+
+	// 获取秒杀令牌
+	tokenId := c.Query("token")
+	if tokenId == "" {
+		// 返回缺少秒杀令牌响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   "missing seckill token",
+			"message": "Seckill token required",
+		})
+		return
+	}
+
+	requestId, err := g.GoodService.SeckillAsync(userId, goodsId, tokenId, c.ClientIP())
+	if err != nil {
+		slog.Log(c.Request.Context(), errs.LogLevel(err), "Async seckill rejected",
+			"user_id", userId,
+			"goods_id", goodsId,
+			"outcome", errs.Outcome(err),
+			"token_id_prefix", model.TokenPrefix(tokenId),
+			"error", err,
+		)
+		// 返回受理失败响应，状态码由错误类别决定
+		c.JSON(errorStatus(err), gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Seckill request rejected",
+		})
+		return
+	}
+
+	// 返回请求ID，下单结果稍后查询
+	c.JSON(http.StatusAccepted, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"request_id": requestId,
+			"status":     model.AsyncSeckillPending,
+		},
+		"message": "Seckill request accepted",
+	})
+}
+
+// GetAsyncSeckillResult 查询异步秒杀请求处理结果接口
+func (g *GoodController) GetAsyncSeckillResult(c *gin.Context) {
+	// 用户ID由认证中间件写入上下文
+	userId := c.GetInt64("userId")
+	requestId := c.Param("request_id")
+
+	result, err := g.GoodService.GetAsyncSeckillResult(userId, requestId)
+	if err != nil {
+		// 请求不存在返回404，其他错误返回500
+		c.JSON(errorStatus(err), gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to get seckill result",
+		})
+		return
+	}
+
+	// 返回处理结果
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    result,
+		"message": "Seckill result retrieved",
+	})
+}
+
 // SimulatePayment 模拟支付接口
 func (g *GoodController) SimulatePayment(c *gin.Context) {
 	// 获取订单ID
@@ -312,7 +708,7 @@ func (g *GoodController) SimulatePayment(c *gin.Context) {
 func (g *GoodController) PreloadGoodsStock(c *gin.Context) {
 	// 从路径参数中获取商品ID
 	id := c.Param("id")
-	goodsId, err := strconv.ParseInt(id, 10, 64)
+	goodsId, err := g.parseGoodsId(id)
 	if err != nil {
 		slog.Warn("Invalid goods ID in preload request",
 			"id", id,
@@ -327,8 +723,43 @@ func (g *GoodController) PreloadGoodsStock(c *gin.Context) {
 		return
 	}
 
+	// 获取强制写入参数，未指定时库存未变化则跳过写入
+	forceStr := c.DefaultQuery("force", "false")
+	force, err := strconv.ParseBool(forceStr)
+	if err != nil {
+		slog.Warn("Invalid force parameter in preload request",
+			"force_str", forceStr,
+			"error", err,
+		)
+		// 返回参数无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Invalid force parameter",
+		})
+		return
+	}
+
 	// 执行预加载
-	err = g.GoodService.PreloadGoodsStock(goodsId)
+	updated, err := g.GoodService.PreloadGoodsStock(goodsId, force)
+	if errors.Is(err, errs.ErrPromotionNotFound) {
+		// 返回促销活动不存在响应
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Promotion not found",
+		})
+		return
+	}
+	if errors.Is(err, errs.ErrInvalidPromotionCount) {
+		// 返回促销库存无效响应，需先修正数据库中的促销库存
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Promotion count must not be negative",
+		})
+		return
+	}
 	if err != nil {
 		slog.Error("Failed to preload goods stock",
 			"goods_id", goodsId,
@@ -343,16 +774,79 @@ func (g *GoodController) PreloadGoodsStock(c *gin.Context) {
 		return
 	}
 
+	if !updated {
+		// 返回库存已是最新响应
+		c.JSON(http.StatusOK, gin.H{
+			"code":    0,
+			"data":    gin.H{"updated": false},
+			"message": "Goods stock already current",
+		})
+		return
+	}
+
 	slog.Info("Goods stock preloaded successfully via API",
 		"goods_id", goodsId,
 	)
 	// 返回成功响应
 	c.JSON(http.StatusOK, gin.H{
 		"code":    0,
+		"data":    gin.H{"updated": true},
 		"message": "Goods stock preloaded successfully",
 	})
 }
 
+// ExpireSeckillToken 强制使秒杀令牌失效接口（管理员），返回令牌是否存在
+func (g *GoodController) ExpireSeckillToken(c *gin.Context) {
+	// 获取商品ID
+	goodsIdStr := c.Query("gid")
+	goodsId, err := g.parseGoodsId(goodsIdStr)
+	if err != nil {
+		slog.Warn("Invalid goods ID in expire token request",
+			"goods_id_str", goodsIdStr,
+			"error", err,
+		)
+		// 返回商品ID无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Invalid good ID",
+		})
+		return
+	}
+
+	tokenId := c.Query("token")
+	existed, err := g.GoodService.ExpireSeckillToken(goodsId, tokenId)
+	if errors.Is(err, errs.ErrTokenMalformed) {
+		// 返回令牌格式无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Invalid seckill token",
+		})
+		return
+	}
+	if err != nil {
+		// 返回失效失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to expire seckill token",
+		})
+		return
+	}
+
+	// 返回令牌是否存在
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"goods_id":        goodsId,
+			"token_id_prefix": model.TokenPrefix(tokenId),
+			"existed":         existed,
+		},
+		"message": "Seckill token expired",
+	})
+}
+
 // SetSeckillEnabled 设置秒杀开关状态接口
 func (g *GoodController) SetSeckillEnabled(c *gin.Context) {
 	// 获取启用状态参数
@@ -488,7 +982,7 @@ func (g *GoodController) AddToBlacklist(c *gin.Context) {
 	}
 
 	// 添加用户到黑名单
-	err = g.GoodService.AddToBlacklist(userId, reason, duration)
+	needsReview, err := g.GoodService.AddToBlacklist(userId, reason, duration)
 	if err != nil {
 		slog.Error("Failed to add user to blacklist",
 			"user_id", userId,
@@ -509,52 +1003,263 @@ func (g *GoodController) AddToBlacklist(c *gin.Context) {
 		"user_id", userId,
 		"reason", reason,
 		"duration", duration,
+		"needs_review", needsReview,
 	)
+	// 租约创建失败时封禁已生效但不会自动过期，提示需要人工复核
+	message := "User added to blacklist successfully"
+	if needsReview {
+		message = "User added to blacklist without expiry, manual review required"
+	}
 	// 返回成功响应
 	c.JSON(http.StatusOK, gin.H{
 		"code":    0,
-		"message": "User added to blacklist successfully",
+		"data":    gin.H{"needs_review": needsReview},
+		"message": message,
 	})
 }
 
-// GetBlacklist 获取黑名单列表接口
-func (g *GoodController) GetBlacklist(c *gin.Context) {
-	// 获取黑名单列表
-	blacklist, err := g.GoodService.GetBlacklist()
-	if err != nil {
-		slog.Error("Failed to get blacklist",
+// ImportBlacklist 批量导入黑名单接口
+// 请求体为{user_id, reason, duration}的JSON数组，逐条校验，格式错误的条目不影响其他条目
+func (g *GoodController) ImportBlacklist(c *gin.Context) {
+	var entries []model.BlacklistEntry
+	if err := c.ShouldBindJSON(&entries); err != nil {
+		slog.Warn("Invalid body in blacklist import request",
 			"error", err,
 		)
-		// 返回获取失败响应
-		c.JSON(http.StatusInternalServerError, gin.H{
+		// 返回请求体无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    -1,
 			"error":   err.Error(),
-			"message": "Failed to get blacklist",
+			"message": "Request body must be a JSON array of blacklist entries",
+		})
+		return
+	}
+	if len(entries) == 0 || len(entries) > maxBlacklistImportSize {
+		slog.Warn("Invalid entry count in blacklist import request",
+			"count", len(entries),
+			"max", maxBlacklistImportSize,
+		)
+		// 返回数量无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   "invalid blacklist entry count",
+			"message": fmt.Sprintf("Between 1 and %d entries per request", maxBlacklistImportSize),
 		})
 		return
 	}
 
-	slog.Info("Blacklist retrieved via API",
-		"count", len(blacklist),
+	results := g.GoodService.ImportBlacklist(entries)
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+
+	slog.Info("Blacklist imported via API",
+		"entries", len(entries),
+		"succeeded", succeeded,
 	)
-	// 返回黑名单数据
+	// 返回每个条目的导入结果
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
 		"data": gin.H{
-			"blacklist": blacklist,
+			"results":   results,
+			"succeeded": succeeded,
+			"failed":    len(results) - succeeded,
 		},
-		"message": "Blacklist retrieved successfully",
+		"message": "Blacklist import completed",
 	})
 }
 
-// GenerateUserToken 生成用户令牌接口
-func (g *GoodController) GenerateUserToken(c *gin.Context) {
-	// 从查询参数获取用户ID
-	userIdStr := c.Query("user_id")
-	if userIdStr == "" {
-		slog.Warn("Missing user_id parameter in token generation request")
-		// 返回缺少用户ID响应
-		c.JSON(http.StatusBadRequest, gin.H{
+// ExportBlacklist 导出全部黑名单接口，返回格式与批量导入一致
+func (g *GoodController) ExportBlacklist(c *gin.Context) {
+	entries, err := g.GoodService.ExportBlacklist()
+	if err != nil {
+		// 返回导出失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to export blacklist",
+		})
+		return
+	}
+
+	// 返回黑名单条目
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    entries,
+		"message": "Blacklist exported successfully",
+	})
+}
+
+// GetTrace 按请求ID回放请求日志接口
+// 返回环形缓冲区中仍保留的该请求全部日志，缓冲区写满后最早的日志会被覆盖
+func (g *GoodController) GetTrace(c *gin.Context) {
+	requestId := c.Param("request_id")
+	if g.Traces == nil {
+		// 未配置log.trace_buffer_size时不记录请求日志
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    -1,
+			"error":   "trace buffer disabled",
+			"message": "Request tracing is not enabled",
+		})
+		return
+	}
+
+	records := g.Traces.Get(requestId)
+	if len(records) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    -1,
+			"error":   "trace not found",
+			"message": "No log records for this request id, it may have been evicted",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"request_id": requestId,
+			"records":    records,
+		},
+	})
+}
+
+// ListActiveSeckills 分页列出进行中的秒杀活动及实时库存接口
+func (g *GoodController) ListActiveSeckills(c *gin.Context) {
+	// 解析分页参数，兼容旧版本的page_size参数
+	page, pageSize, err := parsePagination(c, "page_size")
+	if err != nil {
+		slog.Warn("Invalid pagination parameter in active seckills request",
+			"error", err,
+		)
+		// 返回参数无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Page and size must be positive integers",
+		})
+		return
+	}
+
+	result, err := g.GoodService.ListActiveSeckills(page, pageSize)
+	if err != nil {
+		// 返回查询失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to list active seckills",
+		})
+		return
+	}
+
+	// 返回进行中的秒杀活动
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    result,
+		"message": "Active seckills retrieved successfully",
+	})
+}
+
+// ListSeckillAuditLogs 分页查询商品的秒杀下单审计记录接口
+func (g *GoodController) ListSeckillAuditLogs(c *gin.Context) {
+	// 解析商品ID参数
+	goodsId, err := strconv.ParseInt(c.Query("goods_id"), 10, 64)
+	if err != nil || goodsId <= 0 {
+		// 返回参数无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   "invalid goods_id parameter",
+			"message": "Goods ID must be a positive integer",
+		})
+		return
+	}
+
+	// 解析分页参数
+	page, size, err := parsePagination(c, "size")
+	if err != nil {
+		// 返回参数无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Page and size must be positive integers",
+		})
+		return
+	}
+
+	result, err := g.GoodService.ListSeckillAuditLogs(goodsId, page, size)
+	if err != nil {
+		// 返回查询失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to list seckill audit logs",
+		})
+		return
+	}
+
+	// 返回审计记录
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    result,
+		"message": "Seckill audit logs retrieved successfully",
+	})
+}
+
+// GetBlacklist 获取黑名单列表接口
+func (g *GoodController) GetBlacklist(c *gin.Context) {
+	// 解析分页参数，兼容旧版本的limit参数
+	page, size, err := parsePagination(c, "limit")
+	if err != nil {
+		slog.Warn("Invalid pagination parameter in blacklist request",
+			"error", err,
+		)
+		// 返回参数无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Page and size must be positive integers",
+		})
+		return
+	}
+
+	// 获取黑名单列表（最近加入的在前）
+	blacklist, err := g.GoodService.GetBlacklist(page, size)
+	if err != nil {
+		slog.Error("Failed to get blacklist",
+			"error", err,
+		)
+		// 返回获取失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to get blacklist",
+		})
+		return
+	}
+
+	slog.Info("Blacklist retrieved via API",
+		"count", len(blacklist.Items),
+		"total", blacklist.Total,
+	)
+	// 返回黑名单数据
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    blacklist,
+		"message": "Blacklist retrieved successfully",
+	})
+}
+
+// GenerateUserToken 生成用户令牌接口
+func (g *GoodController) GenerateUserToken(c *gin.Context) {
+	// 从查询参数获取用户ID
+	userIdStr := c.Query("user_id")
+	if userIdStr == "" {
+		slog.Warn("Missing user_id parameter in token generation request")
+		// 返回缺少用户ID响应
+		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    -1,
 			"error":   "missing user_id parameter",
 			"message": "User ID is required",
@@ -672,8 +1377,8 @@ func (g *GoodController) ResetDatabase(c *gin.Context) {
 	}
 
 	// 解析商品ID
-	goodsId, err := strconv.Atoi(goodsIdStr)
-	if err != nil || goodsId <= 0 {
+	goodsId, err := g.parseGoodsId(goodsIdStr)
+	if err != nil {
 		slog.Warn("Invalid goods_id parameter in reset request",
 			"goods_id_str", goodsIdStr,
 			"error", err,
@@ -682,13 +1387,13 @@ func (g *GoodController) ResetDatabase(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    -1,
 			"error":   "invalid goods_id parameter",
-			"message": "Goods ID must be a positive integer",
+			"message": "Goods ID must be an integer within the allowed range",
 		})
 		return
 	}
 
 	// 执行重置数据库操作
-	err = g.GoodService.ResetDataBase(goodsId)
+	err = g.GoodService.ResetDataBase(int(goodsId))
 	if err != nil {
 		slog.Error("Failed to reset database",
 			"goods_id", goodsId,
@@ -712,3 +1417,615 @@ func (g *GoodController) ResetDatabase(c *gin.Context) {
 		"message": "Database reset successfully for goods ID: " + goodsIdStr,
 	})
 }
+
+// PreloadGoodsStockBatch 批量预加载商品库存接口
+// 请求体为商品ID的JSON数组，返回以商品ID为键的预加载结果，部分商品失败时仍返回200
+func (g *GoodController) PreloadGoodsStockBatch(c *gin.Context) {
+	var rawIds []int64
+	if err := c.ShouldBindJSON(&rawIds); err != nil {
+		slog.Warn("Invalid body in batch preload request",
+			"error", err,
+		)
+		// 返回请求体无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Request body must be a JSON array of goods IDs",
+		})
+		return
+	}
+	if len(rawIds) == 0 || len(rawIds) > maxBatchPreloadSize {
+		slog.Warn("Invalid goods ID count in batch preload request",
+			"count", len(rawIds),
+			"max", maxBatchPreloadSize,
+		)
+		// 返回数量无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   "invalid goods ID count",
+			"message": fmt.Sprintf("Between 1 and %d goods IDs per request", maxBatchPreloadSize),
+		})
+		return
+	}
+
+	// 校验商品ID范围并去重
+	goodsIds := make([]int64, 0, len(rawIds))
+	seen := make(map[int64]bool, len(rawIds))
+	for _, goodsId := range rawIds {
+		if err := g.GoodsIdRange.Check(goodsId); err != nil {
+			slog.Warn("Invalid goods ID in batch preload request",
+				"goods_id", goodsId,
+				"error", err,
+			)
+			// 返回商品ID无效响应
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    -1,
+				"error":   err.Error(),
+				"message": "Invalid good ID",
+			})
+			return
+		}
+		if !seen[goodsId] {
+			seen[goodsId] = true
+			goodsIds = append(goodsIds, goodsId)
+		}
+	}
+
+	// 执行批量预加载
+	results, err := g.GoodService.PreloadGoodsStockBatch(goodsIds)
+	if err != nil {
+		slog.Error("Failed to preload goods stock in batch",
+			"count", len(goodsIds),
+			"error", err,
+		)
+		// 返回预加载失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to preload goods stock",
+		})
+		return
+	}
+
+	// 返回每个商品的预加载结果
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"results": results,
+		},
+		"message": "Batch preload completed",
+	})
+}
+
+// ListLocks 列出当前持有的分布式锁接口
+// 返回锁命名空间下的Etcd锁键及其租约剩余有效期，用于排查卡住的秒杀
+func (g *GoodController) ListLocks(c *gin.Context) {
+	locks, err := g.GoodService.ListLocks()
+	if err != nil {
+		slog.Error("Failed to list distributed locks",
+			"error", err,
+		)
+		// 返回查询失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to list locks",
+		})
+		return
+	}
+
+	// 返回锁列表
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"count": len(locks),
+			"locks": locks,
+		},
+		"message": "Locks retrieved successfully",
+	})
+}
+
+// GetSeckillTokenStats 查询商品秒杀令牌签发数与兑换数之比接口
+func (g *GoodController) GetSeckillTokenStats(c *gin.Context) {
+	// 从路径参数中获取商品ID
+	id := c.Param("id")
+	gid, err := g.parseGoodsId(id)
+	if err != nil {
+		// 返回参数错误响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Invalid good ID",
+		})
+		return
+	}
+
+	stats, err := g.GoodService.GetSeckillTokenStats(gid)
+	if err != nil {
+		// 返回查询失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to get seckill token stats",
+		})
+		return
+	}
+
+	// 返回令牌统计
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    stats,
+		"message": "Seckill token stats retrieved successfully",
+	})
+}
+
+// ResetDatabaseBatch 批量重置数据库接口
+func (g *GoodController) ResetDatabaseBatch(c *gin.Context) {
+	// 获取商品ID列表参数，多个ID用逗号分隔
+	goodsIdsStr := c.Query("goods_ids")
+	if goodsIdsStr == "" {
+		slog.Warn("Missing goods_ids parameter in batch reset request")
+		// 返回缺少商品ID响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   "missing goods_ids parameter",
+			"message": "Goods IDs are required",
+		})
+		return
+	}
+
+	// 解析商品ID列表
+	parts := strings.Split(goodsIdsStr, ",")
+	if len(parts) > maxBatchResetSize {
+		slog.Warn("Too many goods_ids in batch reset request",
+			"count", len(parts),
+			"max", maxBatchResetSize,
+		)
+		// 返回数量超限响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   "too many goods_ids",
+			"message": fmt.Sprintf("At most %d goods IDs per request", maxBatchResetSize),
+		})
+		return
+	}
+	goodsIds := make([]int, 0, len(parts))
+	for _, part := range parts {
+		goodsId, err := g.parseGoodsId(part)
+		if err != nil {
+			slog.Warn("Invalid goods_ids parameter in batch reset request",
+				"goods_ids_str", goodsIdsStr,
+				"error", err,
+			)
+			// 返回商品ID无效响应
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    -1,
+				"error":   "invalid goods_ids parameter",
+				"message": "Goods IDs must be comma separated integers within the allowed range",
+			})
+			return
+		}
+		goodsIds = append(goodsIds, int(goodsId))
+	}
+
+	// 执行批量重置
+	results, err := g.GoodService.ResetDataBaseBatch(goodsIds)
+	if err != nil {
+		slog.Error("Failed to reset database in batch",
+			"goods_ids", goodsIds,
+			"error", err,
+		)
+		// 返回重置失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to reset database",
+		})
+		return
+	}
+
+	slog.Info("Database reset in batch via API",
+		"goods_ids", goodsIds,
+	)
+	// 返回每个商品的重置结果
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"results": results,
+		},
+		"message": "Batch database reset completed",
+	})
+}
+
+// RetryOrderOutbox 重发发件箱中订单消息接口
+func (g *GoodController) RetryOrderOutbox(c *gin.Context) {
+	// 获取单次处理数量，默认100
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			// 返回参数错误响应
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    -1,
+				"error":   "invalid limit parameter",
+				"message": "Limit must be a positive integer",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	sent, remaining, err := g.GoodService.RetryOrderOutbox(limit)
+	if err != nil {
+		// 返回重试失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to retry order outbox",
+		})
+		return
+	}
+
+	// 返回重试结果
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"sent":      sent,
+			"remaining": remaining,
+		},
+		"message": "Order outbox retried",
+	})
+}
+
+// OrderExists 查询用户是否已有指定商品订单接口
+func (g *GoodController) OrderExists(c *gin.Context) {
+	// 用户ID由认证中间件写入上下文
+	userId := c.GetInt64("userId")
+
+	// 获取商品ID
+	goodsIdStr := c.Query("gid")
+	goodsId, err := g.parseGoodsId(goodsIdStr)
+	if err != nil {
+		slog.Warn("Invalid goods ID in order exists request",
+			"user_id", userId,
+			"goods_id_str", goodsIdStr,
+			"error", err,
+		)
+		// 返回商品ID无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Invalid good ID",
+		})
+		return
+	}
+
+	exists, err := g.GoodService.HasUserOrder(userId, goodsId)
+	if err != nil {
+		// 返回查询失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to check order",
+		})
+		return
+	}
+
+	// 返回查询结果
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"user_id":  userId,
+			"goods_id": goodsId,
+			"exists":   exists,
+		},
+		"message": "Order existence checked",
+	})
+}
+
+// GetOrderStatus 查询秒杀订单状态接口，只能查询当前用户自己的订单
+func (g *GoodController) GetOrderStatus(c *gin.Context) {
+	// 用户ID由认证中间件写入上下文
+	userId := c.GetInt64("userId")
+	orderId := c.Param("order_id")
+
+	// 查询数据库前先校验订单归属，避免暴露其他用户的订单是否存在
+	ownerId, _, err := handler.ParseOrderId(orderId)
+	if err != nil {
+		// 返回订单ID无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Invalid order ID",
+		})
+		return
+	}
+	if ownerId != userId {
+		slog.Warn("Order status requested by non-owner",
+			"user_id", userId,
+			"order_id", orderId,
+		)
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    -1,
+			"error":   errs.ErrOrderNotOwned.Error(),
+			"message": "Order does not belong to user",
+		})
+		return
+	}
+
+	status, err := g.GoodService.GetOrderStatus(orderId)
+	if err != nil {
+		// 订单不存在返回404，其他错误返回500
+		c.JSON(errorStatus(err), gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to get order status",
+		})
+		return
+	}
+
+	// 返回订单状态
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    status,
+		"message": "Order status retrieved",
+	})
+}
+
+// CancelUnpaidOrders 取消当前用户所有未支付订单接口
+func (g *GoodController) CancelUnpaidOrders(c *gin.Context) {
+	// 用户ID由认证中间件写入上下文
+	userId := c.GetInt64("userId")
+
+	cancelled, err := g.GoodService.CancelUnpaidOrders(userId)
+	if err != nil {
+		// 返回取消失败响应，已取消的数量一并返回
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"data":    gin.H{"cancelled": cancelled},
+			"error":   err.Error(),
+			"message": "Failed to cancel unpaid orders",
+		})
+		return
+	}
+
+	slog.Info("Unpaid orders cancelled via API",
+		"user_id", userId,
+		"cancelled", cancelled,
+	)
+	// 返回取消数量
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"user_id":   userId,
+			"cancelled": cancelled,
+		},
+		"message": "Unpaid orders cancelled",
+	})
+}
+
+// PrecheckSeckill 秒杀资格预检接口
+// 返回各项资格检查结果，不消耗限流次数也不签发令牌
+func (g *GoodController) PrecheckSeckill(c *gin.Context) {
+	// 用户ID由认证中间件写入上下文
+	userId := c.GetInt64("userId")
+
+	// 获取商品ID
+	goodsIdStr := c.Query("gid")
+	goodsId, err := g.parseGoodsId(goodsIdStr)
+	if err != nil {
+		slog.Warn("Invalid goods ID in precheck request",
+			"user_id", userId,
+			"goods_id_str", goodsIdStr,
+			"error", err,
+		)
+		// 返回商品ID无效响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Invalid good ID",
+		})
+		return
+	}
+
+	report := g.GoodService.PrecheckSeckill(userId, goodsId)
+	// 返回资格预检报告
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    report,
+		"message": "Seckill precheck completed",
+	})
+}
+
+// GetCurrentStock 查询商品剩余库存接口，无需认证且不计入用户限流
+// 库存尚未预加载时status为not_loaded且不返回库存数量
+func (g *GoodController) GetCurrentStock(c *gin.Context) {
+	// 从路径参数中获取商品ID
+	id := c.Param("id")
+	gid, err := g.parseGoodsId(id)
+	if err != nil {
+		// 返回参数错误响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Invalid good ID",
+		})
+		return
+	}
+
+	stock, loaded, err := g.GoodService.GetCurrentStock(gid)
+	if err != nil {
+		slog.Error("Failed to query current stock",
+			"goods_id", gid,
+			"error", err,
+		)
+		// 返回查询失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to query stock",
+		})
+		return
+	}
+
+	data := gin.H{
+		"goods_id": gid,
+		"status":   model.StockStatusNotLoaded,
+	}
+	if loaded {
+		data["status"] = model.StockStatusLoaded
+		data["stock"] = stock
+		data["sold_out"] = stock <= 0
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"data":    data,
+		"message": "Stock queried successfully",
+	})
+}
+
+// GetPromotionSchedule 商品秒杀活动排期查询接口
+// 返回商品的全部秒杀活动（已结束、进行中和未开始），按开始时间排序
+func (g *GoodController) GetPromotionSchedule(c *gin.Context) {
+	// 从路径参数中获取商品ID
+	id := c.Param("id")
+	gid, err := g.parseGoodsId(id)
+	if err != nil {
+		// 返回参数错误响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Invalid good ID",
+		})
+		return
+	}
+
+	schedule, err := g.GoodService.GetPromotionSchedule(gid)
+	if err != nil {
+		// 返回查询失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to query promotion schedule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"goods_id":   gid,
+			"promotions": schedule,
+		},
+		"message": "Promotion schedule queried successfully",
+	})
+}
+
+// StockWebSocket 商品库存实时推送接口
+// 连接建立后先推送当前库存，之后每次库存变更推送一条消息，并定期发送心跳
+func (g *GoodController) StockWebSocket(c *gin.Context) {
+	// 从路径参数中获取商品ID
+	id := c.Param("id")
+	gid, err := g.parseGoodsId(id)
+	if err != nil {
+		// 返回参数错误响应
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    -1,
+			"error":   "invalid goods id",
+			"message": "Invalid good ID",
+		})
+		return
+	}
+
+	// 连接数限制
+	if g.wsConnections.Add(1) > maxStockWSConnections {
+		g.wsConnections.Add(-1)
+		slog.Warn("Stock websocket connection limit reached",
+			"goods_id", gid,
+			"limit", maxStockWSConnections,
+		)
+		// 返回服务繁忙响应
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    -1,
+			"error":   "too many connections",
+			"message": "Too many stock subscribers, please try again later",
+		})
+		return
+	}
+	defer g.wsConnections.Add(-1)
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	// 先订阅库存变更，避免升级协议后遗漏消息
+	updates, err := g.GoodService.SubscribeStockUpdates(ctx, gid)
+	if err != nil {
+		// 返回订阅失败响应
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Failed to subscribe stock updates",
+		})
+		return
+	}
+
+	conn, err := stockWSUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		slog.Warn("Failed to upgrade stock websocket",
+			"goods_id", gid,
+			"error", err,
+		)
+		return // Upgrade已写入错误响应
+	}
+	defer conn.Close()
+
+	slog.Info("Stock websocket connected",
+		"goods_id", gid,
+		"client_ip", c.ClientIP(),
+	)
+
+	// 推送当前库存快照
+	if stock, err := g.GoodService.GetGoodsStock(gid); err == nil {
+		snapshot := model.StockUpdate{GoodsId: gid, Stock: stock, Timestamp: time.Now()}
+		conn.SetWriteDeadline(time.Now().Add(stockWSWriteTimeout))
+		if err := conn.WriteJSON(snapshot); err != nil {
+			return
+		}
+	}
+
+	// 读取客户端消息以处理心跳响应和连接关闭
+	conn.SetReadDeadline(time.Now().Add(stockWSPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(stockWSPongWait))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(stockWSPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(stockWSWriteTimeout))
+			if err := conn.WriteJSON(update); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(stockWSWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"seckill_system/errs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry 秒杀系统指标注册表，独立于默认注册表，避免与依赖库注册的指标冲突
+var Registry = prometheus.NewRegistry()
+
+// 秒杀请求相关指标，均按商品ID打标签
+var (
+	SeckillAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "seckill_attempts_total",
+		Help: "Total number of seckill requests.",
+	}, []string{"goods_id"})
+
+	SeckillSuccesses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "seckill_successes_total",
+		Help: "Total number of seckill requests that created an order.",
+	}, []string{"goods_id"})
+
+	SeckillSoldOut = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "seckill_sold_out_total",
+		Help: "Total number of seckill requests rejected because the goods was sold out.",
+	}, []string{"goods_id"})
+
+	SeckillFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "seckill_failures_total",
+		Help: "Total number of failed seckill requests by outcome, sold-out rejections excluded.",
+	}, []string{"goods_id", "outcome"})
+
+	SeckillTokensIssued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "seckill_tokens_issued_total",
+		Help: "Total number of seckill tokens issued.",
+	}, []string{"goods_id"})
+
+	SeckillTokensRedeemed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "seckill_tokens_redeemed_total",
+		Help: "Total number of seckill tokens successfully verified and consumed.",
+	}, []string{"goods_id"})
+
+	TokenGenerationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "seckill_token_generation_seconds",
+		Help:    "Latency of seckill token generation including all eligibility checks.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"goods_id"})
+
+	StockDecrSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "seckill_stock_decr_seconds",
+		Help:    "Latency of the atomic Redis stock decrement.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25},
+	}, []string{"goods_id"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		SeckillAttempts,
+		SeckillSuccesses,
+		SeckillSoldOut,
+		SeckillFailures,
+		SeckillTokensIssued,
+		SeckillTokensRedeemed,
+		TokenGenerationSeconds,
+		StockDecrSeconds,
+	)
+}
+
+// 未通过商品校验的请求统一记录到固定标签下，避免客户端传入的任意商品ID产生无限增长的标签值
+const (
+	UnknownGoods      int64 = 0         // 未校验的商品ID，调用方在商品或活动查询成功前传入
+	UnknownGoodsLabel       = "unknown" // UnknownGoods及其他非法商品ID的标签值
+)
+
+// goodsLabel 返回商品ID标签值，非法商品ID返回UnknownGoodsLabel
+func goodsLabel(goodsId int64) string {
+	if goodsId <= UnknownGoods {
+		return UnknownGoodsLabel
+	}
+	return strconv.FormatInt(goodsId, 10)
+}
+
+// ObserveSeckill 记录一次秒杀请求及其结果，err为nil表示下单成功
+func ObserveSeckill(goodsId int64, err error) {
+	label := goodsLabel(goodsId)
+	SeckillAttempts.WithLabelValues(label).Inc()
+	switch {
+	case err == nil:
+		SeckillSuccesses.WithLabelValues(label).Inc()
+	case errors.Is(err, errs.ErrSoldOut):
+		SeckillSoldOut.WithLabelValues(label).Inc()
+	default:
+		SeckillFailures.WithLabelValues(label, errs.Outcome(err)).Inc()
+	}
+}
+
+// ObserveTokenIssued 记录一次秒杀令牌签发
+func ObserveTokenIssued(goodsId int64) {
+	SeckillTokensIssued.WithLabelValues(goodsLabel(goodsId)).Inc()
+}
+
+// ObserveTokenRedeemed 记录一次秒杀令牌兑换
+func ObserveTokenRedeemed(goodsId int64) {
+	SeckillTokensRedeemed.WithLabelValues(goodsLabel(goodsId)).Inc()
+}
+
+// ObserveTokenGeneration 记录从start开始的秒杀令牌签发耗时
+func ObserveTokenGeneration(goodsId int64, start time.Time) {
+	TokenGenerationSeconds.WithLabelValues(goodsLabel(goodsId)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveStockDecr 记录从start开始的Redis库存扣减耗时
+func ObserveStockDecr(goodsId int64, start time.Time) {
+	StockDecrSeconds.WithLabelValues(goodsLabel(goodsId)).Observe(time.Since(start).Seconds())
+}
+
+// Handler 返回Prometheus指标抓取接口
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"seckill_system/config"
+	"sync"
+)
+
+// ConnLimitListener 使用配置中的单IP连接数上限和豁免网段包装监听器
+func ConnLimitListener(inner net.Listener) (net.Listener, error) {
+	if config.AppConfig == nil {
+		return inner, nil
+	}
+	return NewConnLimitListener(inner, config.AppConfig.Server.MaxConnsPerIP, config.AppConfig.Server.ConnLimitExemptCIDRs)
+}
+
+// NewConnLimitListener 创建限制单个客户端IP并发连接数的监听器
+// 同一IP的活跃连接数达到maxPerIP时，新连接在接受后立即关闭，不会交给HTTP服务器处理；
+// 来源地址属于exemptCIDRs中任一网段的连接不计入上限。maxPerIP不大于0时直接返回原监听器
+func NewConnLimitListener(inner net.Listener, maxPerIP int, exemptCIDRs []string) (net.Listener, error) {
+	if maxPerIP <= 0 {
+		return inner, nil
+	}
+	exempt := make([]*net.IPNet, 0, len(exemptCIDRs))
+	for _, cidr := range exemptCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exempt CIDR %q: %w", cidr, err)
+		}
+		exempt = append(exempt, network)
+	}
+	return &connLimitListener{
+		Listener: inner,
+		maxPerIP: maxPerIP,
+		exempt:   exempt,
+		conns:    make(map[string]int),
+	}, nil
+}
+
+// connLimitListener 按客户端IP统计活跃连接数的监听器
+type connLimitListener struct {
+	net.Listener
+	maxPerIP int
+	exempt   []*net.IPNet
+
+	mu    sync.Mutex
+	conns map[string]int // 客户端IP -> 活跃连接数
+}
+
+// Accept 接受连接，超出上限的连接直接关闭并继续等待下一个连接
+func (l *connLimitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		ip := remoteIP(conn)
+		if ip == nil || l.isExempt(ip) {
+			return conn, nil
+		}
+
+		key := ip.String()
+		if l.acquire(key) {
+			return &limitedConn{Conn: conn, release: func() { l.release(key) }}, nil
+		}
+		// 攻击时每个被拒绝的连接都记录日志会加重负担，仅在Debug级别记录
+		slog.Debug("Connection rejected by per-IP limit",
+			"client_ip", key,
+			"max_conns_per_ip", l.maxPerIP,
+		)
+		conn.Close()
+	}
+}
+
+// isExempt 判断客户端IP是否属于豁免网段
+func (l *connLimitListener) isExempt(ip net.IP) bool {
+	for _, network := range l.exempt {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// acquire 为客户端IP占用一个连接名额，已达上限时返回false
+func (l *connLimitListener) acquire(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conns[key] >= l.maxPerIP {
+		return false
+	}
+	l.conns[key]++
+	return true
+}
+
+// release 释放客户端IP的一个连接名额
+func (l *connLimitListener) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conns[key] <= 1 {
+		delete(l.conns, key)
+		return
+	}
+	l.conns[key]--
+}
+
+// remoteIP 解析连接的客户端IP，无法解析时返回nil（不做限制）
+func remoteIP(conn net.Conn) net.IP {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// limitedConn 关闭时释放连接名额的连接，重复关闭只释放一次
+type limitedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+// Close 关闭连接并释放名额
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
@@ -1,19 +1,37 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"log/slog"
 	"net/http"
+	"seckill_system/config"
+	"seckill_system/errs"
+	"seckill_system/model"
 	"seckill_system/service"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// TokenVerifier 用户令牌验证接口
+type TokenVerifier interface {
+	// VerifyUserToken 验证用户令牌并返回用户ID
+	VerifyUserToken(token string) (int64, error)
+}
+
 // AuthMiddleware 用户认证中间件
 // 验证请求头中的Authorization令牌，解析用户ID并存入上下文
 func AuthMiddleware() gin.HandlerFunc {
-	// 获取商品服务对象，用于令牌验证
-	goodService := service.GetGoodService()
+	// 使用商品服务对象进行令牌验证
+	return NewAuthMiddleware(service.GetGoodService())
+}
 
+// NewAuthMiddleware 使用指定的令牌验证器创建用户认证中间件
+func NewAuthMiddleware(goodService TokenVerifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 从请求头获取Authorization令牌
 		token := c.GetHeader("Authorization")
@@ -37,7 +55,7 @@ func AuthMiddleware() gin.HandlerFunc {
 			slog.Warn("Invalid authorization token in middleware",
 				"path", c.Request.URL.Path,
 				"method", c.Request.Method,
-				"token_prefix", token[:8],
+				"token_prefix", model.TokenPrefix(token),
 				"error", err,
 			)
 			// 令牌验证失败，返回401未授权错误
@@ -56,13 +74,90 @@ func AuthMiddleware() gin.HandlerFunc {
 			"user_id", userId,
 			"path", c.Request.URL.Path,
 			"method", c.Request.Method,
-			"token_prefix", token[:8],
+			"token_prefix", model.TokenPrefix(token),
 		)
 		// 继续执行后续的中间件或处理函数
 		c.Next()
 	}
 }
 
+// IPRateLimiter 客户端IP限流接口
+type IPRateLimiter interface {
+	// CheckIPRateLimit 检查客户端IP是否超出限流，超出时返回errs.ErrRateLimited
+	CheckIPRateLimit(clientIP string) error
+}
+
+// NewIPRateLimitMiddleware 使用指定的限流器创建客户端IP限流中间件
+// 超出限流返回429，限流后端故障且配置为拒绝时返回503
+func NewIPRateLimitMiddleware(limiter IPRateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientIP := c.ClientIP()
+		err := limiter.CheckIPRateLimit(clientIP)
+		if err == nil {
+			c.Next()
+			return
+		}
+
+		status := http.StatusServiceUnavailable
+		if errors.Is(err, errs.ErrRateLimited) {
+			status = http.StatusTooManyRequests
+		}
+		slog.Warn("Request rejected by ip rate limit",
+			"path", c.Request.URL.Path,
+			"client_ip", clientIP,
+			"error", err,
+		)
+		c.AbortWithStatusJSON(status, gin.H{
+			"code":    -1,
+			"error":   err.Error(),
+			"message": "Too many requests from this IP",
+		})
+	}
+}
+
+// ReadOnlyChecker 只读模式查询接口
+type ReadOnlyChecker interface {
+	// ReadOnly 判断系统是否处于只读模式
+	ReadOnly() bool
+}
+
+// ReadOnlyMiddleware 只读模式中间件，使用配置中的响应信息
+func ReadOnlyMiddleware(checker ReadOnlyChecker) gin.HandlerFunc {
+	if config.AppConfig == nil {
+		return NewReadOnlyMiddleware(checker, config.ReadOnlyConfig{})
+	}
+	return NewReadOnlyMiddleware(checker, config.AppConfig.ReadOnly)
+}
+
+// NewReadOnlyMiddleware 创建只读模式中间件
+// 系统处于只读模式时，GET、HEAD和OPTIONS以外的请求返回503并附带Retry-After；读请求正常处理
+func NewReadOnlyMiddleware(checker ReadOnlyChecker, cfg config.ReadOnlyConfig) gin.HandlerFunc {
+	retryAfter := strconv.Itoa(cfg.RetryAfter())
+	message := cfg.ResponseMessage()
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if !checker.ReadOnly() {
+			c.Next()
+			return
+		}
+
+		slog.Info("Write request rejected in read-only mode",
+			"path", c.Request.URL.Path,
+			"method", c.Request.Method,
+		)
+		c.Header("Retry-After", retryAfter)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"code":    -1,
+			"error":   errs.ErrReadOnly.Error(),
+			"message": message,
+		})
+	}
+}
+
 // AdminMiddleware 管理员权限验证中间件
 // 简易版管理员验证，通过查询参数检查是否为管理员操作
 func AdminMiddleware() gin.HandlerFunc {
@@ -92,3 +187,107 @@ func AdminMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// 请求ID相关常量
+const (
+	RequestIdHeader    = "X-Request-Id" // 请求ID请求头/响应头
+	maxRequestIdLength = 64             // 客户端传入请求ID的最大长度
+)
+
+// RequestIdMiddleware 请求ID中间件
+// 优先使用客户端传入的X-Request-Id（仅允许字母、数字、-和_），否则生成随机ID；
+// 请求ID写入响应头和请求context，使用slog.*Context记录的日志可通过/api/admin/trace/:request_id回放
+func RequestIdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(RequestIdHeader)
+		if !validRequestId(requestId) {
+			requestId = newRequestId()
+		}
+		c.Set("requestId", requestId)
+		c.Header(RequestIdHeader, requestId)
+		c.Request = c.Request.WithContext(config.WithRequestId(c.Request.Context(), requestId))
+
+		start := time.Now()
+		slog.InfoContext(c.Request.Context(), "Request started",
+			"path", c.Request.URL.Path,
+			"method", c.Request.Method,
+			"client_ip", c.ClientIP(),
+		)
+		c.Next()
+		slog.InfoContext(c.Request.Context(), "Request completed",
+			"path", c.Request.URL.Path,
+			"method", c.Request.Method,
+			"status", c.Writer.Status(),
+			"latency", time.Since(start),
+		)
+	}
+}
+
+// validRequestId 判断客户端传入的请求ID是否可用
+func validRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+	for _, r := range requestId {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestId 生成16字节随机十六进制请求ID
+func newRequestId() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// InflightLimitMiddleware 全局并发请求上限中间件，使用配置中的上限和豁免路径
+func InflightLimitMiddleware() gin.HandlerFunc {
+	if config.AppConfig == nil {
+		return NewInflightLimitMiddleware(0, nil)
+	}
+	return NewInflightLimitMiddleware(config.AppConfig.Server.MaxInflightRequests, config.AppConfig.Server.InflightExemptPaths)
+}
+
+// NewInflightLimitMiddleware 创建全局并发请求上限中间件
+// 同时处理的请求数达到maxInflight时，新请求不排队，立即返回503；路径以exemptPaths中任一前缀开头的请求不计入上限
+// maxInflight不大于0时不限制
+func NewInflightLimitMiddleware(maxInflight int, exemptPaths []string) gin.HandlerFunc {
+	if maxInflight <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	slots := make(chan struct{}, maxInflight) // 并发槽位
+	return func(c *gin.Context) {
+		for _, prefix := range exemptPaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			c.Next()
+		default:
+			// 过载时每个被拒绝的请求都记录日志会加重负担，仅在Debug级别记录
+			slog.Debug("Request shed by inflight limit",
+				"path", c.Request.URL.Path,
+				"max_inflight", maxInflight,
+			)
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"code":    -1,
+				"error":   "too many in-flight requests",
+				"message": "Server busy, please retry later",
+			})
+		}
+	}
+}
@@ -1,56 +1,154 @@
 package router
 
 import (
+	"net/http/pprof"
+	"strings"
+
 	"seckill_system/web/controller"
+	"seckill_system/web/metrics"
 	"seckill_system/web/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 // InitRouter 初始化并返回Gin路由引擎
-func InitRouter() *gin.Engine {
+// includeAdmin为false时不注册管理接口，管理接口由InitAdminRouter在独立端口提供
+func InitRouter(includeAdmin bool) *gin.Engine {
+	return NewRouter(controller.NewGoodController(), middleware.AuthMiddleware(), includeAdmin, middleware.InflightLimitMiddleware())
+}
+
+// InitAdminRouter 初始化并返回仅包含管理接口和pprof的Gin路由引擎
+func InitAdminRouter() *gin.Engine {
+	return NewAdminRouter(controller.NewGoodController())
+}
+
+// NewRouter 使用指定的控制器和认证中间件创建公共路由引擎
+// global为作用于全部路由的中间件（如并发上限），在请求ID中间件之前执行
+func NewRouter(goodController *controller.GoodController, auth gin.HandlerFunc, includeAdmin bool, global ...gin.HandlerFunc) *gin.Engine {
 	// 创建默认Gin引擎实例
 	r := gin.Default()
+	r.Use(global...)
+	r.Use(middleware.RequestIdMiddleware())
+
+	// Prometheus指标抓取接口
+	r.GET("/metrics", metrics.Handler())
 
-	// 初始化控制器实例
-	goodController := controller.NewGoodController()
+	// 健康检查接口，供负载均衡和k8s探针使用
+	r.GET("/health", goodController.Health) // 存活探针
+	r.GET("/ready", goodController.Ready)   // 就绪探针，检查全部外部依赖
 
 	// 创建API路由组，所有接口前缀为/api
 	api := r.Group("/api")
 	{
 		// 认证相关接口
-		auth := api.Group("/auth")
+		authGroup := api.Group("/auth")
 		{
-			auth.GET("/create_user_token", goodController.GenerateUserToken) // 生成用户令牌接口
-			auth.GET("/verify_user_token", goodController.VerifyToken)       // 验证用户令牌接口
+			authGroup.GET("/create_user_token", goodController.GenerateUserToken) // 生成用户令牌接口
+			authGroup.GET("/verify_user_token", goodController.VerifyToken)       // 验证用户令牌接口
 		}
 
 		// 商品信息接口 - 获取商品详情
 		api.GET("/goods/:id", goodController.GetGoodInfo)
+		// 商品搜索接口 - 按标题关键字搜索
+		api.GET("/goods/search", goodController.SearchGoods)
+		// 商品库存查询接口 - 无需认证，不计入限流
+		api.GET("/goods/:id/stock", goodController.GetCurrentStock)
+		// 商品秒杀活动排期查询接口 - 已结束、进行中和未开始的活动
+		api.GET("/goods/:id/schedule", goodController.GetPromotionSchedule)
+		// 商品库存实时推送接口 - WebSocket
+		api.GET("/goods/:id/ws", goodController.StockWebSocket)
 
-		// 秒杀相关接口
-		api.POST("/seckill/token", middleware.AuthMiddleware(), goodController.GetSeckillToken) // 获取秒杀令牌接口
-		api.POST("/seckill", middleware.AuthMiddleware(), goodController.SeckillWithToken)      // 使用令牌进行秒杀接口
+		// 只读模式下拒绝写接口，读接口不受影响
+		readOnly := middleware.ReadOnlyMiddleware(goodController.GoodService)
+
+		// 秒杀相关接口，获取令牌和下单在认证前先按客户端IP限流
+		ipLimit := middleware.NewIPRateLimitMiddleware(goodController.GoodService)
+		api.POST("/seckill/token", readOnly, ipLimit, auth, goodController.GetSeckillToken) // 获取秒杀令牌接口
+		api.POST("/seckill", readOnly, ipLimit, auth, goodController.SeckillWithToken)      // 使用令牌进行秒杀接口
+		api.POST("/seckill/async", readOnly, ipLimit, auth, goodController.SeckillAsync)    // 异步秒杀接口，返回请求ID
+		api.GET("/seckill/result/:request_id", auth, goodController.GetAsyncSeckillResult)  // 查询异步秒杀结果接口
+		api.GET("/seckill/precheck", auth, goodController.PrecheckSeckill)                  // 秒杀资格预检接口
+
+		// 订单相关接口
+		api.GET("/order/exists", auth, goodController.OrderExists)                        // 查询用户是否已有商品订单
+		api.GET("/order/:order_id", auth, goodController.GetOrderStatus)                  // 按订单ID查询订单状态
+		api.POST("/orders/cancel_all", readOnly, auth, goodController.CancelUnpaidOrders) // 取消用户所有未支付订单
 
 		// 支付相关接口
-		api.POST("/payment/simulate", middleware.AuthMiddleware(), goodController.SimulatePayment) // 模拟支付接口
+		api.POST("/payment/simulate", readOnly, auth, goodController.SimulatePayment) // 模拟支付接口
 
-		// 管理接口组，需要管理员权限
-		admin := api.Group("/admin", middleware.AdminMiddleware())
-		{
-			// 商品库存预加载接口 - 修复：使用路径参数
-			admin.POST("/preload/:id", goodController.PreloadGoodsStock)
-			// 数据库重置接口
-			admin.POST("/reset_db", goodController.ResetDatabase)
-
-			// Etcd配置管理接口
-			admin.POST("/config/seckill/enable", goodController.SetSeckillEnabled) // 设置秒杀开关状态
-			admin.POST("/config/rate_limit", goodController.SetRateLimit)          // 设置限流配置
-
-			// 黑名单管理接口
-			admin.POST("/blacklist/add", goodController.AddToBlacklist) // 添加用户到黑名单
-			admin.GET("/blacklist", goodController.GetBlacklist)        // 获取黑名单列表
+		if includeAdmin {
+			registerAdminRoutes(api, goodController)
 		}
 	}
 	return r
 }
+
+// NewAdminRouter 使用指定的控制器创建管理路由引擎
+// 只包含/api/admin接口和pprof性能分析接口，应绑定在内网地址；不受并发上限约束，便于过载时排查
+func NewAdminRouter(goodController *controller.GoodController) *gin.Engine {
+	r := gin.Default()
+	r.Use(middleware.RequestIdMiddleware())
+	registerAdminRoutes(r.Group("/api"), goodController)
+
+	// pprof性能分析接口
+	r.GET("/debug/pprof/*name", pprofHandler)
+	r.POST("/debug/pprof/*name", pprofHandler)
+	return r
+}
+
+// registerAdminRoutes 在API路由组下注册管理接口
+func registerAdminRoutes(api *gin.RouterGroup, goodController *controller.GoodController) {
+	// 管理接口组，需要管理员权限
+	admin := api.Group("/admin", middleware.AdminMiddleware())
+	{
+		// 商品库存预加载接口 - 修复：使用路径参数
+		admin.POST("/preload/:id", goodController.PreloadGoodsStock)
+		// 商品库存批量预加载接口，请求体为商品ID的JSON数组
+		admin.POST("/preload/batch", goodController.PreloadGoodsStockBatch)
+		// 数据库重置接口
+		admin.POST("/reset_db", goodController.ResetDatabase)
+		// 数据库批量重置接口
+		admin.POST("/reset_db/batch", goodController.ResetDatabaseBatch)
+		// 订单消息发件箱重试接口
+		admin.POST("/outbox/retry", goodController.RetryOrderOutbox)
+		// 秒杀令牌强制失效接口
+		admin.POST("/seckill/token/expire", goodController.ExpireSeckillToken)
+		// 秒杀令牌签发与兑换统计接口
+		admin.GET("/seckill/token/stats/:id", goodController.GetSeckillTokenStats)
+		// 进行中秒杀活动列表接口（含实时库存）
+		admin.GET("/seckills/active", goodController.ListActiveSeckills)
+		// 按请求ID回放请求日志接口
+		admin.GET("/trace/:request_id", goodController.GetTrace)
+		// 当前持有的Etcd分布式锁查询接口
+		admin.GET("/locks", goodController.ListLocks)
+		// 商品秒杀下单审计记录分页查询接口
+		admin.GET("/audit", goodController.ListSeckillAuditLogs)
+
+		// Etcd配置管理接口
+		admin.POST("/config/seckill/enable", goodController.SetSeckillEnabled) // 设置秒杀开关状态
+		admin.POST("/config/rate_limit", goodController.SetRateLimit)          // 设置限流配置
+
+		// 黑名单管理接口
+		admin.POST("/blacklist/add", goodController.AddToBlacklist)     // 添加用户到黑名单
+		admin.GET("/blacklist", goodController.GetBlacklist)            // 获取黑名单列表
+		admin.POST("/blacklist/import", goodController.ImportBlacklist) // 批量导入黑名单
+		admin.GET("/blacklist/export", goodController.ExportBlacklist)  // 导出全部黑名单
+	}
+}
+
+// pprofHandler 分发pprof请求到对应的处理函数
+func pprofHandler(c *gin.Context) {
+	switch strings.TrimPrefix(c.Param("name"), "/") {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Index(c.Writer, c.Request) // 首页及heap、goroutine等命名profile
+	}
+}